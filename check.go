@@ -0,0 +1,474 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/ancestors"
+	"github.com/punkscience/sanitize/internal/casing"
+	"github.com/punkscience/sanitize/internal/cireport"
+	"github.com/punkscience/sanitize/internal/collation"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/paths"
+	"github.com/punkscience/sanitize/internal/risk"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+	"github.com/punkscience/sanitize/internal/service"
+	"github.com/punkscience/sanitize/internal/walker"
+)
+
+// Flags for the check subcommand
+var (
+	anonymize        bool     // Replace path components with stable pseudonyms
+	anonymizeKey     string   // Key for the pseudonym hash (empty = random per run)
+	riskNameLength   int      // Names longer than this are risky (0 = never)
+	riskPathHeadroom int      // Paths leaving fewer characters below the profile's limit are risky (0 = never)
+	ignoreRisks      []string // Risks that are not reported
+	checkFormat      string   // text, sarif or github
+)
+
+// caseConflictRule identifies case conflicts in CI output, where every finding needs a rule
+const caseConflictRule = "case-conflict"
+
+// nonCompliantRule identifies violations of sanitizers that don't explain which rule fired
+const nonCompliantRule = "non-compliant-name"
+
+// errViolationsFound is returned by check mode so the process exits non-zero
+var errViolationsFound = errors.New("non-compliant folder names found")
+
+// checkCmd scans a tree and reports non-compliant names without making changes
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Report non-compliant folder names without changing anything",
+	Long: `Check scans the folder tree, prints every folder name that is not Windows-compatible
+together with the rules it violates, and makes zero changes.
+
+The command exits with 1 if any violations exist (3 if the check could not
+run), which makes it suitable for CI pipelines that validate build artifact trees.
+
+Names that pass every rule but are still likely to cause trouble are listed
+separately as risk warnings: digit-only names that spreadsheets turn into
+numbers, very long names, names mixing writing systems, and paths close to the
+profile's path limit. Risk warnings never make the command fail.
+
+With --format sarif the report is written as SARIF 2.1.0 for code scanning
+tools, and with --format github as ::error and ::warning workflow commands that
+GitHub Actions shows as annotations, inline in pull requests. Paths are given
+relative to the working directory, so run the check from the repository root.
+
+When the profile's target is case-insensitive, siblings whose names differ
+only in case (Photos, photos, PHOTOS) are listed as case conflicts and make the
+command fail: only one of them can exist after the migration. A run with
+--consolidate-case merges them into one folder.`,
+	Example: `  sanitize check --path ./dist
+  sanitize check --path ./assets --format sarif > sanitize.sarif
+  sanitize check --path ./assets --format github`,
+	Args: cobra.NoArgs,
+	// Violations are an expected outcome, so don't print usage on failure
+	SilenceUsage: true,
+	RunE:         runCheck,
+}
+
+// runCheck executes the scan and prints the violations
+func runCheck(cmd *cobra.Command, args []string) error {
+	absPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("error resolving path: %w", err)
+	}
+
+	if err := validatePath(absPath); err != nil {
+		return err
+	}
+	if err := cireport.ValidateFormat(checkFormat); err != nil {
+		return fmt.Errorf("--format: %w", err)
+	}
+	if anonymize && checkFormat != cireport.FormatText {
+		return fmt.Errorf("--anonymize can't be combined with --format %s, whose annotations need real paths", checkFormat)
+	}
+
+	folderSanitizer, err := newFolderSanitizer()
+	if err != nil {
+		return err
+	}
+
+	collator, err := collation.New(collationName)
+	if err != nil {
+		return err
+	}
+
+	options, err := walkerOptions()
+	if err != nil {
+		return err
+	}
+
+	assessor, err := newRiskAssessor()
+	if err != nil {
+		return err
+	}
+
+	profile, err := sanitizer.LookupProfile(profileName)
+	if err != nil {
+		return err
+	}
+
+	// The root and the directories above it are checked for trailing spaces and periods too
+	var directoryWalker interfaces.DirectoryWalker = walker.NewFileSystemWalker(true, 0, options...)
+	if withAncestors {
+		checker := ancestors.NewChecker()
+		directoryWalker = checker.Walker(directoryWalker)
+		folderSanitizer = checker.Sanitizer(folderSanitizer)
+	}
+
+	// Case conflicts only matter on targets that don't tell the spellings apart
+	var auditor *casing.Auditor
+	if !profile.CaseSensitive {
+		if auditor, err = casing.NewAuditor(caseCanonical); err != nil {
+			return fmt.Errorf("--case-canonical: %w", err)
+		}
+		directoryWalker = auditor.Walker(directoryWalker)
+	}
+
+	// Check mode only needs the sanitizer and walker; nothing is renamed or reported live
+	checkService := service.NewSanitizeService(
+		folderSanitizer,
+		directoryWalker,
+		nil,
+		nil,
+		service.WithRiskAssessor(assessor),
+	)
+
+	report, err := checkService.CheckDirectory(absPath)
+	if err != nil {
+		return fmt.Errorf("error during check: %w", err)
+	}
+
+	// Anonymized reports keep only the offending characters of each name
+	var anonymizer *paths.Anonymizer
+	if anonymize {
+		anonymizer = paths.NewAnonymizer(anonymizeKey, sanitizer.IsViolatingRune)
+	}
+
+	sortViolations(report.Violations, collator)
+	sortRisks(report.Risks, collator)
+	var conflicts []casing.Conflict
+	if auditor != nil {
+		conflicts = auditor.Conflicts()
+	}
+	if checkFormat == cireport.FormatText {
+		printCheckReport(cmd, report, absPath, anonymizer, collator)
+		printRiskReport(cmd, report.Risks, absPath, anonymizer)
+		printCaseConflicts(cmd, conflicts, absPath, anonymizer)
+	} else if err := writeCIReport(cmd, report, conflicts); err != nil {
+		return fmt.Errorf("error writing %s report: %w", checkFormat, err)
+	}
+	printCheckWarnings(cmd, report.Warnings, anonymizer != nil)
+
+	if len(report.Violations) > 0 || len(conflicts) > 0 {
+		return withExitCode(exitChanged, errViolationsFound)
+	}
+
+	return nil
+}
+
+// printCheckReport writes each violation and a closing summary line to stdout
+// A non-nil anonymizer replaces every path component and suggested name with a pseudonym
+func printCheckReport(cmd *cobra.Command, report *interfaces.CheckReport, root string, anonymizer *paths.Anonymizer, collator *collation.Collator) {
+	out := cmd.OutOrStdout()
+
+	// With relative paths the root is printed once as a header
+	if relativePaths {
+		header := root
+		if anonymizer != nil {
+			header = anonymizer.Path(root)
+		}
+		fmt.Fprintf(out, "Root: %s\n\n", header)
+	}
+
+	for _, violation := range report.Violations {
+		violationPath := violation.Path
+		suggestedName := violation.SanitizedName
+		if relativePaths {
+			violationPath = paths.Relative(root, violationPath)
+		}
+		if anonymizer != nil {
+			violationPath = anonymizer.Path(violationPath)
+			suggestedName = anonymizer.Component(suggestedName)
+		}
+		fmt.Fprintf(out, "%s\n", violationPath)
+		if violation.SanitizedName == violation.Name {
+			fmt.Fprintln(out, "  suggested name: (none, rename manually)")
+		} else {
+			fmt.Fprintf(out, "  suggested name: %s\n", suggestedName)
+		}
+		if len(violation.Rules) > 0 {
+			fmt.Fprintf(out, "  violates: %s\n", strings.Join(violation.Rules, ", "))
+		}
+	}
+
+	if len(report.Violations) == 0 {
+		fmt.Fprintf(out, "All %d folder names are compliant.\n", report.TotalFolders)
+		return
+	}
+
+	fmt.Fprintf(out, "\n%d of %d folder names are non-compliant.\n", len(report.Violations), report.TotalFolders)
+
+	printOwnerCounts(out, report.Violations, anonymizer, collator)
+}
+
+// writeCIReport writes violations and case conflicts as errors and risks as warnings in the --format of a CI system
+func writeCIReport(cmd *cobra.Command, report *interfaces.CheckReport, conflicts []casing.Conflict) error {
+	var findings []cireport.Finding
+	for _, violation := range report.Violations {
+		suggestion := fmt.Sprintf("rename it to %q", violation.SanitizedName)
+		if violation.SanitizedName == violation.Name {
+			suggestion = "rename it manually"
+		}
+		rules := violation.Rules
+		if len(rules) == 0 {
+			rules = []string{nonCompliantRule}
+		}
+		for _, rule := range rules {
+			findings = append(findings, cireport.Finding{
+				Path:    findingPath(violation.Path),
+				Rule:    rule,
+				Level:   cireport.LevelError,
+				Message: fmt.Sprintf("Folder name %q violates %s: %s; %s", violation.Name, rule, sanitizer.RuleDescription(rule), suggestion),
+			})
+		}
+	}
+	for _, conflict := range conflicts {
+		findings = append(findings, cireport.Finding{
+			Path:    findingPath(conflict.Parent),
+			Rule:    caseConflictRule,
+			Level:   cireport.LevelError,
+			Message: fmt.Sprintf("Folders %s differ only in case and collide on %s targets; merge them into %q", strings.Join(conflict.Names, ", "), profileName, conflict.Canonical),
+		})
+	}
+	for _, entry := range report.Risks {
+		for _, id := range entry.Risks {
+			findings = append(findings, cireport.Finding{
+				Path:    findingPath(entry.Path),
+				Rule:    id,
+				Level:   cireport.LevelWarning,
+				Message: fmt.Sprintf("Folder name %q complies but is risky: %s", entry.Name, risk.Description(id)),
+			})
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if checkFormat == cireport.FormatGitHub {
+		return cireport.WriteGitHub(out, findings)
+	}
+
+	// Violations and risks share one list of rules; only the ones with findings end up in the log
+	rules := []cireport.Rule{
+		{ID: caseConflictRule, Description: "siblings whose names differ only in case, which collide on case-insensitive targets"},
+		{ID: nonCompliantRule, Description: "name doesn't comply with the naming rules"},
+	}
+	for _, finding := range findings {
+		description := sanitizer.RuleDescription(finding.Rule)
+		if finding.Level == cireport.LevelWarning {
+			description = risk.Description(finding.Rule)
+		}
+		rules = append(rules, cireport.Rule{ID: finding.Rule, Description: description})
+	}
+	return cireport.WriteSARIF(out, cireport.Tool{Name: "sanitize", InformationURI: "https://github.com/punkscience/sanitize"}, rules, findings)
+}
+
+// findingPath returns a path the way CI systems resolve annotations: slash-separated and relative to the working directory
+// Paths outside the working directory stay absolute
+func findingPath(path string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if relative, err := filepath.Rel(wd, path); err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+			path = relative
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// newRiskAssessor creates the risk assessor configured by the risk flags
+// The path limit is the one of the selected profile, planned for --path-budget-prefix like the path limit rule
+func newRiskAssessor() (*risk.Assessor, error) {
+	if riskNameLength < 0 || riskPathHeadroom < 0 {
+		return nil, fmt.Errorf("--risk-name-length and --risk-path-headroom must not be negative")
+	}
+	if err := risk.Validate(ignoreRisks); err != nil {
+		return nil, fmt.Errorf("--ignore-risk: %w", err)
+	}
+	profile, err := sanitizer.LookupProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return risk.NewAssessor(
+		risk.WithNameLength(riskNameLength),
+		risk.WithPathHeadroom(riskPathHeadroom, max(sanitizer.PathBudget(profile.MaxPathLength, budgetPrefix), 0)),
+		risk.WithIgnored(ignoreRisks),
+	), nil
+}
+
+// printRiskReport writes each risky name, what its risks mean and a closing summary line to stdout
+// Nothing is printed without risks, so reports of risk-free trees look like before
+func printRiskReport(cmd *cobra.Command, risks []interfaces.Risk, root string, anonymizer *paths.Anonymizer) {
+	if len(risks) == 0 {
+		return
+	}
+	out := cmd.OutOrStdout()
+
+	fmt.Fprintln(out, "\nRisk warnings (names that comply but may still cause trouble):")
+	seen := make(map[string]bool)
+	for _, entry := range risks {
+		riskPath := entry.Path
+		name := entry.Name
+		if relativePaths {
+			riskPath = paths.Relative(root, riskPath)
+		}
+		if anonymizer != nil {
+			riskPath = anonymizer.Path(riskPath)
+			name = anonymizer.Component(name)
+		}
+		fmt.Fprintf(out, "%s\n", riskPath)
+		if entry.Name != filepath.Base(entry.Path) {
+			fmt.Fprintf(out, "  after renaming to: %s\n", name)
+		}
+		fmt.Fprintf(out, "  risks: %s\n", strings.Join(entry.Risks, ", "))
+		for _, id := range entry.Risks {
+			seen[id] = true
+		}
+	}
+
+	fmt.Fprintln(out)
+	for _, id := range risk.Names() {
+		if seen[id] {
+			fmt.Fprintf(out, "%s: %s\n", id, risk.Description(id))
+		}
+	}
+	fmt.Fprintf(out, "\n%d folder names are risky.\n", len(risks))
+}
+
+// printCaseConflicts writes each group of siblings that differ only in case and the spelling they would be merged into
+// Nothing is printed without conflicts, so reports of case-insensitive trees look like before
+func printCaseConflicts(cmd *cobra.Command, conflicts []casing.Conflict, root string, anonymizer *paths.Anonymizer) {
+	if len(conflicts) == 0 {
+		return
+	}
+	out := cmd.OutOrStdout()
+
+	fmt.Fprintf(out, "\nCase conflicts (siblings that collide on %s targets):\n", profileName)
+	for _, conflict := range conflicts {
+		parent := conflict.Parent
+		names := append([]string{}, conflict.Names...)
+		canonical := conflict.Canonical
+		if relativePaths {
+			parent = paths.Relative(root, parent)
+		}
+		if anonymizer != nil {
+			parent = anonymizer.Path(parent)
+			for i, name := range names {
+				names[i] = anonymizer.Component(name)
+			}
+			canonical = anonymizer.Component(canonical)
+		}
+		fmt.Fprintf(out, "%s\n", parent)
+		fmt.Fprintf(out, "  %s -> %s\n", strings.Join(names, ", "), canonical)
+	}
+
+	fmt.Fprintf(out, "\n%d case conflicts found. Run with --consolidate-case to merge them.\n", len(conflicts))
+}
+
+// printCheckWarnings writes the problems that did not stop the check to stderr so stdout stays a clean report
+// Anonymized reports only give the count because warning messages contain real paths
+func printCheckWarnings(cmd *cobra.Command, warnings []error, anonymized bool) {
+	out := cmd.ErrOrStderr()
+	if anonymized && len(warnings) > 0 {
+		fmt.Fprintf(out, "Warnings: %d (details hidden by --anonymize)\n", len(warnings))
+		return
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(out, "Warning: %v\n", warning)
+	}
+}
+
+// sortViolations orders violations deepest first and then by path in collation order
+// Without a collator the processing order is kept, which already sorts paths in byte order within each depth
+func sortViolations(violations []interfaces.Violation, collator *collation.Collator) {
+	if collator == nil {
+		return
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return deeperOrBefore(violations[i].Path, violations[j].Path, collator)
+	})
+}
+
+// sortRisks orders risky names like sortViolations
+func sortRisks(risks []interfaces.Risk, collator *collation.Collator) {
+	if collator == nil {
+		return
+	}
+
+	sort.SliceStable(risks, func(i, j int) bool {
+		return deeperOrBefore(risks[i].Path, risks[j].Path, collator)
+	})
+}
+
+// deeperOrBefore reports whether path a is deeper than b, or at the same depth and first in collation order
+func deeperOrBefore(a, b string, collator *collation.Collator) bool {
+	if depthA, depthB := strings.Count(a, string(filepath.Separator)), strings.Count(b, string(filepath.Separator)); depthA != depthB {
+		return depthA > depthB
+	}
+	return collator.ComparePaths(a, b) < 0
+}
+
+// printOwnerCounts lists the number of violations per folder owner, most violations first
+// Nothing is printed unless the walker attributed owners
+func printOwnerCounts(out io.Writer, violations []interfaces.Violation, anonymizer *paths.Anonymizer, collator *collation.Collator) {
+	counts := make(map[string]int)
+	for _, violation := range violations {
+		if violation.Owner == "" {
+			continue
+		}
+		owner := violation.Owner
+		if anonymizer != nil {
+			owner = anonymizer.Component(owner)
+		}
+		counts[owner]++
+	}
+	if len(counts) == 0 {
+		return
+	}
+
+	owners := make([]string, 0, len(counts))
+	for owner := range counts {
+		owners = append(owners, owner)
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		if counts[owners[i]] != counts[owners[j]] {
+			return counts[owners[i]] > counts[owners[j]]
+		}
+		return collator.Compare(owners[i], owners[j]) < 0
+	})
+
+	fmt.Fprintln(out, "\nBy owner:")
+	for _, owner := range owners {
+		fmt.Fprintf(out, "  %s: %d non-compliant\n", owner, counts[owner])
+	}
+}
+
+// init registers the check subcommand and its flags
+func init() {
+	checkCmd.Flags().BoolVar(&anonymize, "anonymize", false, "Replace path components with stable pseudonyms, keeping only the violating characters")
+	checkCmd.Flags().StringVar(&anonymizeKey, "anonymize-key", "", "Key for pseudonyms so they stay stable across runs (default: random per run)")
+	checkCmd.Flags().IntVar(&riskNameLength, "risk-name-length", risk.DefaultNameLength, "Warn about names longer than this many characters (0 = never)")
+	checkCmd.Flags().IntVar(&riskPathHeadroom, "risk-path-headroom", risk.DefaultPathHeadroom, "Warn about paths leaving fewer characters than this below the profile's path limit (0 = never)")
+	checkCmd.Flags().StringArrayVar(&ignoreRisks, "ignore-risk", nil, "Don't warn about this risk (repeatable): "+strings.Join(risk.Names(), ", "))
+	checkCmd.Flags().StringVar(&checkFormat, "format", cireport.FormatText, "Output format: text, sarif (SARIF 2.1.0 for code scanning) or github (GitHub Actions annotations)")
+	addCaseCanonicalFlag(checkCmd)
+	rootCmd.AddCommand(checkCmd)
+}
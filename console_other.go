@@ -0,0 +1,8 @@
+//go:build !windows
+
+package main
+
+// prepareConsole reports what the terminal can display; terminals outside Windows handle escapes and UTF-8
+func prepareConsole() consoleSupport {
+	return consoleSupport{escapes: true, unicode: true}
+}
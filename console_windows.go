@@ -0,0 +1,34 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// cpUTF8 is the identifier of the UTF-8 console code page
+const cpUTF8 = 65001
+
+// prepareConsole enables ANSI escape sequences in the Windows console and reports what it can display
+// Consoles that refuse virtual terminal processing, like conhost before Windows 10, get neither colors nor the TUI;
+// legacy code pages such as 437 or 850 get ASCII decorations, because emoji and box drawing turn into garbage there
+func prepareConsole() consoleSupport {
+	support := consoleSupport{escapes: true, unicode: true}
+
+	handle := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(handle, &mode); err != nil {
+		return support // Redirected, or a terminal emulator such as mintty that handles escapes itself
+	}
+	if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING == 0 {
+		if err := windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+			support.escapes = false
+		}
+	}
+	if codePage, err := windows.GetConsoleOutputCP(); err == nil && codePage != cpUTF8 {
+		support.unicode = false
+	}
+	return support
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/service"
+)
+
+// Exit codes, so scripts can tell "nothing to do" from "renamed 500 folders" and "half the renames failed"
+const (
+	exitClean   = 0 // Nothing was changed, and nothing needs to be
+	exitChanged = 1 // Folders were renamed, or check found names that need to change
+	exitErrors  = 2 // The run completed, but some folders failed or it stopped early
+	exitFatal   = 3 // The command could not run, or its results could not be saved
+	exitRefused = 4 // A safety check refused the run before anything was renamed (rename limits, no confirmation)
+)
+
+// exitStatus is the exit code of a command that succeeded; commands that renamed folders raise it to exitChanged
+var exitStatus = exitClean
+
+// declined records that the user answered no when a real run asked for confirmation
+// The run stops before renaming anything, as asked, so it exits clean instead of with exitErrors
+var declined bool
+
+// exitError is an error with an exit code other than exitFatal
+type exitError struct {
+	code int
+	err  error
+}
+
+// Error returns the message of the wrapped error
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode attaches an exit code to err
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the exit code for the error a command returned; errors without an exit code are fatal
+func exitCode(err error) int {
+	var coded *exitError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return exitFatal
+}
+
+// runOutcome turns the outcome of a run into the command's result and records its exit status
+// A run without a summary never got past the walk; dry runs change nothing, so they only fail on errors
+// Safety refusals stop the run before the first rename, so they get exitRefused rather than exitErrors;
+// a run the user declined to confirm renamed nothing on purpose, so it is clean
+func runOutcome(summary *interfaces.ProcessingSummary, err error) error {
+	switch {
+	case summary == nil:
+		if err == nil {
+			return nil
+		}
+		return fmt.Errorf("error during sanitization: %w", err)
+	case summary.Aborted && (errors.Is(err, service.ErrNotConfirmed) || errors.Is(err, service.ErrTooManyRenames)):
+		if declined {
+			return nil
+		}
+		return withExitCode(exitRefused, fmt.Errorf("nothing was renamed: %w", err))
+	case summary.ErrorCount > 0 || summary.Aborted:
+		if err == nil {
+			err = fmt.Errorf("completed with %d errors", summary.ErrorCount)
+		}
+		return withExitCode(exitErrors, fmt.Errorf("error during sanitization: %w", err))
+	case err != nil:
+		return fmt.Errorf("error during sanitization: %w", err)
+	case !summary.DryRun && summary.Phases.Applied > 0:
+		exitStatus = exitChanged
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Tests for the exit codes of commands.
+// This test suite ensures scripts can tell clean runs, changes, errors and fatal errors apart.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/service"
+)
+
+// TestRunOutcome tests the result and exit status of dry-run, applied and failed summaries
+func TestRunOutcome(t *testing.T) {
+	tests := []struct {
+		name       string
+		summary    *interfaces.ProcessingSummary
+		err        error
+		wantCode   int
+		wantStatus int
+	}{
+		{"no summary", nil, nil, exitClean, exitClean},
+		{"walk failed", nil, errors.New("no such directory"), exitFatal, exitClean},
+		{"dry run", &interfaces.ProcessingSummary{DryRun: true, Phases: interfaces.PhaseCounts{Planned: 3, WouldRename: 3}}, nil, exitClean, exitClean},
+		{"nothing to rename", &interfaces.ProcessingSummary{Phases: interfaces.PhaseCounts{Skipped: 4}}, nil, exitClean, exitClean},
+		{"applied", &interfaces.ProcessingSummary{Phases: interfaces.PhaseCounts{Planned: 3, Applied: 3}}, nil, exitClean, exitChanged},
+		{"failed renames", &interfaces.ProcessingSummary{ErrorCount: 1, Phases: interfaces.PhaseCounts{Planned: 3, Applied: 2, Failed: 1}}, nil, exitErrors, exitClean},
+		{"dry run with errors", &interfaces.ProcessingSummary{DryRun: true, ErrorCount: 1}, nil, exitErrors, exitClean},
+		{"aborted", &interfaces.ProcessingSummary{Aborted: true, AbortReason: "interrupted"}, nil, exitErrors, exitClean},
+		{"journal not saved", &interfaces.ProcessingSummary{Phases: interfaces.PhaseCounts{Planned: 1, Applied: 1}}, errors.New("disk full"), exitFatal, exitClean},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exitStatus = exitClean
+			defer func() { exitStatus = exitClean }()
+
+			err := runOutcome(tt.summary, tt.err)
+			code := exitClean
+			if err != nil {
+				code = exitCode(err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d (error: %v)", tt.wantCode, code, err)
+			}
+			if exitStatus != tt.wantStatus {
+				t.Errorf("Expected exit status %d, got %d", tt.wantStatus, exitStatus)
+			}
+		})
+	}
+}
+
+// TestRunOutcome_Refused tests that safety refusals get their own exit code, and declined confirmations exit clean
+func TestRunOutcome_Refused(t *testing.T) {
+	notConfirmed := fmt.Errorf("%w: the renames were not confirmed", service.ErrNotConfirmed)
+	tooMany := fmt.Errorf("%w: 12 planned renames exceed the limit of 10", service.ErrTooManyRenames)
+	tests := []struct {
+		name     string
+		declined bool
+		summary  *interfaces.ProcessingSummary
+		err      error
+		wantCode int
+	}{
+		{"declined plan", true, &interfaces.ProcessingSummary{Aborted: true, AbortReason: "the renames were not confirmed"}, notConfirmed, exitClean},
+		{"declined rename limit", true, &interfaces.ProcessingSummary{Aborted: true, AbortReason: "12 planned renames exceed the limit of 10"}, tooMany, exitClean},
+		{"unattended without --yes", false, &interfaces.ProcessingSummary{Aborted: true, AbortReason: "the renames were not confirmed"}, notConfirmed, exitRefused},
+		{"unattended over the rename limit", false, &interfaces.ProcessingSummary{Aborted: true, AbortReason: "12 planned renames exceed the limit of 10"}, tooMany, exitRefused},
+		{"declined, then interrupted", true, &interfaces.ProcessingSummary{Aborted: true, AbortReason: "stopped by the user"}, fmt.Errorf("%w: stopped by the user", service.ErrInterrupted), exitErrors},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			declined = tt.declined
+			exitStatus = exitClean
+			defer func() { declined, exitStatus = false, exitClean }()
+
+			err := runOutcome(tt.summary, tt.err)
+			code := exitClean
+			if err != nil {
+				code = exitCode(err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d (error: %v)", tt.wantCode, code, err)
+			}
+			if exitStatus != exitClean {
+				t.Errorf("Expected exit status %d, got %d", exitClean, exitStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/diff"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+)
+
+// explainCmd shows step by step how names are sanitized
+var explainCmd = &cobra.Command{
+	Use:   "explain NAME...",
+	Short: "Show which rules change a name and what each of them changes",
+	Long: `Explain sanitizes each NAME with the current naming policy (--profile,
+--replacement, --reserved-words and the other naming flags) and shows the result
+with a character-level diff, followed by every rule that fired in the order
+it was applied and what it changed. Nothing on disk is touched.
+
+Changed characters are marked [-removed-]{+added+}. Control characters and
+other invisible characters are shown as escape sequences.`,
+	Example: `  sanitize explain "Report: Q1 <draft>. "
+  sanitize explain --profile fat32-8.3 "Holiday Photos 2024"`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExplain,
+}
+
+// runExplain prints the explanation of every argument
+func runExplain(cmd *cobra.Command, args []string) error {
+	folderSanitizer, err := newFolderSanitizer()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	for i, name := range args {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		explainName(out, folderSanitizer, name)
+	}
+	return nil
+}
+
+// explainName writes the sanitized name, the overall diff and every step of one name
+func explainName(out io.Writer, folderSanitizer interfaces.FolderSanitizer, name string) {
+	sanitized, steps := traceName(folderSanitizer, name)
+
+	fmt.Fprintf(out, "Name:      %q\n", name)
+	fmt.Fprintf(out, "Sanitized: %q\n", sanitized)
+	if len(steps) == 0 {
+		fmt.Fprintln(out, "No rules applied; the name is already compliant.")
+		return
+	}
+	fmt.Fprintf(out, "Changes:   %s\n", inlineDiff(name, sanitized))
+
+	fmt.Fprintf(out, "Rules (%d):\n", len(steps))
+	for i, step := range steps {
+		fmt.Fprintf(out, "  %d. %s: %s\n", i+1, step.Rule, sanitizer.RuleDescription(step.Rule))
+		switch {
+		case step.Before == "" && step.After == "":
+			// The sanitizer only reports rule identifiers
+		case step.Before == step.After:
+			fmt.Fprintln(out, "     reported only, name kept")
+		default:
+			fmt.Fprintf(out, "     %s\n", inlineDiff(step.Before, step.After))
+		}
+	}
+}
+
+// traceName uses the richest interface the sanitizer supports; without NameTracer only the rules are known
+func traceName(folderSanitizer interfaces.FolderSanitizer, name string) (string, []interfaces.RuleStep) {
+	if tracer, ok := folderSanitizer.(interfaces.NameTracer); ok {
+		return tracer.TraceName(name)
+	}
+
+	explainer, ok := folderSanitizer.(interfaces.NameExplainer)
+	if !ok {
+		return folderSanitizer.SanitizeName(name), nil
+	}
+	sanitized, rules := explainer.ExplainName(name)
+	steps := make([]interfaces.RuleStep, 0, len(rules))
+	for _, rule := range rules {
+		steps = append(steps, interfaces.RuleStep{Rule: rule})
+	}
+	return sanitized, steps
+}
+
+// inlineDiff marks the characters that differ between before and after as [-removed-]{+added+}
+func inlineDiff(before, after string) string {
+	var builder strings.Builder
+	for _, segment := range diff.Runes(before, after) {
+		text := visible(segment.Text)
+		switch segment.Op {
+		case diff.Delete:
+			builder.WriteString("[-" + text + "-]")
+		case diff.Insert:
+			builder.WriteString("{+" + text + "+}")
+		default:
+			builder.WriteString(text)
+		}
+	}
+	return builder.String()
+}
+
+// visible replaces characters that don't show up in a terminal with Go escape sequences
+func visible(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		if r == ' ' || (unicode.IsGraphic(r) && !unicode.Is(unicode.Cf, r)) {
+			builder.WriteRune(r)
+			continue
+		}
+		quoted := strconv.QuoteRuneToASCII(r)
+		builder.WriteString(quoted[1 : len(quoted)-1])
+	}
+	return builder.String()
+}
+
+// init registers the explain subcommand
+func init() {
+	rootCmd.AddCommand(explainCmd)
+}
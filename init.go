@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/config"
+	"github.com/punkscience/sanitize/internal/reporter"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+	"github.com/punkscience/sanitize/internal/wizard"
+)
+
+// Flags for the init subcommand
+var (
+	initOutput string // Where the wizard writes the policy file
+	initForce  bool   // Overwrite an existing policy file
+)
+
+// sampleFolderKey is the wizard answer that selects the sample dry run folder; it is not written to the policy
+const sampleFolderKey = "path"
+
+// exampleName shows the effect of the replacement choices
+const exampleName = "Q1: Budget?"
+
+// initCmd asks a few questions and writes a naming policy for them
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a naming policy with an interactive wizard",
+	Long: `Init asks about the target platform, how invalid characters are replaced,
+which directories to leave alone and how cautious real runs should be, then
+writes the answers to a policy file and shows a sample dry run with it.
+
+Use the policy with --config on any command. The file is plain "flag: value"
+lines and can be edited by hand afterwards.`,
+	Example: `  sanitize init
+  sanitize init --output /etc/sanitize/policy.yaml --path /srv/share`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+// runInit runs the wizard, writes the policy and previews it on the sample folder
+func runInit(cmd *cobra.Command, args []string) error {
+	if _, err := os.Stat(initOutput); err == nil && !initForce {
+		return fmt.Errorf("%s already exists (use --force to overwrite it)", initOutput)
+	}
+
+	answers, err := wizard.Run("Sanitize setup", initQuestions())
+	if errors.Is(err, wizard.ErrCancelled) {
+		fmt.Println("Setup cancelled, nothing was written.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	sampleFolder := answers[sampleFolderKey]
+	policy := config.Policy{}
+	for key, value := range answers {
+		if key != sampleFolderKey && value != "" {
+			policy[key] = value
+		}
+	}
+	// An empty replacement removes invalid characters, so it is kept unlike other empty answers
+	policy["replacement"] = answers["replacement"]
+
+	content := append([]byte("# Naming policy written by \"sanitize init\"\n"), config.Format(policy)...)
+	if err := os.WriteFile(initOutput, content, 0644); err != nil {
+		return fmt.Errorf("failed to write policy to %s: %w", initOutput, err)
+	}
+	fmt.Printf("Wrote %s. Use it with: sanitize --config %s\n\n", initOutput, initOutput)
+
+	// The sample dry run uses the answers even where flags were given to init itself
+	scanCmd.InheritedFlags()
+	for key, value := range policy {
+		if err := scanCmd.Flags().Set(key, value); err != nil {
+			return fmt.Errorf("policy value %q for %q is invalid: %w", value, key, err)
+		}
+	}
+	rootPath = sampleFolder
+	verbosity = max(verbosity, reporter.VerbosityInfo) // List every folder the policy would rename
+
+	fmt.Printf("Sample dry run of %s:\n", sampleFolder)
+	return runTree(true, "")
+}
+
+// initQuestions returns the wizard steps; each key except the sample folder is the flag it sets
+func initQuestions() []wizard.Question {
+	profileOptions := make([]wizard.Option, 0)
+	for _, name := range sanitizer.ProfileNames() {
+		profile, _ := sanitizer.LookupProfile(name)
+		profileOptions = append(profileOptions, wizard.Option{Label: name, Value: name, Description: profile.Description})
+	}
+
+	return []wizard.Question{
+		{
+			Key:     "profile",
+			Title:   "Where will the folders be used?",
+			Help:    "Stricter targets replace more characters and shorten longer paths.",
+			Options: profileOptions,
+			Default: sanitizer.DefaultProfile,
+		},
+		{
+			Key:   "replacement",
+			Title: "How should invalid characters be replaced?",
+			Options: []wizard.Option{
+				replacementOption("Underscore", "_"),
+				replacementOption("Hyphen", "-"),
+				replacementOption("Remove them", ""),
+			},
+			Default: sanitizer.DefaultReplacements().InvalidChar,
+		},
+		{
+			Key:   "no-default-protection",
+			Title: "Leave tool-owned directories alone?",
+			Help:  "Renaming .git, .svn, .hg, node_modules, __pycache__, .venv or target breaks the tools that own them.",
+			Options: []wizard.Option{
+				{Label: "Yes", Value: "false", Description: "never rename them or anything inside them"},
+				{Label: "No", Value: "true", Description: "sanitize them like any other folder"},
+			},
+			Default: "false",
+		},
+		{
+			Key:   "protect",
+			Title: "Another directory name to leave alone (optional)",
+			Help:  "For example vendor or build. Add more later with --protect.",
+		},
+		{
+			Key:   "merge",
+			Title: "What if the sanitized name already exists?",
+			Options: []wizard.Option{
+				{Label: "Keep both", Value: "false", Description: "append _1, _2, ... to the new name"},
+				{Label: "Merge", Value: "true", Description: "move the contents into the existing folder"},
+			},
+			Default: "false",
+		},
+		{
+			Key:   "max-errors",
+			Title: "When should a real run give up?",
+			Help:  "Many errors in a row usually mean a permission or network problem.",
+			Options: []wizard.Option{
+				{Label: "After 10 errors", Value: "10"},
+				{Label: "After 100 errors", Value: "100"},
+				{Label: "Never", Value: "0", Description: "process every folder regardless of errors"},
+			},
+			Default: "10",
+		},
+		{
+			Key:     sampleFolderKey,
+			Title:   "Which folder should the sample dry run use?",
+			Help:    "Nothing is renamed; the run only shows what the policy would change.",
+			Default: rootPath,
+		},
+	}
+}
+
+// replacementOption describes a replacement by its effect on an example name
+func replacementOption(label, replacement string) wizard.Option {
+	replacements := sanitizer.DefaultReplacements()
+	replacements.InvalidChar = replacement
+	example := sanitizer.NewWindowsSanitizer(sanitizer.WithReplacements(replacements)).SanitizeName(exampleName)
+
+	return wizard.Option{
+		Label:       label,
+		Value:       replacement,
+		Description: fmt.Sprintf("%q becomes %q", exampleName, example),
+	}
+}
+
+// init registers the init subcommand and its flags
+func init() {
+	initCmd.Flags().StringVarP(&initOutput, "output", "o", "sanitize.yaml", "File to write the policy to")
+	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "Overwrite an existing policy file")
+
+	rootCmd.AddCommand(initCmd)
+}
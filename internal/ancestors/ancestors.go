@@ -0,0 +1,114 @@
+// Package ancestors checks the root of a run and the directories above it for trailing spaces and periods.
+// A compliant folder can still be unusable on Windows when it lives below an ancestor like "Projects.".
+package ancestors
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+)
+
+// Checker implements a walker decorator that adds the root and its ancestors to every walk,
+// and a sanitizer decorator that only trims their trailing spaces and periods
+// The walker has to run before the sanitizer sees the first folder, which the service guarantees
+type Checker struct {
+	// names holds the names of the root (depth 0) and its ancestors (negative depths) in the most recent walk
+	// They are found by depth, since renaming an ancestor first (top-down order) changes the paths below it
+	names map[int]string
+}
+
+// NewChecker creates a Checker
+func NewChecker() *Checker {
+	return &Checker{names: make(map[int]string)}
+}
+
+// Walker wraps a walker so every walk also returns the root and each directory above it, up to the volume root
+// They come after the folders below the root, deepest first, at depths 0, -1, -2 and so on
+func (c *Checker) Walker(next interfaces.DirectoryWalker) interfaces.DirectoryWalker {
+	return &ancestorWalker{next: next, checker: c}
+}
+
+// Sanitizer wraps a sanitizer so the root and its ancestors only lose trailing spaces and periods
+// Their names are outside the tree the run was asked to clean up, so no other rule is applied to them
+func (c *Checker) Sanitizer(next interfaces.FolderSanitizer) interfaces.FolderSanitizer {
+	return &trailingSanitizer{next: next, checker: c}
+}
+
+// ancestorWalker appends the root and its ancestors to the folders of the wrapped walker
+type ancestorWalker struct {
+	next    interfaces.DirectoryWalker
+	checker *Checker
+}
+
+// Walk walks the tree and appends the root and its ancestors that the wrapped walker didn't return itself
+func (aw *ancestorWalker) Walk(rootPath string) ([]interfaces.FolderInfo, error) {
+	folders, err := aw.next.Walk(rootPath)
+	if err != nil {
+		return folders, err
+	}
+
+	walked := make(map[string]bool, len(folders))
+	for _, folder := range folders {
+		walked[folder.Path] = true
+	}
+
+	aw.checker.names = make(map[int]string)
+	depth := 0
+	for path := filepath.Clean(rootPath); filepath.Dir(path) != path; path = filepath.Dir(path) {
+		aw.checker.names[depth] = filepath.Base(path)
+		if !walked[path] {
+			folders = append(folders, interfaces.FolderInfo{
+				Path:   path,
+				Name:   filepath.Base(path),
+				Depth:  depth,
+				Parent: filepath.Dir(path),
+			})
+		}
+		depth--
+	}
+	return folders, nil
+}
+
+// Warnings forwards the problems of the wrapped walker's most recent walk, if it collects them
+func (aw *ancestorWalker) Warnings() []error {
+	if warningWalker, ok := aw.next.(interfaces.WarningWalker); ok {
+		return warningWalker.Warnings()
+	}
+	return nil
+}
+
+// ObserveScan forwards the observer to the wrapped walker if it reports its scan
+func (aw *ancestorWalker) ObserveScan(observe func(scanned int, path string)) {
+	if scanningWalker, ok := aw.next.(interfaces.ScanningWalker); ok {
+		scanningWalker.ObserveScan(observe)
+	}
+}
+
+// trailingSanitizer implements FolderSanitizer and FolderExplainer
+// This struct trims the root and its ancestors and passes every other folder to the wrapped sanitizer
+type trailingSanitizer struct {
+	next    interfaces.FolderSanitizer
+	checker *Checker
+}
+
+// SanitizeName sanitizes a bare name with the wrapped sanitizer; without a path it can't be an ancestor
+func (ts *trailingSanitizer) SanitizeName(name string) string {
+	return ts.next.SanitizeName(name)
+}
+
+// ExplainFolder trims trailing spaces and periods from the root and its ancestors and applies the wrapped sanitizer to every other folder
+// A name that would be empty without them is left alone
+// This method implements the FolderExplainer interface
+func (ts *trailingSanitizer) ExplainFolder(folder interfaces.FolderInfo) (string, []string) {
+	if name, ok := ts.checker.names[folder.Depth]; !ok || name != folder.Name {
+		return interfaces.Explain(ts.next, folder)
+	}
+
+	trimmed := strings.TrimRight(folder.Name, ". ")
+	if trimmed == folder.Name || trimmed == "" {
+		return folder.Name, nil
+	}
+	return trimmed, []string{sanitizer.RuleTrailingPeriod}
+}
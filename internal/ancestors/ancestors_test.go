@@ -0,0 +1,59 @@
+// Package ancestors_test provides tests for the root and ancestor checks.
+// This test suite ensures the directories above a run are listed and only lose trailing spaces and periods.
+package ancestors_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/ancestors"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+	"github.com/punkscience/sanitize/internal/walker"
+)
+
+// TestChecker tests that the root and its ancestors follow the walked folders and are only trimmed
+func TestChecker(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "data", "Projects.", "Café ")
+	walked := interfaces.FolderInfo{Path: filepath.Join(root, "a:b"), Name: "a:b", Depth: 1, Parent: root}
+
+	checker := ancestors.NewChecker()
+	folders, err := checker.Walker(walker.NewListWalker([]interfaces.FolderInfo{walked})).Walk(root)
+	if err != nil {
+		t.Fatalf("Walk() returned error: %v", err)
+	}
+
+	var names []string
+	var depths []int
+	for _, folder := range folders {
+		names = append(names, folder.Name)
+		depths = append(depths, folder.Depth)
+	}
+	if want := []string{"a:b", "Café ", "Projects.", "data"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Walk() returned %v, want %v", names, want)
+	}
+	if want := []int{1, 0, -1, -2}; !reflect.DeepEqual(depths, want) {
+		t.Errorf("Walk() returned depths %v, want %v", depths, want)
+	}
+
+	s := checker.Sanitizer(sanitizer.NewWindowsSanitizer()).(interfaces.FolderExplainer)
+	tests := []struct {
+		folder    interfaces.FolderInfo
+		wantName  string
+		wantRules []string
+	}{
+		{folders[0], "a_b", []string{sanitizer.RuleInvalidCharacters}},
+		{folders[1], "Café", []string{sanitizer.RuleTrailingPeriod}}, // Only trimmed, not transliterated
+		{folders[2], "Projects", []string{sanitizer.RuleTrailingPeriod}},
+		{folders[3], "data", nil},
+		// An ancestor renamed first (top-down) moves the root, which is still recognized
+		{interfaces.FolderInfo{Path: filepath.Join(string(filepath.Separator), "data", "Projects", "Café "), Name: "Café ", Depth: 0}, "Café", []string{sanitizer.RuleTrailingPeriod}},
+	}
+	for _, tt := range tests {
+		name, rules := s.ExplainFolder(tt.folder)
+		if name != tt.wantName || !reflect.DeepEqual(rules, tt.wantRules) {
+			t.Errorf("ExplainFolder(%q) = %q %v, want %q %v", tt.folder.Path, name, rules, tt.wantName, tt.wantRules)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Package audit writes a compliance log of every rename with checksums of the renamed directory's listing.
+// This implementation follows the Decorator pattern by wrapping an existing FolderProcessor.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Entry is one audited rename, written as a single JSON line
+type Entry struct {
+	Time          time.Time `json:"time"`                    // When the rename completed
+	RunID         string    `json:"run_id,omitempty"`        // Identifier of the run that performed the rename
+	User          string    `json:"user"`                    // Account the process ran as
+	OldPath       string    `json:"old_path"`                // Path before the rename
+	NewPath       string    `json:"new_path"`                // Path after the rename
+	Merged        bool      `json:"merged,omitempty"`        // Whether the folder was merged into an existing folder
+	ListingBefore string    `json:"listing_before"`          // Checksum of the directory's entries before the rename
+	ListingAfter  string    `json:"listing_after"`           // Checksum of the directory's entries after the rename
+	ListingError  string    `json:"listing_error,omitempty"` // Why a listing could not be read (its checksum is then empty)
+}
+
+// Log appends an Entry for every rename to a file that is only ever appended to
+// It is safe for concurrent use, so one log can serve a long-running watch
+type Log struct {
+	mu         sync.Mutex
+	file       *os.File
+	fileSystem interfaces.FileSystem
+	runID      string
+	user       string
+	err        error // First failure to write an entry; once set, no further renames are performed
+}
+
+// Open opens the log at path for appending, creating it if needed
+// fileSystem lists the renamed directories; runID is recorded with every entry
+func Open(path string, fileSystem interfaces.FileSystem, runID string) (*Log, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	return &Log{
+		file:       file,
+		fileSystem: fileSystem,
+		runID:      runID,
+		user:       currentUser(),
+	}, nil
+}
+
+// Close closes the log and returns the first failure to write an entry, so an incomplete log is never silent
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	closeErr := l.file.Close()
+	if l.err != nil {
+		return l.err
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to close audit log: %w", closeErr)
+	}
+	return nil
+}
+
+// Processor wraps next so every rename of a real run is audited
+// Dry runs and unchanged names pass through without an entry
+func (l *Log) Processor(next interfaces.FolderProcessor) interfaces.FolderProcessor {
+	return &auditProcessor{next: next, log: l}
+}
+
+// auditProcessor lists a directory before and after handing its rename on
+type auditProcessor struct {
+	next interfaces.FolderProcessor
+	log  *Log
+}
+
+// ProcessRename renames through the wrapped processor and appends an entry for a completed rename
+// Once an entry could not be written, every further rename is refused, as it could not be audited either
+func (p *auditProcessor) ProcessRename(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+	if dryRun || newName == folder.Name {
+		return p.next.ProcessRename(folder, newName, dryRun)
+	}
+	if err := p.log.failure(); err != nil {
+		return nil, fmt.Errorf("%w: %w", err, interfaces.ErrHalted)
+	}
+
+	before, beforeErr := p.log.checksum(folder.Path)
+	result, err := p.next.ProcessRename(folder, newName, dryRun)
+	if err != nil || result == nil || !result.WasRenamed || !result.Success {
+		return result, err
+	}
+	after, afterErr := p.log.checksum(result.NewPath)
+
+	entry := Entry{
+		Time:          time.Now().UTC(),
+		RunID:         p.log.runID,
+		User:          p.log.user,
+		OldPath:       result.OldPath,
+		NewPath:       result.NewPath,
+		Merged:        result.Merged,
+		ListingBefore: before,
+		ListingAfter:  after,
+	}
+	switch {
+	case beforeErr != nil:
+		entry.ListingError = beforeErr.Error()
+	case afterErr != nil:
+		entry.ListingError = afterErr.Error()
+	}
+	p.log.append(entry)
+
+	return result, nil
+}
+
+// failure returns the first failure to write an entry, if any
+func (l *Log) failure() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// append writes an entry as one line; a failure is kept for failure and Close
+func (l *Log) append(entry Entry) {
+	line, err := json.Marshal(entry)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err == nil {
+		// A single write per line keeps concurrent appends from interleaving
+		_, err = l.file.Write(append(line, '\n'))
+	}
+	if err != nil && l.err == nil {
+		l.err = fmt.Errorf("failed to write audit log: %w", err)
+	}
+}
+
+// checksum returns the SHA-256 checksum of a directory's entry names and types, in name order
+// The checksum stays the same across a rename that left the directory's contents intact
+func (l *Log) checksum(path string) (string, error) {
+	entries, err := l.fileSystem.ReadDir(path)
+	if err != nil {
+		return "", err
+	}
+	return Checksum(entries), nil
+}
+
+// Checksum returns the checksum of a directory listing as "sha256:<hex>"
+// Each entry contributes its name and a trailing slash for directories, one per line
+func Checksum(entries []fs.DirEntry) string {
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+		if entry.IsDir() {
+			names[i] += "/"
+		}
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	for _, name := range names {
+		hash.Write([]byte(name))
+		hash.Write([]byte{'\n'})
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// currentUser returns the name of the account the process runs as
+func currentUser() string {
+	if account, err := user.Current(); err == nil {
+		return account.Username
+	}
+	for _, variable := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(variable); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,123 @@
+package audit_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/audit"
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/processor"
+)
+
+// TestLog_Processor tests that a real rename is audited with matching listing checksums and a dry run is not
+func TestLog_Processor(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/tree/a:b/child")
+	memory.WriteFile("/tree/a:b/report.txt", []byte("data"))
+	memory.MkdirAll("/tree/c?d")
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log, err := audit.Open(path, memory, "run-1")
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+	folderProcessor := log.Processor(processor.NewFileSystemProcessor(10, processor.WithFileSystem(memory)))
+
+	rename := func(name, newName string, dryRun bool) {
+		folder := interfaces.FolderInfo{Path: "/tree/" + name, Name: name, Depth: 1, Parent: "/tree"}
+		if _, err := folderProcessor.ProcessRename(folder, newName, dryRun); err != nil {
+			t.Fatalf("ProcessRename(%q) returned error: %v", name, err)
+		}
+	}
+	rename("a:b", "a_b", false)
+	rename("c?d", "c_d", true)
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+
+	entries := readEntries(t, path)
+	if len(entries) != 1 {
+		t.Fatalf("Expected only the real rename to be audited, got %+v", entries)
+	}
+	entry := entries[0]
+	if entry.OldPath != "/tree/a:b" || entry.NewPath != "/tree/a_b" || entry.RunID != "run-1" || entry.User == "" {
+		t.Errorf("Expected the rename with its run and user, got %+v", entry)
+	}
+	if entry.ListingBefore == "" || entry.ListingBefore != entry.ListingAfter {
+		t.Errorf("Expected equal checksums for an intact directory, got %q and %q", entry.ListingBefore, entry.ListingAfter)
+	}
+	entriesAfter, _ := memory.ReadDir("/tree/a_b")
+	if entry.ListingAfter != audit.Checksum(entriesAfter) {
+		t.Errorf("Expected the checksum of the renamed directory's listing, got %q", entry.ListingAfter)
+	}
+}
+
+// TestLog_Append tests that a reopened log keeps its earlier entries
+func TestLog_Append(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/tree/a:b")
+	memory.MkdirAll("/tree/c?d")
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	for _, rename := range [][2]string{{"a:b", "a_b"}, {"c?d", "c_d"}} {
+		log, err := audit.Open(path, memory, "")
+		if err != nil {
+			t.Fatalf("Open() returned error: %v", err)
+		}
+		folder := interfaces.FolderInfo{Path: "/tree/" + rename[0], Name: rename[0], Depth: 1, Parent: "/tree"}
+		folderProcessor := log.Processor(processor.NewFileSystemProcessor(10, processor.WithFileSystem(memory)))
+		if _, err := folderProcessor.ProcessRename(folder, rename[1], false); err != nil {
+			t.Fatalf("ProcessRename() returned error: %v", err)
+		}
+		log.Close()
+	}
+
+	if entries := readEntries(t, path); len(entries) != 2 {
+		t.Errorf("Expected both runs in the log, got %+v", entries)
+	}
+}
+
+// TestChecksum tests that the checksum ignores listing order but not names or entry types
+func TestChecksum(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/one/x")
+	memory.WriteFile("/one/y", nil)
+	memory.MkdirAll("/two/y")
+	memory.WriteFile("/two/x", nil)
+
+	one, _ := memory.ReadDir("/one")
+	two, _ := memory.ReadDir("/two")
+	reversed := []os.DirEntry{one[1], one[0]}
+
+	if audit.Checksum(one) != audit.Checksum(reversed) {
+		t.Error("Expected the checksum to be independent of listing order")
+	}
+	if audit.Checksum(one) == audit.Checksum(two) {
+		t.Error("Expected swapped entry types to change the checksum")
+	}
+}
+
+// readEntries parses the JSON lines of an audit log
+func readEntries(t *testing.T, path string) []audit.Entry {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	var entries []audit.Entry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry audit.Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Failed to parse audit line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,172 @@
+// Package casing finds sibling folders whose names differ only in case and consolidates them.
+// Such siblings coexist on case-sensitive file systems but collide on case-insensitive targets like Windows or OneDrive.
+package casing
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// RuleCaseConsolidation identifies renames into the canonical spelling of a case conflict
+const RuleCaseConsolidation = "case-consolidation"
+
+// Strategies for choosing the canonical spelling of a conflict
+const (
+	Largest = "largest" // The spelling with the most subfolders, so the least content moves
+	Lower   = "lower"   // The lower-case spelling
+)
+
+// Conflict is a group of siblings whose names differ only in case
+type Conflict struct {
+	Parent    string   // Path of the common parent
+	Names     []string // Spellings of the siblings in byte order
+	Canonical string   // Spelling the siblings are consolidated into
+}
+
+// Auditor implements a walker decorator that finds case conflicts, and a sanitizer decorator that consolidates them
+// The walker has to run before the sanitizer sees the first folder, which the service guarantees
+type Auditor struct {
+	strategy  string
+	conflicts []Conflict
+	canonical map[string]string // Canonical spelling by the path of every folder in a conflict
+}
+
+// NewAuditor creates an Auditor that picks canonical spellings with the given strategy
+func NewAuditor(strategy string) (*Auditor, error) {
+	if strategy != Largest && strategy != Lower {
+		return nil, fmt.Errorf("unknown case strategy %q (available: %s, %s)", strategy, Largest, Lower)
+	}
+	return &Auditor{strategy: strategy, canonical: make(map[string]string)}, nil
+}
+
+// Conflicts returns the conflicts of the most recent walk, ordered by parent path
+func (a *Auditor) Conflicts() []Conflict {
+	return a.conflicts
+}
+
+// Walker wraps a walker so every walk is audited for case conflicts
+func (a *Auditor) Walker(next interfaces.DirectoryWalker) interfaces.DirectoryWalker {
+	return &auditingWalker{next: next, auditor: a}
+}
+
+// Sanitizer wraps a sanitizer so every folder of a conflict is renamed to the canonical spelling
+// The canonical spelling is sanitized by the wrapped sanitizer like any other name
+func (a *Auditor) Sanitizer(next interfaces.FolderSanitizer) interfaces.FolderSanitizer {
+	return &consolidatingSanitizer{next: next, auditor: a}
+}
+
+// audit groups the folders by parent and case-folded name
+func (a *Auditor) audit(folders []interfaces.FolderInfo) {
+	a.conflicts = nil
+	a.canonical = make(map[string]string)
+
+	// Subfolder counts tell how much content each spelling holds
+	walked := make(map[string]bool, len(folders))
+	for _, folder := range folders {
+		walked[folder.Path] = true
+	}
+	subfolders := make(map[string]int)
+	for _, folder := range folders {
+		for parent := filepath.Dir(folder.Path); walked[parent]; parent = filepath.Dir(parent) {
+			subfolders[parent]++
+		}
+	}
+
+	groups := make(map[string][]interfaces.FolderInfo)
+	for _, folder := range folders {
+		key := folder.Parent + "\x00" + strings.ToLower(folder.Name)
+		groups[key] = append(groups[key], folder)
+	}
+
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].Name < group[j].Name })
+
+		canonical := strings.ToLower(group[0].Name)
+		if a.strategy == Largest {
+			largest := group[0]
+			for _, folder := range group[1:] {
+				if subfolders[folder.Path] > subfolders[largest.Path] {
+					largest = folder
+				}
+			}
+			canonical = largest.Name
+		}
+
+		conflict := Conflict{Parent: group[0].Parent, Canonical: canonical}
+		for _, folder := range group {
+			conflict.Names = append(conflict.Names, folder.Name)
+			a.canonical[folder.Path] = canonical
+		}
+		a.conflicts = append(a.conflicts, conflict)
+	}
+
+	sort.Slice(a.conflicts, func(i, j int) bool {
+		if a.conflicts[i].Parent != a.conflicts[j].Parent {
+			return a.conflicts[i].Parent < a.conflicts[j].Parent
+		}
+		return a.conflicts[i].Canonical < a.conflicts[j].Canonical
+	})
+}
+
+// auditingWalker audits the folders returned by the wrapped walker
+type auditingWalker struct {
+	next    interfaces.DirectoryWalker
+	auditor *Auditor
+}
+
+// Walk walks the tree and audits the result
+func (aw *auditingWalker) Walk(rootPath string) ([]interfaces.FolderInfo, error) {
+	folders, err := aw.next.Walk(rootPath)
+	if err != nil {
+		return folders, err
+	}
+	aw.auditor.audit(folders)
+	return folders, nil
+}
+
+// Warnings forwards the problems of the wrapped walker's most recent walk, if it collects them
+func (aw *auditingWalker) Warnings() []error {
+	if warningWalker, ok := aw.next.(interfaces.WarningWalker); ok {
+		return warningWalker.Warnings()
+	}
+	return nil
+}
+
+// ObserveScan forwards the observer to the wrapped walker if it reports its scan
+func (aw *auditingWalker) ObserveScan(observe func(scanned int, path string)) {
+	if scanningWalker, ok := aw.next.(interfaces.ScanningWalker); ok {
+		scanningWalker.ObserveScan(observe)
+	}
+}
+
+// consolidatingSanitizer implements FolderSanitizer and FolderExplainer
+// This struct renames the folders of a conflict to the canonical spelling before the wrapped sanitizer applies its rules
+type consolidatingSanitizer struct {
+	next    interfaces.FolderSanitizer
+	auditor *Auditor
+}
+
+// SanitizeName sanitizes a bare name with the wrapped sanitizer; without a path no conflict can be found
+func (cs *consolidatingSanitizer) SanitizeName(name string) string {
+	return cs.next.SanitizeName(name)
+}
+
+// ExplainFolder renames a folder of a conflict to the canonical spelling and applies the wrapped sanitizer
+// This method implements the FolderExplainer interface
+func (cs *consolidatingSanitizer) ExplainFolder(folder interfaces.FolderInfo) (string, []string) {
+	canonical, conflicting := cs.auditor.canonical[folder.Path]
+	if !conflicting || canonical == folder.Name {
+		return interfaces.Explain(cs.next, folder)
+	}
+
+	folder.Name = canonical
+	sanitized, rules := interfaces.Explain(cs.next, folder)
+	return sanitized, append([]string{RuleCaseConsolidation}, rules...)
+}
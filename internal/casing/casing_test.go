@@ -0,0 +1,121 @@
+// Package casing_test provides tests for the case conflict audit.
+// This test suite ensures siblings that differ only in case are grouped and consolidated into one spelling.
+package casing_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/casing"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+	"github.com/punkscience/sanitize/internal/walker"
+)
+
+// folder describes a walked folder below root
+func folder(root string, elements ...string) interfaces.FolderInfo {
+	path := filepath.Join(append([]string{root}, elements...)...)
+	return interfaces.FolderInfo{
+		Path:   path,
+		Name:   filepath.Base(path),
+		Parent: filepath.Dir(path),
+		Depth:  len(elements),
+	}
+}
+
+// sampleTree has three spellings of Photos, the capitalized one holding the most subfolders, and a case-distinct pair elsewhere
+func sampleTree(root string) []interfaces.FolderInfo {
+	return []interfaces.FolderInfo{
+		folder(root, "Photos", "2023"),
+		folder(root, "Photos", "2024"),
+		folder(root, "photos", "2024"),
+		folder(root, "docs", "Notes"),
+		folder(root, "work", "notes"),
+		folder(root, "PHOTOS"),
+		folder(root, "Photos"),
+		folder(root, "docs"),
+		folder(root, "photos"),
+		folder(root, "work"),
+	}
+}
+
+// audit walks the sample tree with a new auditor using the strategy
+func audit(t *testing.T, strategy string) (*casing.Auditor, []interfaces.FolderInfo) {
+	t.Helper()
+	root := t.TempDir()
+	auditor, err := casing.NewAuditor(strategy)
+	if err != nil {
+		t.Fatalf("NewAuditor(%q) returned error: %v", strategy, err)
+	}
+	folders, err := auditor.Walker(walker.NewListWalker(sampleTree(root))).Walk(root)
+	if err != nil {
+		t.Fatalf("Walk() returned error: %v", err)
+	}
+	return auditor, folders
+}
+
+// TestAuditor_Conflicts tests that only siblings are grouped and the canonical spelling follows the strategy
+func TestAuditor_Conflicts(t *testing.T) {
+	testCases := map[string]string{
+		casing.Largest: "Photos", // Two subfolders against one and none
+		casing.Lower:   "photos",
+	}
+	for strategy, canonical := range testCases {
+		auditor, _ := audit(t, strategy)
+		conflicts := auditor.Conflicts()
+		if len(conflicts) != 1 {
+			t.Fatalf("%s: expected 1 conflict, got %+v", strategy, conflicts)
+		}
+		if want := []string{"PHOTOS", "Photos", "photos"}; !reflect.DeepEqual(conflicts[0].Names, want) {
+			t.Errorf("%s: expected names %v, got %v", strategy, want, conflicts[0].Names)
+		}
+		if conflicts[0].Canonical != canonical {
+			t.Errorf("%s: expected canonical %q, got %q", strategy, canonical, conflicts[0].Canonical)
+		}
+	}
+
+	if _, err := casing.NewAuditor("upper"); err == nil {
+		t.Error("NewAuditor(upper) expected error")
+	}
+}
+
+// TestAuditor_LargestTie tests that equally large spellings are decided by byte order
+func TestAuditor_LargestTie(t *testing.T) {
+	root := t.TempDir()
+	auditor, _ := casing.NewAuditor(casing.Largest)
+	tree := []interfaces.FolderInfo{folder(root, "music"), folder(root, "Music")}
+	if _, err := auditor.Walker(walker.NewListWalker(tree)).Walk(root); err != nil {
+		t.Fatalf("Walk() returned error: %v", err)
+	}
+
+	if conflicts := auditor.Conflicts(); len(conflicts) != 1 || conflicts[0].Canonical != "Music" {
+		t.Errorf("Expected Music to be kept, got %+v", conflicts)
+	}
+}
+
+// TestAuditor_Sanitizer tests that conflicting folders are renamed to the sanitized canonical spelling
+func TestAuditor_Sanitizer(t *testing.T) {
+	auditor, folders := audit(t, casing.Lower)
+	consolidating := auditor.Sanitizer(sanitizer.NewWindowsSanitizer())
+	explainer, ok := consolidating.(interfaces.FolderExplainer)
+	if !ok {
+		t.Fatal("Expected the consolidating sanitizer to explain folders")
+	}
+
+	for _, info := range folders {
+		name, rules := explainer.ExplainFolder(info)
+		switch {
+		case info.Name == "PHOTOS" || info.Name == "Photos" && info.Depth == 1:
+			if name != "photos" || len(rules) == 0 || rules[0] != casing.RuleCaseConsolidation {
+				t.Errorf("ExplainFolder(%s) = %q %v, want photos [%s]", info.Path, name, rules, casing.RuleCaseConsolidation)
+			}
+		case name != info.Name || len(rules) != 0:
+			t.Errorf("ExplainFolder(%s) = %q %v, want it unchanged", info.Path, name, rules)
+		}
+	}
+
+	if name := consolidating.SanitizeName("PHOTOS"); name != "PHOTOS" {
+		t.Errorf("SanitizeName(PHOTOS) = %q, want it unchanged without a path", name)
+	}
+}
@@ -0,0 +1,217 @@
+// Package chaos injects synthetic rename failures so teams can rehearse runbooks before touching production data.
+// This implementation follows the Decorator pattern by wrapping a FileSystem for real runs and a FolderProcessor for dry runs.
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Fault is a kind of synthetic rename failure
+type Fault string
+
+// Supported faults
+const (
+	Failure   Fault = "failure"   // Permanent error, e.g. access denied
+	Timeout   Fault = "timeout"   // Transient error that the rename retries may overcome
+	Collision Fault = "collision" // The target name appeared just before the rename
+)
+
+// AllFaults lists every fault in the order they are described
+var AllFaults = []Fault{Failure, Timeout, Collision}
+
+// Config selects how often and which faults are injected
+type Config struct {
+	Rate   float64 // Probability (0-1) that a rename fails
+	Seed   uint64  // Seed of the random source, so a rehearsal can be repeated exactly
+	Faults []Fault // Faults to choose from with equal probability
+}
+
+// ParseConfig parses a comma-separated list such as rate=0.01,seed=42,faults=timeout+collision
+// Only rate is required; the seed defaults to the current time and the faults to AllFaults
+func ParseConfig(text string) (Config, error) {
+	config := Config{Rate: -1, Seed: uint64(time.Now().UnixNano()), Faults: AllFaults}
+
+	for _, part := range strings.Split(text, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			return Config{}, fmt.Errorf("invalid chaos setting %q (want key=value)", part)
+		}
+
+		switch key {
+		case "rate":
+			rate, err := strconv.ParseFloat(value, 64)
+			if err != nil || rate < 0 || rate > 1 {
+				return Config{}, fmt.Errorf("invalid chaos rate %q (want a number from 0 to 1)", value)
+			}
+			config.Rate = rate
+		case "seed":
+			seed, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return Config{}, fmt.Errorf("invalid chaos seed %q", value)
+			}
+			config.Seed = seed
+		case "faults":
+			faults, err := parseFaults(value)
+			if err != nil {
+				return Config{}, err
+			}
+			config.Faults = faults
+		default:
+			return Config{}, fmt.Errorf("unknown chaos setting %q (want rate, seed or faults)", key)
+		}
+	}
+
+	if config.Rate < 0 {
+		return Config{}, fmt.Errorf("chaos settings need a rate, e.g. rate=0.01")
+	}
+	return config, nil
+}
+
+// parseFaults parses fault names separated by +
+func parseFaults(text string) ([]Fault, error) {
+	var faults []Fault
+	for _, name := range strings.Split(text, "+") {
+		fault := Fault(strings.TrimSpace(name))
+		switch fault {
+		case Failure, Timeout, Collision:
+			faults = append(faults, fault)
+		default:
+			return nil, fmt.Errorf("unknown chaos fault %q (want failure, timeout or collision)", name)
+		}
+	}
+	return faults, nil
+}
+
+// InjectedError is a synthetic rename error
+// It unwraps to the standard error a real failure of the same kind would carry, so callers handle it the same way
+type InjectedError struct {
+	Fault Fault
+}
+
+// Error describes the fault and makes clear that it is synthetic
+func (e *InjectedError) Error() string {
+	return fmt.Sprintf("chaos: injected %s", e.Fault)
+}
+
+// Unwrap returns the standard error that matches the fault
+func (e *InjectedError) Unwrap() error {
+	switch e.Fault {
+	case Timeout:
+		return os.ErrDeadlineExceeded
+	case Collision:
+		return fs.ErrExist
+	default:
+		return fs.ErrPermission
+	}
+}
+
+// Timeout reports whether the fault is a timeout, which marks the error as transient
+func (e *InjectedError) Timeout() bool {
+	return e.Fault == Timeout
+}
+
+// Injector decides which renames fail
+// This struct is safe for concurrent use; a single random source keeps seeded rehearsals repeatable
+type Injector struct {
+	config Config
+	mu     sync.Mutex
+	random *rand.Rand
+}
+
+// NewInjector creates an injector for the given configuration
+func NewInjector(config Config) *Injector {
+	return &Injector{
+		config: config,
+		random: rand.New(rand.NewPCG(config.Seed, config.Seed)),
+	}
+}
+
+// Config returns the configuration of the injector, including the seed actually used
+func (in *Injector) Config() Config {
+	return in.config
+}
+
+// FileSystem wraps a file system so renames fail at the configured rate
+// Real runs use it, so injected failures go through the same retries, journal and failure exports as real ones
+func (in *Injector) FileSystem(base interfaces.FileSystem) interfaces.FileSystem {
+	return &faultyFileSystem{FileSystem: base, injector: in}
+}
+
+// Processor wraps a processor so dry-run renames fail at the configured rate
+// Dry runs never call Rename, so the faults are injected into their results instead
+func (in *Injector) Processor(next interfaces.FolderProcessor) interfaces.FolderProcessor {
+	return &faultyProcessor{next: next, injector: in}
+}
+
+// next returns the fault for the next rename, if it should fail
+func (in *Injector) next() (Fault, bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	if len(in.config.Faults) == 0 || in.random.Float64() >= in.config.Rate {
+		return "", false
+	}
+	return in.config.Faults[in.random.IntN(len(in.config.Faults))], true
+}
+
+// faultyFileSystem fails renames at the injector's rate and passes everything else through
+type faultyFileSystem struct {
+	interfaces.FileSystem
+	injector *Injector
+}
+
+// Rename fails with an injected error or renames through the wrapped file system
+func (f *faultyFileSystem) Rename(oldPath, newPath string) error {
+	if fault, ok := f.injector.next(); ok {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: &InjectedError{Fault: fault}}
+	}
+	return f.FileSystem.Rename(oldPath, newPath)
+}
+
+// faultyProcessor fails dry-run renames at the injector's rate
+type faultyProcessor struct {
+	next     interfaces.FolderProcessor
+	injector *Injector
+}
+
+// ProcessRename reports an injected failure for a planned rename or forwards to the wrapped processor
+// Real runs are always forwarded; their faults come from the file system
+func (f *faultyProcessor) ProcessRename(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+	if !dryRun || newName == folder.Name {
+		return f.next.ProcessRename(folder, newName, dryRun)
+	}
+
+	fault, ok := f.injector.next()
+	if !ok {
+		return f.next.ProcessRename(folder, newName, dryRun)
+	}
+
+	newPath := filepath.Join(folder.Parent, newName)
+	err := &os.LinkError{Op: "rename", Old: folder.Path, New: newPath, Err: &InjectedError{Fault: fault}}
+	return &interfaces.RenameResult{
+		OldPath:    folder.Path,
+		NewPath:    newPath,
+		WasRenamed: true,
+		Error:      fmt.Errorf("rename operation failed: %w", err),
+		Transient:  filesystem.IsTransientError(err),
+		Attempts:   1,
+	}, nil
+}
+
+// IsInjected reports whether err was injected by chaos mode
+func IsInjected(err error) bool {
+	var injected *InjectedError
+	return errors.As(err, &injected)
+}
@@ -0,0 +1,127 @@
+// Package chaos_test provides tests for the chaos package.
+// This test suite ensures injected faults are repeatable and surface like the real errors they imitate.
+package chaos_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/chaos"
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/processor"
+)
+
+// TestParseConfig tests parsing of the --chaos settings
+func TestParseConfig(t *testing.T) {
+	config, err := chaos.ParseConfig("rate=0.25, seed=7, faults=timeout+collision")
+	if err != nil {
+		t.Fatalf("ParseConfig() returned error: %v", err)
+	}
+	if config.Rate != 0.25 || config.Seed != 7 || len(config.Faults) != 2 || config.Faults[0] != chaos.Timeout {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+
+	defaults, err := chaos.ParseConfig("rate=0.01")
+	if err != nil || len(defaults.Faults) != len(chaos.AllFaults) {
+		t.Errorf("Expected all faults by default, got %+v, %v", defaults, err)
+	}
+
+	for _, text := range []string{"", "seed=1", "rate=2", "rate=x", "rate=0.1,faults=flood", "rate=0.1,speed=2"} {
+		if _, err := chaos.ParseConfig(text); err == nil {
+			t.Errorf("ParseConfig(%q) should fail", text)
+		}
+	}
+}
+
+// TestInjectedError tests that each fault unwraps to the error a real failure would carry
+func TestInjectedError(t *testing.T) {
+	failure := &chaos.InjectedError{Fault: chaos.Failure}
+	if !errors.Is(failure, fs.ErrPermission) || filesystem.IsTransientError(failure) {
+		t.Error("Expected an injected failure to be a permanent permission error")
+	}
+
+	collision := &chaos.InjectedError{Fault: chaos.Collision}
+	if !errors.Is(collision, fs.ErrExist) {
+		t.Error("Expected an injected collision to be an existence error")
+	}
+
+	timeout := &chaos.InjectedError{Fault: chaos.Timeout}
+	if !filesystem.IsTransientError(timeout) {
+		t.Error("Expected an injected timeout to be transient")
+	}
+	if !chaos.IsInjected(wrapInPathError(timeout)) {
+		t.Error("Expected IsInjected to see through wrapping")
+	}
+}
+
+// wrapInPathError wraps err the way file system errors usually are
+func wrapInPathError(err error) error {
+	return &fs.PathError{Op: "rename", Path: "/data/x", Err: err}
+}
+
+// renameAll renames n folders through a processor on a file system wrapped by injector
+func renameAll(t *testing.T, injector *chaos.Injector, n int) []bool {
+	t.Helper()
+
+	memory := filesystem.NewMemoryFileSystem()
+	folderProcessor := processor.NewFileSystemProcessor(10, processor.WithFileSystem(injector.FileSystem(memory)))
+
+	failed := make([]bool, n)
+	for i := range failed {
+		name := string(rune('a'+i%26)) + ":" + string(rune('a'+i/26))
+		memory.MkdirAll("/data/" + name)
+		folder := interfaces.FolderInfo{Path: "/data/" + name, Name: name, Depth: 1, Parent: "/data"}
+		result, err := folderProcessor.ProcessRename(folder, name+"_", false)
+		if err != nil {
+			t.Fatalf("ProcessRename() returned error: %v", err)
+		}
+		failed[i] = result.Error != nil
+		if failed[i] && !chaos.IsInjected(result.Error) {
+			t.Errorf("Unexpected real error: %v", result.Error)
+		}
+	}
+	return failed
+}
+
+// TestInjector_FileSystem tests that real renames fail at the configured rate and repeat with the same seed
+func TestInjector_FileSystem(t *testing.T) {
+	config := chaos.Config{Rate: 0.3, Seed: 42, Faults: []chaos.Fault{chaos.Failure}}
+
+	first := renameAll(t, chaos.NewInjector(config), 200)
+	second := renameAll(t, chaos.NewInjector(config), 200)
+
+	failures := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Expected the same seed to fail the same renames, differs at %d", i)
+		}
+		if first[i] {
+			failures++
+		}
+	}
+	if failures < 30 || failures > 90 {
+		t.Errorf("Expected about 60 of 200 renames to fail, got %d", failures)
+	}
+}
+
+// TestInjector_Processor tests that dry runs report injected failures without touching unchanged names
+func TestInjector_Processor(t *testing.T) {
+	injector := chaos.NewInjector(chaos.Config{Rate: 1, Seed: 1, Faults: []chaos.Fault{chaos.Timeout}})
+	folderProcessor := injector.Processor(processor.NewFileSystemProcessor(10, processor.WithFileSystem(filesystem.NewMemoryFileSystem())))
+
+	folder := interfaces.FolderInfo{Path: "/data/a:b", Name: "a:b", Depth: 1, Parent: "/data"}
+	result, err := folderProcessor.ProcessRename(folder, "a_b", true)
+	if err != nil {
+		t.Fatalf("ProcessRename() returned error: %v", err)
+	}
+	if result.Success || !chaos.IsInjected(result.Error) || !result.Transient {
+		t.Errorf("Expected an injected transient failure, got %+v", result)
+	}
+
+	compliant := interfaces.FolderInfo{Path: "/data/ok", Name: "ok", Depth: 1, Parent: "/data"}
+	if result, _ := folderProcessor.ProcessRename(compliant, "ok", true); result == nil || result.Error != nil {
+		t.Errorf("Expected compliant folders to pass through, got %+v", result)
+	}
+}
@@ -0,0 +1,365 @@
+// Package checkpoint persists the progress of a run so an interrupted or crashed run can be resumed without re-walking the tree.
+// This implementation follows the Decorator pattern by wrapping an existing ProgressReporter and DirectoryWalker.
+package checkpoint
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// fileVersion identifies the layout of the checkpoint file
+const fileVersion = 1
+
+// Header describes the run that wrote a checkpoint
+type Header struct {
+	Version int    `json:"version"`          // Layout version of the file
+	RunID   string `json:"run_id,omitempty"` // Identifier of the run that wrote the checkpoint
+	Root    string `json:"root"`             // Absolute root path of the run
+}
+
+// Folder is a single folder of the walk as stored in the checkpoint
+type Folder struct {
+	Path   string `json:"path"`            // Full path to the folder
+	Name   string `json:"name"`            // Folder name
+	Depth  int    `json:"depth"`           // Depth level from the root
+	Parent string `json:"parent"`          // Parent directory path
+	Owner  string `json:"owner,omitempty"` // Owner of the folder, when the walker attributes owners
+}
+
+// record is one line of the checkpoint file; exactly one field is set
+// The header and the folders are written once after the walk, done records are appended as folders finish
+type record struct {
+	Header *Header `json:"header,omitempty"`
+	Folder *Folder `json:"folder,omitempty"`
+	Done   int     `json:"done,omitempty"` // Number of folders processed so far, in walk order
+}
+
+// Path returns the checkpoint file for a root inside dir
+// Every root has its own file, so runs on different trees never share a checkpoint
+func Path(dir, root string) string {
+	sum := sha256.Sum256([]byte(root))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".jsonl")
+}
+
+// Recorder implements ProgressReporter, RenameReporter, FolderReporter, FailureReporter, WarningReporter and ScanReporter
+// This struct writes the walk and the number of finished folders to a checkpoint file while forwarding every event
+type Recorder struct {
+	next    interfaces.ProgressReporter
+	path    string
+	header  Header
+	file    *os.File
+	total   int
+	done    int
+	started bool  // Whether the run started processing a folder
+	resumed bool  // Whether the run continues an interrupted one, see WithResumedRun
+	err     error // First write error; the checkpoint is abandoned after it
+}
+
+// Option configures a Recorder
+type Option func(*Recorder)
+
+// WithResumedRun keeps the checkpoint even when the run stops before its first folder
+// A resumed run's checkpoint still lists what the interrupted run left, so it must survive an aborted confirmation
+func WithResumedRun() Option {
+	return func(r *Recorder) {
+		r.resumed = true
+	}
+}
+
+// NewRecorder creates a Recorder that wraps the provided reporter and writes the checkpoint to path
+// Nothing is written until the walk of the wrapped walker returned, see Walker
+func NewRecorder(next interfaces.ProgressReporter, path string, header Header, opts ...Option) *Recorder {
+	header.Version = fileVersion
+	r := &Recorder{
+		next:   next,
+		path:   path,
+		header: header,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Walker wraps a walker so its result becomes the folder list of the checkpoint
+// A checkpoint that can't be written fails the walk, so no folder is renamed without one
+func (r *Recorder) Walker(next interfaces.DirectoryWalker) interfaces.DirectoryWalker {
+	return &recordingWalker{next: next, recorder: r}
+}
+
+// ReportProgress forwards progress updates to the wrapped reporter
+func (r *Recorder) ReportProgress(current, total int, message string) {
+	r.next.ReportProgress(current, total, message)
+}
+
+// ReportError forwards errors to the wrapped reporter
+func (r *Recorder) ReportError(err error) {
+	r.next.ReportError(err)
+}
+
+// ReportWarning forwards warnings when the wrapped reporter supports them
+func (r *Recorder) ReportWarning(warning error) {
+	if warningReporter, ok := r.next.(interfaces.WarningReporter); ok {
+		warningReporter.ReportWarning(warning)
+	}
+}
+
+// ReportScan forwards the discovery progress when the wrapped reporter shows it
+func (r *Recorder) ReportScan(scanned int, path string) {
+	if scanReporter, ok := r.next.(interfaces.ScanReporter); ok {
+		scanReporter.ReportScan(scanned, path)
+	}
+}
+
+// ReportComplete records how many folders were processed and forwards the summary
+func (r *Recorder) ReportComplete(summary interfaces.ProcessingSummary) {
+	r.markDone(summary.ProcessedCount)
+	r.next.ReportComplete(summary)
+}
+
+// ReportRename forwards renames when the wrapped reporter supports them
+func (r *Recorder) ReportRename(result interfaces.RenameResult) {
+	if renameReporter, ok := r.next.(interfaces.RenameReporter); ok {
+		renameReporter.ReportRename(result)
+	}
+}
+
+// ReportFolder records that every folder before the current one is finished and forwards the folder
+// The current folder only counts as done once the next one starts, so a crash mid-rename retries it
+func (r *Recorder) ReportFolder(current, total int, folder interfaces.FolderInfo) {
+	r.started = true
+	r.markDone(current - 1)
+	if folderReporter, ok := r.next.(interfaces.FolderReporter); ok {
+		folderReporter.ReportFolder(current, total, folder)
+	}
+}
+
+// ReportFailure forwards failed folders when the wrapped reporter collects them
+func (r *Recorder) ReportFailure(folder interfaces.FolderInfo, err error) {
+	if failureReporter, ok := r.next.(interfaces.FailureReporter); ok {
+		failureReporter.ReportFailure(folder, err)
+	}
+}
+
+// Finish closes the checkpoint and removes it once every folder was processed, or when the run never started one
+// It reports whether the checkpoint was kept for a later --resume, and the first error writing it
+func (r *Recorder) Finish() (bool, error) {
+	if r.file == nil {
+		return false, r.err
+	}
+	if err := r.file.Close(); err != nil && r.err == nil {
+		r.err = fmt.Errorf("failed to write checkpoint %s: %w", r.path, err)
+	}
+	r.file = nil
+
+	// A run aborted before its first folder, e.g. at the confirmation prompt, has nothing to resume
+	if r.err == nil && (r.done >= r.total || (!r.started && !r.resumed)) {
+		if err := os.Remove(r.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return false, fmt.Errorf("failed to remove checkpoint %s: %w", r.path, err)
+		}
+		return false, nil
+	}
+	return true, r.err
+}
+
+// Started reports whether the run started processing at least one folder
+func (r *Recorder) Started() bool {
+	return r.started
+}
+
+// start writes the header and the folders of the walk
+// They go to a temporary file first, so a crash never leaves a checkpoint without its folder list
+func (r *Recorder) start(folders []interfaces.FolderInfo) error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0700); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	temporary := r.path + ".tmp"
+	if err := writeFolders(temporary, r.header, folders); err != nil {
+		os.Remove(temporary)
+		return err
+	}
+	if err := os.Rename(temporary, r.path); err != nil {
+		return fmt.Errorf("failed to write checkpoint %s: %w", r.path, err)
+	}
+
+	file, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint %s: %w", r.path, err)
+	}
+	r.file = file
+	r.total = len(folders)
+	return nil
+}
+
+// markDone appends a done record when more folders finished than recorded so far
+func (r *Recorder) markDone(done int) {
+	if r.file == nil || r.err != nil || done <= r.done {
+		return
+	}
+	if err := json.NewEncoder(r.file).Encode(record{Done: done}); err != nil {
+		r.err = fmt.Errorf("failed to write checkpoint %s: %w", r.path, err)
+		return
+	}
+	r.done = done
+}
+
+// writeFolders writes a complete checkpoint without any done records to path
+func writeFolders(path string, header Header, folders []interfaces.FolderInfo) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint %s: %w", path, err)
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(record{Header: &header})
+	for _, folder := range folders {
+		if err != nil {
+			break
+		}
+		err = encoder.Encode(record{Folder: &Folder{
+			Path:   folder.Path,
+			Name:   folder.Name,
+			Depth:  folder.Depth,
+			Parent: folder.Parent,
+			Owner:  folder.Owner,
+		}})
+	}
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write checkpoint %s: %w", path, err)
+	}
+	return nil
+}
+
+// recordingWalker starts the checkpoint with the folders returned by the wrapped walker
+type recordingWalker struct {
+	next     interfaces.DirectoryWalker
+	recorder *Recorder
+}
+
+// Walk walks the tree and writes the result to the checkpoint
+func (rw *recordingWalker) Walk(rootPath string) ([]interfaces.FolderInfo, error) {
+	folders, err := rw.next.Walk(rootPath)
+	if err != nil {
+		return folders, err
+	}
+	if err := rw.recorder.start(folders); err != nil {
+		return nil, err
+	}
+	return folders, nil
+}
+
+// Warnings forwards the problems of the wrapped walker's most recent walk, if it collects them
+func (rw *recordingWalker) Warnings() []error {
+	if warningWalker, ok := rw.next.(interfaces.WarningWalker); ok {
+		return warningWalker.Warnings()
+	}
+	return nil
+}
+
+// ObserveScan forwards the observer to the wrapped walker if it reports its scan
+func (rw *recordingWalker) ObserveScan(observe func(scanned int, path string)) {
+	if scanningWalker, ok := rw.next.(interfaces.ScanningWalker); ok {
+		scanningWalker.ObserveScan(observe)
+	}
+}
+
+// State is the progress of an interrupted run as read from its checkpoint
+type State struct {
+	Header
+	Folders []interfaces.FolderInfo // Every folder of the walk, in processing order
+	Done    int                     // Number of folders that were processed
+}
+
+// Load reads the checkpoint at path
+// A missing file is reported as fs.ErrNotExist; a torn last line from a crash is ignored
+func Load(path string) (*State, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	defer file.Close()
+
+	state := &State{}
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return nil, fmt.Errorf("failed to read checkpoint %s: %w", path, readErr)
+		}
+		if readErr == io.EOF && len(bytes.TrimSpace(data)) > 0 {
+			break // Written partially when the run crashed
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			if err := state.add(data); err != nil {
+				return nil, fmt.Errorf("invalid checkpoint %s, line %d: %w", path, line, err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	if state.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d in %s", state.Version, path)
+	}
+	return state, nil
+}
+
+// add applies a single checkpoint line to the state
+func (s *State) add(data []byte) error {
+	var entry record
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return err
+	}
+
+	switch {
+	case entry.Header != nil:
+		s.Header = *entry.Header
+	case entry.Folder != nil:
+		s.Folders = append(s.Folders, interfaces.FolderInfo{
+			Path:   entry.Folder.Path,
+			Name:   entry.Folder.Name,
+			Depth:  entry.Folder.Depth,
+			Parent: entry.Folder.Parent,
+			Owner:  entry.Folder.Owner,
+		})
+	case entry.Done > s.Done:
+		s.Done = min(entry.Done, len(s.Folders))
+	}
+	return nil
+}
+
+// Remaining returns the folders the interrupted run did not finish
+// The first of them may have been renamed just before a crash; it is dropped when its path no longer exists
+func (s *State) Remaining(fileSystem interfaces.FileSystem) []interfaces.FolderInfo {
+	remaining := s.Folders[s.Done:]
+	if len(remaining) > 0 {
+		if _, err := fileSystem.Lstat(remaining[0].Path); errors.Is(err, fs.ErrNotExist) {
+			remaining = remaining[1:]
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,166 @@
+// Package checkpoint_test provides tests for the checkpoint package.
+// This test suite ensures interrupted runs leave a checkpoint that resumes with the unfinished folders.
+package checkpoint_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/checkpoint"
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/reporter"
+	"github.com/punkscience/sanitize/internal/walker"
+)
+
+// testFolders returns the walk used by the tests, in processing order
+func testFolders() []interfaces.FolderInfo {
+	return []interfaces.FolderInfo{
+		{Path: "/data/a/x:1", Name: "x:1", Depth: 2, Parent: "/data/a"},
+		{Path: "/data/a/x:2", Name: "x:2", Depth: 2, Parent: "/data/a", Owner: "alice"},
+		{Path: "/data/a", Name: "a", Depth: 1, Parent: "/data"},
+	}
+}
+
+// startRun walks testFolders through a recorder and processes the first done folders, like the service does
+func startRun(t *testing.T, path string, done int) *checkpoint.Recorder {
+	t.Helper()
+
+	recorder := checkpoint.NewRecorder(reporter.NewNopReporter(), path, checkpoint.Header{RunID: "run-1", Root: "/data"})
+	folders, err := recorder.Walker(walker.NewListWalker(testFolders())).Walk("/data")
+	if err != nil {
+		t.Fatalf("Walk() returned error: %v", err)
+	}
+	for i := 0; i <= done && i < len(folders); i++ {
+		recorder.ReportFolder(i+1, len(folders), folders[i])
+	}
+	return recorder
+}
+
+// TestRecorder_KeepsInterruptedRun tests that an unfinished run is reloaded with its progress
+func TestRecorder_KeepsInterruptedRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints", "run.jsonl")
+	recorder := startRun(t, path, 2)
+	recorder.ReportComplete(interfaces.ProcessingSummary{ProcessedCount: 2, Aborted: true})
+
+	kept, err := recorder.Finish()
+	if err != nil || !kept {
+		t.Fatalf("Finish() = %v, %v, want the checkpoint kept", kept, err)
+	}
+
+	state, err := checkpoint.Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if state.RunID != "run-1" || state.Root != "/data" {
+		t.Errorf("Unexpected header after round trip: %+v", state.Header)
+	}
+	if len(state.Folders) != 3 || state.Folders[1].Owner != "alice" {
+		t.Errorf("Expected the walk with owners, got %+v", state.Folders)
+	}
+	if state.Done != 2 {
+		t.Errorf("Expected 2 finished folders, got %d", state.Done)
+	}
+
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/data/a")
+	remaining := state.Remaining(memory)
+	if len(remaining) != 1 || remaining[0].Path != "/data/a" {
+		t.Errorf("Expected /data/a to remain, got %+v", remaining)
+	}
+}
+
+// TestRecorder_RemovesFinishedRun tests that a run that processed every folder leaves nothing to resume
+func TestRecorder_RemovesFinishedRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	recorder := startRun(t, path, 3)
+	recorder.ReportComplete(interfaces.ProcessingSummary{ProcessedCount: 3})
+
+	kept, err := recorder.Finish()
+	if err != nil || kept {
+		t.Fatalf("Finish() = %v, %v, want the checkpoint removed", kept, err)
+	}
+	if _, err := checkpoint.Load(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected no checkpoint after a finished run, got %v", err)
+	}
+}
+
+// TestLoad_AfterCrash tests that a torn last line is ignored and a folder renamed just before the crash is skipped
+func TestLoad_AfterCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.jsonl")
+	// A killed process writes no complete record; Finish only closes the file here
+	if _, err := startRun(t, path, 1).Finish(); err != nil {
+		t.Fatalf("Finish() returned error: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(`{"done":`)
+	file.Close()
+
+	state, err := checkpoint.Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if state.Done != 1 {
+		t.Errorf("Expected 1 finished folder, got %d", state.Done)
+	}
+
+	// x:2 was renamed, but the crash came before its done record
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/data/a/x_2")
+	remaining := state.Remaining(memory)
+	if len(remaining) != 1 || remaining[0].Path != "/data/a" {
+		t.Errorf("Expected only /data/a to remain, got %+v", remaining)
+	}
+}
+
+// TestPath tests that every root gets its own checkpoint file
+func TestPath(t *testing.T) {
+	if checkpoint.Path("dir", "/data/a") == checkpoint.Path("dir", "/data/b") {
+		t.Error("Expected different checkpoint files for different roots")
+	}
+	if filepath.Dir(checkpoint.Path("dir", "/data/a")) != "dir" {
+		t.Error("Expected the checkpoint file inside dir")
+	}
+}
+
+// TestRecorder_RemovesUnstartedRun tests that a run stopped before its first folder leaves nothing to resume
+// A resumed run keeps its checkpoint, since it still lists what the interrupted run left.
+func TestRecorder_RemovesUnstartedRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []checkpoint.Option
+		wantKept bool
+	}{
+		{name: "new run", wantKept: false},
+		{name: "resumed run", opts: []checkpoint.Option{checkpoint.WithResumedRun()}, wantKept: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "run.jsonl")
+			recorder := checkpoint.NewRecorder(reporter.NewNopReporter(), path, checkpoint.Header{RunID: "run-1", Root: "/data"}, tt.opts...)
+			if _, err := recorder.Walker(walker.NewListWalker(testFolders())).Walk("/data"); err != nil {
+				t.Fatalf("Walk() returned error: %v", err)
+			}
+			recorder.ReportComplete(interfaces.ProcessingSummary{})
+
+			kept, err := recorder.Finish()
+			if err != nil || kept != tt.wantKept {
+				t.Fatalf("Finish() = %v, %v, want %v", kept, err, tt.wantKept)
+			}
+			if recorder.Started() {
+				t.Error("Expected Started() to be false before the first folder")
+			}
+			if _, err := checkpoint.Load(path); errors.Is(err, fs.ErrNotExist) == tt.wantKept {
+				t.Errorf("Load() error = %v, want the checkpoint kept = %v", err, tt.wantKept)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Package cireport formats check results for CI systems: SARIF for code scanning and GitHub Actions workflow annotations.
+// Both formats are built from the same findings, so they always report the same problems.
+package cireport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Formats of check output
+const (
+	FormatText   = "text"   // Human-readable report (default)
+	FormatSARIF  = "sarif"  // SARIF 2.1.0 JSON for code scanning tools
+	FormatGitHub = "github" // ::error and ::warning workflow commands for GitHub Actions
+)
+
+// Formats lists the supported output formats
+var Formats = []string{FormatText, FormatSARIF, FormatGitHub}
+
+// ValidateFormat returns an error for unknown output formats
+func ValidateFormat(format string) error {
+	for _, known := range Formats {
+		if format == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q (use %s)", format, strings.Join(Formats, ", "))
+}
+
+// Levels of findings
+const (
+	LevelError   = "error"   // A violation that fails the check
+	LevelWarning = "warning" // A risk that is reported but never fails the check
+)
+
+// Finding is a single problem of a folder name
+type Finding struct {
+	Path    string // Slash-separated path of the folder, relative to the working directory where possible
+	Rule    string // Identifier of the rule or risk
+	Level   string // LevelError or LevelWarning
+	Message string // What is wrong and how to fix it
+}
+
+// Rule describes a rule or risk that findings refer to
+type Rule struct {
+	ID          string
+	Description string
+}
+
+// Tool identifies the program that produced the findings in SARIF output
+type Tool struct {
+	Name           string
+	InformationURI string
+}
+
+// sarifLog is the subset of the SARIF 2.1.0 schema the check output uses
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// WriteSARIF writes the findings as a SARIF 2.1.0 log with a single run
+// Only rules that have findings are listed; the first description of a rule wins, and rules without one fall back to their identifier
+func WriteSARIF(w io.Writer, tool Tool, rules []Rule, findings []Finding) error {
+	descriptions := make(map[string]string, len(rules))
+	for _, rule := range rules {
+		if _, exists := descriptions[rule.ID]; !exists {
+			descriptions[rule.ID] = rule.Description
+		}
+	}
+
+	used := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, finding := range findings {
+		if !seen[finding.Rule] {
+			seen[finding.Rule] = true
+			used = append(used, finding.Rule)
+		}
+	}
+	sort.Strings(used)
+
+	driver := sarifDriver{Name: tool.Name, InformationURI: tool.InformationURI, Rules: make([]sarifRule, len(used))}
+	index := make(map[string]int, len(used))
+	for i, id := range used {
+		description := descriptions[id]
+		if description == "" {
+			description = id
+		}
+		driver.Rules[i] = sarifRule{ID: id, ShortDescription: sarifMessage{Text: description}}
+		index[id] = i
+	}
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, finding := range findings {
+		results = append(results, sarifResult{
+			RuleID:    finding.Rule,
+			RuleIndex: index[finding.Rule],
+			Level:     finding.Level,
+			Message:   sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: uriReference(finding.Path)}}}},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false) // Keep characters such as < and > readable in paths
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
+
+// uriReference percent-encodes a slash-separated path for use as a SARIF artifact URI
+// Colons are encoded too, so a name like "a:b" isn't mistaken for a URI scheme; absolute paths become file URIs
+func uriReference(path string) string {
+	prefix := ""
+	switch {
+	case len(path) >= 2 && path[1] == ':': // Windows drive, e.g. C:/data
+		prefix, path = "file:///"+path[:2], path[2:]
+	case strings.HasPrefix(path, "/"):
+		prefix = "file://"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), ":", "%3A")
+	}
+	return prefix + strings.Join(segments, "/")
+}
+
+// WriteGitHub writes one GitHub Actions workflow command per finding, e.g. ::error file=dist/a:b,title=invalid-characters::...
+// GitHub shows them as annotations on the run and, for paths in the diff, inline in the pull request
+func WriteGitHub(w io.Writer, findings []Finding) error {
+	for _, finding := range findings {
+		_, err := fmt.Fprintf(w, "::%s file=%s,title=%s::%s\n",
+			finding.Level, escapeProperty(finding.Path), escapeProperty(finding.Rule), escapeData(finding.Message))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeData escapes the message of a workflow command the way the GitHub Actions toolkit does
+func escapeData(text string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(text)
+}
+
+// escapeProperty escapes a property value of a workflow command, which must not contain its separators either
+func escapeProperty(text string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(text)
+}
@@ -0,0 +1,115 @@
+// Package cireport_test provides tests for the cireport package.
+// This test suite ensures SARIF logs and GitHub annotations are well-formed and escaped.
+package cireport_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/cireport"
+)
+
+// findings are a violation and a risk with characters that need escaping in both formats
+var findings = []cireport.Finding{
+	{Path: "assets/a:b,c", Rule: "invalid-characters", Level: cireport.LevelError, Message: "100% bad\nname"},
+	{Path: "assets/007", Rule: "digits-only", Level: cireport.LevelWarning, Message: "risky"},
+}
+
+// TestWriteSARIF tests that the log lists the used rules and one result per finding with an encoded location
+func TestWriteSARIF(t *testing.T) {
+	var out bytes.Buffer
+	rules := []cireport.Rule{
+		{ID: "invalid-characters", Description: "invalid characters replaced"},
+		{ID: "invalid-characters", Description: "ignored, the first description wins"},
+		{ID: "reserved-name", Description: "not used by any finding"},
+	}
+	if err := cireport.WriteSARIF(&out, cireport.Tool{Name: "sanitize"}, rules, findings); err != nil {
+		t.Fatalf("WriteSARIF() returned error: %v", err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID               string `json:"id"`
+						ShortDescription struct {
+							Text string `json:"text"`
+						} `json:"shortDescription"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				RuleIndex int    `json:"ruleIndex"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatalf("SARIF output is not valid JSON: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("Unexpected SARIF log: %s", out.String())
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 {
+		t.Fatalf("Expected only the 2 used rules, got %+v", run.Tool.Driver.Rules)
+	}
+	if rule := run.Tool.Driver.Rules[1]; rule.ID != "invalid-characters" || rule.ShortDescription.Text != "invalid characters replaced" {
+		t.Errorf("Unexpected rule: %+v", rule)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(run.Results))
+	}
+	result := run.Results[0]
+	if result.RuleID != "invalid-characters" || result.RuleIndex != 1 || result.Level != "error" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if uri := result.Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "assets/a%3Ab%2Cc" {
+		t.Errorf("Expected an encoded relative URI, got %q", uri)
+	}
+}
+
+// TestWriteGitHub tests that every finding becomes one workflow command with escaped properties and message
+func TestWriteGitHub(t *testing.T) {
+	var out bytes.Buffer
+	if err := cireport.WriteGitHub(&out, findings); err != nil {
+		t.Fatalf("WriteGitHub() returned error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	want := []string{
+		"::error file=assets/a%3Ab%2Cc,title=invalid-characters::100%25 bad%0Aname",
+		"::warning file=assets/007,title=digits-only::risky",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("Expected %d lines, got %q", len(want), out.String())
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("Line %d: expected %q, got %q", i, want[i], lines[i])
+		}
+	}
+}
+
+// TestValidateFormat tests that unknown formats are rejected
+func TestValidateFormat(t *testing.T) {
+	if err := cireport.ValidateFormat(cireport.FormatSARIF); err != nil {
+		t.Errorf("Expected sarif to be valid, got %v", err)
+	}
+	if err := cireport.ValidateFormat("junit"); err == nil {
+		t.Error("Expected error for unknown format, got none")
+	}
+}
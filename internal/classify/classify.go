@@ -0,0 +1,151 @@
+// Package classify routes folders to different sanitization rules based on what they contain.
+// This implementation follows the Decorator pattern by wrapping the configured FolderSanitizer.
+package classify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Condition kinds supported in rule specifications
+const (
+	ConditionContains = "contains" // Any direct entry has one of the names or extensions
+	ConditionMostly   = "mostly"   // More than half of the direct files have one of the extensions
+)
+
+// Action values supported in rule specifications
+const (
+	ActionSkip    = "skip"    // Leave the folder name unchanged
+	ActionProfile = "profile" // Sanitize with another profile
+)
+
+// Rule is a parsed classification rule such as "contains:.git=skip"
+type Rule struct {
+	Spec      string   // Original specification, for messages
+	Condition string   // ConditionContains or ConditionMostly
+	Values    []string // Lower-cased names or extensions the condition looks for
+	Action    string   // ActionSkip or ActionProfile
+	Profile   string   // Profile name for ActionProfile
+}
+
+// ParseRule parses a rule of the form "<condition>:<value>[,<value>...]=<action>"
+// Conditions are "contains" and "mostly"; actions are "skip" and "profile:<name>"
+func ParseRule(spec string) (Rule, error) {
+	conditionSpec, actionSpec, ok := strings.Cut(spec, "=")
+	if !ok {
+		return Rule{}, fmt.Errorf("classification rule %q: expected <condition>:<values>=<action>", spec)
+	}
+
+	rule := Rule{Spec: spec}
+
+	kind, values, ok := strings.Cut(conditionSpec, ":")
+	if !ok || strings.TrimSpace(values) == "" {
+		return Rule{}, fmt.Errorf("classification rule %q: condition needs values, e.g. contains:.git", spec)
+	}
+	switch rule.Condition = strings.TrimSpace(kind); rule.Condition {
+	case ConditionContains, ConditionMostly:
+	default:
+		return Rule{}, fmt.Errorf("classification rule %q: unknown condition %q (use %s or %s)", spec, rule.Condition, ConditionContains, ConditionMostly)
+	}
+	for _, value := range strings.Split(values, ",") {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			rule.Values = append(rule.Values, value)
+		}
+	}
+
+	action, profile, _ := strings.Cut(strings.TrimSpace(actionSpec), ":")
+	switch rule.Action = action; rule.Action {
+	case ActionSkip:
+	case ActionProfile:
+		if rule.Profile = strings.TrimSpace(profile); rule.Profile == "" {
+			return Rule{}, fmt.Errorf("classification rule %q: profile action needs a name, e.g. profile:onedrive", spec)
+		}
+	default:
+		return Rule{}, fmt.Errorf("classification rule %q: unknown action %q (use %s or %s:<name>)", spec, action, ActionSkip, ActionProfile)
+	}
+
+	return rule, nil
+}
+
+// Matches reports whether a folder's content summary satisfies the rule's condition
+// Folders without a summary never match
+func (r Rule) Matches(contents *interfaces.ContentSummary) bool {
+	if contents == nil {
+		return false
+	}
+
+	switch r.Condition {
+	case ConditionContains:
+		for _, value := range r.Values {
+			if contents.Names[value] || contents.Extensions[value] > 0 {
+				return true
+			}
+		}
+		return false
+	case ConditionMostly:
+		matching := 0
+		for _, value := range r.Values {
+			matching += contents.Extensions[value]
+		}
+		return matching*2 > contents.Files
+	default:
+		return false
+	}
+}
+
+// Route pairs a rule with the sanitizer used for matching folders (nil = leave unchanged)
+type Route struct {
+	Rule      Rule
+	Sanitizer interfaces.FolderSanitizer
+}
+
+// Sanitizer implements FolderSanitizer, NameExplainer, FolderExplainer and NameTracer
+// This struct sends each folder to the sanitizer of the first matching route, or the fallback
+type Sanitizer struct {
+	fallback interfaces.FolderSanitizer
+	routes   []Route
+}
+
+// NewSanitizer creates a classifying sanitizer; routes are evaluated in order
+func NewSanitizer(fallback interfaces.FolderSanitizer, routes []Route) *Sanitizer {
+	return &Sanitizer{
+		fallback: fallback,
+		routes:   routes,
+	}
+}
+
+// SanitizeName sanitizes a bare name with the fallback; without contents no route can match
+// This method implements the FolderSanitizer interface
+func (s *Sanitizer) SanitizeName(name string) string {
+	return s.fallback.SanitizeName(name)
+}
+
+// ExplainName explains a bare name with the fallback
+// This method implements the NameExplainer interface
+func (s *Sanitizer) ExplainName(name string) (string, []string) {
+	return interfaces.Explain(s.fallback, interfaces.FolderInfo{Name: name})
+}
+
+// TraceName traces a bare name with the fallback
+// This method implements the NameTracer interface
+func (s *Sanitizer) TraceName(name string) (string, []interfaces.RuleStep) {
+	return interfaces.Trace(s.fallback, name)
+}
+
+// ExplainFolder sanitizes a folder with the sanitizer of the first route matching its contents
+// This method implements the FolderExplainer interface
+func (s *Sanitizer) ExplainFolder(folder interfaces.FolderInfo) (string, []string) {
+	for _, route := range s.routes {
+		if !route.Rule.Matches(folder.Contents) {
+			continue
+		}
+		if route.Sanitizer == nil {
+			return folder.Name, nil
+		}
+		return interfaces.Explain(route.Sanitizer, folder)
+	}
+
+	return interfaces.Explain(s.fallback, folder)
+}
@@ -0,0 +1,99 @@
+// Package classify_test provides tests for content-based classification rules.
+// This test suite ensures rules parse, match content summaries and route folders to the right sanitizer.
+package classify_test
+
+import (
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/classify"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+)
+
+// TestParseRule_Invalid tests that malformed rules are rejected
+func TestParseRule_Invalid(t *testing.T) {
+	invalid := []string{
+		"contains:.git",         // no action
+		"contains=skip",         // no values
+		"largest:.jpg=skip",     // unknown condition
+		"contains:.git=delete",  // unknown action
+		"mostly:.mp3=profile:",  // missing profile name
+		"mostly:.mp3=profile: ", // blank profile name
+	}
+
+	for _, spec := range invalid {
+		if _, err := classify.ParseRule(spec); err == nil {
+			t.Errorf("ParseRule(%q) expected error", spec)
+		}
+	}
+}
+
+// TestRule_Matches tests the contains and mostly conditions
+func TestRule_Matches(t *testing.T) {
+	media := &interfaces.ContentSummary{
+		Files:      3,
+		Extensions: map[string]int{".mp3": 2, ".txt": 1},
+		Names:      map[string]bool{"a.mp3": true, "b.mp3": true, "notes.txt": true},
+	}
+	repository := &interfaces.ContentSummary{
+		Files:      1,
+		Extensions: map[string]int{".md": 1},
+		Names:      map[string]bool{".git": true, "readme.md": true},
+	}
+
+	testCases := []struct {
+		spec     string
+		contents *interfaces.ContentSummary
+		expected bool
+	}{
+		{"contains:.git=skip", repository, true},
+		{"contains:.git=skip", media, false},
+		{"contains:.TXT=skip", media, true},
+		{"mostly:.mp3,.flac=profile:onedrive", media, true},
+		{"mostly:.txt=skip", media, false},
+		{"mostly:.mp3=skip", &interfaces.ContentSummary{}, false},
+		{"contains:.git=skip", nil, false},
+	}
+
+	for _, tc := range testCases {
+		rule, err := classify.ParseRule(tc.spec)
+		if err != nil {
+			t.Fatalf("ParseRule(%q) returned error: %v", tc.spec, err)
+		}
+		if result := rule.Matches(tc.contents); result != tc.expected {
+			t.Errorf("%q.Matches() = %v, expected %v", tc.spec, result, tc.expected)
+		}
+	}
+}
+
+// TestSanitizer_Routes tests that folders are sanitized by the first matching route
+func TestSanitizer_Routes(t *testing.T) {
+	skipRule, _ := classify.ParseRule("contains:.git=skip")
+	mediaRule, _ := classify.ParseRule("mostly:.mp3=profile:onedrive")
+	onedrive, _ := sanitizer.LookupProfile("onedrive")
+
+	s := classify.NewSanitizer(sanitizer.NewWindowsSanitizer(), []classify.Route{
+		{Rule: skipRule},
+		{Rule: mediaRule, Sanitizer: sanitizer.NewWindowsSanitizer(sanitizer.WithProfile(onedrive))},
+	})
+
+	repository := interfaces.FolderInfo{Name: "repo:1#", Contents: &interfaces.ContentSummary{
+		Names: map[string]bool{".git": true},
+	}}
+	if name, _ := s.ExplainFolder(repository); name != "repo:1#" {
+		t.Errorf("Expected repository root to be left alone, got %q", name)
+	}
+
+	album := interfaces.FolderInfo{Name: "album#1", Contents: &interfaces.ContentSummary{
+		Files:      1,
+		Extensions: map[string]int{".mp3": 1},
+	}}
+	if name, _ := s.ExplainFolder(album); name != "album_1" {
+		t.Errorf("Expected media folder to use the onedrive profile, got %q", name)
+	}
+
+	// Unclassified folders use the fallback, which keeps # on Windows
+	if name, _ := s.ExplainFolder(interfaces.FolderInfo{Name: "notes#1"}); name != "notes#1" {
+		t.Errorf("Expected fallback sanitizer for unclassified folder, got %q", name)
+	}
+}
@@ -0,0 +1,85 @@
+// Package collation orders names and paths in report output.
+// This implementation uses locale-aware Unicode collation, with plain byte order as the default.
+package collation
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// Binary selects plain byte order, which is stable across locales and releases
+const Binary = "binary"
+
+// Collator compares names for sorted report output
+// A nil Collator compares in byte order
+type Collator struct {
+	locale   string
+	mu       sync.Mutex // collate.Collator is not safe for concurrent use
+	collator *collate.Collator
+}
+
+// New creates a collator for a BCP 47 locale such as "de", "sv" or "und" (language-neutral)
+// An empty locale or Binary returns nil, which compares in byte order
+func New(locale string) (*Collator, error) {
+	if locale == "" || strings.EqualFold(locale, Binary) {
+		return nil, nil
+	}
+
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collation %q: use %q or a locale such as \"und\", \"de\" or \"sv\"", locale, Binary)
+	}
+
+	return &Collator{
+		locale:   tag.String(),
+		collator: collate.New(tag),
+	}, nil
+}
+
+// Locale returns the locale the collator sorts by, or Binary
+func (c *Collator) Locale() string {
+	if c == nil {
+		return Binary
+	}
+	return c.locale
+}
+
+// Compare returns -1, 0 or +1 depending on whether a sorts before, equal to or after b
+// Names the locale considers equal fall back to byte order, so the output is deterministic
+func (c *Collator) Compare(a, b string) int {
+	if c != nil {
+		c.mu.Lock()
+		result := c.collator.CompareString(a, b)
+		c.mu.Unlock()
+		if result != 0 {
+			return result
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// ComparePaths compares paths component by component, so a folder sorts directly before its contents
+func (c *Collator) ComparePaths(a, b string) int {
+	componentsA := strings.Split(a, string(filepath.Separator))
+	componentsB := strings.Split(b, string(filepath.Separator))
+
+	for i := 0; i < len(componentsA) && i < len(componentsB); i++ {
+		if result := c.Compare(componentsA[i], componentsB[i]); result != 0 {
+			return result
+		}
+	}
+	return len(componentsA) - len(componentsB)
+}
+
+// Sort sorts names in collation order
+func (c *Collator) Sort(names []string) {
+	sort.SliceStable(names, func(i, j int) bool {
+		return c.Compare(names[i], names[j]) < 0
+	})
+}
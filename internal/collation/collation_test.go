@@ -0,0 +1,69 @@
+// Package collation_test provides tests for the collation package.
+// This test suite ensures names sort by locale rules and fall back to byte order.
+package collation_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/collation"
+)
+
+// TestCollator_Sort tests sorting in byte order and by locale
+func TestCollator_Sort(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected []string
+	}{
+		{collation.Binary, []string{"Zebra", "apple", "Ärger", "Émile"}},
+		{"und", []string{"apple", "Ärger", "Émile", "Zebra"}},
+		// Swedish sorts Ä after Z
+		{"sv", []string{"apple", "Émile", "Zebra", "Ärger"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			collator, err := collation.New(tt.locale)
+			if err != nil {
+				t.Fatalf("New(%q) returned error: %v", tt.locale, err)
+			}
+
+			names := []string{"Émile", "Zebra", "Ärger", "apple"}
+			collator.Sort(names)
+
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Sort() = %v, expected %v", names, tt.expected)
+			}
+		})
+	}
+}
+
+// TestCollator_ComparePaths tests that folders sort directly before their contents
+func TestCollator_ComparePaths(t *testing.T) {
+	collator, err := collation.New("und")
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	paths := []string{
+		filepath.Join("data", "éte", "b"),
+		filepath.Join("data", "ete"),
+		filepath.Join("data", "éte"),
+		filepath.Join("data", "ete", "z"),
+	}
+	expected := []string{paths[1], paths[3], paths[2], paths[0]}
+
+	for i := 0; i < len(expected)-1; i++ {
+		if collator.ComparePaths(expected[i], expected[i+1]) >= 0 {
+			t.Errorf("Expected %q to sort before %q", expected[i], expected[i+1])
+		}
+	}
+}
+
+// TestNew_Invalid tests that malformed locales are rejected
+func TestNew_Invalid(t *testing.T) {
+	if _, err := collation.New("not a locale!"); err == nil {
+		t.Error("Expected error for invalid locale, but got none")
+	}
+}
@@ -0,0 +1,128 @@
+// Package config loads naming policy files that provide defaults for command-line flags.
+// This implementation reads policies from local files or URLs, with checksum pinning and an on-disk cache.
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Policy maps flag names to the values a policy file assigns them
+type Policy map[string]string
+
+// keyPattern matches the flag names a policy may set
+var keyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Parse decodes a policy document
+// The format is the flat subset of YAML that maps flag names to scalar values:
+//
+//	# Central naming policy
+//	merge: true
+//	max-errors: 50
+//	state-dir: "/var/lib/sanitize"
+func Parse(data []byte) (Policy, error) {
+	policy := make(Policy)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(stripComment(scanner.Text()), " \t\r")
+		if strings.TrimSpace(line) == "" || line == "---" {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' || strings.HasPrefix(line, "- ") {
+			return nil, fmt.Errorf("line %d: nested values and lists are not supported", lineNumber)
+		}
+
+		key, rawValue, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNumber)
+		}
+
+		key = strings.TrimSpace(key)
+		if !keyPattern.MatchString(key) {
+			return nil, fmt.Errorf("line %d: invalid key %q", lineNumber, key)
+		}
+		if _, exists := policy[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", lineNumber, key)
+		}
+
+		value, err := parseScalar(strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		policy[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+
+	return policy, nil
+}
+
+// stripComment removes a trailing "# comment" that is not inside quotes
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// parseScalar unquotes a single- or double-quoted YAML scalar; plain scalars are returned as is
+func parseScalar(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		value, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid double-quoted value %s", raw)
+		}
+		return value, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return "", fmt.Errorf("invalid single-quoted value %s", raw)
+		}
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+	default:
+		return raw, nil
+	}
+}
+
+// Format encodes a policy in the format read by Parse, one "key: value" line per flag in key order
+// Values that would not survive Parse as plain scalars are double-quoted
+func Format(policy Policy) []byte {
+	keys := make([]string, 0, len(policy))
+	for key := range policy {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	for _, key := range keys {
+		fmt.Fprintf(&b, "%s: %s\n", key, formatScalar(policy[key]))
+	}
+	return b.Bytes()
+}
+
+// formatScalar quotes a value unless it reads back unchanged as a plain scalar
+func formatScalar(value string) string {
+	if value == "" || value != strings.TrimSpace(value) || strings.ContainsAny(value, "#\"'\n\r\t") {
+		return strconv.Quote(value)
+	}
+	return value
+}
@@ -0,0 +1,173 @@
+// Package config_test provides tests for policy parsing and loading.
+// This test suite covers the flat YAML subset, checksum pinning and the download cache.
+package config_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/config"
+)
+
+// TestParse tests decoding of the supported YAML subset
+func TestParse(t *testing.T) {
+	policy, err := config.Parse([]byte(`---
+# Central naming policy
+merge: true
+max-errors: 50   # abort early
+state-dir: "/var/lib/sanitize # not a comment"
+state-group: 'it''s'
+`))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	expected := map[string]string{
+		"merge":       "true",
+		"max-errors":  "50",
+		"state-dir":   "/var/lib/sanitize # not a comment",
+		"state-group": "it's",
+	}
+	for key, value := range expected {
+		if policy[key] != value {
+			t.Errorf("policy[%q] = %q, expected %q", key, policy[key], value)
+		}
+	}
+	if len(policy) != len(expected) {
+		t.Errorf("Expected %d keys, got %d", len(expected), len(policy))
+	}
+}
+
+// TestParse_Invalid tests that unsupported documents are rejected
+func TestParse_Invalid(t *testing.T) {
+	testCases := map[string]string{
+		"nested":    "rules:\n  merge: true\n",
+		"list":      "- merge\n",
+		"no colon":  "merge true\n",
+		"bad key":   "Merge: true\n",
+		"duplicate": "merge: true\nmerge: false\n",
+		"quote":     "state-dir: \"unterminated\n",
+	}
+
+	for name, document := range testCases {
+		if _, err := config.Parse([]byte(document)); err == nil {
+			t.Errorf("%s: expected Parse() to fail", name)
+		}
+	}
+}
+
+// checksum returns the hex SHA-256 of data
+func checksum(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// TestFormat_RoundTrip tests that formatted policies parse back to the same values
+func TestFormat_RoundTrip(t *testing.T) {
+	policy := config.Policy{
+		"replacement": "",
+		"protect":     "build # output",
+		"profile":     "onedrive",
+		"empty-name":  " untitled-{parent}",
+		"state-group": "it's",
+	}
+
+	parsed, err := config.Parse(config.Format(policy))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	if len(parsed) != len(policy) {
+		t.Errorf("Expected %d keys, got %d", len(policy), len(parsed))
+	}
+	for key, value := range policy {
+		if parsed[key] != value {
+			t.Errorf("parsed[%q] = %q, expected %q", key, parsed[key], value)
+		}
+	}
+}
+
+// TestSource_RemotePinnedAndCached tests checksum pinning and the offline cache for URL policies
+func TestSource_RemotePinnedAndCached(t *testing.T) {
+	const document = "merge: true\n"
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(document))
+	}))
+
+	var stale error
+	source := config.Source{
+		Location: server.URL + "/sanitize.yaml",
+		CacheDir: t.TempDir(),
+		Client:   server.Client(),
+		Stale:    func(err error) { stale = err },
+	}
+
+	// First load downloads and caches, the second revalidates with the ETag
+	for i := 0; i < 2; i++ {
+		policy, err := source.Load()
+		if err != nil {
+			t.Fatalf("Load() returned error: %v", err)
+		}
+		if policy["merge"] != "true" {
+			t.Errorf("Expected merge=true, got %q", policy["merge"])
+		}
+	}
+	if requests != 2 {
+		t.Errorf("Expected 2 requests, got %d", requests)
+	}
+
+	// A pinned policy already in the cache is used without contacting the server
+	source.SHA256 = checksum(document)
+	if _, err := source.Load(); err != nil {
+		t.Fatalf("Load() with pin returned error: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected pinned cache hit without a request, got %d requests", requests)
+	}
+
+	// The cached copy survives a server outage
+	server.Close()
+	source.SHA256 = ""
+	if _, err := source.Load(); err != nil {
+		t.Errorf("Expected cached policy while server is down, got error: %v", err)
+	}
+	if stale == nil {
+		t.Error("Expected a notice that the cached policy is used")
+	}
+
+	// A pin that doesn't match is rejected
+	source.SHA256 = checksum("merge: false\n")
+	if _, err := source.Load(); !errors.Is(err, config.ErrChecksumMismatch) {
+		t.Errorf("Expected ErrChecksumMismatch, got %v", err)
+	}
+}
+
+// TestSource_InsecureRemote tests that plain http:// policies are only loaded with a pinned checksum
+func TestSource_InsecureRemote(t *testing.T) {
+	const document = "merge: true\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(document))
+	}))
+	defer server.Close()
+
+	source := config.Source{Location: server.URL + "/sanitize.yaml"}
+	if _, err := source.Load(); !errors.Is(err, config.ErrInsecureSource) {
+		t.Errorf("Expected ErrInsecureSource for an unpinned http:// policy, got %v", err)
+	}
+
+	source.SHA256 = checksum(document)
+	if _, err := source.Load(); err != nil {
+		t.Errorf("Expected a pinned http:// policy to load, got error: %v", err)
+	}
+}
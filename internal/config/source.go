@@ -0,0 +1,210 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// maxPolicySize bounds the size of a downloaded policy document
+const maxPolicySize = 1024 * 1024
+
+// ErrChecksumMismatch is returned when a policy does not match its pinned checksum
+var ErrChecksumMismatch = errors.New("policy checksum mismatch")
+
+// ErrInsecureSource is returned for plain http:// policies without a pinned checksum, which anyone on the network could replace
+var ErrInsecureSource = errors.New("http:// policies must be pinned with a checksum; use https:// or --config-sha256")
+
+// Source describes where a policy comes from and how it is verified
+type Source struct {
+	// Location is a local file path or an http(s) URL
+	Location string
+	// SHA256 pins the policy to a hex-encoded SHA-256 checksum (empty = no pinning)
+	SHA256 string
+	// CacheDir stores downloaded policies for offline use (empty = no caching)
+	CacheDir string
+	// Client fetches remote policies (nil = a client with a 30 second timeout)
+	Client *http.Client
+	// Stale is called with the download error when the cached copy is used instead (nil = no notice)
+	Stale func(err error)
+}
+
+// IsRemote reports whether the policy is fetched over HTTP(S)
+func (s Source) IsRemote() bool {
+	return strings.HasPrefix(s.Location, "http://") || strings.HasPrefix(s.Location, "https://")
+}
+
+// Load reads, verifies and parses the policy
+func (s Source) Load() (Policy, error) {
+	var data []byte
+	var err error
+
+	switch {
+	case strings.HasPrefix(s.Location, "http://") && s.SHA256 == "":
+		err = ErrInsecureSource
+	case s.IsRemote():
+		data, err = s.fetch()
+	default:
+		data, err = os.ReadFile(s.Location)
+		if err == nil {
+			err = s.verify(data)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policy %s: %w", s.Location, err)
+	}
+
+	policy, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", s.Location, err)
+	}
+
+	return policy, nil
+}
+
+// fetch downloads a remote policy, using the cache to avoid needless downloads and survive outages
+// A cached copy matching the pinned checksum is used without contacting the server at all
+func (s Source) fetch() ([]byte, error) {
+	cached, cachedETag := s.readCache()
+	if cached != nil && s.SHA256 != "" && s.verify(cached) == nil {
+		return cached, nil
+	}
+
+	data, etag, notModified, fetchErr := s.download(cachedETag)
+	if fetchErr == nil && notModified {
+		data, etag = cached, cachedETag
+	}
+	if fetchErr != nil {
+		// Fall back to the last good copy when the server is unreachable
+		if cached == nil {
+			return nil, fetchErr
+		}
+		data, etag = cached, cachedETag
+		if s.Stale != nil {
+			s.Stale(fetchErr)
+		}
+	}
+
+	if err := s.verify(data); err != nil {
+		return nil, err
+	}
+
+	if fetchErr == nil && !notModified {
+		if err := s.writeCache(data, etag); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// download performs a conditional GET for the policy
+func (s Source) download(etag string) (data []byte, newETag string, notModified bool, err error) {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	request, err := http.NewRequest(http.MethodGet, s.Location, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		if etag != "" {
+			return nil, etag, true, nil
+		}
+		fallthrough
+	default:
+		return nil, "", false, fmt.Errorf("unexpected HTTP status %s", response.Status)
+	}
+
+	data, err = io.ReadAll(io.LimitReader(response.Body, maxPolicySize+1))
+	if err != nil {
+		return nil, "", false, err
+	}
+	if len(data) > maxPolicySize {
+		return nil, "", false, fmt.Errorf("policy exceeds %d bytes", maxPolicySize)
+	}
+
+	return data, response.Header.Get("ETag"), false, nil
+}
+
+// verify checks data against the pinned checksum, if any
+func (s Source) verify(data []byte) error {
+	if s.SHA256 == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256(data)
+	actual := hex.EncodeToString(sum[:])
+	if !strings.EqualFold(actual, s.SHA256) {
+		return fmt.Errorf("%w: expected %s, got %s", ErrChecksumMismatch, strings.ToLower(s.SHA256), actual)
+	}
+
+	return nil
+}
+
+// cachePath returns the cache file for the policy URL; the ETag is stored next to it
+func (s Source) cachePath() string {
+	sum := sha256.Sum256([]byte(s.Location))
+	return filepath.Join(s.CacheDir, hex.EncodeToString(sum[:16])+".policy")
+}
+
+// readCache returns the cached policy and its ETag, or nil if nothing is cached
+func (s Source) readCache() ([]byte, string) {
+	if s.CacheDir == "" {
+		return nil, ""
+	}
+
+	data, err := os.ReadFile(s.cachePath())
+	if err != nil {
+		return nil, ""
+	}
+
+	etag, _ := os.ReadFile(s.cachePath() + ".etag")
+	return data, string(etag)
+}
+
+// writeCache stores a verified policy and its ETag
+func (s Source) writeCache(data []byte, etag string) error {
+	if s.CacheDir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(s.CacheDir, 0755); err != nil {
+		return fmt.Errorf("failed to create policy cache %s: %w", s.CacheDir, err)
+	}
+	if err := os.WriteFile(s.cachePath(), data, 0644); err != nil {
+		return fmt.Errorf("failed to cache policy: %w", err)
+	}
+
+	etagPath := s.cachePath() + ".etag"
+	if etag == "" {
+		_ = os.Remove(etagPath)
+		return nil
+	}
+	if err := os.WriteFile(etagPath, []byte(etag), 0644); err != nil {
+		return fmt.Errorf("failed to cache policy: %w", err)
+	}
+
+	return nil
+}
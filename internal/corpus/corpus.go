@@ -0,0 +1,129 @@
+// Package corpus checks a naming pipeline against a golden corpus of names and their expected sanitized forms.
+// Rule authors keep such a corpus next to their policy and run it in CI, like the built-in rules are tested.
+package corpus
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Extension is the file extension of corpus files; other files in a corpus directory are ignored
+const Extension = ".tsv"
+
+// Case is a single name of the corpus and the name it must be sanitized to
+type Case struct {
+	File     string // Corpus file the case was read from, relative to the corpus directory
+	Line     int    // Line of the case in its file
+	Input    string
+	Expected string
+}
+
+// Mismatch is a case the pipeline sanitized differently than expected
+type Mismatch struct {
+	Case
+	Got   string   // Name the pipeline produced
+	Rules []string // Rules that fired, when the pipeline reports them
+}
+
+// Load reads every corpus file below dir in lexical order
+func Load(dir string) ([]Case, error) {
+	var cases []Case
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(path) != Extension {
+			return nil
+		}
+
+		relative, err := filepath.Rel(dir, path)
+		if err != nil {
+			relative = path
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open corpus file: %w", err)
+		}
+		defer file.Close()
+
+		fileCases, err := Parse(relative, file)
+		if err != nil {
+			return err
+		}
+		cases = append(cases, fileCases...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("no cases found in %s (corpus files end in %s)", dir, Extension)
+	}
+
+	return cases, nil
+}
+
+// Parse reads cases, one per line as INPUT<tab>EXPECTED; blank lines and lines starting with # are ignored
+// Either side can be written as a Go quoted string, for names with surrounding spaces, tabs or control characters
+func Parse(file string, r io.Reader) ([]Case, error) {
+	var cases []Case
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		input, expected, found := strings.Cut(line, "\t")
+		if !found {
+			return nil, fmt.Errorf("corpus %s, line %d: expected INPUT<tab>EXPECTED", file, lineNumber)
+		}
+		testCase := Case{File: file, Line: lineNumber}
+		var err error
+		if testCase.Input, err = unquote(input); err != nil {
+			return nil, fmt.Errorf("corpus %s, line %d: %w", file, lineNumber, err)
+		}
+		if testCase.Expected, err = unquote(expected); err != nil {
+			return nil, fmt.Errorf("corpus %s, line %d: %w", file, lineNumber, err)
+		}
+		cases = append(cases, testCase)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read corpus %s: %w", file, err)
+	}
+
+	return cases, nil
+}
+
+// unquote returns a Go quoted string without its quotes and any other text as is
+func unquote(text string) (string, error) {
+	if !strings.HasPrefix(text, `"`) {
+		return text, nil
+	}
+	unquoted, err := strconv.Unquote(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted string %s", text)
+	}
+	return unquoted, nil
+}
+
+// Run sanitizes the input of every case and returns the cases with a different result, in corpus order
+func Run(sanitizer interfaces.FolderSanitizer, cases []Case) []Mismatch {
+	var mismatches []Mismatch
+	for _, testCase := range cases {
+		got, rules := interfaces.Explain(sanitizer, interfaces.FolderInfo{Name: testCase.Input})
+		if got != testCase.Expected {
+			mismatches = append(mismatches, Mismatch{Case: testCase, Got: got, Rules: rules})
+		}
+	}
+	return mismatches
+}
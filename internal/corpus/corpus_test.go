@@ -0,0 +1,77 @@
+// Package corpus_test provides tests for golden corpus checks.
+// This test suite ensures corpus files parse and mismatches are reported with their location.
+package corpus_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/corpus"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+)
+
+// TestParse tests quoting, comments and malformed lines
+func TestParse(t *testing.T) {
+	content := "# Names\n\nReport: Q1\tReport_ Q1\n\"  padded \"\tpadded\r\n\"#tag\"\t#tag\n"
+	cases, err := corpus.Parse("names.tsv", strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if len(cases) != 3 {
+		t.Fatalf("Expected 3 cases, got %+v", cases)
+	}
+	if cases[1].Input != "  padded " || cases[1].Expected != "padded" || cases[1].Line != 4 {
+		t.Errorf("Unexpected quoted case %+v", cases[1])
+	}
+
+	for _, invalid := range []string{"no tab here", "\"unterminated\tx"} {
+		if _, err := corpus.Parse("bad.tsv", strings.NewReader(invalid)); err == nil {
+			t.Errorf("Parse(%q) expected error", invalid)
+		}
+	}
+}
+
+// TestRun tests that only cases with a different result are reported, in corpus order
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.tsv":       "CON\tCON_\nCafé\tCafe\n",
+		"sub/b.tsv":   "a:b\ta-b\n",
+		"ignored.txt": "x\ty\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases, err := corpus.Load(dir)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if len(cases) != 3 {
+		t.Fatalf("Expected the 3 cases of the .tsv files, got %+v", cases)
+	}
+
+	mismatches := corpus.Run(sanitizer.NewWindowsSanitizer(), cases)
+	if len(mismatches) != 1 {
+		t.Fatalf("Expected 1 mismatch, got %+v", mismatches)
+	}
+	mismatch := mismatches[0]
+	if mismatch.File != filepath.Join("sub", "b.tsv") || mismatch.Line != 1 || mismatch.Got != "a_b" {
+		t.Errorf("Unexpected mismatch %+v", mismatch)
+	}
+	if len(mismatch.Rules) != 1 || mismatch.Rules[0] != sanitizer.RuleInvalidCharacters {
+		t.Errorf("Expected the rules that fired, got %v", mismatch.Rules)
+	}
+
+	if _, err := corpus.Load(t.TempDir()); err == nil {
+		t.Error("Expected an empty corpus to be rejected")
+	}
+}
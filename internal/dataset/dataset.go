@@ -0,0 +1,127 @@
+// Package dataset describes every directory name of a tree as a row of a violations dataset.
+// This implementation characterizes names without proposing renames, so the data can be analyzed on its own.
+package dataset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Record is one directory of the dataset
+// The struct tags name the columns of both the CSV and the Parquet output
+type Record struct {
+	Path           string   `parquet:"path"`            // Path of the directory, as shown in every other report
+	Name           string   `parquet:"name"`            // Name of the directory
+	Depth          int      `parquet:"depth"`           // Depth below the root
+	ByteLength     int      `parquet:"byte_length"`     // Length of the name in UTF-8 bytes
+	RuneLength     int      `parquet:"rune_length"`     // Length of the name in characters
+	NonASCII       int      `parquet:"non_ascii"`       // Characters outside ASCII
+	Scripts        string   `parquet:"scripts"`         // Unicode scripts of the characters with their counts, most frequent first
+	PrimaryScript  string   `parquet:"primary_script"`  // Most frequent script other than Common and Inherited (empty = none)
+	MixedScripts   bool     `parquet:"mixed_scripts"`   // Whether more than one script other than Common and Inherited occurs
+	Violations     []string `parquet:"violations,list"` // Identifiers of the rules the name violates
+	ViolationCount int      `parquet:"violation_count"` // Number of violated rules
+	Owner          string   `parquet:"owner"`           // Owner of the directory, when the walker attributes owners
+}
+
+// Columns are the column names in output order
+var Columns = []string{
+	"path", "name", "depth", "byte_length", "rune_length", "non_ascii", "scripts",
+	"primary_script", "mixed_scripts", "violations", "violation_count", "owner",
+}
+
+// NewRecord characterizes a folder whose name violates rules; path is the path to record
+func NewRecord(folder interfaces.FolderInfo, path string, rules []string) Record {
+	record := Record{
+		Path:           path,
+		Name:           folder.Name,
+		Depth:          folder.Depth,
+		ByteLength:     len(folder.Name),
+		RuneLength:     utf8.RuneCountInString(folder.Name),
+		Violations:     append([]string{}, rules...),
+		ViolationCount: len(rules),
+		Owner:          folder.Owner,
+	}
+
+	counts := make(map[string]int)
+	for _, r := range folder.Name {
+		if r > unicode.MaxASCII {
+			record.NonASCII++
+		}
+		counts[scriptOf(r)]++
+	}
+	record.Scripts, record.PrimaryScript, record.MixedScripts = composition(counts)
+
+	return record
+}
+
+// MixedScripts reports whether a name combines letters of more than one writing system, e.g. Latin and Cyrillic
+func MixedScripts(name string) bool {
+	counts := make(map[string]int)
+	for _, r := range name {
+		counts[scriptOf(r)]++
+	}
+	_, _, mixed := composition(counts)
+	return mixed
+}
+
+// composition formats the script counts and picks the primary script
+// Common (digits, punctuation, symbols) and Inherited (combining marks) belong to no writing system of their own
+func composition(counts map[string]int) (scripts, primary string, mixed bool) {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	writingSystems := 0
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s:%d", name, counts[name])
+		if name == "Common" || name == "Inherited" || name == "Unknown" {
+			continue
+		}
+		if writingSystems == 0 {
+			primary = name
+		}
+		writingSystems++
+	}
+
+	return strings.Join(parts, " "), primary, writingSystems > 1
+}
+
+// scriptNames lists the Unicode scripts in a fixed order, so lookups don't depend on map iteration
+var scriptNames = func() []string {
+	names := make([]string, 0, len(unicode.Scripts))
+	for name := range unicode.Scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}()
+
+// scriptOf returns the Unicode script of a character, or Unknown for unassigned code points and invalid UTF-8
+func scriptOf(r rune) string {
+	switch {
+	case r <= unicode.MaxASCII && unicode.IsLetter(r):
+		return "Latin"
+	case r <= unicode.MaxASCII:
+		return "Common"
+	}
+	for _, name := range scriptNames {
+		if unicode.Is(unicode.Scripts[name], r) {
+			return name
+		}
+	}
+	return "Unknown"
+}
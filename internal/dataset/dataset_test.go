@@ -0,0 +1,152 @@
+package dataset_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/punkscience/sanitize/internal/dataset"
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// TestNewRecord tests the lengths and script composition of a name
+func TestNewRecord(t *testing.T) {
+	tests := []struct {
+		name          string
+		byteLength    int
+		runeLength    int
+		nonASCII      int
+		scripts       string
+		primaryScript string
+		mixed         bool
+	}{
+		{"Reports 2024", 12, 12, 0, "Latin:7 Common:5", "Latin", false},
+		{"Café", 5, 4, 1, "Latin:4", "Latin", false},
+		{"Привет мир", 19, 10, 9, "Cyrillic:9 Common:1", "Cyrillic", false},
+		{"日本語abc", 12, 6, 3, "Han:3 Latin:3", "Han", true},
+		{"2024-01", 7, 7, 0, "Common:7", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folder := interfaces.FolderInfo{Name: tt.name, Depth: 2, Owner: "alice"}
+			record := dataset.NewRecord(folder, "/data/"+tt.name, []string{"non-ascii"})
+
+			if record.ByteLength != tt.byteLength || record.RuneLength != tt.runeLength || record.NonASCII != tt.nonASCII {
+				t.Errorf("Expected lengths %d/%d/%d, got %d/%d/%d", tt.byteLength, tt.runeLength, tt.nonASCII,
+					record.ByteLength, record.RuneLength, record.NonASCII)
+			}
+			if record.Scripts != tt.scripts {
+				t.Errorf("Expected scripts %q, got %q", tt.scripts, record.Scripts)
+			}
+			if record.PrimaryScript != tt.primaryScript || record.MixedScripts != tt.mixed {
+				t.Errorf("Expected primary script %q (mixed %v), got %q (mixed %v)", tt.primaryScript, tt.mixed, record.PrimaryScript, record.MixedScripts)
+			}
+			if record.Path != "/data/"+tt.name || record.Depth != 2 || record.Owner != "alice" || record.ViolationCount != 1 {
+				t.Errorf("Expected the folder details to be copied, got %+v", record)
+			}
+		})
+	}
+}
+
+// testRecords returns a compliant and a violating directory
+func testRecords() []dataset.Record {
+	return []dataset.Record{
+		dataset.NewRecord(interfaces.FolderInfo{Name: "ok", Depth: 1}, "ok", nil),
+		dataset.NewRecord(interfaces.FolderInfo{Name: "a:b café", Depth: 1}, "a:b café", []string{"invalid-characters", "non-ascii"}),
+	}
+}
+
+// TestCreate_CSV tests the header, the row values and the joined violations of a CSV dataset
+func TestCreate_CSV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dataset.csv")
+	writeRecords(t, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read dataset: %v", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse dataset: %v", err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("Expected a header and 2 rows, got %d rows", len(rows))
+	}
+	if !reflect.DeepEqual(rows[0], dataset.Columns) {
+		t.Errorf("Expected header %v, got %v", dataset.Columns, rows[0])
+	}
+	if rows[1][9] != "" || rows[1][10] != "0" {
+		t.Errorf("Expected no violations for the compliant row, got %v", rows[1])
+	}
+	if rows[2][9] != "invalid-characters;non-ascii" || rows[2][10] != "2" || rows[2][8] != "false" {
+		t.Errorf("Expected the violations joined with semicolons, got %v", rows[2])
+	}
+}
+
+// TestCreate_Parquet tests that a Parquet dataset reads back as the records that were written
+func TestCreate_Parquet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dataset.parquet")
+	writeRecords(t, path)
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open dataset: %v", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatalf("Failed to stat dataset: %v", err)
+	}
+
+	records, err := parquet.Read[dataset.Record](file, info.Size())
+	if err != nil {
+		t.Fatalf("Failed to read dataset: %v", err)
+	}
+	want := testRecords()
+	// An empty list may read back as nil, which holds the same violations
+	for _, rows := range [][]dataset.Record{records, want} {
+		for i := range rows {
+			if len(rows[i].Violations) == 0 {
+				rows[i].Violations = nil
+			}
+		}
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("Expected %+v, got %+v", want, records)
+	}
+}
+
+// TestCreate_UnknownFormat tests that an unsupported extension is rejected before a file is created
+func TestCreate_UnknownFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dataset.txt")
+	if _, err := dataset.Create(path); err == nil {
+		t.Fatal("Expected an error for an unknown format")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no file to be created, got %v", err)
+	}
+}
+
+// writeRecords writes the test records to a dataset at path
+func writeRecords(t *testing.T, path string) {
+	t.Helper()
+	writer, err := dataset.Create(path)
+	if err != nil {
+		t.Fatalf("Create() returned error: %v", err)
+	}
+	for _, record := range testRecords() {
+		if err := writer.Write(record); err != nil {
+			t.Fatalf("Write() returned error: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() returned error: %v", err)
+	}
+}
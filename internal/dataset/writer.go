@@ -0,0 +1,113 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Writer writes records to a dataset file
+type Writer interface {
+	// Write appends a record
+	Write(record Record) error
+	// Close flushes the dataset and closes the file
+	Close() error
+}
+
+// Create creates the dataset file at path in the format its extension selects: .csv or .parquet
+func Create(path string) (Writer, error) {
+	extension := strings.ToLower(filepath.Ext(path))
+	if extension != ".csv" && extension != ".parquet" {
+		return nil, fmt.Errorf("unknown dataset format %q (want a .csv or .parquet file)", extension)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dataset: %w", err)
+	}
+	if extension == ".parquet" {
+		return NewParquetWriter(file), nil
+	}
+	return NewCSVWriter(file)
+}
+
+// CSVWriter writes records as CSV with a header row; violations are joined with semicolons
+type CSVWriter struct {
+	out    io.WriteCloser
+	writer *csv.Writer
+}
+
+// NewCSVWriter writes the header row to out and returns a writer for the records
+func NewCSVWriter(out io.WriteCloser) (*CSVWriter, error) {
+	w := &CSVWriter{out: out, writer: csv.NewWriter(out)}
+	if err := w.writer.Write(Columns); err != nil {
+		return nil, fmt.Errorf("failed to write dataset: %w", err)
+	}
+	return w, nil
+}
+
+// Write appends a record as a CSV row
+func (w *CSVWriter) Write(record Record) error {
+	err := w.writer.Write([]string{
+		record.Path,
+		record.Name,
+		strconv.Itoa(record.Depth),
+		strconv.Itoa(record.ByteLength),
+		strconv.Itoa(record.RuneLength),
+		strconv.Itoa(record.NonASCII),
+		record.Scripts,
+		record.PrimaryScript,
+		strconv.FormatBool(record.MixedScripts),
+		strings.Join(record.Violations, ";"),
+		strconv.Itoa(record.ViolationCount),
+		record.Owner,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write dataset: %w", err)
+	}
+	return nil
+}
+
+// Close flushes the rows and closes the output
+func (w *CSVWriter) Close() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		w.out.Close()
+		return fmt.Errorf("failed to write dataset: %w", err)
+	}
+	return w.out.Close()
+}
+
+// ParquetWriter writes records as a Parquet file; violations are a list column
+type ParquetWriter struct {
+	out    io.WriteCloser
+	writer *parquet.GenericWriter[Record]
+}
+
+// NewParquetWriter returns a writer for the records; the file is complete once Close returns
+func NewParquetWriter(out io.WriteCloser) *ParquetWriter {
+	return &ParquetWriter{out: out, writer: parquet.NewGenericWriter[Record](out)}
+}
+
+// Write appends a record; rows are buffered and written in row groups
+func (w *ParquetWriter) Write(record Record) error {
+	if _, err := w.writer.Write([]Record{record}); err != nil {
+		return fmt.Errorf("failed to write dataset: %w", err)
+	}
+	return nil
+}
+
+// Close writes the remaining rows and the footer and closes the output
+func (w *ParquetWriter) Close() error {
+	if err := w.writer.Close(); err != nil {
+		w.out.Close()
+		return fmt.Errorf("failed to write dataset: %w", err)
+	}
+	return w.out.Close()
+}
@@ -0,0 +1,79 @@
+// Package diff compares names character by character.
+// This implementation finds the longest common subsequence of runes, which is fast enough for names of a few hundred characters.
+package diff
+
+// Op says whether a segment is kept, removed or added
+type Op int
+
+const (
+	Equal  Op = iota // Text appears in both names
+	Delete           // Text appears only in the old name
+	Insert           // Text appears only in the new name
+)
+
+// Segment is a run of characters with the same Op
+type Segment struct {
+	Op   Op
+	Text string
+}
+
+// Runes returns the segments that turn before into after
+// Within every changed region the deleted text comes before the inserted text
+func Runes(before, after string) []Segment {
+	a, b := []rune(before), []rune(after)
+
+	// common[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var segments []Segment
+	var deleted, inserted []rune
+	flush := func() {
+		segments = appendSegment(segments, Delete, deleted)
+		segments = appendSegment(segments, Insert, inserted)
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			segments = appendSegment(segments, Equal, a[i:i+1])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && common[i+1][j] >= common[i][j+1]):
+			deleted = append(deleted, a[i])
+			i++
+		default:
+			inserted = append(inserted, b[j])
+			j++
+		}
+	}
+	flush()
+
+	return segments
+}
+
+// appendSegment adds text to the last segment if it has the same Op, or starts a new one
+func appendSegment(segments []Segment, op Op, text []rune) []Segment {
+	if len(text) == 0 {
+		return segments
+	}
+	if last := len(segments) - 1; last >= 0 && segments[last].Op == op {
+		segments[last].Text += string(text)
+		return segments
+	}
+	return append(segments, Segment{Op: op, Text: string(text)})
+}
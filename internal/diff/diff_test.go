@@ -0,0 +1,50 @@
+// Package diff_test provides tests for the diff package.
+// This test suite ensures character-level diffs reproduce both names and group changes readably.
+package diff_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/diff"
+)
+
+// TestRunes tests the segments for typical sanitizer changes
+func TestRunes(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   []diff.Segment
+	}{
+		{"unchanged", "docs", "docs", []diff.Segment{{diff.Equal, "docs"}}},
+		{"replaced", "a:b", "a_b", []diff.Segment{{diff.Equal, "a"}, {diff.Delete, ":"}, {diff.Insert, "_"}, {diff.Equal, "b"}}},
+		{"trailing period", "notes.", "notes", []diff.Segment{{diff.Equal, "notes"}, {diff.Delete, "."}}},
+		{"suffix", "CON", "CON_", []diff.Segment{{diff.Equal, "CON"}, {diff.Insert, "_"}}},
+		{"unicode", "Café", "Cafe", []diff.Segment{{diff.Equal, "Caf"}, {diff.Delete, "é"}, {diff.Insert, "e"}}},
+		{"empty", "", "unnamed", []diff.Segment{{diff.Insert, "unnamed"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diff.Runes(tt.before, tt.after)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Runes(%q, %q) = %+v, want %+v", tt.before, tt.after, got, tt.want)
+			}
+
+			// Equal and deleted text rebuild the old name, equal and inserted text the new one
+			var before, after string
+			for _, segment := range got {
+				if segment.Op != diff.Insert {
+					before += segment.Text
+				}
+				if segment.Op != diff.Delete {
+					after += segment.Text
+				}
+			}
+			if before != tt.before || after != tt.after {
+				t.Errorf("Segments rebuild %q -> %q", before, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package failures records folders that failed to process and reloads them for a targeted re-run.
+// This implementation follows the Decorator pattern by wrapping an existing ProgressReporter.
+package failures
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/paths"
+)
+
+// fileVersion identifies the layout of the failed-items file
+const fileVersion = 1
+
+// Item is a single failed folder as stored in the failed-items file
+type Item struct {
+	Path  string `json:"path"`  // Full path to the folder at the time of failure
+	Name  string `json:"name"`  // Folder name at the time of failure
+	Depth int    `json:"depth"` // Depth level from the original root
+	Error string `json:"error"` // Error message that caused the failure
+}
+
+// Header describes the run that produced a failed-items file
+type Header struct {
+	RunID         string `json:"run_id,omitempty"`         // Identifier of the run that produced the failures
+	Root          string `json:"root"`                     // Root path of the run that produced the failures
+	RelativePaths bool   `json:"relative_paths,omitempty"` // Whether item paths are stored relative to Root
+}
+
+// File is the machine-readable failed-items document
+type File struct {
+	Version int `json:"version"` // Layout version of the document
+	Header
+	Items []Item `json:"items"` // Failed folders in processing order
+}
+
+// Recorder implements ProgressReporter, RenameReporter, FailureReporter, WarningReporter and ScanReporter
+// This struct collects failed folders while forwarding every event to the wrapped reporter
+type Recorder struct {
+	next  interfaces.ProgressReporter
+	items []Item
+}
+
+// NewRecorder creates a Recorder that wraps the provided reporter
+func NewRecorder(next interfaces.ProgressReporter) *Recorder {
+	return &Recorder{
+		next:  next,
+		items: make([]Item, 0),
+	}
+}
+
+// ReportProgress forwards progress updates to the wrapped reporter
+func (r *Recorder) ReportProgress(current, total int, message string) {
+	r.next.ReportProgress(current, total, message)
+}
+
+// ReportError forwards errors to the wrapped reporter
+func (r *Recorder) ReportError(err error) {
+	r.next.ReportError(err)
+}
+
+// ReportWarning forwards warnings when the wrapped reporter supports them
+func (r *Recorder) ReportWarning(warning error) {
+	if warningReporter, ok := r.next.(interfaces.WarningReporter); ok {
+		warningReporter.ReportWarning(warning)
+	}
+}
+
+// ReportScan forwards the discovery progress when the wrapped reporter shows it
+func (r *Recorder) ReportScan(scanned int, path string) {
+	if scanReporter, ok := r.next.(interfaces.ScanReporter); ok {
+		scanReporter.ReportScan(scanned, path)
+	}
+}
+
+// ReportComplete forwards the summary to the wrapped reporter
+func (r *Recorder) ReportComplete(summary interfaces.ProcessingSummary) {
+	r.next.ReportComplete(summary)
+}
+
+// ReportRename forwards renames when the wrapped reporter supports them
+func (r *Recorder) ReportRename(result interfaces.RenameResult) {
+	if renameReporter, ok := r.next.(interfaces.RenameReporter); ok {
+		renameReporter.ReportRename(result)
+	}
+}
+
+// ReportFolder forwards the current folder when the wrapped reporter supports it
+func (r *Recorder) ReportFolder(current, total int, folder interfaces.FolderInfo) {
+	if folderReporter, ok := r.next.(interfaces.FolderReporter); ok {
+		folderReporter.ReportFolder(current, total, folder)
+	}
+}
+
+// ReportFailure records a failed folder and forwards it when the wrapped reporter collects failures too
+func (r *Recorder) ReportFailure(folder interfaces.FolderInfo, err error) {
+	r.items = append(r.items, Item{
+		Path:  folder.Path,
+		Name:  folder.Name,
+		Depth: folder.Depth,
+		Error: err.Error(),
+	})
+
+	if failureReporter, ok := r.next.(interfaces.FailureReporter); ok {
+		failureReporter.ReportFailure(folder, err)
+	}
+}
+
+// Items returns the failed folders recorded so far
+func (r *Recorder) Items() []Item {
+	return r.items
+}
+
+// Save writes the recorded failures to path as JSON
+// The file is always written so that an empty list signals a clean run
+func (r *Recorder) Save(path string, header Header) error {
+	items := make([]Item, len(r.items))
+	copy(items, r.items)
+
+	// With relative paths the root is only recorded once, in the header
+	if header.RelativePaths {
+		for i := range items {
+			items[i].Path = paths.Relative(header.Root, items[i].Path)
+		}
+	}
+
+	data, err := json.MarshalIndent(File{
+		Version: fileVersion,
+		Header:  header,
+		Items:   items,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode failed items: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write failed items to %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// Load reads a failed-items file and returns the folders to retry
+// Relative item paths are resolved against root, which may differ from the root of the original run
+// Name and Parent are derived from the resolved path so the list feeds straight into the processor
+func Load(path, root string) ([]interfaces.FolderInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read failed items from %s: %w", path, err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode failed items from %s: %w", path, err)
+	}
+
+	if file.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported failed items version %d in %s", file.Version, path)
+	}
+
+	folders := make([]interfaces.FolderInfo, 0, len(file.Items))
+	for _, item := range file.Items {
+		itemPath := filepath.FromSlash(item.Path)
+		if file.RelativePaths {
+			itemPath = paths.Resolve(root, item.Path)
+		}
+
+		folders = append(folders, interfaces.FolderInfo{
+			Path:   itemPath,
+			Name:   filepath.Base(itemPath),
+			Depth:  item.Depth,
+			Parent: filepath.Dir(itemPath),
+		})
+	}
+
+	return folders, nil
+}
@@ -0,0 +1,79 @@
+// Package failures_test provides tests for the failures package.
+// This test suite ensures failed items survive a save/load round trip.
+package failures_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/failures"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/reporter"
+)
+
+// TestRecorder_SaveAndLoad tests that recorded failures can be reloaded for a retry
+func TestRecorder_SaveAndLoad(t *testing.T) {
+	recorder := failures.NewRecorder(reporter.NewNopReporter())
+	recorder.ReportFailure(interfaces.FolderInfo{
+		Path:   "/data/bad<name>",
+		Name:   "bad<name>",
+		Depth:  1,
+		Parent: "/data",
+	}, errors.New("permission denied"))
+
+	path := filepath.Join(t.TempDir(), "failed.json")
+	if err := recorder.Save(path, failures.Header{RunID: "run-1", Root: "/data"}); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	folders, err := failures.Load(path, "/data")
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if len(folders) != 1 {
+		t.Fatalf("Expected 1 folder, got %d", len(folders))
+	}
+
+	folder := folders[0]
+	if folder.Path != "/data/bad<name>" || folder.Name != "bad<name>" || folder.Parent != "/data" || folder.Depth != 1 {
+		t.Errorf("Unexpected folder after round trip: %+v", folder)
+	}
+}
+
+// TestLoad_MissingFile tests that a missing retry file is reported as an error
+func TestLoad_MissingFile(t *testing.T) {
+	if _, err := failures.Load(filepath.Join(t.TempDir(), "missing.json"), "/data"); err == nil {
+		t.Error("Expected error for missing file, but got none")
+	}
+}
+
+// TestRecorder_RelativePaths tests that relative items are resolved against a different root on load
+func TestRecorder_RelativePaths(t *testing.T) {
+	oldRoot := filepath.Join(t.TempDir(), "old")
+	newRoot := filepath.Join(t.TempDir(), "new")
+
+	recorder := failures.NewRecorder(reporter.NewNopReporter())
+	recorder.ReportFailure(interfaces.FolderInfo{
+		Path:   filepath.Join(oldRoot, "a", "b"),
+		Name:   "b",
+		Depth:  2,
+		Parent: filepath.Join(oldRoot, "a"),
+	}, errors.New("permission denied"))
+
+	path := filepath.Join(t.TempDir(), "failed.json")
+	if err := recorder.Save(path, failures.Header{Root: oldRoot, RelativePaths: true}); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	folders, err := failures.Load(path, newRoot)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	expected := filepath.Join(newRoot, "a", "b")
+	if len(folders) != 1 || folders[0].Path != expected {
+		t.Errorf("Expected folder resolved to %s, got %+v", expected, folders)
+	}
+}
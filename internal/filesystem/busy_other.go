@@ -0,0 +1,13 @@
+//go:build !unix && !windows
+
+package filesystem
+
+import (
+	"syscall"
+)
+
+// busyErrnos is empty; busy files are not recognized on this platform
+var busyErrnos []syscall.Errno
+
+// lockedErrnos is empty; locked files are not recognized on this platform
+var lockedErrnos []syscall.Errno
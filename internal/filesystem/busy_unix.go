@@ -0,0 +1,20 @@
+//go:build unix
+
+package filesystem
+
+import (
+	"syscall"
+)
+
+// busyErrnos are the errors reported while another process holds a file or directory
+var busyErrnos = []syscall.Errno{
+	syscall.EBUSY,
+	syscall.EAGAIN,
+	syscall.ETXTBSY,
+}
+
+// lockedErrnos are the busy errors that mean a process holds the file or directory open, rather than a passing hiccup
+var lockedErrnos = []syscall.Errno{
+	syscall.EBUSY,
+	syscall.ETXTBSY,
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"syscall"
+)
+
+// busyErrnos are the errors Windows reports while another process holds a file or directory
+// Virus scanners, indexers and Explorer windows briefly holding a handle surface as access denied
+var busyErrnos = []syscall.Errno{
+	5,  // ERROR_ACCESS_DENIED
+	32, // ERROR_SHARING_VIOLATION
+	33, // ERROR_LOCK_VIOLATION
+}
+
+// lockedErrnos are the busy errors that mean a process holds the file or directory open, e.g. an Explorer window or an editor
+var lockedErrnos = []syscall.Errno{
+	5,  // ERROR_ACCESS_DENIED
+	32, // ERROR_SHARING_VIOLATION
+	33, // ERROR_LOCK_VIOLATION
+}
@@ -0,0 +1,51 @@
+package filesystem
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// caseRenameSuffix marks the temporary name a case-only rename passes through
+const caseRenameSuffix = ".sanitize-case"
+
+// IsCaseOnlyRename reports whether renaming oldPath to newPath only changes the case of the name on a file system that ignores case
+// Both paths then resolve to the same directory, so a direct rename may do nothing or fail (NTFS, APFS, SMB shares).
+func IsCaseOnlyRename(fileSystem interfaces.FileSystem, oldPath, newPath string) bool {
+	oldName, newName := filepath.Base(oldPath), filepath.Base(newPath)
+	if oldName == newName || !strings.EqualFold(oldName, newName) || filepath.Dir(oldPath) != filepath.Dir(newPath) {
+		return false
+	}
+
+	oldInfo, err := fileSystem.Lstat(oldPath)
+	if err != nil {
+		return false
+	}
+	newInfo, err := fileSystem.Lstat(newPath)
+	return err == nil && fileSystem.SameFile(oldInfo, newInfo)
+}
+
+// RenameCaseOnly changes the case of oldPath's name by renaming it through a temporary sibling name with rename
+// If the second step fails, the folder is moved back to oldPath so it isn't left under the temporary name.
+func RenameCaseOnly(fileSystem interfaces.FileSystem, rename func(oldPath, newPath string) error, oldPath, newPath string) error {
+	temporaryPath := newPath + caseRenameSuffix
+	for counter := 1; ; counter++ {
+		if _, err := fileSystem.Lstat(temporaryPath); err != nil {
+			break
+		}
+		temporaryPath = fmt.Sprintf("%s%s-%d", newPath, caseRenameSuffix, counter)
+	}
+
+	if err := rename(oldPath, temporaryPath); err != nil {
+		return err
+	}
+	if err := rename(temporaryPath, newPath); err != nil {
+		if restoreErr := fileSystem.Rename(temporaryPath, oldPath); restoreErr != nil {
+			return fmt.Errorf("%w (the folder was left at '%s': %v)", err, temporaryPath, restoreErr)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+package filesystem
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// MemoryFileSystem implements the FileSystem interface entirely in memory
+// This struct enables fast, hermetic tests of the walker, processor and service
+type MemoryFileSystem struct {
+	mu    sync.RWMutex
+	nodes map[string]*memoryNode // Keyed by clean path, folded to lower case when foldCase is set
+	// foldCase makes paths that differ only in case refer to the same node, like NTFS and APFS
+	foldCase bool
+}
+
+// memoryNode is a single file or directory in a MemoryFileSystem
+type memoryNode struct {
+	name    string
+	isDir   bool
+	size    int64
+	content []byte
+	modTime time.Time
+}
+
+// NewMemoryFileSystem creates an empty in-memory file system
+// The root of any absolute path is created implicitly
+func NewMemoryFileSystem() *MemoryFileSystem {
+	return &MemoryFileSystem{
+		nodes: make(map[string]*memoryNode),
+	}
+}
+
+// NewCaseInsensitiveMemoryFileSystem creates an empty in-memory file system that ignores case, like NTFS and APFS
+// Names keep the case they were created or renamed with.
+func NewCaseInsensitiveMemoryFileSystem() *MemoryFileSystem {
+	return &MemoryFileSystem{
+		nodes:    make(map[string]*memoryNode),
+		foldCase: true,
+	}
+}
+
+// key returns the key of path in the node map
+func (m *MemoryFileSystem) key(path string) string {
+	path = filepath.Clean(path)
+	if m.foldCase {
+		return strings.ToLower(path)
+	}
+	return path
+}
+
+// MkdirAll creates a directory and all missing parents
+func (m *MemoryFileSystem) MkdirAll(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for current := filepath.Clean(path); !m.isRoot(current); current = filepath.Dir(current) {
+		if _, exists := m.nodes[m.key(current)]; exists {
+			break
+		}
+		m.nodes[m.key(current)] = &memoryNode{name: filepath.Base(current), isDir: true, modTime: time.Now()}
+	}
+}
+
+// WriteFile creates a file (and its parents) with the given content
+func (m *MemoryFileSystem) WriteFile(path string, content []byte) {
+	path = filepath.Clean(path)
+	m.MkdirAll(filepath.Dir(path))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nodes[m.key(path)] = &memoryNode{
+		name:    filepath.Base(path),
+		size:    int64(len(content)),
+		content: append([]byte(nil), content...),
+		modTime: time.Now(),
+	}
+}
+
+// Exists reports whether a path exists
+func (m *MemoryFileSystem) Exists(path string) bool {
+	_, err := m.Stat(path)
+	return err == nil
+}
+
+// Stat returns information about a path
+func (m *MemoryFileSystem) Stat(path string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	path = filepath.Clean(path)
+	if m.isRoot(path) {
+		return &memoryNode{name: path, isDir: true}, nil
+	}
+
+	node, exists := m.nodes[m.key(path)]
+	if !exists {
+		return nil, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+	}
+	return node, nil
+}
+
+// Lstat returns information about a path; the memory file system has no symbolic links
+func (m *MemoryFileSystem) Lstat(path string) (fs.FileInfo, error) {
+	return m.Stat(path)
+}
+
+// ReadDir returns the entries of a directory sorted by name
+func (m *MemoryFileSystem) ReadDir(path string) ([]fs.DirEntry, error) {
+	info, err := m.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: path, Err: fs.ErrInvalid}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	path = m.key(path)
+	var entries []fs.DirEntry
+	for nodePath, node := range m.nodes {
+		if filepath.Dir(nodePath) == path && nodePath != path {
+			entries = append(entries, fs.FileInfoToDirEntry(node))
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+// ReadFile returns the content of a file
+func (m *MemoryFileSystem) ReadFile(path string) ([]byte, error) {
+	info, err := m.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, &fs.PathError{Op: "read", Path: path, Err: fs.ErrInvalid}
+	}
+	return append([]byte(nil), info.(*memoryNode).content...), nil
+}
+
+// Rename moves oldPath and everything below it to newPath
+func (m *MemoryFileSystem) Rename(oldPath, newPath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// A case-only rename of a case-insensitive file system finds its own source as the target and fails
+	oldKey, newKey := m.key(oldPath), m.key(newPath)
+	node, exists := m.nodes[oldKey]
+	if !exists {
+		return &fs.PathError{Op: "rename", Path: oldPath, Err: fs.ErrNotExist}
+	}
+	if _, exists := m.nodes[newKey]; exists {
+		return &fs.PathError{Op: "rename", Path: newPath, Err: fs.ErrExist}
+	}
+	if parent := filepath.Dir(newKey); !m.isRoot(parent) {
+		if parentNode, exists := m.nodes[parent]; !exists || !parentNode.isDir {
+			return &fs.PathError{Op: "rename", Path: newPath, Err: fs.ErrNotExist}
+		}
+	}
+
+	// Collect descendants first so the map isn't modified while iterating
+	prefix := oldKey + string(filepath.Separator)
+	moved := make(map[string]*memoryNode)
+	for nodePath, child := range m.nodes {
+		if strings.HasPrefix(nodePath, prefix) {
+			moved[newKey+string(filepath.Separator)+strings.TrimPrefix(nodePath, prefix)] = child
+			delete(m.nodes, nodePath)
+		}
+	}
+	for nodePath, child := range moved {
+		m.nodes[nodePath] = child
+	}
+
+	delete(m.nodes, oldKey)
+	node.name = filepath.Base(filepath.Clean(newPath))
+	m.nodes[newKey] = node
+
+	return nil
+}
+
+// Remove deletes a file or an empty directory
+func (m *MemoryFileSystem) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := m.key(path)
+	if _, exists := m.nodes[key]; !exists {
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrNotExist}
+	}
+
+	prefix := key + string(filepath.Separator)
+	for nodePath := range m.nodes {
+		if strings.HasPrefix(nodePath, prefix) {
+			return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrExist}
+		}
+	}
+
+	delete(m.nodes, key)
+	return nil
+}
+
+// SameFile reports whether two FileInfos describe the same node
+func (m *MemoryFileSystem) SameFile(a, b fs.FileInfo) bool {
+	nodeA, okA := a.(*memoryNode)
+	nodeB, okB := b.(*memoryNode)
+	return okA && okB && nodeA == nodeB
+}
+
+// isRoot reports whether path is a file system root (always present)
+func (m *MemoryFileSystem) isRoot(path string) bool {
+	return path == filepath.Dir(path)
+}
+
+// Name returns the base name of the node
+func (n *memoryNode) Name() string { return n.name }
+
+// Size returns the content size of a file
+func (n *memoryNode) Size() int64 { return n.size }
+
+// Mode returns the file mode bits
+func (n *memoryNode) Mode() fs.FileMode {
+	if n.isDir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
+
+// ModTime returns the modification time
+func (n *memoryNode) ModTime() time.Time { return n.modTime }
+
+// IsDir reports whether the node is a directory
+func (n *memoryNode) IsDir() bool { return n.isDir }
+
+// Sys returns nil; the memory file system has no underlying data source
+func (n *memoryNode) Sys() any { return nil }
+
+// Ensure MemoryFileSystem satisfies the FileSystem contract
+var _ interfaces.FileSystem = (*MemoryFileSystem)(nil)
@@ -0,0 +1,88 @@
+// Package filesystem_test provides tests for the filesystem package.
+// This test suite ensures the in-memory backend behaves like a real file system.
+package filesystem_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+)
+
+// TestMemoryFileSystem_Rename tests that renaming a directory moves its whole subtree
+func TestMemoryFileSystem_Rename(t *testing.T) {
+	m := filesystem.NewMemoryFileSystem()
+	m.WriteFile("/root/old/sub/file.txt", []byte("data"))
+
+	if err := m.Rename("/root/old", "/root/new"); err != nil {
+		t.Fatalf("Rename() returned error: %v", err)
+	}
+
+	if !m.Exists("/root/new/sub/file.txt") {
+		t.Error("Expected descendants to move with the renamed directory")
+	}
+	if m.Exists("/root/old") {
+		t.Error("Expected old path to be gone")
+	}
+
+	// Renaming onto an existing path must fail
+	m.MkdirAll("/root/other")
+	if err := m.Rename("/root/new", "/root/other"); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("Expected ErrExist, got %v", err)
+	}
+}
+
+// TestMemoryFileSystem_ReadDirAndRemove tests listing order and empty-only removal
+func TestMemoryFileSystem_ReadDirAndRemove(t *testing.T) {
+	m := filesystem.NewMemoryFileSystem()
+	m.MkdirAll("/root/b")
+	m.WriteFile("/root/a/file.txt", nil)
+
+	entries, err := m.ReadDir("/root")
+	if err != nil {
+		t.Fatalf("ReadDir() returned error: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name() != "a" || entries[1].Name() != "b" {
+		t.Errorf("Expected sorted entries [a b], got %v", entries)
+	}
+
+	if err := m.Remove("/root/a"); err == nil {
+		t.Error("Expected error removing a non-empty directory")
+	}
+	if err := m.Remove("/root/b"); err != nil {
+		t.Errorf("Remove() of empty directory returned error: %v", err)
+	}
+}
+
+// TestMemoryFileSystem_CaseInsensitive tests that paths differing only in case name the same node and keep their case
+func TestMemoryFileSystem_CaseInsensitive(t *testing.T) {
+	m := filesystem.NewCaseInsensitiveMemoryFileSystem()
+	m.WriteFile("/root/FOLDER/file.txt", []byte("data"))
+
+	if !m.Exists("/root/folder/FILE.TXT") {
+		t.Error("Expected lookups to ignore case")
+	}
+
+	// Like on NTFS and APFS, the target of a case-only rename is the source itself
+	if err := m.Rename("/root/FOLDER", "/root/Folder"); !errors.Is(err, fs.ErrExist) {
+		t.Errorf("Expected ErrExist for a direct case-only rename, got %v", err)
+	}
+	if !filesystem.IsCaseOnlyRename(m, "/root/FOLDER", "/root/Folder") {
+		t.Fatal("Expected a case-only rename to be detected")
+	}
+	if err := filesystem.RenameCaseOnly(m, m.Rename, "/root/FOLDER", "/root/Folder"); err != nil {
+		t.Fatalf("RenameCaseOnly() returned error: %v", err)
+	}
+
+	entries, err := m.ReadDir("/root")
+	if err != nil || len(entries) != 1 || entries[0].Name() != "Folder" {
+		t.Errorf("Expected the folder to be listed as Folder, got %v, %v", entries, err)
+	}
+	if !m.Exists("/root/Folder/file.txt") {
+		t.Error("Expected the contents to move with the folder")
+	}
+	if filesystem.IsCaseOnlyRename(filesystem.NewMemoryFileSystem(), "/root/FOLDER", "/root/Folder") {
+		t.Error("Expected no case-only rename on a case-sensitive file system")
+	}
+}
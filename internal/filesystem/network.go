@@ -0,0 +1,122 @@
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// NetworkFileSystem decorates a FileSystem for network shares that may be slow, stale or disconnect
+// Reads are retried with exponential backoff on network errors; errors that persist are wrapped
+// with interfaces.ErrFileSystemUnavailable so the run can stop with a clear message
+type NetworkFileSystem struct {
+	base    interfaces.FileSystem
+	retries int
+	delay   time.Duration
+	sleep   func(time.Duration)
+}
+
+// NewNetworkFileSystem wraps base, retrying network errors up to retries times starting at delay
+// Renames and removals are never retried here: a lost reply can't be told apart from a failed operation
+func NewNetworkFileSystem(base interfaces.FileSystem, retries int, delay time.Duration) *NetworkFileSystem {
+	return &NetworkFileSystem{
+		base:    base,
+		retries: retries,
+		delay:   delay,
+		sleep:   time.Sleep,
+	}
+}
+
+// IsNetworkError reports whether err was caused by an unreachable, stale or disconnected network file system
+func IsNetworkError(err error) bool {
+	var errno syscall.Errno
+	return errors.As(err, &errno) && slices.Contains(networkErrnos, errno)
+}
+
+// Stat returns information about a path, retrying network errors
+func (n *NetworkFileSystem) Stat(path string) (fs.FileInfo, error) {
+	var info fs.FileInfo
+	err := n.retry(func() (err error) {
+		info, err = n.base.Stat(path)
+		return err
+	})
+	return info, err
+}
+
+// Lstat returns information about a path without following symbolic links, retrying network errors
+func (n *NetworkFileSystem) Lstat(path string) (fs.FileInfo, error) {
+	var info fs.FileInfo
+	err := n.retry(func() (err error) {
+		info, err = n.base.Lstat(path)
+		return err
+	})
+	return info, err
+}
+
+// ReadDir returns the entries of a directory, retrying network errors
+func (n *NetworkFileSystem) ReadDir(path string) ([]fs.DirEntry, error) {
+	var entries []fs.DirEntry
+	err := n.retry(func() (err error) {
+		entries, err = n.base.ReadDir(path)
+		return err
+	})
+	return entries, err
+}
+
+// ReadFile returns the content of a file, retrying network errors
+func (n *NetworkFileSystem) ReadFile(path string) ([]byte, error) {
+	var content []byte
+	err := n.retry(func() (err error) {
+		content, err = n.base.ReadFile(path)
+		return err
+	})
+	return content, err
+}
+
+// Rename moves oldPath to newPath once, marking network errors as unavailability
+func (n *NetworkFileSystem) Rename(oldPath, newPath string) error {
+	return unavailable(n.base.Rename(oldPath, newPath))
+}
+
+// Remove deletes a file or an empty directory once, marking network errors as unavailability
+func (n *NetworkFileSystem) Remove(path string) error {
+	return unavailable(n.base.Remove(path))
+}
+
+// SameFile reports whether two FileInfos describe the same file
+func (n *NetworkFileSystem) SameFile(a, b fs.FileInfo) bool {
+	return n.base.SameFile(a, b)
+}
+
+// retry runs op until it succeeds, fails with a non-network error or the retries are used up
+func (n *NetworkFileSystem) retry(op func() error) error {
+	delay := n.delay
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || !IsNetworkError(err) {
+			return err
+		}
+		if attempt >= n.retries {
+			return unavailable(err)
+		}
+
+		n.sleep(delay)
+		delay *= 2
+	}
+}
+
+// unavailable wraps network errors with interfaces.ErrFileSystemUnavailable; other errors are returned unchanged
+func unavailable(err error) error {
+	if err == nil || !IsNetworkError(err) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", interfaces.ErrFileSystemUnavailable, err)
+}
+
+// Ensure NetworkFileSystem satisfies the FileSystem contract
+var _ interfaces.FileSystem = (*NetworkFileSystem)(nil)
@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package filesystem
+
+import (
+	"syscall"
+)
+
+// networkErrnos is empty; network file system errors are not recognized on this platform
+var networkErrnos []syscall.Errno
@@ -0,0 +1,79 @@
+// Package filesystem_test provides tests for the filesystem package.
+// This test suite ensures network errors are retried and reported as unavailability.
+package filesystem_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// flakyFileSystem fails the first failures calls to Stat and every Rename with a network error
+type flakyFileSystem struct {
+	*filesystem.MemoryFileSystem
+	failures int
+	calls    int
+}
+
+// Stat fails with a network error until the configured number of failures has been returned
+func (f *flakyFileSystem) Stat(path string) (fs.FileInfo, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, &fs.PathError{Op: "stat", Path: path, Err: syscall.ETIMEDOUT}
+	}
+	return f.MemoryFileSystem.Stat(path)
+}
+
+// Rename always fails with a network error
+func (f *flakyFileSystem) Rename(oldPath, newPath string) error {
+	f.calls++
+	return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: syscall.ETIMEDOUT}
+}
+
+// TestNetworkFileSystem tests retries of network errors and the unavailability error once they persist
+func TestNetworkFileSystem(t *testing.T) {
+	if !filesystem.IsNetworkError(syscall.ETIMEDOUT) {
+		t.Skip("ETIMEDOUT is not a network error on this platform")
+	}
+
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/share/folder")
+
+	// Errors within the retry budget are invisible to the caller
+	flaky := &flakyFileSystem{MemoryFileSystem: memory, failures: 2}
+	network := filesystem.NewNetworkFileSystem(flaky, 2, time.Millisecond)
+	if _, err := network.Stat("/share/folder"); err != nil {
+		t.Errorf("Expected Stat() to succeed after retries, got %v", err)
+	}
+
+	// Errors that outlast the retries mean the share is gone
+	flaky = &flakyFileSystem{MemoryFileSystem: memory, failures: 5}
+	network = filesystem.NewNetworkFileSystem(flaky, 2, time.Millisecond)
+	if _, err := network.Stat("/share/folder"); !errors.Is(err, interfaces.ErrFileSystemUnavailable) {
+		t.Errorf("Expected ErrFileSystemUnavailable, got %v", err)
+	}
+	if flaky.calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", flaky.calls)
+	}
+
+	// Renames are attempted once, but still reported as unavailability
+	flaky.calls = 0
+	if err := network.Rename("/share/folder", "/share/renamed"); !errors.Is(err, interfaces.ErrFileSystemUnavailable) {
+		t.Errorf("Expected ErrFileSystemUnavailable from Rename(), got %v", err)
+	}
+	if flaky.calls != 1 {
+		t.Errorf("Expected Rename() not to be retried, got %d attempts", flaky.calls)
+	}
+
+	// Other errors pass through untouched
+	network = filesystem.NewNetworkFileSystem(memory, 2, time.Millisecond)
+	if _, err := network.Stat("/share/missing"); !errors.Is(err, fs.ErrNotExist) || errors.Is(err, interfaces.ErrFileSystemUnavailable) {
+		t.Errorf("Expected plain ErrNotExist, got %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package filesystem
+
+import (
+	"syscall"
+)
+
+// networkErrnos are the errors NFS and SMB mounts report when the server is slow, gone or restarted
+var networkErrnos = []syscall.Errno{
+	syscall.ESTALE,
+	syscall.ETIMEDOUT,
+	syscall.EHOSTDOWN,
+	syscall.EHOSTUNREACH,
+	syscall.ENETDOWN,
+	syscall.ENETUNREACH,
+	syscall.ENETRESET,
+	syscall.ECONNABORTED,
+	syscall.ECONNRESET,
+	syscall.ENOTCONN,
+}
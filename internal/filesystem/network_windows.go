@@ -0,0 +1,20 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"syscall"
+)
+
+// networkErrnos are the errors Windows reports when an SMB share is slow, gone or restarted
+var networkErrnos = []syscall.Errno{
+	53,   // ERROR_BAD_NETPATH
+	59,   // ERROR_UNEXP_NET_ERR
+	64,   // ERROR_NETNAME_DELETED
+	67,   // ERROR_BAD_NET_NAME
+	121,  // ERROR_SEM_TIMEOUT
+	1222, // ERROR_NO_NETWORK
+	1231, // ERROR_NETWORK_UNREACHABLE
+	1232, // ERROR_HOST_UNREACHABLE
+	2250, // ERROR_NOT_CONNECTED
+}
@@ -0,0 +1,54 @@
+// Package filesystem provides FileSystem implementations for the walker and processor.
+// This package offers the real operating system backend and an in-memory backend for tests.
+package filesystem
+
+import (
+	"io/fs"
+	"os"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// OSFileSystem implements the FileSystem interface using the os package
+// This struct is the default backend used by the CLI; on Windows, the os package adds the \\?\ prefix to long paths
+type OSFileSystem struct{}
+
+// NewOSFileSystem creates a FileSystem backed by the real operating system
+func NewOSFileSystem() interfaces.FileSystem {
+	return OSFileSystem{}
+}
+
+// Stat returns information about a path, following symbolic links
+func (OSFileSystem) Stat(path string) (fs.FileInfo, error) {
+	return os.Stat(path)
+}
+
+// Lstat returns information about a path without following symbolic links
+func (OSFileSystem) Lstat(path string) (fs.FileInfo, error) {
+	return os.Lstat(path)
+}
+
+// ReadDir returns the entries of a directory sorted by name
+func (OSFileSystem) ReadDir(path string) ([]fs.DirEntry, error) {
+	return os.ReadDir(path)
+}
+
+// ReadFile returns the content of a file
+func (OSFileSystem) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+// Rename moves oldPath to newPath
+func (OSFileSystem) Rename(oldPath, newPath string) error {
+	return os.Rename(oldPath, newPath)
+}
+
+// Remove deletes a file or an empty directory
+func (OSFileSystem) Remove(path string) error {
+	return os.Remove(path)
+}
+
+// SameFile reports whether two FileInfos describe the same file
+func (OSFileSystem) SameFile(a, b fs.FileInfo) bool {
+	return os.SameFile(a, b)
+}
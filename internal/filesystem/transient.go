@@ -0,0 +1,31 @@
+package filesystem
+
+import (
+	"errors"
+	"slices"
+	"syscall"
+)
+
+// IsTransientError reports whether err may go away if the operation is repeated later
+// Besides network errors this covers files that are briefly in use, e.g. by a virus scanner or an SMB client,
+// and timeouts reported through a Timeout method, like net.Error and os.ErrDeadlineExceeded
+func IsTransientError(err error) bool {
+	if IsNetworkError(err) {
+		return true
+	}
+
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return true
+	}
+
+	var errno syscall.Errno
+	return errors.As(err, &errno) && slices.Contains(busyErrnos, errno)
+}
+
+// IsLockedError reports whether err means another process holds the file or directory open
+// Such errors outlast a few quick retries, but usually go away once the application is closed
+func IsLockedError(err error) bool {
+	var errno syscall.Errno
+	return errors.As(err, &errno) && slices.Contains(lockedErrnos, errno)
+}
@@ -0,0 +1,221 @@
+// Package htmlreport writes a standalone HTML report of a run for review by people who don't use the command line.
+// This implementation follows the Decorator pattern by wrapping an existing ProgressReporter.
+package htmlreport
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/paths"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+)
+
+//go:embed report.html
+var reportTemplateSource string
+
+// reportTemplate renders the report; it embeds its styles and the table sorting script, so the file needs nothing else
+var reportTemplate = template.Must(template.New("report").Parse(reportTemplateSource))
+
+// Collision resolutions shown in the report
+const (
+	CollisionNone   = ""       // The sanitized name was free
+	CollisionSuffix = "suffix" // The sanitized name was taken, so a suffix was appended
+	CollisionMerged = "merged" // The folder was merged into an existing folder with the sanitized name
+)
+
+// Entry is a single rename as shown in the report
+type Entry struct {
+	OldPath   string   // Path before the rename
+	OldName   string   // Name before the rename
+	NewName   string   // Name after the rename, including any collision suffix
+	Rules     []string // Identifiers of the rules that changed the name
+	Collision string   // How a collision with an existing name was resolved (CollisionNone if there was none)
+	Sanitized string   // Name the rules produced, before the collision was resolved
+}
+
+// Failure is a folder that could not be processed
+type Failure struct {
+	Path  string
+	Error string
+}
+
+// Rule is a rule that fired during the run with its explanation and how many names it changed
+type Rule struct {
+	ID          string
+	Description string
+	Count       int
+}
+
+// Header describes the run the report belongs to
+type Header struct {
+	RunID         string // Identifier of the run
+	Root          string // Root path of the run
+	DryRun        bool   // Whether the renames were only planned
+	RelativePaths bool   // Whether paths are shown relative to Root
+}
+
+// Recorder implements ProgressReporter, FolderReporter, RenameReporter, FailureReporter, WarningReporter and ScanReporter
+// This struct collects renames, failures, warnings and the summary while forwarding every event to the wrapped reporter
+type Recorder struct {
+	next     interfaces.ProgressReporter
+	entries  []Entry
+	failures []Failure
+	warnings []string
+	summary  *interfaces.ProcessingSummary
+	now      func() time.Time
+}
+
+// NewRecorder creates a Recorder that wraps the provided reporter
+func NewRecorder(next interfaces.ProgressReporter) *Recorder {
+	return &Recorder{
+		next: next,
+		now:  time.Now,
+	}
+}
+
+// ReportProgress forwards progress updates to the wrapped reporter
+func (r *Recorder) ReportProgress(current, total int, message string) {
+	r.next.ReportProgress(current, total, message)
+}
+
+// ReportError forwards errors to the wrapped reporter
+// Failed folders are recorded through ReportFailure, so the report isn't cluttered with the abort messages
+func (r *Recorder) ReportError(err error) {
+	r.next.ReportError(err)
+}
+
+// ReportWarning records a warning and forwards it when the wrapped reporter supports warnings
+func (r *Recorder) ReportWarning(warning error) {
+	r.warnings = append(r.warnings, warning.Error())
+	if warningReporter, ok := r.next.(interfaces.WarningReporter); ok {
+		warningReporter.ReportWarning(warning)
+	}
+}
+
+// ReportScan forwards the discovery progress when the wrapped reporter shows it
+func (r *Recorder) ReportScan(scanned int, path string) {
+	if scanReporter, ok := r.next.(interfaces.ScanReporter); ok {
+		scanReporter.ReportScan(scanned, path)
+	}
+}
+
+// ReportFolder forwards the current folder when the wrapped reporter supports it
+func (r *Recorder) ReportFolder(current, total int, folder interfaces.FolderInfo) {
+	if folderReporter, ok := r.next.(interfaces.FolderReporter); ok {
+		folderReporter.ReportFolder(current, total, folder)
+	}
+}
+
+// ReportRename records a rename and forwards it when the wrapped reporter supports renames
+func (r *Recorder) ReportRename(result interfaces.RenameResult) {
+	entry := Entry{
+		OldPath:   result.OldPath,
+		OldName:   filepath.Base(result.OldPath),
+		NewName:   filepath.Base(result.NewPath),
+		Rules:     result.Rules,
+		Sanitized: result.SanitizedName,
+	}
+	switch {
+	case result.Merged:
+		entry.Collision = CollisionMerged
+	case result.SanitizedName != "" && entry.NewName != result.SanitizedName:
+		entry.Collision = CollisionSuffix
+	}
+	r.entries = append(r.entries, entry)
+
+	if renameReporter, ok := r.next.(interfaces.RenameReporter); ok {
+		renameReporter.ReportRename(result)
+	}
+}
+
+// ReportFailure records a failed folder and forwards it when the wrapped reporter collects failures too
+func (r *Recorder) ReportFailure(folder interfaces.FolderInfo, err error) {
+	r.failures = append(r.failures, Failure{Path: folder.Path, Error: err.Error()})
+
+	if failureReporter, ok := r.next.(interfaces.FailureReporter); ok {
+		failureReporter.ReportFailure(folder, err)
+	}
+}
+
+// ReportComplete records the summary and forwards it to the wrapped reporter
+func (r *Recorder) ReportComplete(summary interfaces.ProcessingSummary) {
+	r.summary = &summary
+	r.next.ReportComplete(summary)
+}
+
+// Entries returns the renames recorded so far
+func (r *Recorder) Entries() []Entry {
+	return r.entries
+}
+
+// Save writes the report to path as a single HTML file
+// The report is written even if the run failed, so a partial run can be reviewed too
+func (r *Recorder) Save(path string, header Header) error {
+	// Failed folders arrive with their full path; renames are already shown the way the run displays paths
+	failures := make([]Failure, len(r.failures))
+	copy(failures, r.failures)
+	if header.RelativePaths {
+		for i := range failures {
+			failures[i].Path = paths.Relative(header.Root, failures[i].Path)
+		}
+	}
+
+	collisions := 0
+	for _, entry := range r.entries {
+		if entry.Collision != CollisionNone {
+			collisions++
+		}
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write report to %s: %w", path, err)
+	}
+	err = reportTemplate.Execute(file, map[string]any{
+		"Header":     header,
+		"Generated":  r.now().UTC(),
+		"Summary":    r.summary,
+		"Entries":    r.entries,
+		"Collisions": collisions,
+		"Rules":      r.rules(),
+		"Failures":   failures,
+		"Warnings":   r.warnings,
+	})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write report to %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// rules returns every rule that changed a name with its explanation, the most frequent first
+func (r *Recorder) rules() []Rule {
+	counts := make(map[string]int)
+	for _, entry := range r.entries {
+		for _, rule := range entry.Rules {
+			counts[rule]++
+		}
+	}
+
+	rules := make([]Rule, 0, len(counts))
+	for id, count := range counts {
+		rules = append(rules, Rule{ID: id, Description: sanitizer.RuleDescription(id), Count: count})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Count != rules[j].Count {
+			return rules[i].Count > rules[j].Count
+		}
+		return rules[i].ID < rules[j].ID
+	})
+
+	return rules
+}
@@ -0,0 +1,74 @@
+// Package htmlreport_test provides tests for the htmlreport package.
+// This test suite ensures the report lists renames, rule explanations, collisions and failures.
+package htmlreport_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/htmlreport"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/reporter"
+)
+
+// TestRecorder_Save tests that the saved report contains every rename, its rules, collisions, failures and the summary
+func TestRecorder_Save(t *testing.T) {
+	recorder := htmlreport.NewRecorder(reporter.NewNopReporter())
+	recorder.ReportRename(interfaces.RenameResult{
+		OldPath:       "/data/a<b>",
+		NewPath:       "/data/a_b_",
+		Rules:         []string{"invalid-characters"},
+		SanitizedName: "a_b_",
+	})
+	recorder.ReportRename(interfaces.RenameResult{
+		OldPath:       "/data/c:d",
+		NewPath:       "/data/c_d_1",
+		Rules:         []string{"invalid-characters"},
+		SanitizedName: "c_d",
+	})
+	recorder.ReportFailure(interfaces.FolderInfo{Path: "/data/locked", Name: "locked"}, errors.New("permission denied"))
+	recorder.ReportComplete(interfaces.ProcessingSummary{TotalFolders: 5, ElapsedTime: "1s"})
+
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := recorder.Save(path, htmlreport.Header{RunID: "run-1", Root: "/data", DryRun: true, RelativePaths: true}); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	report := string(data)
+
+	for _, want := range []string{
+		"Planned renames",
+		"run-1",
+		"a&lt;b&gt;", // Names are escaped, never interpreted as markup
+		"c_d_1",
+		"c_d already exists",
+		"invalid characters",
+		"<dt>Name collisions resolved</dt><dd>1</dd>",
+		"<td class=\"name\">locked</td>", // Failed paths are shown relative to the root like the renames
+		"permission denied",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected report to contain %q", want)
+		}
+	}
+	if strings.Contains(report, "a<b>") {
+		t.Error("Expected names to be HTML-escaped")
+	}
+}
+
+// TestRecorder_Merged tests that merges are reported as collisions without a suffix
+func TestRecorder_Merged(t *testing.T) {
+	recorder := htmlreport.NewRecorder(reporter.NewNopReporter())
+	recorder.ReportRename(interfaces.RenameResult{OldPath: "/data/Photos ", NewPath: "/data/Photos", Merged: true, SanitizedName: "Photos"})
+
+	entries := recorder.Entries()
+	if len(entries) != 1 || entries[0].Collision != htmlreport.CollisionMerged {
+		t.Errorf("Expected a merged collision, got %+v", entries)
+	}
+}
@@ -2,6 +2,20 @@
 // This follows the Interface Segregation Principle by defining focused, specific interfaces.
 package interfaces
 
+import (
+	"errors"
+	"io/fs"
+	"time"
+)
+
+// ErrFileSystemUnavailable is wrapped by FileSystem implementations when the backend itself is gone
+// (e.g. a network share disconnected), so callers can stop instead of failing every remaining folder
+var ErrFileSystemUnavailable = errors.New("file system unavailable")
+
+// ErrHalted is returned by FolderProcessor implementations that refuse every further rename of a run
+// (e.g. a rename quota waiting for approval); the folder counts as not processed and the run stops
+var ErrHalted = errors.New("renaming halted")
+
 // FolderSanitizer defines the contract for sanitizing folder names
 // This interface follows the Single Responsibility Principle - it only handles name sanitization
 type FolderSanitizer interface {
@@ -9,6 +23,59 @@ type FolderSanitizer interface {
 	SanitizeName(name string) string
 }
 
+// NameExplainer is an optional extension of FolderSanitizer for sanitizers that can
+// report which rules changed a name (used for explanations and reports)
+type NameExplainer interface {
+	// ExplainName returns the sanitized name and the identifiers of the rules that fired
+	ExplainName(name string) (string, []string)
+}
+
+// FolderExplainer is an optional extension of FolderSanitizer for sanitizers whose output
+// depends on where a folder lives (e.g. replacement templates that reference the parent)
+type FolderExplainer interface {
+	// ExplainFolder returns the sanitized name of the folder and the identifiers of the rules that fired
+	ExplainFolder(folder FolderInfo) (string, []string)
+}
+
+// RuleStep records a rule that changed a name together with the name before and after it
+type RuleStep struct {
+	Rule   string // Rule identifier as reported by NameExplainer
+	Before string
+	After  string
+}
+
+// NameTracer is an optional extension of FolderSanitizer for sanitizers that can
+// report what each rule changed (used by the explain subcommand)
+type NameTracer interface {
+	// TraceName returns the sanitized name and the rules that fired, in the order they were applied
+	TraceName(name string) (string, []RuleStep)
+}
+
+// Explain sanitizes a folder with the richest interface the sanitizer supports
+// FolderExplainer gets the whole folder, NameExplainer the name; other sanitizers report no rules
+func Explain(sanitizer FolderSanitizer, folder FolderInfo) (string, []string) {
+	if explainer, ok := sanitizer.(FolderExplainer); ok {
+		return explainer.ExplainFolder(folder)
+	}
+	if explainer, ok := sanitizer.(NameExplainer); ok {
+		return explainer.ExplainName(folder.Name)
+	}
+	return sanitizer.SanitizeName(folder.Name), nil
+}
+
+// Trace reports what each rule of the sanitizer changed in name; without NameTracer only the rules are known
+func Trace(sanitizer FolderSanitizer, name string) (string, []RuleStep) {
+	if tracer, ok := sanitizer.(NameTracer); ok {
+		return tracer.TraceName(name)
+	}
+	sanitized, rules := Explain(sanitizer, FolderInfo{Name: name})
+	steps := make([]RuleStep, 0, len(rules))
+	for _, rule := range rules {
+		steps = append(steps, RuleStep{Rule: rule})
+	}
+	return sanitized, steps
+}
+
 // DirectoryWalker defines the contract for walking directory trees
 // This interface abstracts the directory traversal logic
 type DirectoryWalker interface {
@@ -16,6 +83,20 @@ type DirectoryWalker interface {
 	Walk(rootPath string) ([]FolderInfo, error)
 }
 
+// WarningWalker is an optional extension of DirectoryWalker for walkers that skip over
+// non-fatal problems (e.g. unreadable directories) and hand them over for reporting
+type WarningWalker interface {
+	// Warnings returns the problems encountered during the most recent Walk
+	Warnings() []error
+}
+
+// ScanningWalker is an optional extension of DirectoryWalker for walkers that report their progress
+// while the tree is discovered, so the walk of a huge tree doesn't run silently
+type ScanningWalker interface {
+	// ObserveScan makes later walks call observe with the number of directories read so far and the latest one
+	ObserveScan(observe func(scanned int, path string))
+}
+
 // FolderProcessor defines the contract for processing folder renames
 // This interface handles the actual renaming operations
 type FolderProcessor interface {
@@ -34,13 +115,103 @@ type ProgressReporter interface {
 	ReportComplete(summary ProcessingSummary)
 }
 
+// RenameReporter is an optional extension of ProgressReporter for reporters that
+// want to announce each individual rename (e.g. accessible, screen-reader friendly output)
+type RenameReporter interface {
+	// ReportRename sends the outcome of a single rename operation
+	ReportRename(result RenameResult)
+}
+
+// FolderReporter is an optional extension of ProgressReporter for reporters that
+// need structured information about the folder currently being processed (e.g. its path)
+type FolderReporter interface {
+	// ReportFolder is sent right before ReportProgress for the same folder
+	ReportFolder(current, total int, folder FolderInfo)
+}
+
+// FailureReporter is an optional extension of ProgressReporter for reporters that
+// want to collect the folders that failed to process (e.g. for a targeted re-run)
+type FailureReporter interface {
+	// ReportFailure sends a folder that could not be processed together with its error
+	ReportFailure(folder FolderInfo, err error)
+}
+
+// WarningReporter is an optional extension of ProgressReporter for reporters that
+// show non-fatal problems, such as directories the walk had to skip
+type WarningReporter interface {
+	// ReportWarning sends a problem that did not stop the run
+	ReportWarning(warning error)
+}
+
+// ScanReporter is an optional extension of ProgressReporter for reporters that
+// show the discovery phase, which precedes the first ReportProgress call
+type ScanReporter interface {
+	// ReportScan sends the number of directories the walk read so far and the latest one
+	ReportScan(scanned int, path string)
+}
+
+// Pacer decides when a run pauses between folders, e.g. during business hours or under heavy load
+// This interface keeps scheduling policy out of the service, which only waits as told
+type Pacer interface {
+	// Pause returns how long to wait before the next folder and why (0 = continue now)
+	Pause() (time.Duration, string)
+	// Observe records how long the last rename took
+	Observe(latency time.Duration)
+}
+
+// Hold lets the user pause a run between folders and resume it later, e.g. from the TUI
+// This interface keeps the user interface out of the service, which only waits while the run is held
+type Hold interface {
+	// Released returns nil while the run may continue, or a channel that is closed once the user resumes it
+	Released() <-chan struct{}
+}
+
+// RenameVerifier re-checks the file system after a run, e.g. to catch other processes renaming the same folders
+// This interface lets the service confirm its renames without knowing how the tree is read
+type RenameVerifier interface {
+	// VerifyRenames returns one error per rename whose new path is missing or whose old path exists again
+	VerifyRenames(renames []RenameResult) []error
+}
+
+// FileSystem defines the contract for the file system the walker and processor operate on
+// This interface allows in-memory tests and alternative backends without changing the pipeline
+type FileSystem interface {
+	// Stat returns information about a path, following symbolic links
+	Stat(path string) (fs.FileInfo, error)
+	// Lstat returns information about a path without following symbolic links
+	Lstat(path string) (fs.FileInfo, error)
+	// ReadDir returns the entries of a directory sorted by name
+	ReadDir(path string) ([]fs.DirEntry, error)
+	// ReadFile returns the content of a file
+	ReadFile(path string) ([]byte, error)
+	// Rename moves oldPath to newPath
+	Rename(oldPath, newPath string) error
+	// Remove deletes a file or an empty directory
+	Remove(path string) error
+	// SameFile reports whether two FileInfos obtained from this file system describe the same file
+	SameFile(a, b fs.FileInfo) bool
+}
+
 // FolderInfo represents information about a folder to be processed
 // This struct encapsulates all necessary folder metadata
 type FolderInfo struct {
-	Path   string // Full path to the folder
-	Name   string // Current folder name
-	Depth  int    // Depth level from root (for ordering)
-	Parent string // Parent directory path
+	Path     string          // Full path to the folder
+	Name     string          // Current folder name
+	Depth    int             // Depth level from root (for ordering)
+	Parent   string          // Parent directory path
+	Contents *ContentSummary // Direct contents, when the walker classifies folders (nil otherwise)
+	Owner    string          // Owner of the folder, when the walker attributes owners (empty otherwise)
+
+	// Contested is set when the run is planned if another folder in the same directory is renamed to the same name
+	Contested bool
+}
+
+// ContentSummary describes the direct contents of a folder for classification rules
+// Names and extensions are lower-cased so rules match case-insensitively
+type ContentSummary struct {
+	Files      int             // Number of regular files directly inside the folder
+	Extensions map[string]int  // File count per extension, including the dot (e.g. ".jpg")
+	Names      map[string]bool // Names of all direct entries, files and folders alike
 }
 
 // RenameResult contains the outcome of a rename operation
@@ -50,16 +221,109 @@ type RenameResult struct {
 	OldPath    string // Original path
 	NewPath    string // New path after rename
 	WasRenamed bool   // Whether the folder actually needed renaming
+	Merged     bool   // Whether the folder was merged into an existing folder instead of renamed
 	Error      error  // Any error that occurred
+	Transient  bool   // Whether Error is transient (file in use, network hiccup), so a later retry may succeed
+	Attempts   int    // Number of rename attempts, including retries of transient errors
+	Vanished   bool   // Whether Error means the folder was deleted or moved after the scan, so there was nothing left to rename
+	Locked     bool   // Whether Error means another application holds the folder or a file in it open, so it can be renamed once that is closed
+
+	// Rules lists the identifiers of the rules that changed the name, when the sanitizer explains its changes
+	Rules []string
+	// SanitizedName is the name the rules produced; it differs from the new name when a collision was resolved with a suffix
+	SanitizedName string
 }
 
 // ProcessingSummary contains statistics about the entire processing operation
 // This struct provides a complete overview of what was accomplished
 type ProcessingSummary struct {
-	TotalFolders   int    // Total number of folders found
-	ProcessedCount int    // Number of folders processed
-	RenamedCount   int    // Number of folders actually renamed
-	ErrorCount     int    // Number of errors encountered
-	SkippedCount   int    // Number of folders skipped
-	ElapsedTime    string // Time taken for the operation
+	RunID          string `json:"run_id,omitempty"`       // Identifier of the run, shared with its journal, logs and other artifacts
+	TotalFolders   int    `json:"total_folders"`          // Total number of folders found
+	ProcessedCount int    `json:"processed_count"`        // Number of folders processed
+	RenamedCount   int    `json:"renamed_count"`          // Number of folders renamed, or that would be renamed in a dry run
+	ErrorCount     int    `json:"error_count"`            // Number of errors encountered
+	WarningCount   int    `json:"warning_count"`          // Number of non-fatal problems, e.g. directories the walk skipped
+	SkippedCount   int    `json:"skipped_count"`          // Number of folders skipped
+	RemainingCount int    `json:"remaining_count"`        // Number of folders a stopped run didn't reach
+	ElapsedTime    string `json:"elapsed_time"`           // Time taken for the operation
+	Aborted        bool   `json:"aborted,omitempty"`      // Whether the run stopped early (e.g. error budget exceeded)
+	AbortReason    string `json:"abort_reason,omitempty"` // Why the run stopped early
+
+	// DryRun and Phases separate what the run planned from what it applied; both modes fill them the same way
+	DryRun bool        `json:"dry_run"`
+	Phases PhaseCounts `json:"phases"`
+
+	// Owners breaks renames and errors down by folder owner, when the walker attributes owners
+	Owners map[string]GroupStats `json:"owners,omitempty"`
+
+	// Rules breaks renames and errors down by the rule that changed the name; a name changed by several rules counts for each
+	Rules map[string]GroupStats `json:"rules,omitempty"`
+
+	// Directories breaks renames and errors down by the top-level directory below the root they are in ("." = the root itself)
+	Directories map[string]GroupStats `json:"directories,omitempty"`
+
+	// Verification is the outcome of re-checking the applied renames, when the run was verified
+	Verification *Verification `json:"verification,omitempty"`
+
+	// Locked lists the folders still held open by other applications after their retry at the end of the run
+	Locked []string `json:"locked,omitempty"`
+}
+
+// Verification reports whether the renames of a run are still in place after it finished
+// Discrepancies usually mean another process renamed or recreated folders concurrently
+type Verification struct {
+	Checked       int      `json:"checked"`                 // Applied renames that were re-checked
+	Discrepancies []string `json:"discrepancies,omitempty"` // One message per rename that is not in place
+}
+
+// PhaseCounts splits the folders of a run by outcome so planned renames are never mistaken for applied ones
+// Planned always equals Applied + WouldRename + Deferred + Failed + Vanished, in dry runs and real runs alike
+type PhaseCounts struct {
+	Planned     int `json:"planned"`      // Folders whose name needs to change
+	Applied     int `json:"applied"`      // Planned renames that were performed; always 0 in a dry run
+	WouldRename int `json:"would_rename"` // Planned renames a dry run checked and a real run would perform; always 0 in a real run
+	Deferred    int `json:"deferred"`     // Planned renames left for a later run because an aborted or interrupted run didn't reach them
+	Failed      int `json:"failed"`       // Planned renames that failed
+	Vanished    int `json:"vanished"`     // Planned renames whose folder was deleted or moved after the scan; not errors
+	Skipped     int `json:"skipped"`      // Folders whose name already complies
+}
+
+// GroupStats counts the outcomes of one group of folders in a summary breakdown: an owner, a rule or a top-level directory
+// Shared storage reports use them to see who keeps creating incompatible names, why, and which parts of the tree are worst
+type GroupStats struct {
+	RenamedCount int `json:"renamed_count"` // Folders of the group that were (or would be) renamed
+	ErrorCount   int `json:"error_count"`   // Folders of the group that failed to process
+}
+
+// Violation describes a folder name that does not comply with the sanitization rules
+// This struct is produced by check mode, which never changes the file system
+type Violation struct {
+	Path          string   // Full path to the folder
+	Name          string   // Current folder name
+	SanitizedName string   // Name the folder would be renamed to
+	Rules         []string // Identifiers of the rules the name violates
+	Owner         string   // Owner of the folder, when the walker attributes owners
+}
+
+// RiskAssessor flags names that pass every rule but are still likely to cause trouble elsewhere
+// (e.g. in spreadsheets, or when files are added below a path close to its limit)
+type RiskAssessor interface {
+	// AssessName returns the identifiers of the risks of name, the name the folder has after sanitization
+	AssessName(folder FolderInfo, name string) []string
+}
+
+// Risk is a folder whose name complies, or will comply once renamed, but remains risky
+type Risk struct {
+	Path  string   // Full path to the folder
+	Name  string   // Name that was assessed: the current name, or the suggested name of a violation
+	Risks []string // Identifiers of the risks
+	Owner string   // Owner of the folder, when the walker attributes owners
+}
+
+// CheckReport contains the outcome of a check (lint) run
+type CheckReport struct {
+	TotalFolders int         // Total number of folders checked
+	Violations   []Violation // Non-compliant folders in processing order
+	Risks        []Risk      // Folders with risky names in processing order, kept apart from the violations
+	Warnings     []error     // Non-fatal problems, e.g. directories the walk skipped
 }
@@ -0,0 +1,50 @@
+package journal
+
+// Change is a folder that both plans rename, but to different targets
+type Change struct {
+	OldPath string // Path of the folder before either plan
+	Before  Entry  // Rename in the earlier plan
+	After   Entry  // Rename in the later plan
+}
+
+// Comparison lists how a later plan differs from an earlier one
+// Renames both plans perform identically are left out, so a reviewer only re-reads the delta
+type Comparison struct {
+	Added   []Entry  // Renames only the later plan performs, in its processing order
+	Removed []Entry  // Renames only the earlier plan performs, in its processing order
+	Changed []Change // Folders renamed to a different target, in the processing order of the later plan
+}
+
+// Empty reports whether both plans perform exactly the same renames
+func (c Comparison) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Changed) == 0
+}
+
+// Compare matches the renames of two plans by the path of the folder they rename
+// Both plans should have been loaded against the same root, so relative and absolute plans compare alike
+func Compare(earlier, later []Entry) Comparison {
+	before := make(map[string]Entry, len(earlier))
+	for _, entry := range earlier {
+		before[entry.OldPath] = entry
+	}
+
+	var comparison Comparison
+	seen := make(map[string]bool, len(later))
+	for _, entry := range later {
+		seen[entry.OldPath] = true
+		previous, planned := before[entry.OldPath]
+		switch {
+		case !planned:
+			comparison.Added = append(comparison.Added, entry)
+		case previous.NewPath != entry.NewPath || previous.Merged != entry.Merged:
+			comparison.Changed = append(comparison.Changed, Change{OldPath: entry.OldPath, Before: previous, After: entry})
+		}
+	}
+	for _, entry := range earlier {
+		if !seen[entry.OldPath] {
+			comparison.Removed = append(comparison.Removed, entry)
+		}
+	}
+
+	return comparison
+}
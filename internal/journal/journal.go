@@ -0,0 +1,254 @@
+// Package journal records the renames of a run and reloads them for applying a plan or undoing a run.
+// This implementation follows the Decorator pattern by wrapping an existing ProgressReporter.
+package journal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/paths"
+)
+
+// fileVersion identifies the layout of plan and journal files
+const fileVersion = 1
+
+// Kinds of rename records
+const (
+	KindPlan    = "plan"    // Renames a dry run would perform, for review and a later apply
+	KindJournal = "journal" // Renames an apply run performed, for undo
+)
+
+// Entry is a single rename in processing order
+type Entry struct {
+	OldPath    string `json:"old_path"`               // Path before the rename
+	OldPathRaw []byte `json:"old_path_raw,omitempty"` // Bytes of OldPath when it isn't valid UTF-8, which JSON strings can't hold
+	NewPath    string `json:"new_path"`               // Path after the rename
+	Depth      int    `json:"depth"`                  // Depth level from the original root
+	Merged     bool   `json:"merged,omitempty"`       // Whether the folder was merged into an existing folder
+}
+
+// Header describes the run that produced a plan or journal
+type Header struct {
+	Kind          string `json:"kind"`                     // KindPlan or KindJournal
+	RunID         string `json:"run_id,omitempty"`         // Identifier of the run that produced the renames
+	Root          string `json:"root"`                     // Root path of the run
+	RelativePaths bool   `json:"relative_paths,omitempty"` // Whether entry paths are stored relative to Root
+	RulesVersion  int    `json:"rules_version,omitempty"`  // Rules version the new names were produced with (0 = not recorded)
+	Order         string `json:"order,omitempty"`          // Order the folders were renamed in (empty = bottom-up)
+}
+
+// File is the machine-readable plan or journal document
+type File struct {
+	Version int `json:"version"` // Layout version of the document
+	Header
+	Entries []Entry `json:"entries"` // Renames in processing order (deepest first unless Order says otherwise)
+}
+
+// Recorder implements ProgressReporter, FolderReporter, RenameReporter, FailureReporter, WarningReporter and ScanReporter
+// This struct collects renames while forwarding every event to the wrapped reporter
+type Recorder struct {
+	next    interfaces.ProgressReporter
+	depth   int
+	entries []Entry
+}
+
+// NewRecorder creates a Recorder that wraps the provided reporter
+func NewRecorder(next interfaces.ProgressReporter) *Recorder {
+	return &Recorder{
+		next:    next,
+		entries: make([]Entry, 0),
+	}
+}
+
+// ReportProgress forwards progress updates to the wrapped reporter
+func (r *Recorder) ReportProgress(current, total int, message string) {
+	r.next.ReportProgress(current, total, message)
+}
+
+// ReportError forwards errors to the wrapped reporter
+func (r *Recorder) ReportError(err error) {
+	r.next.ReportError(err)
+}
+
+// ReportWarning forwards warnings when the wrapped reporter supports them
+func (r *Recorder) ReportWarning(warning error) {
+	if warningReporter, ok := r.next.(interfaces.WarningReporter); ok {
+		warningReporter.ReportWarning(warning)
+	}
+}
+
+// ReportScan forwards the discovery progress when the wrapped reporter shows it
+func (r *Recorder) ReportScan(scanned int, path string) {
+	if scanReporter, ok := r.next.(interfaces.ScanReporter); ok {
+		scanReporter.ReportScan(scanned, path)
+	}
+}
+
+// ReportComplete forwards the summary to the wrapped reporter
+func (r *Recorder) ReportComplete(summary interfaces.ProcessingSummary) {
+	r.next.ReportComplete(summary)
+}
+
+// ReportFolder remembers the depth of the current folder and forwards it when the wrapped reporter supports it
+func (r *Recorder) ReportFolder(current, total int, folder interfaces.FolderInfo) {
+	r.depth = folder.Depth
+
+	if folderReporter, ok := r.next.(interfaces.FolderReporter); ok {
+		folderReporter.ReportFolder(current, total, folder)
+	}
+}
+
+// ReportRename records a rename and forwards it when the wrapped reporter supports renames
+func (r *Recorder) ReportRename(result interfaces.RenameResult) {
+	r.entries = append(r.entries, Entry{
+		OldPath: result.OldPath,
+		NewPath: result.NewPath,
+		Depth:   r.depth,
+		Merged:  result.Merged,
+	})
+
+	if renameReporter, ok := r.next.(interfaces.RenameReporter); ok {
+		renameReporter.ReportRename(result)
+	}
+}
+
+// ReportFailure forwards failed folders when the wrapped reporter collects failures
+func (r *Recorder) ReportFailure(folder interfaces.FolderInfo, err error) {
+	if failureReporter, ok := r.next.(interfaces.FailureReporter); ok {
+		failureReporter.ReportFailure(folder, err)
+	}
+}
+
+// Entries returns the renames recorded so far
+func (r *Recorder) Entries() []Entry {
+	return r.entries
+}
+
+// Save writes the recorded renames to path as JSON
+// The file is always written so that an empty list signals that nothing needed renaming
+func (r *Recorder) Save(path string, header Header) error {
+	entries := make([]Entry, len(r.entries))
+	copy(entries, r.entries)
+
+	// With relative paths the root is only recorded once, in the header
+	if header.RelativePaths {
+		for i := range entries {
+			entries[i].OldPath = paths.Relative(header.Root, entries[i].OldPath)
+			entries[i].NewPath = paths.Relative(header.Root, entries[i].NewPath)
+		}
+	}
+
+	// Names that aren't valid UTF-8 (see --source-encoding) are kept byte for byte so undo restores them exactly
+	for i := range entries {
+		if !utf8.ValidString(entries[i].OldPath) {
+			entries[i].OldPathRaw = []byte(entries[i].OldPath)
+		}
+	}
+
+	// Plans are reviewed by people, so characters such as < and > stay readable
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(File{
+		Version: fileVersion,
+		Header:  header,
+		Entries: entries,
+	}); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", header.Kind, err)
+	}
+
+	if err := os.WriteFile(path, data.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s to %s: %w", header.Kind, path, err)
+	}
+
+	return nil
+}
+
+// Load reads a plan or journal of the expected kind
+// Relative entry paths are resolved against root, which may differ from the root of the original run;
+// an empty root resolves them against the root recorded in the file
+func Load(path, kind, root string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s from %s: %w", kind, path, err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode %s from %s: %w", kind, path, err)
+	}
+
+	if file.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported %s version %d in %s", kind, file.Version, path)
+	}
+	if file.Kind != kind {
+		return nil, fmt.Errorf("%s is a %s, not a %s", path, file.Kind, kind)
+	}
+
+	if root == "" {
+		root = file.Root
+	}
+	for i := range file.Entries {
+		if len(file.Entries[i].OldPathRaw) > 0 {
+			file.Entries[i].OldPath = string(file.Entries[i].OldPathRaw)
+		}
+		file.Entries[i].OldPath = resolve(file.RelativePaths, root, file.Entries[i].OldPath)
+		file.Entries[i].NewPath = resolve(file.RelativePaths, root, file.Entries[i].NewPath)
+	}
+
+	return &file, nil
+}
+
+// resolve returns an entry path as a native path, below root for relative files
+func resolve(relative bool, root, path string) string {
+	if relative {
+		return paths.Resolve(root, path)
+	}
+	return filepath.FromSlash(path)
+}
+
+// Folders returns the folders a plan renames, ready for a ListWalker
+func (f *File) Folders() []interfaces.FolderInfo {
+	folders := make([]interfaces.FolderInfo, 0, len(f.Entries))
+	for _, entry := range f.Entries {
+		folders = append(folders, interfaces.FolderInfo{
+			Path:   entry.OldPath,
+			Name:   filepath.Base(entry.OldPath),
+			Depth:  entry.Depth,
+			Parent: filepath.Dir(entry.OldPath),
+		})
+	}
+	return folders
+}
+
+// Sanitizer returns a sanitizer that renames each planned folder to its planned name
+// Applying a plan therefore performs exactly the reviewed renames, even if the rules changed since
+func (f *File) Sanitizer() interfaces.FolderSanitizer {
+	names := make(plannedNames, len(f.Entries))
+	for _, entry := range f.Entries {
+		names[entry.OldPath] = filepath.Base(entry.NewPath)
+	}
+	return names
+}
+
+// plannedNames implements FolderSanitizer and FolderExplainer over the names of a plan, keyed by path
+type plannedNames map[string]string
+
+// SanitizeName returns the name unchanged; without a path no planned name can be found
+func (p plannedNames) SanitizeName(name string) string {
+	return name
+}
+
+// ExplainFolder returns the planned name of the folder, or its current name if it isn't part of the plan
+func (p plannedNames) ExplainFolder(folder interfaces.FolderInfo) (string, []string) {
+	if name, ok := p[folder.Path]; ok {
+		return name, nil
+	}
+	return folder.Name, nil
+}
@@ -0,0 +1,274 @@
+// Package journal_test provides tests for the journal package.
+// This test suite ensures plans and journals survive a save/load round trip and can be undone.
+package journal_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/journal"
+	"github.com/punkscience/sanitize/internal/reporter"
+)
+
+// record feeds a rename of the folder at path to the recorder, like the service does
+func record(recorder *journal.Recorder, oldPath, newPath string, depth int) {
+	recorder.ReportFolder(1, 1, interfaces.FolderInfo{Path: oldPath, Name: filepath.Base(oldPath), Depth: depth})
+	recorder.ReportRename(interfaces.RenameResult{Success: true, OldPath: oldPath, NewPath: newPath, WasRenamed: true})
+}
+
+// TestRecorder_SaveAndLoad tests that recorded renames are reloaded with their depth
+func TestRecorder_SaveAndLoad(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "data")
+	recorder := journal.NewRecorder(reporter.NewNopReporter())
+	record(recorder, filepath.Join(root, "a<b>"), filepath.Join(root, "a_b_"), 1)
+
+	path := filepath.Join(t.TempDir(), "journal.json")
+	if err := recorder.Save(path, journal.Header{Kind: journal.KindJournal, RunID: "run-1", Root: root}); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	file, err := journal.Load(path, journal.KindJournal, "")
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	if file.RunID != "run-1" || file.Root != root {
+		t.Errorf("Unexpected header after round trip: %+v", file.Header)
+	}
+	if len(file.Entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(file.Entries))
+	}
+	entry := file.Entries[0]
+	if entry.OldPath != filepath.Join(root, "a<b>") || entry.NewPath != filepath.Join(root, "a_b_") || entry.Depth != 1 {
+		t.Errorf("Unexpected entry after round trip: %+v", entry)
+	}
+}
+
+// TestRecorder_RelativePaths tests that relative entries are resolved against a different root on load
+func TestRecorder_RelativePaths(t *testing.T) {
+	oldRoot := filepath.Join(t.TempDir(), "old")
+	newRoot := filepath.Join(t.TempDir(), "new")
+
+	recorder := journal.NewRecorder(reporter.NewNopReporter())
+	record(recorder, filepath.Join(oldRoot, "x", "bad:name"), filepath.Join(oldRoot, "x", "bad_name"), 2)
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	header := journal.Header{Kind: journal.KindPlan, Root: oldRoot, RelativePaths: true}
+	if err := recorder.Save(path, header); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	file, err := journal.Load(path, journal.KindPlan, newRoot)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+
+	entry := file.Entries[0]
+	if entry.OldPath != filepath.Join(newRoot, "x", "bad:name") || entry.NewPath != filepath.Join(newRoot, "x", "bad_name") {
+		t.Errorf("Expected entry below %s, got %+v", newRoot, entry)
+	}
+}
+
+// TestRecorder_InvalidUTF8 tests that old paths which aren't valid UTF-8 survive a round trip byte for byte
+func TestRecorder_InvalidUTF8(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "data")
+	recorder := journal.NewRecorder(reporter.NewNopReporter())
+	record(recorder, filepath.Join(root, "caf\xe9"), filepath.Join(root, "cafe"), 1)
+
+	path := filepath.Join(t.TempDir(), "journal.json")
+	header := journal.Header{Kind: journal.KindJournal, Root: root, RelativePaths: true}
+	if err := recorder.Save(path, header); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	file, err := journal.Load(path, journal.KindJournal, "")
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if entry := file.Entries[0]; entry.OldPath != filepath.Join(root, "caf\xe9") {
+		t.Errorf("Expected the original bytes after round trip, got %q", entry.OldPath)
+	}
+}
+
+// TestLoad_WrongKind tests that a plan cannot be loaded as a journal
+func TestLoad_WrongKind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := journal.NewRecorder(reporter.NewNopReporter()).Save(path, journal.Header{Kind: journal.KindPlan, Root: "/data"}); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	if _, err := journal.Load(path, journal.KindJournal, ""); err == nil {
+		t.Error("Expected error when loading a plan as a journal, but got none")
+	}
+}
+
+// TestFile_Sanitizer tests that a plan renames exactly its planned folders
+func TestFile_Sanitizer(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "data")
+	file := &journal.File{Entries: []journal.Entry{
+		{OldPath: filepath.Join(root, "a b"), NewPath: filepath.Join(root, "a_b_2"), Depth: 1},
+	}}
+
+	folders := file.Folders()
+	if len(folders) != 1 || folders[0].Name != "a b" || folders[0].Parent != root {
+		t.Fatalf("Unexpected folders: %+v", folders)
+	}
+
+	explainer, ok := file.Sanitizer().(interfaces.FolderExplainer)
+	if !ok {
+		t.Fatal("Expected plan sanitizer to implement FolderExplainer")
+	}
+	if name, _ := explainer.ExplainFolder(folders[0]); name != "a_b_2" {
+		t.Errorf("Expected planned name a_b_2, got %q", name)
+	}
+
+	other := interfaces.FolderInfo{Path: filepath.Join(root, "c d"), Name: "c d"}
+	if name, _ := explainer.ExplainFolder(other); name != "c d" {
+		t.Errorf("Expected unplanned folder to keep its name, got %q", name)
+	}
+}
+
+// TestCompare tests that only renames added, removed or retargeted since the earlier plan are listed
+func TestCompare(t *testing.T) {
+	earlier := []journal.Entry{
+		{OldPath: "/data/a:b", NewPath: "/data/a_b"},
+		{OldPath: "/data/c:d", NewPath: "/data/c_d"},
+		{OldPath: "/data/e:f", NewPath: "/data/e_f"},
+		{OldPath: "/data/g:h", NewPath: "/data/g_h"},
+	}
+	later := []journal.Entry{
+		{OldPath: "/data/x:y", NewPath: "/data/x_y"},
+		{OldPath: "/data/c:d", NewPath: "/data/c_d"},
+		{OldPath: "/data/e:f", NewPath: "/data/e_f_1"},
+		{OldPath: "/data/g:h", NewPath: "/data/g_h", Merged: true},
+	}
+
+	comparison := journal.Compare(earlier, later)
+	if len(comparison.Added) != 1 || comparison.Added[0].OldPath != "/data/x:y" {
+		t.Errorf("Expected x:y to be added, got %+v", comparison.Added)
+	}
+	if len(comparison.Removed) != 1 || comparison.Removed[0].OldPath != "/data/a:b" {
+		t.Errorf("Expected a:b to be removed, got %+v", comparison.Removed)
+	}
+	if len(comparison.Changed) != 2 || comparison.Changed[0].Before.NewPath != "/data/e_f" || comparison.Changed[0].After.NewPath != "/data/e_f_1" ||
+		comparison.Changed[1].OldPath != "/data/g:h" {
+		t.Errorf("Expected e:f to be retargeted and g:h to be merged, got %+v", comparison.Changed)
+	}
+
+	if !journal.Compare(later, later).Empty() {
+		t.Error("Expected identical plans to compare empty")
+	}
+}
+
+// nestedJournal returns a memory file system after renaming a parent and its child, and the matching journal
+func nestedJournal(t *testing.T) (*filesystem.MemoryFileSystem, *journal.File) {
+	t.Helper()
+
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/data/new parent/new child")
+
+	// Children are renamed before their parents, so the child entry is recorded under the old parent
+	return memory, &journal.File{Entries: []journal.Entry{
+		{OldPath: "/data/old:parent/old:child", NewPath: "/data/old:parent/new child", Depth: 2},
+		{OldPath: "/data/old:parent", NewPath: "/data/new parent", Depth: 1},
+	}}
+}
+
+// TestUndo_RestoresNestedRenames tests that undo restores parents before their children
+func TestUndo_RestoresNestedRenames(t *testing.T) {
+	memory, file := nestedJournal(t)
+
+	summary := journal.Undo(memory, file, false, reporter.NewNopReporter())
+
+	if summary.ErrorCount != 0 || summary.RenamedCount != 2 {
+		t.Errorf("Expected 2 restores without errors, got %+v", summary)
+	}
+	if want := (interfaces.PhaseCounts{Planned: 2, Applied: 2}); summary.Phases != want {
+		t.Errorf("Expected phases %+v, got %+v", want, summary.Phases)
+	}
+	if !memory.Exists("/data/old:parent/old:child") {
+		t.Error("Expected the original tree to be restored")
+	}
+}
+
+// TestUndo_DryRun tests that a dry run checks nested entries without renaming anything
+func TestUndo_DryRun(t *testing.T) {
+	memory, file := nestedJournal(t)
+
+	summary := journal.Undo(memory, file, true, reporter.NewNopReporter())
+
+	if summary.ErrorCount != 0 || summary.RenamedCount != 2 {
+		t.Errorf("Expected 2 simulated restores without errors, got %+v", summary)
+	}
+	if want := (interfaces.PhaseCounts{Planned: 2, WouldRename: 2}); summary.Phases != want {
+		t.Errorf("Expected phases %+v, got %+v", want, summary.Phases)
+	}
+	if !memory.Exists("/data/new parent/new child") {
+		t.Error("Expected the tree to be unchanged after a dry run")
+	}
+}
+
+// errorRecorder remembers the reported errors
+type errorRecorder struct {
+	interfaces.ProgressReporter
+	errs []error
+}
+
+func (e *errorRecorder) ReportError(err error) { e.errs = append(e.errs, err) }
+
+// TestUndo_MergedEntry tests that merged folders are reported instead of restored
+func TestUndo_MergedEntry(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/data/target")
+	file := &journal.File{Entries: []journal.Entry{
+		{OldPath: "/data/tar:get", NewPath: "/data/target", Depth: 1, Merged: true},
+	}}
+	recorder := &errorRecorder{ProgressReporter: reporter.NewNopReporter()}
+
+	summary := journal.Undo(memory, file, false, recorder)
+
+	if summary.ErrorCount != 1 || len(recorder.errs) != 1 || !errors.Is(recorder.errs[0], journal.ErrMergeNotReversible) {
+		t.Errorf("Expected ErrMergeNotReversible, got %v (summary %+v)", recorder.errs, summary)
+	}
+	if !memory.Exists("/data/target") {
+		t.Error("Expected the merge target to be left alone")
+	}
+}
+
+// TestVerifier tests that nested renames are checked where they ended up and that changes made afterwards are flagged
+func TestVerifier(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/data/new parent/new child")
+	memory.MkdirAll("/data/new other")
+	renames := []interfaces.RenameResult{
+		{OldPath: "/data/old:parent/old:child", NewPath: "/data/old:parent/new child"},
+		{OldPath: "/data/old:parent", NewPath: "/data/new parent"},
+		{OldPath: "/data/old:other", NewPath: "/data/new other"},
+	}
+	verifier := journal.NewVerifier(memory)
+
+	if discrepancies := verifier.VerifyRenames(renames); len(discrepancies) != 0 {
+		t.Fatalf("Expected the renames to be in place, got %v", discrepancies)
+	}
+
+	// Another process renames the child away and recreates the old name of the other folder
+	if err := memory.Rename("/data/new parent/new child", "/data/new parent/moved"); err != nil {
+		t.Fatal(err)
+	}
+	memory.MkdirAll("/data/old:other")
+
+	discrepancies := verifier.VerifyRenames(renames)
+	if len(discrepancies) != 2 {
+		t.Fatalf("Expected 2 discrepancies, got %v", discrepancies)
+	}
+	if want := "/data/new parent/new child, which no longer exists"; !strings.Contains(discrepancies[0].Error(), want) {
+		t.Errorf("Expected the missing child to be flagged, got %v", discrepancies[0])
+	}
+	if want := "but /data/old:other exists again"; !strings.Contains(discrepancies[1].Error(), want) {
+		t.Errorf("Expected the recreated folder to be flagged, got %v", discrepancies[1])
+	}
+}
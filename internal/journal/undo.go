@@ -0,0 +1,98 @@
+package journal
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// ErrMergeNotReversible is reported for journal entries whose folder was merged into another folder
+var ErrMergeNotReversible = errors.New("merged folders cannot be separated again")
+
+// Undo renames every journal entry back to its old path, most recent rename first
+// Reversing the processing order restores parents before their children, so every recorded
+// path is valid again by the time it is renamed. Progress and the summary go to the reporter.
+func Undo(fileSystem interfaces.FileSystem, file *File, dryRun bool, reporter interfaces.ProgressReporter) interfaces.ProcessingSummary {
+	startTime := time.Now()
+	summary := interfaces.ProcessingSummary{TotalFolders: len(file.Entries), DryRun: dryRun}
+	var simulated []Entry // Entries restored so far in a dry run
+
+	for i := len(file.Entries) - 1; i >= 0; i-- {
+		entry := file.Entries[i]
+		summary.ProcessedCount++
+		summary.Phases.Planned++
+		reporter.ReportProgress(summary.ProcessedCount, summary.TotalFolders, fmt.Sprintf("Restoring: %s", entry.OldPath))
+
+		if err := undoEntry(fileSystem, entry, dryRun, simulated); err != nil {
+			reporter.ReportError(fmt.Errorf("cannot restore %s: %w", entry.OldPath, err))
+			summary.ErrorCount++
+			summary.Phases.Failed++
+			continue
+		}
+
+		summary.RenamedCount++
+		if dryRun {
+			summary.Phases.WouldRename++
+			simulated = append(simulated, entry)
+		} else {
+			summary.Phases.Applied++
+		}
+		if renameReporter, ok := reporter.(interfaces.RenameReporter); ok {
+			renameReporter.ReportRename(interfaces.RenameResult{
+				Success:    true,
+				OldPath:    entry.NewPath,
+				NewPath:    entry.OldPath,
+				WasRenamed: true,
+			})
+		}
+	}
+
+	summary.ElapsedTime = time.Since(startTime).String()
+	reporter.ReportComplete(summary)
+
+	return summary
+}
+
+// undoEntry renames a single entry back after checking that nothing else took its place
+// In a dry run nothing is renamed, so paths are checked where the simulated restores left them
+func undoEntry(fileSystem interfaces.FileSystem, entry Entry, dryRun bool, simulated []Entry) error {
+	if entry.Merged {
+		return ErrMergeNotReversible
+	}
+
+	newInfo, err := fileSystem.Lstat(currentPath(entry.NewPath, simulated))
+	if err != nil {
+		return fmt.Errorf("renamed folder is gone: %w", err)
+	}
+	// A case-only rename on a case-insensitive file system finds the folder itself under its old path
+	if oldInfo, err := fileSystem.Lstat(currentPath(entry.OldPath, simulated)); err == nil && !fileSystem.SameFile(newInfo, oldInfo) {
+		return fmt.Errorf("old path is in use again")
+	}
+
+	if dryRun {
+		return nil
+	}
+	if filesystem.IsCaseOnlyRename(fileSystem, entry.NewPath, entry.OldPath) {
+		return filesystem.RenameCaseOnly(fileSystem, fileSystem.Rename, entry.NewPath, entry.OldPath)
+	}
+	return fileSystem.Rename(entry.NewPath, entry.OldPath)
+}
+
+// currentPath maps a recorded path to where it is before the simulated restores took place
+// The most recent restore is mapped first, so nested restores resolve one level at a time
+func currentPath(path string, simulated []Entry) string {
+	for i := len(simulated) - 1; i >= 0; i-- {
+		restored := simulated[i]
+		if path == restored.OldPath {
+			path = restored.NewPath
+		} else if rest, ok := strings.CutPrefix(path, restored.OldPath+string(filepath.Separator)); ok {
+			path = filepath.Join(restored.NewPath, rest)
+		}
+	}
+	return path
+}
@@ -0,0 +1,75 @@
+package journal
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Verifier implements RenameVerifier by listing the parents of renamed folders once more
+// Each affected parent is read a single time, however many of its children were renamed
+type Verifier struct {
+	fileSystem interfaces.FileSystem
+}
+
+// NewVerifier creates a Verifier that reads the tree through fileSystem
+func NewVerifier(fileSystem interfaces.FileSystem) *Verifier {
+	return &Verifier{fileSystem: fileSystem}
+}
+
+// VerifyRenames confirms that every new name exists and every old name is gone, in processing order
+// Renames of deeper folders are followed through the later renames of their parents, so every entry
+// is checked where it ended up. Names are compared exactly, which also covers case-only renames.
+func (v *Verifier) VerifyRenames(renames []interfaces.RenameResult) []error {
+	listings := make(map[string]map[string]bool)
+	listErrors := make(map[string]error)
+	var discrepancies []error
+
+	for i, rename := range renames {
+		oldPath := finalPath(rename.OldPath, renames[i+1:])
+		newPath := finalPath(rename.NewPath, renames[i+1:])
+
+		parent := filepath.Dir(newPath)
+		names, listed := listings[parent]
+		if err, failed := listErrors[parent]; failed {
+			discrepancies = append(discrepancies, fmt.Errorf("cannot verify the rename of %s to %s: %w", oldPath, newPath, err))
+			continue
+		}
+		if !listed {
+			entries, err := v.fileSystem.ReadDir(parent)
+			if err != nil {
+				listErrors[parent] = err
+				discrepancies = append(discrepancies, fmt.Errorf("cannot verify the rename of %s to %s: %w", oldPath, newPath, err))
+				continue
+			}
+			names = make(map[string]bool, len(entries))
+			for _, entry := range entries {
+				names[entry.Name()] = true
+			}
+			listings[parent] = names
+		}
+
+		// A merged folder is gone by design and its target existed before, so only the target is checked
+		switch {
+		case !names[filepath.Base(newPath)]:
+			discrepancies = append(discrepancies, fmt.Errorf("%s was renamed to %s, which no longer exists", oldPath, newPath))
+		case !rename.Merged && names[filepath.Base(oldPath)]:
+			discrepancies = append(discrepancies, fmt.Errorf("%s was renamed to %s, but %s exists again", oldPath, newPath, oldPath))
+		}
+	}
+
+	return discrepancies
+}
+
+// finalPath maps a path recorded during the run to where it is after the later renames
+// Later renames can only move a path by renaming one of its ancestors
+func finalPath(path string, later []interfaces.RenameResult) string {
+	for _, rename := range later {
+		if rest, ok := strings.CutPrefix(path, rename.OldPath+string(filepath.Separator)); ok {
+			path = filepath.Join(rename.NewPath, rest)
+		}
+	}
+	return path
+}
@@ -0,0 +1,100 @@
+// Package keepalive keeps the session of a network mount open while a plan awaits approval.
+// This implementation stats the root at a fixed interval and notices when the session was lost in between.
+package keepalive
+
+import (
+	"fmt"
+	"io/fs"
+	"sync"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Keeper stats a path at a fixed interval, so SMB and NFS mounts don't drop an idle session
+// A failed stat, or a root that comes back as a different directory (the share was remounted), means
+// the session was lost; once the root is reachable again, onRestored is called to re-validate the plan.
+type Keeper struct {
+	fileSystem interfaces.FileSystem
+	path       string
+	interval   time.Duration
+	onRestored func()
+
+	mu   sync.Mutex
+	root fs.FileInfo // Root as of the last successful check (nil before the first one)
+	lost error       // Why the last check failed; nil while the session is alive
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New creates a Keeper for path; onRestored may be nil
+func New(fileSystem interfaces.FileSystem, path string, interval time.Duration, onRestored func()) *Keeper {
+	return &Keeper{
+		fileSystem: fileSystem,
+		path:       path,
+		interval:   interval,
+		onRestored: onRestored,
+	}
+}
+
+// Start checks the path once and then every interval until Stop is called
+func (k *Keeper) Start() {
+	k.stop = make(chan struct{})
+	k.done = make(chan struct{})
+	k.Check()
+
+	go func() {
+		defer close(k.done)
+		ticker := time.NewTicker(k.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-k.stop:
+				return
+			case <-ticker.C:
+				k.Check()
+			}
+		}
+	}()
+}
+
+// Stop ends the periodic checks and waits for a running check to finish
+func (k *Keeper) Stop() {
+	close(k.stop)
+	<-k.done
+}
+
+// Check stats the path once and reports whether the session is alive
+// The first successful check after a lost session calls onRestored.
+func (k *Keeper) Check() error {
+	info, err := k.fileSystem.Stat(k.path)
+
+	k.mu.Lock()
+	restored := false
+	switch {
+	case err != nil:
+		k.lost = fmt.Errorf("session to %s lost: %w", k.path, err)
+	case k.root != nil && !k.fileSystem.SameFile(k.root, info):
+		restored = true // Remounted while no check was looking
+	default:
+		restored = k.lost != nil
+	}
+	if err == nil {
+		k.root = info
+		k.lost = nil
+	}
+	lost := k.lost
+	k.mu.Unlock()
+
+	if restored && k.onRestored != nil {
+		k.onRestored()
+	}
+	return lost
+}
+
+// Lost returns why the session is currently lost, or nil while it is alive
+func (k *Keeper) Lost() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.lost
+}
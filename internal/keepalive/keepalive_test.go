@@ -0,0 +1,74 @@
+package keepalive_test
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/keepalive"
+)
+
+// droppingFileSystem fails every stat while the session is down
+type droppingFileSystem struct {
+	*filesystem.MemoryFileSystem
+	down bool
+}
+
+// Stat fails with a network error while the session is down
+func (d *droppingFileSystem) Stat(path string) (fs.FileInfo, error) {
+	if d.down {
+		return nil, &fs.PathError{Op: "stat", Path: path, Err: errors.New("host is down")}
+	}
+	return d.MemoryFileSystem.Stat(path)
+}
+
+// TestKeeper_Check tests that lost sessions and remounts are noticed and restoring them calls back once
+func TestKeeper_Check(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/share/music")
+	dropping := &droppingFileSystem{MemoryFileSystem: memory}
+
+	restored := 0
+	keeper := keepalive.New(dropping, "/share", time.Hour, func() { restored++ })
+
+	if err := keeper.Check(); err != nil || restored != 0 {
+		t.Fatalf("Expected the first check to succeed without a callback, got %v, %d", err, restored)
+	}
+
+	dropping.down = true
+	if err := keeper.Check(); err == nil || keeper.Lost() == nil {
+		t.Fatalf("Expected a failed stat to lose the session, got %v", err)
+	}
+	dropping.down = false
+	if err := keeper.Check(); err != nil || keeper.Lost() != nil || restored != 1 {
+		t.Fatalf("Expected the session to be restored once, got %v, %d callbacks", err, restored)
+	}
+	keeper.Check()
+	if restored != 1 {
+		t.Errorf("Expected no callback while the session stays alive, got %d", restored)
+	}
+
+	// A remount between two checks shows up as a different root directory
+	memory.Rename("/share", "/old")
+	memory.MkdirAll("/share")
+	if err := keeper.Check(); err != nil || restored != 2 {
+		t.Errorf("Expected a remounted root to call back, got %v, %d callbacks", err, restored)
+	}
+}
+
+// TestKeeper_Start tests that the keeper checks periodically until it is stopped
+func TestKeeper_Start(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/share")
+	dropping := &droppingFileSystem{MemoryFileSystem: memory, down: true}
+
+	keeper := keepalive.New(dropping, "/share", time.Millisecond, nil)
+	keeper.Start()
+	defer keeper.Stop()
+
+	if keeper.Lost() == nil {
+		t.Error("Expected Start to check the root right away")
+	}
+}
@@ -0,0 +1,63 @@
+// Package logging opens the audit log that records warnings, skipped directories and renames.
+// This implementation builds on log/slog, so every entry has a level, a timestamp and structured attributes.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Formats lists the supported log formats
+var Formats = []string{"text", "json"}
+
+// Discard returns a logger that drops every entry, used when no log file is configured
+func Discard() *slog.Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+// ParseLevel converts a level name (debug, info, warn or error) to a slog level
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q (want debug, info, warn or error)", name)
+	}
+	return level, nil
+}
+
+// New returns a logger writing entries of at least the given level to out as text or JSON lines
+func New(out io.Writer, level, format string) (*slog.Logger, error) {
+	minLevel, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &slog.HandlerOptions{Level: minLevel}
+	switch strings.ToLower(format) {
+	case "text":
+		return slog.New(slog.NewTextHandler(out, options)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(out, options)), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q (want %s)", format, strings.Join(Formats, " or "))
+	}
+}
+
+// Open appends log entries to the file at path, creating it if needed
+// Entries are written unbuffered, so the log is complete even if the process is killed
+func Open(path, level, format string) (*slog.Logger, io.Closer, error) {
+	// Validate the settings before touching the file system
+	if _, err := New(io.Discard, level, format); err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	logger, _ := New(file, level, format)
+	return logger, file, nil
+}
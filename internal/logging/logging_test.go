@@ -0,0 +1,58 @@
+package logging_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/logging"
+)
+
+// TestOpen tests that entries below the level are dropped and the file is appended to
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sanitize.log")
+
+	for run := 0; run < 2; run++ {
+		logger, closer, err := logging.Open(path, "warn", "json")
+		if err != nil {
+			t.Fatalf("Open() returned error: %v", err)
+		}
+		logger.Info("renamed folder", "old_path", "/data/a:b")
+		logger.Warn("skipped directory", "path", "/data/locked")
+		closer.Close()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one warning per run, got %d lines:\n%s", len(lines), data)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Expected JSON lines, got %q: %v", lines[0], err)
+	}
+	if entry["level"] != "WARN" || entry["path"] != "/data/locked" {
+		t.Errorf("Expected the warning with its attributes, got %v", entry)
+	}
+}
+
+// TestOpen_Invalid tests that invalid settings are rejected without creating the file
+func TestOpen_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sanitize.log")
+
+	if _, _, err := logging.Open(path, "loud", "text"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+	if _, _, err := logging.Open(path, "info", "xml"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no log file to be created, got %v", err)
+	}
+}
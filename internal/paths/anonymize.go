@@ -0,0 +1,77 @@
+package paths
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+)
+
+// pseudonymLength is the number of hex characters used for each pseudonym
+const pseudonymLength = 10
+
+// Anonymizer replaces path components with stable pseudonyms derived from a keyed hash
+// Characters selected by keep are preserved so shared reports still show what was wrong
+type Anonymizer struct {
+	key  []byte
+	keep func(rune) bool
+}
+
+// NewAnonymizer creates an Anonymizer using the given key
+// An empty key generates a random one, so pseudonyms are only stable within a single run
+func NewAnonymizer(key string, keep func(rune) bool) *Anonymizer {
+	keyBytes := []byte(key)
+	if len(keyBytes) == 0 {
+		keyBytes = make([]byte, 32)
+		_, _ = rand.Read(keyBytes)
+	}
+
+	return &Anonymizer{
+		key:  keyBytes,
+		keep: keep,
+	}
+}
+
+// Component returns the pseudonym for a single name, followed by any preserved characters
+// Trailing spaces and periods are preserved too, because they are violations on their own
+func (a *Anonymizer) Component(name string) string {
+	if name == "" || name == "." || name == ".." {
+		return name
+	}
+
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(name))
+	pseudonym := hex.EncodeToString(mac.Sum(nil))[:pseudonymLength]
+
+	var kept strings.Builder
+	if a.keep != nil {
+		for _, r := range name {
+			if a.keep(r) {
+				kept.WriteRune(r)
+			}
+		}
+	}
+
+	trimmed := strings.TrimRight(name, ". ")
+	trailing := name[len(trimmed):]
+
+	if kept.Len() == 0 && trailing == "" {
+		return pseudonym
+	}
+	return pseudonym + "~" + kept.String() + trailing
+}
+
+// Path anonymizes every component of a path while keeping its separators and volume
+func (a *Anonymizer) Path(path string) string {
+	volume := filepath.VolumeName(path)
+	rest := filepath.ToSlash(path[len(volume):])
+
+	components := strings.Split(rest, "/")
+	for i, component := range components {
+		components[i] = a.Component(component)
+	}
+
+	return volume + strings.Join(components, "/")
+}
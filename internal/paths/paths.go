@@ -0,0 +1,59 @@
+// Package paths provides root-relative path conversion for reports and artifacts.
+// This keeps server-local mount details out of shared outputs.
+package paths
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Relative returns path relative to root using forward slashes
+// Paths outside root (or that cannot be made relative) are returned unchanged
+func Relative(root, path string) string {
+	if !Within(root, path) {
+		return path
+	}
+	rel, _ := filepath.Rel(root, path)
+	return filepath.ToSlash(rel)
+}
+
+// Resolve turns a root-relative path back into a path below root
+// Absolute paths are returned unchanged
+func Resolve(root, path string) string {
+	native := filepath.FromSlash(path)
+	if filepath.IsAbs(native) {
+		return native
+	}
+	return filepath.Join(root, native)
+}
+
+// relativeError hides the root prefix in an error message while preserving the wrapped error
+type relativeError struct {
+	root string
+	err  error
+}
+
+// Error returns the wrapped message with every occurrence of the root prefix removed
+func (re *relativeError) Error() string {
+	prefix := strings.TrimSuffix(re.root, string(filepath.Separator)) + string(filepath.Separator)
+	return strings.ReplaceAll(re.err.Error(), prefix, "")
+}
+
+// Unwrap exposes the original error to errors.Is and errors.As
+func (re *relativeError) Unwrap() error {
+	return re.err
+}
+
+// RelativeError wraps err so that its message shows paths relative to root
+func RelativeError(root string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &relativeError{root: root, err: err}
+}
+
+// Within reports whether path is root itself or lies below it
+func Within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
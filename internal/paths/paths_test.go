@@ -0,0 +1,172 @@
+// Package paths_test provides tests for the paths package.
+// This test suite ensures relative paths round-trip against a different root.
+package paths_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/paths"
+)
+
+// TestRelative tests conversion of paths to root-relative form
+func TestRelative(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "mnt", "share")
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"nested", filepath.Join(root, "a", "b"), "a/b"},
+		{"root itself", root, "."},
+		{"outside root", filepath.Join(string(filepath.Separator), "etc"), filepath.Join(string(filepath.Separator), "etc")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := paths.Relative(root, tc.path); result != tc.expected {
+				t.Errorf("Relative(%q, %q) = %q, expected %q", root, tc.path, result, tc.expected)
+			}
+		})
+	}
+}
+
+// TestWithin tests detection of paths inside a root
+func TestWithin(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "mnt", "sandbox")
+
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{root, true},
+		{filepath.Join(root, "a"), true},
+		{filepath.Join(root, "..", "data"), false},
+		{root + "2", false},
+	}
+
+	for _, tc := range testCases {
+		if result := paths.Within(root, tc.path); result != tc.expected {
+			t.Errorf("Within(%q, %q) = %v, expected %v", root, tc.path, result, tc.expected)
+		}
+	}
+}
+
+// TestRenames tests that paths below renamed folders are followed through every rename
+func TestRenames(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "mnt", "share")
+	renames := make(paths.Renames)
+	renames.Record(filepath.Join(root, "a?"), filepath.Join(root, "a_"))
+	renames.Record(filepath.Join(root, "a_", "b?"), filepath.Join(root, "a_", "b_"))
+	renames.Record(filepath.Join(root, "same"), filepath.Join(root, "same"))
+
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{filepath.Join(root, "a?", "b?", "c"), filepath.Join(root, "a_", "b_", "c")},
+		{filepath.Join(root, "a?", "x"), filepath.Join(root, "a_", "x")},
+		{filepath.Join(root, "a?"), filepath.Join(root, "a?")}, // The renamed folder itself is not below a rename
+		{filepath.Join(root, "a?x", "y"), filepath.Join(root, "a?x", "y")},
+		{filepath.Join(root, "same", "z"), filepath.Join(root, "same", "z")},
+	}
+
+	for _, tc := range testCases {
+		if result := renames.Current(tc.path); result != tc.expected {
+			t.Errorf("Current(%q) = %q, expected %q", tc.path, result, tc.expected)
+		}
+	}
+}
+
+// TestResolve tests resolving relative paths against a different root
+func TestResolve(t *testing.T) {
+	newRoot := filepath.Join(string(filepath.Separator), "data")
+
+	if result := paths.Resolve(newRoot, "a/b"); result != filepath.Join(newRoot, "a", "b") {
+		t.Errorf("Resolve() = %q, expected path below %q", result, newRoot)
+	}
+
+	absolute := filepath.Join(string(filepath.Separator), "mnt", "x")
+	if result := paths.Resolve(newRoot, absolute); result != absolute {
+		t.Errorf("Resolve() = %q, expected absolute path unchanged", result)
+	}
+}
+
+// TestAnonymizer tests that pseudonyms are stable and keep violating characters
+func TestAnonymizer(t *testing.T) {
+	keep := func(r rune) bool { return r == '<' || r == '>' }
+	a := paths.NewAnonymizer("secret", keep)
+
+	first := a.Path("Confidential/Project <X>")
+	second := a.Path("Confidential/Project <X>")
+	if first != second {
+		t.Errorf("Expected stable pseudonyms, got %q and %q", first, second)
+	}
+
+	if strings.Contains(first, "Confidential") || strings.Contains(first, "Project") {
+		t.Errorf("Expected names to be hidden, got %q", first)
+	}
+
+	if !strings.HasSuffix(first, "~<>") {
+		t.Errorf("Expected violating characters to be preserved, got %q", first)
+	}
+
+	if other := paths.NewAnonymizer("other", keep).Path("Confidential"); strings.HasPrefix(first, other) {
+		t.Errorf("Expected different keys to produce different pseudonyms")
+	}
+}
+
+// TestSplitUNC tests splitting network paths into server, share and remainder
+func TestSplitUNC(t *testing.T) {
+	testCases := []struct {
+		path                string
+		server, share, rest string
+		ok                  bool
+	}{
+		{`\\server\share\a\b`, "server", "share", `a\b`, true},
+		{`//server/share`, "server", "share", "", true},
+		{`\\server`, "server", "", "", true},
+		{`\\?\UNC\server\share\a`, "server", "share", "a", true},
+		{`\\?\C:\a`, "", "", "", false},
+		{`C:\a`, "", "", "", false},
+		{"/mnt/share", "", "", "", false},
+	}
+
+	for _, tc := range testCases {
+		server, share, rest, ok := paths.SplitUNC(tc.path)
+		if server != tc.server || share != tc.share || rest != tc.rest || ok != tc.ok {
+			t.Errorf("SplitUNC(%q) = %q, %q, %q, %v, expected %q, %q, %q, %v",
+				tc.path, server, share, rest, ok, tc.server, tc.share, tc.rest, tc.ok)
+		}
+	}
+}
+
+// TestSystemLocation tests that volume roots and listed locations are refused while their subdirectories are not
+func TestSystemLocation(t *testing.T) {
+	root := filepath.VolumeName(t.TempDir()) + string(filepath.Separator)
+	home := filepath.Join(root, "home", "alice")
+	locations := paths.SystemLocations(home)
+
+	tests := []struct {
+		path   string
+		system bool
+	}{
+		{root, true},
+		{home, true},
+		{home + string(filepath.Separator), true},
+		{filepath.Join(home, "Music"), false},
+		{filepath.Join(root, "srv", "share"), false},
+		{`\\nas\share`, false},
+	}
+
+	for _, tt := range tests {
+		if reason := paths.SystemLocation(tt.path, locations); (reason != "") != tt.system {
+			t.Errorf("SystemLocation(%q) = %q, expected a system location: %v", tt.path, reason, tt.system)
+		}
+	}
+	if len(locations) < 2 {
+		t.Errorf("Expected platform locations besides the home directory, got %v", locations)
+	}
+}
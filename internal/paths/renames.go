@@ -0,0 +1,41 @@
+package paths
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Renames records the folders renamed during a run, keyed by their path before the rename
+// Paths collected before the renames, e.g. by a tree walk, can be resolved to where the folders are now.
+type Renames map[string]string
+
+// Record remembers that the folder at oldPath is now at newPath, along with everything below it
+func (r Renames) Record(oldPath, newPath string) {
+	if oldPath != newPath {
+		r[oldPath] = newPath
+	}
+}
+
+// Current returns where path is after the recorded renames of its ancestors
+// A folder renamed after its parent was renamed is followed through both renames.
+func (r Renames) Current(path string) string {
+	for renamed := true; renamed; {
+		renamed = false
+		for ancestor := filepath.Dir(path); ; ancestor = filepath.Dir(ancestor) {
+			if newPath, ok := r[ancestor]; ok {
+				path = Rebase(path, ancestor, newPath)
+				renamed = true
+				break
+			}
+			if filepath.Dir(ancestor) == ancestor {
+				break
+			}
+		}
+	}
+	return path
+}
+
+// Rebase returns path, which must be base or lie below it, moved from base to target
+func Rebase(path, base, target string) string {
+	return target + strings.TrimPrefix(path, base)
+}
@@ -0,0 +1,40 @@
+package paths
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SystemLocation returns a description of why path is a system location that must not be sanitized, or ""
+// Volume roots are always system locations; locations lists the others, as returned by SystemLocations.
+// UNC share roots are the usual target on a NAS and don't count as volume roots.
+func SystemLocation(path string, locations []string) string {
+	path = filepath.Clean(path)
+	if _, _, _, unc := SplitUNC(path); !unc && filepath.Dir(path) == path {
+		return "the root of a volume"
+	}
+	for _, location := range locations {
+		if samePath(path, filepath.Clean(location)) {
+			return "a system location"
+		}
+	}
+	return ""
+}
+
+// SystemLocations returns the operating system and application directories of this platform and home
+// Empty or unset locations are left out.
+func SystemLocations(home string) []string {
+	locations := systemLocations()
+	if home != "" {
+		locations = append(locations, home)
+	}
+	return locations
+}
+
+// samePath compares two clean paths the way the platform's default file systems do
+func samePath(a, b string) bool {
+	if caseInsensitivePaths {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
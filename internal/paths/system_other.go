@@ -0,0 +1,17 @@
+//go:build !windows
+
+package paths
+
+import "runtime"
+
+// caseInsensitivePaths is set where the default file systems ignore case
+var caseInsensitivePaths = runtime.GOOS == "darwin"
+
+// systemLocations returns the directories of the operating system, installed software and user homes
+func systemLocations() []string {
+	return []string{
+		"/bin", "/boot", "/dev", "/etc", "/home", "/lib", "/lib64", "/opt", "/proc", "/root",
+		"/sbin", "/sys", "/usr", "/usr/local", "/var",
+		"/Applications", "/Library", "/System", "/Users", // macOS
+	}
+}
@@ -0,0 +1,23 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// caseInsensitivePaths is set where the default file systems ignore case
+const caseInsensitivePaths = true
+
+// systemLocations returns the Windows and program directories and the parent of the user profiles
+func systemLocations() []string {
+	var locations []string
+	for _, variable := range []string{"SystemRoot", "ProgramFiles", "ProgramFiles(x86)", "ProgramW6432", "ProgramData"} {
+		if value := os.Getenv(variable); value != "" {
+			locations = append(locations, value)
+		}
+	}
+	if drive := os.Getenv("SystemDrive"); drive != "" {
+		locations = append(locations, filepath.Join(drive+`\`, "Users"))
+	}
+	return locations
+}
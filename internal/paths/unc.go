@@ -0,0 +1,33 @@
+package paths
+
+import (
+	"strings"
+)
+
+// SplitUNC splits a UNC path (\\server\share\rest, with either slash) into server, share and remainder
+// Extended-length UNC paths (\\?\UNC\server\share) are recognized too; other device paths are not UNC paths
+func SplitUNC(path string) (server, share, rest string, ok bool) {
+	path = strings.ReplaceAll(path, "/", `\`)
+
+	switch {
+	case len(path) >= 8 && strings.EqualFold(path[:8], `\\?\UNC\`):
+		path = path[8:]
+	case strings.HasPrefix(path, `\\?\`), strings.HasPrefix(path, `\\.\`):
+		return "", "", "", false
+	case strings.HasPrefix(path, `\\`):
+		path = path[2:]
+	default:
+		return "", "", "", false
+	}
+
+	parts := strings.SplitN(path, `\`, 3)
+	server = parts[0]
+	if len(parts) > 1 {
+		share = parts[1]
+	}
+	if len(parts) > 2 {
+		rest = parts[2]
+	}
+
+	return server, share, rest, true
+}
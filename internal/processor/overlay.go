@@ -0,0 +1,81 @@
+// Package processor provides a virtual file system overlay for dry runs.
+// This implementation lets dry runs see the effect of earlier simulated renames.
+package processor
+
+import (
+	"path/filepath"
+
+	"github.com/punkscience/sanitize/internal/paths"
+)
+
+// virtualOverlay records simulated renames on top of the real file system
+// This struct makes dry-run collision detection match what a real run would produce
+type virtualOverlay struct {
+	// created contains paths that exist only because of simulated renames
+	created map[string]bool
+	// removed contains paths (and implicitly their descendants) moved away by simulated renames
+	removed map[string]bool
+	// origins maps the new path of a simulated rename to the path the folder really has
+	origins map[string]string
+}
+
+// newVirtualOverlay creates an empty overlay
+func newVirtualOverlay() *virtualOverlay {
+	return &virtualOverlay{
+		created: make(map[string]bool),
+		removed: make(map[string]bool),
+		origins: make(map[string]string),
+	}
+}
+
+// recordRename simulates moving oldPath to newPath
+func (vo *virtualOverlay) recordRename(oldPath, newPath string) {
+	vo.removed[oldPath] = true
+	delete(vo.created, oldPath)
+	vo.created[newPath] = true
+	delete(vo.removed, newPath)
+	vo.origins[newPath] = vo.realPath(oldPath)
+}
+
+// recordRemove simulates removing a path (e.g. a source emptied by a merge)
+func (vo *virtualOverlay) recordRemove(path string) {
+	vo.removed[path] = true
+	delete(vo.created, path)
+}
+
+// lookup reports whether the overlay knows the state of a path
+// The second return value is false when the real file system should be consulted
+func (vo *virtualOverlay) lookup(path string) (exists bool, known bool) {
+	if vo.created[path] {
+		return true, true
+	}
+
+	// A path is gone if it or any of its ancestors was moved away
+	for current := path; ; {
+		if vo.removed[current] {
+			return false, true
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+
+	return false, false
+}
+
+// realPath returns where a path below a simulated rename really is, so its contents can be looked up
+// Paths outside simulated renames are returned unchanged
+func (vo *virtualOverlay) realPath(path string) string {
+	for current := path; ; {
+		if origin, ok := vo.origins[current]; ok {
+			return paths.Rebase(path, current, origin)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return path
+		}
+		current = parent
+	}
+}
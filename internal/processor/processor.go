@@ -3,11 +3,16 @@
 package processor
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
 	"path/filepath"
+	"time"
 
-	"sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
 )
 
 // FileSystemProcessor implements the FolderProcessor interface for file system operations
@@ -15,18 +20,87 @@ import (
 type FileSystemProcessor struct {
 	// maxCollisionRetries limits how many collision resolution attempts to make
 	maxCollisionRetries int
+	// mergeOnCollision moves the children of the source into an existing target directory
+	mergeOnCollision bool
+	// overlay records dry-run renames so later collision checks see the simulated state
+	overlay *virtualOverlay
+	// fileSystem is the backend renames are performed on
+	fileSystem interfaces.FileSystem
+	// renameRetries is how often a rename that failed with a transient error is repeated
+	renameRetries int
+	// retryDelay is the wait before the first retry; it doubles for every further retry
+	retryDelay time.Duration
+	// sleep waits between retries
+	sleep func(time.Duration)
+	// confirmVanished lists the parent before a missing source counts as vanished rather than failed
+	confirmVanished bool
+	// hashSuffixes derives collision suffixes from the original name instead of numbering them
+	hashSuffixes bool
+}
+
+// Option configures optional FileSystemProcessor behavior
+type Option func(*FileSystemProcessor)
+
+// WithMergeOnCollision merges the source into an existing target directory instead of appending a numbered suffix
+// Conflicting children are merged recursively; conflicting files get a numbered suffix
+func WithMergeOnCollision(merge bool) Option {
+	return func(fsp *FileSystemProcessor) {
+		fsp.mergeOnCollision = merge
+	}
+}
+
+// WithFileSystem makes the processor operate on the given backend instead of the real file system
+func WithFileSystem(fileSystem interfaces.FileSystem) Option {
+	return func(fsp *FileSystemProcessor) {
+		fsp.fileSystem = fileSystem
+	}
+}
+
+// WithRenameRetries repeats renames that fail with a transient error up to retries times
+// The first retry waits delay; every further retry waits twice as long as the one before
+func WithRenameRetries(retries int, delay time.Duration) Option {
+	return func(fsp *FileSystemProcessor) {
+		fsp.renameRetries = retries
+		fsp.retryDelay = delay
+	}
+}
+
+// WithConfirmVanished lists the parent of a folder that is gone at rename time before it counts as vanished
+// A folder still listed there, or a parent that can't be listed, fails the rename as before; this guards
+// against stale lookups on network file systems.
+func WithConfirmVanished(confirm bool) Option {
+	return func(fsp *FileSystemProcessor) {
+		fsp.confirmVanished = confirm
+	}
+}
+
+// WithHashSuffixes resolves collisions with a suffix derived from a hash of the folder's original name
+// Unlike numbered suffixes it doesn't depend on processing order, so every run maps a name to the same result
+func WithHashSuffixes(hashSuffixes bool) Option {
+	return func(fsp *FileSystemProcessor) {
+		fsp.hashSuffixes = hashSuffixes
+	}
 }
 
 // NewFileSystemProcessor creates a new instance of FileSystemProcessor with default settings
 // This constructor allows for configuration of processing behavior
-func NewFileSystemProcessor(maxCollisionRetries int) interfaces.FolderProcessor {
+func NewFileSystemProcessor(maxCollisionRetries int, options ...Option) interfaces.FolderProcessor {
 	if maxCollisionRetries <= 0 {
 		maxCollisionRetries = 1000 // Default safety limit
 	}
 
-	return &FileSystemProcessor{
+	fsp := &FileSystemProcessor{
 		maxCollisionRetries: maxCollisionRetries,
+		overlay:             newVirtualOverlay(),
+		fileSystem:          filesystem.NewOSFileSystem(),
+		sleep:               time.Sleep,
 	}
+
+	for _, option := range options {
+		option(fsp)
+	}
+
+	return fsp
 }
 
 // ProcessRename handles renaming a single folder with collision detection and error recovery
@@ -51,26 +125,63 @@ func (fsp *FileSystemProcessor) ProcessRename(folder interfaces.FolderInfo, newN
 	// Construct the target path
 	newPath := filepath.Join(folder.Parent, newName)
 
+	// Merge into an existing directory instead of creating a numbered sibling
+	if fsp.mergeOnCollision && fsp.isMergeTarget(folder.Path, newPath) {
+		result.NewPath = newPath
+		result.WasRenamed = true
+		result.Merged = true
+
+		if dryRun {
+			fsp.overlay.recordRemove(folder.Path)
+		} else if err := fsp.mergeDirectories(folder.Path, newPath, result); err != nil {
+			result.Error = fmt.Errorf("merge operation failed: %w", err)
+			result.Transient = filesystem.IsTransientError(err)
+			result.Locked = filesystem.IsLockedError(err)
+			result.Vanished = fsp.vanished(folder.Path, err)
+			return result, nil // Return result with error, don't fail the operation
+		}
+
+		result.Success = true
+		return result, nil
+	}
+
+	// On a case-insensitive file system a case-only rename finds the folder itself under the new name, which is no collision
+	caseOnly := fsp.isCaseOnlyRename(folder.Path, newPath)
+
 	// Handle potential name collisions
-	finalPath, err := fsp.resolveNameCollision(newPath, newName)
-	if err != nil {
-		result.Error = fmt.Errorf("failed to resolve name collision: %w", err)
-		return result, nil // Return result with error, don't fail the operation
+	finalPath := newPath
+	if !caseOnly {
+		var err error
+		if finalPath, err = fsp.resolveNameCollision(newPath, newName, folder.Name, folder.Contested); err != nil {
+			result.Error = fmt.Errorf("failed to resolve name collision: %w", err)
+			return result, nil // Return result with error, don't fail the operation
+		}
 	}
 
 	result.NewPath = finalPath
 	result.WasRenamed = true
 
-	// If dry run mode, simulate the operation
+	// If dry run mode, simulate the operation so later collisions see it
 	if dryRun {
+		fsp.overlay.recordRename(folder.Path, finalPath)
 		result.Success = true
 		return result, nil
 	}
 
-	// Perform the actual rename operation
-	err = fsp.performRename(folder.Path, finalPath)
+	// Perform the actual rename operation; a case-only rename passes through a temporary name so the new case sticks
+	var err error
+	if caseOnly {
+		err = filesystem.RenameCaseOnly(fsp.fileSystem, func(oldPath, newPath string) error {
+			return fsp.performRename(oldPath, newPath, result)
+		}, folder.Path, finalPath)
+	} else {
+		err = fsp.performRename(folder.Path, finalPath, result)
+	}
 	if err != nil {
 		result.Error = fmt.Errorf("rename operation failed: %w", err)
+		result.Transient = filesystem.IsTransientError(err)
+		result.Locked = filesystem.IsLockedError(err)
+		result.Vanished = fsp.vanished(folder.Path, err)
 		return result, nil // Return result with error, don't fail the operation
 	}
 
@@ -78,11 +189,15 @@ func (fsp *FileSystemProcessor) ProcessRename(folder interfaces.FolderInfo, newN
 	return result, nil
 }
 
+// hashSuffixLength is the number of hex digits of a hash-derived collision suffix
+const hashSuffixLength = 6
+
 // resolveNameCollision handles naming conflicts by finding an available name
-// This method ensures that rename operations don't overwrite existing folders
-func (fsp *FileSystemProcessor) resolveNameCollision(targetPath, baseName string) (string, error) {
+// This method ensures that rename operations don't overwrite existing folders; originalName seeds hash suffixes
+// With hash suffixes, a contested name is suffixed even while it is free, so the first folder processed doesn't keep it
+func (fsp *FileSystemProcessor) resolveNameCollision(targetPath, baseName, originalName string, contested bool) (string, error) {
 	// Check if the target path is already available
-	if !fsp.pathExists(targetPath) {
+	if !fsp.pathExists(targetPath) && !(fsp.hashSuffixes && contested && !fsp.mergeOnCollision) {
 		return targetPath, nil
 	}
 
@@ -94,6 +209,16 @@ func (fsp *FileSystemProcessor) resolveNameCollision(targetPath, baseName string
 		nameWithoutExt = baseName[:len(baseName)-len(ext)]
 	}
 
+	// The hash suffix only depends on the original name; numbers follow only if even that is taken
+	if fsp.hashSuffixes {
+		sum := sha256.Sum256([]byte(originalName))
+		nameWithoutExt += "_" + hex.EncodeToString(sum[:])[:hashSuffixLength]
+		candidatePath := filepath.Join(dir, nameWithoutExt+ext)
+		if !fsp.pathExists(candidatePath) {
+			return candidatePath, nil
+		}
+	}
+
 	// Try numbered variations until we find an available name
 	for counter := 1; counter <= fsp.maxCollisionRetries; counter++ {
 		var candidateName string
@@ -117,18 +242,146 @@ func (fsp *FileSystemProcessor) resolveNameCollision(targetPath, baseName string
 // pathExists checks if a path exists in the file system
 // This method provides safe existence checking with proper error handling
 func (fsp *FileSystemProcessor) pathExists(path string) bool {
-	_, err := os.Stat(path)
+	// Simulated dry-run renames take precedence over the real file system
+	if exists, known := fsp.overlay.lookup(path); known {
+		return exists
+	}
+
+	// Below a simulated rename the folder's contents are still at its real path
+	_, err := fsp.fileSystem.Stat(fsp.overlay.realPath(path))
 	return err == nil
 }
 
+// isCaseOnlyRename reports whether renaming sourcePath to targetPath only changes case on a case-insensitive file system
+// Simulated renames are case-sensitive, so a target they created or removed is a different path.
+func (fsp *FileSystemProcessor) isCaseOnlyRename(sourcePath, targetPath string) bool {
+	if _, known := fsp.overlay.lookup(targetPath); known {
+		return false
+	}
+	return filesystem.IsCaseOnlyRename(fsp.fileSystem, fsp.overlay.realPath(sourcePath), fsp.overlay.realPath(targetPath))
+}
+
 // performRename executes the actual file system rename operation
-// This method handles the low-level rename with proper error context
-func (fsp *FileSystemProcessor) performRename(oldPath, newPath string) error {
-	// Attempt the rename operation
-	err := os.Rename(oldPath, newPath)
+// Transient errors are retried with exponential backoff; every attempt is counted in result
+func (fsp *FileSystemProcessor) performRename(oldPath, newPath string, result *interfaces.RenameResult) error {
+	delay := fsp.retryDelay
+	for attempt := 0; ; attempt++ {
+		result.Attempts++
+		err := fsp.fileSystem.Rename(oldPath, newPath)
+		if err == nil {
+			return nil
+		}
+
+		// A rename whose reply was lost may have succeeded, so a retry finds the source gone
+		if attempt > 0 && errors.Is(err, fs.ErrNotExist) && fsp.renameCompleted(oldPath, newPath) {
+			return nil
+		}
+
+		if !filesystem.IsTransientError(err) || attempt >= fsp.renameRetries {
+			// Provide more context about the failure
+			if attempt > 0 {
+				return fmt.Errorf("failed to rename '%s' to '%s' after %d attempts: %w", oldPath, newPath, attempt+1, err)
+			}
+			return fmt.Errorf("failed to rename '%s' to '%s': %w", oldPath, newPath, err)
+		}
+
+		fsp.sleep(delay)
+		delay *= 2
+	}
+}
+
+// vanished reports whether a rename failed because its source is gone, i.e. it was deleted or moved after the scan
+// A missing child during a merge doesn't count, since the source itself is still there.
+func (fsp *FileSystemProcessor) vanished(path string, err error) bool {
+	if !errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	if _, statErr := fsp.fileSystem.Lstat(path); !errors.Is(statErr, fs.ErrNotExist) {
+		return false
+	}
+	if !fsp.confirmVanished {
+		return true
+	}
+
+	entries, listErr := fsp.fileSystem.ReadDir(filepath.Dir(path))
+	if errors.Is(listErr, fs.ErrNotExist) {
+		return true // The whole branch is gone
+	}
+	if listErr != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.Name() == filepath.Base(path) {
+			return false
+		}
+	}
+	return true
+}
+
+// renameCompleted reports whether oldPath is gone and newPath exists, i.e. an earlier attempt went through
+func (fsp *FileSystemProcessor) renameCompleted(oldPath, newPath string) bool {
+	if _, err := fsp.fileSystem.Lstat(oldPath); !errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	_, err := fsp.fileSystem.Lstat(newPath)
+	return err == nil
+}
+
+// isMergeTarget reports whether targetPath is an existing directory distinct from sourcePath
+// A case-only rename on a case-insensitive file system resolves to the same directory and is not a merge;
+// neither is a link on either side, since merging would move the contents of its target, which may be outside the tree
+func (fsp *FileSystemProcessor) isMergeTarget(sourcePath, targetPath string) bool {
+	// Simulated targets are always distinct directories; simulated removals are gone
+	if exists, known := fsp.overlay.lookup(targetPath); known {
+		return exists
+	}
+
+	targetInfo, err := fsp.fileSystem.Lstat(fsp.overlay.realPath(targetPath))
+	if err != nil || !targetInfo.IsDir() {
+		return false
+	}
+
+	sourceInfo, err := fsp.fileSystem.Lstat(fsp.overlay.realPath(sourcePath))
+	if err != nil || !sourceInfo.IsDir() {
+		return false
+	}
+
+	return !fsp.fileSystem.SameFile(sourceInfo, targetInfo)
+}
+
+// mergeDirectories moves every child of sourcePath into targetPath and removes the emptied source
+// Child directories that exist in both are merged recursively, other conflicts get a numbered suffix; every move counts towards result
+func (fsp *FileSystemProcessor) mergeDirectories(sourcePath, targetPath string, result *interfaces.RenameResult) error {
+	entries, err := fsp.fileSystem.ReadDir(sourcePath)
 	if err != nil {
-		// Provide more context about the failure
-		return fmt.Errorf("failed to rename '%s' to '%s': %w", oldPath, newPath, err)
+		return fmt.Errorf("failed to read '%s': %w", sourcePath, err)
+	}
+
+	for _, entry := range entries {
+		childSource := filepath.Join(sourcePath, entry.Name())
+		childTarget := filepath.Join(targetPath, entry.Name())
+
+		// Recurse when both sides are directories
+		if entry.IsDir() && fsp.isMergeTarget(childSource, childTarget) {
+			if err := fsp.mergeDirectories(childSource, childTarget, result); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Otherwise move the child, picking a free name if the target is taken
+		finalPath, err := fsp.resolveNameCollision(childTarget, entry.Name(), entry.Name(), false)
+		if err != nil {
+			return err
+		}
+		if err := fsp.performRename(childSource, finalPath, result); err != nil {
+			return err
+		}
+	}
+
+	// The source is empty now; remove it to complete the merge
+	if err := fsp.fileSystem.Remove(sourcePath); err != nil {
+		return fmt.Errorf("failed to remove merged folder '%s': %w", sourcePath, err)
 	}
 
 	return nil
@@ -0,0 +1,366 @@
+// Package processor_test provides tests for the processor package.
+// This test suite ensures rename and merge operations behave correctly on a real file system.
+package processor_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/processor"
+)
+
+// folderInfo builds the FolderInfo for a direct child of parent
+func folderInfo(parent, name string) interfaces.FolderInfo {
+	return interfaces.FolderInfo{
+		Path:   filepath.Join(parent, name),
+		Name:   name,
+		Depth:  1,
+		Parent: parent,
+	}
+}
+
+// mustWrite creates a file with the given content, failing the test on error
+func mustWrite(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+// TestFileSystemProcessor_ProcessRename_Collision tests numbered suffixes when the target exists
+func TestFileSystemProcessor_ProcessRename_Collision(t *testing.T) {
+	root := t.TempDir()
+	mustWrite(t, filepath.Join(root, "source", "a.txt"), "a")
+	mustWrite(t, filepath.Join(root, "target", "b.txt"), "b")
+
+	p := processor.NewFileSystemProcessor(10)
+	result, err := p.ProcessRename(folderInfo(root, "source"), "target", false)
+	if err != nil || result.Error != nil {
+		t.Fatalf("ProcessRename() failed: %v %v", err, result.Error)
+	}
+
+	if result.NewPath != filepath.Join(root, "target_1") {
+		t.Errorf("Expected rename to target_1, got %s", result.NewPath)
+	}
+}
+
+// TestFileSystemProcessor_ProcessRename_HashSuffix tests that the suffix depends on the original name only
+func TestFileSystemProcessor_ProcessRename_HashSuffix(t *testing.T) {
+	root := t.TempDir()
+	mustWrite(t, filepath.Join(root, "Source?", "a.txt"), "a")
+	mustWrite(t, filepath.Join(root, "target", "b.txt"), "b")
+
+	sum := sha256.Sum256([]byte("Source?"))
+	want := filepath.Join(root, "target_"+hex.EncodeToString(sum[:])[:6])
+
+	// A dry run and the real rename agree, as both only depend on the original name
+	var p interfaces.FolderProcessor
+	for _, dryRun := range []bool{true, false} {
+		p = processor.NewFileSystemProcessor(10, processor.WithHashSuffixes(true))
+		result, err := p.ProcessRename(folderInfo(root, "Source?"), "target", dryRun)
+		if err != nil || result.Error != nil {
+			t.Fatalf("ProcessRename() failed: %v %v", err, result.Error)
+		}
+		if result.NewPath != want {
+			t.Errorf("Expected rename to %s, got %s", want, result.NewPath)
+		}
+	}
+
+	// When even the hashed name is taken, numbers follow the hash
+	mustWrite(t, filepath.Join(root, "Source?", "c.txt"), "c")
+	result, err := p.ProcessRename(folderInfo(root, "Source?"), "target", false)
+	if err != nil || result.Error != nil {
+		t.Fatalf("ProcessRename() failed: %v %v", err, result.Error)
+	}
+	if result.NewPath != want+"_1" {
+		t.Errorf("Expected rename to %s_1, got %s", want, result.NewPath)
+	}
+}
+
+// TestFileSystemProcessor_ProcessRename_HashSuffixOrder tests that siblings renamed to the same name get the same names in any order
+func TestFileSystemProcessor_ProcessRename_HashSuffixOrder(t *testing.T) {
+	names := []string{"Café", "Cafë"}
+	for _, dryRun := range []bool{true, false} {
+		var results []map[string]string
+		for _, order := range [][]string{names, {names[1], names[0]}} {
+			root := t.TempDir()
+			for _, name := range names {
+				mustWrite(t, filepath.Join(root, name, "file.txt"), name)
+			}
+
+			p := processor.NewFileSystemProcessor(10, processor.WithHashSuffixes(true))
+			renamed := make(map[string]string)
+			for _, name := range order {
+				folder := folderInfo(root, name)
+				folder.Contested = true // Both become Cafe
+				result, err := p.ProcessRename(folder, "Cafe", dryRun)
+				if err != nil || result.Error != nil {
+					t.Fatalf("ProcessRename() failed: %v %v", err, result.Error)
+				}
+				renamed[name] = filepath.Base(result.NewPath)
+			}
+			results = append(results, renamed)
+		}
+
+		for _, name := range names {
+			sum := sha256.Sum256([]byte(name))
+			want := "Cafe_" + hex.EncodeToString(sum[:])[:6]
+			for _, renamed := range results {
+				if renamed[name] != want {
+					t.Errorf("Expected %s to become %s in every order (dry run %v), got %v", name, want, dryRun, results)
+				}
+			}
+		}
+	}
+}
+
+// TestFileSystemProcessor_ProcessRename_CaseOnly tests that case-only renames on case-insensitive file systems change the case
+func TestFileSystemProcessor_ProcessRename_CaseOnly(t *testing.T) {
+	for _, dryRun := range []bool{true, false} {
+		memory := filesystem.NewCaseInsensitiveMemoryFileSystem()
+		memory.WriteFile("/root/FOLDER/file.txt", []byte("data"))
+
+		p := processor.NewFileSystemProcessor(10, processor.WithFileSystem(memory), processor.WithMergeOnCollision(true))
+		result, err := p.ProcessRename(folderInfo("/root", "FOLDER"), "Folder", dryRun)
+		if err != nil || result.Error != nil {
+			t.Fatalf("ProcessRename() failed: %v %v", err, result.Error)
+		}
+		if result.NewPath != "/root/Folder" || result.Merged {
+			t.Errorf("dryRun=%v: expected a rename to Folder, got %+v", dryRun, result)
+		}
+		if dryRun {
+			continue
+		}
+
+		entries, _ := memory.ReadDir("/root")
+		if len(entries) != 1 || entries[0].Name() != "Folder" {
+			t.Errorf("Expected the folder to be listed as Folder, got %v", entries)
+		}
+		if result.Attempts != 2 {
+			t.Errorf("Expected two renames through a temporary name, got %d", result.Attempts)
+		}
+	}
+}
+
+// TestFileSystemProcessor_ProcessRename_Merge tests merging into an existing directory
+func TestFileSystemProcessor_ProcessRename_Merge(t *testing.T) {
+	root := t.TempDir()
+	mustWrite(t, filepath.Join(root, "source", "only-source.txt"), "s")
+	mustWrite(t, filepath.Join(root, "source", "shared.txt"), "from source")
+	mustWrite(t, filepath.Join(root, "source", "sub", "deep.txt"), "deep")
+	mustWrite(t, filepath.Join(root, "target", "shared.txt"), "from target")
+	mustWrite(t, filepath.Join(root, "target", "sub", "existing.txt"), "existing")
+
+	p := processor.NewFileSystemProcessor(10, processor.WithMergeOnCollision(true))
+
+	// Dry run must not touch the file system
+	result, err := p.ProcessRename(folderInfo(root, "source"), "target", true)
+	if err != nil || !result.Merged {
+		t.Fatalf("Expected dry-run merge, got %+v, %v", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "source")); err != nil {
+		t.Fatalf("Dry run removed the source: %v", err)
+	}
+
+	result, err = p.ProcessRename(folderInfo(root, "source"), "target", false)
+	if err != nil || result.Error != nil {
+		t.Fatalf("ProcessRename() failed: %v %v", err, result.Error)
+	}
+	if !result.Merged || result.NewPath != filepath.Join(root, "target") {
+		t.Errorf("Expected merge into target, got %+v", result)
+	}
+
+	expectedFiles := map[string]string{
+		"only-source.txt":  "s",
+		"shared.txt":       "from target",
+		"shared_1.txt":     "from source",
+		"sub/deep.txt":     "deep",
+		"sub/existing.txt": "existing",
+	}
+	for name, content := range expectedFiles {
+		data, err := os.ReadFile(filepath.Join(root, "target", filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("Expected %s in merged target: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("Expected %s to contain %q, got %q", name, content, string(data))
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "source")); !os.IsNotExist(err) {
+		t.Errorf("Expected source to be removed after merge, got %v", err)
+	}
+}
+
+// TestFileSystemProcessor_DryRunOverlay tests that dry runs see collisions created by earlier simulated renames
+func TestFileSystemProcessor_DryRunOverlay(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a<b", "a>b"} {
+		if err := os.Mkdir(filepath.Join(root, name), 0755); err != nil {
+			t.Skipf("File system does not allow test folder name: %v", err)
+		}
+	}
+
+	p := processor.NewFileSystemProcessor(10)
+
+	first, err := p.ProcessRename(folderInfo(root, "a<b"), "a_b", true)
+	if err != nil || first.NewPath != filepath.Join(root, "a_b") {
+		t.Fatalf("Expected first dry-run rename to a_b, got %+v, %v", first, err)
+	}
+
+	// a_b doesn't exist on disk, but a real run would have created it
+	second, err := p.ProcessRename(folderInfo(root, "a>b"), "a_b", true)
+	if err != nil || second.NewPath != filepath.Join(root, "a_b_1") {
+		t.Errorf("Expected second dry-run rename to a_b_1, got %+v, %v", second, err)
+	}
+}
+
+// flakyFileSystem fails the first failures renames with err
+// With lostReply set, the failing renames still take place, as when a network reply goes missing
+type flakyFileSystem struct {
+	*filesystem.MemoryFileSystem
+	err       error
+	failures  int
+	lostReply bool
+	calls     int
+}
+
+// Rename fails until the configured number of failures has been returned
+func (f *flakyFileSystem) Rename(oldPath, newPath string) error {
+	f.calls++
+	if f.calls > f.failures {
+		return f.MemoryFileSystem.Rename(oldPath, newPath)
+	}
+	if f.lostReply {
+		if err := f.MemoryFileSystem.Rename(oldPath, newPath); err != nil {
+			return err
+		}
+	}
+	return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: f.err}
+}
+
+// TestFileSystemProcessor_RenameRetries tests that transient rename errors are retried and classified
+func TestFileSystemProcessor_RenameRetries(t *testing.T) {
+	if !filesystem.IsTransientError(syscall.EBUSY) {
+		t.Skip("EBUSY is not a transient error on this platform")
+	}
+
+	tests := []struct {
+		name          string
+		flaky         flakyFileSystem
+		wantSuccess   bool
+		wantTransient bool
+		wantAttempts  int
+		wantLocked    bool
+	}{
+		{"busy then free", flakyFileSystem{err: syscall.EBUSY, failures: 2}, true, false, 3, false},
+		{"busy throughout", flakyFileSystem{err: syscall.EBUSY, failures: 10}, false, true, 4, true},
+		{"permanent error", flakyFileSystem{err: syscall.EINVAL, failures: 10}, false, false, 1, false},
+		{"lost reply", flakyFileSystem{err: syscall.ETIMEDOUT, failures: 1, lostReply: true}, true, false, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := filesystem.NewMemoryFileSystem()
+			memory.MkdirAll("/share/bad:name")
+			flaky := tt.flaky
+			flaky.MemoryFileSystem = memory
+
+			proc := processor.NewFileSystemProcessor(0,
+				processor.WithFileSystem(&flaky),
+				processor.WithRenameRetries(3, time.Millisecond),
+			)
+			result, err := proc.ProcessRename(folderInfo("/share", "bad:name"), "bad_name", false)
+			if err != nil {
+				t.Fatalf("ProcessRename() returned error: %v", err)
+			}
+
+			if result.Success != tt.wantSuccess || result.Transient != tt.wantTransient || result.Attempts != tt.wantAttempts {
+				t.Errorf("Got success=%v transient=%v attempts=%d (error %v), expected %v, %v, %d",
+					result.Success, result.Transient, result.Attempts, result.Error, tt.wantSuccess, tt.wantTransient, tt.wantAttempts)
+			}
+			if tt.wantSuccess && !memory.Exists("/share/bad_name") {
+				t.Error("Expected the folder to be renamed")
+			}
+			if result.Locked != tt.wantLocked {
+				t.Errorf("Got locked=%v, expected %v", result.Locked, tt.wantLocked)
+			}
+		})
+	}
+}
+
+// staleFileSystem reports one folder as missing to renames and lookups while it is still listed in its parent
+// This is what a stale attribute cache on a network share looks like
+type staleFileSystem struct {
+	*filesystem.MemoryFileSystem
+	stale string
+}
+
+// Rename fails for the stale folder as if it were gone
+func (s *staleFileSystem) Rename(oldPath, newPath string) error {
+	if oldPath == s.stale {
+		return &os.LinkError{Op: "rename", Old: oldPath, New: newPath, Err: fs.ErrNotExist}
+	}
+	return s.MemoryFileSystem.Rename(oldPath, newPath)
+}
+
+// Lstat reports the stale folder as missing
+func (s *staleFileSystem) Lstat(path string) (fs.FileInfo, error) {
+	if path == s.stale {
+		return nil, &fs.PathError{Op: "lstat", Path: path, Err: fs.ErrNotExist}
+	}
+	return s.MemoryFileSystem.Lstat(path)
+}
+
+// TestFileSystemProcessor_Vanished tests that folders gone since the scan are told apart from failed renames
+func TestFileSystemProcessor_Vanished(t *testing.T) {
+	tests := []struct {
+		name         string
+		folder       interfaces.FolderInfo
+		stale        bool
+		confirm      bool
+		wantVanished bool
+	}{
+		{"deleted folder", folderInfo("/share", "gone"), false, false, true},
+		{"deleted folder confirmed", folderInfo("/share", "gone"), false, true, true},
+		{"deleted branch confirmed", folderInfo("/share/gone", "child"), false, true, true},
+		{"stale lookup", folderInfo("/share", "listed"), true, false, true},
+		{"stale lookup confirmed", folderInfo("/share", "listed"), true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := filesystem.NewMemoryFileSystem()
+			memory.MkdirAll("/share/listed")
+			fileSystem := &staleFileSystem{MemoryFileSystem: memory}
+			if tt.stale {
+				fileSystem.stale = tt.folder.Path
+			}
+
+			proc := processor.NewFileSystemProcessor(0,
+				processor.WithFileSystem(fileSystem),
+				processor.WithConfirmVanished(tt.confirm),
+			)
+			result, err := proc.ProcessRename(tt.folder, "renamed", false)
+			if err != nil {
+				t.Fatalf("ProcessRename() returned error: %v", err)
+			}
+
+			if result.Error == nil || result.Vanished != tt.wantVanished {
+				t.Errorf("Expected a failed rename with vanished=%v, got vanished=%v (error %v)", tt.wantVanished, result.Vanished, result.Error)
+			}
+		})
+	}
+}
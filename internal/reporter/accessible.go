@@ -0,0 +1,134 @@
+// Package reporter provides an accessible progress reporter for screen readers.
+// This implementation avoids alt-screen rendering, emoji and color so every event is a plain sentence.
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/collation"
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// AccessibleReporter implements the ProgressReporter, RenameReporter and WarningReporter interfaces
+// This struct writes plain, sequential lines with explicit wording for screen reader users
+type AccessibleReporter struct {
+	verbosity Verbosity
+	dryRun    bool
+	collator  *collation.Collator
+}
+
+// NewAccessibleReporter creates a new screen-reader friendly progress reporter
+// This constructor configures the reporter for different output modes; collator orders names in the summary
+func NewAccessibleReporter(verbosity Verbosity, dryRun bool, collator *collation.Collator) interfaces.ProgressReporter {
+	return &AccessibleReporter{
+		verbosity: verbosity,
+		dryRun:    dryRun,
+		collator:  collator,
+	}
+}
+
+// ReportProgress announces which folder is being processed
+// Progress is only announced from debug verbosity up to avoid flooding the screen reader
+func (ar *AccessibleReporter) ReportProgress(current, total int, message string) {
+	if ar.verbosity >= VerbosityDebug {
+		fmt.Printf("Folder %d of %d. %s\n", current, total, message)
+	}
+}
+
+// ReportRename announces a single rename using explicit wording, except in quiet mode
+// This method never relies on color or symbols to convey the outcome
+func (ar *AccessibleReporter) ReportRename(result interfaces.RenameResult) {
+	if ar.verbosity == VerbosityQuiet {
+		return
+	}
+	switch {
+	case result.Merged && ar.dryRun:
+		fmt.Printf("Would merge %s into %s\n", result.OldPath, result.NewPath)
+	case result.Merged:
+		fmt.Printf("Merged %s into %s\n", result.OldPath, result.NewPath)
+	case ar.dryRun:
+		fmt.Printf("Would rename %s to %s\n", result.OldPath, result.NewPath)
+	default:
+		fmt.Printf("Renamed %s to %s\n", result.OldPath, result.NewPath)
+	}
+	if len(result.Rules) > 0 {
+		fmt.Printf("Reason: %s.\n", strings.Join(result.Rules, ", "))
+	}
+}
+
+// ReportError announces an error as a plain sentence
+// This method ensures errors are spoken in full rather than signaled visually
+func (ar *AccessibleReporter) ReportError(err error) {
+	fmt.Printf("Error: %v\n", err)
+}
+
+// ReportWarning announces a problem that did not stop the run as a plain sentence
+// This method implements the WarningReporter interface
+func (ar *AccessibleReporter) ReportWarning(warning error) {
+	if ar.verbosity == VerbosityQuiet {
+		return
+	}
+	fmt.Printf("Warning: %v\n", warning)
+}
+
+// ReportComplete announces the summary as full sentences
+// This method provides the same information as the CLI summary without decorations; like there, quiet runs may skip it
+func (ar *AccessibleReporter) ReportComplete(summary interfaces.ProcessingSummary) {
+	if ar.verbosity == VerbosityQuiet && !noteworthy(summary) {
+		return
+	}
+	if ar.dryRun {
+		fmt.Println("Dry run complete. No changes were made to the file system.")
+	} else {
+		fmt.Println("Processing complete.")
+	}
+
+	fmt.Printf("%d folders found.\n", summary.TotalFolders)
+	fmt.Printf("%d folders processed.\n", summary.ProcessedCount)
+	if ar.dryRun {
+		fmt.Printf("%d renames planned: %d would be applied, %d deferred, %d failed.\n",
+			summary.Phases.Planned, summary.Phases.WouldRename, summary.Phases.Deferred, summary.Phases.Failed)
+	} else {
+		fmt.Printf("%d renames planned: %d applied, %d deferred, %d failed.\n",
+			summary.Phases.Planned, summary.Phases.Applied, summary.Phases.Deferred, summary.Phases.Failed)
+	}
+	if summary.Phases.Vanished > 0 {
+		fmt.Printf("%d folders vanished before they could be renamed.\n", summary.Phases.Vanished)
+	}
+	fmt.Printf("%d folders already compliant.\n", summary.Phases.Skipped)
+	if summary.WarningCount > 0 {
+		fmt.Printf("%d warnings.\n", summary.WarningCount)
+	}
+	fmt.Printf("Time elapsed: %s.\n", summary.ElapsedTime)
+	if summary.RunID != "" {
+		fmt.Printf("Run ID: %s.\n", summary.RunID)
+	}
+
+	if summary.Aborted {
+		fmt.Printf("Run aborted early: %s.\n", summary.AbortReason)
+		if summary.RemainingCount > 0 {
+			fmt.Printf("%d folders were not processed.\n", summary.RemainingCount)
+		}
+	}
+
+	if verification := summary.Verification; verification != nil {
+		fmt.Printf("%s.\n", verificationHeadline(verification))
+		for _, discrepancy := range verification.Discrepancies {
+			fmt.Printf("Discrepancy: %s.\n", discrepancy)
+		}
+	}
+
+	if len(summary.Locked) > 0 {
+		fmt.Printf("%s.\n", lockedHeadline(len(summary.Locked)))
+		for _, path := range summary.Locked {
+			fmt.Printf("In use: %s.\n", path)
+		}
+	}
+
+	for _, section := range breakdowns(summary, ar.dryRun, ar.collator) {
+		for _, line := range section.lines {
+			fmt.Printf("%s %s.\n", section.label, line)
+		}
+	}
+}
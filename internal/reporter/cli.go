@@ -4,68 +4,207 @@ package reporter
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
-	"sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/collation"
+	"github.com/punkscience/sanitize/internal/interfaces"
 )
 
-// CLIReporter implements the ProgressReporter interface for command-line output
+// CLIReporter implements the ProgressReporter, RenameReporter, WarningReporter and ScanReporter interfaces for command-line output
 // This struct provides simple text-based progress reporting
 type CLIReporter struct {
-	verbose bool
-	dryRun  bool
+	out       io.Writer
+	verbosity Verbosity
+	dryRun    bool
+	color     bool // Highlight the changed characters of renames with ANSI colors
+	terminal  bool // Stdout is a terminal, so progress is redrawn in place
+	collator  *collation.Collator
+	progress  *progressMeter // Progress below debug verbosity
+}
+
+// CLIOption configures optional CLIReporter behavior
+type CLIOption func(*CLIReporter)
+
+// WithColor highlights the changed characters of renames with ANSI colors
+func WithColor(color bool) CLIOption {
+	return func(cr *CLIReporter) {
+		cr.color = color
+	}
+}
+
+// WithOutput writes to w instead of stdout
+func WithOutput(w io.Writer) CLIOption {
+	return func(cr *CLIReporter) {
+		cr.out = w
+	}
+}
+
+// WithTerminal shows progress as a bar redrawn in place instead of periodic log lines
+func WithTerminal(terminal bool) CLIOption {
+	return func(cr *CLIReporter) {
+		cr.terminal = terminal
+	}
 }
 
 // NewCLIReporter creates a new CLI progress reporter
-// This constructor configures the reporter for different output modes
-func NewCLIReporter(verbose, dryRun bool) interfaces.ProgressReporter {
-	return &CLIReporter{
-		verbose: verbose,
-		dryRun:  dryRun,
+// This constructor configures the reporter for different output modes; collator orders names in the summary
+func NewCLIReporter(verbosity Verbosity, dryRun bool, collator *collation.Collator, options ...CLIOption) interfaces.ProgressReporter {
+	cr := &CLIReporter{
+		out:       os.Stdout,
+		verbosity: verbosity,
+		dryRun:    dryRun,
+		collator:  collator,
+	}
+
+	for _, option := range options {
+		option(cr)
 	}
+	cr.progress = newProgressMeter(cr.out, cr.terminal)
+
+	return cr
 }
 
 // ReportProgress sends progress updates to the console
-// This method provides real-time feedback during processing
+// Debug verbosity prints every folder; otherwise a progress bar with throughput and ETA is shown, except in quiet mode
 func (cr *CLIReporter) ReportProgress(current, total int, message string) {
-	if cr.verbose {
-		fmt.Printf("[%d/%d] %s\n", current, total, message)
+	switch {
+	case cr.verbosity >= VerbosityDebug:
+		cr.progress.clear() // The scanning line gives way to the per-folder lines
+		fmt.Fprintf(cr.out, "[%d/%d] %s\n", current, total, message)
+	case cr.verbosity > VerbosityQuiet:
+		cr.progress.update(current, total, message)
+	}
+}
+
+// ReportScan shows how many directories the walk found so far, and at trace verbosity every directory it reads
+// This method implements the ScanReporter interface
+func (cr *CLIReporter) ReportScan(scanned int, path string) {
+	switch {
+	case cr.verbosity >= VerbosityTrace:
+		fmt.Fprintf(cr.out, "Scanning [%d] %s\n", scanned, path)
+	case cr.verbosity > VerbosityQuiet:
+		cr.progress.scan(scanned, path)
 	}
 }
 
+// ReportRename shows a single rename and the rules behind it from info verbosity up
+// This method implements the RenameReporter interface
+func (cr *CLIReporter) ReportRename(result interfaces.RenameResult) {
+	if cr.verbosity < VerbosityInfo {
+		return
+	}
+	verb := "Renamed"
+	if cr.dryRun {
+		verb = "Would rename"
+	}
+	oldPath, newName := result.OldPath, filepath.Base(result.NewPath)
+	if cr.color {
+		oldPath, newName = highlightPaths(oldPath, newName)
+	}
+	cr.progress.printLine(fmt.Sprintf("  %s %s -> %s%s", verb, oldPath, newName, ruleSuffix(result.Rules)))
+}
+
 // ReportError sends error information to the console
 // This method ensures errors are visible to the user
 func (cr *CLIReporter) ReportError(err error) {
-	fmt.Printf("Error: %v\n", err)
+	cr.progress.printLine(fmt.Sprintf("Error: %v", err))
+}
+
+// ReportWarning shows a problem that did not stop the run, except in quiet mode
+// This method implements the WarningReporter interface
+func (cr *CLIReporter) ReportWarning(warning error) {
+	if cr.verbosity == VerbosityQuiet {
+		return
+	}
+	cr.progress.printLine(fmt.Sprintf("Warning: %v", warning))
 }
 
 // ReportComplete signals that processing is finished with a summary
-// This method provides a comprehensive overview of the operation results
+// This method provides a comprehensive overview of the operation results; quiet runs that changed and failed nothing print none
 func (cr *CLIReporter) ReportComplete(summary interfaces.ProcessingSummary) {
+	cr.progress.clear()
+	if cr.verbosity == VerbosityQuiet && !noteworthy(summary) {
+		return
+	}
+
+	if cr.dryRun {
+		fmt.Fprintln(cr.out, "\n=== DRY RUN SUMMARY ===")
+		fmt.Fprintln(cr.out, "No changes were made to the file system")
+	} else {
+		fmt.Fprintln(cr.out, "\n=== PROCESSING SUMMARY ===")
+	}
+
+	fmt.Fprintf(cr.out, "Total folders found: %d\n", summary.TotalFolders)
+	fmt.Fprintf(cr.out, "Folders processed: %d\n", summary.ProcessedCount)
+	fmt.Fprintf(cr.out, "Renames planned: %d\n", summary.Phases.Planned)
 	if cr.dryRun {
-		fmt.Println("\n=== DRY RUN SUMMARY ===")
-		fmt.Println("No changes were made to the file system")
+		fmt.Fprintf(cr.out, "  Would rename: %d\n", summary.Phases.WouldRename)
 	} else {
-		fmt.Println("\n=== PROCESSING SUMMARY ===")
+		fmt.Fprintf(cr.out, "  Applied: %d\n", summary.Phases.Applied)
+	}
+	fmt.Fprintf(cr.out, "  Deferred: %d\n", summary.Phases.Deferred)
+	fmt.Fprintf(cr.out, "  Failed: %d\n", summary.Phases.Failed)
+	if summary.Phases.Vanished > 0 {
+		fmt.Fprintf(cr.out, "  Vanished: %d\n", summary.Phases.Vanished)
 	}
+	fmt.Fprintf(cr.out, "Already compliant: %d\n", summary.Phases.Skipped)
 
-	fmt.Printf("Total folders found: %d\n", summary.TotalFolders)
-	fmt.Printf("Folders processed: %d\n", summary.ProcessedCount)
-	fmt.Printf("Folders renamed: %d\n", summary.RenamedCount)
-	fmt.Printf("Folders skipped: %d\n", summary.SkippedCount)
+	if summary.WarningCount > 0 {
+		fmt.Fprintf(cr.out, "Warnings: %d\n", summary.WarningCount)
+	}
 
-	if summary.ErrorCount > 0 {
-		fmt.Printf("Errors encountered: %d\n", summary.ErrorCount)
+	fmt.Fprintf(cr.out, "Time elapsed: %s\n", summary.ElapsedTime)
+	if summary.RunID != "" {
+		fmt.Fprintf(cr.out, "Run ID: %s\n", summary.RunID)
 	}
 
-	fmt.Printf("Time elapsed: %s\n", summary.ElapsedTime)
+	if summary.Aborted {
+		fmt.Fprintf(cr.out, "\nRun aborted early: %s\n", summary.AbortReason)
+		if summary.RemainingCount > 0 {
+			fmt.Fprintf(cr.out, "%d folders were not processed.\n", summary.RemainingCount)
+		}
+	}
 
-	if summary.RenamedCount > 0 {
-		if cr.dryRun {
-			fmt.Printf("\n%d folders would be renamed. Run without --dry-run to apply changes.\n", summary.RenamedCount)
-		} else {
-			fmt.Printf("\nSuccessfully sanitized %d folder names.\n", summary.RenamedCount)
+	if verification := summary.Verification; verification != nil {
+		fmt.Fprintf(cr.out, "\n%s\n", verificationHeadline(verification))
+		for _, discrepancy := range verification.Discrepancies {
+			fmt.Fprintf(cr.out, "  %s\n", discrepancy)
 		}
-	} else if summary.TotalFolders > 0 {
-		fmt.Println("\nAll folder names are already compatible.")
 	}
+
+	if len(summary.Locked) > 0 {
+		fmt.Fprintf(cr.out, "\n%s:\n", lockedHeadline(len(summary.Locked)))
+		for _, path := range summary.Locked {
+			fmt.Fprintf(cr.out, "  %s\n", path)
+		}
+	}
+
+	for _, section := range breakdowns(summary, cr.dryRun, cr.collator) {
+		fmt.Fprintf(cr.out, "\n%s:\n", section.title)
+		for _, line := range section.lines {
+			fmt.Fprintf(cr.out, "  %s\n", line)
+		}
+	}
+
+	switch {
+	case cr.dryRun && summary.Phases.WouldRename+summary.Phases.Deferred > 0:
+		fmt.Fprintf(cr.out, "\n%d folders would be renamed. Run without --dry-run to apply changes.\n", summary.Phases.WouldRename+summary.Phases.Deferred)
+	case summary.Phases.Deferred > 0:
+		fmt.Fprintf(cr.out, "\nSanitized %d folder names; %d planned renames were deferred. Run again to apply them.\n", summary.Phases.Applied, summary.Phases.Deferred)
+	case summary.Phases.Applied > 0:
+		fmt.Fprintf(cr.out, "\nSuccessfully sanitized %d folder names.\n", summary.Phases.Applied)
+	case summary.Phases.Planned == 0 && summary.TotalFolders > 0:
+		fmt.Fprintln(cr.out, "\nAll folder names are already compatible.")
+	}
+}
+
+// ruleSuffix formats the rules that changed a name for the end of a rename line, or "" when none are known
+func ruleSuffix(rules []string) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(rules, ", ") + "]"
 }
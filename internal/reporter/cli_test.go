@@ -0,0 +1,44 @@
+// Package reporter_test provides tests for the console reporters.
+// This test suite checks what the reporters write, with output captured in buffers.
+package reporter_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/reporter"
+)
+
+// visibleLines returns the lines a terminal shows for out, where a carriage return starts the line over
+func visibleLines(out string) []string {
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line[strings.LastIndex(line, "\r")+1:], " ")
+	}
+	return lines
+}
+
+// TestCLIReporter_RenameBelowProgressBar tests that renames at info verbosity are printed on their own line above the bar
+func TestCLIReporter_RenameBelowProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	cli := reporter.NewCLIReporter(reporter.VerbosityInfo, true, nil, reporter.WithOutput(&out), reporter.WithTerminal(true))
+
+	cli.ReportProgress(2, 2, "Processing: /tmp/tt/bad:27") // The last folder draws the bar at once
+	if !strings.Contains(out.String(), "100% 2/2") {
+		t.Fatalf("Expected the progress bar to be drawn, got %q", out.String())
+	}
+	cli.(interfaces.RenameReporter).ReportRename(interfaces.RenameResult{OldPath: "/tmp/tt/bad:27", NewPath: "/tmp/tt/bad_27"})
+
+	lines := visibleLines(out.String())
+	if len(lines) != 2 {
+		t.Fatalf("Expected the rename line and the redrawn bar, got %q", lines)
+	}
+	if lines[0] != "  Would rename /tmp/tt/bad:27 -> bad_27" {
+		t.Errorf("Expected the rename on a line of its own, got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "[") || !strings.Contains(lines[1], "100% 2/2") {
+		t.Errorf("Expected the bar to be redrawn below the rename, got %q", lines[1])
+	}
+}
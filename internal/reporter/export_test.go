@@ -0,0 +1,42 @@
+package reporter
+
+import "time"
+
+// TUIModel exposes the state of the TUI to the tests, which drive it without a terminal
+type TUIModel = tuiModel
+
+// NewTUIModel creates the model of a TUI with plain ASCII decorations and the given clock
+func NewTUIModel(dryRun bool, now func() time.Time) *TUIModel {
+	model := newTUIModel(dryRun, asciiGlyphs, nil)
+	model.now = now
+	return model
+}
+
+// AddResult appends a rename line to the result list
+func (m *tuiModel) AddResult(text string) {
+	m.addResult(resultEntry{text: text, status: resultRenamed})
+}
+
+// SaveResults writes the results like the w key does
+func (m *tuiModel) SaveResults() (string, error) {
+	return m.saveResults()
+}
+
+// SetQuery filters the result list like typing the query after / does
+func (m *tuiModel) SetQuery(query string) {
+	m.setQuery(query)
+}
+
+// Shown returns the lines of the result list that match the query
+func (m *tuiModel) Shown() []string {
+	lines := make([]string, 0, len(m.shown))
+	for _, entry := range m.shown {
+		lines = append(lines, entry.text)
+	}
+	return lines
+}
+
+// QueryErr returns why the query is not a valid regular expression
+func (m *tuiModel) QueryErr() string {
+	return m.queryErr
+}
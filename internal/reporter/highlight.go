@@ -0,0 +1,39 @@
+package reporter
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/diff"
+)
+
+// ANSI escape sequences for highlighted characters; underlining keeps changed spaces visible
+const (
+	removedColor = "\x1b[1;4;31m" // Bold, underlined red
+	addedColor   = "\x1b[1;4;32m" // Bold, underlined green
+	resetColor   = "\x1b[0m"
+)
+
+// highlightChanges colors the characters a rename removes from oldName and adds to newName
+func highlightChanges(oldName, newName string) (string, string) {
+	var oldBuilder, newBuilder strings.Builder
+	for _, segment := range diff.Runes(oldName, newName) {
+		switch segment.Op {
+		case diff.Delete:
+			oldBuilder.WriteString(removedColor + segment.Text + resetColor)
+		case diff.Insert:
+			newBuilder.WriteString(addedColor + segment.Text + resetColor)
+		default:
+			oldBuilder.WriteString(segment.Text)
+			newBuilder.WriteString(segment.Text)
+		}
+	}
+	return oldBuilder.String(), newBuilder.String()
+}
+
+// highlightPaths colors the changes between the last elements of oldPath and newPath
+// The parent directories are left alone, since a rename doesn't change them
+func highlightPaths(oldPath, newPath string) (string, string) {
+	oldName, newName := highlightChanges(filepath.Base(oldPath), filepath.Base(newPath))
+	return strings.TrimSuffix(oldPath, filepath.Base(oldPath)) + oldName, strings.TrimSuffix(newPath, filepath.Base(newPath)) + newName
+}
@@ -0,0 +1,139 @@
+// Package reporter provides a machine-parsable JSON Lines progress reporter.
+// This implementation is designed for GUI wrappers and installers that render their own progress.
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// jsonProgressInterval limits how often progress records are emitted
+const jsonProgressInterval = 250 * time.Millisecond
+
+// JSONReporter implements ProgressReporter, FolderReporter, RenameReporter and WarningReporter
+// This struct writes one compact JSON object per line to the configured writer
+type JSONReporter struct {
+	encoder     *json.Encoder
+	currentPath string
+	renamed     int
+	errors      int
+	lastEmit    time.Time
+}
+
+// jsonRecord is a single line of machine-parsable output
+type jsonRecord struct {
+	Type    string                        `json:"type"`               // progress, rename, error, warning or complete
+	Current int                           `json:"current,omitempty"`  // Index of the current folder (1-based)
+	Total   int                           `json:"total,omitempty"`    // Total number of folders
+	Percent float64                       `json:"percent,omitempty"`  // Completion percentage (0-100)
+	Renamed int                           `json:"renamed"`            // Folders renamed so far
+	Errors  int                           `json:"errors"`             // Errors encountered so far
+	Path    string                        `json:"path,omitempty"`     // Folder currently being processed
+	NewPath string                        `json:"new_path,omitempty"` // New path of rename records
+	Merged  bool                          `json:"merged,omitempty"`   // Whether a rename record merged into an existing folder
+	Rules   []string                      `json:"rules,omitempty"`    // Rules that changed the name of rename records
+	Message string                        `json:"message,omitempty"`  // Message of error and warning records
+	Summary *interfaces.ProcessingSummary `json:"summary,omitempty"`  // Final summary for complete records
+}
+
+// NewJSONReporter creates a reporter that writes JSON Lines records to w
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false) // Keep characters such as < and > readable in paths
+
+	return &JSONReporter{
+		encoder: encoder,
+	}
+}
+
+// ReportFolder remembers the path of the folder about to be processed
+func (jr *JSONReporter) ReportFolder(current, total int, folder interfaces.FolderInfo) {
+	jr.currentPath = folder.Path
+}
+
+// ReportProgress emits a progress record, throttled to jsonProgressInterval
+// The first and last records are always emitted so consumers see 0% and 100%
+func (jr *JSONReporter) ReportProgress(current, total int, message string) {
+	now := time.Now()
+	if current != 1 && current != total && now.Sub(jr.lastEmit) < jsonProgressInterval {
+		return
+	}
+	jr.lastEmit = now
+
+	jr.emit(jsonRecord{
+		Type:    "progress",
+		Current: current,
+		Total:   total,
+		Percent: percentage(current, total),
+		Renamed: jr.renamed,
+		Errors:  jr.errors,
+		Path:    jr.currentPath,
+	})
+}
+
+// ReportRename counts a successful rename and emits a rename record with the rules behind it
+func (jr *JSONReporter) ReportRename(result interfaces.RenameResult) {
+	jr.renamed++
+	jr.emit(jsonRecord{
+		Type:    "rename",
+		Renamed: jr.renamed,
+		Errors:  jr.errors,
+		Path:    result.OldPath,
+		NewPath: result.NewPath,
+		Merged:  result.Merged,
+		Rules:   result.Rules,
+	})
+}
+
+// ReportError emits an error record immediately
+func (jr *JSONReporter) ReportError(err error) {
+	jr.errors++
+	jr.emit(jsonRecord{
+		Type:    "error",
+		Renamed: jr.renamed,
+		Errors:  jr.errors,
+		Path:    jr.currentPath,
+		Message: err.Error(),
+	})
+}
+
+// ReportWarning emits a warning record immediately; warnings don't count as errors
+func (jr *JSONReporter) ReportWarning(warning error) {
+	jr.emit(jsonRecord{
+		Type:    "warning",
+		Renamed: jr.renamed,
+		Errors:  jr.errors,
+		Path:    jr.currentPath,
+		Message: warning.Error(),
+	})
+}
+
+// ReportComplete emits the final record including the full summary
+func (jr *JSONReporter) ReportComplete(summary interfaces.ProcessingSummary) {
+	jr.emit(jsonRecord{
+		Type:    "complete",
+		Current: summary.ProcessedCount,
+		Total:   summary.TotalFolders,
+		Percent: percentage(summary.ProcessedCount, summary.TotalFolders),
+		Renamed: summary.RenamedCount,
+		Errors:  summary.ErrorCount,
+		Summary: &summary,
+	})
+}
+
+// emit writes a single record; write errors are ignored so a closed pipe never stops a run
+func (jr *JSONReporter) emit(record jsonRecord) {
+	_ = jr.encoder.Encode(record)
+}
+
+// percentage returns current/total as a percentage rounded to one decimal, treating an empty run as complete
+func percentage(current, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return math.Round(float64(current)/float64(total)*1000) / 10
+}
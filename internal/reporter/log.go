@@ -0,0 +1,71 @@
+// Package reporter provides a progress reporter that writes an audit trail to a structured log.
+// This implementation is combined with the console reporters, so the log is independent of what is shown.
+package reporter
+
+import (
+	"log/slog"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// LogReporter implements the ProgressReporter and RenameReporter interfaces with a slog logger
+// Warnings and skipped directories are logged by the walker as they are found, so they are not repeated here
+type LogReporter struct {
+	logger *slog.Logger
+	dryRun bool
+}
+
+// NewLogReporter creates a reporter that logs every rename, error and the summary of a run
+func NewLogReporter(logger *slog.Logger, dryRun bool) *LogReporter {
+	return &LogReporter{
+		logger: logger.With("dry_run", dryRun),
+		dryRun: dryRun,
+	}
+}
+
+// ReportProgress logs each folder as it is checked, at debug level
+func (lr *LogReporter) ReportProgress(current, total int, message string) {
+	lr.logger.Debug("checking folder", "current", current, "total", total, "message", message)
+}
+
+// ReportRename logs a single rename with the rules behind it
+// This method implements the RenameReporter interface
+func (lr *LogReporter) ReportRename(result interfaces.RenameResult) {
+	message := "renamed folder"
+	switch {
+	case result.Merged && lr.dryRun:
+		message = "would merge folder"
+	case result.Merged:
+		message = "merged folder"
+	case lr.dryRun:
+		message = "would rename folder"
+	}
+	lr.logger.Info(message, "old_path", result.OldPath, "new_path", result.NewPath, "rules", result.Rules)
+}
+
+// ReportError logs an error, including folders that could not be processed
+func (lr *LogReporter) ReportError(err error) {
+	lr.logger.Error("error", "error", err)
+}
+
+// ReportComplete logs the counts of the finished run
+func (lr *LogReporter) ReportComplete(summary interfaces.ProcessingSummary) {
+	attributes := []any{
+		"folders", summary.TotalFolders,
+		"processed", summary.ProcessedCount,
+		"planned", summary.Phases.Planned,
+		"applied", summary.Phases.Applied,
+		"would_rename", summary.Phases.WouldRename,
+		"deferred", summary.Phases.Deferred,
+		"failed", summary.Phases.Failed,
+		"vanished", summary.Phases.Vanished,
+		"compliant", summary.Phases.Skipped,
+		"warnings", summary.WarningCount,
+		"elapsed", summary.ElapsedTime,
+	}
+	if summary.Aborted {
+		lr.logger.Warn("run aborted", append(attributes, "reason", summary.AbortReason)...)
+		return
+	}
+	lr.logger.Info("run complete", attributes...)
+}
@@ -0,0 +1,111 @@
+// Package reporter provides a fan-out reporter that forwards every event to several reporters.
+// This implementation follows the Composite pattern so outputs can be combined freely.
+package reporter
+
+import (
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// MultiReporter implements ProgressReporter and all optional reporter extensions
+// Optional events are only forwarded to reporters that support them
+type MultiReporter struct {
+	reporters []interfaces.ProgressReporter
+}
+
+// NewMultiReporter creates a reporter that forwards every event to all given reporters
+func NewMultiReporter(reporters ...interfaces.ProgressReporter) *MultiReporter {
+	return &MultiReporter{
+		reporters: reporters,
+	}
+}
+
+// ReportProgress forwards progress updates to every reporter
+func (mr *MultiReporter) ReportProgress(current, total int, message string) {
+	for _, r := range mr.reporters {
+		r.ReportProgress(current, total, message)
+	}
+}
+
+// ReportError forwards errors to every reporter
+func (mr *MultiReporter) ReportError(err error) {
+	for _, r := range mr.reporters {
+		r.ReportError(err)
+	}
+}
+
+// ReportComplete forwards the summary to every reporter
+func (mr *MultiReporter) ReportComplete(summary interfaces.ProcessingSummary) {
+	for _, r := range mr.reporters {
+		r.ReportComplete(summary)
+	}
+}
+
+// ReportWarning forwards warnings to reporters that support them
+func (mr *MultiReporter) ReportWarning(warning error) {
+	for _, r := range mr.reporters {
+		if warningReporter, ok := r.(interfaces.WarningReporter); ok {
+			warningReporter.ReportWarning(warning)
+		}
+	}
+}
+
+// ReportScan forwards the discovery progress to reporters that support it
+func (mr *MultiReporter) ReportScan(scanned int, path string) {
+	for _, r := range mr.reporters {
+		if scanReporter, ok := r.(interfaces.ScanReporter); ok {
+			scanReporter.ReportScan(scanned, path)
+		}
+	}
+}
+
+// ReportFolder forwards the current folder to reporters that support it
+func (mr *MultiReporter) ReportFolder(current, total int, folder interfaces.FolderInfo) {
+	for _, r := range mr.reporters {
+		if folderReporter, ok := r.(interfaces.FolderReporter); ok {
+			folderReporter.ReportFolder(current, total, folder)
+		}
+	}
+}
+
+// ReportRename forwards renames to reporters that support them
+func (mr *MultiReporter) ReportRename(result interfaces.RenameResult) {
+	for _, r := range mr.reporters {
+		if renameReporter, ok := r.(interfaces.RenameReporter); ok {
+			renameReporter.ReportRename(result)
+		}
+	}
+}
+
+// ReportFailure forwards failures to reporters that support them
+func (mr *MultiReporter) ReportFailure(folder interfaces.FolderInfo, err error) {
+	for _, r := range mr.reporters {
+		if failureReporter, ok := r.(interfaces.FailureReporter); ok {
+			failureReporter.ReportFailure(folder, err)
+		}
+	}
+}
+
+// Released forwards the hold of the first reporter that holds the run, e.g. a paused TUI
+// This method implements the Hold interface, so wrapping a TUI doesn't lose its pause key
+func (mr *MultiReporter) Released() <-chan struct{} {
+	for _, r := range mr.reporters {
+		if hold, ok := r.(interfaces.Hold); ok {
+			if released := hold.Released(); released != nil {
+				return released
+			}
+		}
+	}
+	return nil
+}
+
+// Closed returns the channel of the first reporter the user can close, or nil if there is none
+func (mr *MultiReporter) Closed() <-chan struct{} {
+	for _, r := range mr.reporters {
+		if closable, ok := r.(interface{ Closed() <-chan struct{} }); ok {
+			if closed := closable.Closed(); closed != nil {
+				return closed
+			}
+		}
+	}
+	return nil
+}
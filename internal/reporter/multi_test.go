@@ -0,0 +1,43 @@
+package reporter_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/reporter"
+)
+
+// holdingReporter is a reporter the user can pause and close, like the TUI
+type holdingReporter struct {
+	released chan struct{}
+	closed   chan struct{}
+}
+
+func (holdingReporter) ReportProgress(current, total int, message string)   {}
+func (holdingReporter) ReportError(err error)                               {}
+func (holdingReporter) ReportComplete(summary interfaces.ProcessingSummary) {}
+func (h holdingReporter) Released() <-chan struct{}                         { return h.released }
+func (h holdingReporter) Closed() <-chan struct{}                           { return h.closed }
+
+// TestMultiReporter_ForwardsHoldAndClose tests that wrapping a TUI-like reporter keeps its pause and quit channels
+func TestMultiReporter_ForwardsHoldAndClose(t *testing.T) {
+	cli := reporter.NewCLIReporter(reporter.VerbosityWarn, true, nil, reporter.WithOutput(io.Discard))
+	holding := holdingReporter{released: make(chan struct{}), closed: make(chan struct{})}
+
+	// Nested like --progress-fd inside --log-file
+	multi := reporter.NewMultiReporter(cli, reporter.NewMultiReporter(cli, holding))
+	var hold interfaces.Hold = multi
+	if hold.Released() != (<-chan struct{})(holding.released) {
+		t.Error("Expected the hold of the wrapped reporter to be forwarded")
+	}
+	if multi.Closed() != (<-chan struct{})(holding.closed) {
+		t.Error("Expected the closed channel of the wrapped reporter to be forwarded")
+	}
+
+	// Running and unclosable reporters forward nothing
+	plain := reporter.NewMultiReporter(cli, holdingReporter{})
+	if plain.Released() != nil || plain.Closed() != nil {
+		t.Error("Expected nil channels without a held or closable reporter")
+	}
+}
@@ -0,0 +1,22 @@
+package reporter
+
+import (
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// nopReporter implements the ProgressReporter interface by discarding every event
+type nopReporter struct{}
+
+// NewNopReporter creates a reporter for callers that need one but don't show progress, e.g. around recorders in tests
+func NewNopReporter() interfaces.ProgressReporter {
+	return nopReporter{}
+}
+
+// ReportProgress discards progress updates
+func (nopReporter) ReportProgress(current, total int, message string) {}
+
+// ReportError discards errors
+func (nopReporter) ReportError(err error) {}
+
+// ReportComplete discards the summary
+func (nopReporter) ReportComplete(summary interfaces.ProcessingSummary) {}
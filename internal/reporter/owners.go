@@ -0,0 +1,81 @@
+package reporter
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/punkscience/sanitize/internal/collation"
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// summaryDirectoryLimit is how many top-level directories the summary lists; the JSON summary has all of them
+const summaryDirectoryLimit = 10
+
+// groupLines formats the statistics of a summary breakdown, e.g. per owner, groups with the most affected folders first
+// Ties are broken by group name in collation order so the output is stable between runs; limit > 0 lists only that many groups
+func groupLines(groups map[string]interfaces.GroupStats, renamedVerb string, limit int, collator *collation.Collator) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		a, b := groups[names[i]], groups[names[j]]
+		if totalA, totalB := a.RenamedCount+a.ErrorCount, b.RenamedCount+b.ErrorCount; totalA != totalB {
+			return totalA > totalB
+		}
+		return collator.Compare(names[i], names[j]) < 0
+	})
+
+	lines := make([]string, 0, len(names))
+	for i, name := range names {
+		if limit > 0 && i == limit {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(names)-limit))
+			break
+		}
+		stats := groups[name]
+		lines = append(lines, fmt.Sprintf("%s: %d %s, %d errors", name, stats.RenamedCount, renamedVerb, stats.ErrorCount))
+	}
+
+	return lines
+}
+
+// breakdown is one section of the summary that breaks renames and errors down by group
+type breakdown struct {
+	title string // Heading of the section, e.g. "By rule"
+	label string // Prefix of each line in accessible output, e.g. "Rule"
+	lines []string
+}
+
+// breakdowns returns the non-empty breakdowns of a summary: by owner, by rule and by top-level directory
+func breakdowns(summary interfaces.ProcessingSummary, dryRun bool, collator *collation.Collator) []breakdown {
+	verb := renamedVerb(dryRun)
+	sections := []breakdown{
+		{title: "By owner", label: "Owner", lines: groupLines(summary.Owners, verb, 0, collator)},
+		{title: "By rule", label: "Rule", lines: groupLines(summary.Rules, verb, 0, collator)},
+		{title: "By top-level directory", label: "Directory", lines: groupLines(summary.Directories, verb, summaryDirectoryLimit, collator)},
+	}
+	return slices.DeleteFunc(sections, func(section breakdown) bool { return len(section.lines) == 0 })
+}
+
+// renamedVerb describes renames in summaries, which only happen hypothetically in dry runs
+func renamedVerb(dryRun bool) string {
+	if dryRun {
+		return "to rename"
+	}
+	return "renamed"
+}
+
+// verificationHeadline summarizes the re-check of the applied renames for the run summary
+func verificationHeadline(verification *interfaces.Verification) string {
+	if len(verification.Discrepancies) == 0 {
+		return fmt.Sprintf("Verified %d renames: all in place", verification.Checked)
+	}
+	return fmt.Sprintf("Verified %d renames: %d no longer in place", verification.Checked, len(verification.Discrepancies))
+}
+
+// lockedHeadline introduces the folders other applications kept open until the end of the run
+func lockedHeadline(count int) string {
+	return fmt.Sprintf("%d folders are in use by other applications; close them (e.g. Explorer windows, editors) and run again", count)
+}
@@ -0,0 +1,139 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Progress rendering intervals
+const (
+	barRedrawInterval = 100 * time.Millisecond // A terminal bar is redrawn at most this often
+	progressLogPeriod = 30 * time.Second       // Without a terminal, a progress line is logged this often
+	progressBarWidth  = 30
+	scanPathWidth     = 50 // Longer paths in the scanning line are cut at the front
+)
+
+// progressMeter shows how far a run is, with throughput and the estimated time left
+// On a terminal it redraws a single bar line; otherwise it logs a line every progressLogPeriod, so short runs stay silent
+type progressMeter struct {
+	out         io.Writer
+	interactive bool
+	now         func() time.Time
+
+	start    time.Time
+	rendered time.Time // When progress was last drawn or logged
+	current  int
+	total    int
+	line     string // Line redrawn in place on screen (empty = none shown)
+}
+
+// newProgressMeter creates a meter writing to out; interactive selects the redrawn bar
+func newProgressMeter(out io.Writer, interactive bool) *progressMeter {
+	return &progressMeter{
+		out:         out,
+		interactive: interactive,
+		now:         time.Now,
+	}
+}
+
+// update records the progress and draws it when the interval has passed
+// A message that comes without progress, e.g. why the run pauses, is printed as a line of its own
+func (m *progressMeter) update(current, total int, message string) {
+	now := m.now()
+	if m.start.IsZero() {
+		m.start, m.rendered = now, now
+	}
+	if current == m.current && total == m.total && message != "" {
+		m.printLine(message)
+		return
+	}
+	m.current, m.total = current, total
+
+	switch {
+	case total <= 0:
+	case m.interactive && (now.Sub(m.rendered) >= barRedrawInterval || current == total):
+		m.rendered = now
+		m.draw()
+	case !m.interactive && now.Sub(m.rendered) >= progressLogPeriod:
+		m.rendered = now
+		fmt.Fprintf(m.out, "Progress: %s\n", m.status(now))
+	}
+}
+
+// scan shows how many directories the walk found so far, while the total is still unknown
+// Like the bar, the count is redrawn on a terminal and logged every progressLogPeriod otherwise, so short walks stay silent
+func (m *progressMeter) scan(scanned int, path string) {
+	now := m.now()
+	if m.rendered.IsZero() {
+		m.rendered = now
+		return
+	}
+
+	switch {
+	case m.interactive && now.Sub(m.rendered) >= barRedrawInterval:
+		m.rendered = now
+		m.drawLine(fmt.Sprintf("Scanning: %d directories found, in %s", scanned, truncateLeft(path, scanPathWidth)))
+	case !m.interactive && now.Sub(m.rendered) >= progressLogPeriod:
+		m.rendered = now
+		fmt.Fprintf(m.out, "Scanning: %d directories found so far, now in %s\n", scanned, path)
+	}
+}
+
+// printLine writes a line of output, keeping the bar below it
+func (m *progressMeter) printLine(line string) {
+	shown := m.line
+	m.clear()
+	fmt.Fprintln(m.out, line)
+	if shown != "" {
+		m.drawLine(shown)
+	}
+}
+
+// draw redraws the bar line in place
+func (m *progressMeter) draw() {
+	filled := progressBarWidth * m.current / m.total
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+	m.drawLine(fmt.Sprintf("[%s] %s", bar, m.status(m.now())))
+}
+
+// drawLine replaces the line shown in place
+func (m *progressMeter) drawLine(line string) {
+	padding := ""
+	if len(line) < len(m.line) {
+		padding = strings.Repeat(" ", len(m.line)-len(line)) // Overwrite the rest of a longer previous line
+	}
+	fmt.Fprintf(m.out, "\r%s%s", line, padding)
+	m.line = line
+}
+
+// clear removes the bar so other output starts on an empty line
+// Spaces are used instead of an erase sequence, which legacy Windows consoles don't understand
+func (m *progressMeter) clear() {
+	if m.line == "" {
+		return
+	}
+	fmt.Fprintf(m.out, "\r%s\r", strings.Repeat(" ", len(m.line)))
+	m.line = ""
+}
+
+// status formats the percentage, count, throughput and estimated time left
+func (m *progressMeter) status(now time.Time) string {
+	status := fmt.Sprintf("%3d%% %d/%d", 100*m.current/m.total, m.current, m.total)
+
+	elapsed := now.Sub(m.start).Seconds()
+	if elapsed <= 0 || m.current == 0 {
+		return status
+	}
+	rate := float64(m.current) / elapsed
+	status += fmt.Sprintf(" %.1f folders/s", rate)
+	if m.current < m.total {
+		eta := time.Duration(float64(m.total-m.current) / rate * float64(time.Second))
+		status += " ETA " + eta.Round(time.Second).String()
+	}
+	return status
+}
@@ -3,36 +3,216 @@
 package reporter
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
-	"sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/collation"
+	"github.com/punkscience/sanitize/internal/interfaces"
 )
 
+// Heights of the result list of renames and errors
+const (
+	tuiListHeight    = 8 // Rows shown until the terminal reports its size
+	tuiMinListHeight = 3 // Rows shown however much else the screen holds
+)
+
+// tuiScrollHint names the keys that scroll and search the result list
+const tuiScrollHint = "Up/Down, PgUp/PgDn, Home/End to scroll, / to search, w to save"
+
+// tuiRegexPrefix marks a search query as a case-insensitive regular expression, like in reserved-word files
+const tuiRegexPrefix = "re:"
+
 // TUIReporter implements the ProgressReporter interface using Bubble Tea
 // This struct provides an interactive terminal UI for progress reporting
 type TUIReporter struct {
-	program  *tea.Program
-	model    *tuiModel
-	complete chan interfaces.ProcessingSummary
-	dryRun   bool
+	program *tea.Program
+	model   *tuiModel
+	done    chan struct{} // Closed once the program has exited and restored the terminal
+	dryRun  bool
 }
 
 // tuiModel represents the Bubble Tea model for the TUI
 // This struct maintains the state of the interactive display
 type tuiModel struct {
-	current     int
-	total       int
-	message     string
-	errors      []string
-	complete    bool
-	summary     interfaces.ProcessingSummary
-	dryRun      bool
-	showErrors  bool
-	windowWidth int
+	current      int
+	total        int
+	message      string
+	scanned      int    // Directories the walk found before processing started
+	scanPath     string // Directory the walk read last
+	errors       []string
+	warnings     []string
+	results      []resultEntry          // Every rename and error of the run, in order
+	shown        []resultEntry          // The results the search query matches; all of them without a query
+	searching    bool                   // Keys edit the search query until enter or esc
+	query        string                 // Case-insensitive substring, or a regular expression after re:
+	queryErr     string                 // Why the query is not a valid regular expression
+	matches      func(text string) bool // Filter compiled from the query (nil = show every result)
+	scroll       int                    // Index of the first shown result in the list
+	follow       bool                   // The list scrolls along with new results until the user scrolls up
+	listHeight   int                    // Rows fitList gave the list on the current screen; paging scrolls by this much
+	complete     bool
+	summary      interfaces.ProcessingSummary
+	dryRun       bool
+	showErrors   bool
+	windowWidth  int
+	windowHeight int // 0 until the terminal reports its size
+	glyphs       tuiGlyphs
+	collator     *collation.Collator
+	hold         *pauseSwitch     // Space pauses the run between folders
+	saved        string           // Outcome of the last save of the results, shown until the next one
+	now          func() time.Time // Clock for the names of saved result files
+}
+
+// pauseSwitch holds the run between folders while the user has paused it
+// The model toggles it on the UI goroutine while the service reads it between folders, so it is guarded by a mutex
+type pauseSwitch struct {
+	mu       sync.Mutex
+	released chan struct{} // Closed on resume; nil while the run is not paused
+}
+
+// toggle pauses a running run or resumes a paused one and reports whether it is paused now
+func (p *pauseSwitch) toggle() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.released == nil {
+		p.released = make(chan struct{})
+		return true
+	}
+	close(p.released)
+	p.released = nil
+	return false
+}
+
+// resume lets a paused run continue; it does nothing when the run is not paused
+func (p *pauseSwitch) resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.released != nil {
+		close(p.released)
+		p.released = nil
+	}
+}
+
+// paused reports whether the user paused the run
+func (p *pauseSwitch) paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.released != nil
+}
+
+// Released returns the channel that is closed when the user resumes the run, or nil while it is not paused
+func (p *pauseSwitch) Released() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.released
+}
+
+// resultStatus is the outcome of an entry in the result list
+type resultStatus int
+
+// Outcomes shown in the result list
+const (
+	resultRenamed resultStatus = iota
+	resultPlanned              // A dry run would rename the folder
+	resultFailed
+)
+
+// resultEntry is one line of the result list
+type resultEntry struct {
+	text   string
+	status resultStatus // Errors are shown in red between the renames
+}
+
+// tuiGlyphs holds the decorations used by the TUI display
+// This struct allows switching between emoji/box-drawing and plain ASCII without changing the layout
+type tuiGlyphs struct {
+	title     string // Title prefix
+	complete  string // Completion header prefix
+	found     string // Total folders prefix
+	processed string // Processed folders prefix
+	renamed   string // Renamed folders prefix
+	skipped   string // Skipped folders prefix
+	errors    string // Error line prefix
+	elapsed   string // Elapsed time prefix
+	runID     string // Run ID prefix
+	hint      string // Dry-run hint prefix
+	success   string // Success message prefix
+	allGood   string // Already-compatible message prefix
+	warning   string // In-progress error count prefix
+	paused    string // Paused notice prefix
+	bullet    string // Error detail bullet
+	arrow     string // Separates old and new names in the result list
+	done      string // Status of an applied rename in the result list
+	planned   string // Status of a rename a dry run would perform
+	failed    string // Status of an error in the result list
+	barFilled string // Filled progress bar cell
+	barEmpty  string // Empty progress bar cell
+	barLeft   string // Progress bar left edge
+	barRight  string // Progress bar right edge
+}
+
+// unicodeGlyphs are the default emoji and box-drawing decorations
+var unicodeGlyphs = tuiGlyphs{
+	title:     "🔧 ",
+	complete:  "✅ ",
+	found:     "📁 ",
+	processed: "⚡ ",
+	renamed:   "✏️  ",
+	skipped:   "⏭️  ",
+	errors:    "❌ ",
+	elapsed:   "⏱️  ",
+	runID:     "🆔 ",
+	hint:      "💡 ",
+	success:   "🎉 ",
+	allGood:   "✨ ",
+	warning:   "⚠️  ",
+	paused:    "⏸️  ",
+	bullet:    "• ",
+	arrow:     " → ",
+	done:      "✓ ",
+	planned:   "○ ",
+	failed:    "✗ ",
+	barFilled: "█",
+	barEmpty:  "░",
+	barLeft:   "▕",
+	barRight:  "▏",
+}
+
+// asciiGlyphs are plain ASCII decorations for legacy consoles and log aggregators
+var asciiGlyphs = tuiGlyphs{
+	title:     "",
+	complete:  "[DONE] ",
+	found:     "- ",
+	processed: "- ",
+	renamed:   "- ",
+	skipped:   "- ",
+	errors:    "[!] ",
+	elapsed:   "- ",
+	runID:     "- ",
+	hint:      "Tip: ",
+	success:   "",
+	allGood:   "",
+	warning:   "[!] ",
+	paused:    "[PAUSED] ",
+	bullet:    "* ",
+	arrow:     " -> ",
+	done:      "+ ",
+	planned:   "~ ",
+	failed:    "x ",
+	barFilled: "#",
+	barEmpty:  "-",
+	barLeft:   "[",
+	barRight:  "]",
 }
 
 // progressMsg represents a progress update message
@@ -42,63 +222,134 @@ type progressMsg struct {
 	message string
 }
 
+// scanMsg represents discovery progress while the tree is walked
+type scanMsg struct {
+	scanned int
+	path    string
+}
+
 // errorMsg represents an error message
 type errorMsg struct {
 	err error
 }
 
+// renameMsg represents a completed (or, in a dry run, planned) rename
+type renameMsg struct {
+	result interfaces.RenameResult
+}
+
+// warningMsg represents a problem that did not stop the run
+type warningMsg struct {
+	warning error
+}
+
 // completeMsg represents completion with summary
 type completeMsg struct {
 	summary interfaces.ProcessingSummary
 }
 
-// NewTUIReporter creates a new TUI progress reporter using Bubble Tea
-// This constructor initializes the interactive terminal interface
-func NewTUIReporter(dryRun bool) interfaces.ProgressReporter {
-	model := &tuiModel{
-		dryRun:      dryRun,
-		errors:      make([]string, 0),
-		windowWidth: 80, // Default width
+// NewTUIReporter creates a new TUI progress reporter using Bubble Tea and starts it in its own goroutine
+// This constructor initializes the interactive terminal interface; asciiOutput replaces emoji and box-drawing with plain ASCII
+// and collator orders names in the summary. Close the reporter to restore the terminal if the run ends without a summary
+func NewTUIReporter(dryRun, asciiOutput bool, collator *collation.Collator) *TUIReporter {
+	glyphs := unicodeGlyphs
+	if asciiOutput {
+		glyphs = asciiGlyphs
+	}
+
+	model := newTUIModel(dryRun, glyphs, collator)
+	tr := &TUIReporter{
+		program: tea.NewProgram(model, tea.WithAltScreen()),
+		model:   model,
+		done:    make(chan struct{}),
+		dryRun:  dryRun,
 	}
 
-	program := tea.NewProgram(model, tea.WithAltScreen())
+	// The program reads keys and redraws while the service runs; events reach it through Send
+	go func() {
+		defer close(tr.done)
+		if _, err := tr.program.Run(); err != nil {
+			fmt.Fprintf(os.Stderr, "Terminal UI failed: %v\n", err)
+		}
+	}()
+
+	return tr
+}
 
-	return &TUIReporter{
-		program:  program,
-		model:    model,
-		complete: make(chan interfaces.ProcessingSummary),
-		dryRun:   dryRun,
+// newTUIModel creates the state of a TUI that follows new results and uses the system clock
+func newTUIModel(dryRun bool, glyphs tuiGlyphs, collator *collation.Collator) *tuiModel {
+	return &tuiModel{
+		dryRun:      dryRun,
+		errors:      make([]string, 0),
+		follow:      true,
+		listHeight:  tuiListHeight,
+		windowWidth: 80, // Default width
+		glyphs:      glyphs,
+		collator:    collator,
+		hold:        &pauseSwitch{},
+		now:         time.Now,
 	}
 }
 
 // ReportProgress sends progress updates to the TUI
 // This method updates the progress display in real-time
 func (tr *TUIReporter) ReportProgress(current, total int, message string) {
-	if tr.program != nil {
-		tr.program.Send(progressMsg{
-			current: current,
-			total:   total,
-			message: message,
-		})
-	}
+	tr.program.Send(progressMsg{
+		current: current,
+		total:   total,
+		message: message,
+	})
+}
+
+// ReportScan sends discovery progress to the TUI, which shows it until processing starts
+// This method implements the ScanReporter interface
+func (tr *TUIReporter) ReportScan(scanned int, path string) {
+	tr.program.Send(scanMsg{scanned: scanned, path: path})
 }
 
 // ReportError sends error information to the TUI
 // This method adds errors to the display list
 func (tr *TUIReporter) ReportError(err error) {
-	if tr.program != nil {
-		tr.program.Send(errorMsg{err: err})
-	}
+	tr.program.Send(errorMsg{err: err})
 }
 
+// ReportRename sends a completed rename to the result list
+// This method implements the RenameReporter interface
+func (tr *TUIReporter) ReportRename(result interfaces.RenameResult) {
+	tr.program.Send(renameMsg{result: result})
+}
+
+// ReportWarning sends a problem that did not stop the run to the TUI
+// This method implements the WarningReporter interface
+func (tr *TUIReporter) ReportWarning(warning error) {
+	tr.program.Send(warningMsg{warning: warning})
+}
+
+// Released returns the channel that is closed when the user resumes a run paused with space, or nil while it runs
+// This method implements the Hold interface
+func (tr *TUIReporter) Released() <-chan struct{} {
+	return tr.model.hold.Released()
+}
+
+// Ensure TUIReporter lets the user hold the run
+var _ interfaces.Hold = (*TUIReporter)(nil)
+
 // ReportComplete signals completion and shows the summary
-// This method finalizes the TUI display with results
+// This method blocks until the user quits, so the summary and the results can be reviewed first
 func (tr *TUIReporter) ReportComplete(summary interfaces.ProcessingSummary) {
-	if tr.program != nil {
-		tr.program.Send(completeMsg{summary: summary})
-		// Give the program a moment to process the message
-		tr.program.Quit()
-	}
+	tr.program.Send(completeMsg{summary: summary})
+	<-tr.done
+}
+
+// Closed returns a channel that is closed once the user quit the TUI, so a run can stop when nobody watches it anymore
+func (tr *TUIReporter) Closed() <-chan struct{} {
+	return tr.done
+}
+
+// Close quits the TUI unless the user already did and waits until the terminal is restored
+func (tr *TUIReporter) Close() {
+	tr.program.Quit()
+	<-tr.done
 }
 
 // Bubble Tea Model Methods
@@ -109,108 +360,234 @@ func (m *tuiModel) Init() tea.Cmd {
 }
 
 // Update handles Bubble Tea messages and updates the model
+// The result list is fitted to the new layout afterwards, so View only draws
 func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	cmd := m.update(msg)
+	m.fitList()
+	return m, cmd
+}
+
+// update applies a single message to the model and returns the command to run next
+func (m *tuiModel) update(msg tea.Msg) tea.Cmd {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.windowWidth = msg.Width
-		return m, nil
+		m.windowHeight = msg.Height
+		return nil
 
 	case progressMsg:
 		m.current = msg.current
 		m.total = msg.total
 		m.message = msg.message
-		return m, nil
+		return nil
+
+	case scanMsg:
+		m.scanned = msg.scanned
+		m.scanPath = msg.path
+		return nil
 
 	case errorMsg:
 		m.errors = append(m.errors, msg.err.Error())
-		return m, nil
+		m.addResult(resultEntry{text: msg.err.Error(), status: resultFailed})
+		return nil
+
+	case renameMsg:
+		status := resultRenamed
+		if m.dryRun {
+			status = resultPlanned
+		}
+		m.addResult(resultEntry{text: m.renameLine(msg.result), status: status})
+		return nil
+
+	case warningMsg:
+		m.warnings = append(m.warnings, msg.warning.Error())
+		return nil
 
 	case completeMsg:
 		m.complete = true
 		m.summary = msg.summary
-		return m, tea.Quit
+		return nil
 
 	case tea.KeyMsg:
+		if m.searching && msg.String() != "ctrl+c" {
+			m.editQuery(msg)
+			return nil
+		}
 		switch msg.String() {
 		case "q", "ctrl+c":
-			return m, tea.Quit
+			m.hold.resume() // A paused run must not wait for a TUI that is gone
+			return tea.Quit
+		case " ":
+			if !m.complete {
+				m.hold.toggle()
+			}
 		case "e":
 			m.showErrors = !m.showErrors
-			return m, nil
+			return nil
+		case "w":
+			if path, err := m.saveResults(); err != nil {
+				m.saved = fmt.Sprintf("Could not save the results: %v", err)
+			} else {
+				m.saved = "Saved the results to " + path
+			}
+		case "up", "k":
+			m.scrollBy(-1)
+		case "down", "j":
+			m.scrollBy(1)
+		case "pgup", "b":
+			m.scrollBy(-m.listHeight)
+		case "pgdown", "f":
+			m.scrollBy(m.listHeight)
+		case "home", "g":
+			m.scroll, m.follow = 0, false
+		case "end", "G":
+			m.follow = true
+		case "/":
+			m.searching = true
+		case "esc":
+			m.setQuery("")
 		}
 	}
 
-	return m, nil
+	return nil
 }
 
-// View renders the TUI display
-func (m *tuiModel) View() string {
-	var b strings.Builder
-
-	// Styles
-	titleStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("15")).
-		Background(lipgloss.Color("63")).
-		Padding(0, 1)
-
-	headerStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("39"))
+// tuiStyles are the lipgloss styles of the TUI
+type tuiStyles struct {
+	title, header, progress, error, info lipgloss.Style
+}
 
-	progressStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("40"))
+// newTUIStyles creates the styles of the TUI
+func newTUIStyles() tuiStyles {
+	return tuiStyles{
+		title: lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("15")).
+			Background(lipgloss.Color("63")).
+			Padding(0, 1),
+		header:   lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")),
+		progress: lipgloss.NewStyle().Foreground(lipgloss.Color("40")),
+		error:    lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+		info:     lipgloss.NewStyle().Foreground(lipgloss.Color("245")),
+	}
+}
 
-	errorStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("196"))
+// View renders the TUI display
+func (m *tuiModel) View() string {
+	styles := newTUIStyles()
+	head, footer := m.render(styles)
+	if len(m.results) > 0 {
+		head += "\n" + m.renderResults(styles)
+	}
+	return head + footer
+}
 
-	infoStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("245"))
+// render draws everything but the result list, which goes between the two parts
+func (m *tuiModel) render(styles tuiStyles) (string, string) {
+	var b, footer strings.Builder
 
 	// Title
-	title := "🔧 Folder Name Sanitizer"
+	title := m.glyphs.title + "Folder Name Sanitizer"
 	if m.dryRun {
 		title += " (DRY RUN)"
 	}
-	b.WriteString(titleStyle.Render(title))
+	b.WriteString(styles.title.Render(title))
 	b.WriteString("\n\n")
 
 	if m.complete {
 		// Show completion summary
-		b.WriteString(headerStyle.Render("✅ Processing Complete"))
+		b.WriteString(styles.header.Render(m.glyphs.complete + "Processing Complete"))
 		b.WriteString("\n\n")
 
-		b.WriteString(fmt.Sprintf("📁 Total folders found: %d\n", m.summary.TotalFolders))
-		b.WriteString(fmt.Sprintf("⚡ Folders processed: %d\n", m.summary.ProcessedCount))
-		b.WriteString(fmt.Sprintf("✏️  Folders renamed: %d\n", m.summary.RenamedCount))
-		b.WriteString(fmt.Sprintf("⏭️  Folders skipped: %d\n", m.summary.SkippedCount))
+		b.WriteString(fmt.Sprintf("%sTotal folders found: %d\n", m.glyphs.found, m.summary.TotalFolders))
+		b.WriteString(fmt.Sprintf("%sFolders processed: %d\n", m.glyphs.processed, m.summary.ProcessedCount))
+		b.WriteString(fmt.Sprintf("%sRenames planned: %d\n", m.glyphs.renamed, m.summary.Phases.Planned))
+		if m.dryRun {
+			b.WriteString(fmt.Sprintf("   Would rename: %d\n", m.summary.Phases.WouldRename))
+		} else {
+			b.WriteString(fmt.Sprintf("   Applied: %d\n", m.summary.Phases.Applied))
+		}
+		b.WriteString(fmt.Sprintf("   Deferred: %d\n", m.summary.Phases.Deferred))
+		failed := fmt.Sprintf("   Failed: %d", m.summary.Phases.Failed)
+		if m.summary.Phases.Failed > 0 {
+			failed = styles.error.Render(failed)
+		}
+		b.WriteString(failed + "\n")
+		if m.summary.Phases.Vanished > 0 {
+			b.WriteString(fmt.Sprintf("   Vanished: %d\n", m.summary.Phases.Vanished))
+		}
+		b.WriteString(fmt.Sprintf("%sAlready compliant: %d\n", m.glyphs.skipped, m.summary.Phases.Skipped))
+
+		if m.summary.WarningCount > 0 {
+			b.WriteString(fmt.Sprintf("%sWarnings: %d\n", m.glyphs.warning, m.summary.WarningCount))
+		}
+
+		b.WriteString(fmt.Sprintf("%sTime elapsed: %s\n", m.glyphs.elapsed, m.summary.ElapsedTime))
+		if m.summary.RunID != "" {
+			b.WriteString(fmt.Sprintf("%sRun ID: %s\n", m.glyphs.runID, m.summary.RunID))
+		}
 
-		if m.summary.ErrorCount > 0 {
-			b.WriteString(errorStyle.Render(fmt.Sprintf("❌ Errors encountered: %d", m.summary.ErrorCount)))
+		if m.summary.Aborted {
+			b.WriteString(styles.error.Render(fmt.Sprintf("%sRun aborted early: %s", m.glyphs.errors, m.summary.AbortReason)))
 			b.WriteString("\n")
+			if m.summary.RemainingCount > 0 {
+				b.WriteString(fmt.Sprintf("%d folders were not processed.\n", m.summary.RemainingCount))
+			}
 		}
 
-		b.WriteString(fmt.Sprintf("⏱️  Time elapsed: %s\n", m.summary.ElapsedTime))
+		if verification := m.summary.Verification; verification != nil {
+			b.WriteString("\n")
+			headline := verificationHeadline(verification)
+			if len(verification.Discrepancies) > 0 {
+				headline = styles.error.Render(headline)
+			}
+			b.WriteString(headline + "\n")
+			for _, discrepancy := range verification.Discrepancies {
+				b.WriteString("  " + discrepancy + "\n")
+			}
+		}
 
-		if m.summary.RenamedCount > 0 {
-			if m.dryRun {
-				b.WriteString("\n")
-				b.WriteString(infoStyle.Render(fmt.Sprintf("💡 %d folders would be renamed. Run without --dry-run to apply changes.", m.summary.RenamedCount)))
-			} else {
-				b.WriteString("\n")
-				b.WriteString(progressStyle.Render(fmt.Sprintf("🎉 Successfully sanitized %d folder names!", m.summary.RenamedCount)))
+		if len(m.summary.Locked) > 0 {
+			b.WriteString("\n")
+			b.WriteString(styles.error.Render(lockedHeadline(len(m.summary.Locked))))
+			b.WriteString("\n")
+			for _, path := range m.summary.Locked {
+				b.WriteString("  " + path + "\n")
 			}
-		} else if m.summary.TotalFolders > 0 {
+		}
+
+		for _, section := range breakdowns(m.summary, m.dryRun, m.collator) {
+			b.WriteString("\n")
+			b.WriteString(styles.header.Render(section.title))
 			b.WriteString("\n")
-			b.WriteString(infoStyle.Render("✨ All folder names are already compatible."))
+			for _, line := range section.lines {
+				b.WriteString("  " + line + "\n")
+			}
 		}
 
-		if len(m.errors) > 0 {
-			b.WriteString("\n\n")
-			b.WriteString(infoStyle.Render("Press 'e' to toggle error details, 'q' to quit"))
+		switch phases := m.summary.Phases; {
+		case m.dryRun && phases.WouldRename+phases.Deferred > 0:
+			b.WriteString("\n")
+			b.WriteString(styles.info.Render(fmt.Sprintf("%s%d folders would be renamed. Run without --dry-run to apply changes.", m.glyphs.hint, phases.WouldRename+phases.Deferred)))
+		case phases.Deferred > 0:
+			b.WriteString("\n")
+			b.WriteString(styles.info.Render(fmt.Sprintf("%sSanitized %d folder names; %d planned renames were deferred. Run again to apply them.", m.glyphs.hint, phases.Applied, phases.Deferred)))
+		case phases.Applied > 0:
+			b.WriteString("\n")
+			b.WriteString(styles.progress.Render(fmt.Sprintf("%sSuccessfully sanitized %d folder names!", m.glyphs.success, phases.Applied)))
+		case phases.Planned == 0 && m.summary.TotalFolders > 0:
+			b.WriteString("\n")
+			b.WriteString(styles.info.Render(m.glyphs.allGood + "All folder names are already compatible."))
+		}
+
+		b.WriteString("\n")
+
+		if len(m.errors) > 0 || len(m.warnings) > 0 {
+			footer.WriteString("\n")
+			footer.WriteString(styles.info.Render("Press 'e' to toggle error details, " + tuiScrollHint + ", 'q' to quit"))
 		} else {
-			b.WriteString("\n\n")
-			b.WriteString(infoStyle.Render("Press 'q' to quit"))
+			footer.WriteString("\n")
+			footer.WriteString(styles.info.Render("Press " + tuiScrollHint + ", 'q' to quit"))
 		}
 
 	} else {
@@ -219,47 +596,299 @@ func (m *tuiModel) View() string {
 			percentage := float64(m.current) / float64(m.total) * 100
 			progressBar := m.createProgressBar(percentage)
 
-			b.WriteString(headerStyle.Render("Processing Folders"))
+			b.WriteString(styles.header.Render("Processing Folders"))
 			b.WriteString("\n\n")
-			b.WriteString(progressStyle.Render(progressBar))
+			b.WriteString(styles.progress.Render(progressBar))
 			b.WriteString("\n")
 			b.WriteString(fmt.Sprintf("Progress: %d/%d (%.1f%%)", m.current, m.total, percentage))
 			b.WriteString("\n\n")
+		} else if m.scanned > 0 {
+			b.WriteString(styles.header.Render("Scanning Folders"))
+			b.WriteString("\n\n")
+			b.WriteString(fmt.Sprintf("Found: %d directories", m.scanned))
+			b.WriteString("\n")
+			b.WriteString("Reading: ")
+			b.WriteString(styles.info.Render(truncateLeft(m.scanPath, m.windowWidth-len("Reading: "))))
+			b.WriteString("\n\n")
 		}
 
 		if m.message != "" {
 			b.WriteString("Current: ")
-			b.WriteString(infoStyle.Render(m.message))
+			b.WriteString(styles.info.Render(m.message))
+			b.WriteString("\n")
+		}
+		if m.hold.paused() {
+			b.WriteString(styles.header.Render(m.glyphs.paused + "Paused after the current folder. Press space to resume."))
 			b.WriteString("\n")
 		}
 
 		if len(m.errors) > 0 {
-			b.WriteString("\n")
-			b.WriteString(errorStyle.Render(fmt.Sprintf("⚠️  %d errors encountered", len(m.errors))))
+			footer.WriteString("\n")
+			footer.WriteString(styles.error.Render(fmt.Sprintf("%s%d errors encountered", m.glyphs.warning, len(m.errors))))
+		}
+		if len(m.warnings) > 0 {
+			footer.WriteString("\n")
+			footer.WriteString(styles.info.Render(fmt.Sprintf("%d warnings", len(m.warnings))))
 		}
 
-		b.WriteString("\n\n")
-		b.WriteString(infoStyle.Render("Press 'q' to quit"))
+		footer.WriteString("\n\n")
+		footer.WriteString(styles.info.Render("Press space to pause, " + tuiScrollHint + ", 'q' to quit"))
+	}
+
+	if m.saved != "" {
+		footer.WriteString("\n")
+		footer.WriteString(styles.info.Render(m.saved))
 	}
 
-	// Show errors if requested
+	// Show errors and warnings if requested
 	if m.showErrors && len(m.errors) > 0 {
-		b.WriteString("\n\n")
-		b.WriteString(headerStyle.Render("Error Details:"))
-		b.WriteString("\n")
+		footer.WriteString("\n\n")
+		footer.WriteString(styles.header.Render("Error Details:"))
+		footer.WriteString("\n")
 		for i, err := range m.errors {
 			if i >= 10 { // Limit to 10 errors to avoid overwhelming the display
-				b.WriteString(errorStyle.Render(fmt.Sprintf("... and %d more errors", len(m.errors)-10)))
+				footer.WriteString(styles.error.Render(fmt.Sprintf("... and %d more errors", len(m.errors)-10)))
 				break
 			}
-			b.WriteString(errorStyle.Render(fmt.Sprintf("• %s", err)))
-			b.WriteString("\n")
+			footer.WriteString(styles.error.Render(fmt.Sprintf("%s%s", m.glyphs.bullet, err)))
+			footer.WriteString("\n")
+		}
+	}
+	if m.showErrors && len(m.warnings) > 0 {
+		footer.WriteString("\n\n")
+		footer.WriteString(styles.header.Render("Warnings:"))
+		footer.WriteString("\n")
+		for i, warning := range m.warnings {
+			if i >= 10 { // Same limit as for errors
+				footer.WriteString(styles.info.Render(fmt.Sprintf("... and %d more warnings", len(m.warnings)-10)))
+				break
+			}
+			footer.WriteString(styles.info.Render(fmt.Sprintf("%s%s", m.glyphs.bullet, warning)))
+			footer.WriteString("\n")
+		}
+	}
+
+	return b.String(), footer.String()
+}
+
+// fitList gives the result list the rows the rest of the screen leaves free and keeps it at its end while it follows new results
+// The height is kept for paging
+func (m *tuiModel) fitList() {
+	height := tuiListHeight
+	if m.windowHeight > 0 {
+		head, footer := m.render(newTUIStyles())
+		used := strings.Count(head, "\n") + strings.Count(footer, "\n") + 3 // List header, blank line, last line
+		if m.searching || m.query != "" {
+			used++ // Search line
 		}
+		height = max(m.windowHeight-used, tuiMinListHeight)
 	}
+	m.listHeight = height
+
+	last := max(len(m.shown)-height, 0)
+	if m.follow || m.scroll > last {
+		m.scroll = last
+	}
+}
 
+// renderResults draws the visible part of the result list, as fitted by fitList, with a header naming the shown range
+func (m *tuiModel) renderResults(styles tuiStyles) string {
+	start := min(m.scroll, len(m.shown))
+	end := min(start+m.listHeight, len(m.shown))
+
+	var b strings.Builder
+	title := "Results"
+	if m.complete {
+		title = "All Results"
+	}
+	switch {
+	case m.matches != nil && len(m.shown) == 0:
+		title = fmt.Sprintf("No results match (%d in total)", len(m.results))
+	case m.matches != nil:
+		title = fmt.Sprintf("%s matching (%d-%d of %d, %d in total)", title, start+1, end, len(m.shown), len(m.results))
+	default:
+		title = fmt.Sprintf("%s (%d-%d of %d)", title, start+1, end, len(m.shown))
+	}
+	b.WriteString(styles.header.Render(title))
+	b.WriteString("\n")
+	switch {
+	case m.queryErr != "":
+		b.WriteString(styles.error.Render(fmt.Sprintf("/%s: %s", m.query, m.queryErr)) + "\n")
+	case m.searching:
+		b.WriteString("/" + m.query + "_\n")
+	case m.query != "":
+		b.WriteString(fmt.Sprintf("/%s (esc to clear)\n", m.query))
+	}
+	for _, entry := range m.shown[start:end] {
+		icon := m.statusIcon(entry.status)
+		line := icon + truncateLeft(entry.text, m.windowWidth-2-len([]rune(icon)))
+		if entry.status == resultFailed {
+			line = styles.error.Render(line)
+		}
+		b.WriteString("  " + line + "\n")
+	}
 	return b.String()
 }
 
+// statusIcon returns the glyph that marks the outcome of a result
+func (m *tuiModel) statusIcon(status resultStatus) string {
+	switch status {
+	case resultPlanned:
+		return m.glyphs.planned
+	case resultFailed:
+		return m.glyphs.failed
+	default:
+		return m.glyphs.done
+	}
+}
+
+// addResult appends a rename or error to the results and, if the search query matches it, to the list
+func (m *tuiModel) addResult(entry resultEntry) {
+	m.results = append(m.results, entry)
+	switch {
+	case m.matches == nil:
+		m.shown = m.results
+	case m.matches(entry.text):
+		m.shown = append(m.shown, entry)
+	}
+}
+
+// editQuery applies a key pressed while the search query is edited; the list is filtered as the user types
+func (m *tuiModel) editQuery(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.searching = false
+	case tea.KeyEsc:
+		m.searching = false
+		m.setQuery("")
+	case tea.KeyBackspace:
+		runes := []rune(m.query)
+		if len(runes) > 0 {
+			m.setQuery(string(runes[:len(runes)-1]))
+		}
+	case tea.KeySpace:
+		m.setQuery(m.query + " ")
+	case tea.KeyRunes:
+		m.setQuery(m.query + string(msg.Runes))
+	}
+}
+
+// setQuery filters the list by a new search query and shows its last match
+// An invalid regular expression keeps the previous filter, so the list does not flicker while it is typed
+func (m *tuiModel) setQuery(query string) {
+	m.query, m.queryErr = query, ""
+	m.follow = true
+
+	switch {
+	case query == "":
+		m.matches = nil
+	case strings.HasPrefix(query, tuiRegexPrefix):
+		pattern, err := regexp.Compile("(?i)" + strings.TrimPrefix(query, tuiRegexPrefix))
+		if err != nil {
+			m.queryErr = err.Error()
+			return
+		}
+		m.matches = pattern.MatchString
+	default:
+		lower := strings.ToLower(query)
+		m.matches = func(text string) bool { return strings.Contains(strings.ToLower(text), lower) }
+	}
+
+	if m.matches == nil {
+		m.shown = m.results
+		return
+	}
+	m.shown = make([]resultEntry, 0)
+	for _, entry := range m.results {
+		if m.matches(entry.text) {
+			m.shown = append(m.shown, entry)
+		}
+	}
+}
+
+// saveResults writes every error, warning and result of the run to a timestamped file in the working directory
+// Unlike the screen, the file is neither filtered nor cut off, so nothing is lost when the TUI closes
+func (m *tuiModel) saveResults() (string, error) {
+	now := m.now()
+
+	var b strings.Builder
+	mode := ""
+	if m.dryRun {
+		mode = " (dry run)"
+	}
+	fmt.Fprintf(&b, "Sanitize results%s, saved %s\n", mode, now.Format("2006-01-02 15:04:05"))
+	if m.summary.RunID != "" {
+		fmt.Fprintf(&b, "Run ID: %s\n", m.summary.RunID)
+	}
+	if !m.complete {
+		fmt.Fprintf(&b, "The run was still in progress: %d of %d folders processed\n", m.current, m.total)
+	}
+
+	fmt.Fprintf(&b, "\nErrors (%d):\n", len(m.errors))
+	for _, err := range m.errors {
+		b.WriteString("  " + err + "\n")
+	}
+	fmt.Fprintf(&b, "\nWarnings (%d):\n", len(m.warnings))
+	for _, warning := range m.warnings {
+		b.WriteString("  " + warning + "\n")
+	}
+	fmt.Fprintf(&b, "\nResults (%d):\n", len(m.results))
+	for _, entry := range m.results {
+		b.WriteString("  " + m.statusIcon(entry.status) + entry.text + "\n")
+	}
+
+	file, path, err := createResultsFile(now.Format("20060102-150405"))
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.WriteString(b.String()); err != nil {
+		file.Close()
+		return "", err
+	}
+	return path, file.Close()
+}
+
+// createResultsFile creates a new results file named after stamp in the working directory
+// Saves within the same second never overwrite each other; later ones get a counter, e.g. sanitize-results-20240301-142530-2.txt
+func createResultsFile(stamp string) (*os.File, string, error) {
+	for attempt := 1; ; attempt++ {
+		path := fmt.Sprintf("sanitize-results-%s.txt", stamp)
+		if attempt > 1 {
+			path = fmt.Sprintf("sanitize-results-%s-%d.txt", stamp, attempt)
+		}
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		return file, path, err
+	}
+}
+
+// scrollBy moves the result list by delta rows; reaching the end follows new results again
+func (m *tuiModel) scrollBy(delta int) {
+	last := max(len(m.shown)-m.listHeight, 0)
+	m.scroll = min(max(m.scroll+delta, 0), last)
+	m.follow = m.scroll == last
+}
+
+// renameLine formats a rename for the result list as the old path, the new name and the rules behind it
+func (m *tuiModel) renameLine(result interfaces.RenameResult) string {
+	line := result.OldPath + m.glyphs.arrow + filepath.Base(result.NewPath)
+	if result.Merged {
+		line += " (merge)"
+	}
+	return line + ruleSuffix(result.Rules)
+}
+
+// truncateLeft shortens text to width runes by dropping its start, which keeps the changed name visible
+func truncateLeft(text string, width int) string {
+	runes := []rune(text)
+	if width < 4 || len(runes) <= width {
+		return text
+	}
+	return "..." + string(runes[len(runes)-width+3:])
+}
+
 // createProgressBar creates a visual progress bar
 func (m *tuiModel) createProgressBar(percentage float64) string {
 	width := m.windowWidth - 20 // Leave space for other content
@@ -271,7 +900,7 @@ func (m *tuiModel) createProgressBar(percentage float64) string {
 	}
 
 	filled := int(percentage / 100 * float64(width))
-	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+	bar := strings.Repeat(m.glyphs.barFilled, filled) + strings.Repeat(m.glyphs.barEmpty, width-filled)
 
-	return fmt.Sprintf("▕%s▏", bar)
+	return m.glyphs.barLeft + bar + m.glyphs.barRight
 }
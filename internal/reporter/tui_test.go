@@ -0,0 +1,143 @@
+package reporter_test
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/reporter"
+)
+
+// TestTUIModel_SaveResults tests that saving twice within a second keeps both files with the full result list
+func TestTUIModel_SaveResults(t *testing.T) {
+	t.Chdir(t.TempDir())
+	clock := time.Date(2024, 3, 1, 14, 25, 30, 0, time.UTC)
+	model := reporter.NewTUIModel(true, func() time.Time { return clock })
+	model.AddResult("a:b -> a_b")
+
+	first, err := model.SaveResults()
+	if err != nil {
+		t.Fatalf("SaveResults() returned error: %v", err)
+	}
+	model.AddResult("c?d -> c_d")
+	second, err := model.SaveResults()
+	if err != nil {
+		t.Fatalf("SaveResults() returned error: %v", err)
+	}
+
+	if first != "sanitize-results-20240301-142530.txt" || second != "sanitize-results-20240301-142530-2.txt" {
+		t.Fatalf("SaveResults() wrote %q and %q, want a counter on the second file", first, second)
+	}
+	tests := []struct {
+		path    string
+		results int
+	}{
+		{first, 1},
+		{second, 2},
+	}
+	for _, tt := range tests {
+		content, err := os.ReadFile(tt.path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", tt.path, err)
+		}
+		text := string(content)
+		if !strings.Contains(text, "(dry run)") || !strings.Contains(text, "a:b -> a_b") {
+			t.Errorf("%s is missing the header or the first result:\n%s", tt.path, text)
+		}
+		if want := fmt.Sprintf("Results (%d):", tt.results); !strings.Contains(text, want) {
+			t.Errorf("%s: expected %q in:\n%s", tt.path, want, text)
+		}
+	}
+}
+
+// tuiResults are the result lines the query tests filter
+var tuiResults = []string{
+	"Photos:2024 -> Photos_2024",
+	"report?.txt -> report_.txt",
+	"PHOTOS old -> PHOTOS_old",
+	"failed to rename Music*",
+}
+
+// TestTUIModel_SetQuery tests substring and re: queries, and that an invalid regular expression keeps the previous filter
+func TestTUIModel_SetQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		queries []string // Typed in order; the last one is checked
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "no query shows everything",
+			queries: []string{""},
+			want:    tuiResults,
+		},
+		{
+			name:    "substring ignores case",
+			queries: []string{"photos"},
+			want:    []string{tuiResults[0], tuiResults[2]},
+		},
+		{
+			name:    "substring is not a pattern",
+			queries: []string{"?.txt"},
+			want:    []string{tuiResults[1]},
+		},
+		{
+			name:    "regular expression",
+			queries: []string{"re:^photos.*_\\d+$"},
+			want:    []string{tuiResults[0]},
+		},
+		{
+			name:    "no matches",
+			queries: []string{"video"},
+			want:    []string{},
+		},
+		{
+			name:    "invalid regular expression keeps the previous filter",
+			queries: []string{"re:music", "re:music("},
+			want:    []string{tuiResults[3]},
+			wantErr: true,
+		},
+		{
+			name:    "clearing the query shows everything again",
+			queries: []string{"photos", ""},
+			want:    tuiResults,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := reporter.NewTUIModel(false, time.Now)
+			for _, line := range tuiResults {
+				model.AddResult(line)
+			}
+			for _, query := range tt.queries {
+				model.SetQuery(query)
+			}
+
+			if got := model.Shown(); !slices.Equal(got, tt.want) {
+				t.Errorf("Shown() = %q, want %q", got, tt.want)
+			}
+			if (model.QueryErr() != "") != tt.wantErr {
+				t.Errorf("QueryErr() = %q, want an error = %v", model.QueryErr(), tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestTUIModel_FilterNewResults tests that results arriving after the query was set are filtered the same way
+func TestTUIModel_FilterNewResults(t *testing.T) {
+	model := reporter.NewTUIModel(false, time.Now)
+	model.AddResult(tuiResults[0])
+	model.SetQuery("re:^(photos|report)")
+	for _, line := range tuiResults[1:] {
+		model.AddResult(line)
+	}
+
+	want := []string{tuiResults[0], tuiResults[1], tuiResults[2]}
+	if got := model.Shown(); !slices.Equal(got, want) {
+		t.Errorf("Shown() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,56 @@
+package reporter
+
+import (
+	"fmt"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Verbosity selects how much the console reporters show; errors are shown at every level
+type Verbosity int
+
+// Verbosity levels, from --quiet up to -vvv
+const (
+	VerbosityQuiet Verbosity = iota // Errors, and the summary of runs that renamed or failed something
+	VerbosityWarn                   // Also progress, warnings and every summary (default)
+	VerbosityInfo                   // Also every rename (-v)
+	VerbosityDebug                  // Also every folder as it is checked (-vv)
+	VerbosityTrace                  // Also every directory the walk reads (-vvv)
+)
+
+// verbosityNames are the names of the levels in help texts and logs
+var verbosityNames = map[Verbosity]string{
+	VerbosityQuiet: "quiet",
+	VerbosityWarn:  "warn",
+	VerbosityInfo:  "info",
+	VerbosityDebug: "debug",
+	VerbosityTrace: "trace",
+}
+
+// NewVerbosity returns the level selected by --quiet and the number of -v flags
+// Every -v raises the default warn level by one, up to trace
+func NewVerbosity(quiet bool, verbose int) (Verbosity, error) {
+	switch {
+	case quiet && verbose > 0:
+		return VerbosityQuiet, fmt.Errorf("--quiet and --verbose can't be combined")
+	case quiet:
+		return VerbosityQuiet, nil
+	case verbose < 0:
+		return VerbosityWarn, fmt.Errorf("--verbose must not be negative")
+	}
+	return min(VerbosityWarn+Verbosity(verbose), VerbosityTrace), nil
+}
+
+// String returns the name of the level
+func (v Verbosity) String() string {
+	return verbosityNames[v]
+}
+
+// noteworthy reports whether a quiet run has to show its summary: something was (or would be) renamed, failed or left in use
+func noteworthy(summary interfaces.ProcessingSummary) bool {
+	return summary.Phases.Planned > 0 ||
+		summary.ErrorCount > 0 ||
+		summary.Aborted ||
+		len(summary.Locked) > 0 ||
+		(summary.Verification != nil && len(summary.Verification.Discrepancies) > 0)
+}
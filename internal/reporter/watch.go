@@ -0,0 +1,83 @@
+// Package reporter provides a progress reporter for long-running watch mode.
+// This implementation writes one timestamped line per rename or problem and no per-directory summaries.
+package reporter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// watchTimeFormat prefixes every line so the output reads like a service log
+const watchTimeFormat = "2006-01-02 15:04:05"
+
+// WatchReporter implements the ProgressReporter, RenameReporter and WarningReporter interfaces for watch mode
+// This struct stays quiet about new directories that already comply, so the log only shows what changed
+type WatchReporter struct {
+	verbosity Verbosity
+	dryRun    bool
+	color     bool // Highlight the changed characters of renames with ANSI colors
+}
+
+// NewWatchReporter creates a new watch mode progress reporter
+func NewWatchReporter(verbosity Verbosity, dryRun, color bool) interfaces.ProgressReporter {
+	return &WatchReporter{
+		verbosity: verbosity,
+		dryRun:    dryRun,
+		color:     color,
+	}
+}
+
+// ReportProgress logs each new directory as it is checked, from debug verbosity up
+func (wr *WatchReporter) ReportProgress(current, total int, message string) {
+	if wr.verbosity >= VerbosityDebug {
+		wr.printf("%s", message)
+	}
+}
+
+// ReportRename logs a single rename, even in quiet mode: renames are what the log is for
+// This method implements the RenameReporter interface
+func (wr *WatchReporter) ReportRename(result interfaces.RenameResult) {
+	oldPath, newPath := result.OldPath, result.NewPath
+	if wr.color {
+		oldPath, newPath = highlightPaths(oldPath, newPath)
+	}
+
+	switch {
+	case result.Merged && wr.dryRun:
+		wr.printf("Would merge %s into %s", oldPath, newPath)
+	case result.Merged:
+		wr.printf("Merged %s into %s", oldPath, newPath)
+	case wr.dryRun:
+		wr.printf("Would rename %s to %s%s", oldPath, newPath, ruleSuffix(result.Rules))
+	default:
+		wr.printf("Renamed %s to %s%s", oldPath, newPath, ruleSuffix(result.Rules))
+	}
+}
+
+// ReportError logs an error
+func (wr *WatchReporter) ReportError(err error) {
+	wr.printf("Error: %v", err)
+}
+
+// ReportWarning logs a problem that did not stop the run
+// This method implements the WarningReporter interface
+func (wr *WatchReporter) ReportWarning(warning error) {
+	if wr.verbosity == VerbosityQuiet {
+		return
+	}
+	wr.printf("Warning: %v", warning)
+}
+
+// ReportComplete logs why handling a new directory stopped early; renames were already logged one by one
+func (wr *WatchReporter) ReportComplete(summary interfaces.ProcessingSummary) {
+	if summary.Aborted {
+		wr.printf("Stopped early: %s", summary.AbortReason)
+	}
+}
+
+// printf writes a single timestamped line
+func (wr *WatchReporter) printf(format string, args ...any) {
+	fmt.Printf("%s %s\n", time.Now().Format(watchTimeFormat), fmt.Sprintf(format, args...))
+}
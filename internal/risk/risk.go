@@ -0,0 +1,161 @@
+// Package risk flags folder names that pass every naming rule but are still likely to cause trouble.
+// Risks are reported apart from violations: they never fail a check and never cause a rename.
+package risk
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/punkscience/sanitize/internal/dataset"
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Risk identifiers reported by AssessName
+// These names are stable so they can be used in scripts and with WithIgnored
+const (
+	DigitsOnly   = "digits-only"
+	LongName     = "long-name"
+	MixedScripts = "mixed-scripts"
+	PathHeadroom = "path-headroom"
+)
+
+// descriptions explains each risk for help texts and reports
+var descriptions = map[string]string{
+	DigitsOnly:   "only digits with a leading zero or more than 15 digits; spreadsheets turn such names into numbers and drop zeros or digits",
+	LongName:     "longer than the name length threshold; little of the path limit is left for the files inside",
+	MixedScripts: "letters of more than one writing system, e.g. Latin and Cyrillic; such names can look like other names",
+	PathHeadroom: "the path is within the headroom of the profile's path limit; files added inside may not fit",
+}
+
+// Description returns what a risk means, or "" for unknown risks
+func Description(risk string) string {
+	return descriptions[risk]
+}
+
+// Names returns the identifiers of all risks in alphabetical order
+func Names() []string {
+	names := make([]string, 0, len(descriptions))
+	for name := range descriptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default thresholds
+const (
+	DefaultNameLength   = 200 // Names longer than this leave little of a 255-260 character path limit
+	DefaultPathHeadroom = 20  // Characters a path should leave below the limit for the files inside
+)
+
+// maxExactDigits is the number of significant digits spreadsheets keep for numbers
+const maxExactDigits = 15
+
+// Assessor implements RiskAssessor with configurable thresholds
+type Assessor struct {
+	nameLength    int             // Names longer than this are risky (0 = never)
+	pathHeadroom  int             // Paths leaving fewer characters than this below maxPathLength are risky (0 = never)
+	maxPathLength int             // Root-relative path limit of the profile (0 = none)
+	ignored       map[string]bool // Risks that are never reported
+}
+
+// Option configures optional Assessor behavior
+type Option func(*Assessor)
+
+// WithNameLength flags names longer than length characters (0 = never)
+func WithNameLength(length int) Option {
+	return func(a *Assessor) {
+		a.nameLength = length
+	}
+}
+
+// WithPathHeadroom flags folders whose root-relative path leaves fewer than headroom characters below maxPathLength
+// Profiles without a path limit (maxPathLength 0) are never flagged
+func WithPathHeadroom(headroom, maxPathLength int) Option {
+	return func(a *Assessor) {
+		a.pathHeadroom = headroom
+		a.maxPathLength = maxPathLength
+	}
+}
+
+// WithIgnored never reports the given risks
+func WithIgnored(risks []string) Option {
+	return func(a *Assessor) {
+		for _, risk := range risks {
+			a.ignored[risk] = true
+		}
+	}
+}
+
+// NewAssessor creates an Assessor with the default name length threshold and no path limit
+func NewAssessor(options ...Option) *Assessor {
+	a := &Assessor{
+		nameLength: DefaultNameLength,
+		ignored:    make(map[string]bool),
+	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+// Validate checks that every risk given to WithIgnored exists
+func Validate(risks []string) error {
+	for _, risk := range risks {
+		if _, known := descriptions[risk]; !known {
+			return fmt.Errorf("unknown risk %q (available: %s)", risk, strings.Join(Names(), ", "))
+		}
+	}
+	return nil
+}
+
+// AssessName returns the risks of name, which the folder has once it is sanitized
+// This method implements the RiskAssessor interface
+func (a *Assessor) AssessName(folder interfaces.FolderInfo, name string) []string {
+	var risks []string
+	add := func(risk string, risky bool) {
+		if risky && !a.ignored[risk] {
+			risks = append(risks, risk)
+		}
+	}
+
+	length := utf8.RuneCountInString(name)
+	add(DigitsOnly, digitsOnly(name) && (name[0] == '0' && len(name) > 1 || len(name) > maxExactDigits))
+	add(LongName, a.nameLength > 0 && length > a.nameLength)
+	add(MixedScripts, dataset.MixedScripts(name))
+	if a.pathHeadroom > 0 && a.maxPathLength > 0 {
+		add(PathHeadroom, a.maxPathLength-pathLength(folder, length) < a.pathHeadroom)
+	}
+
+	return risks
+}
+
+// digitsOnly reports whether a name consists of ASCII digits alone
+func digitsOnly(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// pathLength returns the length of the root-relative path of a folder with a name of nameLength characters
+// The root-relative path consists of the last Depth components of the folder path, like for the path limit rule
+func pathLength(folder interfaces.FolderInfo, nameLength int) int {
+	components := strings.Split(filepath.ToSlash(folder.Path), "/")
+	if folder.Depth < 1 || len(components) < folder.Depth {
+		return nameLength
+	}
+	parent := components[len(components)-folder.Depth : len(components)-1]
+	if len(parent) == 0 {
+		return nameLength
+	}
+	return utf8.RuneCountInString(strings.Join(parent, "/")) + 1 + nameLength
+}
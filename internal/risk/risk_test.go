@@ -0,0 +1,69 @@
+// Package risk_test provides tests for the risk package.
+// This test suite ensures risky names are flagged with the configured thresholds.
+package risk_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/risk"
+)
+
+// folderAt returns a folder at depth below the root /data, with parents named p1, p2, ...
+func folderAt(depth int, name string) interfaces.FolderInfo {
+	path := "/data"
+	for i := 1; i < depth; i++ {
+		path += "/p" + strings.Repeat("x", i)
+	}
+	return interfaces.FolderInfo{Path: path + "/" + name, Name: name, Depth: depth}
+}
+
+// TestAssessor_AssessName tests each risk with the default thresholds
+func TestAssessor_AssessName(t *testing.T) {
+	assessor := risk.NewAssessor()
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "Invoices", want: nil},
+		{name: "2024", want: nil},
+		{name: "0", want: nil},
+		{name: "0012", want: []string{risk.DigitsOnly}},
+		{name: "1234567890123456", want: []string{risk.DigitsOnly}},
+		{name: strings.Repeat("n", 201), want: []string{risk.LongName}},
+		{name: "Pаypal", want: []string{risk.MixedScripts}}, // Cyrillic а
+		{name: "Ελληνικά", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := assessor.AssessName(folderAt(1, tt.name), tt.name); !slices.Equal(got, tt.want) {
+			t.Errorf("AssessName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestAssessor_Thresholds tests configured thresholds, the path limit headroom and ignored risks
+func TestAssessor_Thresholds(t *testing.T) {
+	assessor := risk.NewAssessor(risk.WithNameLength(10), risk.WithPathHeadroom(20, 40))
+
+	// px/name: 2 + 1 + 17 = 20 characters leave exactly the headroom; one more character doesn't
+	fits, tight := strings.Repeat("n", 17), strings.Repeat("n", 18)
+	if got := assessor.AssessName(folderAt(2, fits), fits); !slices.Equal(got, []string{risk.LongName}) {
+		t.Errorf("Expected only a long name, got %v", got)
+	}
+	if got := assessor.AssessName(folderAt(2, tight), tight); !slices.Equal(got, []string{risk.LongName, risk.PathHeadroom}) {
+		t.Errorf("Expected a long name close to the path limit, got %v", got)
+	}
+
+	ignoring := risk.NewAssessor(risk.WithNameLength(0), risk.WithIgnored([]string{risk.DigitsOnly}))
+	if got := ignoring.AssessName(folderAt(1, "007"), "007"+strings.Repeat("0", 300)); got != nil {
+		t.Errorf("Expected ignored and disabled risks to be skipped, got %v", got)
+	}
+
+	if err := risk.Validate([]string{risk.LongName, "bogus"}); err == nil {
+		t.Error("Expected an unknown risk to be rejected")
+	}
+}
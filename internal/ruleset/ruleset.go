@@ -0,0 +1,220 @@
+// Package ruleset applies organization-specific naming rules loaded from a file.
+// Find/replace and strip rules run before or after the built-in rules; reserved words and length limits extend the profile.
+package ruleset
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/sanitizer"
+)
+
+// RulePrefix prefixes the rule identifier reported for a custom rule, e.g. "custom:house:12"
+const RulePrefix = "custom:"
+
+// regexPrefix marks a pattern as a regular expression instead of literal text
+const regexPrefix = "re:"
+
+// replacementSeparator separates a pattern from its replacement
+const replacementSeparator = "=>"
+
+// Section headers of a ruleset file; rules before the first header run before the built-in rules
+const (
+	sectionBefore = "[before]"
+	sectionAfter  = "[after]"
+)
+
+// Rule is a single find/replace or strip rule of a ruleset
+type Rule struct {
+	ID          string // Identifier reported when the rule changes a name
+	Spec        string // Original rule line, for messages
+	expression  *regexp.Regexp
+	replacement string
+	literal     bool // The replacement is inserted as is instead of expanding $1 references
+}
+
+// Apply returns name with every match of the rule replaced
+func (r Rule) Apply(name string) string {
+	if r.literal {
+		return r.expression.ReplaceAllLiteralString(name, r.replacement)
+	}
+	return r.expression.ReplaceAllString(name, r.replacement)
+}
+
+// Ruleset is a named list of rules loaded from a file
+type Ruleset struct {
+	Name          string
+	Before        []Rule   // Rules applied before the built-in rules, in file order
+	After         []Rule   // Rules applied after the built-in rules, in file order
+	Reserved      []string // Additional reserved names, suffixed like the built-in device names
+	MaxNameLength int      // Stricter limit for the length of a name (0 = profile default)
+	MaxPathLength int      // Stricter limit for the root-relative path length (0 = profile default)
+}
+
+// Load reads a ruleset from a file; the ruleset is named after the file without its extension
+func Load(path string) (*Ruleset, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ruleset: %w", err)
+	}
+	defer file.Close()
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return Parse(name, file)
+}
+
+// Parse reads ruleset directives, one per line; blank lines and lines starting with # are ignored
+// Directives are "replace PATTERN => TEXT", "strip PATTERN", "reserved NAME...", "max-length N" and "max-path-length N";
+// a PATTERN is literal text or "re:<expression>", and text with surrounding spaces can be written as a Go quoted string
+func Parse(name string, r io.Reader) (*Ruleset, error) {
+	ruleset := &Ruleset{Name: name}
+	after := false
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch strings.ToLower(line) {
+		case sectionBefore:
+			after = false
+			continue
+		case sectionAfter:
+			after = true
+			continue
+		}
+
+		if err := ruleset.parseDirective(line, lineNumber, after); err != nil {
+			return nil, fmt.Errorf("ruleset %s, line %d: %w", name, lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ruleset %s: %w", name, err)
+	}
+
+	return ruleset, nil
+}
+
+// parseDirective parses a single directive line; after tells whether it belongs to the [after] section
+func (rs *Ruleset) parseDirective(line string, lineNumber int, after bool) error {
+	directive, argument, _ := strings.Cut(line, " ")
+	argument = strings.TrimSpace(argument)
+
+	switch directive {
+	case "replace", "strip":
+		rule, err := rs.parseRule(directive, argument, line, lineNumber, after)
+		if err != nil {
+			return err
+		}
+		if after {
+			rs.After = append(rs.After, rule)
+		} else {
+			rs.Before = append(rs.Before, rule)
+		}
+	case "reserved":
+		names := strings.Fields(argument)
+		if len(names) == 0 {
+			return fmt.Errorf("reserved needs at least one name")
+		}
+		rs.Reserved = append(rs.Reserved, names...)
+	case "max-length", "max-path-length":
+		limit, err := strconv.Atoi(argument)
+		if err != nil || limit < 1 {
+			return fmt.Errorf("%s needs a positive number, got %q", directive, argument)
+		}
+		if directive == "max-length" {
+			if limit < sanitizer.MinNameLength {
+				return fmt.Errorf("max-length must be at least %d, got %d", sanitizer.MinNameLength, limit)
+			}
+			rs.MaxNameLength = limit
+		} else {
+			rs.MaxPathLength = limit
+		}
+	default:
+		return fmt.Errorf("unknown directive %q (available: replace, strip, reserved, max-length, max-path-length)", directive)
+	}
+	return nil
+}
+
+// parseRule parses the pattern and replacement of a replace or strip directive
+func (rs *Ruleset) parseRule(directive, argument, line string, lineNumber int, after bool) (Rule, error) {
+	rule := Rule{ID: fmt.Sprintf("%s%s:%d", RulePrefix, rs.Name, lineNumber), Spec: line}
+
+	spec := argument
+	if directive == "replace" {
+		pattern, replacement, found := strings.Cut(argument, replacementSeparator)
+		if !found {
+			return Rule{}, fmt.Errorf("replace needs PATTERN %s TEXT", replacementSeparator)
+		}
+		var err error
+		if rule.replacement, err = unquote(strings.TrimSpace(replacement)); err != nil {
+			return Rule{}, err
+		}
+		spec = strings.TrimSpace(pattern)
+	}
+
+	source, isExpression := strings.CutPrefix(spec, regexPrefix)
+	if !isExpression {
+		text, err := unquote(spec)
+		if err != nil {
+			return Rule{}, err
+		}
+		source = regexp.QuoteMeta(text)
+		rule.literal = true
+	}
+	if source == "" {
+		return Rule{}, fmt.Errorf("empty pattern in %q", line)
+	}
+
+	expression, err := regexp.Compile(source)
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid pattern %q: %w", spec, err)
+	}
+	rule.expression = expression
+
+	// Rules after the built-in ones must not bring back what those just removed
+	if after {
+		for _, char := range rule.replacement {
+			if char < 32 || sanitizer.IsViolatingRune(char) {
+				return Rule{}, fmt.Errorf("replacement %q contains the invalid character %q", rule.replacement, char)
+			}
+		}
+	}
+
+	return rule, nil
+}
+
+// unquote returns a Go quoted string without its quotes and any other text as is
+func unquote(text string) (string, error) {
+	if !strings.HasPrefix(text, `"`) {
+		return text, nil
+	}
+	unquoted, err := strconv.Unquote(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted string %s", text)
+	}
+	return unquoted, nil
+}
+
+// ApplyProfile returns the profile extended by the ruleset's reserved names and tightened by its length limits
+// Limits only ever get stricter, so a ruleset can't make a profile accept names its target rejects
+func (rs *Ruleset) ApplyProfile(profile sanitizer.Profile) sanitizer.Profile {
+	if len(rs.Reserved) > 0 {
+		profile.ReservedNames = append(append([]string{}, profile.ReservedNames...), rs.Reserved...)
+	}
+	if rs.MaxNameLength > 0 && (profile.MaxNameLength == 0 || rs.MaxNameLength < profile.MaxNameLength) {
+		profile.MaxNameLength = rs.MaxNameLength
+	}
+	if rs.MaxPathLength > 0 && (profile.MaxPathLength == 0 || rs.MaxPathLength < profile.MaxPathLength) {
+		profile.MaxPathLength = rs.MaxPathLength
+	}
+	return profile
+}
@@ -0,0 +1,126 @@
+// Package ruleset_test provides tests for custom rulesets.
+// This test suite ensures directives parse and rules run before and after the built-in rules.
+package ruleset_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/ruleset"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+)
+
+// sampleRules has rules in both sections, reserved names and a length limit
+const sampleRules = `
+# House conventions
+replace re:^(\d{4})(\d{2})(\d{2})\b => $1-$2-$3
+replace " & " => " and "
+strip re:[()]
+reserved Draft Temp
+max-length 40
+
+[after]
+replace re:_+ => _
+replace re:-$ => " x."
+`
+
+// parseSample parses sampleRules or fails the test
+func parseSample(t *testing.T) *ruleset.Ruleset {
+	t.Helper()
+	rules, err := ruleset.Parse("house", strings.NewReader(sampleRules))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	return rules
+}
+
+// TestParse tests that directives end up in the right section and limits are read
+func TestParse(t *testing.T) {
+	rules := parseSample(t)
+
+	if len(rules.Before) != 3 || len(rules.After) != 2 {
+		t.Fatalf("Expected 3 before and 2 after rules, got %d and %d", len(rules.Before), len(rules.After))
+	}
+	if rules.Before[0].ID != "custom:house:3" {
+		t.Errorf("Expected the rule to be identified by its line, got %q", rules.Before[0].ID)
+	}
+	if !reflect.DeepEqual(rules.Reserved, []string{"Draft", "Temp"}) || rules.MaxNameLength != 40 {
+		t.Errorf("Unexpected reserved names %v or length limit %d", rules.Reserved, rules.MaxNameLength)
+	}
+}
+
+// TestParse_Invalid tests that malformed directives are rejected with their line number
+func TestParse_Invalid(t *testing.T) {
+	invalid := []string{
+		"rename a => b",
+		"replace a b",
+		"replace re:( => x",
+		"strip \"unterminated",
+		"reserved",
+		"max-length 0",
+		"max-length 1",
+		"max-length 2",
+		"max-length 3",
+		"[after]\nreplace x => a:b",
+	}
+	for _, content := range invalid {
+		if _, err := ruleset.Parse("bad", strings.NewReader(content)); err == nil {
+			t.Errorf("Parse(%q) expected error", content)
+		}
+	}
+
+	_, err := ruleset.Parse("bad", strings.NewReader("strip x\n\nmax-length many"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected error on line 3, got %v", err)
+	}
+}
+
+// TestSanitizer tests that custom rules wrap the built-in ones and the result stays compliant
+func TestSanitizer(t *testing.T) {
+	rules := parseSample(t)
+	profile, _ := sanitizer.LookupProfile(sanitizer.DefaultProfile)
+	base := sanitizer.NewWindowsSanitizer(sanitizer.WithProfile(rules.ApplyProfile(profile)))
+	s := ruleset.NewSanitizer(base, []*ruleset.Ruleset{rules})
+
+	tests := map[string]string{
+		"20240131 Q1 & Q2 (final)": "2024-01-31 Q1 and Q2 final",
+		"a::b":                     "a_b",
+		"draft":                    "draft_",
+		"release-":                 "release x", // Trailing period of the [after] rule removed again
+		strings.Repeat("x", 60):    strings.Repeat("x", 40),
+	}
+	for input, want := range tests {
+		if got := s.SanitizeName(input); got != want {
+			t.Errorf("SanitizeName(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	_, explained := s.ExplainName("20240131 a::b")
+	want := []string{"custom:house:3", sanitizer.RuleInvalidCharacters, "custom:house:10"}
+	if !reflect.DeepEqual(explained, want) {
+		t.Errorf("ExplainName() rules = %v, want %v", explained, want)
+	}
+
+	name, steps := s.TraceName("20240131 a::b")
+	if name != "2024-01-31 a_b" || len(steps) != 3 || steps[2].Before != "2024-01-31 a__b" {
+		t.Errorf("TraceName() = %q %+v", name, steps)
+	}
+}
+
+// TestParse_MinNameLength tests that the smallest name length flags accept is also the smallest a rules file accepts
+func TestParse_MinNameLength(t *testing.T) {
+	rules, err := ruleset.Parse("tiny", strings.NewReader(fmt.Sprintf("max-length %d", sanitizer.MinNameLength)))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if rules.MaxNameLength != sanitizer.MinNameLength {
+		t.Errorf("Expected max-length %d, got %d", sanitizer.MinNameLength, rules.MaxNameLength)
+	}
+
+	_, err = ruleset.Parse("tiny", strings.NewReader(fmt.Sprintf("max-length %d", sanitizer.MinNameLength-1)))
+	if err == nil || !strings.Contains(err.Error(), "at least") {
+		t.Errorf("Expected a limit below the minimum to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package ruleset
+
+import (
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Sanitizer implements FolderSanitizer, NameExplainer, FolderExplainer and NameTracer
+// This struct applies the [before] rules of its rulesets, then the wrapped sanitizer, then the [after] rules
+type Sanitizer struct {
+	base     interfaces.FolderSanitizer
+	rulesets []*Ruleset
+}
+
+// NewSanitizer wraps base with the rules of the rulesets, applied in the order given
+// Reserved names and length limits are not applied here; see Ruleset.ApplyProfile
+func NewSanitizer(base interfaces.FolderSanitizer, rulesets []*Ruleset) *Sanitizer {
+	return &Sanitizer{
+		base:     base,
+		rulesets: rulesets,
+	}
+}
+
+// SanitizeName returns the sanitized version of a bare name
+// This method implements the FolderSanitizer interface
+func (s *Sanitizer) SanitizeName(name string) string {
+	sanitized, _ := s.ExplainFolder(interfaces.FolderInfo{Name: name})
+	return sanitized
+}
+
+// ExplainName returns the sanitized name and the rules that fired, including custom rules
+// This method implements the NameExplainer interface
+func (s *Sanitizer) ExplainName(name string) (string, []string) {
+	return s.ExplainFolder(interfaces.FolderInfo{Name: name})
+}
+
+// ExplainFolder applies the custom and built-in rules to the folder
+// This method implements the FolderExplainer interface
+func (s *Sanitizer) ExplainFolder(folder interfaces.FolderInfo) (string, []string) {
+	var steps []interfaces.RuleStep
+	for _, ruleset := range s.rulesets {
+		folder.Name, steps = applyRules(ruleset.Before, folder.Name, steps)
+	}
+	rules := stepRules(steps)
+
+	sanitized, baseRules := interfaces.Explain(s.base, folder)
+	rules = append(rules, baseRules...)
+
+	// When an [after] rule changed the name, the wrapped sanitizer runs once more so the result stays compliant
+	steps = nil
+	afterName := sanitized
+	for _, ruleset := range s.rulesets {
+		afterName, steps = applyRules(ruleset.After, afterName, steps)
+	}
+	if afterName == sanitized {
+		return sanitized, rules
+	}
+	rules = append(rules, stepRules(steps)...)
+
+	folder.Name = afterName
+	sanitized, baseRules = interfaces.Explain(s.base, folder)
+	return sanitized, append(rules, baseRules...)
+}
+
+// TraceName returns the sanitized name and what each custom and built-in rule changed
+// This method implements the NameTracer interface
+func (s *Sanitizer) TraceName(name string) (string, []interfaces.RuleStep) {
+	var steps []interfaces.RuleStep
+	for _, ruleset := range s.rulesets {
+		name, steps = applyRules(ruleset.Before, name, steps)
+	}
+
+	sanitized, baseSteps := interfaces.Trace(s.base, name)
+	steps = append(steps, baseSteps...)
+
+	afterName := sanitized
+	for _, ruleset := range s.rulesets {
+		afterName, steps = applyRules(ruleset.After, afterName, steps)
+	}
+	if afterName == sanitized {
+		return sanitized, steps
+	}
+
+	sanitized, baseSteps = interfaces.Trace(s.base, afterName)
+	return sanitized, append(steps, baseSteps...)
+}
+
+// stepRules returns the rule identifiers of the steps
+func stepRules(steps []interfaces.RuleStep) []string {
+	rules := make([]string, 0, len(steps))
+	for _, step := range steps {
+		rules = append(rules, step.Rule)
+	}
+	return rules
+}
+
+// applyRules applies rules in order and records a step for every rule that changed the name
+func applyRules(rules []Rule, name string, steps []interfaces.RuleStep) (string, []interfaces.RuleStep) {
+	for _, rule := range rules {
+		if replaced := rule.Apply(name); replaced != name {
+			steps = append(steps, interfaces.RuleStep{Rule: rule.ID, Before: name, After: replaced})
+			name = replaced
+		}
+	}
+	return name, steps
+}
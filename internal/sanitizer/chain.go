@@ -0,0 +1,98 @@
+package sanitizer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// ProfilePrefix marks a pipeline entry that applies a whole profile instead of a single stage, e.g. "profile:onedrive"
+const ProfilePrefix = "profile:"
+
+// Chain implements FolderSanitizer, NameExplainer, FolderExplainer and NameTracer
+// This struct composes sanitizers into a pipeline: each one sanitizes the result of the previous one
+type Chain struct {
+	links []interfaces.FolderSanitizer
+}
+
+// NewChain composes the sanitizers in the order given
+func NewChain(links ...interfaces.FolderSanitizer) *Chain {
+	return &Chain{links: links}
+}
+
+// SanitizeName runs the name through every sanitizer of the chain
+// This method implements the FolderSanitizer interface
+func (c *Chain) SanitizeName(name string) string {
+	sanitized, _ := c.ExplainFolder(interfaces.FolderInfo{Name: name})
+	return sanitized
+}
+
+// ExplainName runs the name through the chain and reports the rules of every sanitizer in order
+// This method implements the NameExplainer interface
+func (c *Chain) ExplainName(name string) (string, []string) {
+	return c.ExplainFolder(interfaces.FolderInfo{Name: name})
+}
+
+// ExplainFolder runs the folder's name through the chain; every sanitizer sees the folder's location
+// This method implements the FolderExplainer interface
+func (c *Chain) ExplainFolder(folder interfaces.FolderInfo) (string, []string) {
+	var rules []string
+	for _, link := range c.links {
+		var linkRules []string
+		folder.Name, linkRules = interfaces.Explain(link, folder)
+		rules = append(rules, linkRules...)
+	}
+	return folder.Name, rules
+}
+
+// TraceName runs the name through the chain and reports what each rule of every sanitizer changed
+// This method implements the NameTracer interface
+func (c *Chain) TraceName(name string) (string, []interfaces.RuleStep) {
+	var steps []interfaces.RuleStep
+	for _, link := range c.links {
+		var linkSteps []interfaces.RuleStep
+		name, linkSteps = interfaces.Trace(link, name)
+		steps = append(steps, linkSteps...)
+	}
+	return name, steps
+}
+
+// NewPipeline builds a sanitizer from pipeline entries: stage names and "profile:NAME" for a whole profile
+// Consecutive stages share one sanitizer configured by options; a profile entry applies options with that profile
+func NewPipeline(entries []string, options ...Option) (interfaces.FolderSanitizer, error) {
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("empty pipeline")
+	}
+
+	var links []interfaces.FolderSanitizer
+	var stages []string
+	flush := func() {
+		if len(stages) > 0 {
+			links = append(links, NewWindowsSanitizer(append(options[:len(options):len(options)], WithStages(stages))...))
+			stages = nil
+		}
+	}
+
+	for _, entry := range entries {
+		if profileName, ok := strings.CutPrefix(entry, ProfilePrefix); ok {
+			profile, err := LookupProfile(profileName)
+			if err != nil {
+				return nil, err
+			}
+			flush()
+			links = append(links, NewWindowsSanitizer(append(options[:len(options):len(options)], WithProfile(profile))...))
+			continue
+		}
+		if err := ValidateStages([]string{entry}); err != nil {
+			return nil, err
+		}
+		stages = append(stages, entry)
+	}
+	flush()
+
+	if len(links) == 1 {
+		return links[0], nil
+	}
+	return NewChain(links...), nil
+}
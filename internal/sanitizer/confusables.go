@@ -0,0 +1,76 @@
+package sanitizer
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// confusableRunes maps letters of other scripts to the ASCII letters they are indistinguishable from
+// This is the subset of the Unicode confusables data (UTS #39) whose prototype is a single ASCII letter;
+// fullwidth, mathematical and other compatibility forms are folded with NFKC instead
+var confusableRunes = map[rune]rune{
+	// Cyrillic
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T',
+	'Х': 'X', 'Ѕ': 'S', 'І': 'I', 'Ј': 'J', 'Ү': 'Y', 'Ԛ': 'Q', 'Ԝ': 'W', 'Һ': 'h', 'Ӏ': 'I',
+	'а': 'a', 'е': 'e', 'о': 'o', 'р': 'p', 'с': 'c', 'у': 'y', 'х': 'x', 'ѕ': 's', 'і': 'i', 'ј': 'j',
+	'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w', 'һ': 'h', 'ӏ': 'l', 'ү': 'y',
+	// Greek
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N', 'Ο': 'O',
+	'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+	'α': 'a', 'ι': 'i', 'ν': 'v', 'ο': 'o', 'ρ': 'p', 'υ': 'u',
+	// Armenian
+	'Ս': 'U', 'Օ': 'O', 'ս': 'u', 'օ': 'o', 'հ': 'h', 'ո': 'n', 'ց': 'g', 'զ': 'q',
+	// Latin letters that look like other Latin letters
+	'ı': 'i', 'ȷ': 'j', 'ɑ': 'a', 'ɡ': 'g', 'ɩ': 'i',
+}
+
+// WithConfusables maps characters that look like ASCII letters and digits to them, e.g. Cyrillic "А" or fullwidth "Ａ" to "A"
+// Visually identical names then sanitize to the same name, so duplicates collide instead of living side by side
+func WithConfusables(confusables bool) Option {
+	return func(ws *WindowsSanitizer) {
+		ws.confusables = confusables
+	}
+}
+
+// confusableASCII returns the ASCII look-alike of r, or false if it has none
+// Compatibility forms only count if NFKC folds them into printable ASCII, e.g. "Ａ" -> "A" or "ﬁ" -> "fi"
+func confusableASCII(r rune) (string, bool) {
+	if r < 128 {
+		return "", false
+	}
+	if ascii, ok := confusableRunes[r]; ok {
+		return string(ascii), true
+	}
+
+	folded := norm.NFKC.String(string(r))
+	if folded == string(r) || folded == "" {
+		return "", false
+	}
+	for _, c := range folded {
+		if c < 32 || c > 126 {
+			return "", false
+		}
+	}
+	return folded, true
+}
+
+// applyConfusables replaces every character that has an ASCII look-alike with it
+func (ws *WindowsSanitizer) applyConfusables(name string, steps trace) (string, trace) {
+	var builder strings.Builder
+	found := false
+	for _, r := range name {
+		if ascii, ok := confusableASCII(r); ok {
+			builder.WriteString(ascii)
+			found = true
+			continue
+		}
+		builder.WriteRune(r)
+	}
+
+	if !found {
+		return name, steps
+	}
+	normalized := builder.String()
+	return normalized, steps.add(RuleConfusables, name, normalized)
+}
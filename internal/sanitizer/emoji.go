@@ -0,0 +1,163 @@
+package sanitizer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/runenames"
+)
+
+// Emoji modes for emoji and pictographs
+const (
+	EmojiReplace = "replace" // Replace each with the invalid character replacement, like any other unmappable character
+	EmojiStrip   = "strip"   // Remove them
+	EmojiText    = "text"    // Replace each with its Unicode name as a slug, e.g. "🎉" -> "party-popper"
+)
+
+// emojiModes lists the modes for help output and validation
+var emojiModes = []string{EmojiReplace, EmojiStrip, EmojiText}
+
+// emojiRunes are the emoji and pictographs the emoji stage handles: the emoji blocks of the supplementary planes,
+// miscellaneous symbols and dingbats, and the few emoji in other blocks such as ⌚, ⭐ and 〰
+var emojiRunes = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x231A, Hi: 0x231B, Stride: 1}, // Watch, hourglass
+		{Lo: 0x23E9, Hi: 0x23F3, Stride: 1}, // Media controls, alarm clock, stopwatch
+		{Lo: 0x23F8, Hi: 0x23FA, Stride: 1}, // Pause, stop, record
+		{Lo: 0x2600, Hi: 0x27BF, Stride: 1}, // Miscellaneous symbols, dingbats
+		{Lo: 0x2B05, Hi: 0x2B07, Stride: 1}, // Arrows
+		{Lo: 0x2B1B, Hi: 0x2B1C, Stride: 1}, // Large squares
+		{Lo: 0x2B50, Hi: 0x2B50, Stride: 1}, // Star
+		{Lo: 0x2B55, Hi: 0x2B55, Stride: 1}, // Large circle
+		{Lo: 0x3030, Hi: 0x3030, Stride: 1}, // Wavy dash
+		{Lo: 0x303D, Hi: 0x303D, Stride: 1}, // Part alternation mark
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1F000, Hi: 0x1F0FF, Stride: 1}, // Mahjong tiles, dominoes, playing cards
+		{Lo: 0x1F170, Hi: 0x1F1FF, Stride: 1}, // Enclosed letters and regional indicators
+		{Lo: 0x1F200, Hi: 0x1F2FF, Stride: 1}, // Enclosed ideographs
+		{Lo: 0x1F300, Hi: 0x1F6FF, Stride: 1}, // Pictographs, emoticons, transport and map symbols
+		{Lo: 0x1F7E0, Hi: 0x1F7FF, Stride: 1}, // Colored circles and squares
+		{Lo: 0x1F900, Hi: 0x1F9FF, Stride: 1}, // Supplemental symbols and pictographs
+		{Lo: 0x1FA70, Hi: 0x1FAFF, Stride: 1}, // Symbols and pictographs extended
+	},
+}
+
+// Runes that only modify the emoji before them
+const (
+	zeroWidthJoiner = '\u200d' // Joins emoji into one, e.g. family members
+	emojiSelector   = '\ufe0f' // Requests the emoji presentation of the preceding character
+)
+
+// EmojiModes returns the names of the emoji modes
+func EmojiModes() []string {
+	return append([]string{}, emojiModes...)
+}
+
+// ValidateEmoji checks that mode is an emoji mode; "" selects EmojiReplace
+func ValidateEmoji(mode string) error {
+	if mode == "" {
+		return nil
+	}
+	for _, known := range emojiModes {
+		if mode == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown emoji mode %q (available: %s)", mode, strings.Join(emojiModes, ", "))
+}
+
+// WithEmoji sets how emoji and pictographs are handled; it must pass ValidateEmoji
+// Without a mode, or with EmojiReplace, they are left to transliteration, which replaces each as before
+func WithEmoji(mode string) Option {
+	return func(ws *WindowsSanitizer) {
+		ws.emoji = mode
+	}
+}
+
+// IsEmojiRune reports whether r is an emoji or pictograph the emoji stage handles
+func IsEmojiRune(r rune) bool {
+	return unicode.Is(emojiRunes, r)
+}
+
+// isRegionalIndicator reports whether r is one of the letters two of which make up a flag, e.g. 🇩🇪
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// isSkinTone reports whether r is a skin tone modifier, which only changes the emoji before it
+func isSkinTone(r rune) bool {
+	return r >= 0x1F3FB && r <= 0x1F3FF
+}
+
+// emojiText returns the text an emoji is replaced with in EmojiText mode, e.g. "party-popper"
+// Emoji newer than the Unicode tables of this build have no name and yield ""
+func emojiText(r rune) string {
+	if isRegionalIndicator(r) {
+		return string(rune('a' + r - 0x1F1E6))
+	}
+	return strings.ReplaceAll(strings.ToLower(runenames.Name(r)), " ", "-")
+}
+
+// applyEmoji removes emoji and pictographs, or replaces them according to the emoji mode
+// Joiners, presentation selectors and skin tones that follow an emoji are removed with it
+func (ws *WindowsSanitizer) applyEmoji(name string, steps trace, ctx templateContext) (string, trace) {
+	replacement := ws.expand(ws.replacements.InvalidChar, ctx)
+	runes := []rune(name)
+	var builder strings.Builder
+	found, afterEmoji, dropSpace := false, false, false
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if afterEmoji && (r == zeroWidthJoiner || r == emojiSelector || isSkinTone(r)) {
+			found = true
+			continue
+		}
+		if !IsEmojiRune(r) {
+			// A stripped emoji between two spaces leaves only one of them: "Party 🎉 time" -> "Party time"
+			if dropSpace && r == ' ' {
+				dropSpace = false
+				continue
+			}
+			dropSpace = false
+			if ws.emoji == EmojiText && afterEmoji && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				builder.WriteByte('-')
+			}
+			builder.WriteRune(r)
+			afterEmoji = false
+			continue
+		}
+
+		found = true
+		switch ws.emoji {
+		case EmojiStrip:
+			last, _ := utf8.DecodeLastRuneInString(builder.String())
+			dropSpace = dropSpace || builder.Len() == 0 || last == ' '
+		case EmojiText:
+			text := emojiText(r)
+			// Two regional indicators are a flag: "🇩🇪" -> "flag-de"
+			if isRegionalIndicator(r) && i+1 < len(runes) && isRegionalIndicator(runes[i+1]) {
+				text = "flag-" + text + emojiText(runes[i+1])
+				i++
+			}
+			if text == "" {
+				text = replacement
+			}
+			if last, _ := utf8.DecodeLastRuneInString(builder.String()); unicode.IsLetter(last) || unicode.IsDigit(last) {
+				builder.WriteByte('-')
+			}
+			builder.WriteString(text)
+		default:
+			builder.WriteString(replacement)
+		}
+		afterEmoji = true
+	}
+
+	if !found {
+		return name, steps
+	}
+	cleaned := builder.String()
+	return cleaned, steps.add(RuleEmoji, name, cleaned)
+}
@@ -0,0 +1,59 @@
+package sanitizer
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// ValidateSourceEncodings checks that every name is a known encoding, e.g. "latin1", "shift_jis" or "euc-kr"
+// Names follow the WHATWG Encoding Standard, which also lists their aliases
+func ValidateSourceEncodings(names []string) error {
+	for _, name := range names {
+		if _, err := htmlindex.Get(name); err != nil {
+			return fmt.Errorf("unknown encoding %q (e.g. latin1, windows-1252, shift_jis, euc-jp, gbk, big5, euc-kr)", name)
+		}
+	}
+	return nil
+}
+
+// WithSourceEncodings decodes names that aren't valid UTF-8 from the first of the encodings they are valid in
+// The names must pass ValidateSourceEncodings; valid UTF-8 names are never decoded.
+func WithSourceEncodings(names []string) Option {
+	return func(ws *WindowsSanitizer) {
+		ws.sourceEncodings = nil
+		for _, name := range names {
+			if enc, err := htmlindex.Get(name); err == nil {
+				ws.sourceEncodings = append(ws.sourceEncodings, enc)
+			}
+		}
+	}
+}
+
+// applyDecode converts a name that isn't valid UTF-8 from the configured source encodings
+// An encoding fits if it decodes every byte; single-byte encodings like Latin-1 always fit, so they belong last.
+// Names no encoding fits are left alone, so transliteration replaces each invalid byte as without decoding.
+func (ws *WindowsSanitizer) applyDecode(name string, steps trace) (string, trace) {
+	if utf8.ValidString(name) {
+		return name, steps
+	}
+
+	for _, enc := range ws.sourceEncodings {
+		if decoded, ok := decodeName(enc, name); ok {
+			return decoded, steps.add(RuleSourceEncoding, name, decoded)
+		}
+	}
+	return name, steps
+}
+
+// decodeName decodes name with enc and reports whether every byte was valid in it
+func decodeName(enc encoding.Encoding, name string) (string, bool) {
+	decoded, err := enc.NewDecoder().String(name)
+	if err != nil || strings.ContainsRune(decoded, utf8.RuneError) {
+		return "", false
+	}
+	return decoded, true
+}
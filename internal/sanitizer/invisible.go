@@ -0,0 +1,59 @@
+package sanitizer
+
+import (
+	"unicode"
+)
+
+// invisibleRunes are the code points that render as nothing, or only change how neighboring characters render:
+// zero-width spaces and joiners, bidirectional marks, embeddings, overrides and isolates, fillers,
+// variation selectors and tags. Names containing them look like other names, which breaks scripts and enables spoofing.
+var invisibleRunes = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00AD, Hi: 0x00AD, Stride: 1}, // Soft hyphen
+		{Lo: 0x034F, Hi: 0x034F, Stride: 1}, // Combining grapheme joiner
+		{Lo: 0x061C, Hi: 0x061C, Stride: 1}, // Arabic letter mark
+		{Lo: 0x115F, Hi: 0x1160, Stride: 1}, // Hangul choseong and jungseong fillers
+		{Lo: 0x17B4, Hi: 0x17B5, Stride: 1}, // Khmer inherent vowels
+		{Lo: 0x180B, Hi: 0x180F, Stride: 1}, // Mongolian variation selectors and vowel separator
+		{Lo: 0x200B, Hi: 0x200F, Stride: 1}, // Zero-width space, non-joiner and joiner, left-to-right and right-to-left marks
+		{Lo: 0x202A, Hi: 0x202E, Stride: 1}, // Bidirectional embeddings and overrides
+		{Lo: 0x2060, Hi: 0x206F, Stride: 1}, // Word joiner, invisible operators, bidirectional isolates, deprecated formatting
+		{Lo: 0x3164, Hi: 0x3164, Stride: 1}, // Hangul filler
+		{Lo: 0xFE00, Hi: 0xFE0F, Stride: 1}, // Variation selectors
+		{Lo: 0xFEFF, Hi: 0xFEFF, Stride: 1}, // Zero-width no-break space (byte order mark)
+		{Lo: 0xFFA0, Hi: 0xFFA0, Stride: 1}, // Halfwidth Hangul filler
+		{Lo: 0xFFF9, Hi: 0xFFFB, Stride: 1}, // Interlinear annotation
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1D173, Hi: 0x1D17A, Stride: 1}, // Musical symbol formatting
+		{Lo: 0xE0000, Hi: 0xE007F, Stride: 1}, // Tags
+		{Lo: 0xE0100, Hi: 0xE01EF, Stride: 1}, // Variation selectors supplement
+	},
+}
+
+// IsInvisibleRune reports whether r is an invisible or formatting character the invisible stage removes
+func IsInvisibleRune(r rune) bool {
+	return unicode.Is(invisibleRunes, r)
+}
+
+// applyInvisible removes invisible and formatting characters, or replaces each with the configured replacement
+// Rules versions before 3 leave them to transliteration, which replaced each with the invalid character replacement
+func (ws *WindowsSanitizer) applyInvisible(name string, steps trace, ctx templateContext) (string, trace) {
+	replacement := ws.expand(ws.replacements.Invisible, ctx)
+	runes := make([]rune, 0, len(name))
+	found := false
+	for _, r := range name {
+		if !IsInvisibleRune(r) {
+			runes = append(runes, r)
+			continue
+		}
+		runes = append(runes, []rune(replacement)...)
+		found = true
+	}
+
+	if !found {
+		return name, steps
+	}
+	cleaned := string(runes)
+	return cleaned, steps.add(RuleInvisibleCharacters, name, cleaned)
+}
@@ -0,0 +1,55 @@
+package sanitizer
+
+import (
+	"strings"
+)
+
+// isPortable reports whether a character belongs to the POSIX portable filename character set
+func isPortable(r rune) bool {
+	return (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '_' || r == '-'
+}
+
+// applyPortable replaces characters outside the POSIX portable set and a leading hyphen
+// The replacement is filtered to portable characters too, falling back to an underscore
+func (ws *WindowsSanitizer) applyPortable(name string, steps trace, ctx templateContext) (string, trace) {
+	replacement := strings.Map(func(r rune) rune {
+		if isPortable(r) {
+			return r
+		}
+		return -1
+	}, ws.expand(ws.replacements.InvalidChar, ctx))
+	if replacement == "" && ws.replacements.InvalidChar != "" {
+		replacement = "_"
+	}
+
+	var builder strings.Builder
+	replaced := false
+	for _, r := range name {
+		if isPortable(r) {
+			builder.WriteRune(r)
+			continue
+		}
+		builder.WriteString(replacement)
+		replaced = true
+	}
+	if replaced {
+		steps = steps.add(RulePortableChars, name, builder.String())
+		name = builder.String()
+	}
+
+	// A leading hyphen makes the name look like a command-line option
+	if strings.HasPrefix(name, "-") {
+		trimmed := strings.TrimLeft(name, "-")
+		prefix := strings.TrimLeft(replacement, "-")
+		if prefix == "" {
+			prefix = "_"
+		}
+		steps = steps.add(RuleLeadingHyphen, name, prefix+trimmed)
+		name = prefix + trimmed
+	}
+
+	if name == "" {
+		return ws.emptyName(name, ctx, steps)
+	}
+	return name, steps
+}
@@ -0,0 +1,118 @@
+package sanitizer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Profile describes the naming restrictions of a target file system or storage service
+// Every profile builds on the Windows rules; profiles only add restrictions
+type Profile struct {
+	Name          string   // Identifier used with --profile
+	Description   string   // One-line summary shown in help output
+	InvalidChars  []rune   // Characters that are replaced
+	ReservedNames []string // Case-insensitive names that get the reserved suffix
+	MaxNameLength int      // Maximum length of a single name
+	MaxPathLength int      // Maximum length of the root-relative path (0 = unlimited)
+	ShortNames    bool     // Convert names to 8.3 short names (upper case, 8 character base, 3 character extension)
+	PortableOnly  bool     // Restrict names to the POSIX portable filename character set [A-Za-z0-9._-]
+	CaseSensitive bool     // Names that differ only in case are distinct on the target
+}
+
+// MinNameLength is the smallest name length limit a run accepts
+// It is the longest UTF-8 encoding of a character, so truncation always keeps at least one whole character
+const MinNameLength = utf8.UTFMax
+
+// windowsDeviceNames are the reserved device names shared by every profile
+var windowsDeviceNames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// profiles holds the built-in profiles by name
+var profiles = map[string]Profile{
+	"windows": {
+		Name:          "windows",
+		Description:   "Windows (NTFS) naming rules",
+		InvalidChars:  windowsInvalidChars,
+		ReservedNames: windowsDeviceNames,
+		MaxNameLength: 255,
+	},
+	"onedrive": {
+		Name:          "onedrive",
+		Description:   "Windows rules plus OneDrive/SharePoint restrictions (# %, forms, .lock, desktop.ini, 400-character paths)",
+		InvalidChars:  append(append([]rune{}, windowsInvalidChars...), '#', '%'),
+		ReservedNames: append(append([]string{}, windowsDeviceNames...), "forms", ".lock", "desktop.ini"),
+		MaxNameLength: 255,
+		MaxPathLength: 400,
+	},
+	"fat32": {
+		Name:          "fat32",
+		Description:   "FAT32/exFAT for SD cards, car stereos and cameras (also replaces + , ; = [ ], 255-character paths)",
+		InvalidChars:  fatInvalidChars,
+		ReservedNames: windowsDeviceNames,
+		MaxNameLength: 255,
+		MaxPathLength: 255,
+	},
+	"fat32-8.3": {
+		Name:          "fat32-8.3",
+		Description:   "fat32 plus 8.3 short names for devices without long file name support",
+		InvalidChars:  fatInvalidChars,
+		ReservedNames: windowsDeviceNames,
+		MaxNameLength: 255, // Short name conversion enforces the 8.3 limits
+		MaxPathLength: 255,
+		ShortNames:    true,
+	},
+	"posix": {
+		Name:          "posix",
+		Description:   "POSIX portable filenames: only [A-Za-z0-9._-], no leading hyphen",
+		InvalidChars:  windowsInvalidChars,
+		ReservedNames: windowsDeviceNames,
+		MaxNameLength: 255,
+		PortableOnly:  true,
+		CaseSensitive: true,
+	},
+}
+
+// fatInvalidChars adds the characters that are invalid in FAT short names and trip up embedded devices
+var fatInvalidChars = append(append([]rune{}, windowsInvalidChars...), '+', ',', ';', '=', '[', ']')
+
+// DefaultProfile is the profile used when none is selected
+const DefaultProfile = "windows"
+
+// LookupProfile returns the built-in profile with the given name
+func LookupProfile(name string) (Profile, error) {
+	profile, exists := profiles[strings.ToLower(name)]
+	if !exists {
+		return Profile{}, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(ProfileNames(), ", "))
+	}
+	return profile, nil
+}
+
+// ProfileNames returns the names of all built-in profiles in alphabetical order
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WithProfile applies the restrictions of a profile
+func WithProfile(profile Profile) Option {
+	return func(ws *WindowsSanitizer) {
+		ws.invalidChars = profile.InvalidChars
+		ws.reservedNames = make(map[string]bool, len(profile.ReservedNames))
+		for _, name := range profile.ReservedNames {
+			ws.reservedNames[strings.ToUpper(name)] = true
+		}
+		ws.maxNameLength = profile.MaxNameLength
+		ws.maxPathLength = profile.MaxPathLength
+		ws.shortNames = profile.ShortNames
+		ws.portableOnly = profile.PortableOnly
+	}
+}
@@ -0,0 +1,147 @@
+package sanitizer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Replacements configures the strings substituted for offending input
+// Each string is a template that may reference {parent}, {depth}, {date} and {hash8}
+type Replacements struct {
+	InvalidChar    string // Replaces each invalid or unmappable character
+	EmptyName      string // Replaces names that end up empty
+	ReservedSuffix string // Appended to Windows reserved names
+	Invisible      string // Replaces each invisible or formatting character (empty = removed)
+}
+
+// DefaultReplacements returns the replacements used when none are configured
+func DefaultReplacements() Replacements {
+	return Replacements{
+		InvalidChar:    "_",
+		EmptyName:      "_empty_",
+		ReservedSuffix: "_",
+	}
+}
+
+// templateVariables describes the variables available in replacement templates
+var templateVariables = map[string]string{
+	"parent": "sanitized name of the parent folder",
+	"depth":  "depth of the folder below the root",
+	"date":   "date of the run (YYYY-MM-DD)",
+	"hash8":  "first 8 hex digits of the SHA-256 of the original folder path",
+}
+
+// templateVariablePattern matches a {variable} reference
+var templateVariablePattern = regexp.MustCompile(`\{([a-z0-9]+)\}`)
+
+// Validate checks that every template only uses known variables and valid literal characters
+func (r Replacements) Validate() error {
+	templates := []struct {
+		option   string
+		template string
+	}{
+		{"invalid character replacement", r.InvalidChar},
+		{"empty name replacement", r.EmptyName},
+		{"reserved name suffix", r.ReservedSuffix},
+		{"invisible character replacement", r.Invisible},
+	}
+
+	for _, t := range templates {
+		for _, match := range templateVariablePattern.FindAllStringSubmatch(t.template, -1) {
+			if _, known := templateVariables[match[1]]; !known {
+				return fmt.Errorf("%s %q: unknown variable {%s}", t.option, t.template, match[1])
+			}
+		}
+
+		literal := templateVariablePattern.ReplaceAllString(t.template, "")
+		for _, char := range literal {
+			if char < 32 || IsViolatingRune(char) {
+				return fmt.Errorf("%s %q: character %q is not allowed in folder names", t.option, t.template, char)
+			}
+		}
+	}
+
+	if strings.TrimSpace(r.EmptyName) == "" {
+		return fmt.Errorf("empty name replacement must not be blank")
+	}
+	if r.ReservedSuffix == "" || strings.HasSuffix(r.ReservedSuffix, ".") || strings.HasSuffix(r.ReservedSuffix, " ") {
+		return fmt.Errorf("reserved name suffix %q must not be empty or end with a period or space", r.ReservedSuffix)
+	}
+
+	return nil
+}
+
+// TemplateVariables returns the variable names and descriptions available in replacement templates
+func TemplateVariables() map[string]string {
+	variables := make(map[string]string, len(templateVariables))
+	for name, description := range templateVariables {
+		variables[name] = description
+	}
+	return variables
+}
+
+// templateContext holds the values replacement templates are expanded with
+type templateContext struct {
+	folder interfaces.FolderInfo
+	date   string
+}
+
+// expand substitutes the variables of a validated template
+func (ws *WindowsSanitizer) expand(template string, ctx templateContext) string {
+	if !strings.Contains(template, "{") {
+		return template
+	}
+
+	return templateVariablePattern.ReplaceAllStringFunc(template, func(reference string) string {
+		switch reference[1 : len(reference)-1] {
+		case "parent":
+			return parentName(ctx.folder)
+		case "depth":
+			return strconv.Itoa(ctx.folder.Depth)
+		case "date":
+			return ctx.date
+		case "hash8":
+			source := ctx.folder.Path
+			if source == "" {
+				source = ctx.folder.Name
+			}
+			sum := sha256.Sum256([]byte(source))
+			return hex.EncodeToString(sum[:])[:8]
+		default:
+			return reference
+		}
+	})
+}
+
+// newTemplateContext captures the values templates are expanded with for one folder
+func (ws *WindowsSanitizer) newTemplateContext(folder interfaces.FolderInfo) templateContext {
+	return templateContext{
+		folder: folder,
+		date:   ws.now().Format("2006-01-02"),
+	}
+}
+
+// parentName returns the parent folder's name sanitized with the default rules
+// Parents are processed after their children, so the sanitized form is what the parent will be called
+func parentName(folder interfaces.FolderInfo) string {
+	if folder.Parent == "" {
+		return ""
+	}
+
+	base := filepath.Base(folder.Parent)
+	if base == "." || base == string(filepath.Separator) || strings.HasSuffix(base, ":"+string(filepath.Separator)) {
+		return ""
+	}
+
+	return defaultSanitizer.SanitizeName(base)
+}
+
+// defaultSanitizer sanitizes template inputs without applying custom templates itself
+var defaultSanitizer = NewWindowsSanitizer()
@@ -0,0 +1,38 @@
+package sanitizer
+
+import "fmt"
+
+// CurrentRulesVersion is the rules version of this release
+// Any change to the names the rules produce (transliteration tables, truncation, suffixes) bumps it,
+// and the behavior of every earlier version stays available through WithRulesVersion.
+//
+// Versions:
+//
+//	1: Latin transliteration, character replacement, reserved names and length limits as first released
+//	2: Names over the length limit are cut instead of ending in "...", which re-introduced trailing periods
+//	3: Invisible and formatting characters (zero-width, bidirectional controls) are removed instead of replaced
+const CurrentRulesVersion = 3
+
+// LookupRulesVersion checks that a pinned rules version is known to this release; 0 selects CurrentRulesVersion
+func LookupRulesVersion(version int) (int, error) {
+	if version == 0 {
+		return CurrentRulesVersion, nil
+	}
+	if version < 1 || version > CurrentRulesVersion {
+		return 0, fmt.Errorf("unknown rules version %d (this release supports 1 to %d)", version, CurrentRulesVersion)
+	}
+	return version, nil
+}
+
+// WithRulesVersion reproduces the names of an earlier rules version; it must pass LookupRulesVersion
+// Pinning a version guarantees that re-running the tool never renames already-sanitized content differently.
+func WithRulesVersion(version int) Option {
+	return func(ws *WindowsSanitizer) {
+		ws.rulesVersion = version
+	}
+}
+
+// RulesVersion returns the rules version the sanitizer reproduces
+func (ws *WindowsSanitizer) RulesVersion() int {
+	return ws.rulesVersion
+}
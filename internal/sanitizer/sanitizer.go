@@ -3,11 +3,16 @@
 package sanitizer
 
 import (
+	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 	"unicode"
+	"unicode/utf8"
 
-	"sanitize/internal/interfaces"
+	"golang.org/x/text/encoding"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
 )
 
 // WindowsSanitizer implements the FolderSanitizer interface for Windows compatibility
@@ -15,114 +20,371 @@ import (
 type WindowsSanitizer struct {
 	// invalidChars contains characters that are not allowed in Windows folder names
 	invalidChars []rune
-	// reservedNames contains case-insensitive reserved names in Windows
+	// reservedNames contains reserved names, upper-cased for case-insensitive lookup
 	reservedNames map[string]bool
 	// controlCharsRegex matches ASCII control characters (0-31)
 	controlCharsRegex *regexp.Regexp
 	// maxNameLength defines the maximum allowed folder name length
 	maxNameLength int
+	// maxPathLength limits the root-relative path length (0 = unlimited)
+	maxPathLength int
+	// pathPrefix is where the tree will be copied to; path lengths are planned below it ("" = the current root)
+	pathPrefix string
+	// shortNames converts names to 8.3 short names
+	shortNames bool
+	// portableOnly restricts names to the POSIX portable filename character set
+	portableOnly bool
+	// replacements holds the templates substituted for offending input
+	replacements Replacements
+	// now provides the run date for templates
+	now func() time.Time
+	// whitespace configures the optional whitespace normalization rules
+	whitespace Whitespace
+	// slug converts names to lower-case, hyphen-separated slugs for web servers
+	slug bool
+	// localeTable holds the locale-specific transliterations of lower-case letters (nil = generic transliteration only)
+	localeTable map[rune]string
+	// confusables maps characters that look like ASCII letters and digits to them
+	confusables bool
+	// emoji selects how emoji and pictographs are handled ("" = replaced like other unmappable characters)
+	emoji string
+	// truncation selects how names over the length limit are shortened ("" = the default of the rules version)
+	truncation string
+	// stages lists the pipeline stages to apply in order (nil = every stage the configuration enables, see Stages)
+	stages []string
+	// sourceEncodings decode names that aren't valid UTF-8, tried in order (nil = not decoded)
+	sourceEncodings []encoding.Encoding
+	// rulesVersion selects the behavior of a release, so pinned names never change (see CurrentRulesVersion)
+	rulesVersion int
+}
+
+// Option configures optional WindowsSanitizer behavior
+type Option func(*WindowsSanitizer)
+
+// WithReplacements sets the replacement templates; they must pass Replacements.Validate
+func WithReplacements(replacements Replacements) Option {
+	return func(ws *WindowsSanitizer) {
+		ws.replacements = replacements
+	}
+}
+
+// WithPathPrefix plans path lengths for the tree copied to prefix instead of where it is now
+// The prefix counts against the profile's path limit, or the classic Windows MAX_PATH for profiles without one.
+func WithPathPrefix(prefix string) Option {
+	return func(ws *WindowsSanitizer) {
+		ws.pathPrefix = prefix
+	}
+}
+
+// legacyMaxPath is the longest path Windows APIs accept without the \\?\ prefix: MAX_PATH minus the terminating null
+const legacyMaxPath = 259
+
+// PathBudget returns the length left for root-relative paths under a path limit once the tree is copied to prefix
+// 0 means unlimited; a result below 0 means the prefix alone exceeds the limit. An empty prefix leaves the limit as is.
+func PathBudget(maxPathLength int, prefix string) int {
+	prefix = strings.TrimRight(prefix, `\/`)
+	if prefix == "" {
+		return maxPathLength
+	}
+	if maxPathLength == 0 {
+		maxPathLength = legacyMaxPath
+	}
+	budget := maxPathLength - utf8.RuneCountInString(prefix) - 1 // The prefix and the separator after it
+	if budget == 0 {
+		return -1 // Not to be mistaken for unlimited
+	}
+	return budget
+}
+
+// windowsInvalidChars contains characters that are not allowed in Windows folder names
+var windowsInvalidChars = []rune{'<', '>', ':', '"', '|', '?', '*', '\\', '/'}
+
+// IsViolatingRune reports whether a printable character is invalid in Windows names or non-ASCII
+// Used to keep the offending characters visible in anonymized reports
+func IsViolatingRune(r rune) bool {
+	if r > 127 {
+		return true
+	}
+	for _, invalid := range windowsInvalidChars {
+		if r == invalid {
+			return true
+		}
+	}
+	return false
 }
 
+// controlCharsRegex matches ASCII control characters (0-31)
+var controlCharsRegex = regexp.MustCompile(`[\x00-\x1F]`)
+
 // NewWindowsSanitizer creates a new instance of WindowsSanitizer with default Windows rules
 // This constructor initializes all the Windows-specific rules and constraints
-func NewWindowsSanitizer() interfaces.FolderSanitizer {
-	return &WindowsSanitizer{
-		invalidChars: []rune{'<', '>', ':', '"', '|', '?', '*', '\\', '/'},
-		reservedNames: map[string]bool{
-			"CON": true, "PRN": true, "AUX": true, "NUL": true,
-			"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
-			"COM6": true, "COM7": true, "COM8": true, "COM9": true,
-			"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
-			"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
-		},
-		controlCharsRegex: regexp.MustCompile(`[\x00-\x1F]`),
-		maxNameLength:     255,
+func NewWindowsSanitizer(options ...Option) interfaces.FolderSanitizer {
+	ws := &WindowsSanitizer{
+		controlCharsRegex: controlCharsRegex,
+		replacements:      DefaultReplacements(),
+		now:               time.Now,
+		rulesVersion:      CurrentRulesVersion,
+	}
+	WithProfile(profiles[DefaultProfile])(ws)
+
+	for _, option := range options {
+		option(ws)
+	}
+
+	return ws
+}
+
+// Rule identifiers reported by ExplainName
+// These names are stable so they can be used in scripts and reports
+const (
+	RuleEmptyName           = "empty-name"
+	RuleSourceEncoding      = "source-encoding"
+	RuleControlCharacters   = "control-characters"
+	RuleInvisibleCharacters = "invisible-characters"
+	RuleConfusables         = "confusables"
+	RuleEmoji               = "emoji"
+	RuleInvalidCharacters   = "invalid-characters"
+	RuleNonASCII            = "non-ascii"
+	RuleSurroundingSpaces   = "surrounding-spaces"
+	RuleTrailingPeriod      = "trailing-period-or-space"
+	RuleReservedName        = "reserved-name"
+	RuleMaxLength           = "max-length"
+	RuleMaxPathLength       = "max-path-length"
+	RuleShortName           = "short-name"
+	RulePortableChars       = "portable-characters"
+	RuleLeadingHyphen       = "leading-hyphen"
+	RuleCollapseSpaces      = "collapse-spaces"
+	RuleSpaceReplacement    = "space-replacement"
+	RuleSlug                = "slug"
+)
+
+// ruleDescriptions provides a human-readable explanation for each rule
+var ruleDescriptions = map[string]string{
+	RuleEmptyName:           "name is empty or contains only removable characters",
+	RuleSourceEncoding:      "name that isn't valid UTF-8 decoded from its source encoding (--source-encoding)",
+	RuleControlCharacters:   "control characters (ASCII 0-31) removed",
+	RuleInvisibleCharacters: "invisible and formatting characters (zero-width spaces and joiners, bidirectional controls) removed (or replaced with --invisible-replacement)",
+	RuleConfusables:         "look-alike characters (Cyrillic or Greek letters, fullwidth forms) replaced with their ASCII equivalents (--confusables)",
+	RuleEmoji:               "emoji and pictographs removed or replaced with their names (--emoji)",
+	RuleInvalidCharacters:   `invalid characters (< > : " | ? * \ / plus any the profile adds) replaced (underscore by default)`,
+	RuleNonASCII:            "non-ASCII characters converted to closest ASCII equivalent (or as --translit-locale spells them)",
+	RuleSurroundingSpaces:   "leading/trailing spaces trimmed",
+	RuleTrailingPeriod:      "trailing periods and spaces removed",
+	RuleReservedName:        "reserved name suffixed (underscore by default)",
+	RuleMaxLength:           "name truncated to the maximum length",
+	RuleMaxPathLength:       "name shortened so the path fits the profile's maximum path length (below --path-budget-prefix, if set)",
+	RuleShortName:           "name converted to an upper-case 8.3 short name",
+	RulePortableChars:       "characters outside the POSIX portable set [A-Za-z0-9._-] replaced",
+	RuleLeadingHyphen:       "leading hyphen replaced so the name can't be mistaken for an option",
+	RuleCollapseSpaces:      "runs of whitespace collapsed into a single space (--collapse-spaces)",
+	RuleSpaceReplacement:    "spaces replaced (--space-replacement)",
+	RuleSlug:                "name converted to a lower-case, hyphen-separated slug (--slug)",
+}
+
+// RuleDescription returns the human-readable explanation of a rule identifier
+func RuleDescription(rule string) string {
+	if description, exists := ruleDescriptions[rule]; exists {
+		return description
 	}
+	return rule
 }
 
 // SanitizeName sanitizes a folder name according to Windows naming rules
 // This method implements the FolderSanitizer interface and ensures Windows compatibility
 func (ws *WindowsSanitizer) SanitizeName(name string) string {
+	sanitized, _ := ws.ExplainName(name)
+	return sanitized
+}
+
+// ExplainName sanitizes a folder name and reports which rules changed it
+// The returned rules are listed in the order they were applied
+func (ws *WindowsSanitizer) ExplainName(name string) (string, []string) {
+	return ws.ExplainFolder(interfaces.FolderInfo{Name: name})
+}
+
+// ExplainFolder sanitizes a folder's name using its location for replacement templates
+// This method implements the FolderExplainer interface
+func (ws *WindowsSanitizer) ExplainFolder(folder interfaces.FolderInfo) (string, []string) {
+	name, steps := ws.traceFolder(folder)
+	return name, steps.rules()
+}
+
+// TraceName sanitizes a folder name and reports what each rule changed
+// This method implements the NameTracer interface
+func (ws *WindowsSanitizer) TraceName(name string) (string, []interfaces.RuleStep) {
+	return ws.traceFolder(interfaces.FolderInfo{Name: name})
+}
+
+// traceFolder applies the rules to a folder's name in order and records every rule that changed it
+func (ws *WindowsSanitizer) traceFolder(folder interfaces.FolderInfo) (string, trace) {
+	var steps trace
+	name := folder.Name
+	ctx := ws.newTemplateContext(folder)
+
 	// Handle empty input
 	if name == "" {
-		return "_empty_"
+		return ws.emptyName(name, ctx, steps)
+	}
+
+	// Apply the pipeline stages in order, each to the result of the previous one
+	for _, stageName := range ws.activeStages() {
+		name, steps = pipelineStages[stageName].apply(ws, folder, name, steps, ctx)
+	}
+
+	return name, steps
+}
+
+// applyPathLength shortens the name so the root-relative path fits the profile's limit
+// Names are left alone when the folder's location is unknown or the parent path alone is too long
+func (ws *WindowsSanitizer) applyPathLength(folder interfaces.FolderInfo, name string, steps trace) (string, trace) {
+	limit := PathBudget(ws.maxPathLength, ws.pathPrefix)
+	if limit <= 0 || folder.Path == "" || folder.Depth < 1 {
+		return name, steps
 	}
 
-	// Remove control characters (ASCII 0-31)
-	name = ws.controlCharsRegex.ReplaceAllString(name, "")
+	// The root-relative path consists of the last Depth components of the folder path
+	components := strings.Split(filepath.ToSlash(folder.Path), "/")
+	if len(components) < folder.Depth {
+		return name, steps
+	}
+	parentLength := utf8.RuneCountInString(strings.Join(components[len(components)-folder.Depth:len(components)-1], "/"))
+	if parentLength > 0 {
+		parentLength++ // separator between parent and name
+	}
 
-	// Process each character for validity
-	name = ws.processCharacters(name)
+	available := limit - parentLength
+	if len(name) <= available || available < 1 {
+		return name, steps
+	}
+
+	shortened := strings.TrimRight(name[:available], ". ")
+	if ws.truncation != "" {
+		shortened = ws.truncate(name, available)
+	}
+	if shortened == "" {
+		return name, steps
+	}
 
-	// Apply Windows-specific rules
-	name = ws.applyWindowsRules(name)
+	return shortened, steps.add(RuleMaxPathLength, name, shortened)
+}
 
-	return name
+// emptyName returns the expanded empty-name replacement
+// Templates can expand to trailing periods or spaces (e.g. an empty {parent}), so those are trimmed too
+func (ws *WindowsSanitizer) emptyName(before string, ctx templateContext, steps trace) (string, trace) {
+	name := strings.TrimRight(strings.TrimSpace(ws.expand(ws.replacements.EmptyName, ctx)), ". ")
+	if name == "" {
+		name = DefaultReplacements().EmptyName
+	}
+	return name, steps.add(RuleEmptyName, before, name)
 }
 
 // processCharacters handles character-by-character processing for Unicode and invalid characters
 // This method converts Unicode to ASCII and replaces invalid characters
-func (ws *WindowsSanitizer) processCharacters(name string) string {
+// Both rules work on single characters, so the invalid character step is recorded as if it ran first
+func (ws *WindowsSanitizer) processCharacters(name string, steps trace, ctx templateContext) (string, trace) {
 	// Convert to runes for proper Unicode handling
 	runes := []rune(name)
+	replacement := []rune(ws.expand(ws.replacements.InvalidChar, ctx))
 	sanitized := make([]rune, 0, len(runes))
+	invalidOnly := make([]rune, 0, len(runes)) // Only invalid characters replaced, for the trace
+	foundInvalid, foundNonASCII := false, false
 
-	for _, r := range runes {
+	for i, r := range runes {
 		// Check if it's an invalid character
 		if ws.containsRune(ws.invalidChars, r) {
-			sanitized = append(sanitized, '_')
-		} else if r > 127 { // Non-ASCII character
+			sanitized = append(sanitized, replacement...)
+			invalidOnly = append(invalidOnly, replacement...)
+			foundInvalid = true
+			continue
+		}
+		invalidOnly = append(invalidOnly, r)
+		if r > 127 { // Non-ASCII character
+			// Locale-specific transliterations take precedence, e.g. "ü" -> "ue" for German
+			if ascii, ok := ws.localeTransliteration(runes, i); ok {
+				sanitized = append(sanitized, []rune(ascii)...)
+				foundNonASCII = true
+				continue
+			}
 			// Convert Unicode to closest ASCII equivalent
 			ascii := ws.unicodeToASCII(r)
 			if ascii != 0 {
 				sanitized = append(sanitized, ascii)
 			} else {
-				sanitized = append(sanitized, '_')
+				sanitized = append(sanitized, replacement...)
 			}
+			foundNonASCII = true
 		} else {
 			sanitized = append(sanitized, r)
 		}
 	}
 
-	return string(sanitized)
+	if foundInvalid {
+		steps = steps.add(RuleInvalidCharacters, name, string(invalidOnly))
+	}
+	if foundNonASCII {
+		steps = steps.add(RuleNonASCII, string(invalidOnly), string(sanitized))
+	}
+
+	return string(sanitized), steps
 }
 
 // applyWindowsRules applies Windows-specific naming rules
-// This method handles trimming, reserved names, and length limits
-func (ws *WindowsSanitizer) applyWindowsRules(name string) string {
+// This method handles trimming and reserved names
+func (ws *WindowsSanitizer) applyWindowsRules(name string, steps trace, ctx templateContext) (string, trace) {
 	// Remove leading/trailing spaces
-	name = strings.TrimSpace(name)
+	if trimmed := strings.TrimSpace(name); trimmed != name {
+		steps = steps.add(RuleSurroundingSpaces, name, trimmed)
+		name = trimmed
+	}
 
 	// If empty after trimming, use placeholder
 	if name == "" {
-		return "_empty_"
+		return ws.emptyName(name, ctx, steps)
 	}
 
 	// Remove trailing periods and spaces (Windows doesn't allow this)
-	name = strings.TrimRight(name, ". ")
+	if trimmed := strings.TrimRight(name, ". "); trimmed != name {
+		steps = steps.add(RuleTrailingPeriod, name, trimmed)
+		name = trimmed
+	}
 
 	// If empty after trimming periods/spaces, use placeholder
 	if name == "" {
-		return "_empty_"
+		return ws.emptyName(name, ctx, steps)
 	}
 
 	// Check for reserved names (case insensitive)
 	upperName := strings.ToUpper(name)
 	if ws.reservedNames[upperName] {
-		name = name + "_"
-	}
-
-	// Handle length limit
-	if len(name) > ws.maxNameLength {
-		name = name[:ws.maxNameLength-3] + "..."
+		suffixed := name + ws.expand(ws.replacements.ReservedSuffix, ctx)
+		steps = steps.add(RuleReservedName, name, suffixed)
+		name = suffixed
 	}
 
 	// Final check - if result contains only spaces, replace with placeholder
 	if strings.TrimSpace(name) == "" {
-		return "_empty_"
+		return ws.emptyName(name, ctx, steps)
 	}
 
-	return name
+	return name, steps
+}
+
+// applyMaxLength truncates names longer than the maximum name length with the configured strategy
+func (ws *WindowsSanitizer) applyMaxLength(name string, steps trace) (string, trace) {
+	if len(name) <= ws.maxNameLength {
+		return name, steps
+	}
+
+	truncated := ws.truncate(name, ws.maxNameLength)
+	if ws.rulesVersion < 2 && ws.truncation == "" {
+		// Rules version 1 appended "...", which brought back the trailing periods Windows rejects
+		// A limit too short to hold the ellipsis gets a plain byte cut instead
+		truncated = name[:ws.maxNameLength]
+		if ws.maxNameLength >= len(legacyEllipsis) {
+			truncated = name[:ws.maxNameLength-len(legacyEllipsis)] + legacyEllipsis
+		}
+	}
+	return truncated, steps.add(RuleMaxLength, name, truncated)
 }
 
 // containsRune checks if a slice of runes contains a specific rune
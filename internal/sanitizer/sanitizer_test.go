@@ -3,10 +3,12 @@
 package sanitizer_test
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
-	"sanitize/internal/sanitizer"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/sanitizer"
 )
 
 // TestWindowsSanitizer_SanitizeName tests the main sanitization functionality
@@ -146,8 +148,8 @@ func TestWindowsSanitizer_SanitizeName(t *testing.T) {
 		// Length limits
 		{
 			name:     "very long name",
-			input:    strings.Repeat("a", 300),         // 300 characters
-			expected: strings.Repeat("a", 252) + "...", // 255 characters total
+			input:    strings.Repeat("a", 300), // 300 characters
+			expected: strings.Repeat("a", 255),
 		},
 
 		// Complex real-world examples
@@ -271,6 +273,297 @@ func TestWindowsSanitizer_ReservedNames(t *testing.T) {
 	}
 }
 
+// TestWindowsSanitizer_ExplainName tests that the rules which changed a name are reported
+// This test ensures rule attribution matches the transformations actually applied
+func TestWindowsSanitizer_ExplainName(t *testing.T) {
+	s := sanitizer.NewWindowsSanitizer()
+	explainer, ok := s.(interfaces.NameExplainer)
+	if !ok {
+		t.Fatal("WindowsSanitizer should implement NameExplainer")
+	}
+
+	testCases := []struct {
+		name          string
+		input         string
+		expectedName  string
+		expectedRules []string
+	}{
+		{
+			name:          "valid name",
+			input:         "ValidFolder",
+			expectedName:  "ValidFolder",
+			expectedRules: nil,
+		},
+		{
+			name:          "invalid and unicode",
+			input:         "café<1>",
+			expectedName:  "cafe_1_",
+			expectedRules: []string{sanitizer.RuleInvalidCharacters, sanitizer.RuleNonASCII},
+		},
+		{
+			name:          "trailing period on reserved name",
+			input:         "con.",
+			expectedName:  "con_",
+			expectedRules: []string{sanitizer.RuleTrailingPeriod, sanitizer.RuleReservedName},
+		},
+		{
+			name:          "control characters only",
+			input:         "\x01\x02",
+			expectedName:  "_empty_",
+			expectedRules: []string{sanitizer.RuleControlCharacters, sanitizer.RuleEmptyName},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, rules := explainer.ExplainName(tc.input)
+			if result != tc.expectedName {
+				t.Errorf("ExplainName(%q) name = %q, expected %q", tc.input, result, tc.expectedName)
+			}
+			if strings.Join(rules, ",") != strings.Join(tc.expectedRules, ",") {
+				t.Errorf("ExplainName(%q) rules = %v, expected %v", tc.input, rules, tc.expectedRules)
+			}
+			if result != s.SanitizeName(tc.input) {
+				t.Errorf("ExplainName(%q) disagrees with SanitizeName", tc.input)
+			}
+		})
+	}
+}
+
+// TestWindowsSanitizer_TraceName tests that every step records what its rule changed
+func TestWindowsSanitizer_TraceName(t *testing.T) {
+	tracer, ok := sanitizer.NewWindowsSanitizer().(interfaces.NameTracer)
+	if !ok {
+		t.Fatal("WindowsSanitizer should implement NameTracer")
+	}
+
+	name, steps := tracer.TraceName(" café<1>. ")
+	expected := []interfaces.RuleStep{
+		{Rule: sanitizer.RuleInvalidCharacters, Before: " café<1>. ", After: " café_1_. "},
+		{Rule: sanitizer.RuleNonASCII, Before: " café_1_. ", After: " cafe_1_. "},
+		{Rule: sanitizer.RuleSurroundingSpaces, Before: " cafe_1_. ", After: "cafe_1_."},
+		{Rule: sanitizer.RuleTrailingPeriod, Before: "cafe_1_.", After: "cafe_1_"},
+	}
+	if name != "cafe_1_" || len(steps) != len(expected) {
+		t.Fatalf("TraceName() = %q, %+v", name, steps)
+	}
+	for i := range expected {
+		if steps[i] != expected[i] {
+			t.Errorf("Step %d = %+v, expected %+v", i+1, steps[i], expected[i])
+		}
+	}
+
+	if _, steps := tracer.TraceName("ValidFolder"); len(steps) != 0 {
+		t.Errorf("Expected no steps for a compliant name, got %+v", steps)
+	}
+}
+
+// TestWindowsSanitizer_ReplacementTemplates tests that replacement templates expand folder variables
+func TestWindowsSanitizer_ReplacementTemplates(t *testing.T) {
+	s := sanitizer.NewWindowsSanitizer(sanitizer.WithReplacements(sanitizer.Replacements{
+		InvalidChar:    "-",
+		EmptyName:      "untitled-{parent}-{depth}",
+		ReservedSuffix: "-{hash8}",
+	}))
+	explainer := s.(interfaces.FolderExplainer)
+
+	folder := interfaces.FolderInfo{Path: "/data/My:Photos/...", Name: "...", Depth: 2, Parent: "/data/My:Photos"}
+	if name, _ := explainer.ExplainFolder(folder); name != "untitled-My_Photos-2" {
+		t.Errorf("Expected empty name to expand to untitled-My_Photos-2, got %q", name)
+	}
+
+	if name := s.SanitizeName("a<b"); name != "a-b" {
+		t.Errorf("Expected invalid character replacement a-b, got %q", name)
+	}
+
+	reserved, _ := explainer.ExplainFolder(interfaces.FolderInfo{Path: "/data/CON", Name: "CON", Parent: "/data"})
+	if !strings.HasPrefix(reserved, "CON-") || len(reserved) != len("CON-")+8 {
+		t.Errorf("Expected reserved name with hash suffix, got %q", reserved)
+	}
+}
+
+// TestReplacements_Validate tests that invalid replacement templates are rejected
+func TestReplacements_Validate(t *testing.T) {
+	if err := sanitizer.DefaultReplacements().Validate(); err != nil {
+		t.Errorf("Default replacements should be valid: %v", err)
+	}
+
+	invalid := map[string]sanitizer.Replacements{
+		"unknown variable":  {InvalidChar: "{nope}", EmptyName: "x", ReservedSuffix: "_"},
+		"invalid character": {InvalidChar: ":", EmptyName: "x", ReservedSuffix: "_"},
+		"blank empty name":  {InvalidChar: "_", EmptyName: " ", ReservedSuffix: "_"},
+		"empty suffix":      {InvalidChar: "_", EmptyName: "x", ReservedSuffix: ""},
+		"trailing period":   {InvalidChar: "_", EmptyName: "x", ReservedSuffix: "."},
+	}
+	for name, replacements := range invalid {
+		if err := replacements.Validate(); err == nil {
+			t.Errorf("%s: expected Validate() to fail", name)
+		}
+	}
+}
+
+// TestWindowsSanitizer_OneDriveProfile tests the extra OneDrive/SharePoint restrictions
+func TestWindowsSanitizer_OneDriveProfile(t *testing.T) {
+	profile, err := sanitizer.LookupProfile("onedrive")
+	if err != nil {
+		t.Fatalf("LookupProfile() returned error: %v", err)
+	}
+	s := sanitizer.NewWindowsSanitizer(sanitizer.WithProfile(profile))
+
+	testCases := map[string]string{
+		"50% #1":      "50_ _1",
+		"forms":       "forms_",
+		".lock":       ".lock_",
+		"Desktop.ini": "Desktop.ini_",
+		"CON":         "CON_",
+		" Reports ":   "Reports",
+		"Reports":     "Reports",
+	}
+	for input, expected := range testCases {
+		if result := s.SanitizeName(input); result != expected {
+			t.Errorf("SanitizeName(%q) = %q, expected %q", input, result, expected)
+		}
+	}
+
+	// The default profile keeps # and % as they are valid on Windows
+	if result := sanitizer.NewWindowsSanitizer().SanitizeName("50% #1"); result != "50% #1" {
+		t.Errorf("Default profile should not replace # or %%, got %q", result)
+	}
+
+	// Names are shortened so the root-relative path stays within 400 characters
+	parent := strings.Repeat("p", 390)
+	folder := interfaces.FolderInfo{
+		Path:   "/mnt/onedrive/" + parent + "/" + strings.Repeat("n", 20),
+		Name:   strings.Repeat("n", 20),
+		Depth:  2,
+		Parent: "/mnt/onedrive/" + parent,
+	}
+	name, rules := s.(interfaces.FolderExplainer).ExplainFolder(folder)
+	if name != strings.Repeat("n", 9) {
+		t.Errorf("Expected name shortened to 9 characters, got %d", len(name))
+	}
+	if strings.Join(rules, ",") != sanitizer.RuleMaxPathLength {
+		t.Errorf("Expected rules [%s], got %v", sanitizer.RuleMaxPathLength, rules)
+	}
+
+	if _, err := sanitizer.LookupProfile("unknown"); err == nil {
+		t.Error("Expected LookupProfile() to fail for an unknown profile")
+	}
+}
+
+// TestWindowsSanitizer_PathPrefix tests that path lengths are planned below the destination prefix
+func TestWindowsSanitizer_PathPrefix(t *testing.T) {
+	prefix := `\\server\share\dept\archive\2024\`
+	s := sanitizer.NewWindowsSanitizer(sanitizer.WithPathPrefix(prefix))
+
+	// Without a profile limit the classic Windows limit of 259 characters applies to prefix, separator and path
+	parent := strings.Repeat("p", 200)
+	folder := interfaces.FolderInfo{
+		Path:   "/mnt/data/" + parent + "/" + strings.Repeat("n", 40),
+		Name:   strings.Repeat("n", 40),
+		Depth:  2,
+		Parent: "/mnt/data/" + parent,
+	}
+	name, rules := s.(interfaces.FolderExplainer).ExplainFolder(folder)
+	expected := 259 - len(`\\server\share\dept\archive\2024`) - 1 - len(parent) - 1
+	if len(name) != expected {
+		t.Errorf("Expected name shortened to %d characters, got %d", expected, len(name))
+	}
+	if strings.Join(rules, ",") != sanitizer.RuleMaxPathLength {
+		t.Errorf("Expected rules [%s], got %v", sanitizer.RuleMaxPathLength, rules)
+	}
+
+	// Without a prefix the default profile doesn't limit paths
+	if name, _ := sanitizer.NewWindowsSanitizer().(interfaces.FolderExplainer).ExplainFolder(folder); name != folder.Name {
+		t.Errorf("Expected no path limit without a prefix, got %q", name)
+	}
+
+	budgets := []struct {
+		limit  int
+		prefix string
+		want   int
+	}{
+		{400, "", 400},
+		{0, "", 0},
+		{400, "/mnt/archive/", 387},
+		{0, "/mnt/archive", 246},
+		{10, "/mnt/archive", -3},
+		{13, "/mnt/archive", -1},
+	}
+	for _, tt := range budgets {
+		if got := sanitizer.PathBudget(tt.limit, tt.prefix); got != tt.want {
+			t.Errorf("PathBudget(%d, %q) = %d, expected %d", tt.limit, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+// TestWindowsSanitizer_FAT32Profiles tests the FAT32 character set and the optional 8.3 mode
+func TestWindowsSanitizer_FAT32Profiles(t *testing.T) {
+	testCases := map[string]map[string]string{
+		"fat32": {
+			"a+b=c[1]":      "a_b_c_1_",
+			"Rock; Roll, 2": "Rock_ Roll_ 2",
+			"trailing. ":    "trailing",
+			"Long Name":     "Long Name",
+		},
+		"fat32-8.3": {
+			"My Holiday Photos": "MYHOLIDA",
+			"report.final.doc":  "REPORTFI.DOC",
+			"a+b":               "A_B",
+			"con":               "CON_",
+			"MUSIC":             "MUSIC",
+			"Über":              "UBER",
+		},
+	}
+
+	for profileName, cases := range testCases {
+		profile, err := sanitizer.LookupProfile(profileName)
+		if err != nil {
+			t.Fatalf("LookupProfile(%q) returned error: %v", profileName, err)
+		}
+		s := sanitizer.NewWindowsSanitizer(sanitizer.WithProfile(profile))
+
+		for input, expected := range cases {
+			if result := s.SanitizeName(input); result != expected {
+				t.Errorf("%s: SanitizeName(%q) = %q, expected %q", profileName, input, result, expected)
+			}
+		}
+	}
+}
+
+// TestWindowsSanitizer_POSIXProfile tests the POSIX portable filename character set
+func TestWindowsSanitizer_POSIXProfile(t *testing.T) {
+	profile, err := sanitizer.LookupProfile("posix")
+	if err != nil {
+		t.Fatalf("LookupProfile() returned error: %v", err)
+	}
+	s := sanitizer.NewWindowsSanitizer(sanitizer.WithProfile(profile))
+
+	testCases := map[string]string{
+		"ok.name_1-2": "ok.name_1-2",
+		"My File (1)": "My_File__1_",
+		"-rf":         "_rf",
+		"--x y":       "_x_y",
+		"café":        "cafe",
+		"50% #1":      "50___1",
+	}
+	for input, expected := range testCases {
+		if result := s.SanitizeName(input); result != expected {
+			t.Errorf("SanitizeName(%q) = %q, expected %q", input, result, expected)
+		}
+	}
+
+	// A replacement outside the portable set falls back to an underscore
+	s = sanitizer.NewWindowsSanitizer(sanitizer.WithProfile(profile), sanitizer.WithReplacements(sanitizer.Replacements{
+		InvalidChar:    " ",
+		EmptyName:      "_empty_",
+		ReservedSuffix: "_",
+	}))
+	if result := s.SanitizeName("a b"); result != "a_b" {
+		t.Errorf("Expected non-portable replacement to fall back to underscore, got %q", result)
+	}
+}
+
 // BenchmarkWindowsSanitizer_SanitizeName benchmarks the sanitization performance
 // This benchmark helps ensure the sanitizer performs efficiently
 func BenchmarkWindowsSanitizer_SanitizeName(b *testing.B) {
@@ -295,3 +588,401 @@ func BenchmarkWindowsSanitizer_LongName(b *testing.B) {
 		s.SanitizeName(longName)
 	}
 }
+
+// TestWindowsSanitizer_RulesVersion1 pins the names of rules version 1
+// These names must never change; a release that sanitizes differently needs a new rules version instead
+func TestWindowsSanitizer_RulesVersion1(t *testing.T) {
+	s := sanitizer.NewWindowsSanitizer(sanitizer.WithRulesVersion(1))
+
+	golden := map[string]string{
+		"Café Münchën":    "Cafe Munchen",
+		"a<b>c":           "a_b_c",
+		"CON":             "CON_",
+		"trailing. ":      "trailing",
+		"  spaced  ":      "spaced",
+		"Straße":          "Straae",
+		"Ærøskøbing":      "Aroskobing",
+		"naïve:résumé?":   "naive_resume_",
+		"日本語":             "aaa",
+		"tab\there":       "tabhere",
+		"zero\u200bwidth": "zero_width",
+	}
+	golden[strings.Repeat("a", 300)] = strings.Repeat("a", 252) + "..."
+	for input, want := range golden {
+		if got := s.SanitizeName(input); got != want {
+			t.Errorf("SanitizeName(%q) = %q, rules version 1 requires %q", input, got, want)
+		}
+	}
+
+	if version, err := sanitizer.LookupRulesVersion(0); err != nil || version != sanitizer.CurrentRulesVersion {
+		t.Errorf("Expected 0 to select the current rules version, got %d (error %v)", version, err)
+	}
+	for _, version := range []int{-1, sanitizer.CurrentRulesVersion + 1} {
+		if _, err := sanitizer.LookupRulesVersion(version); err == nil {
+			t.Errorf("Expected rules version %d to be rejected", version)
+		}
+	}
+	if version := s.(*sanitizer.WindowsSanitizer).RulesVersion(); version != 1 {
+		t.Errorf("Expected the sanitizer to reproduce rules version 1, got %d", version)
+	}
+}
+
+// TestWindowsSanitizer_InvisibleCharacters tests that zero-width and bidirectional control characters are removed or replaced
+func TestWindowsSanitizer_InvisibleCharacters(t *testing.T) {
+	tests := []struct {
+		input       string
+		replacement string
+		want        string
+	}{
+		{"Foo\u200bBar", "", "FooBar"},
+		{"Invoice\u202egpj.exe", "", "Invoicegpj.exe"},
+		{"\ufeffReports\u2066 2024\u2069", "", "Reports 2024"},
+		{"a\u200db\u00adc\U000E0041", "", "abc"},
+		{"Foo\u200bBar", "-", "Foo-Bar"},
+		{"\u200b\u200c", "", "_empty_"},
+	}
+	for _, tt := range tests {
+		replacements := sanitizer.DefaultReplacements()
+		replacements.Invisible = tt.replacement
+		s := sanitizer.NewWindowsSanitizer(sanitizer.WithReplacements(replacements))
+		if got := s.SanitizeName(tt.input); got != tt.want {
+			t.Errorf("SanitizeName(%q) with replacement %q = %q, want %q", tt.input, tt.replacement, got, tt.want)
+		}
+	}
+
+	_, rules := sanitizer.NewWindowsSanitizer().(interfaces.NameExplainer).ExplainName("Café\u200b")
+	if want := []string{sanitizer.RuleInvisibleCharacters, sanitizer.RuleNonASCII}; !reflect.DeepEqual(rules, want) {
+		t.Errorf("ExplainName() rules = %v, want %v", rules, want)
+	}
+
+	// Pinned earlier versions replace them like any other unmappable character, unless the stage is listed
+	pinned := sanitizer.NewWindowsSanitizer(sanitizer.WithRulesVersion(2))
+	if got := pinned.SanitizeName("Foo\u200bBar"); got != "Foo_Bar" {
+		t.Errorf("SanitizeName() with rules version 2 = %q, want %q", got, "Foo_Bar")
+	}
+	listed := sanitizer.NewWindowsSanitizer(sanitizer.WithRulesVersion(2), sanitizer.WithStages([]string{sanitizer.StageInvisible}))
+	if got := listed.SanitizeName("Foo\u200bBar"); got != "FooBar" {
+		t.Errorf("SanitizeName() with the invisible stage listed = %q, want %q", got, "FooBar")
+	}
+}
+
+// TestWindowsSanitizer_TransliterationLocale tests that locale tables take precedence over the generic transliteration
+func TestWindowsSanitizer_TransliterationLocale(t *testing.T) {
+	tests := []struct {
+		locale string
+		input  string
+		want   string
+	}{
+		{"", "Grüße aus Köln", "Gruae aus Koln"},
+		{"de", "Grüße aus Köln", "Gruesse aus Koeln"},
+		{"de-CH", "Übung", "Uebung"},
+		{"de", "MÜLLER", "MUELLER"},
+		{"de", "Café", "Cafe"}, // Letters outside the table use the generic transliteration
+		{"da_DK.UTF-8", "Ærø Århus", "Aeroe Aarhus"},
+		{"sv", "Åsa Öberg", "Aasa Oeberg"},
+	}
+	for _, tt := range tests {
+		s := sanitizer.NewWindowsSanitizer(sanitizer.WithTransliterationLocale(tt.locale))
+		if got := s.SanitizeName(tt.input); got != tt.want {
+			t.Errorf("SanitizeName(%q) with locale %q = %q, want %q", tt.input, tt.locale, got, tt.want)
+		}
+	}
+
+	if err := sanitizer.ValidateTransliterationLocale("de_AT"); err != nil {
+		t.Errorf("ValidateTransliterationLocale() returned error: %v", err)
+	}
+	if err := sanitizer.ValidateTransliterationLocale("tlh"); err == nil {
+		t.Error("Expected a locale without a table to be rejected")
+	}
+}
+
+// TestWindowsSanitizer_Confusables tests that look-alike characters are replaced with ASCII before transliteration
+func TestWindowsSanitizer_Confusables(t *testing.T) {
+	s := sanitizer.NewWindowsSanitizer(sanitizer.WithConfusables(true))
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"\u0420aypal", "Paypal"}, // Cyrillic Er
+		{"ＡＢＣ１２３", "ABC123"},      // Fullwidth
+		{"𝐁𝐨𝐥𝐝", "Bold"},          // Mathematical bold
+		{"Ｃ：ｄｉｒ", "C_dir"},        // Fullwidth colon is still invalid
+		{"Café", "Cafe"},
+	}
+	for _, tt := range tests {
+		if got := s.SanitizeName(tt.input); got != tt.want {
+			t.Errorf("SanitizeName(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	// Visually identical names collapse into the same name
+	if latin, cyrillic := s.SanitizeName("Reports"), s.SanitizeName("R\u0435p\u043erts"); latin != cyrillic {
+		t.Errorf("Expected look-alike names to sanitize alike, got %q and %q", latin, cyrillic)
+	}
+	if got := sanitizer.NewWindowsSanitizer().SanitizeName("\u0420aypal"); got != "Aaypal" {
+		t.Errorf("SanitizeName() without confusables = %q, want %q", got, "Aaypal")
+	}
+
+	_, rules := s.(interfaces.NameExplainer).ExplainName("\u0420aypal")
+	if want := []string{sanitizer.RuleConfusables}; !reflect.DeepEqual(rules, want) {
+		t.Errorf("ExplainName() rules = %v, want %v", rules, want)
+	}
+}
+
+// TestWindowsSanitizer_Emoji tests that emoji are replaced, stripped or spelled out according to the emoji mode
+func TestWindowsSanitizer_Emoji(t *testing.T) {
+	tests := []struct {
+		mode  string
+		input string
+		want  string
+	}{
+		{"", "Party 🎉 time", "Party _ time"},
+		{sanitizer.EmojiReplace, "Party 🎉 time", "Party _ time"},
+		{sanitizer.EmojiStrip, "Party 🎉 time", "Party time"},
+		{sanitizer.EmojiStrip, "🎉Launch👍🏽", "Launch"},
+		{sanitizer.EmojiText, "Party 🎉 time", "Party party-popper time"},
+		{sanitizer.EmojiText, "Launch🎉🎉", "Launch-party-popper-party-popper"},
+		{sanitizer.EmojiText, "Family 👨\u200d👩\u200d👧", "Family man-woman-girl"},
+		{sanitizer.EmojiText, "Trip 🇩🇪", "Trip flag-de"},
+		{sanitizer.EmojiText, "Café", "Cafe"},
+	}
+	for _, tt := range tests {
+		s := sanitizer.NewWindowsSanitizer(sanitizer.WithEmoji(tt.mode))
+		if got := s.SanitizeName(tt.input); got != tt.want {
+			t.Errorf("SanitizeName(%q) with emoji mode %q = %q, want %q", tt.input, tt.mode, got, tt.want)
+		}
+	}
+
+	_, rules := sanitizer.NewWindowsSanitizer(sanitizer.WithEmoji(sanitizer.EmojiStrip)).(interfaces.NameExplainer).ExplainName("🎉 Café")
+	if want := []string{sanitizer.RuleEmoji, sanitizer.RuleNonASCII}; !reflect.DeepEqual(rules, want) {
+		t.Errorf("ExplainName() rules = %v, want %v", rules, want)
+	}
+
+	if err := sanitizer.ValidateEmoji("keep"); err == nil {
+		t.Error("Expected an unknown emoji mode to be rejected")
+	}
+}
+
+// TestWindowsSanitizer_SourceEncoding tests that names which aren't valid UTF-8 are decoded from the first fitting encoding
+func TestWindowsSanitizer_SourceEncoding(t *testing.T) {
+	encodings := []string{"shift_jis", "latin1"}
+	s := sanitizer.NewWindowsSanitizer(sanitizer.WithSourceEncodings(encodings))
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"caf\xe9", "cafe"},                 // Latin-1, not valid Shift-JIS
+		{"\x93\xfa\x96\x7b\x8c\xea", "aaa"}, // Shift-JIS for 日本語, transliterated afterwards
+		{"Café", "Cafe"},                    // Valid UTF-8 is never decoded
+	}
+	for _, tt := range tests {
+		if got := s.SanitizeName(tt.input); got != tt.want {
+			t.Errorf("SanitizeName(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	decoded := sanitizer.NewWindowsSanitizer(sanitizer.WithSourceEncodings(encodings), sanitizer.WithStages([]string{sanitizer.StageDecode}))
+	if got := decoded.SanitizeName("\x93\xfa\x96\x7b\x8c\xea"); got != "日本語" {
+		t.Errorf("SanitizeName() with only the decode stage = %q, want %q", got, "日本語")
+	}
+	_, rules := s.(interfaces.NameExplainer).ExplainName("caf\xe9")
+	if want := []string{sanitizer.RuleSourceEncoding, sanitizer.RuleNonASCII}; !reflect.DeepEqual(rules, want) {
+		t.Errorf("ExplainName() rules = %v, want %v", rules, want)
+	}
+
+	// Without source encodings every invalid byte is replaced, as before
+	if got := sanitizer.NewWindowsSanitizer().SanitizeName("caf\xe9"); got != "caf_" {
+		t.Errorf("SanitizeName() without source encodings = %q, want %q", got, "caf_")
+	}
+
+	if err := sanitizer.ValidateSourceEncodings([]string{"latin1", "klingon"}); err == nil {
+		t.Error("Expected an unknown encoding to be rejected")
+	}
+}
+
+// TestWindowsSanitizer_Whitespace tests collapsing and replacing whitespace inside names
+func TestWindowsSanitizer_Whitespace(t *testing.T) {
+	tests := []struct {
+		whitespace sanitizer.Whitespace
+		input      string
+		want       string
+		rules      string
+	}{
+		{sanitizer.Whitespace{}, "Project   Files", "Project   Files", ""},
+		{sanitizer.Whitespace{Collapse: true}, "Project   Files", "Project Files", sanitizer.RuleCollapseSpaces},
+		{sanitizer.Whitespace{Replacement: "_"}, "Project   Files", "Project___Files", sanitizer.RuleSpaceReplacement},
+		{sanitizer.Whitespace{Collapse: true, Replacement: "-"}, "  Project   Files ", "Project-Files",
+			strings.Join([]string{sanitizer.RuleSurroundingSpaces, sanitizer.RuleCollapseSpaces, sanitizer.RuleSpaceReplacement}, ",")},
+	}
+
+	for _, tt := range tests {
+		s := sanitizer.NewWindowsSanitizer(sanitizer.WithWhitespace(tt.whitespace))
+		name, rules := s.(interfaces.NameExplainer).ExplainName(tt.input)
+		if name != tt.want || strings.Join(rules, ",") != tt.rules {
+			t.Errorf("ExplainName(%q) with %+v = %q %v, want %q [%s]", tt.input, tt.whitespace, name, rules, tt.want, tt.rules)
+		}
+	}
+
+	for _, replacement := range []string{":", " ", "a\tb"} {
+		if err := (sanitizer.Whitespace{Replacement: replacement}).Validate(); err == nil {
+			t.Errorf("Expected space replacement %q to be rejected", replacement)
+		}
+	}
+}
+
+// TestWindowsSanitizer_Slug tests that slug mode produces lower-case, hyphen-separated names
+func TestWindowsSanitizer_Slug(t *testing.T) {
+	tests := map[string]string{
+		"My Fancy Folder!": "my-fancy-folder",
+		"Café Münchën":     "cafe-munchen",
+		"--Q3__Report--":   "q3-report",
+		"v1.2 (final)":     "v1-2-final",
+		"already-a-slug":   "already-a-slug",
+		"!!!":              "empty",
+		"CON":              "con_", // Still reserved on Windows
+	}
+
+	s := sanitizer.NewWindowsSanitizer(sanitizer.WithSlug(true))
+	for input, want := range tests {
+		if got := s.SanitizeName(input); got != want {
+			t.Errorf("SanitizeName(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	_, rules := s.(interfaces.NameExplainer).ExplainName("My Folder")
+	if strings.Join(rules, ",") != sanitizer.RuleSlug {
+		t.Errorf("Expected only the slug rule, got %v", rules)
+	}
+}
+
+// TestNewPipeline tests that stages run in the configured order and profiles can be chained
+func TestNewPipeline(t *testing.T) {
+	tests := []struct {
+		entries []string
+		input   string
+		want    string
+	}{
+		{[]string{sanitizer.StageTransliterate, sanitizer.StageWindows, sanitizer.StageSlug, sanitizer.StageLength}, "Café: My Folder!!  ", "cafe-my-folder"},
+		{[]string{sanitizer.StageSlug, sanitizer.StageWindows}, "con", "con_"},
+		{[]string{sanitizer.StageWindows, sanitizer.StageSlug}, "con", "con"}, // The slug drops the reserved suffix again
+		{[]string{sanitizer.StageWindows}, "a:b ", "a:b"},
+		{[]string{sanitizer.StageTransliterate, "profile:posix"}, "Straße 2024?", "Straae_2024_"},
+	}
+
+	for _, tt := range tests {
+		pipeline, err := sanitizer.NewPipeline(tt.entries)
+		if err != nil {
+			t.Fatalf("NewPipeline(%v) returned error: %v", tt.entries, err)
+		}
+		if got := pipeline.SanitizeName(tt.input); got != tt.want {
+			t.Errorf("Pipeline %v: SanitizeName(%q) = %q, want %q", tt.entries, tt.input, got, tt.want)
+		}
+	}
+
+	chain, _ := sanitizer.NewPipeline([]string{sanitizer.StageTransliterate, "profile:posix"})
+	_, rules := chain.(interfaces.NameExplainer).ExplainName("a:b c")
+	if want := []string{sanitizer.RuleInvalidCharacters, sanitizer.RulePortableChars}; !reflect.DeepEqual(rules, want) {
+		t.Errorf("Expected the rules of both links %v, got %v", want, rules)
+	}
+
+	for _, entries := range [][]string{nil, {"nope"}, {"profile:nope"}} {
+		if _, err := sanitizer.NewPipeline(entries); err == nil {
+			t.Errorf("NewPipeline(%v) expected error", entries)
+		}
+	}
+}
+
+// TestWindowsSanitizer_DefaultStages tests that listing the default stages explicitly changes nothing
+func TestWindowsSanitizer_DefaultStages(t *testing.T) {
+	var stages []string
+	for _, stage := range sanitizer.Stages() {
+		if stage != sanitizer.StageSlug && stage != sanitizer.StagePortable && stage != sanitizer.StageShortName {
+			stages = append(stages, stage)
+		}
+	}
+
+	defaults := sanitizer.NewWindowsSanitizer()
+	explicit := sanitizer.NewWindowsSanitizer(sanitizer.WithStages(stages))
+	for _, input := range []string{"Report: Q1 <draft>. ", "CON", "Café Münchën", "\x01tab\tname", strings.Repeat("x", 300)} {
+		if want, got := defaults.SanitizeName(input), explicit.SanitizeName(input); got != want {
+			t.Errorf("SanitizeName(%q) = %q with explicit stages, want %q", input, got, want)
+		}
+	}
+}
+
+// TestWindowsSanitizer_Truncation tests the truncation strategies and that none leaves a trailing period
+func TestWindowsSanitizer_Truncation(t *testing.T) {
+	profile, _ := sanitizer.LookupProfile(sanitizer.DefaultProfile)
+	profile.MaxNameLength = 20
+
+	tests := []struct {
+		strategy string
+		input    string
+		want     string
+	}{
+		{"", "Quarterly report 24. Final", "Quarterly report 24"},
+		{sanitizer.TruncateCut, "Quarterly report 24. Final", "Quarterly report 24"},
+		{sanitizer.TruncateMiddle, "Quarterly report. Final version", "Quarterly... version"},
+		{sanitizer.TruncateWord, "Quarterly report draft version", "Quarterly report"},
+		{sanitizer.TruncateWord, "Quarterlyreportdraftversion", "Quarterlyreportdraft"},
+	}
+	for _, tt := range tests {
+		s := sanitizer.NewWindowsSanitizer(sanitizer.WithProfile(profile), sanitizer.WithTruncation(tt.strategy))
+		if got := s.SanitizeName(tt.input); got != tt.want {
+			t.Errorf("Strategy %q: SanitizeName(%q) = %q, want %q", tt.strategy, tt.input, got, tt.want)
+		}
+	}
+
+	// Without transliteration names can hold multi-byte characters, which are never split
+	lengthOnly := sanitizer.NewWindowsSanitizer(sanitizer.WithProfile(profile), sanitizer.WithStages([]string{sanitizer.StageLength}))
+	if got := lengthOnly.SanitizeName("Résumé Résumé Résumé"); got != "Résumé Résumé R" {
+		t.Errorf("Expected the cut to keep whole characters, got %q", got)
+	}
+
+	hashed := sanitizer.NewWindowsSanitizer(sanitizer.WithProfile(profile), sanitizer.WithTruncation(sanitizer.TruncateHash))
+	first, second := hashed.SanitizeName("Quarterly report 2023 final"), hashed.SanitizeName("Quarterly report 2024 final")
+	if len(first) != 20 || first == second || !strings.HasPrefix(first, "Quarterly r-") {
+		t.Errorf("Expected unique hash-suffixed names of 20 characters, got %q and %q", first, second)
+	}
+	if again := hashed.SanitizeName("Quarterly report 2023 final"); again != first {
+		t.Errorf("Expected the hash suffix to be stable, got %q and %q", first, again)
+	}
+
+	if err := sanitizer.ValidateTruncation("ellipsis"); err == nil {
+		t.Error("Expected an unknown strategy to be rejected")
+	}
+}
+
+// TestWindowsSanitizer_TruncationTinyLimits tests that every strategy handles limits too short for an ellipsis or a hash
+func TestWindowsSanitizer_TruncationTinyLimits(t *testing.T) {
+	const input = "Some very long:name here.txt"
+
+	tests := []struct {
+		strategy     string
+		rulesVersion int
+		want         map[int]string // Sanitized name per limit
+	}{
+		{"", 1, map[int]string{1: "S", 2: "So", 3: "..."}},
+		{"", 0, map[int]string{1: "S", 2: "So", 3: "Som"}},
+		{sanitizer.TruncateCut, 0, map[int]string{1: "S", 2: "So", 3: "Som"}},
+		{sanitizer.TruncateMiddle, 0, map[int]string{1: "S", 2: "So", 3: "Som"}},
+		{sanitizer.TruncateWord, 0, map[int]string{1: "S", 2: "So", 3: "Som"}},
+		{sanitizer.TruncateHash, 0, map[int]string{1: "S", 2: "So", 3: "Som"}},
+	}
+
+	for _, tt := range tests {
+		for _, limit := range []int{1, 2, 3} {
+			profile, _ := sanitizer.LookupProfile(sanitizer.DefaultProfile)
+			profile.MaxNameLength = limit
+			options := []sanitizer.Option{sanitizer.WithProfile(profile), sanitizer.WithTruncation(tt.strategy)}
+			if tt.rulesVersion != 0 {
+				options = append(options, sanitizer.WithRulesVersion(tt.rulesVersion))
+			}
+
+			got := sanitizer.NewWindowsSanitizer(options...).SanitizeName(input)
+			if got != tt.want[limit] {
+				t.Errorf("Strategy %q, rules version %d, limit %d: SanitizeName(%q) = %q, want %q", tt.strategy, tt.rulesVersion, limit, input, got, tt.want[limit])
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package sanitizer
+
+import (
+	"strings"
+)
+
+// shortNameSpecials are the punctuation characters allowed in 8.3 short names besides A-Z and 0-9
+const shortNameSpecials = "!#$%&'()-@^_`{}~"
+
+// applyShortName converts a sanitized name to an 8.3 short name
+// The last period separates the extension; other periods and spaces are dropped, and
+// characters not allowed in short names are replaced with the invalid character replacement
+func (ws *WindowsSanitizer) applyShortName(name string, steps trace, ctx templateContext) (string, trace) {
+	base, extension := name, ""
+	if i := strings.LastIndex(name, "."); i > 0 {
+		base, extension = name[:i], name[i+1:]
+	}
+
+	replacement := ws.shortNameReplacement(ctx)
+	base = truncate(shortNameComponent(base, replacement), 8)
+	extension = truncate(shortNameComponent(extension, replacement), 3)
+
+	if base == "" {
+		base = truncate(shortNameComponent(ws.expand(ws.replacements.EmptyName, ctx), replacement), 8)
+		if base == "" {
+			base = "_"
+		}
+	}
+
+	short := base
+	if extension != "" {
+		short += "." + extension
+	}
+
+	// Reserved names must stay distinguishable after shortening, e.g. "con" must not become "CON"
+	if ws.reservedNames[short] {
+		short = truncate(base, 7) + "_"
+		if extension != "" {
+			short += "." + extension
+		}
+	}
+
+	if short == name {
+		return name, steps
+	}
+	return short, steps.add(RuleShortName, name, short)
+}
+
+// shortNameReplacement returns the invalid character replacement restricted to short name characters
+func (ws *WindowsSanitizer) shortNameReplacement(ctx templateContext) string {
+	replacement := shortNameComponent(ws.expand(ws.replacements.InvalidChar, ctx), "")
+	if replacement == "" && ws.replacements.InvalidChar != "" {
+		return "_"
+	}
+	return replacement
+}
+
+// shortNameComponent upper-cases a name component and keeps only characters valid in short names
+// Spaces and periods are dropped; other characters become the replacement
+func shortNameComponent(component, replacement string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToUpper(component) {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune(shortNameSpecials, r):
+			builder.WriteRune(r)
+		case r == ' ' || r == '.':
+		default:
+			builder.WriteString(replacement)
+		}
+	}
+	return builder.String()
+}
+
+// truncate shortens an ASCII string to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,49 @@
+package sanitizer
+
+import (
+	"strings"
+)
+
+// WithSlug converts names to lower-case, hyphen-separated slugs that are safe in URLs, e.g. "My Fancy Folder!" -> "my-fancy-folder"
+// Non-ASCII characters are transliterated first, so "Café Menü" becomes "cafe-menu"
+func WithSlug(slug bool) Option {
+	return func(ws *WindowsSanitizer) {
+		ws.slug = slug
+	}
+}
+
+// slugify lower-cases a name and joins its runs of letters and digits with single hyphens
+func slugify(name string) string {
+	var builder strings.Builder
+	separate := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if separate && builder.Len() > 0 {
+				builder.WriteByte('-')
+			}
+			builder.WriteRune(r)
+			separate = false
+			continue
+		}
+		separate = true
+	}
+	return builder.String()
+}
+
+// applySlug converts the transliterated name to a slug
+// Names without a single letter or digit get the slug of the empty-name replacement instead
+func (ws *WindowsSanitizer) applySlug(name string, steps trace, ctx templateContext) (string, trace) {
+	slug := slugify(name)
+	if slug == "" {
+		fallback := slugify(ws.expand(ws.replacements.EmptyName, ctx))
+		if fallback == "" {
+			fallback = "empty"
+		}
+		return fallback, steps.add(RuleEmptyName, name, fallback)
+	}
+
+	if slug != name {
+		steps = steps.add(RuleSlug, name, slug)
+	}
+	return slug, steps
+}
@@ -0,0 +1,135 @@
+package sanitizer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Pipeline stage names, usable with WithStages and NewPipeline
+const (
+	StageDecode        = "decode"
+	StageControl       = "control"
+	StageInvisible     = "invisible"
+	StageConfusables   = "confusables"
+	StageEmoji         = "emoji"
+	StageTransliterate = "transliterate"
+	StageSlug          = "slug"
+	StageWindows       = "windows"
+	StageLength        = "length"
+	StageWhitespace    = "whitespace"
+	StagePortable      = "portable"
+	StageShortName     = "short-name"
+	StagePathLength    = "path-length"
+)
+
+// stage is one step of the sanitizer pipeline, applied to the result of the previous step
+type stage struct {
+	description string
+	apply       func(ws *WindowsSanitizer, folder interfaces.FolderInfo, name string, steps trace, ctx templateContext) (string, trace)
+}
+
+// stageOrder lists the stages in the order the default pipeline applies them
+var stageOrder = []string{
+	StageDecode, StageControl, StageInvisible, StageConfusables, StageEmoji, StageTransliterate, StageSlug, StageWindows, StageLength,
+	StageWhitespace, StagePortable, StageShortName, StagePathLength,
+}
+
+// pipelineStages holds the stages by name
+var pipelineStages = map[string]stage{
+	StageDecode: {"decode names that aren't valid UTF-8 from --source-encoding (default pipeline: only with --source-encoding)", func(ws *WindowsSanitizer, _ interfaces.FolderInfo, name string, steps trace, _ templateContext) (string, trace) {
+		return ws.applyDecode(name, steps)
+	}},
+	StageControl: {"remove control characters (ASCII 0-31)", func(ws *WindowsSanitizer, _ interfaces.FolderInfo, name string, steps trace, _ templateContext) (string, trace) {
+		if cleaned := ws.controlCharsRegex.ReplaceAllString(name, ""); cleaned != name {
+			steps = steps.add(RuleControlCharacters, name, cleaned)
+			name = cleaned
+		}
+		return name, steps
+	}},
+	StageInvisible: {"remove invisible and formatting characters such as zero-width spaces and bidirectional overrides", func(ws *WindowsSanitizer, _ interfaces.FolderInfo, name string, steps trace, ctx templateContext) (string, trace) {
+		return ws.applyInvisible(name, steps, ctx)
+	}},
+	StageConfusables: {"replace look-alike characters such as Cyrillic or fullwidth letters with ASCII (default pipeline: only with --confusables)", func(ws *WindowsSanitizer, _ interfaces.FolderInfo, name string, steps trace, _ templateContext) (string, trace) {
+		return ws.applyConfusables(name, steps)
+	}},
+	StageEmoji: {"strip emoji and pictographs or replace them with their names (default pipeline: only with --emoji strip or text)", func(ws *WindowsSanitizer, _ interfaces.FolderInfo, name string, steps trace, ctx templateContext) (string, trace) {
+		return ws.applyEmoji(name, steps, ctx)
+	}},
+	StageTransliterate: {"replace invalid characters and convert non-ASCII characters to ASCII", func(ws *WindowsSanitizer, _ interfaces.FolderInfo, name string, steps trace, ctx templateContext) (string, trace) {
+		return ws.processCharacters(name, steps, ctx)
+	}},
+	StageSlug: {"convert to a lower-case, hyphen-separated slug (default pipeline: only with --slug)", func(ws *WindowsSanitizer, _ interfaces.FolderInfo, name string, steps trace, ctx templateContext) (string, trace) {
+		return ws.applySlug(name, steps, ctx)
+	}},
+	StageWindows: {"trim surrounding spaces and trailing periods, suffix reserved names", func(ws *WindowsSanitizer, _ interfaces.FolderInfo, name string, steps trace, ctx templateContext) (string, trace) {
+		return ws.applyWindowsRules(name, steps, ctx)
+	}},
+	StageLength: {"truncate names longer than the maximum name length", func(ws *WindowsSanitizer, _ interfaces.FolderInfo, name string, steps trace, _ templateContext) (string, trace) {
+		return ws.applyMaxLength(name, steps)
+	}},
+	StageWhitespace: {"collapse and replace spaces as set by --collapse-spaces and --space-replacement", func(ws *WindowsSanitizer, _ interfaces.FolderInfo, name string, steps trace, _ templateContext) (string, trace) {
+		return ws.applyWhitespace(name, steps)
+	}},
+	StagePortable: {"restrict to the POSIX portable characters [A-Za-z0-9._-] (default pipeline: only for the posix profile)", func(ws *WindowsSanitizer, _ interfaces.FolderInfo, name string, steps trace, ctx templateContext) (string, trace) {
+		return ws.applyPortable(name, steps, ctx)
+	}},
+	StageShortName: {"convert to an 8.3 short name (default pipeline: only for the fat32-8.3 profile)", func(ws *WindowsSanitizer, _ interfaces.FolderInfo, name string, steps trace, ctx templateContext) (string, trace) {
+		return ws.applyShortName(name, steps, ctx)
+	}},
+	StagePathLength: {"shorten names so the path fits the profile's path limit", func(ws *WindowsSanitizer, folder interfaces.FolderInfo, name string, steps trace, _ templateContext) (string, trace) {
+		return ws.applyPathLength(folder, name, steps)
+	}},
+}
+
+// Stages returns the names of the pipeline stages in default order
+func Stages() []string {
+	return append([]string{}, stageOrder...)
+}
+
+// StageDescription returns what a pipeline stage does
+func StageDescription(name string) string {
+	return pipelineStages[name].description
+}
+
+// WithStages applies only the named stages, in the given order, instead of the default pipeline
+// Listing a stage enables it, e.g. slug without --slug; the names must pass ValidateStages
+func WithStages(names []string) Option {
+	return func(ws *WindowsSanitizer) {
+		ws.stages = append([]string{}, names...)
+	}
+}
+
+// ValidateStages checks that every name is a pipeline stage
+func ValidateStages(names []string) error {
+	for _, name := range names {
+		if _, exists := pipelineStages[name]; !exists {
+			return fmt.Errorf("unknown pipeline stage %q (available: %s)", name, strings.Join(stageOrder, ", "))
+		}
+	}
+	return nil
+}
+
+// activeStages returns the stages to apply: the configured ones, or every stage the profile and options enable
+func (ws *WindowsSanitizer) activeStages() []string {
+	if ws.stages != nil {
+		return ws.stages
+	}
+
+	active := make([]string, 0, len(stageOrder))
+	for _, name := range stageOrder {
+		switch {
+		case name == StageDecode && len(ws.sourceEncodings) == 0,
+			name == StageConfusables && !ws.confusables,
+			name == StageEmoji && (ws.emoji == "" || ws.emoji == EmojiReplace),
+			name == StageSlug && !ws.slug,
+			name == StagePortable && !ws.portableOnly,
+			name == StageShortName && !ws.shortNames,
+			name == StageInvisible && ws.rulesVersion < 3:
+			continue
+		}
+		active = append(active, name)
+	}
+	return active
+}
@@ -0,0 +1,25 @@
+package sanitizer
+
+import (
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// trace collects the rules that changed a name, in the order they were applied
+type trace []interfaces.RuleStep
+
+// add records that rule turned before into after
+func (t trace) add(rule, before, after string) trace {
+	return append(t, interfaces.RuleStep{Rule: rule, Before: before, After: after})
+}
+
+// rules returns the identifiers of the recorded rules
+func (t trace) rules() []string {
+	if len(t) == 0 {
+		return nil
+	}
+	rules := make([]string, len(t))
+	for i, step := range t {
+		rules[i] = step.Rule
+	}
+	return rules
+}
@@ -0,0 +1,77 @@
+package sanitizer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// localeTables holds the locale-specific transliterations of lower-case letters, keyed by language
+// Letters a table doesn't list fall back to the generic transliteration
+var localeTables = map[string]map[rune]string{
+	"de": {'ä': "ae", 'ö': "oe", 'ü': "ue", 'ß': "ss"},
+	"da": {'æ': "ae", 'ø': "oe", 'å': "aa"},
+	"nb": {'æ': "ae", 'ø': "oe", 'å': "aa"},
+	"nn": {'æ': "ae", 'ø': "oe", 'å': "aa"},
+	"no": {'æ': "ae", 'ø': "oe", 'å': "aa"},
+	"sv": {'ä': "ae", 'ö': "oe", 'å': "aa"},
+	"fi": {'ä': "ae", 'ö': "oe", 'å': "aa"},
+	"is": {'æ': "ae", 'ö': "oe", 'þ': "th", 'ð': "d"},
+	"nl": {'ĳ': "ij"},
+	"fr": {'æ': "ae", 'œ': "oe"},
+}
+
+// TransliterationLocales returns the languages with locale-specific transliteration, sorted
+func TransliterationLocales() []string {
+	locales := make([]string, 0, len(localeTables))
+	for locale := range localeTables {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// localeLanguage returns the language of a locale such as "de", "de-CH" or "de_DE.UTF-8"
+func localeLanguage(locale string) string {
+	language, _, _ := strings.Cut(strings.ToLower(locale), ".")
+	language, _, _ = strings.Cut(language, "_")
+	language, _, _ = strings.Cut(language, "-")
+	return language
+}
+
+// ValidateTransliterationLocale checks that locale has a transliteration table; "" selects the generic transliteration
+func ValidateTransliterationLocale(locale string) error {
+	if locale == "" {
+		return nil
+	}
+	if _, ok := localeTables[localeLanguage(locale)]; !ok {
+		return fmt.Errorf("no transliteration table for locale %q (available: %s)", locale, strings.Join(TransliterationLocales(), ", "))
+	}
+	return nil
+}
+
+// WithTransliterationLocale transliterates the letters of a language the way its speakers expect, e.g. "ü" -> "ue" for German
+// The locale must pass ValidateTransliterationLocale; region and encoding suffixes like "de-CH" or "de_DE.UTF-8" are ignored
+func WithTransliterationLocale(locale string) Option {
+	return func(ws *WindowsSanitizer) {
+		ws.localeTable = localeTables[localeLanguage(locale)]
+	}
+}
+
+// localeTransliteration returns the locale-specific transliteration of runes[i], or false if the locale doesn't define one
+// Upper-case letters keep their case: "Ü" becomes "Ue" in "Übung" and "UE" in "MÜLLER"
+func (ws *WindowsSanitizer) localeTransliteration(runes []rune, i int) (string, bool) {
+	r := runes[i]
+	ascii, ok := ws.localeTable[unicode.ToLower(r)]
+	if !ok || !unicode.IsUpper(r) {
+		return ascii, ok
+	}
+
+	next := i+1 < len(runes) && unicode.IsUpper(runes[i+1])
+	previous := i > 0 && unicode.IsUpper(runes[i-1]) && (i+1 == len(runes) || !unicode.IsLower(runes[i+1]))
+	if next || previous {
+		return strings.ToUpper(ascii), true
+	}
+	return strings.ToUpper(ascii[:1]) + ascii[1:], true
+}
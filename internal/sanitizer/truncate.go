@@ -0,0 +1,120 @@
+package sanitizer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Truncation strategies for names over the length limit
+const (
+	TruncateCut    = "cut"    // Keep the beginning of the name
+	TruncateMiddle = "middle" // Keep the beginning and the end, joined by "..."
+	TruncateWord   = "word"   // Cut at the last word boundary, so no word is cut in half
+	TruncateHash   = "hash"   // Keep the beginning and append a hash of the full name, so truncated names stay unique
+)
+
+// truncationStrategies lists the strategies for help output and validation
+var truncationStrategies = []string{TruncateCut, TruncateMiddle, TruncateWord, TruncateHash}
+
+// legacyEllipsis is appended to truncated names by rules version 1
+const legacyEllipsis = "..."
+
+// hashSuffixLength is the number of hex digits the hash strategy appends
+const hashSuffixLength = 8
+
+// TruncationStrategies returns the names of the truncation strategies
+func TruncationStrategies() []string {
+	return append([]string{}, truncationStrategies...)
+}
+
+// ValidateTruncation checks that strategy is a truncation strategy; "" selects the default of the rules version
+func ValidateTruncation(strategy string) error {
+	if strategy == "" {
+		return nil
+	}
+	for _, known := range truncationStrategies {
+		if strategy == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown truncation strategy %q (available: %s)", strategy, strings.Join(truncationStrategies, ", "))
+}
+
+// WithTruncation sets how names over the length limit are shortened; it must pass ValidateTruncation
+// Without a strategy, rules version 1 appends "..." and later versions cut
+func WithTruncation(strategy string) Option {
+	return func(ws *WindowsSanitizer) {
+		ws.truncation = strategy
+	}
+}
+
+// truncate shortens name to at most limit bytes with the configured strategy
+func (ws *WindowsSanitizer) truncate(name string, limit int) string {
+	strategy := ws.truncation
+	if strategy == "" {
+		strategy = TruncateCut
+	}
+
+	switch {
+	case strategy == TruncateMiddle && limit > len(legacyEllipsis)+1:
+		budget := limit - len(legacyEllipsis)
+		head := cutBytes(name, (budget+1)/2)
+		tail := tailBytes(name, budget-len(head))
+		return head + legacyEllipsis + tail
+	case strategy == TruncateWord:
+		cut := cutBytes(name, limit)
+		if next, _ := utf8.DecodeRuneInString(name[len(cut):]); isWordBoundary(next) {
+			return trimCut(cut)
+		}
+		if boundary := strings.LastIndexFunc(cut, isWordBoundary); boundary >= limit/2 {
+			return trimCut(cut[:boundary])
+		}
+		return trimCut(cut)
+	case strategy == TruncateHash && limit > hashSuffixLength+1:
+		sum := sha256.Sum256([]byte(name))
+		suffix := "-" + hex.EncodeToString(sum[:])[:hashSuffixLength]
+		return trimCut(cutBytes(name, limit-len(suffix))) + suffix
+	default:
+		return trimCut(cutBytes(name, limit))
+	}
+}
+
+// cutBytes returns the longest prefix of name with at most limit bytes that doesn't split a character
+func cutBytes(name string, limit int) string {
+	if len(name) <= limit {
+		return name
+	}
+	end := limit
+	for end > 0 && !utf8.RuneStart(name[end]) {
+		end--
+	}
+	return name[:end]
+}
+
+// tailBytes returns the longest suffix of name with at most limit bytes that doesn't split a character
+func tailBytes(name string, limit int) string {
+	if len(name) <= limit {
+		return name
+	}
+	start := len(name) - limit
+	for start < len(name) && !utf8.RuneStart(name[start]) {
+		start++
+	}
+	return name[start:]
+}
+
+// trimCut removes the trailing periods and spaces a cut can expose; a cut that is nothing else stays as is
+func trimCut(cut string) string {
+	if trimmed := strings.TrimRight(cut, ". "); trimmed != "" {
+		return trimmed
+	}
+	return cut
+}
+
+// isWordBoundary reports whether a character separates words in a name
+func isWordBoundary(r rune) bool {
+	return r == ' ' || r == '-' || r == '_' || r == '.' || r == ','
+}
@@ -0,0 +1,57 @@
+package sanitizer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Whitespace configures the optional whitespace normalization rules
+// Leading and trailing whitespace is always trimmed; these rules change the whitespace inside a name.
+type Whitespace struct {
+	Collapse    bool   // Collapses each run of whitespace into a single space
+	Replacement string // Replaces each remaining space, e.g. "_" or "-" ("" = keep spaces)
+}
+
+// Validate checks that the replacement is usable in folder names and contains no whitespace itself
+func (w Whitespace) Validate() error {
+	for _, char := range w.Replacement {
+		if char < 32 || unicode.IsSpace(char) || IsViolatingRune(char) {
+			return fmt.Errorf("space replacement %q: character %q is not allowed", w.Replacement, char)
+		}
+	}
+	return nil
+}
+
+// WithWhitespace enables the whitespace normalization rules; the settings must pass Whitespace.Validate
+func WithWhitespace(whitespace Whitespace) Option {
+	return func(ws *WindowsSanitizer) {
+		ws.whitespace = whitespace
+	}
+}
+
+// applyWhitespace collapses runs of whitespace and replaces spaces as configured
+func (ws *WindowsSanitizer) applyWhitespace(name string, steps trace) (string, trace) {
+	if ws.whitespace.Collapse {
+		if collapsed := strings.Join(strings.Fields(name), " "); collapsed != name {
+			steps = steps.add(RuleCollapseSpaces, name, collapsed)
+			name = collapsed
+		}
+	}
+
+	if ws.whitespace.Replacement != "" {
+		replaced := strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return ' '
+			}
+			return r
+		}, name)
+		replaced = strings.ReplaceAll(replaced, " ", ws.whitespace.Replacement)
+		if replaced != name {
+			steps = steps.add(RuleSpaceReplacement, name, replaced)
+			name = replaced
+		}
+	}
+
+	return name, steps
+}
@@ -0,0 +1,177 @@
+// Package schedule decides when a long run should pause so it doesn't degrade daytime performance.
+// This implementation provides a Pacer that honors quiet hours and backs off while the file system is slow.
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// day is the length of the daily cycle quiet hours repeat in
+const day = 24 * time.Hour
+
+// latencyWeight is how many parts of the moving latency average the previous value keeps out of latencyWeight+1
+// A few slow renames in a row trigger a pause; a single one does not
+const latencyWeight = 4
+
+// Window is a daily time range in local time, e.g. 08:00-18:00; it may wrap around midnight
+type Window struct {
+	start time.Duration // Offset of the start from midnight
+	end   time.Duration // Offset of the end from midnight
+}
+
+// ParseWindow parses a window written as HH:MM-HH:MM
+func ParseWindow(text string) (Window, error) {
+	startText, endText, found := strings.Cut(text, "-")
+	if !found {
+		return Window{}, fmt.Errorf("invalid time window %q (want HH:MM-HH:MM)", text)
+	}
+
+	start, err := parseClock(strings.TrimSpace(startText))
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid time window %q: %w", text, err)
+	}
+	end, err := parseClock(strings.TrimSpace(endText))
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid time window %q: %w", text, err)
+	}
+	if start == end {
+		return Window{}, fmt.Errorf("invalid time window %q: start and end are the same", text)
+	}
+
+	return Window{start: start, end: end}, nil
+}
+
+// String formats the window the way ParseWindow reads it
+func (w Window) String() string {
+	return formatClock(w.start) + "-" + formatClock(w.end)
+}
+
+// remaining returns how long the window still lasts at t, or 0 when t is outside the window
+func (w Window) remaining(t time.Time) time.Duration {
+	offset := sinceMidnight(t)
+	if w.start < w.end {
+		if offset >= w.start && offset < w.end {
+			return w.end - offset
+		}
+		return 0
+	}
+
+	// The window wraps around midnight, e.g. 22:00-06:00
+	switch {
+	case offset >= w.start:
+		return day - offset + w.end
+	case offset < w.end:
+		return w.end - offset
+	default:
+		return 0
+	}
+}
+
+// Pacer implements the Pacer interface with quiet hours and a file system latency limit
+// This struct is not safe for concurrent use; the service calls it from a single goroutine
+type Pacer struct {
+	quietHours []Window
+	maxLatency time.Duration // Pause once the average rename latency exceeds this (0 = never)
+	cooldown   time.Duration // How long a latency pause lasts
+	average    time.Duration // Moving average of recent rename latencies
+	slowUntil  time.Time     // End of the current latency pause
+	now        func() time.Time
+}
+
+// Option configures optional Pacer behavior
+type Option func(*Pacer)
+
+// WithQuietHours pauses the run while the local time is inside any of the windows
+func WithQuietHours(windows ...Window) Option {
+	return func(p *Pacer) {
+		p.quietHours = append(p.quietHours, windows...)
+	}
+}
+
+// WithLatencyLimit pauses the run for cooldown once renames take longer than maxLatency on average
+// Slow renames mean heavy load from users, so the run makes way for them (0 = no limit)
+func WithLatencyLimit(maxLatency, cooldown time.Duration) Option {
+	return func(p *Pacer) {
+		p.maxLatency = maxLatency
+		p.cooldown = cooldown
+	}
+}
+
+// WithClock replaces the wall clock, e.g. with a fixed time in tests
+func WithClock(now func() time.Time) Option {
+	return func(p *Pacer) {
+		p.now = now
+	}
+}
+
+// NewPacer creates a pacer that never pauses unless configured by options
+func NewPacer(options ...Option) *Pacer {
+	p := &Pacer{now: time.Now}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+// Pause returns how long to wait before the next folder and why
+// This method implements the Pacer interface
+func (p *Pacer) Pause() (time.Duration, string) {
+	now := p.now()
+	if wait := p.slowUntil.Sub(now); wait > 0 {
+		return wait, fmt.Sprintf("renames took longer than %s, the file system is busy", p.maxLatency)
+	}
+
+	for _, window := range p.quietHours {
+		if wait := window.remaining(now); wait > 0 {
+			return wait, fmt.Sprintf("quiet hours %s", window)
+		}
+	}
+	return 0, ""
+}
+
+// Observe adds a rename latency to the moving average and starts a pause once it exceeds the limit
+// This method implements the Pacer interface
+func (p *Pacer) Observe(latency time.Duration) {
+	if p.maxLatency <= 0 {
+		return
+	}
+
+	if p.average == 0 {
+		p.average = latency
+	} else {
+		p.average = (p.average*latencyWeight + latency) / (latencyWeight + 1)
+	}
+
+	if p.average > p.maxLatency {
+		p.slowUntil = p.now().Add(p.cooldown)
+		p.average = 0 // Judge the file system afresh after the pause
+	}
+}
+
+// Ensure Pacer satisfies the Pacer contract
+var _ interfaces.Pacer = (*Pacer)(nil)
+
+// parseClock parses HH:MM into an offset from midnight
+func parseClock(text string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", text)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day (HH:MM)", text)
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+// formatClock formats an offset from midnight as HH:MM
+func formatClock(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset/time.Hour), int(offset%time.Hour/time.Minute))
+}
+
+// sinceMidnight returns the local wall-clock time of t as an offset from midnight
+func sinceMidnight(t time.Time) time.Duration {
+	hour, minute, second := t.Clock()
+	return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second +
+		time.Duration(t.Nanosecond())
+}
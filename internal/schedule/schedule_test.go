@@ -0,0 +1,99 @@
+// Package schedule_test provides tests for the schedule package.
+// This test suite ensures quiet hours and latency pauses start and end at the right time.
+package schedule_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/schedule"
+)
+
+// at returns a clock fixed to the given local time of day
+func at(hour, minute int) func() time.Time {
+	return func() time.Time {
+		return time.Date(2024, 3, 4, hour, minute, 0, 0, time.Local)
+	}
+}
+
+// mustWindow parses a window or fails the test
+func mustWindow(t *testing.T, text string) schedule.Window {
+	t.Helper()
+	window, err := schedule.ParseWindow(text)
+	if err != nil {
+		t.Fatalf("ParseWindow(%q) returned error: %v", text, err)
+	}
+	return window
+}
+
+// TestParseWindow tests parsing and formatting of time windows
+func TestParseWindow(t *testing.T) {
+	if got := mustWindow(t, "7:30 - 19:00").String(); got != "07:30-19:00" {
+		t.Errorf("Expected 07:30-19:00, got %s", got)
+	}
+
+	for _, text := range []string{"", "08:00", "08:00-25:00", "noon-18:00", "08:00-08:00"} {
+		if _, err := schedule.ParseWindow(text); err == nil {
+			t.Errorf("ParseWindow(%q) should fail", text)
+		}
+	}
+}
+
+// TestPacer_QuietHours tests that the pacer waits until the end of the current window
+func TestPacer_QuietHours(t *testing.T) {
+	tests := []struct {
+		name   string
+		window string
+		hour   int
+		want   time.Duration
+	}{
+		{"inside", "08:00-18:00", 10, 8 * time.Hour},
+		{"before", "08:00-18:00", 7, 0},
+		{"at the end", "08:00-18:00", 18, 0},
+		{"wrapping, evening", "22:00-06:00", 23, 7 * time.Hour},
+		{"wrapping, morning", "22:00-06:00", 5, time.Hour},
+		{"wrapping, outside", "22:00-06:00", 12, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pacer := schedule.NewPacer(schedule.WithQuietHours(mustWindow(t, tt.window)), schedule.WithClock(at(tt.hour, 0)))
+
+			wait, reason := pacer.Pause()
+			if wait != tt.want {
+				t.Errorf("Expected a pause of %s, got %s", tt.want, wait)
+			}
+			if wait > 0 && !strings.Contains(reason, tt.window) {
+				t.Errorf("Expected the reason to name the window, got %q", reason)
+			}
+		})
+	}
+}
+
+// TestPacer_LatencyLimit tests that only sustained slow renames start a pause
+func TestPacer_LatencyLimit(t *testing.T) {
+	pacer := schedule.NewPacer(schedule.WithLatencyLimit(100*time.Millisecond, time.Minute), schedule.WithClock(at(12, 0)))
+
+	pacer.Observe(10 * time.Millisecond)
+	pacer.Observe(300 * time.Millisecond) // A single outlier
+	if wait, _ := pacer.Pause(); wait != 0 {
+		t.Fatalf("Expected no pause after a single slow rename, got %s", wait)
+	}
+
+	for i := 0; i < 5; i++ {
+		pacer.Observe(300 * time.Millisecond)
+	}
+	if wait, _ := pacer.Pause(); wait != time.Minute {
+		t.Errorf("Expected a pause of 1m after sustained slow renames, got %s", wait)
+	}
+}
+
+// TestPacer_Unconfigured tests that a pacer without options never pauses
+func TestPacer_Unconfigured(t *testing.T) {
+	pacer := schedule.NewPacer()
+	pacer.Observe(time.Hour)
+	if wait, _ := pacer.Pause(); wait != 0 {
+		t.Errorf("Expected no pause, got %s", wait)
+	}
+}
@@ -3,10 +3,15 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
-	"sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/paths"
 )
 
 // SanitizeService orchestrates the folder sanitization process
@@ -16,6 +21,111 @@ type SanitizeService struct {
 	walker    interfaces.DirectoryWalker
 	processor interfaces.FolderProcessor
 	reporter  interfaces.ProgressReporter
+
+	// maxErrors aborts the run once more errors than this occurred (0 = unlimited)
+	maxErrors int
+	// maxErrorRate aborts the run once the error percentage exceeds this value (0 = unlimited)
+	maxErrorRate float64
+	// maxRenames asks for confirmation before a real run renames more folders than this (0 = unlimited)
+	maxRenames int
+	// maxRenamePercent asks for confirmation before a real run renames more than this percentage of the folders (0 = unlimited)
+	maxRenamePercent float64
+	// confirmRenames decides whether a run over the rename limit goes ahead (nil = never)
+	confirmRenames func(reason string) bool
+	// planner simulates the renames of a real run so they can be confirmed before the first one (nil = no confirmation)
+	planner interfaces.FolderProcessor
+	// confirmPlan decides whether a real run with this many renames and resolved collisions goes ahead
+	confirmPlan func(renames, collisions int) bool
+	// relativePaths reports paths relative to the root instead of absolute
+	relativePaths bool
+	// interrupt stops the run before the next folder once it is closed (nil = never)
+	interrupt <-chan struct{}
+	// pacer pauses the run between folders, e.g. during quiet hours (nil = never)
+	pacer interfaces.Pacer
+	// hold keeps the run waiting between folders while the user has paused it (nil = never)
+	hold interfaces.Hold
+	// verifier re-checks the applied renames before the summary is reported (nil = never)
+	verifier interfaces.RenameVerifier
+	// runID identifies the run in the summary (empty = not recorded)
+	runID string
+	// riskAssessor flags risky names in check reports (nil = no risk warnings)
+	riskAssessor interfaces.RiskAssessor
+	// summary is the outcome of the most recent SanitizeDirectory call that got past the walk (nil = none yet)
+	summary *interfaces.ProcessingSummary
+}
+
+// ErrErrorBudgetExceeded is returned when a run is aborted by the error budget
+var ErrErrorBudgetExceeded = errors.New("error budget exceeded")
+
+// ErrTooManyRenames is returned when a run plans more renames than allowed and was not confirmed
+var ErrTooManyRenames = errors.New("too many renames")
+
+// ErrNotConfirmed is returned when the renames of a real run were not confirmed
+var ErrNotConfirmed = errors.New("not confirmed")
+
+// ErrInterrupted is returned when a run is stopped through its interrupt channel, e.g. by Ctrl-C
+var ErrInterrupted = errors.New("interrupted")
+
+// minErrorRateSample is the number of processed folders required before the error rate is evaluated
+// This prevents a single early failure from counting as a 100% error rate
+const minErrorRateSample = 20
+
+// Option configures optional SanitizeService behavior
+type Option func(*SanitizeService)
+
+// WithMaxErrors aborts the run once more than maxErrors errors occurred (0 = unlimited)
+func WithMaxErrors(maxErrors int) Option {
+	return func(ss *SanitizeService) {
+		ss.maxErrors = maxErrors
+	}
+}
+
+// WithMaxErrorRate aborts the run once the error percentage (0-100) exceeds maxErrorRate (0 = unlimited)
+// The rate is only evaluated after minErrorRateSample folders have been processed
+func WithMaxErrorRate(maxErrorRate float64) Option {
+	return func(ss *SanitizeService) {
+		ss.maxErrorRate = maxErrorRate
+	}
+}
+
+// WithMaxRenames holds a real run back when it plans more than maxRenames renames or more than maxPercent (0-100)
+// of the folders (0 = unlimited); confirm decides whether it goes ahead anyway, and a nil confirm never does
+func WithMaxRenames(maxRenames int, maxPercent float64, confirm func(reason string) bool) Option {
+	return func(ss *SanitizeService) {
+		ss.maxRenames = maxRenames
+		ss.maxRenamePercent = maxPercent
+		ss.confirmRenames = confirm
+	}
+}
+
+// WithConfirmation asks confirm before a real run renames anything; planner simulates the run in dry-run mode first,
+// so the number of renames and of collisions resolved with a suffix or a merge are known up front
+func WithConfirmation(planner interfaces.FolderProcessor, confirm func(renames, collisions int) bool) Option {
+	return func(ss *SanitizeService) {
+		ss.planner = planner
+		ss.confirmPlan = confirm
+	}
+}
+
+// WithRiskAssessor adds the names that comply but remain risky to check reports, apart from the violations
+func WithRiskAssessor(assessor interfaces.RiskAssessor) Option {
+	return func(ss *SanitizeService) {
+		ss.riskAssessor = assessor
+	}
+}
+
+// WithRelativePaths reports paths relative to the root so shared outputs don't leak mount details
+func WithRelativePaths(relativePaths bool) Option {
+	return func(ss *SanitizeService) {
+		ss.relativePaths = relativePaths
+	}
+}
+
+// WithRunID records the identifier of the run in the summary, for correlation with its journal and logs
+func WithRunID(runID string) Option {
+	return func(ss *SanitizeService) {
+		ss.runID = runID
+	}
 }
 
 // NewSanitizeService creates a new instance of SanitizeService with the provided dependencies
@@ -25,13 +135,51 @@ func NewSanitizeService(
 	walker interfaces.DirectoryWalker,
 	processor interfaces.FolderProcessor,
 	reporter interfaces.ProgressReporter,
+	options ...Option,
 ) *SanitizeService {
-	return &SanitizeService{
+	ss := &SanitizeService{
 		sanitizer: sanitizer,
 		walker:    walker,
 		processor: processor,
 		reporter:  reporter,
 	}
+
+	for _, option := range options {
+		option(ss)
+	}
+
+	return ss
+}
+
+// WithInterrupt stops scheduling folders once interrupt is closed; the folder in progress is finished first
+// The summary is still reported, with the folders that were not reached counted as remaining
+func WithInterrupt(interrupt <-chan struct{}) Option {
+	return func(ss *SanitizeService) {
+		ss.interrupt = interrupt
+	}
+}
+
+// WithPacer pauses the run between folders whenever the pacer asks for it
+// The pacer also learns how long each rename took, so it can back off under heavy load
+func WithPacer(pacer interfaces.Pacer) Option {
+	return func(ss *SanitizeService) {
+		ss.pacer = pacer
+	}
+}
+
+// WithHold lets the user pause the run between folders, in dry runs too; the folder in progress is finished first
+func WithHold(hold interfaces.Hold) Option {
+	return func(ss *SanitizeService) {
+		ss.hold = hold
+	}
+}
+
+// WithVerifier re-checks every applied rename once the run is over and adds the outcome to the summary
+// Dry runs apply nothing, so they are never verified
+func WithVerifier(verifier interfaces.RenameVerifier) Option {
+	return func(ss *SanitizeService) {
+		ss.verifier = verifier
+	}
 }
 
 // SanitizeDirectory performs the complete folder sanitization process
@@ -40,11 +188,14 @@ func (ss *SanitizeService) SanitizeDirectory(rootPath string, dryRun bool) error
 	startTime := time.Now()
 
 	// Step 1: Walk the directory tree to collect folder information
+	ss.observeScan(rootPath)
 	folders, err := ss.walker.Walk(rootPath)
+	warningCount := ss.reportWarnings(rootPath, ss.walkWarnings())
 	if err != nil {
 		ss.reporter.ReportError(fmt.Errorf("failed to walk directory tree: %w", err))
 		return err
 	}
+	folders = ss.markContested(folders)
 
 	// Initialize processing statistics
 	totalFolders := len(folders)
@@ -52,50 +203,194 @@ func (ss *SanitizeService) SanitizeDirectory(rootPath string, dryRun bool) error
 	renamedCount := 0
 	errorCount := 0
 	skippedCount := 0
+	abortReason := ""
+	var abortErr error
+	var owners, ruleStats, directories map[string]interfaces.GroupStats
+	var phases interfaces.PhaseCounts
+	var applied []interfaces.RenameResult
+	renamed := make(paths.Renames) // Folders renamed so far, so folders walked below them are found again
+
+	// A wrong --path must not rename a whole share before anyone notices
+	if !dryRun {
+		var confirmed bool
+		if abortReason, confirmed = ss.checkRenameLimit(folders); abortReason != "" {
+			abortErr = ErrTooManyRenames
+		} else if !confirmed && !ss.confirmRun(folders) {
+			abortReason = "the renames were not confirmed"
+			abortErr = ErrNotConfirmed
+		}
+		if abortReason != "" {
+			ss.reporter.ReportError(fmt.Errorf("aborting: %s", abortReason))
+		}
+	}
 
 	// Step 2: Process each folder for sanitization
-	for i, folder := range folders {
+	// Folders held open by other applications are queued again behind all others and retried once
+	queue := slices.Clone(folders)
+	firstRetry := len(queue)
+	reached := 0 // Queue entries before this one are processed or queued again
+	var locked []string
+	for next := 0; next < len(queue); next++ {
+		folder := queue[next]
+		retry := next >= firstRetry
+		if abortReason != "" {
+			break
+		}
+
+		// Renames are never cut short; an interrupt only takes effect between folders
+		if ss.interrupted() || ss.pause(next, len(queue)) {
+			abortReason = "stopped by the user"
+			abortErr = ErrInterrupted
+			break
+		}
+
 		// Report progress
+		directory := topLevelDirectory(rootPath, folder.Path) // As walked, before renamed parents changed the path
+		folder = currentFolder(folder, renamed)
+		ss.reportFolder(rootPath, next+1, len(queue), folder)
 		progressMsg := fmt.Sprintf("Processing: %s", folder.Name)
-		ss.reporter.ReportProgress(i+1, totalFolders, progressMsg)
+		if retry {
+			progressMsg = fmt.Sprintf("Retrying locked folder: %s", folder.Name)
+		}
+		ss.reporter.ReportProgress(next+1, len(queue), progressMsg)
 
-		// Sanitize the folder name
-		sanitizedName := ss.sanitizer.SanitizeName(folder.Name)
+		// Sanitize the folder name, remembering which rules changed it
+		sanitizedName, rules := ss.sanitizeFolder(folder)
 
 		// Process the rename operation
+		renameStart := time.Now()
 		result, err := ss.processor.ProcessRename(folder, sanitizedName, dryRun)
-		processedCount++
+		if errors.Is(err, interfaces.ErrHalted) {
+			abortReason = err.Error()
+			abortErr = interfaces.ErrHalted
+			ss.reporter.ReportError(fmt.Errorf("aborting: %s", abortReason))
+			break
+		}
+		if ss.pacer != nil {
+			ss.pacer.Observe(time.Since(renameStart))
+		}
+		reached = next + 1
 
-		if err != nil {
-			ss.reporter.ReportError(fmt.Errorf("failed to process folder %s: %w", folder.Path, err))
-			errorCount++
+		// The application holding the folder may have let go by the end of the run
+		if err == nil && result.Locked && !retry {
+			warningCount += ss.reportWarnings(rootPath, []error{fmt.Errorf("%s is in use by another application; retrying it at the end of the run", folder.Path)})
+			queue = append(queue, queue[next])
 			continue
 		}
+		processedCount++
+		if result != nil && result.WasRenamed {
+			result.Rules = rules
+			result.SanitizedName = sanitizedName
+		}
 
 		// Handle the result
-		if result.Error != nil {
-			ss.reporter.ReportError(fmt.Errorf("rename error for %s: %w", folder.Path, result.Error))
+		failed, wasRenamed, vanished := false, false, false
+		if err != nil {
+			err = ss.displayError(rootPath, err)
+			ss.reporter.ReportError(fmt.Errorf("failed to process folder %s: %w", ss.displayPath(rootPath, folder.Path), err))
+			ss.reportFailure(folder, err)
 			errorCount++
+			failed = true
+		} else if result.Vanished {
+			// Deleting or moving a folder during a run is not a failure of the run; there is just nothing left to rename
+			warningCount += ss.reportWarnings(rootPath, []error{fmt.Errorf("%s vanished before it could be renamed", folder.Path)})
+			vanished = true
+		} else if result.Error != nil {
+			renameErr := ss.displayError(rootPath, result.Error)
+			if result.Transient {
+				renameErr = fmt.Errorf("%w (transient error, a later retry may succeed)", renameErr)
+			}
+			ss.reporter.ReportError(fmt.Errorf("rename error for %s: %w", ss.displayPath(rootPath, folder.Path), renameErr))
+			ss.reportFailure(folder, renameErr)
+			if result.Locked {
+				locked = append(locked, ss.displayPath(rootPath, folder.Path))
+			}
+			errorCount++
+			failed = true
 		} else if result.WasRenamed && result.Success {
 			renamedCount++
+			renamed.Record(result.OldPath, result.NewPath)
+			wasRenamed = true
+			if !dryRun {
+				applied = append(applied, *result)
+			}
+			ss.reportRename(rootPath, *result)
 		} else if !result.WasRenamed {
 			skippedCount++
 		}
+
+		if vanished {
+			phases.Planned++
+			phases.Vanished++
+		} else {
+			phases = countPhase(phases, wasRenamed, failed, dryRun)
+		}
+
+		// Attribute renames and errors to the folder owner when the walker recorded one
+		if folder.Owner != "" {
+			owners = tallyGroup(owners, folder.Owner, wasRenamed, failed)
+		}
+		for _, rule := range rules {
+			ruleStats = tallyGroup(ruleStats, rule, wasRenamed, failed)
+		}
+		directories = tallyGroup(directories, directory, wasRenamed, failed)
+
+		// A disconnected share would fail every remaining folder, so stop with one clear error
+		if errors.Is(resultError(result, err), interfaces.ErrFileSystemUnavailable) {
+			abortReason = "the file system became unavailable (e.g. a network share disconnected)"
+			abortErr = interfaces.ErrFileSystemUnavailable
+			ss.reporter.ReportError(fmt.Errorf("aborting: %s", abortReason))
+			break
+		}
+
+		// Stop hammering the file system once the error budget is spent
+		if abortReason = ss.checkErrorBudget(errorCount, processedCount); abortReason != "" {
+			abortErr = ErrErrorBudgetExceeded
+			ss.reporter.ReportError(fmt.Errorf("aborting: %s", abortReason))
+			break
+		}
+	}
+
+	// Folders an aborted run didn't reach are still split into renames left for later and compliant names
+	for _, folder := range queue[reached:] {
+		if sanitizedName, _ := ss.sanitizeFolder(folder); sanitizedName != folder.Name {
+			phases.Planned++
+			phases.Deferred++
+		} else {
+			phases.Skipped++
+		}
 	}
 
 	// Step 3: Generate and report the final summary
 	elapsedTime := time.Since(startTime)
 	summary := interfaces.ProcessingSummary{
+		RunID:          ss.runID,
 		TotalFolders:   totalFolders,
 		ProcessedCount: processedCount,
 		RenamedCount:   renamedCount,
 		ErrorCount:     errorCount,
+		WarningCount:   warningCount,
 		SkippedCount:   skippedCount,
 		ElapsedTime:    elapsedTime.String(),
+		RemainingCount: totalFolders - processedCount,
+		Aborted:        abortReason != "",
+		AbortReason:    abortReason,
+		Owners:         owners,
+		Rules:          ruleStats,
+		Directories:    directories,
+		DryRun:         dryRun,
+		Phases:         phases,
+		Verification:   ss.verify(rootPath, applied, dryRun),
+		Locked:         locked,
 	}
 
+	ss.summary = &summary
 	ss.reporter.ReportComplete(summary)
 
+	if summary.Aborted {
+		return fmt.Errorf("%w: %s", abortErr, abortReason)
+	}
+
 	// Return error if there were critical issues
 	if errorCount > 0 && renamedCount == 0 {
 		return fmt.Errorf("sanitization completed with %d errors and no successful renames", errorCount)
@@ -103,3 +398,406 @@ func (ss *SanitizeService) SanitizeDirectory(rootPath string, dryRun bool) error
 
 	return nil
 }
+
+// Summary returns the summary of the most recent SanitizeDirectory call, or nil if none got past the walk
+// Callers use it to tell a run without changes from one that renamed folders or completed with errors
+func (ss *SanitizeService) Summary() *interfaces.ProcessingSummary {
+	return ss.summary
+}
+
+// currentFolder returns the folder at its path after the renames of the run so far
+// Walked paths below a renamed parent are stale, e.g. in a top-down run; the rules and reports need the current one.
+func currentFolder(folder interfaces.FolderInfo, renamed paths.Renames) interfaces.FolderInfo {
+	if len(renamed) == 0 {
+		return folder
+	}
+	folder.Path = renamed.Current(folder.Path)
+	folder.Parent = filepath.Dir(folder.Path)
+	return folder
+}
+
+// verify re-checks the applied renames when a verifier is configured, with paths shown like in every other report
+func (ss *SanitizeService) verify(rootPath string, applied []interfaces.RenameResult, dryRun bool) *interfaces.Verification {
+	if ss.verifier == nil || dryRun {
+		return nil
+	}
+
+	verification := &interfaces.Verification{Checked: len(applied)}
+	for _, discrepancy := range ss.verifier.VerifyRenames(applied) {
+		verification.Discrepancies = append(verification.Discrepancies, ss.displayError(rootPath, discrepancy).Error())
+	}
+	return verification
+}
+
+// observeScan forwards the discovery progress of the walker to the reporter when both support it
+// Paths are shown the same way as in every other report
+func (ss *SanitizeService) observeScan(rootPath string) {
+	scanReporter, reports := ss.reporter.(interfaces.ScanReporter)
+	scanningWalker, scans := ss.walker.(interfaces.ScanningWalker)
+	if !reports || !scans {
+		return
+	}
+	scanningWalker.ObserveScan(func(scanned int, path string) {
+		scanReporter.ReportScan(scanned, ss.displayPath(rootPath, path))
+	})
+}
+
+// interrupted reports whether the interrupt channel has been closed
+func (ss *SanitizeService) interrupted() bool {
+	select {
+	case <-ss.interrupt:
+		return true
+	default:
+		return false
+	}
+}
+
+// pause waits while the user holds the run, then for as long as the pacer asks before the next folder, and reports why
+// It returns true when the run was interrupted while waiting
+func (ss *SanitizeService) pause(processed, total int) bool {
+	if ss.held(processed, total) {
+		return true
+	}
+	if ss.pacer == nil {
+		return false
+	}
+
+	for {
+		wait, reason := ss.pacer.Pause()
+		if wait <= 0 {
+			return false
+		}
+
+		ss.reporter.ReportProgress(processed, total, fmt.Sprintf("Paused for %s: %s", wait.Round(time.Second), reason))
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ss.interrupt:
+			timer.Stop()
+			return true
+		}
+	}
+}
+
+// held waits until the user resumes a run they paused
+// It returns true when the run was interrupted while held
+func (ss *SanitizeService) held(processed, total int) bool {
+	if ss.hold == nil {
+		return false
+	}
+	released := ss.hold.Released()
+	if released == nil {
+		return false
+	}
+
+	ss.reporter.ReportProgress(processed, total, "Paused by the user")
+	select {
+	case <-released:
+		return false
+	case <-ss.interrupt:
+		return true
+	}
+}
+
+// CheckDirectory scans the tree and reports every non-compliant folder name without changing anything
+// This method backs check (lint) mode and is safe to run on read-only trees
+func (ss *SanitizeService) CheckDirectory(rootPath string) (*interfaces.CheckReport, error) {
+	folders, err := ss.walker.Walk(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory tree: %w", err)
+	}
+
+	report := &interfaces.CheckReport{TotalFolders: len(folders)}
+	for _, warning := range ss.walkWarnings() {
+		report.Warnings = append(report.Warnings, ss.displayError(rootPath, warning))
+	}
+
+	for _, folder := range folders {
+		// Report-only rules (e.g. reserved words) flag a name without changing it
+		sanitizedName, rules := ss.sanitizeFolder(folder)
+
+		// Risks are assessed on the name the folder ends up with, so renaming never leaves a risk unreported
+		if ss.riskAssessor != nil {
+			if risks := ss.riskAssessor.AssessName(folder, sanitizedName); len(risks) > 0 {
+				report.Risks = append(report.Risks, interfaces.Risk{
+					Path:  folder.Path,
+					Name:  sanitizedName,
+					Risks: risks,
+					Owner: folder.Owner,
+				})
+			}
+		}
+
+		if sanitizedName == folder.Name && len(rules) == 0 {
+			continue
+		}
+
+		report.Violations = append(report.Violations, interfaces.Violation{
+			Path:          folder.Path,
+			Name:          folder.Name,
+			SanitizedName: sanitizedName,
+			Rules:         rules,
+			Owner:         folder.Owner,
+		})
+	}
+
+	return report, nil
+}
+
+// ProfileDirectory scans the tree and passes every folder with the rules its name violates to visit, without changing anything
+// Compliant folders are visited too, with no rules; the walk warnings are returned, and an error from visit stops the scan
+func (ss *SanitizeService) ProfileDirectory(rootPath string, visit func(folder interfaces.FolderInfo, rules []string) error) ([]error, error) {
+	folders, err := ss.walker.Walk(rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory tree: %w", err)
+	}
+
+	var warnings []error
+	for _, warning := range ss.walkWarnings() {
+		warnings = append(warnings, ss.displayError(rootPath, warning))
+	}
+
+	for _, folder := range folders {
+		_, rules := ss.sanitizeFolder(folder)
+		if err := visit(folder, rules); err != nil {
+			return warnings, err
+		}
+	}
+
+	return warnings, nil
+}
+
+// sanitizeFolder returns the sanitized name of a folder and, when the sanitizer can explain itself, the rules that fired
+// Sanitizers that use the folder's location get the whole FolderInfo rather than just the name
+func (ss *SanitizeService) sanitizeFolder(folder interfaces.FolderInfo) (string, []string) {
+	return interfaces.Explain(ss.sanitizer, folder)
+}
+
+// markContested flags the folders that share their new name with a sibling that is renamed too
+// Names are compared case-insensitively, so the flags don't depend on the file system; folders is cloned before any change
+func (ss *SanitizeService) markContested(folders []interfaces.FolderInfo) []interfaces.FolderInfo {
+	targets := make(map[string][]int)
+	for i, folder := range folders {
+		if sanitizedName, _ := ss.sanitizeFolder(folder); sanitizedName != folder.Name {
+			target := strings.ToLower(filepath.Join(folder.Parent, sanitizedName))
+			targets[target] = append(targets[target], i)
+		}
+	}
+
+	var marked []interfaces.FolderInfo
+	for _, members := range targets {
+		if len(members) < 2 {
+			continue
+		}
+		if marked == nil {
+			marked = slices.Clone(folders)
+		}
+		for _, i := range members {
+			marked[i].Contested = true
+		}
+	}
+	if marked == nil {
+		return folders
+	}
+	return marked
+}
+
+// resultError returns the error of a processed folder, whether it was returned or recorded in the result
+func resultError(result *interfaces.RenameResult, err error) error {
+	if err != nil || result == nil {
+		return err
+	}
+	return result.Error
+}
+
+// countPhase adds the outcome of a processed folder to the phase counts
+// A dry run's renames are only checked, so they count as renames a real run would perform
+func countPhase(phases interfaces.PhaseCounts, renamed, failed, dryRun bool) interfaces.PhaseCounts {
+	switch {
+	case failed:
+		phases.Failed++
+	case renamed && dryRun:
+		phases.WouldRename++
+	case renamed:
+		phases.Applied++
+	default:
+		phases.Skipped++
+		return phases
+	}
+	phases.Planned++
+	return phases
+}
+
+// tallyGroup adds a folder's outcome to the statistics of its group, e.g. its owner, creating the map on first use
+// Folders that were neither renamed nor failed are not counted
+func tallyGroup(groups map[string]interfaces.GroupStats, group string, renamed, failed bool) map[string]interfaces.GroupStats {
+	if !renamed && !failed {
+		return groups
+	}
+	if groups == nil {
+		groups = make(map[string]interfaces.GroupStats)
+	}
+
+	stats := groups[group]
+	if renamed {
+		stats.RenamedCount++
+	}
+	if failed {
+		stats.ErrorCount++
+	}
+	groups[group] = stats
+
+	return groups
+}
+
+// topLevelDirectory returns the name of the directory directly below the root that contains path
+// Folders directly below the root are their own top-level directory; the root and anything outside it count as "."
+func topLevelDirectory(rootPath, path string) string {
+	relative, err := filepath.Rel(rootPath, path)
+	if err != nil || relative == "." || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "."
+	}
+	top, _, _ := strings.Cut(relative, string(filepath.Separator))
+	return top
+}
+
+// reportFolder forwards the current folder to the reporter if it wants structured progress
+// Paths are shown the same way as in every other report
+func (ss *SanitizeService) reportFolder(rootPath string, current, total int, folder interfaces.FolderInfo) {
+	if folderReporter, ok := ss.reporter.(interfaces.FolderReporter); ok {
+		folder.Path = ss.displayPath(rootPath, folder.Path)
+		folder.Parent = ss.displayPath(rootPath, folder.Parent)
+		folderReporter.ReportFolder(current, total, folder)
+	}
+}
+
+// reportRename forwards a successful rename to the reporter if it supports per-rename output
+// Reporters that don't implement RenameReporter are left untouched
+func (ss *SanitizeService) reportRename(rootPath string, result interfaces.RenameResult) {
+	if renameReporter, ok := ss.reporter.(interfaces.RenameReporter); ok {
+		result.OldPath = ss.displayPath(rootPath, result.OldPath)
+		result.NewPath = ss.displayPath(rootPath, result.NewPath)
+		renameReporter.ReportRename(result)
+	}
+}
+
+// reportFailure forwards a failed folder to the reporter if it collects failures
+// Reporters that don't implement FailureReporter are left untouched
+func (ss *SanitizeService) reportFailure(folder interfaces.FolderInfo, err error) {
+	if failureReporter, ok := ss.reporter.(interfaces.FailureReporter); ok {
+		failureReporter.ReportFailure(folder, err)
+	}
+}
+
+// walkWarnings returns the problems the most recent walk skipped over, if the walker collects them
+func (ss *SanitizeService) walkWarnings() []error {
+	if warningWalker, ok := ss.walker.(interfaces.WarningWalker); ok {
+		return warningWalker.Warnings()
+	}
+	return nil
+}
+
+// reportWarnings forwards warnings to the reporter if it shows them and returns how many there were
+// Reporters that don't implement WarningReporter still see the count in the summary
+func (ss *SanitizeService) reportWarnings(rootPath string, warnings []error) int {
+	if warningReporter, ok := ss.reporter.(interfaces.WarningReporter); ok {
+		for _, warning := range warnings {
+			warningReporter.ReportWarning(ss.displayError(rootPath, warning))
+		}
+	}
+	return len(warnings)
+}
+
+// displayPath returns the path as it should appear in reports (relative to the root if configured)
+func (ss *SanitizeService) displayPath(rootPath, path string) string {
+	if ss.relativePaths {
+		return paths.Relative(rootPath, path)
+	}
+	return path
+}
+
+// displayError rewrites paths inside an error message relative to the root if configured
+func (ss *SanitizeService) displayError(rootPath string, err error) error {
+	if ss.relativePaths {
+		return paths.RelativeError(rootPath, err)
+	}
+	return err
+}
+
+// checkRenameLimit returns a non-empty reason when the planned renames exceed the limit and were not confirmed
+// It also reports whether the user confirmed renaming over the limit, which makes any further confirmation redundant.
+// Like the error rate, the percentage is only evaluated for at least minErrorRateSample folders.
+func (ss *SanitizeService) checkRenameLimit(folders []interfaces.FolderInfo) (string, bool) {
+	if ss.maxRenames <= 0 && ss.maxRenamePercent <= 0 {
+		return "", false
+	}
+
+	planned := 0
+	for _, folder := range folders {
+		if sanitizedName, _ := ss.sanitizeFolder(folder); sanitizedName != folder.Name {
+			planned++
+		}
+	}
+
+	reason := ""
+	if ss.maxRenames > 0 && planned > ss.maxRenames {
+		reason = fmt.Sprintf("%d planned renames exceed the limit of %d", planned, ss.maxRenames)
+	} else if ss.maxRenamePercent > 0 && len(folders) >= minErrorRateSample {
+		percent := float64(planned) / float64(len(folders)) * 100
+		if percent > ss.maxRenamePercent {
+			reason = fmt.Sprintf("%d planned renames (%.1f%% of %d folders) exceed the limit of %.1f%%", planned, percent, len(folders), ss.maxRenamePercent)
+		}
+	}
+
+	switch {
+	case reason == "":
+		return "", false
+	case ss.confirmRenames != nil && ss.confirmRenames(reason):
+		return "", true
+	default:
+		return reason, false
+	}
+}
+
+// confirmRun simulates the run with the planner and asks for confirmation when a confirmation is configured
+// A run without renames has nothing to confirm
+func (ss *SanitizeService) confirmRun(folders []interfaces.FolderInfo) bool {
+	if ss.planner == nil || ss.confirmPlan == nil {
+		return true
+	}
+
+	renames, collisions := 0, 0
+	renamed := make(paths.Renames)
+	for _, folder := range folders {
+		folder = currentFolder(folder, renamed)
+		sanitizedName, _ := ss.sanitizeFolder(folder)
+		result, err := ss.planner.ProcessRename(folder, sanitizedName, true)
+		if err != nil || result == nil || !result.WasRenamed {
+			continue
+		}
+		renamed.Record(result.OldPath, result.NewPath)
+		renames++
+		if result.Merged || filepath.Base(result.NewPath) != sanitizedName {
+			collisions++
+		}
+	}
+
+	return renames == 0 || ss.confirmPlan(renames, collisions)
+}
+
+// checkErrorBudget returns a non-empty reason when the configured error budget has been exceeded
+// This method evaluates both the absolute error count and the error rate
+func (ss *SanitizeService) checkErrorBudget(errorCount, processedCount int) string {
+	if ss.maxErrors > 0 && errorCount > ss.maxErrors {
+		return fmt.Sprintf("%d errors exceed the limit of %d", errorCount, ss.maxErrors)
+	}
+
+	if ss.maxErrorRate > 0 && processedCount >= minErrorRateSample {
+		rate := float64(errorCount) / float64(processedCount) * 100
+		if rate > ss.maxErrorRate {
+			return fmt.Sprintf("error rate %.1f%% exceeds the limit of %.1f%%", rate, ss.maxErrorRate)
+		}
+	}
+
+	return ""
+}
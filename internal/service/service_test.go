@@ -4,10 +4,15 @@ package service_test
 
 import (
 	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
-	"sanitize/internal/interfaces"
-	"sanitize/internal/service"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/service"
 )
 
 // Mock implementations for testing
@@ -165,6 +170,34 @@ func TestSanitizeService_SanitizeDirectory_WalkError(t *testing.T) {
 	}
 }
 
+// TestSanitizeService_Summary tests that the summary of the last run is kept, and that a failed walk leaves none
+func TestSanitizeService_Summary(t *testing.T) {
+	walkErr := errors.New("walk failed")
+	walker := &mockWalker{}
+	svc := service.NewSanitizeService(&mockSanitizer{}, walker, &mockProcessor{}, &mockReporter{})
+
+	if svc.Summary() != nil {
+		t.Error("Expected no summary before the first run")
+	}
+
+	walker.walkFunc = func(path string) ([]interfaces.FolderInfo, error) {
+		return nil, walkErr
+	}
+	_ = svc.SanitizeDirectory("/test", false)
+	if svc.Summary() != nil {
+		t.Error("Expected no summary after a failed walk")
+	}
+
+	walker.walkFunc = nil
+	if err := svc.SanitizeDirectory("/test", false); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	summary := svc.Summary()
+	if summary == nil || summary.Phases.Applied != 2 {
+		t.Errorf("Expected a summary with 2 applied renames, got %+v", summary)
+	}
+}
+
 // TestSanitizeService_SanitizeDirectory_ProcessingErrors tests handling of processing errors
 func TestSanitizeService_SanitizeDirectory_ProcessingErrors(t *testing.T) {
 	sanitizer := &mockSanitizer{}
@@ -326,3 +359,1165 @@ func TestSanitizeService_SanitizeDirectory_EmptyDirectory(t *testing.T) {
 		t.Errorf("Expected 0 total folders, got %d", summary.TotalFolders)
 	}
 }
+
+// mockRenameReporter extends mockReporter with the optional RenameReporter interface
+type mockRenameReporter struct {
+	mockReporter
+	renameCalls []interfaces.RenameResult
+}
+
+func (m *mockRenameReporter) ReportRename(result interfaces.RenameResult) {
+	m.renameCalls = append(m.renameCalls, result)
+}
+
+// TestSanitizeService_SanitizeDirectory_RenameReporter tests that renames are forwarded to reporters that support them
+func TestSanitizeService_SanitizeDirectory_RenameReporter(t *testing.T) {
+	sanitizer := &mockSanitizer{
+		sanitizeFunc: func(name string) string {
+			if name == "folder1" {
+				return name // No change needed
+			}
+			return name + "_clean"
+		},
+	}
+
+	walker := &mockWalker{}
+	processor := &mockProcessor{}
+	reporter := &mockRenameReporter{}
+
+	svc := service.NewSanitizeService(sanitizer, walker, processor, reporter)
+
+	err := svc.SanitizeDirectory("/test", false)
+	if err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	if len(reporter.renameCalls) != 1 {
+		t.Fatalf("Expected 1 rename call, got %d", len(reporter.renameCalls))
+	}
+
+	if reporter.renameCalls[0].OldPath != "/test/folder2" {
+		t.Errorf("Expected rename of /test/folder2, got %s", reporter.renameCalls[0].OldPath)
+	}
+}
+
+// mockRuleExplainer renames folder2 and explains the change with a rule
+type mockRuleExplainer struct {
+	mockSanitizer
+}
+
+func (m *mockRuleExplainer) ExplainName(name string) (string, []string) {
+	if name == "folder2" {
+		return name + "_clean", []string{"invalid-characters"}
+	}
+	return name, nil
+}
+
+// TestSanitizeService_SanitizeDirectory_RenameRules tests that renames carry the rules that changed the name
+func TestSanitizeService_SanitizeDirectory_RenameRules(t *testing.T) {
+	reporter := &mockRenameReporter{}
+	svc := service.NewSanitizeService(&mockRuleExplainer{}, &mockWalker{}, &mockProcessor{}, reporter)
+
+	if err := svc.SanitizeDirectory("/test", true); err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	if len(reporter.renameCalls) != 1 {
+		t.Fatalf("Expected 1 rename call, got %d", len(reporter.renameCalls))
+	}
+	if rules := reporter.renameCalls[0].Rules; len(rules) != 1 || rules[0] != "invalid-characters" {
+		t.Errorf("Expected the rename to carry [invalid-characters], got %v", rules)
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_Contested tests that siblings renamed to the same name are flagged before processing
+func TestSanitizeService_SanitizeDirectory_Contested(t *testing.T) {
+	sanitizer := &mockSanitizer{sanitizeFunc: func(name string) string {
+		return strings.NewReplacer("é", "e", "ë", "e", "Ë", "E", "?", "").Replace(name)
+	}}
+	walker := &mockWalker{
+		walkFunc: func(string) ([]interfaces.FolderInfo, error) {
+			return []interfaces.FolderInfo{
+				{Path: "/test/Café", Name: "Café", Depth: 1, Parent: "/test"},
+				{Path: "/test/CAFË", Name: "CAFË", Depth: 1, Parent: "/test"}, // Clashes on case-insensitive file systems
+				{Path: "/test/other?", Name: "other?", Depth: 1, Parent: "/test"},
+				{Path: "/test/sub/Cafë", Name: "Cafë", Depth: 2, Parent: "/test/sub"},
+				{Path: "/test/sub/Cafe", Name: "Cafe", Depth: 2, Parent: "/test/sub"}, // Compliant, so an existing target rather than a contest
+			}, nil
+		},
+	}
+	contested := make(map[string]bool)
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			contested[folder.Path] = folder.Contested
+			return &interfaces.RenameResult{Success: true, OldPath: folder.Path, WasRenamed: folder.Name != newName}, nil
+		},
+	}
+
+	svc := service.NewSanitizeService(sanitizer, walker, processor, &mockReporter{})
+	if err := svc.SanitizeDirectory("/test", true); err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	expected := map[string]bool{"/test/Café": true, "/test/CAFË": true, "/test/other?": false, "/test/sub/Cafë": false, "/test/sub/Cafe": false}
+	for path, want := range expected {
+		if contested[path] != want {
+			t.Errorf("Expected %s to be contested=%v, got %v", path, want, contested[path])
+		}
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_Breakdown tests that renames and errors are broken down by rule and top-level directory
+func TestSanitizeService_SanitizeDirectory_Breakdown(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(string) ([]interfaces.FolderInfo, error) {
+			return []interfaces.FolderInfo{
+				{Path: "/test/a/x/folder2", Name: "folder2", Depth: 3, Parent: "/test/a/x"},
+				{Path: "/test/a/folder2", Name: "folder2", Depth: 2, Parent: "/test/a"},
+				{Path: "/test/b/folder1", Name: "folder1", Depth: 2, Parent: "/test/b"},
+				{Path: "/test/folder2", Name: "folder2", Depth: 1, Parent: "/test"},
+			}, nil
+		},
+	}
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			if folder.Path == "/test/a/folder2" {
+				return nil, errors.New("access denied")
+			}
+			return &interfaces.RenameResult{Success: true, OldPath: folder.Path, WasRenamed: folder.Name != newName}, nil
+		},
+	}
+	reporter := &mockReporter{}
+
+	svc := service.NewSanitizeService(&mockRuleExplainer{}, walker, processor, reporter)
+	if err := svc.SanitizeDirectory("/test", true); err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	summary := reporter.completeCalls[0]
+	if got, want := summary.Rules["invalid-characters"], (interfaces.GroupStats{RenamedCount: 2, ErrorCount: 1}); len(summary.Rules) != 1 || got != want {
+		t.Errorf("Expected rules {invalid-characters: %+v}, got %v", want, summary.Rules)
+	}
+
+	expected := map[string]interfaces.GroupStats{
+		"a":       {RenamedCount: 1, ErrorCount: 1},
+		"folder2": {RenamedCount: 1},
+	}
+	if len(summary.Directories) != len(expected) {
+		t.Fatalf("Expected directories %v, got %v", expected, summary.Directories)
+	}
+	for directory, stats := range expected {
+		if summary.Directories[directory] != stats {
+			t.Errorf("Expected %s to have %+v, got %+v", directory, stats, summary.Directories[directory])
+		}
+	}
+}
+
+// failingFolders builds a list of folders for error budget tests
+func failingFolders(count int) []interfaces.FolderInfo {
+	folders := make([]interfaces.FolderInfo, count)
+	for i := range folders {
+		name := fmt.Sprintf("folder%d", i)
+		folders[i] = interfaces.FolderInfo{Path: "/test/" + name, Name: name, Depth: 1, Parent: "/test"}
+	}
+	return folders
+}
+
+// TestSanitizeService_SanitizeDirectory_MaxErrors tests aborting once the error count exceeds the budget
+func TestSanitizeService_SanitizeDirectory_MaxErrors(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(path string) ([]interfaces.FolderInfo, error) {
+			return failingFolders(10), nil
+		},
+	}
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			return nil, errors.New("filer unavailable")
+		},
+	}
+	reporter := &mockReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, walker, processor, reporter, service.WithMaxErrors(3))
+
+	err := svc.SanitizeDirectory("/test", false)
+	if !errors.Is(err, service.ErrErrorBudgetExceeded) {
+		t.Fatalf("Expected ErrErrorBudgetExceeded, got %v", err)
+	}
+
+	if len(reporter.completeCalls) != 1 {
+		t.Fatalf("Expected 1 complete call, got %d", len(reporter.completeCalls))
+	}
+
+	summary := reporter.completeCalls[0]
+	if !summary.Aborted {
+		t.Error("Expected summary to be marked as aborted")
+	}
+	if summary.ProcessedCount != 4 {
+		t.Errorf("Expected processing to stop after 4 folders, got %d", summary.ProcessedCount)
+	}
+	want := interfaces.PhaseCounts{Planned: 10, Failed: 4, Deferred: 6}
+	if summary.Phases != want {
+		t.Errorf("Expected unreached folders to be deferred, got %+v", summary.Phases)
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_MaxErrorRate tests aborting once the error rate exceeds the budget
+func TestSanitizeService_SanitizeDirectory_MaxErrorRate(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(path string) ([]interfaces.FolderInfo, error) {
+			return failingFolders(100), nil
+		},
+	}
+	calls := 0
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			calls++
+			if calls%2 == 0 {
+				return nil, errors.New("filer unavailable")
+			}
+			return &interfaces.RenameResult{Success: true, OldPath: folder.Path, NewPath: folder.Path}, nil
+		},
+	}
+	reporter := &mockReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, walker, processor, reporter, service.WithMaxErrorRate(25))
+
+	err := svc.SanitizeDirectory("/test", false)
+	if !errors.Is(err, service.ErrErrorBudgetExceeded) {
+		t.Fatalf("Expected ErrErrorBudgetExceeded, got %v", err)
+	}
+
+	// The rate is only evaluated once enough folders have been processed
+	summary := reporter.completeCalls[0]
+	if summary.ProcessedCount != 20 {
+		t.Errorf("Expected processing to stop after 20 folders, got %d", summary.ProcessedCount)
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_MaxRenames tests holding back runs that plan more renames than allowed
+func TestSanitizeService_SanitizeDirectory_MaxRenames(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(path string) ([]interfaces.FolderInfo, error) {
+			return failingFolders(30), nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		maxRenames int
+		maxPercent float64
+		dryRun     bool
+		confirm    func(reason string) bool
+		wantAbort  bool
+	}{
+		{name: "count exceeded", maxRenames: 10, wantAbort: true},
+		{name: "percentage exceeded", maxPercent: 50, wantAbort: true},
+		{name: "declined", maxRenames: 10, confirm: func(string) bool { return false }, wantAbort: true},
+		{name: "confirmed", maxRenames: 10, confirm: func(string) bool { return true }},
+		{name: "within limits", maxRenames: 30, maxPercent: 100},
+		{name: "dry run", maxRenames: 10, dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed := 0
+			processor := &mockProcessor{
+				processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+					processed++
+					return &interfaces.RenameResult{Success: true, OldPath: folder.Path, NewPath: folder.Parent + "/" + newName, WasRenamed: true}, nil
+				},
+			}
+			reporter := &mockReporter{}
+
+			svc := service.NewSanitizeService(&mockSanitizer{}, walker, processor, reporter,
+				service.WithMaxRenames(tt.maxRenames, tt.maxPercent, tt.confirm))
+
+			err := svc.SanitizeDirectory("/test", tt.dryRun)
+			if !tt.wantAbort {
+				if err != nil || processed != 30 {
+					t.Fatalf("Expected all 30 folders to be processed, got %d (error %v)", processed, err)
+				}
+				return
+			}
+
+			if !errors.Is(err, service.ErrTooManyRenames) {
+				t.Fatalf("Expected ErrTooManyRenames, got %v", err)
+			}
+			if processed != 0 {
+				t.Errorf("Expected no folder to be processed, got %d", processed)
+			}
+			summary := reporter.completeCalls[0]
+			if !summary.Aborted || summary.RemainingCount != 30 {
+				t.Errorf("Expected an aborted summary with 30 remaining folders, got %+v", summary)
+			}
+			if want := (interfaces.PhaseCounts{Planned: 30, Deferred: 30}); summary.Phases != want {
+				t.Errorf("Expected every rename to be deferred, got %+v", summary.Phases)
+			}
+		})
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_Confirmation tests asking for confirmation with the simulated renames before a real run
+func TestSanitizeService_SanitizeDirectory_Confirmation(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(path string) ([]interfaces.FolderInfo, error) {
+			return failingFolders(3), nil
+		},
+	}
+	// The planner resolves a collision for the second folder and merges the third
+	planner := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			if !dryRun {
+				t.Errorf("Expected the planner to simulate %s", folder.Name)
+			}
+			result := &interfaces.RenameResult{Success: true, OldPath: folder.Path, NewPath: folder.Parent + "/" + newName, WasRenamed: folder.Name != newName}
+			switch {
+			case !result.WasRenamed:
+				result.NewPath = folder.Path
+			case folder.Name == "folder1":
+				result.NewPath += "_1"
+			case folder.Name == "folder2":
+				result.Merged = true
+			}
+			return result, nil
+		},
+	}
+
+	for _, answer := range []bool{true, false} {
+		t.Run(fmt.Sprintf("answer %v", answer), func(t *testing.T) {
+			processed := 0
+			processor := &mockProcessor{
+				processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+					processed++
+					return &interfaces.RenameResult{Success: true, OldPath: folder.Path, NewPath: folder.Parent + "/" + newName, WasRenamed: true}, nil
+				},
+			}
+			var renames, collisions int
+			svc := service.NewSanitizeService(&mockSanitizer{}, walker, processor, &mockReporter{},
+				service.WithConfirmation(planner, func(r, c int) bool {
+					renames, collisions = r, c
+					return answer
+				}))
+
+			err := svc.SanitizeDirectory("/test", false)
+			if renames != 3 || collisions != 2 {
+				t.Errorf("Expected 3 renames with 2 collisions to be confirmed, got %d and %d", renames, collisions)
+			}
+			if answer && (err != nil || processed != 3) {
+				t.Errorf("Expected a confirmed run to process 3 folders, got %d (error %v)", processed, err)
+			}
+			if !answer && (!errors.Is(err, service.ErrNotConfirmed) || processed != 0) {
+				t.Errorf("Expected a declined run to process nothing, got %d (error %v)", processed, err)
+			}
+		})
+	}
+
+	// Dry runs and runs without renames have nothing to confirm
+	asked := false
+	confirm := service.WithConfirmation(planner, func(int, int) bool {
+		asked = true
+		return false
+	})
+	if err := service.NewSanitizeService(&mockSanitizer{}, walker, &mockProcessor{}, &mockReporter{}, confirm).SanitizeDirectory("/test", true); err != nil || asked {
+		t.Errorf("Expected a dry run to go ahead without asking, got error %v", err)
+	}
+	compliant := &mockSanitizer{sanitizeFunc: func(name string) string { return name }}
+	if err := service.NewSanitizeService(compliant, walker, &mockProcessor{}, &mockReporter{}, confirm).SanitizeDirectory("/test", false); err != nil || asked {
+		t.Errorf("Expected a run without renames to go ahead without asking, got error %v", err)
+	}
+}
+
+// TestSanitizeService_CheckDirectory tests that check mode reports violations without processing
+func TestSanitizeService_CheckDirectory(t *testing.T) {
+	sanitizer := &mockSanitizer{
+		sanitizeFunc: func(name string) string {
+			if name == "folder1" {
+				return name // Already compliant
+			}
+			return name + "_clean"
+		},
+	}
+
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			t.Error("Check mode must not process renames")
+			return nil, nil
+		},
+	}
+
+	svc := service.NewSanitizeService(sanitizer, &mockWalker{}, processor, &mockReporter{})
+
+	report, err := svc.CheckDirectory("/test")
+	if err != nil {
+		t.Fatalf("CheckDirectory() returned error: %v", err)
+	}
+
+	if report.TotalFolders != 2 {
+		t.Errorf("Expected 2 total folders, got %d", report.TotalFolders)
+	}
+	if len(report.Violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %d", len(report.Violations))
+	}
+	if report.Violations[0].SanitizedName != "folder2_clean" {
+		t.Errorf("Expected suggested name folder2_clean, got %s", report.Violations[0].SanitizedName)
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_RelativePaths tests that reported paths are relative to the root
+func TestSanitizeService_SanitizeDirectory_RelativePaths(t *testing.T) {
+	reporter := &mockRenameReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, &mockWalker{}, &mockProcessor{}, reporter, service.WithRelativePaths(true))
+
+	if err := svc.SanitizeDirectory("/test", false); err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	if len(reporter.renameCalls) != 2 {
+		t.Fatalf("Expected 2 rename calls, got %d", len(reporter.renameCalls))
+	}
+
+	result := reporter.renameCalls[0]
+	if result.OldPath != "folder1" || result.NewPath != "folder1_sanitized" {
+		t.Errorf("Expected relative paths, got %s -> %s", result.OldPath, result.NewPath)
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_Owners tests that renames and errors are attributed to folder owners
+func TestSanitizeService_SanitizeDirectory_Owners(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(string) ([]interfaces.FolderInfo, error) {
+			return []interfaces.FolderInfo{
+				{Path: "/test/a", Name: "a", Parent: "/test", Owner: "alice"},
+				{Path: "/test/b", Name: "b", Parent: "/test", Owner: "alice"},
+				{Path: "/test/c", Name: "c", Parent: "/test", Owner: "bob"},
+				{Path: "/test/ok", Name: "ok", Parent: "/test", Owner: "carol"},
+			}, nil
+		},
+	}
+	sanitizer := &mockSanitizer{
+		sanitizeFunc: func(name string) string {
+			if name == "ok" {
+				return name
+			}
+			return name + "_sanitized"
+		},
+	}
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			if folder.Name == "b" {
+				return nil, errors.New("access denied")
+			}
+			return &interfaces.RenameResult{Success: true, OldPath: folder.Path, WasRenamed: folder.Name != newName}, nil
+		},
+	}
+	reporter := &mockReporter{}
+
+	svc := service.NewSanitizeService(sanitizer, walker, processor, reporter)
+	if err := svc.SanitizeDirectory("/test", false); err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	expected := map[string]interfaces.GroupStats{
+		"alice": {RenamedCount: 1, ErrorCount: 1},
+		"bob":   {RenamedCount: 1},
+	}
+	owners := reporter.completeCalls[0].Owners
+	if len(owners) != len(expected) {
+		t.Fatalf("Expected owners %v, got %v", expected, owners)
+	}
+	for owner, stats := range expected {
+		if owners[owner] != stats {
+			t.Errorf("Expected %s to have %+v, got %+v", owner, stats, owners[owner])
+		}
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_FileSystemUnavailable tests that a disconnected share stops the run
+func TestSanitizeService_SanitizeDirectory_FileSystemUnavailable(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(string) ([]interfaces.FolderInfo, error) {
+			return failingFolders(10), nil
+		},
+	}
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			return &interfaces.RenameResult{
+				OldPath: folder.Path,
+				Error:   fmt.Errorf("rename operation failed: %w", interfaces.ErrFileSystemUnavailable),
+			}, nil
+		},
+	}
+	reporter := &mockReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, walker, processor, reporter)
+	err := svc.SanitizeDirectory("/test", false)
+	if !errors.Is(err, interfaces.ErrFileSystemUnavailable) {
+		t.Fatalf("Expected ErrFileSystemUnavailable, got %v", err)
+	}
+
+	summary := reporter.completeCalls[0]
+	if !summary.Aborted || summary.ProcessedCount != 1 {
+		t.Errorf("Expected the run to abort after the first folder, got %+v", summary)
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_Halted tests that a processor refusing further renames stops the run before the folder
+func TestSanitizeService_SanitizeDirectory_Halted(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(string) ([]interfaces.FolderInfo, error) {
+			return failingFolders(10), nil
+		},
+	}
+	calls := 0
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			calls++
+			if calls > 2 {
+				return nil, fmt.Errorf("quota exceeded: %w", interfaces.ErrHalted)
+			}
+			return &interfaces.RenameResult{Success: true, OldPath: folder.Path, NewPath: folder.Path + "_", WasRenamed: true}, nil
+		},
+	}
+	reporter := &mockReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, walker, processor, reporter)
+	err := svc.SanitizeDirectory("/test", false)
+	if !errors.Is(err, interfaces.ErrHalted) {
+		t.Fatalf("Expected ErrHalted, got %v", err)
+	}
+
+	summary := reporter.completeCalls[0]
+	if !summary.Aborted || summary.ProcessedCount != 2 || summary.RemainingCount != 8 || summary.ErrorCount != 0 {
+		t.Errorf("Expected the halted folder to count as not processed, got %+v", summary)
+	}
+}
+
+// mockExplainer flags names containing "secret" without changing them
+type mockExplainer struct {
+	mockSanitizer
+}
+
+func (m *mockExplainer) ExplainName(name string) (string, []string) {
+	if strings.Contains(name, "secret") {
+		return name, []string{"reserved-word:test"}
+	}
+	return name, nil
+}
+
+// TestSanitizeService_CheckDirectory_ReportOnlyRules tests that rules flag names the sanitizer leaves unchanged
+func TestSanitizeService_CheckDirectory_ReportOnlyRules(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(string) ([]interfaces.FolderInfo, error) {
+			return []interfaces.FolderInfo{
+				{Path: "/test/secret plans", Name: "secret plans", Parent: "/test"},
+				{Path: "/test/public", Name: "public", Parent: "/test"},
+			}, nil
+		},
+	}
+
+	svc := service.NewSanitizeService(&mockExplainer{}, walker, &mockProcessor{}, &mockReporter{})
+	report, err := svc.CheckDirectory("/test")
+	if err != nil {
+		t.Fatalf("CheckDirectory() returned error: %v", err)
+	}
+
+	if len(report.Violations) != 1 || report.Violations[0].Name != "secret plans" {
+		t.Fatalf("Expected only the flagged folder as a violation, got %+v", report.Violations)
+	}
+	if report.Violations[0].SanitizedName != "secret plans" {
+		t.Errorf("Expected the name to stay unchanged, got %q", report.Violations[0].SanitizedName)
+	}
+}
+
+// riskyNames flags names starting with a zero
+type riskyNames struct{}
+
+func (riskyNames) AssessName(folder interfaces.FolderInfo, name string) []string {
+	if strings.HasPrefix(name, "0") {
+		return []string{"digits-only"}
+	}
+	return nil
+}
+
+// TestSanitizeService_CheckDirectory_Risks tests that risks are assessed on the sanitized names and kept apart from violations
+func TestSanitizeService_CheckDirectory_Risks(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(path string) ([]interfaces.FolderInfo, error) {
+			return []interfaces.FolderInfo{
+				{Path: "/test/007", Name: "007", Depth: 1, Parent: "/test"},
+				{Path: "/test/0:1", Name: "0:1", Depth: 1, Parent: "/test"},
+				{Path: "/test/docs", Name: "docs", Depth: 1, Parent: "/test"},
+			}, nil
+		},
+	}
+	sanitizer := &mockSanitizer{sanitizeFunc: func(name string) string { return strings.ReplaceAll(name, ":", "_") }}
+
+	svc := service.NewSanitizeService(sanitizer, walker, nil, nil, service.WithRiskAssessor(riskyNames{}))
+	report, err := svc.CheckDirectory("/test")
+	if err != nil {
+		t.Fatalf("CheckDirectory() returned error: %v", err)
+	}
+
+	if len(report.Violations) != 1 || report.Violations[0].Name != "0:1" {
+		t.Errorf("Expected only 0:1 to violate the rules, got %+v", report.Violations)
+	}
+	if len(report.Risks) != 2 || report.Risks[0].Name != "007" || report.Risks[1].Name != "0_1" {
+		t.Errorf("Expected 007 and the suggested name 0_1 to be risky, got %+v", report.Risks)
+	}
+}
+
+// TestSanitizeService_ProfileDirectory tests that every folder is visited with its rules and nothing is processed
+func TestSanitizeService_ProfileDirectory(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(string) ([]interfaces.FolderInfo, error) {
+			return []interfaces.FolderInfo{
+				{Path: "/test/secret plans", Name: "secret plans", Parent: "/test"},
+				{Path: "/test/public", Name: "public", Parent: "/test"},
+			}, nil
+		},
+	}
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			t.Error("Profiling must not process renames")
+			return nil, nil
+		},
+	}
+
+	svc := service.NewSanitizeService(&mockExplainer{}, walker, processor, &mockReporter{})
+	visited := make(map[string][]string)
+	_, err := svc.ProfileDirectory("/test", func(folder interfaces.FolderInfo, rules []string) error {
+		visited[folder.Name] = rules
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ProfileDirectory() returned error: %v", err)
+	}
+
+	if len(visited) != 2 {
+		t.Fatalf("Expected both folders to be visited, got %v", visited)
+	}
+	if rules := visited["secret plans"]; len(rules) != 1 || rules[0] != "reserved-word:test" {
+		t.Errorf("Expected the reserved-word rule for the flagged folder, got %v", rules)
+	}
+	if rules := visited["public"]; len(rules) != 0 {
+		t.Errorf("Expected no rules for the compliant folder, got %v", rules)
+	}
+
+	stop := errors.New("disk full")
+	_, err = svc.ProfileDirectory("/test", func(interfaces.FolderInfo, []string) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected the visit error to stop the scan, got %v", err)
+	}
+}
+
+// mockWarningWalker extends mockWalker with the optional WarningWalker interface
+type mockWarningWalker struct {
+	mockWalker
+	warnings []error
+}
+
+func (m *mockWarningWalker) Warnings() []error {
+	return m.warnings
+}
+
+// mockWarningReporter extends mockReporter with the optional WarningReporter interface
+type mockWarningReporter struct {
+	mockReporter
+	warningCalls []error
+}
+
+func (m *mockWarningReporter) ReportWarning(warning error) {
+	m.warningCalls = append(m.warningCalls, warning)
+}
+
+// TestSanitizeService_SanitizeDirectory_Warnings tests that walk warnings reach the reporter and the summary
+func TestSanitizeService_SanitizeDirectory_Warnings(t *testing.T) {
+	walker := &mockWarningWalker{
+		warnings: []error{errors.New("cannot read /test/private: permission denied")},
+	}
+	reporter := &mockWarningReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, walker, &mockProcessor{}, reporter)
+
+	if err := svc.SanitizeDirectory("/test", true); err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	if len(reporter.warningCalls) != 1 {
+		t.Fatalf("Expected 1 warning call, got %d", len(reporter.warningCalls))
+	}
+	if len(reporter.errorCalls) != 0 {
+		t.Errorf("Expected warnings not to be reported as errors, got %v", reporter.errorCalls)
+	}
+	if len(reporter.completeCalls) != 1 {
+		t.Fatalf("Expected 1 complete call, got %d", len(reporter.completeCalls))
+	}
+	summary := reporter.completeCalls[0]
+	if summary.WarningCount != 1 {
+		t.Errorf("Expected warning count 1, got %d", summary.WarningCount)
+	}
+	if summary.ErrorCount != 0 {
+		t.Errorf("Expected error count 0, got %d", summary.ErrorCount)
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_Vanished tests that folders gone since the scan are warnings, not errors
+func TestSanitizeService_SanitizeDirectory_Vanished(t *testing.T) {
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			return &interfaces.RenameResult{
+				OldPath:    folder.Path,
+				WasRenamed: true,
+				Error:      fmt.Errorf("rename operation failed: %w", fs.ErrNotExist),
+				Vanished:   true,
+			}, nil
+		},
+	}
+	reporter := &mockWarningReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, &mockWalker{}, processor, reporter)
+
+	// Without a single rename, errors would fail the run
+	if err := svc.SanitizeDirectory("/test", false); err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	if len(reporter.errorCalls) != 0 {
+		t.Errorf("Expected vanished folders not to be reported as errors, got %v", reporter.errorCalls)
+	}
+	summary := reporter.completeCalls[0]
+	if summary.ErrorCount != 0 || summary.WarningCount != 2 || len(reporter.warningCalls) != 2 {
+		t.Errorf("Expected 2 warnings and no errors, got %+v", summary)
+	}
+	if summary.Phases != (interfaces.PhaseCounts{Planned: 2, Vanished: 2}) {
+		t.Errorf("Expected 2 planned renames that vanished, got %+v", summary.Phases)
+	}
+}
+
+// TestSanitizeService_CheckDirectory_Warnings tests that check reports carry the walk warnings
+func TestSanitizeService_CheckDirectory_Warnings(t *testing.T) {
+	walker := &mockWarningWalker{
+		warnings: []error{errors.New("cannot read /test/private: permission denied")},
+	}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, walker, nil, nil)
+
+	report, err := svc.CheckDirectory("/test")
+	if err != nil {
+		t.Fatalf("CheckDirectory() returned error: %v", err)
+	}
+	if len(report.Warnings) != 1 {
+		t.Errorf("Expected 1 warning, got %d", len(report.Warnings))
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_Phases tests that dry runs and real runs fill the same phase counts
+func TestSanitizeService_SanitizeDirectory_Phases(t *testing.T) {
+	tests := []struct {
+		name   string
+		dryRun bool
+		want   interfaces.PhaseCounts
+	}{
+		{"real run", false, interfaces.PhaseCounts{Planned: 2, Applied: 1, Failed: 1, Skipped: 1}},
+		{"dry run", true, interfaces.PhaseCounts{Planned: 2, WouldRename: 1, Failed: 1, Skipped: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sanitizer := &mockSanitizer{
+				sanitizeFunc: func(name string) string {
+					if name == "folder0" {
+						return name // Already compliant
+					}
+					return name + "_clean"
+				},
+			}
+			walker := &mockWalker{
+				walkFunc: func(path string) ([]interfaces.FolderInfo, error) {
+					return failingFolders(3), nil
+				},
+			}
+			processor := &mockProcessor{
+				processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+					if folder.Name == "folder2" {
+						return nil, errors.New("access denied")
+					}
+					return &interfaces.RenameResult{
+						Success:    true,
+						OldPath:    folder.Path,
+						NewPath:    folder.Parent + "/" + newName,
+						WasRenamed: folder.Name != newName,
+					}, nil
+				},
+			}
+			reporter := &mockReporter{}
+
+			svc := service.NewSanitizeService(sanitizer, walker, processor, reporter)
+			_ = svc.SanitizeDirectory("/test", tt.dryRun)
+
+			if len(reporter.completeCalls) != 1 {
+				t.Fatalf("Expected 1 complete call, got %d", len(reporter.completeCalls))
+			}
+			summary := reporter.completeCalls[0]
+			if summary.DryRun != tt.dryRun {
+				t.Errorf("Expected DryRun %v, got %v", tt.dryRun, summary.DryRun)
+			}
+			if summary.Phases != tt.want {
+				t.Errorf("Expected phases %+v, got %+v", tt.want, summary.Phases)
+			}
+		})
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_Interrupt tests that an interrupt finishes the current folder and reports what remains
+func TestSanitizeService_SanitizeDirectory_Interrupt(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(path string) ([]interfaces.FolderInfo, error) {
+			return failingFolders(5), nil
+		},
+	}
+	interrupt := make(chan struct{})
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			if folder.Name == "folder1" {
+				close(interrupt) // Ctrl-C while the second rename is in flight
+			}
+			return &interfaces.RenameResult{Success: true, OldPath: folder.Path, NewPath: folder.Parent + "/" + newName, WasRenamed: true}, nil
+		},
+	}
+	reporter := &mockReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, walker, processor, reporter, service.WithInterrupt(interrupt))
+
+	err := svc.SanitizeDirectory("/test", false)
+	if !errors.Is(err, service.ErrInterrupted) {
+		t.Fatalf("Expected ErrInterrupted, got %v", err)
+	}
+
+	if len(reporter.completeCalls) != 1 {
+		t.Fatalf("Expected a partial summary, got %d complete calls", len(reporter.completeCalls))
+	}
+	summary := reporter.completeCalls[0]
+	if !summary.Aborted {
+		t.Error("Expected summary to be marked as aborted")
+	}
+	if summary.ProcessedCount != 2 || summary.RemainingCount != 3 {
+		t.Errorf("Expected 2 processed and 3 remaining folders, got %d and %d", summary.ProcessedCount, summary.RemainingCount)
+	}
+	if summary.Phases.Applied != 2 || summary.Phases.Deferred != 3 {
+		t.Errorf("Expected the in-flight rename to be applied and the rest deferred, got %+v", summary.Phases)
+	}
+}
+
+// mockPacer pauses a fixed number of times and records the observed latencies
+type mockPacer struct {
+	pauses   int
+	observed int
+}
+
+func (m *mockPacer) Pause() (time.Duration, string) {
+	if m.pauses > 0 {
+		m.pauses--
+		return time.Millisecond, "quiet hours"
+	}
+	return 0, ""
+}
+
+func (m *mockPacer) Observe(latency time.Duration) {
+	m.observed++
+}
+
+// TestSanitizeService_SanitizeDirectory_Pacer tests that pauses are reported and every rename is observed
+func TestSanitizeService_SanitizeDirectory_Pacer(t *testing.T) {
+	pacer := &mockPacer{pauses: 2}
+	reporter := &mockReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, &mockWalker{}, &mockProcessor{}, reporter, service.WithPacer(pacer))
+
+	if err := svc.SanitizeDirectory("/test", false); err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	if pacer.observed != 2 {
+		t.Errorf("Expected 2 observed renames, got %d", pacer.observed)
+	}
+	paused := 0
+	for _, call := range reporter.progressCalls {
+		if strings.Contains(call.message, "Paused") && strings.Contains(call.message, "quiet hours") {
+			paused++
+		}
+	}
+	if paused != 2 {
+		t.Errorf("Expected 2 pause reports, got %d", paused)
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_InterruptWhilePaused tests that an interrupt ends a pause
+func TestSanitizeService_SanitizeDirectory_InterruptWhilePaused(t *testing.T) {
+	interrupt := make(chan struct{})
+	pacer := &blockingPacer{interrupt: interrupt}
+	reporter := &mockReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, &mockWalker{}, &mockProcessor{}, reporter,
+		service.WithPacer(pacer), service.WithInterrupt(interrupt))
+
+	err := svc.SanitizeDirectory("/test", false)
+	if !errors.Is(err, service.ErrInterrupted) {
+		t.Fatalf("Expected ErrInterrupted, got %v", err)
+	}
+	if summary := reporter.completeCalls[0]; summary.ProcessedCount != 0 || summary.RemainingCount != 2 {
+		t.Errorf("Expected nothing processed, got %+v", summary)
+	}
+}
+
+// blockingPacer asks for a long pause and closes the interrupt channel, like Ctrl-C during quiet hours
+type blockingPacer struct {
+	interrupt chan struct{}
+}
+
+func (b *blockingPacer) Pause() (time.Duration, string) {
+	close(b.interrupt)
+	return time.Hour, "quiet hours"
+}
+
+func (b *blockingPacer) Observe(latency time.Duration) {}
+
+// mockVerifier flags every rename it is asked about
+type mockVerifier struct {
+	renames []interfaces.RenameResult
+}
+
+func (m *mockVerifier) VerifyRenames(renames []interfaces.RenameResult) []error {
+	m.renames = renames
+	var discrepancies []error
+	for _, rename := range renames {
+		discrepancies = append(discrepancies, fmt.Errorf("%s no longer exists", rename.NewPath))
+	}
+	return discrepancies
+}
+
+// TestSanitizeService_SanitizeDirectory_RunID tests that the run ID is recorded in the summary
+func TestSanitizeService_SanitizeDirectory_RunID(t *testing.T) {
+	reporter := &mockReporter{}
+	svc := service.NewSanitizeService(&mockSanitizer{}, &mockWalker{}, &mockProcessor{}, reporter, service.WithRunID("01JAB3C4D5E6F7G8H9JKMNPQRS"))
+
+	if err := svc.SanitizeDirectory("/test", true); err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+	if len(reporter.completeCalls) != 1 || reporter.completeCalls[0].RunID != "01JAB3C4D5E6F7G8H9JKMNPQRS" {
+		t.Errorf("Expected the run ID in the summary, got %+v", reporter.completeCalls)
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_Verifier tests that applied renames are verified into the summary, and dry runs are not
+func TestSanitizeService_SanitizeDirectory_Verifier(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(string) ([]interfaces.FolderInfo, error) {
+			return failingFolders(3), nil
+		},
+	}
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			return &interfaces.RenameResult{Success: true, OldPath: folder.Path, NewPath: folder.Path + "_", WasRenamed: true}, nil
+		},
+	}
+
+	for _, dryRun := range []bool{false, true} {
+		reporter := &mockReporter{}
+		verifier := &mockVerifier{}
+		svc := service.NewSanitizeService(&mockSanitizer{}, walker, processor, reporter,
+			service.WithVerifier(verifier), service.WithRelativePaths(true))
+		if err := svc.SanitizeDirectory("/test", dryRun); err != nil {
+			t.Fatalf("SanitizeDirectory(dryRun=%v) returned error: %v", dryRun, err)
+		}
+
+		verification := reporter.completeCalls[0].Verification
+		if dryRun {
+			if verification != nil || verifier.renames != nil {
+				t.Errorf("Expected dry runs not to be verified, got %+v", verification)
+			}
+			continue
+		}
+		if verification == nil || verification.Checked != 3 || len(verification.Discrepancies) != 3 {
+			t.Fatalf("Expected 3 checked renames with 3 discrepancies, got %+v", verification)
+		}
+		if !strings.HasPrefix(verification.Discrepancies[0], "folder") {
+			t.Errorf("Expected discrepancies with relative paths, got %q", verification.Discrepancies[0])
+		}
+	}
+}
+
+// mockScanningWalker reports a scan of its folders while walking
+type mockScanningWalker struct {
+	mockWalker
+	observe func(scanned int, path string)
+}
+
+func (m *mockScanningWalker) ObserveScan(observe func(scanned int, path string)) {
+	m.observe = observe
+}
+
+func (m *mockScanningWalker) Walk(rootPath string) ([]interfaces.FolderInfo, error) {
+	folders, err := m.mockWalker.Walk(rootPath)
+	for i, folder := range folders {
+		m.observe(i+1, folder.Path)
+	}
+	return folders, err
+}
+
+// mockScanReporter records the scan progress
+type mockScanReporter struct {
+	mockReporter
+	scans []string
+}
+
+func (m *mockScanReporter) ReportScan(scanned int, path string) {
+	m.scans = append(m.scans, fmt.Sprintf("%d %s", scanned, path))
+}
+
+// TestSanitizeService_SanitizeDirectory_Scan tests that the discovery progress of the walker reaches the reporter
+func TestSanitizeService_SanitizeDirectory_Scan(t *testing.T) {
+	walker := &mockScanningWalker{mockWalker: mockWalker{
+		walkFunc: func(string) ([]interfaces.FolderInfo, error) {
+			return failingFolders(2), nil
+		},
+	}}
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			return &interfaces.RenameResult{Success: true, OldPath: folder.Path, NewPath: folder.Path}, nil
+		},
+	}
+	reporter := &mockScanReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, walker, processor, reporter, service.WithRelativePaths(true))
+	if err := svc.SanitizeDirectory("/test", true); err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	if want := []string{"1 folder0", "2 folder1"}; !slices.Equal(reporter.scans, want) {
+		t.Errorf("Expected scan reports %v, got %v", want, reporter.scans)
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_RenamedParents tests that folders below a renamed parent are processed at its new path
+// The walker returns parents first, like a top-down walk
+func TestSanitizeService_SanitizeDirectory_RenamedParents(t *testing.T) {
+	walker := &mockWalker{
+		walkFunc: func(string) ([]interfaces.FolderInfo, error) {
+			return []interfaces.FolderInfo{
+				{Path: "/test/a", Name: "a", Depth: 1, Parent: "/test"},
+				{Path: "/test/d", Name: "d", Depth: 1, Parent: "/test"},
+				{Path: "/test/a/b", Name: "b", Depth: 2, Parent: "/test/a"},
+				{Path: "/test/a/b/c", Name: "c", Depth: 3, Parent: "/test/a/b"},
+			}, nil
+		},
+	}
+	sanitizer := &mockSanitizer{
+		sanitizeFunc: func(name string) string {
+			return strings.ToUpper(name)
+		},
+	}
+
+	var processed []string
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			processed = append(processed, folder.Path)
+			return &interfaces.RenameResult{Success: true, OldPath: folder.Path, NewPath: folder.Parent + "/" + newName, WasRenamed: true}, nil
+		},
+	}
+	reporter := &mockRenameReporter{}
+
+	svc := service.NewSanitizeService(sanitizer, walker, processor, reporter)
+	if err := svc.SanitizeDirectory("/test", false); err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	if want := []string{"/test/a", "/test/d", "/test/A/b", "/test/A/B/c"}; !slices.Equal(processed, want) {
+		t.Errorf("Expected folders processed at %v, got %v", want, processed)
+	}
+	if last := reporter.renameCalls[len(reporter.renameCalls)-1]; last.NewPath != "/test/A/B/C" {
+		t.Errorf("Expected the last rename to end at /test/A/B/C, got %s", last.NewPath)
+	}
+}
+
+// TestSanitizeService_SanitizeDirectory_Locked tests that locked folders are retried at the end and listed if still locked
+func TestSanitizeService_SanitizeDirectory_Locked(t *testing.T) {
+	var calls []string
+	attempts := map[string]int{}
+	processor := &mockProcessor{
+		processFunc: func(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+			calls = append(calls, folder.Name)
+			attempts[folder.Name]++
+			result := &interfaces.RenameResult{OldPath: folder.Path, NewPath: folder.Parent + "/" + newName, WasRenamed: true}
+			// folder1 is released before its retry, folder2 stays open
+			if folder.Name == "folder2" || attempts[folder.Name] == 1 {
+				result.Error = errors.New("rename operation failed: device or resource busy")
+				result.Locked = true
+				return result, nil
+			}
+			result.Success = true
+			return result, nil
+		},
+	}
+	reporter := &mockWarningReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, &mockWalker{}, processor, reporter)
+	_ = svc.SanitizeDirectory("/test", false)
+
+	if want := []string{"folder1", "folder2", "folder1", "folder2"}; !slices.Equal(calls, want) {
+		t.Errorf("Expected locked folders to be retried at the end, got %v", calls)
+	}
+	summary := reporter.completeCalls[0]
+	if summary.ProcessedCount != 2 || summary.RenamedCount != 1 || summary.ErrorCount != 1 {
+		t.Errorf("Expected 1 rename and 1 error for 2 folders, got %+v", summary)
+	}
+	if !slices.Equal(summary.Locked, []string{"/test/folder2"}) {
+		t.Errorf("Expected folder2 to be listed as locked, got %v", summary.Locked)
+	}
+	if len(reporter.warningCalls) != 2 {
+		t.Errorf("Expected a warning for each deferred folder, got %v", reporter.warningCalls)
+	}
+}
+
+// mockHold holds the run before the first folder until the test releases it
+type mockHold struct {
+	released chan struct{}
+	calls    int
+}
+
+func (m *mockHold) Released() <-chan struct{} {
+	m.calls++
+	if m.calls == 1 {
+		return m.released
+	}
+	return nil
+}
+
+// TestSanitizeService_SanitizeDirectory_Hold tests that a held dry run waits until it is released and reports the pause
+func TestSanitizeService_SanitizeDirectory_Hold(t *testing.T) {
+	hold := &mockHold{released: make(chan struct{})}
+	reporter := &mockReporter{}
+
+	svc := service.NewSanitizeService(&mockSanitizer{}, &mockWalker{}, &mockProcessor{}, reporter, service.WithHold(hold))
+
+	done := make(chan error)
+	go func() { done <- svc.SanitizeDirectory("/test", true) }()
+
+	select {
+	case <-done:
+		t.Fatal("Expected the held run to wait")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(hold.released)
+	if err := <-done; err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	if len(reporter.progressCalls) == 0 || reporter.progressCalls[0].message != "Paused by the user" {
+		t.Errorf("Expected the pause to be reported first, got %+v", reporter.progressCalls)
+	}
+	if summary := reporter.completeCalls[0]; summary.ProcessedCount != 2 {
+		t.Errorf("Expected every folder processed after the release, got %+v", summary)
+	}
+}
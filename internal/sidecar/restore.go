@@ -0,0 +1,228 @@
+package sidecar
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Attributes reads and removes original names stored on the folders themselves
+// The xattr package implements it with extended attributes.
+type Attributes interface {
+	Original(path string) (string, error) // Original name stored on path, or "" if none is stored
+	Remove(path string) error             // Remove the original name stored on path
+}
+
+// RestoreOption configures optional Restore behavior
+type RestoreOption func(*restoreConfig)
+
+// restoreConfig holds the settings of one Restore call
+type restoreConfig struct {
+	attributes Attributes
+}
+
+// FromAttributes reads the original names from attributes instead of mapping files
+// Restored folders lose their attribute; mapping files are left alone.
+func FromAttributes(attributes Attributes) RestoreOption {
+	return func(config *restoreConfig) {
+		config.attributes = attributes
+	}
+}
+
+// entry is a folder a mapping file records, with the file it came from
+type entry struct {
+	path     string // Current path of the folder
+	original string // Name to restore
+	mapDir   string // Directory of the mapping file
+	key      string // Key of the entry in the mapping file
+}
+
+// Restore renames every folder recorded in the mapping files of the given folders back to its original name
+// Deepest folders are restored first, so every recorded path is still valid when it is renamed. Restored
+// entries are removed from the mapping files; progress and the summary go to the reporter.
+func Restore(fileSystem interfaces.FileSystem, folders []interfaces.FolderInfo, dryRun bool, reporter interfaces.ProgressReporter, options ...RestoreOption) interfaces.ProcessingSummary {
+	startTime := time.Now()
+	summary := interfaces.ProcessingSummary{DryRun: dryRun}
+
+	var config restoreConfig
+	for _, option := range options {
+		option(&config)
+	}
+
+	var entries []entry
+	var mappings map[string]map[string]string
+	var err error
+	if config.attributes != nil {
+		entries, err = findAttributeEntries(config.attributes, folders)
+	} else {
+		entries, mappings, err = findEntries(fileSystem, folders)
+	}
+	if err != nil {
+		reporter.ReportError(err)
+		summary.ErrorCount++
+	}
+	summary.TotalFolders = len(entries)
+
+	var restored []rename
+	for _, entry := range entries {
+		summary.ProcessedCount++
+		summary.Phases.Planned++
+		reporter.ReportProgress(summary.ProcessedCount, summary.TotalFolders, fmt.Sprintf("Restoring: %s", entry.path))
+
+		target := filepath.Join(filepath.Dir(entry.path), entry.original)
+		if err := restoreEntry(fileSystem, entry, target, dryRun); err != nil {
+			reporter.ReportError(fmt.Errorf("cannot restore %s: %w", entry.path, err))
+			summary.ErrorCount++
+			summary.Phases.Failed++
+			continue
+		}
+
+		summary.RenamedCount++
+		if dryRun {
+			summary.Phases.WouldRename++
+		} else {
+			summary.Phases.Applied++
+			delete(mappings[entry.mapDir], entry.key)
+			restored = append(restored, rename{oldPath: entry.path, newPath: target})
+			// The folder has its original name again, so a later restore must not touch it
+			if config.attributes != nil {
+				if err := config.attributes.Remove(target); err != nil {
+					reporter.ReportError(err)
+					summary.ErrorCount++
+				}
+			}
+		}
+		if renameReporter, ok := reporter.(interfaces.RenameReporter); ok {
+			renameReporter.ReportRename(interfaces.RenameResult{
+				Success:    true,
+				OldPath:    entry.path,
+				NewPath:    target,
+				WasRenamed: true,
+			})
+		}
+	}
+
+	// The mapping files keep the entries that could not be restored; they moved with restored parents
+	if !dryRun {
+		for dir, names := range mappings {
+			for _, rename := range restored {
+				dir = follow(dir, rename)
+			}
+			if err := Save(filepath.Join(dir, FileName), names); err != nil {
+				reporter.ReportError(err)
+				summary.ErrorCount++
+			}
+		}
+	}
+
+	summary.ElapsedTime = time.Since(startTime).String()
+	reporter.ReportComplete(summary)
+
+	return summary
+}
+
+// findEntries reads the mapping files in the given folders and returns their entries deepest first
+// The mappings are returned by directory, so restored entries can be removed from them
+func findEntries(fileSystem interfaces.FileSystem, folders []interfaces.FolderInfo) ([]entry, map[string]map[string]string, error) {
+	var entries []entry
+	mappings := make(map[string]map[string]string)
+	var loadErrors []error
+
+	for _, folder := range folders {
+		mapping, err := Load(fileSystem, filepath.Join(folder.Path, FileName))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			loadErrors = append(loadErrors, err)
+			continue
+		}
+
+		mappings[folder.Path] = mapping.Names
+		for _, key := range sortedKeys(mapping.Names) {
+			original := mapping.Names[key]
+			// A tampered file must not move folders out of their parent or out of the tree
+			if !filepath.IsLocal(filepath.FromSlash(key)) || !validName(original) {
+				loadErrors = append(loadErrors, fmt.Errorf("ignoring invalid entry %q: %q in %s", key, original, filepath.Join(folder.Path, FileName)))
+				continue
+			}
+			entries = append(entries, entry{
+				path:     filepath.Join(folder.Path, filepath.FromSlash(key)),
+				original: original,
+				mapDir:   folder.Path,
+				key:      key,
+			})
+		}
+	}
+
+	sortDeepestFirst(entries)
+	return entries, mappings, errors.Join(loadErrors...)
+}
+
+// findAttributeEntries reads the original names stored on the given folders and returns them deepest first
+// A file system without attributes stops the search, since no folder below it can carry one either.
+func findAttributeEntries(attributes Attributes, folders []interfaces.FolderInfo) ([]entry, error) {
+	var entries []entry
+	var readErrors []error
+
+	for _, folder := range folders {
+		original, err := attributes.Original(folder.Path)
+		if errors.Is(err, errors.ErrUnsupported) {
+			return nil, err
+		}
+		if err != nil {
+			readErrors = append(readErrors, err)
+			continue
+		}
+		if original == "" || original == filepath.Base(folder.Path) {
+			continue
+		}
+		if !validName(original) {
+			readErrors = append(readErrors, fmt.Errorf("ignoring invalid original name %q of %s", original, folder.Path))
+			continue
+		}
+		entries = append(entries, entry{path: folder.Path, original: original})
+	}
+
+	sortDeepestFirst(entries)
+	return entries, errors.Join(readErrors...)
+}
+
+// sortDeepestFirst orders entries so every folder comes before its parent
+func sortDeepestFirst(entries []entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return strings.Count(entries[i].path, string(filepath.Separator)) > strings.Count(entries[j].path, string(filepath.Separator))
+	})
+}
+
+// validName reports whether an original name is a single path element
+func validName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsRune(name, '/') && !strings.ContainsRune(name, filepath.Separator)
+}
+
+// restoreEntry renames a folder back after checking that nothing else took its original name
+func restoreEntry(fileSystem interfaces.FileSystem, entry entry, target string, dryRun bool) error {
+	info, err := fileSystem.Lstat(entry.path)
+	if err != nil {
+		return fmt.Errorf("renamed folder is gone: %w", err)
+	}
+	// A case-only rename on a case-insensitive file system finds the folder itself under its original name
+	if targetInfo, err := fileSystem.Lstat(target); err == nil && !fileSystem.SameFile(info, targetInfo) {
+		return fmt.Errorf("original name %q is in use", entry.original)
+	}
+
+	if dryRun {
+		return nil
+	}
+	if filesystem.IsCaseOnlyRename(fileSystem, entry.path, target) {
+		return filesystem.RenameCaseOnly(fileSystem, fileSystem.Rename, entry.path, target)
+	}
+	return fileSystem.Rename(entry.path, target)
+}
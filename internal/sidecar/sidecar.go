@@ -0,0 +1,270 @@
+// Package sidecar keeps mapping files next to renamed folders that record their original names.
+// This implementation follows the Decorator pattern by wrapping an existing FolderProcessor.
+package sidecar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// FileName is the name of every mapping file
+const FileName = ".sanitize-map.json"
+
+// fileVersion identifies the layout of mapping files
+const fileVersion = 1
+
+// Modes select where mapping files are written
+const (
+	PerDirectory = "dir"  // One mapping file in each parent of a renamed folder
+	Root         = "root" // A single manifest in the root of the run
+)
+
+// Modes lists the supported modes
+var Modes = []string{PerDirectory, Root}
+
+// Map is the content of a mapping file
+// Names maps the current name of a renamed folder to its original name; in a root manifest the
+// keys are slash-separated paths relative to the root, in a per-directory file they are plain names
+type Map struct {
+	Version int               `json:"version"`
+	Names   map[string]string `json:"names"`
+}
+
+// rename is a completed rename in processing order
+type rename struct {
+	oldPath string
+	newPath string
+}
+
+// Recorder collects the renames of a run and merges them into the mapping files when it is saved
+// It is safe for concurrent use
+type Recorder struct {
+	mu         sync.Mutex
+	root       string
+	mode       string
+	fileSystem interfaces.FileSystem
+	renames    []rename
+}
+
+// NewRecorder creates a Recorder for a run on root; fileSystem reads existing mapping files
+func NewRecorder(root, mode string, fileSystem interfaces.FileSystem) (*Recorder, error) {
+	if mode != PerDirectory && mode != Root {
+		return nil, fmt.Errorf("invalid mapping mode %q (want %s)", mode, strings.Join(Modes, " or "))
+	}
+	return &Recorder{root: root, mode: mode, fileSystem: fileSystem}, nil
+}
+
+// Processor wraps next so every completed rename of a real run is recorded
+// Merged folders are not recorded, because there is no folder left to restore
+func (r *Recorder) Processor(next interfaces.FolderProcessor) interfaces.FolderProcessor {
+	return &recordingProcessor{next: next, recorder: r}
+}
+
+// recordingProcessor records the renames it hands on
+type recordingProcessor struct {
+	next     interfaces.FolderProcessor
+	recorder *Recorder
+}
+
+// ProcessRename renames through the wrapped processor and records a completed rename
+// This method implements the FolderProcessor interface
+func (p *recordingProcessor) ProcessRename(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+	result, err := p.next.ProcessRename(folder, newName, dryRun)
+	if err == nil && !dryRun && result != nil && result.WasRenamed && result.Success && !result.Merged {
+		p.recorder.mu.Lock()
+		p.recorder.renames = append(p.recorder.renames, rename{oldPath: result.OldPath, newPath: result.NewPath})
+		p.recorder.mu.Unlock()
+	}
+	return result, err
+}
+
+// Save merges the recorded renames into the mapping files
+// A folder renamed before keeps its first original name, and entries follow their folders through
+// the renames of their parents, so the files always lead back to the names before the first run
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.renames) == 0 {
+		return nil
+	}
+
+	// Existing entries are loaded where they were before the run; folders are processed deepest first,
+	// so the parent of every renamed folder still had its old path when the folder was renamed
+	originals := make(map[string]string)
+	loaded := make(map[string]bool)
+	for _, rename := range r.renames {
+		dir := r.mapDir(rename.oldPath)
+		if loaded[dir] {
+			continue
+		}
+		loaded[dir] = true
+		if err := r.load(dir, originals); err != nil {
+			return err
+		}
+	}
+
+	// Replay the renames in processing order, moving entries along with their folders and parents
+	for _, rename := range r.renames {
+		original, known := originals[rename.oldPath]
+		moveEntries(originals, rename)
+		if !known {
+			original = filepath.Base(rename.oldPath)
+		}
+		originals[rename.newPath] = original
+	}
+
+	return r.write(originals, loaded)
+}
+
+// mapDir returns the directory whose mapping file records a folder
+func (r *Recorder) mapDir(path string) string {
+	if r.mode == Root {
+		return r.root
+	}
+	return filepath.Dir(path)
+}
+
+// load adds the entries of the mapping file in dir to originals, keyed by absolute path
+// The file itself may have moved with its directory during the run
+func (r *Recorder) load(dir string, originals map[string]string) error {
+	mapping, err := Load(r.fileSystem, filepath.Join(r.currentPath(dir), FileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for key, original := range mapping.Names {
+		originals[filepath.Join(dir, filepath.FromSlash(key))] = original
+	}
+	return nil
+}
+
+// currentPath returns where a path from before the run is after all recorded renames
+func (r *Recorder) currentPath(path string) string {
+	for _, rename := range r.renames {
+		path = follow(path, rename)
+	}
+	return path
+}
+
+// write saves the entries grouped by mapping file; files whose entries were all restored are removed
+func (r *Recorder) write(originals map[string]string, loaded map[string]bool) error {
+	files := make(map[string]map[string]string)
+	for dir := range loaded {
+		files[r.currentPath(dir)] = make(map[string]string)
+	}
+	for path, original := range originals {
+		dir := r.mapDir(path)
+		if files[dir] == nil {
+			files[dir] = make(map[string]string)
+		}
+		key := filepath.Base(path)
+		if r.mode == Root {
+			relative, err := filepath.Rel(r.root, path)
+			if err != nil {
+				continue
+			}
+			key = filepath.ToSlash(relative)
+		}
+		// A folder renamed back to its original name needs no entry any more
+		if filepath.Base(path) != original {
+			files[dir][key] = original
+		}
+	}
+
+	for dir, names := range files {
+		if err := Save(filepath.Join(dir, FileName), names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveEntries rewrites the entries at or below a renamed path to its new path
+func moveEntries(originals map[string]string, rename rename) {
+	for path, original := range originals {
+		if moved := follow(path, rename); moved != path {
+			delete(originals, path)
+			originals[moved] = original
+		}
+	}
+}
+
+// follow returns where path is after a rename of itself or one of its ancestors
+func follow(path string, rename rename) string {
+	if path == rename.oldPath {
+		return rename.newPath
+	}
+	if rest, ok := strings.CutPrefix(path, rename.oldPath+string(filepath.Separator)); ok {
+		return filepath.Join(rename.newPath, rest)
+	}
+	return path
+}
+
+// Load reads the mapping file at path
+func Load(fileSystem interfaces.FileSystem, path string) (*Map, error) {
+	data, err := fileSystem.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read name mapping %s: %w", path, err)
+	}
+
+	var mapping Map
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to decode name mapping %s: %w", path, err)
+	}
+	if mapping.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported name mapping version %d in %s", mapping.Version, path)
+	}
+	return &mapping, nil
+}
+
+// Save writes names to the mapping file at path, or removes the file when names is empty
+func Save(path string, names map[string]string) error {
+	if len(names) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove name mapping %s: %w", path, err)
+		}
+		return nil
+	}
+
+	// Original names are often non-ASCII, so they are written as they are rather than escaped
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(Map{Version: fileVersion, Names: names}); err != nil {
+		return fmt.Errorf("failed to encode name mapping: %w", err)
+	}
+
+	if err := os.WriteFile(path, data.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write name mapping %s: %w", path, err)
+	}
+	return nil
+}
+
+// sortedKeys returns the keys of a mapping, deepest first and then in byte order
+func sortedKeys(names map[string]string) []string {
+	keys := make([]string, 0, len(names))
+	for key := range names {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		di, dj := strings.Count(keys[i], "/"), strings.Count(keys[j], "/")
+		if di != dj {
+			return di > dj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,163 @@
+package sidecar_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/processor"
+	"github.com/punkscience/sanitize/internal/reporter"
+	"github.com/punkscience/sanitize/internal/sidecar"
+)
+
+// renameAll renames folders deepest first through a processor wrapped by the recorder and saves the mapping
+func renameAll(t *testing.T, root, mode string, renames [][2]string) {
+	t.Helper()
+	fileSystem := filesystem.NewOSFileSystem()
+	recorder, err := sidecar.NewRecorder(root, mode, fileSystem)
+	if err != nil {
+		t.Fatalf("NewRecorder() returned error: %v", err)
+	}
+	folderProcessor := recorder.Processor(processor.NewFileSystemProcessor(10, processor.WithFileSystem(fileSystem)))
+
+	for _, rename := range renames {
+		path := filepath.Join(root, filepath.FromSlash(rename[0]))
+		folder := interfaces.FolderInfo{Path: path, Name: filepath.Base(path), Parent: filepath.Dir(path)}
+		if _, err := folderProcessor.ProcessRename(folder, rename[1], false); err != nil {
+			t.Fatalf("ProcessRename(%s) returned error: %v", rename[0], err)
+		}
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+}
+
+// loadNames returns the entries of the mapping file in dir
+func loadNames(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	mapping, err := sidecar.Load(filesystem.NewOSFileSystem(), filepath.Join(dir, sidecar.FileName))
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	return mapping.Names
+}
+
+// TestRecorder_PerDirectory tests that each parent records the original names of its renamed children
+func TestRecorder_PerDirectory(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "Café", "Été"), 0755)
+
+	renameAll(t, root, sidecar.PerDirectory, [][2]string{{"Café/Été", "Ete"}, {"Café", "Cafe"}})
+
+	if names := loadNames(t, root); !reflect.DeepEqual(names, map[string]string{"Cafe": "Café"}) {
+		t.Errorf("Expected the root to map Cafe back, got %v", names)
+	}
+	if names := loadNames(t, filepath.Join(root, "Cafe")); !reflect.DeepEqual(names, map[string]string{"Ete": "Été"}) {
+		t.Errorf("Expected the renamed parent to map Ete back, got %v", names)
+	}
+}
+
+// TestRecorder_Root tests that a root manifest follows entries through later renames and keeps first originals
+func TestRecorder_Root(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "Café", "Été"), 0755)
+
+	renameAll(t, root, sidecar.Root, [][2]string{{"Café/Été", "Ete"}, {"Café", "Cafe"}})
+	renameAll(t, root, sidecar.Root, [][2]string{{"Cafe", "CAFE"}})
+
+	want := map[string]string{"CAFE": "Café", "CAFE/Ete": "Été"}
+	if names := loadNames(t, root); !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+	if _, err := os.Stat(filepath.Join(root, "CAFE", sidecar.FileName)); !os.IsNotExist(err) {
+		t.Errorf("Expected no per-directory file in root mode, got %v", err)
+	}
+}
+
+// TestRestore tests that restore brings the original names back and removes the mapping files
+func TestRestore(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "Café", "Été"), 0755)
+	renameAll(t, root, sidecar.PerDirectory, [][2]string{{"Café/Été", "Ete"}, {"Café", "Cafe"}})
+
+	folders := []interfaces.FolderInfo{{Path: filepath.Join(root, "Cafe")}, {Path: root}}
+	summary := sidecar.Restore(filesystem.NewOSFileSystem(), folders, true, reporter.NewNopReporter())
+	if summary.Phases.WouldRename != 2 {
+		t.Errorf("Expected a dry run to plan 2 restores, got %+v", summary.Phases)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Cafe", "Ete")); err != nil {
+		t.Fatalf("Expected a dry run to rename nothing: %v", err)
+	}
+
+	summary = sidecar.Restore(filesystem.NewOSFileSystem(), folders, false, reporter.NewNopReporter())
+	if summary.Phases.Applied != 2 || summary.ErrorCount != 0 {
+		t.Fatalf("Expected 2 restores without errors, got %+v", summary)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Café", "Été")); err != nil {
+		t.Errorf("Expected the original names to be back: %v", err)
+	}
+	for _, dir := range []string{root, filepath.Join(root, "Café")} {
+		if _, err := os.Stat(filepath.Join(dir, sidecar.FileName)); !os.IsNotExist(err) {
+			t.Errorf("Expected the emptied mapping file in %s to be removed, got %v", dir, err)
+		}
+	}
+}
+
+// TestRestore_Conflicts tests that taken names and entries leaving their directory are not restored
+func TestRestore_Conflicts(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "a_b"), 0755)
+	os.MkdirAll(filepath.Join(root, "a b"), 0755)
+	os.MkdirAll(filepath.Join(root, "c_d"), 0755)
+	names := map[string]string{"a_b": "a b", "c_d": "../escaped"}
+	if err := sidecar.Save(filepath.Join(root, sidecar.FileName), names); err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	summary := sidecar.Restore(filesystem.NewOSFileSystem(), []interfaces.FolderInfo{{Path: root}}, false, reporter.NewNopReporter())
+	if summary.Phases.Applied != 0 || summary.ErrorCount != 2 {
+		t.Errorf("Expected both entries to fail, got %+v", summary)
+	}
+	if _, err := os.Stat(filepath.Join(root, "c_d")); err != nil {
+		t.Errorf("Expected the folder with an invalid entry to stay: %v", err)
+	}
+	if kept := loadNames(t, root); !reflect.DeepEqual(kept, names) {
+		t.Errorf("Expected failed entries to stay in the mapping file, got %v", kept)
+	}
+}
+
+// fakeAttributes keeps original names by path and records the paths whose attribute was removed
+type fakeAttributes struct {
+	names   map[string]string
+	removed []string
+}
+
+func (a *fakeAttributes) Original(path string) (string, error) { return a.names[path], nil }
+func (a *fakeAttributes) Remove(path string) error             { a.removed = append(a.removed, path); return nil }
+
+// TestRestore_FromAttributes tests that original names are read from attributes, deepest folder first
+func TestRestore_FromAttributes(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "Cafe", "Ete"), 0755)
+	attributes := &fakeAttributes{names: map[string]string{
+		filepath.Join(root, "Cafe"):        "Café",
+		filepath.Join(root, "Cafe", "Ete"): "Été",
+	}}
+
+	folders := []interfaces.FolderInfo{{Path: filepath.Join(root, "Cafe")}, {Path: filepath.Join(root, "Cafe", "Ete")}}
+	summary := sidecar.Restore(filesystem.NewOSFileSystem(), folders, false, reporter.NewNopReporter(), sidecar.FromAttributes(attributes))
+	if summary.Phases.Applied != 2 || summary.ErrorCount != 0 {
+		t.Fatalf("Expected 2 restores without errors, got %+v", summary)
+	}
+	if _, err := os.Stat(filepath.Join(root, "Café", "Été")); err != nil {
+		t.Errorf("Expected the original names to be back: %v", err)
+	}
+	// The attribute moves with the folder, so it is removed under the restored name
+	expected := []string{filepath.Join(root, "Cafe", "Été"), filepath.Join(root, "Café")}
+	if !reflect.DeepEqual(attributes.removed, expected) {
+		t.Errorf("Expected the attributes of %v to be removed, got %v", expected, attributes.removed)
+	}
+}
@@ -0,0 +1,146 @@
+// Package state manages the directory where run artifacts (failed items, reports, checkpoints) are written.
+// This implementation gives every run its own subdirectory so concurrent runs never clobber each other.
+package state
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dir represents the per-run artifact directory inside a shared state directory
+// This struct applies consistent permissions and group ownership to every artifact
+type Dir struct {
+	// RunID uniquely identifies the run and is embedded in every artifact name
+	RunID string
+	// Path is the per-run subdirectory all artifacts are written to
+	Path string
+	// dirMode is applied to the run directory; file permissions are derived from it
+	dirMode os.FileMode
+	// gid is the group that owns the artifacts (-1 = leave unchanged)
+	gid int
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs; it has no I, L, O or U
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewRunID generates a unique run identifier in ULID format, e.g. 01JAB3C4D5E6F7G8H9JKMNPQRS
+// The first 10 characters encode the start time in milliseconds, so run IDs sort by time
+func NewRunID() string {
+	return newULID(time.Now())
+}
+
+// newULID encodes a 48-bit millisecond timestamp and 80 random bits as 26 Crockford base32 characters
+func newULID(now time.Time) string {
+	var id [16]byte
+	milliseconds := uint64(now.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		id[i] = byte(milliseconds)
+		milliseconds >>= 8
+	}
+	rand.Read(id[6:]) // Never fails; it crashes the program if no randomness is available
+
+	// The 128 bits are encoded from the least significant end, 5 bits per character
+	high, low := binary.BigEndian.Uint64(id[:8]), binary.BigEndian.Uint64(id[8:])
+	encoded := make([]byte, 26)
+	for i := len(encoded) - 1; i >= 0; i-- {
+		encoded[i] = crockford[low&31]
+		low = low>>5 | high<<59
+		high >>= 5
+	}
+	return string(encoded)
+}
+
+// New creates the per-run subdirectory <root>/<runID> with the given permissions and group
+// An empty group leaves ownership unchanged
+func New(root, runID string, dirMode os.FileMode, group string) (*Dir, error) {
+	gid, err := lookupGroup(group)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Dir{
+		RunID:   runID,
+		Path:    filepath.Join(root, runID),
+		dirMode: dirMode,
+		gid:     gid,
+	}
+
+	if err := os.MkdirAll(d.Path, dirMode); err != nil {
+		return nil, fmt.Errorf("failed to create state directory %s: %w", d.Path, err)
+	}
+
+	// MkdirAll is subject to the umask, so apply the requested permissions explicitly
+	if err := d.apply(d.Path, dirMode); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// ArtifactPath returns the path for an artifact with the run ID embedded in its name
+// For example "failed.json" becomes "<run dir>/failed-<runID>.json"
+func (d *Dir) ArtifactPath(name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(filepath.Base(name), ext)
+	return filepath.Join(d.Path, fmt.Sprintf("%s-%s%s", base, d.RunID, ext))
+}
+
+// Finalize applies the configured permissions and group ownership to a written artifact
+// Files get the directory permissions without any execute bits
+func (d *Dir) Finalize(path string) error {
+	return d.apply(path, d.dirMode&^0111)
+}
+
+// apply sets the mode and group of a path
+func (d *Dir) apply(path string, mode os.FileMode) error {
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+
+	if d.gid >= 0 {
+		if err := os.Chown(path, -1, d.gid); err != nil {
+			return fmt.Errorf("failed to set group ownership on %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// lookupGroup resolves a group name or numeric ID (empty = -1, leave unchanged)
+func lookupGroup(group string) (int, error) {
+	if group == "" {
+		return -1, nil
+	}
+
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return -1, fmt.Errorf("unknown group %q: %w", group, err)
+	}
+
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return -1, fmt.Errorf("group %q has non-numeric ID %q", group, g.Gid)
+	}
+
+	return gid, nil
+}
+
+// ParseMode parses an octal permission string such as "0750"
+func ParseMode(mode string) (os.FileMode, error) {
+	value, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || value > 0777 {
+		return 0, fmt.Errorf("invalid permission mode %q: expected octal such as 0750", mode)
+	}
+	return os.FileMode(value), nil
+}
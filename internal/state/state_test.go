@@ -0,0 +1,66 @@
+// Package state_test provides tests for the state package.
+// This test suite ensures per-run artifact directories are isolated and correctly named.
+package state_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/state"
+)
+
+// TestNew_ArtifactPath tests that artifacts land in a per-run subdirectory with the run ID in their name
+func TestNew_ArtifactPath(t *testing.T) {
+	root := t.TempDir()
+
+	dir, err := state.New(root, "run-1", 0750, "")
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(root, "run-1"))
+	if err != nil {
+		t.Fatalf("Expected run directory to exist: %v", err)
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Errorf("Expected run directory mode 0750, got %o", info.Mode().Perm())
+	}
+
+	expected := filepath.Join(root, "run-1", "failed-run-1.json")
+	if path := dir.ArtifactPath("failed.json"); path != expected {
+		t.Errorf("ArtifactPath() = %s, expected %s", path, expected)
+	}
+}
+
+// TestNewRunID tests that run IDs are unique, time-sortable ULIDs
+func TestNewRunID(t *testing.T) {
+	first, second := state.NewRunID(), state.NewRunID()
+	if first == second {
+		t.Errorf("Expected unique run IDs, got %s twice", first)
+	}
+	if strings.ContainsAny(first, `/\: `) {
+		t.Errorf("Run ID %q contains characters unsafe for file names", first)
+	}
+
+	// Run IDs are ULIDs: 26 Crockford base32 characters that sort by creation time
+	if len(first) != 26 || strings.Trim(first, "0123456789ABCDEFGHJKMNPQRSTVWXYZ") != "" {
+		t.Errorf("Expected a ULID, got %q", first)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if later := state.NewRunID(); later <= first {
+		t.Errorf("Expected %q to sort after %q", later, first)
+	}
+}
+
+// TestParseMode tests octal permission parsing
+func TestParseMode(t *testing.T) {
+	if mode, err := state.ParseMode("0750"); err != nil || mode != 0750 {
+		t.Errorf("ParseMode(0750) = %o, %v", mode, err)
+	}
+	if _, err := state.ParseMode("rwx"); err == nil {
+		t.Error("Expected error for non-octal mode, but got none")
+	}
+}
@@ -0,0 +1,44 @@
+package walker
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// SummarizeFolder reads a folder's direct contents and summarizes them
+// Walkers that don't list directories themselves (e.g. retries) use this to classify folders
+func SummarizeFolder(fileSystem interfaces.FileSystem, path string) (*interfaces.ContentSummary, error) {
+	entries, err := fileSystem.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return summarize(entries), nil
+}
+
+// summarize builds the content summary of a directory listing
+func summarize(entries []fs.DirEntry) *interfaces.ContentSummary {
+	summary := &interfaces.ContentSummary{
+		Extensions: make(map[string]int),
+		Names:      make(map[string]bool, len(entries)),
+	}
+
+	for _, entry := range entries {
+		name := strings.ToLower(entry.Name())
+		summary.Names[name] = true
+
+		if !entry.Type().IsRegular() {
+			continue
+		}
+
+		summary.Files++
+		if ext := filepath.Ext(name); ext != "" && ext != name {
+			summary.Extensions[ext]++
+		}
+	}
+
+	return summary
+}
@@ -0,0 +1,39 @@
+package walker
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// ErrOneFileSystemUnsupported is returned when staying on one file system is requested on a platform without device IDs
+var ErrOneFileSystemUnsupported = errors.New("staying on one file system is not supported on this platform")
+
+// WithOneFileSystem keeps the walk on the file system of the root, like du -x
+// Directories on other devices (mount points) are neither returned nor descended into
+func WithOneFileSystem(oneFileSystem bool) Option {
+	return func(fsw *FileSystemWalker) {
+		fsw.oneFileSystem = oneFileSystem
+	}
+}
+
+// OneFileSystemSupported reports whether mount points can be detected on this platform
+func OneFileSystemSupported() bool {
+	return devicesSupported
+}
+
+// rootDevice remembers the device of the walk root, if the walk stays on one file system
+type rootDevice struct {
+	id    uint64
+	known bool
+}
+
+// crosses reports whether a directory lives on a different device than the root
+// Directories whose device can't be determined are treated as local
+func (d rootDevice) crosses(info fs.FileInfo) bool {
+	if !d.known {
+		return false
+	}
+
+	id, ok := fileDevice(info)
+	return ok && id != d.id
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package walker
+
+import (
+	"io/fs"
+)
+
+// devicesSupported reports whether device IDs are available on this platform
+const devicesSupported = false
+
+// fileDevice reports that device IDs are unavailable on this platform
+func fileDevice(info fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
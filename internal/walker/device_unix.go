@@ -0,0 +1,20 @@
+//go:build unix
+
+package walker
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// devicesSupported reports whether device IDs are available on this platform
+const devicesSupported = true
+
+// fileDevice returns the ID of the device a file lives on
+func fileDevice(info fs.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(stat.Dev), true
+}
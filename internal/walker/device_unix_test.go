@@ -0,0 +1,80 @@
+//go:build unix
+
+package walker_test
+
+import (
+	"io/fs"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/walker"
+)
+
+// mountedFileSystem reports every path below mountPoint as living on a second device
+type mountedFileSystem struct {
+	*filesystem.MemoryFileSystem
+	mountPoint string
+}
+
+// deviceInfo attaches a device ID to a memory file system entry
+type deviceInfo struct {
+	fs.FileInfo
+	stat *syscall.Stat_t
+}
+
+func (d deviceInfo) Sys() any { return d.stat }
+
+func (m mountedFileSystem) withDevice(path string, info fs.FileInfo, err error) (fs.FileInfo, error) {
+	if err != nil {
+		return nil, err
+	}
+	stat := &syscall.Stat_t{Dev: 1}
+	if path == m.mountPoint || strings.HasPrefix(path, m.mountPoint+"/") {
+		stat.Dev = 2
+	}
+	return deviceInfo{FileInfo: info, stat: stat}, nil
+}
+
+func (m mountedFileSystem) Stat(path string) (fs.FileInfo, error) {
+	info, err := m.MemoryFileSystem.Stat(path)
+	return m.withDevice(path, info, err)
+}
+
+func (m mountedFileSystem) Lstat(path string) (fs.FileInfo, error) {
+	info, err := m.MemoryFileSystem.Lstat(path)
+	return m.withDevice(path, info, err)
+}
+
+// TestFileSystemWalker_OneFileSystem tests that mount points are skipped together with their contents
+func TestFileSystemWalker_OneFileSystem(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/srv/local/child")
+	memory.MkdirAll("/srv/nfs/remote")
+	mounted := mountedFileSystem{MemoryFileSystem: memory, mountPoint: "/srv/nfs"}
+
+	walk := func(oneFileSystem bool) map[string]bool {
+		folders, err := walker.NewFileSystemWalker(true, 0,
+			walker.WithFileSystem(mounted),
+			walker.WithOneFileSystem(oneFileSystem),
+		).Walk("/srv")
+		if err != nil {
+			t.Fatalf("Walk() returned error: %v", err)
+		}
+		paths := make(map[string]bool)
+		for _, folder := range folders {
+			paths[folder.Path] = true
+		}
+		return paths
+	}
+
+	paths := walk(true)
+	if len(paths) != 2 || !paths["/srv/local"] || !paths["/srv/local/child"] {
+		t.Errorf("Expected only the local folders, got %v", paths)
+	}
+
+	if paths := walk(false); len(paths) != 4 {
+		t.Errorf("Expected all 4 folders without WithOneFileSystem, got %v", paths)
+	}
+}
@@ -0,0 +1,50 @@
+package walker
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// Link policies for directories that are links to other directories
+const (
+	// LinksSkip neither renames links nor descends into them
+	LinksSkip = "skip"
+	// LinksRename renames the links themselves, but never descends into them
+	LinksRename = "rename"
+)
+
+// ValidateLinks reports an error for unknown link policies
+func ValidateLinks(policy string) error {
+	if policy != LinksSkip && policy != LinksRename {
+		return fmt.Errorf("unknown link policy %q (expected %s or %s)", policy, LinksSkip, LinksRename)
+	}
+	return nil
+}
+
+// WithLinks sets how symbolic links, junctions and volume mount points to directories are handled
+// They are never descended into, since their targets may lie outside the tree or lead back into it
+func WithLinks(policy string) Option {
+	return func(fsw *FileSystemWalker) {
+		fsw.links = policy
+	}
+}
+
+// linkInfo presents a link to a directory as a directory without contents
+// This lets the walk rename the link like a folder without reading its target
+type linkInfo struct {
+	fs.FileInfo
+}
+
+// IsDir reports the link as a directory
+func (linkInfo) IsDir() bool { return true }
+
+// isDirectoryLink reports whether info, as returned by Lstat, is a link whose target is a directory
+// Go reports symbolic links with ModeSymlink, and Windows junctions and volume mount points as irregular files;
+// other reparse points on directories, such as cloud-file placeholders, are ordinary directories
+func (fsw *FileSystemWalker) isDirectoryLink(path string, info fs.FileInfo) bool {
+	if info.IsDir() || info.Mode()&(fs.ModeSymlink|fs.ModeIrregular) == 0 {
+		return false
+	}
+	target, err := fsw.fileSystem.Stat(path)
+	return err == nil && target.IsDir()
+}
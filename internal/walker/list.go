@@ -0,0 +1,32 @@
+// Package walker provides a list-based walker for targeted re-runs.
+// This implementation returns a fixed set of folders instead of scanning the file system.
+package walker
+
+import (
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// ListWalker implements the DirectoryWalker interface over a predefined folder list
+// This struct lets a run process exactly the items from a previous run without re-scanning the tree
+type ListWalker struct {
+	folders []interfaces.FolderInfo
+}
+
+// NewListWalker creates a walker that always returns the provided folders
+func NewListWalker(folders []interfaces.FolderInfo) interfaces.DirectoryWalker {
+	return &ListWalker{
+		folders: folders,
+	}
+}
+
+// Walk returns the predefined folders sorted deepest first; rootPath is ignored
+// This method implements the DirectoryWalker interface
+func (lw *ListWalker) Walk(rootPath string) ([]interfaces.FolderInfo, error) {
+	folders := make([]interfaces.FolderInfo, len(lw.folders))
+	copy(folders, lw.folders)
+
+	// Keep the same bottom-up ordering guarantees as the file system walker
+	sortByDepth(folders)
+
+	return folders, nil
+}
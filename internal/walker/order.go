@@ -0,0 +1,75 @@
+// Package walker provides a walker that changes the order folders are processed in.
+// This implementation lets a run rename parents before their children (top-down).
+package walker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Orders folders can be renamed in
+const (
+	OrderBottomUp = "bottom-up" // Deepest folders first, so no rename moves a folder that is still to be processed
+	OrderTopDown  = "top-down"  // Shallowest folders first, so shortened parents count towards the path length of their children
+)
+
+// ValidateOrder returns an error for an unknown rename order
+func ValidateOrder(order string) error {
+	switch order {
+	case OrderBottomUp, OrderTopDown:
+		return nil
+	default:
+		return fmt.Errorf("unknown order %q (available: %s)", order, strings.Join([]string{OrderBottomUp, OrderTopDown}, ", "))
+	}
+}
+
+// OrderedWalker implements the DirectoryWalker interface by reordering the folders of the wrapped walker
+// Wrapping the walker, rather than sorting later, keeps checkpoints and plans in the order the run processes folders
+type OrderedWalker struct {
+	next  interfaces.DirectoryWalker
+	order string
+}
+
+// NewOrderedWalker creates a walker that returns the folders of next in the given order
+// Walkers return folders deepest first, so OrderBottomUp leaves them as they are
+func NewOrderedWalker(next interfaces.DirectoryWalker, order string) interfaces.DirectoryWalker {
+	return &OrderedWalker{
+		next:  next,
+		order: order,
+	}
+}
+
+// Walk returns the folders next finds, shallowest first for OrderTopDown
+// Folders of the same depth keep their order, so siblings are processed like in a bottom-up run
+// This method implements the DirectoryWalker interface
+func (ow *OrderedWalker) Walk(rootPath string) ([]interfaces.FolderInfo, error) {
+	folders, err := ow.next.Walk(rootPath)
+	if err != nil || ow.order != OrderTopDown {
+		return folders, err
+	}
+
+	sort.SliceStable(folders, func(i, j int) bool {
+		return folders[i].Depth < folders[j].Depth
+	})
+	return folders, nil
+}
+
+// Warnings forwards the problems the wrapped walker skipped over
+// This method implements the WarningWalker interface
+func (ow *OrderedWalker) Warnings() []error {
+	if warningWalker, ok := ow.next.(interfaces.WarningWalker); ok {
+		return warningWalker.Warnings()
+	}
+	return nil
+}
+
+// ObserveScan forwards the observer to the wrapped walker if it reports its scan
+// This method implements the ScanningWalker interface
+func (ow *OrderedWalker) ObserveScan(observe func(scanned int, path string)) {
+	if scanningWalker, ok := ow.next.(interfaces.ScanningWalker); ok {
+		scanningWalker.ObserveScan(observe)
+	}
+}
@@ -0,0 +1,138 @@
+package walker
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/user"
+	"strconv"
+)
+
+// ErrOwnershipUnsupported is returned when ownership filters or attribution are requested on a platform without numeric owners
+var ErrOwnershipUnsupported = errors.New("directory ownership is not supported on this platform")
+
+// OwnerFilter restricts the walk to directories owned by a user and/or group
+// Directories that don't match are not returned, but their subdirectories are still walked
+type OwnerFilter struct {
+	UID int // Required owner user ID (-1 = any)
+	GID int // Required owner group ID (-1 = any)
+}
+
+// NewOwnerFilter resolves user and group names (or numeric IDs) into a filter
+// Empty names match any owner
+func NewOwnerFilter(owner, group string) (OwnerFilter, error) {
+	filter := OwnerFilter{UID: -1, GID: -1}
+	if owner == "" && group == "" {
+		return filter, nil
+	}
+	if !ownershipSupported {
+		return filter, ErrOwnershipUnsupported
+	}
+
+	if owner != "" {
+		uid, err := lookupID(owner, func(name string) (string, error) {
+			u, err := user.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			return u.Uid, nil
+		})
+		if err != nil {
+			return filter, fmt.Errorf("unknown owner %q: %w", owner, err)
+		}
+		filter.UID = uid
+	}
+
+	if group != "" {
+		gid, err := lookupID(group, func(name string) (string, error) {
+			g, err := user.LookupGroup(name)
+			if err != nil {
+				return "", err
+			}
+			return g.Gid, nil
+		})
+		if err != nil {
+			return filter, fmt.Errorf("unknown group %q: %w", group, err)
+		}
+		filter.GID = gid
+	}
+
+	return filter, nil
+}
+
+// OwnershipSupported reports whether directory owners can be determined on this platform
+func OwnershipSupported() bool {
+	return ownershipSupported
+}
+
+// WithOwnerFilter restricts the walk to directories owned by the filter's user and/or group
+func WithOwnerFilter(filter OwnerFilter) Option {
+	return func(fsw *FileSystemWalker) {
+		fsw.ownerFilter = filter
+	}
+}
+
+// WithOwnerAttribution makes the walker record the owner of every returned directory
+// Owners are resolved to user names where possible and to numeric IDs otherwise
+func WithOwnerAttribution(attribute bool) Option {
+	return func(fsw *FileSystemWalker) {
+		fsw.attributeOwners = attribute
+	}
+}
+
+// ownerNames resolves numeric owners to user names, caching every lookup for the walk
+type ownerNames map[int]string
+
+// name returns the owner name of a file, or an empty string when owners are unavailable
+func (names ownerNames) name(info fs.FileInfo) string {
+	uid, _, ok := fileOwner(info)
+	if !ok {
+		return ""
+	}
+
+	if name, cached := names[uid]; cached {
+		return name
+	}
+
+	name := strconv.Itoa(uid)
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	names[uid] = name
+
+	return name
+}
+
+// isActive reports whether the filter restricts anything
+func (f OwnerFilter) isActive() bool {
+	return f.UID >= 0 || f.GID >= 0
+}
+
+// matches reports whether a directory's owner satisfies the filter
+// Directories whose owner can't be determined never match an active filter
+func (f OwnerFilter) matches(info fs.FileInfo) bool {
+	if !f.isActive() {
+		return true
+	}
+
+	uid, gid, ok := fileOwner(info)
+	if !ok {
+		return false
+	}
+
+	return (f.UID < 0 || f.UID == uid) && (f.GID < 0 || f.GID == gid)
+}
+
+// lookupID accepts a numeric ID directly or resolves a name with lookup
+func lookupID(name string, lookup func(string) (string, error)) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+
+	id, err := lookup(name)
+	if err != nil {
+		return -1, err
+	}
+
+	return strconv.Atoi(id)
+}
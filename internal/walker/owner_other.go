@@ -0,0 +1,15 @@
+//go:build !unix
+
+package walker
+
+import (
+	"io/fs"
+)
+
+// ownershipSupported reports whether file owners are available on this platform
+const ownershipSupported = false
+
+// fileOwner reports that owners are unavailable on this platform
+func fileOwner(info fs.FileInfo) (uid, gid int, ok bool) {
+	return -1, -1, false
+}
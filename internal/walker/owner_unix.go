@@ -0,0 +1,20 @@
+//go:build unix
+
+package walker
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// ownershipSupported reports whether file owners are available on this platform
+const ownershipSupported = true
+
+// fileOwner returns the numeric owner and group of a file
+func fileOwner(info fs.FileInfo) (uid, gid int, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return -1, -1, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
@@ -0,0 +1,49 @@
+package walker
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// ReadPathList reads directories to process, one per line, for use with a ListWalker
+// Relative paths are resolved against root; blank lines and repeated paths are skipped.
+// Lines are not trimmed beyond the line ending, because trailing spaces may be what needs fixing.
+func ReadPathList(r io.Reader, root string) ([]interfaces.FolderInfo, error) {
+	var folders []interfaces.FolderInfo
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		path := line
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		path = filepath.Clean(path)
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		folders = append(folders, interfaces.FolderInfo{
+			Path:   path,
+			Name:   filepath.Base(path),
+			Depth:  strings.Count(path, string(filepath.Separator)),
+			Parent: filepath.Dir(path),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read path list: %w", err)
+	}
+
+	return folders, nil
+}
@@ -0,0 +1,88 @@
+package walker
+
+import (
+	"bufio"
+	"bytes"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Protection describes directories the walker never returns for renaming
+// Renaming these would break the tools that own them
+type Protection struct {
+	// Names are directory names (case-insensitive) that are neither renamed nor descended into
+	Names []string
+	// MarkerFile exempts any directory containing a file with this name from renaming ("" = disabled)
+	MarkerFile string
+	// MarkerSubtree makes every marker file exempt the whole subtree, not just its directory
+	MarkerSubtree bool
+}
+
+// markerSubtreeKeyword in a marker file exempts the directory's whole subtree
+const markerSubtreeKeyword = "subtree"
+
+// DefaultProtection returns the built-in protection for version control, dependency and build directories
+func DefaultProtection() Protection {
+	return Protection{
+		Names:      []string{".git", ".svn", ".hg", "node_modules", "__pycache__", ".venv", "target"},
+		MarkerFile: ".nosanitize",
+	}
+}
+
+// WithProtection replaces the built-in protection; an empty Protection disables it
+func WithProtection(protection Protection) Option {
+	return func(fsw *FileSystemWalker) {
+		fsw.protection = protection
+	}
+}
+
+// IsProtectedName reports whether a directory name is protected together with its subtree
+func (p Protection) IsProtectedName(name string) bool {
+	for _, protected := range p.Names {
+		if strings.EqualFold(name, protected) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMarker reports whether a directory listing contains the marker file
+func (p Protection) hasMarker(entries []fs.DirEntry) bool {
+	if p.MarkerFile == "" {
+		return false
+	}
+	for _, entry := range entries {
+		if entry.Name() == p.MarkerFile && !entry.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// markerExemptsSubtree reports whether the marker in dir covers the whole subtree
+// Data owners opt in by writing "subtree" on its own line; an empty marker only covers the directory
+func (p Protection) markerExemptsSubtree(fileSystem interfaces.FileSystem, dir string) bool {
+	if p.MarkerSubtree {
+		return true
+	}
+
+	content, err := fileSystem.ReadFile(filepath.Join(dir, p.MarkerFile))
+	if err != nil {
+		return false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.EqualFold(line, markerSubtreeKeyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+// Package walker provides a walker restricted to a single directory tree.
+// This implementation lets watch mode process a new directory while reporting against the watched root.
+package walker
+
+import (
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// SubtreeWalker implements the DirectoryWalker interface for one directory tree below the root
+// This struct walks its own directory with the wrapped walker, whatever root the caller passes
+type SubtreeWalker struct {
+	next interfaces.DirectoryWalker
+	dir  string
+}
+
+// NewSubtreeWalker creates a walker that walks dir with next instead of the root it is given
+func NewSubtreeWalker(next interfaces.DirectoryWalker, dir string) interfaces.DirectoryWalker {
+	return &SubtreeWalker{
+		next: next,
+		dir:  dir,
+	}
+}
+
+// Walk returns the folders next finds in the subtree; rootPath is ignored
+// This method implements the DirectoryWalker interface
+func (sw *SubtreeWalker) Walk(rootPath string) ([]interfaces.FolderInfo, error) {
+	return sw.next.Walk(sw.dir)
+}
+
+// Warnings forwards the problems the wrapped walker skipped over
+// This method implements the WarningWalker interface
+func (sw *SubtreeWalker) Warnings() []error {
+	if warningWalker, ok := sw.next.(interfaces.WarningWalker); ok {
+		return warningWalker.Warnings()
+	}
+	return nil
+}
+
+// ObserveScan forwards the observer to the wrapped walker if it reports its scan
+// This method implements the ScanningWalker interface
+func (sw *SubtreeWalker) ObserveScan(observe func(scanned int, path string)) {
+	if scanningWalker, ok := sw.next.(interfaces.ScanningWalker); ok {
+		scanningWalker.ObserveScan(observe)
+	}
+}
@@ -3,12 +3,16 @@
 package walker
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
 
-	"sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
 )
 
 // FileSystemWalker implements the DirectoryWalker interface for file system traversal
@@ -18,25 +22,110 @@ type FileSystemWalker struct {
 	skipInaccessible bool
 	// maxDepth limits how deep the walker will traverse (0 = unlimited)
 	maxDepth int
+	// fileSystem is the backend the walker reads from
+	fileSystem interfaces.FileSystem
+	// summarizeContents attaches a ContentSummary to every folder
+	summarizeContents bool
+	// protection lists directories that are never returned for renaming
+	protection Protection
+	// ownerFilter restricts the returned directories to an owner
+	ownerFilter OwnerFilter
+	// attributeOwners records the owner of every returned directory
+	attributeOwners bool
+	// owners caches owner name lookups during a walk
+	owners ownerNames
+	// oneFileSystem keeps the walk on the device of the root
+	oneFileSystem bool
+	// links is how links to directories below the root are handled, see LinksSkip and LinksRename
+	links string
+	// device is the device of the current walk's root when oneFileSystem is set
+	device rootDevice
+	// warnings collects the problems the current walk skipped over
+	warnings []error
+	// includeRoot returns the root directory itself, after everything below it
+	includeRoot bool
+	// observeScan is told about every directory the walk reads (nil = nobody)
+	observeScan func(scanned int, path string)
+	// scanned counts the directories the current walk read
+	scanned int
+	// logger records skipped directories and read problems as they are found
+	logger *slog.Logger
+}
+
+// Option configures optional FileSystemWalker behavior
+type Option func(*FileSystemWalker)
+
+// WithFileSystem makes the walker read from the given backend instead of the real file system
+func WithFileSystem(fileSystem interfaces.FileSystem) Option {
+	return func(fsw *FileSystemWalker) {
+		fsw.fileSystem = fileSystem
+	}
+}
+
+// WithContentSummary makes the walker attach a summary of each folder's direct contents
+// The summary is built from the directory listing the walk reads anyway, so it costs no extra I/O
+func WithContentSummary(summarize bool) Option {
+	return func(fsw *FileSystemWalker) {
+		fsw.summarizeContents = summarize
+	}
+}
+
+// WithRootIncluded makes the walk return the root directory itself at depth 0, so it is processed last
+// Protection and ownership rules apply to the root like to any other directory
+func WithRootIncluded(include bool) Option {
+	return func(fsw *FileSystemWalker) {
+		fsw.includeRoot = include
+	}
+}
+
+// WithLogger records skipped directories (info) and directories that could not be read (warn) as they are found
+// The problems are still collected for Warnings, so reporters see them whether or not a logger is set
+func WithLogger(logger *slog.Logger) Option {
+	return func(fsw *FileSystemWalker) {
+		fsw.logger = logger
+	}
 }
 
 // NewFileSystemWalker creates a new instance of FileSystemWalker with default settings
 // This constructor allows for configuration of walker behavior
-func NewFileSystemWalker(skipInaccessible bool, maxDepth int) interfaces.DirectoryWalker {
-	return &FileSystemWalker{
+func NewFileSystemWalker(skipInaccessible bool, maxDepth int, options ...Option) interfaces.DirectoryWalker {
+	fsw := &FileSystemWalker{
 		skipInaccessible: skipInaccessible,
 		maxDepth:         maxDepth,
+		fileSystem:       filesystem.NewOSFileSystem(),
+		protection:       DefaultProtection(),
+		links:            LinksSkip,
+		ownerFilter:      OwnerFilter{UID: -1, GID: -1},
+		logger:           slog.New(slog.DiscardHandler),
 	}
+
+	for _, option := range options {
+		option(fsw)
+	}
+
+	return fsw
 }
 
 // Walk traverses the directory tree and returns folder information sorted by depth
 // This method implements the DirectoryWalker interface with proper error handling
 func (fsw *FileSystemWalker) Walk(rootPath string) ([]interfaces.FolderInfo, error) {
+	fsw.warnings = nil
+	fsw.scanned = 0
+
 	// Validate the root path exists and is accessible
 	if err := fsw.validateRootPath(rootPath); err != nil {
 		return nil, fmt.Errorf("invalid root path: %w", err)
 	}
 
+	fsw.owners = make(ownerNames)
+
+	fsw.device = rootDevice{}
+	if fsw.oneFileSystem {
+		if info, err := fsw.fileSystem.Stat(rootPath); err == nil {
+			fsw.device.id, fsw.device.known = fileDevice(info)
+		}
+	}
+
 	// Collect all directories using filepath.Walk
 	folders, err := fsw.collectDirectories(rootPath)
 	if err != nil {
@@ -49,6 +138,18 @@ func (fsw *FileSystemWalker) Walk(rootPath string) ([]interfaces.FolderInfo, err
 	return folders, nil
 }
 
+// ObserveScan makes later walks call observe after reading each directory
+// This method implements the ScanningWalker interface
+func (fsw *FileSystemWalker) ObserveScan(observe func(scanned int, path string)) {
+	fsw.observeScan = observe
+}
+
+// Warnings returns the directories the most recent walk could not read or had to skip
+// This method implements the WarningWalker interface
+func (fsw *FileSystemWalker) Warnings() []error {
+	return fsw.warnings
+}
+
 // validateRootPath ensures the root path exists and is a directory
 // This method provides early validation to prevent unnecessary processing
 func (fsw *FileSystemWalker) validateRootPath(rootPath string) error {
@@ -59,7 +160,7 @@ func (fsw *FileSystemWalker) validateRootPath(rootPath string) error {
 	}
 
 	// Check if path exists and is accessible
-	info, err := os.Stat(absPath)
+	info, err := fsw.fileSystem.Stat(absPath)
 	if err != nil {
 		return fmt.Errorf("path not accessible: %w", err)
 	}
@@ -78,18 +179,17 @@ func (fsw *FileSystemWalker) collectDirectories(rootPath string) ([]interfaces.F
 	var folders []interfaces.FolderInfo
 	var collectErrors []error
 
-	// Use filepath.Walk for comprehensive directory traversal
-	err := filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
-		return fsw.processWalkPath(path, info, err, rootPath, &folders, &collectErrors)
+	// Walk the tree through the configured file system backend
+	err := fsw.walk(rootPath, func(path string, info fs.FileInfo, entries []fs.DirEntry, err error) error {
+		return fsw.processWalkPath(path, info, entries, err, rootPath, &folders, &collectErrors)
 	})
 
-	// If we encountered errors but still have folders, continue with warnings
-	if len(collectErrors) > 0 {
-		// Log warnings about inaccessible directories
-		for _, collectErr := range collectErrors {
-			// In a real implementation, this might use a proper logger
-			fmt.Printf("Warning: %v\n", collectErr)
-		}
+	// Problems with single directories don't stop the walk; the caller decides how to report them
+	fsw.warnings = collectErrors
+
+	// An incomplete listing must not be processed as if it were the whole tree
+	if errors.Is(err, interfaces.ErrFileSystemUnavailable) {
+		return nil, fmt.Errorf("directory walk interrupted: %w", err)
 	}
 
 	// Return error only if we couldn't collect any folders and had a critical error
@@ -100,12 +200,97 @@ func (fsw *FileSystemWalker) collectDirectories(rootPath string) ([]interfaces.F
 	return folders, nil
 }
 
+// walkFunc is called for every path visited by walk
+// It matches filepath.WalkFunc, plus the directory listing for directories that could be read
+type walkFunc func(path string, info fs.FileInfo, entries []fs.DirEntry, err error) error
+
+// walk traverses the tree below root through the file system backend
+// This method mirrors filepath.Walk semantics, including SkipDir handling and lexical ordering
+func (fsw *FileSystemWalker) walk(root string, fn walkFunc) error {
+	info, err := fsw.fileSystem.Lstat(root)
+	// The root is followed even if it is a link, since it was named explicitly
+	if err == nil && fsw.isDirectoryLink(root, info) {
+		info, err = fsw.fileSystem.Stat(root)
+	}
+	if err != nil {
+		err = fn(root, nil, nil, err)
+	} else {
+		err = fsw.walkPath(root, info, fn)
+	}
+	if err == filepath.SkipDir || err == filepath.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walkPath recursively descends path, calling fn for path and every entry below it
+func (fsw *FileSystemWalker) walkPath(path string, info fs.FileInfo, fn walkFunc) error {
+	// Links to directories are never read: their target may be outside the tree or contain the tree itself
+	if fsw.isDirectoryLink(path, info) {
+		if fsw.links != LinksRename {
+			fsw.logger.Info("skipped link", "path", path)
+			return nil
+		}
+		// Nothing below the link is walked, so skipping it is the same as visiting it
+		if err := fn(path, linkInfo{info}, nil, nil); err != filepath.SkipDir {
+			return err
+		}
+		return nil
+	}
+
+	if !info.IsDir() {
+		return fn(path, info, nil, nil)
+	}
+
+	// Mount points are skipped before their listing is read, so unresponsive network mounts aren't touched
+	if fsw.device.crosses(info) {
+		fsw.logger.Info("skipped mount point", "path", path)
+		return nil
+	}
+
+	entries, readErr := fsw.fileSystem.ReadDir(path)
+	fsw.scanned++
+	if fsw.observeScan != nil {
+		fsw.observeScan(fsw.scanned, path)
+	}
+	err := fn(path, info, entries, readErr)
+	// A read error gives fn a chance to skip the directory; either way its entries can't be walked
+	if readErr != nil || err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		childPath := filepath.Join(path, entry.Name())
+		childInfo, err := fsw.fileSystem.Lstat(childPath)
+		if err != nil {
+			if err := fn(childPath, childInfo, nil, err); err != nil && err != filepath.SkipDir {
+				return err
+			}
+			continue
+		}
+
+		if err := fsw.walkPath(childPath, childInfo, fn); err != nil {
+			if !childInfo.IsDir() || err != filepath.SkipDir {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // processWalkPath handles each path encountered during directory traversal
-// This method implements the logic for each filepath.Walk callback
-func (fsw *FileSystemWalker) processWalkPath(path string, info os.FileInfo, err error, rootPath string, folders *[]interfaces.FolderInfo, collectErrors *[]error) error {
+// This method implements the logic for each walk callback
+func (fsw *FileSystemWalker) processWalkPath(path string, info fs.FileInfo, entries []fs.DirEntry, err error, rootPath string, folders *[]interfaces.FolderInfo, collectErrors *[]error) error {
 	// Handle path access errors
 	if err != nil {
+		// A file system that went away fails every remaining path, so stop the walk instead
+		if errors.Is(err, interfaces.ErrFileSystemUnavailable) {
+			return err
+		}
+
 		if fsw.skipInaccessible && os.IsPermission(err) {
+			fsw.logger.Warn("skipped directory", "path", path, "error", err)
 			*collectErrors = append(*collectErrors, fmt.Errorf("permission denied: %s", path))
 			return filepath.SkipDir
 		}
@@ -114,14 +299,15 @@ func (fsw *FileSystemWalker) processWalkPath(path string, info os.FileInfo, err
 		if path != rootPath {
 			folderInfo := fsw.extractFolderInfoFromPath(path, rootPath)
 			*folders = append(*folders, folderInfo)
+			fsw.logger.Warn("cannot access directory", "path", path, "error", err)
 			*collectErrors = append(*collectErrors, fmt.Errorf("error accessing %s: %w", path, err))
 		}
 
 		return filepath.SkipDir
 	}
 
-	// Process directories (skip the root directory itself)
-	if info.IsDir() && path != rootPath {
+	// Process directories (skip the root directory itself unless asked to include it)
+	if info.IsDir() && (path != rootPath || fsw.includeRoot) {
 		depth := fsw.calculateDepth(path, rootPath)
 
 		// Check depth limit if specified
@@ -129,12 +315,40 @@ func (fsw *FileSystemWalker) processWalkPath(path string, info os.FileInfo, err
 			return filepath.SkipDir
 		}
 
+		// Tool-owned directories are left alone together with everything inside them
+		if fsw.protection.IsProtectedName(info.Name()) {
+			fsw.logger.Info("skipped protected directory", "path", path)
+			return filepath.SkipDir
+		}
+
+		// A marker file exempts its own directory and, if it asks for it, the whole subtree
+		if fsw.protection.hasMarker(entries) {
+			if fsw.protection.markerExemptsSubtree(fsw.fileSystem, path) {
+				fsw.logger.Info("skipped directory with marker file", "path", path, "subtree", true)
+				return filepath.SkipDir
+			}
+			fsw.logger.Info("skipped directory with marker file", "path", path, "subtree", false)
+			return nil
+		}
+
+		// Directories owned by someone else are left alone, but their subdirectories may still match
+		if !fsw.ownerFilter.matches(info) {
+			fsw.logger.Debug("skipped directory of another owner", "path", path)
+			return nil
+		}
+
 		folderInfo := interfaces.FolderInfo{
 			Path:   path,
 			Name:   filepath.Base(path),
 			Depth:  depth,
 			Parent: filepath.Dir(path),
 		}
+		if fsw.summarizeContents {
+			folderInfo.Contents = summarize(entries)
+		}
+		if fsw.attributeOwners {
+			folderInfo.Owner = fsw.owners.name(info)
+		}
 
 		*folders = append(*folders, folderInfo)
 	}
@@ -178,6 +392,11 @@ func (fsw *FileSystemWalker) calculateDepth(path, root string) int {
 // sortFoldersByDepth sorts folders by depth (deepest first) for bottom-up processing
 // This method ensures safe processing order to avoid path conflicts during renames
 func (fsw *FileSystemWalker) sortFoldersByDepth(folders []interfaces.FolderInfo) {
+	sortByDepth(folders)
+}
+
+// sortByDepth sorts folders deepest first, then by path, shared by all walker implementations
+func sortByDepth(folders []interfaces.FolderInfo) {
 	sort.Slice(folders, func(i, j int) bool {
 		// Primary sort: deeper folders first
 		if folders[i].Depth != folders[j].Depth {
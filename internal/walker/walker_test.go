@@ -3,12 +3,21 @@
 package walker_test
 
 import (
+	"bytes"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
+	"os/user"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
 
-	"sanitize/internal/walker"
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/walker"
 )
 
 // TestFileSystemWalker_Walk tests basic directory walking functionality
@@ -220,6 +229,356 @@ func TestFileSystemWalker_SkipInaccessible(t *testing.T) {
 	}
 }
 
+// TestListWalker_Walk tests that the list walker returns the predefined folders deepest first
+// This test ensures targeted re-runs keep the bottom-up processing order
+func TestListWalker_Walk(t *testing.T) {
+	w := walker.NewListWalker([]interfaces.FolderInfo{
+		{Path: "/root/a", Name: "a", Depth: 1, Parent: "/root"},
+		{Path: "/root/a/b/c", Name: "c", Depth: 3, Parent: "/root/a/b"},
+		{Path: "/root/a/b", Name: "b", Depth: 2, Parent: "/root/a"},
+	})
+
+	folders, err := w.Walk("/ignored")
+	if err != nil {
+		t.Fatalf("Walk() returned error: %v", err)
+	}
+
+	if len(folders) != 3 {
+		t.Fatalf("Expected 3 folders, got %d", len(folders))
+	}
+
+	for i, expected := range []string{"c", "b", "a"} {
+		if folders[i].Name != expected {
+			t.Errorf("Expected folder %d to be %s, got %s", i, expected, folders[i].Name)
+		}
+	}
+}
+
+// TestSubtreeWalker_RootIncluded tests that a subtree walk returns the new directory itself last
+// This test ensures watch mode renames children before their parent and honors protection on the new directory
+func TestSubtreeWalker_RootIncluded(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/tree/in:box/a:1")
+	memory.MkdirAll("/tree/other:dir")
+	memory.MkdirAll("/tree/node_modules/x:y")
+
+	base := walker.NewFileSystemWalker(true, 0, walker.WithFileSystem(memory), walker.WithRootIncluded(true))
+	folders, err := walker.NewSubtreeWalker(base, "/tree/in:box").Walk("/tree")
+	if err != nil {
+		t.Fatalf("Walk() returned error: %v", err)
+	}
+
+	if len(folders) != 2 || folders[0].Path != "/tree/in:box/a:1" || folders[1].Path != "/tree/in:box" {
+		t.Fatalf("Expected a:1 and then in:box, got %+v", folders)
+	}
+	if folders[1].Depth != 0 || folders[1].Parent != "/tree" {
+		t.Errorf("Expected the subtree root at depth 0 below /tree, got %+v", folders[1])
+	}
+
+	// A protected directory stays protected when it is the root of the walk
+	folders, _ = walker.NewSubtreeWalker(base, "/tree/node_modules").Walk("/tree")
+	if len(folders) != 0 {
+		t.Errorf("Expected nothing from a protected subtree, got %+v", folders)
+	}
+}
+
+// TestFileSystemWalker_ObserveScan tests that every directory read is reported with a running count, also through a SubtreeWalker
+func TestFileSystemWalker_ObserveScan(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/tree/a/b")
+	memory.MkdirAll("/tree/c")
+
+	var counts []int
+	var paths []string
+	subtree := walker.NewSubtreeWalker(walker.NewFileSystemWalker(true, 0, walker.WithFileSystem(memory)), "/tree")
+	scanningWalker, ok := subtree.(interfaces.ScanningWalker)
+	if !ok {
+		t.Fatal("Expected SubtreeWalker to implement ScanningWalker")
+	}
+	scanningWalker.ObserveScan(func(scanned int, path string) {
+		counts = append(counts, scanned)
+		paths = append(paths, path)
+	})
+
+	for range 2 {
+		counts, paths = nil, nil
+		if _, err := subtree.Walk("/tree"); err != nil {
+			t.Fatalf("Walk() returned error: %v", err)
+		}
+		if want := []string{"/tree", "/tree/a", "/tree/a/b", "/tree/c"}; !slices.Equal(paths, want) {
+			t.Errorf("Expected scan of %v, got %v", want, paths)
+		}
+		if want := []int{1, 2, 3, 4}; !slices.Equal(counts, want) {
+			t.Errorf("Expected counts %v starting over for every walk, got %v", want, counts)
+		}
+	}
+}
+
+// TestFileSystemWalker_ContentSummary tests that folders carry a summary of their direct contents
+// This test ensures classification rules can see file extensions and marker entries
+func TestFileSystemWalker_ContentSummary(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/tree/album")
+	memory.WriteFile("/tree/album/01.MP3", nil)
+	memory.WriteFile("/tree/album/02.mp3", nil)
+	memory.WriteFile("/tree/album/cover.jpg", nil)
+	memory.MkdirAll("/tree/repo/.git")
+
+	w := walker.NewFileSystemWalker(true, 0, walker.WithFileSystem(memory), walker.WithContentSummary(true))
+	folders, err := w.Walk("/tree")
+	if err != nil {
+		t.Fatalf("Walk() returned error: %v", err)
+	}
+
+	summaries := make(map[string]*interfaces.ContentSummary)
+	for _, folder := range folders {
+		summaries[folder.Path] = folder.Contents
+	}
+
+	album := summaries["/tree/album"]
+	if album == nil || album.Files != 3 || album.Extensions[".mp3"] != 2 || album.Extensions[".jpg"] != 1 {
+		t.Errorf("Unexpected album summary: %+v", album)
+	}
+
+	repo := summaries["/tree/repo"]
+	if repo == nil || repo.Files != 0 || !repo.Names[".git"] {
+		t.Errorf("Unexpected repository summary: %+v", repo)
+	}
+
+	// Summaries are only collected on request
+	folders, _ = walker.NewFileSystemWalker(true, 0, walker.WithFileSystem(memory)).Walk("/tree")
+	for _, folder := range folders {
+		if folder.Contents != nil {
+			t.Errorf("Expected no summary for %s without WithContentSummary", folder.Path)
+		}
+	}
+}
+
+// TestFileSystemWalker_Protection tests that tool directories and marked directories are not returned
+// This test ensures protected subtrees are skipped while marked directories' children are still walked
+func TestFileSystemWalker_Protection(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/tree/.git/objects")
+	memory.MkdirAll("/tree/app/node_modules/left-pad")
+	memory.MkdirAll("/tree/owned/child")
+	memory.WriteFile("/tree/owned/.nosanitize", nil)
+	memory.MkdirAll("/tree/plain")
+
+	walk := func(options ...walker.Option) map[string]bool {
+		options = append([]walker.Option{walker.WithFileSystem(memory)}, options...)
+		folders, err := walker.NewFileSystemWalker(true, 0, options...).Walk("/tree")
+		if err != nil {
+			t.Fatalf("Walk() returned error: %v", err)
+		}
+		paths := make(map[string]bool)
+		for _, folder := range folders {
+			paths[folder.Path] = true
+		}
+		return paths
+	}
+
+	// Built-in protection is on by default
+	paths := walk()
+	for _, expected := range []string{"/tree/app", "/tree/owned/child", "/tree/plain"} {
+		if !paths[expected] {
+			t.Errorf("Expected %s to be walked", expected)
+		}
+	}
+	for _, protected := range []string{"/tree/.git", "/tree/.git/objects", "/tree/app/node_modules", "/tree/app/node_modules/left-pad", "/tree/owned"} {
+		if paths[protected] {
+			t.Errorf("Expected %s to be protected", protected)
+		}
+	}
+
+	// A marker asking for it exempts the whole subtree
+	memory.WriteFile("/tree/owned/.nosanitize", []byte("# keep our names\nsubtree\n"))
+	if paths = walk(); paths["/tree/owned/child"] {
+		t.Error("Expected subtree marker to protect /tree/owned/child")
+	}
+
+	// MarkerSubtree applies subtree protection to every marker
+	memory.WriteFile("/tree/owned/.nosanitize", nil)
+	protection := walker.DefaultProtection()
+	protection.MarkerSubtree = true
+	if paths = walk(walker.WithProtection(protection)); paths["/tree/owned/child"] {
+		t.Error("Expected MarkerSubtree to protect /tree/owned/child")
+	}
+
+	// An empty Protection disables it
+	paths = walk(walker.WithProtection(walker.Protection{}))
+	if len(paths) != 8 {
+		t.Errorf("Expected all 8 folders without protection, got %d", len(paths))
+	}
+}
+
+// TestFileSystemWalker_Logger tests that skipped directories are logged as they are found
+func TestFileSystemWalker_Logger(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/tree/.git/objects")
+	memory.MkdirAll("/tree/owned")
+	memory.WriteFile("/tree/owned/.nosanitize", nil)
+	memory.MkdirAll("/tree/plain")
+
+	var log bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&log, nil))
+	_, err := walker.NewFileSystemWalker(true, 0, walker.WithFileSystem(memory), walker.WithLogger(logger)).Walk("/tree")
+	if err != nil {
+		t.Fatalf("Walk() returned error: %v", err)
+	}
+
+	for _, expected := range []string{
+		`msg="skipped protected directory" path=/tree/.git`,
+		`msg="skipped directory with marker file" path=/tree/owned subtree=false`,
+	} {
+		if !strings.Contains(log.String(), expected) {
+			t.Errorf("Expected the log to contain %q, got:\n%s", expected, log.String())
+		}
+	}
+	if strings.Contains(log.String(), "/tree/plain") {
+		t.Errorf("Expected walked directories not to be logged, got:\n%s", log.String())
+	}
+}
+
+// linkedFileSystem reports some directories of a memory file system as links to Lstat, like Go does for
+// symbolic links (ModeSymlink) and Windows junctions (irregular files); Stat still follows them
+type linkedFileSystem struct {
+	*filesystem.MemoryFileSystem
+	links map[string]fs.FileMode
+}
+
+// linkEntry is the Lstat result for a link
+type linkEntry struct {
+	fs.FileInfo
+	mode fs.FileMode
+}
+
+func (l linkEntry) Mode() fs.FileMode { return l.mode | 0777 }
+func (l linkEntry) IsDir() bool       { return false }
+
+func (l linkedFileSystem) Lstat(path string) (fs.FileInfo, error) {
+	info, err := l.MemoryFileSystem.Lstat(path)
+	if mode, ok := l.links[path]; ok && err == nil {
+		return linkEntry{FileInfo: info, mode: mode}, nil
+	}
+	return info, err
+}
+
+// TestFileSystemWalker_Links tests that links to directories are skipped or renamed, but never descended into
+func TestFileSystemWalker_Links(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/tree/plain")
+	memory.MkdirAll("/tree/junction:old/inside")
+	memory.MkdirAll("/tree/symlink/inside")
+	memory.WriteFile("/tree/file-link", nil)
+	linked := linkedFileSystem{MemoryFileSystem: memory, links: map[string]fs.FileMode{
+		"/tree/junction:old": fs.ModeIrregular,
+		"/tree/symlink":      fs.ModeSymlink,
+		"/tree/file-link":    fs.ModeSymlink,
+	}}
+
+	walk := func(root, policy string) []string {
+		folders, err := walker.NewFileSystemWalker(true, 0, walker.WithFileSystem(linked), walker.WithLinks(policy)).Walk(root)
+		if err != nil {
+			t.Fatalf("Walk() returned error: %v", err)
+		}
+		var paths []string
+		for _, folder := range folders {
+			paths = append(paths, folder.Path)
+		}
+		slices.Sort(paths)
+		return paths
+	}
+
+	if got := walk("/tree", walker.LinksSkip); !slices.Equal(got, []string{"/tree/plain"}) {
+		t.Errorf("Expected links to be skipped, got %v", got)
+	}
+	if got, want := walk("/tree", walker.LinksRename), []string{"/tree/junction:old", "/tree/plain", "/tree/symlink"}; !slices.Equal(got, want) {
+		t.Errorf("Expected the links themselves to be returned, got %v, want %v", got, want)
+	}
+
+	// A root that is a link is followed
+	if got := walk("/tree/junction:old", walker.LinksSkip); !slices.Equal(got, []string{"/tree/junction:old/inside"}) {
+		t.Errorf("Expected the linked root to be walked, got %v", got)
+	}
+
+	if err := walker.ValidateLinks("follow"); err == nil {
+		t.Error("Expected unknown link policy to be rejected")
+	}
+}
+
+// TestFileSystemWalker_OwnerFilter tests that only directories owned by the requested user are returned
+// This test relies on the current user owning everything in a fresh temporary directory
+func TestFileSystemWalker_OwnerFilter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Ownership filters are not supported on Windows")
+	}
+
+	tempDir := createTempDirStructure(t)
+	defer os.RemoveAll(tempDir)
+
+	walk := func(filter walker.OwnerFilter) int {
+		folders, err := walker.NewFileSystemWalker(true, 0, walker.WithOwnerFilter(filter)).Walk(tempDir)
+		if err != nil {
+			t.Fatalf("Walk() returned error: %v", err)
+		}
+		return len(folders)
+	}
+
+	all := walk(walker.OwnerFilter{UID: -1, GID: -1})
+	if all == 0 {
+		t.Fatal("Expected folders without a filter")
+	}
+
+	if owned := walk(walker.OwnerFilter{UID: os.Getuid(), GID: -1}); owned != all {
+		t.Errorf("Expected all %d folders to be owned by the current user, got %d", all, owned)
+	}
+
+	if foreign := walk(walker.OwnerFilter{UID: os.Getuid() + 1, GID: -1}); foreign != 0 {
+		t.Errorf("Expected no folders owned by another user, got %d", foreign)
+	}
+
+	filter, err := walker.NewOwnerFilter(fmt.Sprint(os.Getuid()), "")
+	if err != nil || filter.UID != os.Getuid() {
+		t.Errorf("NewOwnerFilter() with numeric ID = %+v, %v", filter, err)
+	}
+}
+
+// TestFileSystemWalker_OwnerAttribution tests that returned folders carry their owner
+func TestFileSystemWalker_OwnerAttribution(t *testing.T) {
+	if !walker.OwnershipSupported() {
+		t.Skip("Directory ownership is not supported on this platform")
+	}
+
+	tempDir := createTempDirStructure(t)
+	defer os.RemoveAll(tempDir)
+
+	expected := fmt.Sprint(os.Getuid())
+	if current, err := user.Current(); err == nil {
+		expected = current.Username
+	}
+
+	folders, err := walker.NewFileSystemWalker(true, 0, walker.WithOwnerAttribution(true)).Walk(tempDir)
+	if err != nil {
+		t.Fatalf("Walk() returned error: %v", err)
+	}
+	for _, folder := range folders {
+		if folder.Owner != expected {
+			t.Errorf("Expected %s to be owned by %q, got %q", folder.Path, expected, folder.Owner)
+		}
+	}
+
+	// Without attribution owners are left empty
+	folders, err = walker.NewFileSystemWalker(true, 0).Walk(tempDir)
+	if err != nil {
+		t.Fatalf("Walk() returned error: %v", err)
+	}
+	for _, folder := range folders {
+		if folder.Owner != "" {
+			t.Errorf("Expected no owner for %s, got %q", folder.Path, folder.Owner)
+		}
+	}
+}
+
 // BenchmarkFileSystemWalker_Walk benchmarks directory walking performance
 // This benchmark helps ensure the walker performs efficiently
 func BenchmarkFileSystemWalker_Walk(b *testing.B) {
@@ -298,3 +657,70 @@ func createLargeDirStructure(b *testing.B) string {
 }
 
 // fmt import moved to the top with other imports
+
+// TestReadPathList tests reading directories from a list
+func TestReadPathList(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "data")
+	absolute := filepath.Join(string(filepath.Separator), "other", "dir")
+	input := "a/b \r\n\n" + absolute + "\na/b \n" + "a/\n"
+
+	folders, err := walker.ReadPathList(strings.NewReader(input), root)
+	if err != nil {
+		t.Fatalf("ReadPathList() returned error: %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(root, "a", "b "), // Trailing space preserved, CRLF stripped
+		absolute,
+		filepath.Join(root, "a"),
+	}
+	if len(folders) != len(expected) {
+		t.Fatalf("Expected %d folders, got %+v", len(expected), folders)
+	}
+	for i, folder := range folders {
+		if folder.Path != expected[i] {
+			t.Errorf("Folder %d: expected path %q, got %q", i, expected[i], folder.Path)
+		}
+		if folder.Name != filepath.Base(expected[i]) || folder.Parent != filepath.Dir(expected[i]) {
+			t.Errorf("Folder %d: unexpected name or parent in %+v", i, folder)
+		}
+	}
+
+	// Deeper folders come first once listed folders are walked
+	walked, _ := walker.NewListWalker(folders).Walk(root)
+	if walked[0].Path != expected[0] {
+		t.Errorf("Expected the deepest folder first, got %q", walked[0].Path)
+	}
+}
+
+// TestOrderedWalker tests that top-down walks return parents first and keep the sibling order
+func TestOrderedWalker(t *testing.T) {
+	list := walker.NewListWalker([]interfaces.FolderInfo{
+		{Path: "/test/a", Name: "a", Depth: 1, Parent: "/test"},
+		{Path: "/test/a/d", Name: "d", Depth: 2, Parent: "/test/a"},
+		{Path: "/test/a/b/c", Name: "c", Depth: 3, Parent: "/test/a/b"},
+		{Path: "/test/a/b", Name: "b", Depth: 2, Parent: "/test/a"},
+	})
+
+	tests := map[string][]string{
+		walker.OrderBottomUp: {"/test/a/b/c", "/test/a/b", "/test/a/d", "/test/a"},
+		walker.OrderTopDown:  {"/test/a", "/test/a/b", "/test/a/d", "/test/a/b/c"},
+	}
+	for order, want := range tests {
+		folders, err := walker.NewOrderedWalker(list, order).Walk("/test")
+		if err != nil {
+			t.Fatalf("Walk(%s) returned error: %v", order, err)
+		}
+		var got []string
+		for _, folder := range folders {
+			got = append(got, folder.Path)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("Order %s returned %v, want %v", order, got, want)
+		}
+	}
+
+	if err := walker.ValidateOrder("sideways"); err == nil {
+		t.Error("ValidateOrder() expected error for an unknown order")
+	}
+}
@@ -0,0 +1,184 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Control is what the control socket of a running watcher can act on; nil fields are not offered
+type Control struct {
+	Quota  *Quota                   // Rename quota whose held directories can be approved or discarded
+	Reload func() ([]string, error) // Reloads the policy and describes what changed
+}
+
+// NewControlHandler serves control requests for a running watcher
+// GET /quota returns the QuotaStatus, POST /quota/approve and POST /quota/discard act on the held directories,
+// and POST /reload reloads the policy
+func NewControlHandler(control Control) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /quota", func(w http.ResponseWriter, r *http.Request) {
+		if quota := control.quota(w); quota != nil {
+			writeControlJSON(w, http.StatusOK, quota.Status())
+		}
+	})
+	mux.HandleFunc("POST /quota/approve", func(w http.ResponseWriter, r *http.Request) {
+		if quota := control.quota(w); quota != nil {
+			writeControlJSON(w, http.StatusOK, controlResult{Directories: quota.Approve()})
+		}
+	})
+	mux.HandleFunc("POST /quota/discard", func(w http.ResponseWriter, r *http.Request) {
+		if quota := control.quota(w); quota != nil {
+			writeControlJSON(w, http.StatusOK, controlResult{Directories: quota.Discard()})
+		}
+	})
+	mux.HandleFunc("POST /reload", func(w http.ResponseWriter, r *http.Request) {
+		if control.Reload == nil {
+			writeControlJSON(w, http.StatusNotFound, controlError{Error: "this watcher can't reload its policy"})
+			return
+		}
+		changes, err := control.Reload()
+		if err != nil {
+			writeControlJSON(w, http.StatusUnprocessableEntity, controlError{Error: err.Error()})
+			return
+		}
+		writeControlJSON(w, http.StatusOK, controlResult{Changes: changes})
+	})
+	return mux
+}
+
+// quota returns the quota, or answers that there is none
+func (c Control) quota(w http.ResponseWriter) *Quota {
+	if c.Quota == nil {
+		writeControlJSON(w, http.StatusNotFound, controlError{Error: "this watcher has no rename quota"})
+	}
+	return c.Quota
+}
+
+// controlResult is the response to approve, discard and reload requests
+type controlResult struct {
+	Directories int      `json:"directories,omitempty"` // Directories released or dropped
+	Changes     []string `json:"changes,omitempty"`     // Flags a reload changed
+}
+
+// controlError is the response to a request that failed
+type controlError struct {
+	Error string `json:"error"`
+}
+
+// writeControlJSON writes v as a JSON response with the given status
+func writeControlJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// ListenControl listens on a Unix domain socket that only the current user can connect to
+// A socket left behind by a watcher that crashed is replaced; one that is still in use is an error
+func ListenControl(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("control socket %s: file exists and is not a socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("control socket %s is in use by another watcher", path)
+		}
+		os.Remove(path)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("error creating control socket: %w", err)
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("error restricting control socket: %w", err)
+	}
+	return listener, nil
+}
+
+// ControlClient talks to a running watcher through its control socket
+type ControlClient struct {
+	path   string
+	client *http.Client
+}
+
+// NewControlClient creates a client for the control socket at path
+func NewControlClient(path string) *ControlClient {
+	return &ControlClient{
+		path: path,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+	}
+}
+
+// Status returns the quota of the watcher
+func (c *ControlClient) Status() (QuotaStatus, error) {
+	var status QuotaStatus
+	err := c.do(http.MethodGet, "/quota", &status)
+	return status, err
+}
+
+// Approve releases the held directories and returns how many there were
+func (c *ControlClient) Approve() (int, error) {
+	var result controlResult
+	err := c.do(http.MethodPost, "/quota/approve", &result)
+	return result.Directories, err
+}
+
+// Discard drops the held directories and returns how many there were
+func (c *ControlClient) Discard() (int, error) {
+	var result controlResult
+	err := c.do(http.MethodPost, "/quota/discard", &result)
+	return result.Directories, err
+}
+
+// Reload makes the watcher reload its policy and returns what changed
+func (c *ControlClient) Reload() ([]string, error) {
+	var result controlResult
+	err := c.do(http.MethodPost, "/reload", &result)
+	return result.Changes, err
+}
+
+// do sends a request to the watcher and decodes the JSON response into v
+func (c *ControlClient) do(method, path string, v any) error {
+	// The host is ignored by the Unix socket dialer but required in the URL
+	request, err := http.NewRequest(method, "http://watch"+path, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return fmt.Errorf("no watcher is listening on %s: %w", c.path, opErr.Err)
+		}
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		var failure controlError
+		if json.NewDecoder(response.Body).Decode(&failure) == nil && failure.Error != "" {
+			return errors.New(failure.Error)
+		}
+		return fmt.Errorf("watcher answered %s", strings.ToLower(http.StatusText(response.StatusCode)))
+	}
+	return json.NewDecoder(response.Body).Decode(v)
+}
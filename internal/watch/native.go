@@ -0,0 +1,125 @@
+// Package watch provides the event source backed by the operating system's change notifications.
+// This implementation uses inotify on Linux, ReadDirectoryChangesW on Windows, kqueue on BSD and macOS and FEN on illumos.
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// NativeSource implements the EventSource interface with the operating system's change notifications
+// This struct translates fsnotify events, which report moves as a Rename of the old name and a Create of the new one
+type NativeSource struct {
+	notify *fsnotify.Watcher
+	events chan Event
+	errors chan error
+	done   chan struct{}
+	close  sync.Once
+}
+
+// NewNativeSource starts an event source on the operating system's change notifications
+func NewNativeSource() (*NativeSource, error) {
+	notify, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("error starting file system notifications: %w", err)
+	}
+
+	ns := &NativeSource{
+		notify: notify,
+		events: make(chan Event),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+	}
+	go ns.translate()
+	return ns, nil
+}
+
+// Add starts reporting changes directly inside dir
+// This method implements the EventSource interface
+func (ns *NativeSource) Add(dir string) error {
+	return ns.notify.Add(dir)
+}
+
+// Remove stops reporting changes inside dir
+// This method implements the EventSource interface
+func (ns *NativeSource) Remove(dir string) error {
+	// Backends drop the watches of deleted and moved directories on their own and then fail
+	// to remove them again, each with its own error, so there is nothing worth reporting
+	_ = ns.notify.Remove(dir)
+	return nil
+}
+
+// Events returns the channel changes are delivered on
+// This method implements the EventSource interface
+func (ns *NativeSource) Events() <-chan Event {
+	return ns.events
+}
+
+// Errors returns the channel problems are delivered on
+// This method implements the EventSource interface
+func (ns *NativeSource) Errors() <-chan error {
+	return ns.errors
+}
+
+// Close stops the notifications and closes the channels
+// This method implements the EventSource interface
+func (ns *NativeSource) Close() error {
+	var err error
+	ns.close.Do(func() {
+		close(ns.done)
+		err = ns.notify.Close()
+	})
+	return err
+}
+
+// translate forwards fsnotify events and errors until the source is closed
+func (ns *NativeSource) translate() {
+	defer close(ns.events)
+	defer close(ns.errors)
+
+	for {
+		select {
+		case <-ns.done:
+			return
+		case event, ok := <-ns.notify.Events:
+			if !ok {
+				return
+			}
+			// A moved or deleted entry is gone under this name; the new name of a moved one arrives as a Create
+			if event.Has(fsnotify.Rename) || event.Has(fsnotify.Remove) {
+				ns.send(Event{Path: event.Name, Op: Remove})
+			}
+			if event.Has(fsnotify.Create) {
+				ns.send(Event{Path: event.Name, Op: Create})
+			}
+			if event.Has(fsnotify.Write) || event.Has(fsnotify.Chmod) {
+				ns.send(Event{Path: event.Name, Op: Write})
+			}
+		case err, ok := <-ns.notify.Errors:
+			if !ok {
+				return
+			}
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				err = fmt.Errorf("%w: %v", ErrOverflow, err)
+			}
+			select {
+			case ns.errors <- err:
+			case <-ns.done:
+			}
+		}
+	}
+}
+
+// send delivers an event unless the source is being closed
+func (ns *NativeSource) send(event Event) {
+	select {
+	case ns.events <- event:
+	case <-ns.done:
+	}
+}
+
+// Ensure NativeSource satisfies the EventSource contract
+var _ EventSource = (*NativeSource)(nil)
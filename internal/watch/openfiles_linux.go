@@ -0,0 +1,35 @@
+//go:build linux
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// openFilesDetectable reports whether open files can be detected on this platform
+const openFilesDetectable = true
+
+// hasOpenFiles reports whether any process has a file at or below dir open
+// Only processes whose /proc entries are readable are seen, i.e. all of them when running as root
+func hasOpenFiles(dir string) bool {
+	processes, err := os.ReadDir("/proc")
+	if err != nil {
+		return false
+	}
+
+	for _, process := range processes {
+		fdDir := filepath.Join("/proc", process.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue // Not a process, already gone, or not ours to inspect
+		}
+		for _, fd := range fds {
+			target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err == nil && within(dir, target) && target != dir {
+				return true
+			}
+		}
+	}
+	return false
+}
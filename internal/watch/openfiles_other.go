@@ -0,0 +1,11 @@
+//go:build !linux
+
+package watch
+
+// openFilesDetectable reports whether open files can be detected on this platform
+const openFilesDetectable = false
+
+// hasOpenFiles reports that open files can't be detected on this platform
+func hasOpenFiles(dir string) bool {
+	return false
+}
@@ -0,0 +1,213 @@
+// Package watch provides an event source that polls directory listings.
+// This implementation works on every file system, including clustered and network file systems without change notifications.
+package watch
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// PollingSource implements the EventSource interface by comparing directory listings at a fixed interval
+// This struct only reports subdirectories, never writes; every poll reads every watched directory
+// Without Write events, settling relies on the modification times the watcher checks before sanitizing
+type PollingSource struct {
+	fileSystem interfaces.FileSystem
+	interval   time.Duration
+
+	mu       sync.Mutex
+	children map[string]map[string]bool // Subdirectory names of every watched directory at the last poll
+
+	events chan Event
+	errors chan error
+	done   chan struct{}
+	close  sync.Once
+}
+
+// NewPollingSource starts an event source that lists the watched directories of fileSystem every interval
+func NewPollingSource(fileSystem interfaces.FileSystem, interval time.Duration) *PollingSource {
+	ps := &PollingSource{
+		fileSystem: fileSystem,
+		interval:   interval,
+		children:   make(map[string]map[string]bool),
+		events:     make(chan Event),
+		errors:     make(chan error),
+		done:       make(chan struct{}),
+	}
+	go ps.run()
+	return ps
+}
+
+// Add lists dir now, so only subdirectories created afterwards are reported
+// This method implements the EventSource interface
+func (ps *PollingSource) Add(dir string) error {
+	names, err := ps.list(dir)
+	if err != nil {
+		return err
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.children[dir] = names
+	return nil
+}
+
+// Remove stops listing dir
+// This method implements the EventSource interface
+func (ps *PollingSource) Remove(dir string) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	delete(ps.children, dir)
+	return nil
+}
+
+// Events returns the channel changes are delivered on
+// This method implements the EventSource interface
+func (ps *PollingSource) Events() <-chan Event {
+	return ps.events
+}
+
+// Errors returns the channel problems are delivered on
+// This method implements the EventSource interface
+func (ps *PollingSource) Errors() <-chan error {
+	return ps.errors
+}
+
+// Close stops polling and closes the channels
+// This method implements the EventSource interface
+func (ps *PollingSource) Close() error {
+	ps.close.Do(func() {
+		close(ps.done)
+	})
+	return nil
+}
+
+// run polls until the source is closed
+func (ps *PollingSource) run() {
+	defer close(ps.events)
+	defer close(ps.errors)
+
+	ticker := time.NewTicker(ps.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ps.done:
+			return
+		case <-ticker.C:
+			if !ps.poll() {
+				return
+			}
+		}
+	}
+}
+
+// poll compares every watched directory with its last listing and reports the differences
+// It returns false once the source is closed
+func (ps *PollingSource) poll() bool {
+	ps.mu.Lock()
+	dirs := make([]string, 0, len(ps.children))
+	for dir := range ps.children {
+		dirs = append(dirs, dir)
+	}
+	ps.mu.Unlock()
+	sort.Strings(dirs) // Parents before children, so new trees are reported top-down
+
+	for _, dir := range dirs {
+		names, err := ps.list(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			// The parent's listing reports the directory as removed
+			continue
+		}
+		if err != nil {
+			if !ps.deliver(nil, err) {
+				return false
+			}
+			continue
+		}
+
+		// The events are sent without holding the lock, because the watcher calls Add while handling them
+		if !ps.deliver(ps.diff(dir, names), nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// diff replaces the last listing of dir with names and returns the changes between them
+func (ps *PollingSource) diff(dir string, names map[string]bool) []Event {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	previous, watched := ps.children[dir]
+	if !watched {
+		return nil // Removed while it was being listed
+	}
+	ps.children[dir] = names
+
+	var events []Event
+	for _, name := range sortedNames(previous) {
+		if !names[name] {
+			events = append(events, Event{Path: filepath.Join(dir, name), Op: Remove})
+		}
+	}
+	for _, name := range sortedNames(names) {
+		if !previous[name] {
+			events = append(events, Event{Path: filepath.Join(dir, name), Op: Create})
+		}
+	}
+	return events
+}
+
+// deliver sends events and an optional error, and returns false once the source is closed
+func (ps *PollingSource) deliver(events []Event, err error) bool {
+	for _, event := range events {
+		select {
+		case ps.events <- event:
+		case <-ps.done:
+			return false
+		}
+	}
+	if err != nil {
+		select {
+		case ps.errors <- err:
+		case <-ps.done:
+			return false
+		}
+	}
+	return true
+}
+
+// list returns the names of the subdirectories of dir; symbolic links are not followed
+func (ps *PollingSource) list(dir string) (map[string]bool, error) {
+	entries, err := ps.fileSystem.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names[entry.Name()] = true
+		}
+	}
+	return names, nil
+}
+
+// sortedNames returns the names of a listing in a stable order
+func sortedNames(names map[string]bool) []string {
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// Ensure PollingSource satisfies the EventSource contract
+var _ EventSource = (*PollingSource)(nil)
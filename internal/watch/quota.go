@@ -0,0 +1,195 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// ErrQuotaExceeded is wrapped by the quota's processor once a rename would exceed the cap
+// It also wraps interfaces.ErrHalted, so the service stops the run instead of failing every remaining folder
+var ErrQuotaExceeded = errors.New("rename quota exceeded")
+
+// Quota caps the renames watch mode performs per time window
+// Once a rename is refused, renaming stays halted until a human approves or discards the held directories
+type Quota struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	renames  []time.Time // When the renames within the window happened, oldest first
+	exceeded bool        // Set by a refused rename; only Approve and Discard clear it
+	exempt   bool        // Set while approved directories are sanitized
+	held     []string    // Directories waiting for approval, in the order they were held
+	approved []string    // Approved directories the watcher hasn't sanitized yet
+	release  chan struct{}
+}
+
+// QuotaStatus is a snapshot of a quota for the control API
+type QuotaStatus struct {
+	Limit    int           `json:"limit"`
+	Window   time.Duration `json:"window"`
+	Used     int           `json:"used"` // Renames within the current window
+	Exceeded bool          `json:"exceeded"`
+	Held     []string      `json:"held"`
+}
+
+// NewQuota allows limit renames within every window
+func NewQuota(limit int, window time.Duration) *Quota {
+	return &Quota{
+		limit:   limit,
+		window:  window,
+		now:     time.Now,
+		release: make(chan struct{}, 1),
+	}
+}
+
+// Processor wraps next so that renames beyond the quota are refused with ErrQuotaExceeded
+// Folders whose name doesn't change pass through without counting
+func (q *Quota) Processor(next interfaces.FolderProcessor) interfaces.FolderProcessor {
+	return &quotaProcessor{next: next, quota: q}
+}
+
+// quotaProcessor counts every rename against the quota before handing it on
+type quotaProcessor struct {
+	next  interfaces.FolderProcessor
+	quota *Quota
+}
+
+// ProcessRename refuses the rename once the quota is spent
+// This method implements the FolderProcessor interface
+func (p *quotaProcessor) ProcessRename(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+	if newName != folder.Name && !p.quota.allow() {
+		return nil, fmt.Errorf("%w (%d renames within %s), waiting for approval: %w", ErrQuotaExceeded, p.quota.limit, p.quota.window, interfaces.ErrHalted)
+	}
+	return p.next.ProcessRename(folder, newName, dryRun)
+}
+
+// allow records a rename if the window has room for it, and otherwise halts renaming
+func (q *Quota) allow() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.exempt {
+		return true
+	}
+	if q.exceeded {
+		return false
+	}
+
+	q.expire()
+	if len(q.renames) >= q.limit {
+		q.exceeded = true
+		return false
+	}
+	q.renames = append(q.renames, q.now())
+	return true
+}
+
+// expire forgets the renames that dropped out of the window; the caller holds the lock
+func (q *Quota) expire() {
+	cutoff := q.now().Add(-q.window)
+	kept := 0
+	for kept < len(q.renames) && !q.renames[kept].After(cutoff) {
+		kept++
+	}
+	q.renames = q.renames[kept:]
+}
+
+// Exceeded reports whether renaming is halted until approval
+func (q *Quota) Exceeded() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.exceeded
+}
+
+// hold remembers a directory for approval; a directory already held is not added twice
+func (q *Quota) hold(dir string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, held := range q.held {
+		if held == dir {
+			return
+		}
+	}
+	q.held = append(q.held, dir)
+}
+
+// Approve hands the held directories back to the watcher, which sanitizes them without counting them
+// Renaming resumes with an empty window; it returns the number of released directories
+func (q *Quota) Approve() int {
+	q.mu.Lock()
+	released := len(q.held)
+	q.approved = append(q.approved, q.held...)
+	q.reset()
+	q.mu.Unlock()
+
+	select {
+	case q.release <- struct{}{}:
+	default: // The watcher has a release pending already and picks these up with it
+	}
+	return released
+}
+
+// Discard drops the held directories without sanitizing them, so a regular run can deal with them later
+// Renaming resumes with an empty window; it returns the number of dropped directories
+func (q *Quota) Discard() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	dropped := len(q.held)
+	q.reset()
+	return dropped
+}
+
+// reset clears the window and the held directories; the caller holds the lock
+func (q *Quota) reset() {
+	q.renames = nil
+	q.exceeded = false
+	q.held = nil
+}
+
+// Status returns a snapshot of the quota
+func (q *Quota) Status() QuotaStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.expire()
+	return QuotaStatus{
+		Limit:    q.limit,
+		Window:   q.window,
+		Used:     len(q.renames),
+		Exceeded: q.exceeded,
+		Held:     append([]string{}, q.held...),
+	}
+}
+
+// released is signaled after Approve
+func (q *Quota) released() <-chan struct{} {
+	return q.release
+}
+
+// takeApproved returns the approved directories that still have to be sanitized
+func (q *Quota) takeApproved() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	approved := q.approved
+	q.approved = nil
+	return approved
+}
+
+// exemptWhile runs fn with the quota lifted, for directories a human approved
+func (q *Quota) exemptWhile(fn func()) {
+	q.mu.Lock()
+	q.exempt = true
+	q.mu.Unlock()
+
+	defer func() {
+		q.mu.Lock()
+		q.exempt = false
+		q.mu.Unlock()
+	}()
+	fn()
+}
@@ -0,0 +1,173 @@
+// Package watch provides settle detection, so directories that are still being written are not renamed mid-transfer.
+// This implementation keeps new directories pending until their tree has been quiet for the settle time.
+package watch
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pendingDir is a new directory waiting for its tree to settle
+type pendingDir struct {
+	deadline time.Time // When the tree is checked again
+	newest   time.Time // Newest modification time in the tree at the last check
+}
+
+// WithSettle makes the watcher wait until a new directory tree saw no changes for settle before sanitizing it
+// Every event inside the tree restarts the wait; 0 sanitizes new directories immediately
+func WithSettle(settle time.Duration) Option {
+	return func(w *Watcher) {
+		w.settle = settle
+	}
+}
+
+// WithOpenFileCheck additionally waits while a process has a file below a new directory open
+// This is only detectable on some platforms (see OpenFilesDetectable) and is ignored elsewhere
+func WithOpenFileCheck(check bool) Option {
+	return func(w *Watcher) {
+		w.checkOpenFiles = check
+	}
+}
+
+// OpenFilesDetectable reports whether open files can be detected on this platform
+func OpenFilesDetectable() bool {
+	return openFilesDetectable
+}
+
+// schedule adds a new directory to the pending set, or reports activity in a pending tree it belongs to
+func (w *Watcher) schedule(dir string) {
+	if w.touch(dir) {
+		return // Sanitizing the pending tree covers the new directory
+	}
+	w.pending[dir] = &pendingDir{
+		deadline: w.now().Add(w.settle),
+		newest:   w.newestChange(dir),
+	}
+	w.resetTimer()
+}
+
+// touch restarts the wait of every pending tree that contains path and reports whether there was one
+func (w *Watcher) touch(path string) bool {
+	touched := false
+	for dir, pending := range w.pending {
+		if within(dir, path) {
+			pending.deadline = w.now().Add(w.settle)
+			touched = true
+		}
+	}
+	if touched {
+		w.resetTimer()
+	}
+	return touched
+}
+
+// drop forgets pending trees at or below path, e.g. because they were moved away before they settled
+func (w *Watcher) drop(path string) {
+	for dir := range w.pending {
+		if within(path, dir) {
+			delete(w.pending, dir)
+		}
+	}
+	w.resetTimer()
+}
+
+// settleDue sanitizes every pending tree whose wait is over and that is still quiet
+func (w *Watcher) settleDue() {
+	now := w.now()
+	due := make([]string, 0, len(w.pending))
+	for dir, pending := range w.pending {
+		if !pending.deadline.After(now) {
+			due = append(due, dir)
+		}
+	}
+	sort.Strings(due)
+
+	for _, dir := range due {
+		pending := w.pending[dir]
+		if _, err := w.fileSystem.Lstat(dir); err != nil {
+			delete(w.pending, dir) // Gone again; a moved directory is scheduled under its new name
+			continue
+		}
+
+		// Modification times catch writes the event source doesn't report, e.g. when polling
+		if newest := w.newestChange(dir); !newest.Equal(pending.newest) {
+			pending.newest = newest
+			pending.deadline = now.Add(w.settle)
+			continue
+		}
+		if w.checkOpenFiles && hasOpenFiles(dir) {
+			pending.deadline = now.Add(w.settle)
+			continue
+		}
+
+		delete(w.pending, dir)
+		w.sanitizeDir(dir)
+	}
+	w.resetTimer()
+}
+
+// resetTimer points the settle timer at the earliest pending deadline
+func (w *Watcher) resetTimer() {
+	var earliest time.Time
+	for _, pending := range w.pending {
+		if earliest.IsZero() || pending.deadline.Before(earliest) {
+			earliest = pending.deadline
+		}
+	}
+
+	if earliest.IsZero() {
+		if w.timer != nil {
+			w.timer.Stop()
+		}
+		return
+	}
+
+	wait := max(earliest.Sub(w.now()), 0)
+	if w.timer == nil {
+		w.timer = time.NewTimer(wait)
+	} else {
+		w.timer.Reset(wait)
+	}
+}
+
+// timerC returns the settle timer's channel, or nil (which blocks forever) when nothing is pending
+func (w *Watcher) timerC() <-chan time.Time {
+	if w.timer == nil {
+		return nil
+	}
+	return w.timer.C
+}
+
+// newestChange returns the newest modification time of dir and everything below it
+// Directory modification times change when entries are added, removed or renamed
+func (w *Watcher) newestChange(dir string) time.Time {
+	info, err := w.fileSystem.Lstat(dir)
+	if err != nil {
+		return time.Time{}
+	}
+	newest := info.ModTime()
+	if !info.IsDir() {
+		return newest
+	}
+
+	entries, err := w.fileSystem.ReadDir(dir)
+	if err != nil {
+		return newest
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && w.skip(entry.Name()) {
+			continue
+		}
+		if changed := w.newestChange(filepath.Join(dir, entry.Name())); changed.After(newest) {
+			newest = changed
+		}
+	}
+	return newest
+}
+
+// within reports whether path is dir itself or lies below it
+func within(dir, path string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
@@ -0,0 +1,42 @@
+// Package watch defines the event sources watch mode can be driven by.
+// This keeps the watcher independent of how a file system reports changes, so shares without notifications can be polled.
+package watch
+
+import (
+	"errors"
+)
+
+// ErrOverflow is wrapped by event sources that lost events, e.g. because the kernel queue overflowed
+// The watcher then checks the whole tree, since some new directories may have gone unnoticed
+var ErrOverflow = errors.New("file system events were lost")
+
+// Op is the kind of change an Event reports
+type Op int
+
+// Supported changes
+const (
+	Create Op = iota + 1 // A file or directory appeared, including the new name of a moved one
+	Remove               // A file or directory disappeared, including the old name of a moved one
+	Write                // A file was written to or its attributes changed; sources may not report these
+)
+
+// Event describes a change directly inside a watched directory
+type Event struct {
+	Path string // Full path of the entry that changed
+	Op   Op
+}
+
+// EventSource defines the contract for reporting changes in a set of directories
+// This interface is not recursive: the watcher adds every directory of the tree individually
+type EventSource interface {
+	// Add starts reporting changes directly inside dir
+	Add(dir string) error
+	// Remove stops reporting changes inside dir; removing a directory that is gone already is not an error
+	Remove(dir string) error
+	// Events returns the channel changes are delivered on; it is closed by Close
+	Events() <-chan Event
+	// Errors returns the channel problems are delivered on; it is closed by Close
+	Errors() <-chan error
+	// Close stops the source and releases its resources
+	Close() error
+}
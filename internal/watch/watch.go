@@ -0,0 +1,289 @@
+// Package watch sanitizes directories as soon as they are created below a watched root.
+// This implementation adds every directory of the tree to an EventSource and hands new directories to a Sanitizer.
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Sanitizer sanitizes the directory tree rooted at dir, including dir itself unless it is the watched root
+type Sanitizer func(dir string) error
+
+// Watcher monitors a directory tree and sanitizes every new directory
+// This struct is not safe for concurrent use; Run handles one event at a time
+type Watcher struct {
+	root       string
+	sanitize   Sanitizer
+	fileSystem interfaces.FileSystem
+	skip       func(name string) bool // Directories that are neither watched nor descended into
+	onError    func(err error)
+	onReady    func(directories int)
+	source     EventSource // nil = the operating system's notifications, opened by Run
+	watched    map[string]bool
+
+	settle         time.Duration          // How long a new tree must be quiet before it is sanitized
+	checkOpenFiles bool                   // Also wait while files in a new tree are open
+	pending        map[string]*pendingDir // New directories waiting to settle
+	timer          *time.Timer            // Fires at the earliest pending deadline
+	now            func() time.Time
+
+	quota *Quota // Caps the renames per time window (nil = unlimited)
+}
+
+// Option configures optional Watcher behavior
+type Option func(*Watcher)
+
+// WithFileSystem makes the watcher inspect new paths through the given backend
+func WithFileSystem(fileSystem interfaces.FileSystem) Option {
+	return func(w *Watcher) {
+		w.fileSystem = fileSystem
+	}
+}
+
+// WithSkip leaves directories whose name matches skip unwatched, e.g. protected tool directories
+// Nothing below them is sanitized anyway, and watching them would waste the kernel's watch limit
+func WithSkip(skip func(name string) bool) Option {
+	return func(w *Watcher) {
+		w.skip = skip
+	}
+}
+
+// WithEventSource makes the watcher use source instead of the operating system's notifications
+// Run closes the source when it returns
+func WithEventSource(source EventSource) Option {
+	return func(w *Watcher) {
+		w.source = source
+	}
+}
+
+// WithErrorHandler receives the problems that don't stop watching, e.g. a directory that couldn't be watched
+func WithErrorHandler(onError func(err error)) Option {
+	return func(w *Watcher) {
+		w.onError = onError
+	}
+}
+
+// WithReadyHandler is called once the initial watches are in place with the number of watched directories
+func WithReadyHandler(onReady func(directories int)) Option {
+	return func(w *Watcher) {
+		w.onReady = onReady
+	}
+}
+
+// WithQuota holds new directories for approval once quota refused a rename
+// The quota's Processor must wrap the processor the sanitizer renames with
+func WithQuota(quota *Quota) Option {
+	return func(w *Watcher) {
+		w.quota = quota
+	}
+}
+
+// NewWatcher creates a watcher for the tree at root that hands new directories to sanitize
+func NewWatcher(root string, sanitize Sanitizer, options ...Option) *Watcher {
+	w := &Watcher{
+		root:       root,
+		sanitize:   sanitize,
+		fileSystem: filesystem.NewOSFileSystem(),
+		skip:       func(string) bool { return false },
+		onError:    func(error) {},
+		onReady:    func(int) {},
+		now:        time.Now,
+	}
+
+	for _, option := range options {
+		option(w)
+	}
+
+	return w
+}
+
+// Run watches the tree until stop is closed or the event source fails
+// Directories that already exist are watched but not sanitized; run a regular pass for those first
+func (w *Watcher) Run(stop <-chan struct{}) error {
+	if w.source == nil {
+		source, err := NewNativeSource()
+		if err != nil {
+			return err
+		}
+		w.source = source
+	}
+	defer w.source.Close()
+
+	w.watched = make(map[string]bool)
+	w.pending = make(map[string]*pendingDir)
+	defer func() {
+		if w.timer != nil {
+			w.timer.Stop()
+		}
+	}()
+	if err := w.source.Add(w.root); err != nil {
+		return fmt.Errorf("error watching %s: %w", w.root, err)
+	}
+	w.watched[w.root] = true
+	w.addTree(w.root)
+	w.onReady(len(w.watched))
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-w.timerC():
+			w.settleDue()
+		case <-w.quotaReleased():
+			w.sanitizeApproved()
+		case event, ok := <-w.source.Events():
+			if !ok {
+				return nil
+			}
+			w.handleEvent(event)
+		case err, ok := <-w.source.Errors():
+			if !ok {
+				return nil
+			}
+			if !errors.Is(err, ErrOverflow) {
+				w.onError(err)
+				continue
+			}
+			// Events were lost, so some new directories may have gone unnoticed
+			w.onError(fmt.Errorf("checking the whole tree: %w", err))
+			w.addTree(w.root)
+			if w.settle > 0 {
+				w.schedule(w.root) // Transfers may be in progress anywhere, so the whole tree has to settle
+			} else {
+				w.sanitizeDir(w.root)
+			}
+		}
+	}
+}
+
+// handleEvent sanitizes a new directory and keeps the watches in line with the tree
+func (w *Watcher) handleEvent(event Event) {
+	switch event.Op {
+	case Remove:
+		// A directory that was moved away or deleted takes its watches with it; a moved one reappears as a Create
+		w.forget(event.Path)
+		w.drop(event.Path)
+		w.touch(event.Path)
+		return
+	case Write:
+		w.touch(event.Path)
+		return
+	}
+
+	info, err := w.fileSystem.Lstat(event.Path)
+	if err != nil || !info.IsDir() || w.skip(info.Name()) {
+		w.touch(event.Path) // A new file still counts as activity in a pending tree
+		return
+	}
+
+	// Watch first, so nothing created while the tree is sanitized goes unnoticed
+	// If sanitizing renames the directory, its Remove event drops these watches again
+	if w.add(event.Path) {
+		w.addTree(event.Path)
+	}
+
+	if w.settle > 0 {
+		w.schedule(event.Path)
+		return
+	}
+	w.sanitizeDir(event.Path)
+}
+
+// sanitizeDir hands a directory tree to the sanitizer and reports a failure
+// While the quota is exceeded, the tree is held for approval instead
+func (w *Watcher) sanitizeDir(dir string) {
+	if w.quota != nil && w.quota.Exceeded() {
+		w.holdDir(dir)
+		return
+	}
+
+	err := w.sanitize(dir)
+	switch {
+	case err != nil && w.quota != nil && w.quota.Exceeded():
+		// The tree hit the quota partway; sanitizing it again after approval finishes it
+		w.holdDir(dir)
+	case err != nil:
+		w.onError(fmt.Errorf("error sanitizing %s: %w", dir, err))
+	}
+}
+
+// holdDir remembers a directory until a human approves or discards the held renames
+func (w *Watcher) holdDir(dir string) {
+	w.quota.hold(dir)
+	w.onError(fmt.Errorf("holding %s until the renames are approved: %w", dir, ErrQuotaExceeded))
+}
+
+// quotaReleased returns the channel signaled by Quota.Approve, or nil without a quota
+func (w *Watcher) quotaReleased() <-chan struct{} {
+	if w.quota == nil {
+		return nil
+	}
+	return w.quota.released()
+}
+
+// sanitizeApproved sanitizes the directories released by Quota.Approve without counting their renames
+func (w *Watcher) sanitizeApproved() {
+	w.quota.exemptWhile(func() {
+		for _, dir := range w.quota.takeApproved() {
+			if _, err := w.fileSystem.Lstat(dir); err != nil {
+				continue // Moved away or deleted while it was held
+			}
+			if err := w.sanitize(dir); err != nil {
+				w.onError(fmt.Errorf("error sanitizing %s: %w", dir, err))
+			}
+		}
+	})
+}
+
+// addTree watches every directory below dir
+func (w *Watcher) addTree(dir string) {
+	entries, err := w.fileSystem.ReadDir(dir)
+	if err != nil {
+		w.onError(fmt.Errorf("error reading %s: %w", dir, err))
+		return
+	}
+
+	for _, entry := range entries {
+		// DirEntry.IsDir is false for symbolic links, so linked trees are never followed
+		if !entry.IsDir() || w.skip(entry.Name()) {
+			continue
+		}
+		child := filepath.Join(dir, entry.Name())
+		if w.add(child) {
+			w.addTree(child)
+		}
+	}
+}
+
+// add watches a single directory and reports whether it is watched now
+func (w *Watcher) add(dir string) bool {
+	if w.watched[dir] {
+		return true
+	}
+	if err := w.source.Add(dir); err != nil {
+		w.onError(fmt.Errorf("error watching %s: %w", dir, err))
+		return false
+	}
+	w.watched[dir] = true
+	return true
+}
+
+// forget removes the watches on path and everything below it
+func (w *Watcher) forget(path string) {
+	prefix := path + string(filepath.Separator)
+	for dir := range w.watched {
+		if dir == path || strings.HasPrefix(dir, prefix) {
+			if err := w.source.Remove(dir); err != nil {
+				w.onError(fmt.Errorf("error unwatching %s: %w", dir, err))
+			}
+			delete(w.watched, dir)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+// Package watch_test provides tests for the watch package.
+// This test suite ensures new directories are handed to the sanitizer and renamed trees stay watched, with every event source.
+package watch_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/watch"
+)
+
+// recorder is a sanitizer that replaces exclamation marks (valid on every platform) in new directory names
+// It remembers every directory it was handed
+type recorder struct {
+	mu        sync.Mutex
+	dirs      []string
+	processor interfaces.FolderProcessor
+}
+
+// sanitize renames every directory of the tree at dir whose name contains an exclamation mark, deepest first
+func (r *recorder) sanitize(dir string) error {
+	r.mu.Lock()
+	r.dirs = append(r.dirs, dir)
+	r.mu.Unlock()
+
+	return renameTree(r.processor, dir)
+}
+
+// renameTree renames the directories below dir and then dir itself through processor
+func renameTree(processor interfaces.FolderProcessor, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if err := renameTree(processor, filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	folder := interfaces.FolderInfo{Path: dir, Name: filepath.Base(dir), Parent: filepath.Dir(dir)}
+	_, err = processor.ProcessRename(folder, strings.ReplaceAll(folder.Name, "!", "_"), false)
+	return err
+}
+
+// renamer is a minimal FolderProcessor that renames on disk
+type renamer struct{}
+
+// ProcessRename renames the folder unless the name is unchanged
+func (renamer) ProcessRename(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+	if newName == folder.Name {
+		return &interfaces.RenameResult{OldPath: folder.Path, NewPath: folder.Path, Success: true}, nil
+	}
+	newPath := filepath.Join(folder.Parent, newName)
+	return &interfaces.RenameResult{OldPath: folder.Path, NewPath: newPath, Success: true, WasRenamed: true}, os.Rename(folder.Path, newPath)
+}
+
+// saw reports whether dir was handed to the sanitizer
+func (r *recorder) saw(dir string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, seen := range r.dirs {
+		if seen == dir {
+			return true
+		}
+	}
+	return false
+}
+
+// sources returns a factory for every event source, so each behavior is tested against all of them
+func sources() map[string]func(t *testing.T) watch.EventSource {
+	return map[string]func(t *testing.T) watch.EventSource{
+		"native": func(t *testing.T) watch.EventSource {
+			source, err := watch.NewNativeSource()
+			if err != nil {
+				t.Fatalf("NewNativeSource() returned error: %v", err)
+			}
+			return source
+		},
+		"polling": func(t *testing.T) watch.EventSource {
+			return watch.NewPollingSource(filesystem.NewOSFileSystem(), 10*time.Millisecond)
+		},
+	}
+}
+
+// startWatcher runs a watcher on root until the test ends and waits until it is ready
+func startWatcher(t *testing.T, root string, options ...watch.Option) *recorder {
+	t.Helper()
+	return runWatcher(t, root, &recorder{processor: renamer{}}, options...)
+}
+
+// runWatcher runs a watcher with the given recorder on root until the test ends and waits until it is ready
+func runWatcher(t *testing.T, root string, rec *recorder, options ...watch.Option) *recorder {
+	t.Helper()
+
+	ready := make(chan struct{})
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+
+	options = append(options, watch.WithReadyHandler(func(int) { close(ready) }))
+	watcher := watch.NewWatcher(root, rec.sanitize, options...)
+	go func() { done <- watcher.Run(stop) }()
+	t.Cleanup(func() {
+		close(stop)
+		if err := <-done; err != nil {
+			t.Errorf("Run() returned error: %v", err)
+		}
+	})
+
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatalf("Run() stopped early: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Watcher did not become ready")
+	}
+	return rec
+}
+
+// eventually waits until condition holds or fails the test
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// exists reports whether path exists
+func exists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// TestWatcher_SanitizesNewDirectories tests that new directories are handed over and renamed ones stay watched
+func TestWatcher_SanitizesNewDirectories(t *testing.T) {
+	for name, newSource := range sources() {
+		t.Run(name, func(t *testing.T) {
+			testSanitizesNewDirectories(t, watch.WithEventSource(newSource(t)))
+		})
+	}
+}
+
+// testSanitizesNewDirectories runs TestWatcher_SanitizesNewDirectories with one event source
+func testSanitizesNewDirectories(t *testing.T, source watch.Option) {
+	root := t.TempDir()
+	existing := filepath.Join(root, "existing")
+	if err := os.Mkdir(existing, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	rec := startWatcher(t, root, source)
+
+	if err := os.Mkdir(filepath.Join(existing, "a!b"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "a!b to be renamed", func() bool { return exists(filepath.Join(existing, "a_b")) })
+
+	// The renamed directory is watched under its new name
+	if err := os.Mkdir(filepath.Join(existing, "a_b", "c!d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "c!d to be renamed", func() bool { return exists(filepath.Join(existing, "a_b", "c_d")) })
+
+	if rec.saw(existing) || rec.saw(root) {
+		t.Error("Expected directories that existed before watching to be left alone")
+	}
+}
+
+// TestWatcher_Skip tests that skipped directories are neither watched nor handed over
+func TestWatcher_Skip(t *testing.T) {
+	for name, newSource := range sources() {
+		t.Run(name, func(t *testing.T) {
+			testSkip(t, watch.WithEventSource(newSource(t)))
+		})
+	}
+}
+
+// testSkip runs TestWatcher_Skip with one event source
+func testSkip(t *testing.T, source watch.Option) {
+	root := t.TempDir()
+	modules := filepath.Join(root, "node_modules")
+	if err := os.Mkdir(modules, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	rec := startWatcher(t, root, source, watch.WithSkip(func(name string) bool { return name == "node_modules" }))
+
+	if err := os.Mkdir(filepath.Join(modules, "x!y"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A directory created afterwards proves the event for x!y would have arrived by now
+	if err := os.Mkdir(filepath.Join(root, "marker"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "marker to be handed over", func() bool { return rec.saw(filepath.Join(root, "marker")) })
+
+	if rec.saw(filepath.Join(modules, "x!y")) {
+		t.Error("Expected nothing below a skipped directory to be handed over")
+	}
+}
+
+// TestPollingSource tests that polling reports created, removed and moved subdirectories
+func TestPollingSource(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/tree/old")
+	source := watch.NewPollingSource(memory, time.Millisecond)
+	defer source.Close()
+
+	if err := source.Add("/tree"); err != nil {
+		t.Fatalf("Add() returned error: %v", err)
+	}
+	memory.MkdirAll("/tree/new")
+	memory.WriteFile("/tree/file.txt", nil) // Files are not reported
+	if err := memory.Rename("/tree/old", "/tree/moved"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []watch.Event{
+		{Path: "/tree/old", Op: watch.Remove},
+		{Path: "/tree/moved", Op: watch.Create},
+		{Path: "/tree/new", Op: watch.Create},
+	}
+	for _, expected := range want {
+		select {
+		case event := <-source.Events():
+			if event != expected {
+				t.Errorf("Expected %+v, got %+v", expected, event)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for %+v", expected)
+		}
+	}
+}
+
+// TestWatcher_Settle tests that a directory is only sanitized once nothing inside it changed for the settle time
+func TestWatcher_Settle(t *testing.T) {
+	for name, newSource := range sources() {
+		t.Run(name, func(t *testing.T) {
+			root := t.TempDir()
+			startWatcher(t, root, watch.WithEventSource(newSource(t)), watch.WithSettle(300*time.Millisecond))
+
+			incoming := filepath.Join(root, "in!coming")
+			if err := os.Mkdir(incoming, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			// A transfer that keeps writing for longer than the settle time
+			file := filepath.Join(incoming, "data.bin")
+			for i := 0; i < 8; i++ {
+				if err := os.WriteFile(file, make([]byte, i+1), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+			if !exists(incoming) {
+				t.Fatal("Expected the directory to stay untouched while it is being written")
+			}
+
+			eventually(t, "in!coming to be renamed once quiet", func() bool { return exists(filepath.Join(root, "in_coming")) })
+		})
+	}
+}
+
+// TestWatcher_OpenFiles tests that a directory with an open file is only sanitized once the file is closed
+func TestWatcher_OpenFiles(t *testing.T) {
+	if !watch.OpenFilesDetectable() {
+		t.Skip("Open files can't be detected on this platform")
+	}
+
+	root := t.TempDir()
+	startWatcher(t, root, watch.WithSettle(100*time.Millisecond), watch.WithOpenFileCheck(true))
+
+	incoming := filepath.Join(root, "in!coming")
+	if err := os.Mkdir(incoming, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(filepath.Join(incoming, "data.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(500 * time.Millisecond)
+	if !exists(incoming) {
+		t.Fatal("Expected the directory to stay untouched while a file in it is open")
+	}
+
+	file.Close()
+	eventually(t, "in!coming to be renamed once the file is closed", func() bool { return exists(filepath.Join(root, "in_coming")) })
+}
+
+// TestQuota tests that renames beyond the quota halt renaming until approval
+func TestQuota(t *testing.T) {
+	quota := watch.NewQuota(2, 50*time.Millisecond)
+	processor := quota.Processor(&countingProcessor{})
+	rename := func(name string) error {
+		_, err := processor.ProcessRename(interfaces.FolderInfo{Path: "/data/" + name, Name: name, Parent: "/data"}, name+"_", false)
+		return err
+	}
+
+	if rename("a") != nil || rename("b") != nil {
+		t.Fatal("Expected renames within the quota to pass")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if rename("c") != nil || rename("d") != nil {
+		t.Fatal("Expected renames to pass again once the window moved on")
+	}
+
+	err := rename("e")
+	if !errors.Is(err, watch.ErrQuotaExceeded) || !errors.Is(err, interfaces.ErrHalted) {
+		t.Fatalf("Expected the rename to exceed the quota, got %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if rename("f") == nil || !quota.Exceeded() {
+		t.Error("Expected renaming to stay halted until approval")
+	}
+
+	// Names that don't change never count
+	if _, err := processor.ProcessRename(interfaces.FolderInfo{Path: "/data/ok", Name: "ok"}, "ok", false); err != nil {
+		t.Errorf("Expected compliant folders to pass through, got %v", err)
+	}
+
+	quota.Discard()
+	if quota.Exceeded() || rename("g") != nil {
+		t.Error("Expected renaming to resume after discarding")
+	}
+}
+
+// countingProcessor accepts every rename without touching the file system
+type countingProcessor struct{}
+
+// ProcessRename reports a successful rename
+func (*countingProcessor) ProcessRename(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+	return &interfaces.RenameResult{OldPath: folder.Path, Success: true, WasRenamed: newName != folder.Name}, nil
+}
+
+// TestWatcher_Quota tests that directories beyond the quota are held until approved through the control socket
+func TestWatcher_Quota(t *testing.T) {
+	root := t.TempDir()
+	quota := watch.NewQuota(2, time.Hour)
+	runWatcher(t, root, &recorder{processor: quota.Processor(renamer{})}, watch.WithQuota(quota))
+
+	listener, err := watch.ListenControl(filepath.Join(t.TempDir(), "control.sock"))
+	if err != nil {
+		t.Fatalf("ListenControl() returned error: %v", err)
+	}
+	server := &http.Server{Handler: watch.NewControlHandler(watch.Control{Quota: quota})}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	client := watch.NewControlClient(listener.Addr().String())
+
+	for _, name := range []string{"one!", "two!"} {
+		if err := os.Mkdir(filepath.Join(root, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		renamed := filepath.Join(root, strings.ReplaceAll(name, "!", "_"))
+		eventually(t, name+" to be renamed", func() bool { return exists(renamed) })
+	}
+
+	for _, name := range []string{"three!", "four!"} {
+		if err := os.Mkdir(filepath.Join(root, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	eventually(t, "both directories to be held", func() bool {
+		status, err := client.Status()
+		return err == nil && status.Exceeded && len(status.Held) == 2
+	})
+	if exists(filepath.Join(root, "three_")) || exists(filepath.Join(root, "four_")) {
+		t.Fatal("Expected held directories to keep their names")
+	}
+
+	if released, err := client.Approve(); err != nil || released != 2 {
+		t.Fatalf("Approve() = %d, %v", released, err)
+	}
+	eventually(t, "held directories to be renamed", func() bool {
+		return exists(filepath.Join(root, "three_")) && exists(filepath.Join(root, "four_"))
+	})
+
+	status, err := client.Status()
+	if err != nil || status.Exceeded || status.Used != 0 {
+		t.Errorf("Expected approved renames not to count, got %+v, %v", status, err)
+	}
+}
+
+// TestControlHandler_Reload tests that reloads are passed through and their errors reach the client
+func TestControlHandler_Reload(t *testing.T) {
+	var failure error
+	reload := func() ([]string, error) {
+		if failure != nil {
+			return nil, failure
+		}
+		return []string{`--replacement: "_" -> "-"`}, nil
+	}
+
+	listener, err := watch.ListenControl(filepath.Join(t.TempDir(), "control.sock"))
+	if err != nil {
+		t.Fatalf("ListenControl() returned error: %v", err)
+	}
+	server := &http.Server{Handler: watch.NewControlHandler(watch.Control{Reload: reload})}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	client := watch.NewControlClient(listener.Addr().String())
+
+	changes, err := client.Reload()
+	if err != nil || len(changes) != 1 {
+		t.Fatalf("Reload() = %v, %v", changes, err)
+	}
+
+	failure = errors.New("invalid replacement")
+	if _, err := client.Reload(); err == nil || err.Error() != "invalid replacement" {
+		t.Errorf("Expected the reload error, got %v", err)
+	}
+
+	// Without a quota the quota requests are refused
+	if _, err := client.Status(); err == nil || !strings.Contains(err.Error(), "no rename quota") {
+		t.Errorf("Expected an error without a quota, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package web
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrInvalidLink is returned for guest tokens that are malformed, tampered with or expired
+var ErrInvalidLink = errors.New("invalid or expired link")
+
+// guestLink is the payload of a read-only guest link
+type guestLink struct {
+	RunID   string `json:"run"`             // Run whose report the link grants access to
+	Scope   string `json:"scope,omitempty"` // Root-relative folder the report is limited to (empty = whole tree)
+	Expires int64  `json:"exp"`             // Unix time after which the link stops working
+}
+
+// linkSigner issues and verifies HMAC-signed guest tokens
+// This struct needs no storage: everything a link grants is encoded in the token itself
+type linkSigner struct {
+	key []byte
+}
+
+// newLinkSigner creates a signer for the given key
+// An empty key is replaced by a random one, so links only survive until the server restarts
+func newLinkSigner(key string) *linkSigner {
+	if key != "" {
+		return &linkSigner{key: []byte(key)}
+	}
+
+	random := make([]byte, 32)
+	_, _ = rand.Read(random)
+	return &linkSigner{key: random}
+}
+
+// Sign encodes and signs a guest link as a URL-safe token
+func (ls *linkSigner) Sign(link guestLink) (string, error) {
+	payload, err := json.Marshal(link)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode link: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(ls.mac(encoded)), nil
+}
+
+// Verify decodes a token and checks its signature and expiry at the given time
+func (ls *linkSigner) Verify(token string, now time.Time) (guestLink, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return guestLink{}, ErrInvalidLink
+	}
+
+	expected, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, ls.mac(encoded)) {
+		return guestLink{}, ErrInvalidLink
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return guestLink{}, ErrInvalidLink
+	}
+
+	var link guestLink
+	if err := json.Unmarshal(payload, &link); err != nil {
+		return guestLink{}, ErrInvalidLink
+	}
+
+	if now.Unix() > link.Expires {
+		return guestLink{}, ErrInvalidLink
+	}
+
+	return link, nil
+}
+
+// mac computes the signature of an encoded payload
+func (ls *linkSigner) mac(encoded string) []byte {
+	h := hmac.New(sha256.New, ls.key)
+	h.Write([]byte(encoded))
+	return h.Sum(nil)
+}
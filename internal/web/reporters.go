@@ -0,0 +1,112 @@
+package web
+
+import (
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/paths"
+)
+
+// recordingWalker wraps a DirectoryWalker and remembers the walked folders for the plan
+type recordingWalker struct {
+	next    interfaces.DirectoryWalker
+	planner *planReporter
+}
+
+// Walk delegates to the wrapped walker and records every folder by path
+func (rw *recordingWalker) Walk(rootPath string) ([]interfaces.FolderInfo, error) {
+	folders, err := rw.next.Walk(rootPath)
+	rw.planner.folders = make(map[string]interfaces.FolderInfo, len(folders))
+	for _, folder := range folders {
+		rw.planner.folders[folder.Path] = folder
+	}
+	return folders, err
+}
+
+// Warnings forwards the problems of the wrapped walker's most recent walk, if it collects them
+func (rw *recordingWalker) Warnings() []error {
+	if warningWalker, ok := rw.next.(interfaces.WarningWalker); ok {
+		return warningWalker.Warnings()
+	}
+	return nil
+}
+
+// planReporter collects the dry-run renames as plan items
+type planReporter struct {
+	items    []PlanItem
+	folders  map[string]interfaces.FolderInfo
+	warnings []string
+}
+
+// ReportProgress is ignored while planning
+func (pr *planReporter) ReportProgress(current, total int, message string) {}
+
+// ReportError is ignored while planning; errors surface when applying
+func (pr *planReporter) ReportError(err error) {}
+
+// ReportWarning keeps a problem found while walking so the plan can show it
+func (pr *planReporter) ReportWarning(warning error) {
+	pr.warnings = append(pr.warnings, warning.Error())
+}
+
+// ReportComplete is ignored while planning
+func (pr *planReporter) ReportComplete(summary interfaces.ProcessingSummary) {}
+
+// ReportRename adds a proposed rename to the plan
+func (pr *planReporter) ReportRename(result interfaces.RenameResult) {
+	pr.items = append(pr.items, PlanItem{
+		ID:      len(pr.items),
+		OldPath: result.OldPath,
+		NewPath: result.NewPath,
+		Merged:  result.Merged,
+	})
+}
+
+// progressReporter updates the server's progress state and run record during an apply run
+type progressReporter struct {
+	server *Server
+	run    *Run
+}
+
+// ReportProgress records the current position
+func (pr *progressReporter) ReportProgress(current, total int, message string) {
+	pr.server.mu.Lock()
+	defer pr.server.mu.Unlock()
+	pr.server.progress.Current = current
+	pr.server.progress.Total = total
+	pr.server.progress.Message = message
+}
+
+// ReportError records an error message
+func (pr *progressReporter) ReportError(err error) {
+	pr.server.mu.Lock()
+	defer pr.server.mu.Unlock()
+	pr.server.progress.Errors = append(pr.server.progress.Errors, err.Error())
+}
+
+// ReportComplete records the summary
+func (pr *progressReporter) ReportComplete(summary interfaces.ProcessingSummary) {
+	pr.server.mu.Lock()
+	defer pr.server.mu.Unlock()
+	pr.server.progress.Summary = &summary
+	pr.run.Summary = &summary
+}
+
+// ReportRename records a completed rename in the run
+func (pr *progressReporter) ReportRename(result interfaces.RenameResult) {
+	pr.server.mu.Lock()
+	defer pr.server.mu.Unlock()
+	pr.run.Renamed = append(pr.run.Renamed, RunItem{
+		OldPath: paths.Relative(pr.server.root, result.OldPath),
+		NewPath: paths.Relative(pr.server.root, result.NewPath),
+		Merged:  result.Merged,
+	})
+}
+
+// ReportFailure records a failed folder in the run
+func (pr *progressReporter) ReportFailure(folder interfaces.FolderInfo, err error) {
+	pr.server.mu.Lock()
+	defer pr.server.mu.Unlock()
+	pr.run.Failed = append(pr.run.Failed, RunItem{
+		OldPath: paths.Relative(pr.server.root, folder.Path),
+		Error:   paths.RelativeError(pr.server.root, err).Error(),
+	})
+}
@@ -0,0 +1,66 @@
+package web
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// RunItem is a single folder touched by an apply run
+type RunItem struct {
+	OldPath string `json:"old_path"`        // Root-relative path before the run
+	NewPath string `json:"new_path"`        // Root-relative path after the run (empty for failures)
+	Merged  bool   `json:"merged"`          // Whether the folder was merged into an existing folder
+	Error   string `json:"error,omitempty"` // Why the folder could not be processed
+}
+
+// Run records the outcome of one apply run so its report can be reviewed later
+type Run struct {
+	ID       string                        `json:"id"`
+	Started  time.Time                     `json:"started"`
+	Finished *time.Time                    `json:"finished,omitempty"`
+	Summary  *interfaces.ProcessingSummary `json:"summary,omitempty"`
+	Renamed  []RunItem                     `json:"renamed"`
+	Failed   []RunItem                     `json:"failed"`
+}
+
+// scoped returns a copy of the run limited to items at or below scope
+// The summary is omitted because its counts cover the whole tree
+func (r *Run) scoped(scope string) Run {
+	view := Run{
+		ID:       r.ID,
+		Started:  r.Started,
+		Finished: r.Finished,
+		Summary:  r.Summary,
+		Renamed:  filterScope(r.Renamed, scope),
+		Failed:   filterScope(r.Failed, scope),
+	}
+	if scope != "" {
+		view.Summary = nil
+	}
+	return view
+}
+
+// filterScope returns the items whose original path lies at or below scope
+func filterScope(items []RunItem, scope string) []RunItem {
+	filtered := make([]RunItem, 0, len(items))
+	for _, item := range items {
+		if inScope(item.OldPath, scope) {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// inScope reports whether a root-relative path lies at or below scope
+func inScope(relPath, scope string) bool {
+	return scope == "" || relPath == scope || strings.HasPrefix(relPath, scope+"/")
+}
+
+// normalizeScope turns user input into a clean root-relative slash path ("" = whole tree)
+func normalizeScope(scope string) string {
+	cleaned := path.Clean("/" + strings.ReplaceAll(scope, "\\", "/"))
+	return strings.TrimPrefix(cleaned, "/")
+}
@@ -0,0 +1,525 @@
+// Package web provides a small embedded web UI for browsing the plan, approving items and watching progress.
+// This implementation serves a single-page UI embedded with go:embed and a minimal JSON API.
+package web
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/keepalive"
+	"github.com/punkscience/sanitize/internal/service"
+	"github.com/punkscience/sanitize/internal/state"
+	"github.com/punkscience/sanitize/internal/walker"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+//go:embed templates/guest.html
+var guestTemplateSource string
+
+// guestTemplate renders the read-only report behind a guest link
+var guestTemplate = template.Must(template.New("guest").Parse(guestTemplateSource))
+
+// defaultLinkTTL is how long guest links stay valid when no lifetime is requested
+const defaultLinkTTL = 72 * time.Hour
+
+// Option configures optional Server behavior
+type Option func(*Server)
+
+// WithLinkKey sets the key guest links are signed with
+// With a fixed key, links stay valid across server restarts; an empty key uses a random key per process
+func WithLinkKey(key string) Option {
+	return func(s *Server) {
+		s.links = newLinkSigner(key)
+	}
+}
+
+// WithLinkTTL sets the lifetime of guest links that don't request one explicitly
+func WithLinkTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		if ttl > 0 {
+			s.linkTTL = ttl
+		}
+	}
+}
+
+// WithReloader offers reloading the policy through the API
+// reload validates and swaps in the new policy, returning what changed; the plan is rebuilt afterwards
+func WithReloader(reload func() ([]string, error)) Option {
+	return func(s *Server) {
+		s.reload = reload
+	}
+}
+
+// WithKeepalive stats the root every interval while the server waits for approvals, so network mounts
+// don't drop the idle session; once a lost session is back, the plan is re-validated with its approvals kept
+func WithKeepalive(fileSystem interfaces.FileSystem, interval time.Duration) Option {
+	return func(s *Server) {
+		if interval > 0 {
+			s.keeper = keepalive.New(fileSystem, s.root, interval, s.revalidatePlan)
+		}
+	}
+}
+
+// ComponentFactory creates fresh pipeline components for each plan or apply run
+// A new processor per run keeps dry-run collision simulation independent between runs
+type ComponentFactory struct {
+	Sanitizer func() interfaces.FolderSanitizer
+	Walker    func() interfaces.DirectoryWalker
+	Processor func() interfaces.FolderProcessor
+}
+
+// PlanItem is a single proposed rename shown in the UI
+type PlanItem struct {
+	ID       int    `json:"id"`       // Stable index within the current plan
+	OldPath  string `json:"old_path"` // Current folder path
+	NewPath  string `json:"new_path"` // Path after the rename
+	Merged   bool   `json:"merged"`   // Whether the folder would be merged into an existing one
+	Approved bool   `json:"approved"` // Whether the user approved this item
+}
+
+// Progress describes the state of the current or last apply run
+type Progress struct {
+	Running bool                          `json:"running"`
+	Current int                           `json:"current"`
+	Total   int                           `json:"total"`
+	Message string                        `json:"message"`
+	Errors  []string                      `json:"errors"`
+	Summary *interfaces.ProcessingSummary `json:"summary,omitempty"`
+}
+
+// Server serves the web UI and its JSON API for a single root path
+// This struct guards all mutable state with a mutex because HTTP handlers run concurrently
+type Server struct {
+	root      string
+	factory   ComponentFactory
+	mu        sync.Mutex
+	plan      []PlanItem
+	folders   map[string]interfaces.FolderInfo
+	progress  Progress
+	planError string
+	warnings  []string
+	runs      []*Run
+	links     *linkSigner
+	linkTTL   time.Duration
+	reload    func() ([]string, error)
+	now       func() time.Time
+
+	token      string // Session token of state-changing API requests
+	listenHost string // Host ListenAndServe binds, allowed in Host headers besides loopback names
+	anyHost    bool   // Whether ListenAndServe binds a wildcard address, so any Host header is allowed
+
+	keeper      *keepalive.Keeper // Keeps the root's session alive (nil = no keepalive)
+	revalidated *time.Time        // When the plan was last re-validated after a lost session
+}
+
+// NewServer creates a web UI server for the given root path
+func NewServer(root string, factory ComponentFactory, options ...Option) *Server {
+	s := &Server{
+		root:    root,
+		factory: factory,
+		folders: make(map[string]interfaces.FolderInfo),
+		links:   newLinkSigner(""),
+		linkTTL: defaultLinkTTL,
+		now:     time.Now,
+		token:   newSessionToken(),
+	}
+
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// Handler returns the HTTP handler serving the UI and API
+// API requests are guarded against other sites; guest links are not, so data owners can open them from anywhere
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	static, _ := fs.Sub(staticFiles, "static")
+	mux.Handle("/", http.FileServer(http.FS(static)))
+	mux.HandleFunc("/api/plan", s.guard(s.handlePlan))
+	mux.HandleFunc("/api/approve", s.guard(s.handleApprove))
+	mux.HandleFunc("/api/apply", s.guard(s.handleApply))
+	mux.HandleFunc("/api/progress", s.guard(s.handleProgress))
+	mux.HandleFunc("/api/runs", s.guard(s.handleRuns))
+	mux.HandleFunc("/api/links", s.guard(s.handleLinks))
+	mux.HandleFunc("/api/reload", s.guard(s.handleReload))
+	mux.HandleFunc("GET /guest/{token}", s.handleGuest)
+
+	return mux
+}
+
+// ListenAndServe builds the initial plan and serves the UI on addr until the server fails
+func (s *Server) ListenAndServe(addr string) error {
+	s.bindHost(addr)
+	s.refreshPlan()
+	defer s.StartKeepalive()()
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
+
+// StartKeepalive starts the keepalive configured with WithKeepalive and returns a function that stops it
+// ListenAndServe calls it; without a keepalive it does nothing.
+func (s *Server) StartKeepalive() (stop func()) {
+	if s.keeper == nil {
+		return func() {}
+	}
+	s.keeper.Start()
+	return s.keeper.Stop
+}
+
+// handlePlan returns the current plan; POST recomputes it
+func (s *Server) handlePlan(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if s.isRunning() {
+			http.Error(w, "an apply run is in progress", http.StatusConflict)
+			return
+		}
+		s.refreshPlan()
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	session := ""
+	if s.keeper != nil {
+		if err := s.keeper.Lost(); err != nil {
+			session = err.Error()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, map[string]any{
+		"root":        s.root,
+		"items":       s.plan,
+		"error":       s.planError,
+		"warnings":    s.warnings,
+		"session":     session,
+		"revalidated": s.revalidated,
+	})
+}
+
+// approveRequest selects plan items to approve or reject
+type approveRequest struct {
+	IDs      []int `json:"ids"`
+	Approved bool  `json:"approved"`
+}
+
+// handleApprove marks plan items as approved or not approved
+func (s *Server) handleApprove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request approveRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range request.IDs {
+		if id >= 0 && id < len(s.plan) {
+			s.plan[id].Approved = request.Approved
+		}
+	}
+	writeJSON(w, map[string]any{"items": s.plan})
+}
+
+// handleApply starts applying all approved items in the background
+func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	folders, run, err := s.startApply()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	go s.apply(folders, run)
+	writeJSON(w, map[string]any{"started": len(folders), "run_id": run.ID})
+}
+
+// handleReload reloads the policy between apply runs and rebuilds the plan with it
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.reload == nil {
+		http.Error(w, "reloading is not enabled", http.StatusNotFound)
+		return
+	}
+	if s.isRunning() {
+		http.Error(w, "an apply run is in progress", http.StatusConflict)
+		return
+	}
+
+	changes, err := s.reload()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	s.refreshPlan()
+	writeJSON(w, map[string]any{"changes": changes})
+}
+
+// handleProgress returns the state of the current or last apply run
+func (s *Server) handleProgress(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, s.progress)
+}
+
+// handleRuns lists the recorded apply runs, newest first
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs := make([]Run, 0, len(s.runs))
+	for i := len(s.runs) - 1; i >= 0; i-- {
+		runs = append(runs, *s.runs[i])
+	}
+	writeJSON(w, map[string]any{"runs": runs})
+}
+
+// linkRequest asks for a guest link to a run, optionally limited to a folder
+type linkRequest struct {
+	RunID string `json:"run_id"`
+	Scope string `json:"scope"`
+	TTL   string `json:"ttl"` // Go duration such as "72h"; empty uses the server default
+}
+
+// handleLinks issues an expiring read-only guest link to a run's report
+func (s *Server) handleLinks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request linkRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	ttl := s.linkTTL
+	if request.TTL != "" {
+		parsed, err := time.ParseDuration(request.TTL)
+		if err != nil || parsed <= 0 {
+			http.Error(w, fmt.Sprintf("invalid ttl %q", request.TTL), http.StatusBadRequest)
+			return
+		}
+		ttl = parsed
+	}
+
+	if s.findRun(request.RunID) == nil {
+		http.Error(w, fmt.Sprintf("unknown run %q", request.RunID), http.StatusNotFound)
+		return
+	}
+
+	expires := s.now().Add(ttl)
+	token, err := s.links.Sign(guestLink{
+		RunID:   request.RunID,
+		Scope:   normalizeScope(request.Scope),
+		Expires: expires.Unix(),
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"url":     "/guest/" + token,
+		"expires": expires.UTC(),
+	})
+}
+
+// handleGuest renders the read-only report a guest link grants access to
+func (s *Server) handleGuest(w http.ResponseWriter, r *http.Request) {
+	link, err := s.links.Verify(r.PathValue("token"), s.now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	run := s.findRun(link.RunID)
+	if run == nil {
+		// Runs are kept in memory, so links outlive them across restarts
+		http.Error(w, "this run is no longer available", http.StatusNotFound)
+		return
+	}
+
+	s.mu.Lock()
+	view := run.scoped(link.Scope)
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = guestTemplate.Execute(w, map[string]any{
+		"Run":     view,
+		"Scope":   link.Scope,
+		"Expires": time.Unix(link.Expires, 0).UTC(),
+	})
+}
+
+// findRun returns the recorded run with the given ID or nil
+func (s *Server) findRun(id string) *Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, run := range s.runs {
+		if run.ID == id {
+			return run
+		}
+	}
+	return nil
+}
+
+// refreshPlan recomputes the plan with a dry run of the full pipeline
+func (s *Server) refreshPlan() {
+	planner := &planReporter{}
+	svc := service.NewSanitizeService(
+		s.factory.Sanitizer(),
+		&recordingWalker{next: s.factory.Walker(), planner: planner},
+		s.factory.Processor(),
+		planner,
+	)
+
+	err := svc.SanitizeDirectory(s.root, true)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.plan = planner.items
+	s.folders = planner.folders
+	s.warnings = planner.warnings
+	s.planError = ""
+	if err != nil {
+		s.planError = err.Error()
+	}
+}
+
+// revalidatePlan rebuilds the plan after a lost session, keeping the approvals of items that didn't change
+// A running apply refreshes the plan itself when it ends.
+func (s *Server) revalidatePlan() {
+	if s.isRunning() {
+		return
+	}
+
+	s.mu.Lock()
+	approved := make(map[PlanItem]bool)
+	for _, item := range s.plan {
+		if item.Approved {
+			approved[PlanItem{OldPath: item.OldPath, NewPath: item.NewPath, Merged: item.Merged}] = true
+		}
+	}
+	s.mu.Unlock()
+
+	s.refreshPlan()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, item := range s.plan {
+		s.plan[i].Approved = approved[PlanItem{OldPath: item.OldPath, NewPath: item.NewPath, Merged: item.Merged}]
+	}
+	revalidated := s.now().UTC()
+	s.revalidated = &revalidated
+}
+
+// startApply marks the server as running and returns the approved folders and a new run record
+func (s *Server) startApply() ([]interfaces.FolderInfo, *Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.progress.Running {
+		return nil, nil, errors.New("an apply run is already in progress")
+	}
+	if s.keeper != nil {
+		if err := s.keeper.Lost(); err != nil {
+			return nil, nil, fmt.Errorf("%w; the plan is re-validated once the share is back", err)
+		}
+	}
+
+	var folders []interfaces.FolderInfo
+	for _, item := range s.plan {
+		if item.Approved {
+			folders = append(folders, s.folders[item.OldPath])
+		}
+	}
+	if len(folders) == 0 {
+		return nil, nil, errors.New("no approved items to apply")
+	}
+
+	run := &Run{
+		ID:      state.NewRunID(),
+		Started: s.now().UTC(),
+		Renamed: make([]RunItem, 0),
+		Failed:  make([]RunItem, 0),
+	}
+	s.runs = append(s.runs, run)
+
+	s.progress = Progress{Running: true, Total: len(folders), Errors: make([]string, 0)}
+	return folders, run, nil
+}
+
+// apply renames the approved folders and refreshes the plan afterwards
+func (s *Server) apply(folders []interfaces.FolderInfo, run *Run) {
+	svc := service.NewSanitizeService(
+		s.factory.Sanitizer(),
+		walker.NewListWalker(folders),
+		s.factory.Processor(),
+		&progressReporter{server: s, run: run},
+		service.WithRunID(run.ID),
+	)
+
+	err := svc.SanitizeDirectory(s.root, false)
+
+	// The run only ends once the plan reflects the new state of the tree
+	s.refreshPlan()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.progress.Errors = append(s.progress.Errors, err.Error())
+	}
+	finished := s.now().UTC()
+	run.Finished = &finished
+	s.progress.Running = false
+}
+
+// isRunning reports whether an apply run is in progress
+func (s *Server) isRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.progress.Running
+}
+
+// writeJSON encodes v as the JSON response body
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
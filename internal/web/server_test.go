@@ -0,0 +1,428 @@
+// Package web_test provides tests for the embedded web UI server.
+// This test suite drives the JSON API against an in-memory file system.
+package web_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/processor"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+	"github.com/punkscience/sanitize/internal/walker"
+	"github.com/punkscience/sanitize/internal/web"
+)
+
+// testToken is the session token of test servers
+const testToken = "test-token"
+
+// newTestServer creates a server over an in-memory tree with two non-compliant folders
+func newTestServer(t *testing.T, options ...web.Option) (*httptest.Server, *filesystem.MemoryFileSystem) {
+	t.Helper()
+	return newTestServerWith(t, func() interfaces.FolderSanitizer { return sanitizer.NewWindowsSanitizer() }, options...)
+}
+
+// newTestServerWith creates the test server with the given sanitizer factory
+func newTestServerWith(t *testing.T, newSanitizer func() interfaces.FolderSanitizer, options ...web.Option) (*httptest.Server, *filesystem.MemoryFileSystem) {
+	t.Helper()
+
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/tree/bad<one>")
+	memory.MkdirAll("/tree/bad<two>")
+	memory.MkdirAll("/tree/fine")
+
+	server := web.NewServer("/tree", web.ComponentFactory{
+		Sanitizer: newSanitizer,
+		Walker: func() interfaces.DirectoryWalker {
+			return walker.NewFileSystemWalker(true, 0, walker.WithFileSystem(memory))
+		},
+		Processor: func() interfaces.FolderProcessor {
+			return processor.NewFileSystemProcessor(10, processor.WithFileSystem(memory))
+		},
+	}, append([]web.Option{web.WithSessionToken(testToken)}, options...)...)
+
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(httpServer.Close)
+	return httpServer, memory
+}
+
+// postJSON sends a POST request with the session token and decodes the JSON response into v
+func postJSON(t *testing.T, url, body string, v any) int {
+	t.Helper()
+
+	request, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create request for %s: %v", url, err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(web.SessionHeader, testToken)
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", url, err)
+	}
+	defer response.Body.Close()
+
+	if v != nil && response.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(response.Body).Decode(v); err != nil {
+			t.Fatalf("Failed to decode response from %s: %v", url, err)
+		}
+	}
+	return response.StatusCode
+}
+
+// TestServer_ApproveAndApply tests that only approved plan items are renamed
+func TestServer_ApproveAndApply(t *testing.T) {
+	httpServer, memory := newTestServer(t)
+
+	var plan struct {
+		Items []web.PlanItem `json:"items"`
+	}
+	if status := postJSON(t, httpServer.URL+"/api/plan", "", &plan); status != http.StatusOK {
+		t.Fatalf("Expected plan to succeed, got status %d", status)
+	}
+	if len(plan.Items) != 2 {
+		t.Fatalf("Expected 2 plan items, got %d", len(plan.Items))
+	}
+
+	// Applying without approvals is rejected
+	if status := postJSON(t, httpServer.URL+"/api/apply", "", nil); status != http.StatusConflict {
+		t.Errorf("Expected conflict when nothing is approved, got status %d", status)
+	}
+
+	var approvedID int
+	for _, item := range plan.Items {
+		if item.OldPath == "/tree/bad<one>" {
+			approvedID = item.ID
+		}
+	}
+	body := `{"ids":[` + strconv.Itoa(approvedID) + `],"approved":true}`
+	if status := postJSON(t, httpServer.URL+"/api/approve", body, nil); status != http.StatusOK {
+		t.Fatalf("Expected approve to succeed, got status %d", status)
+	}
+
+	if status := postJSON(t, httpServer.URL+"/api/apply", "", nil); status != http.StatusOK {
+		t.Fatalf("Expected apply to start, got status %d", status)
+	}
+	waitForCompletion(t, httpServer.URL)
+
+	if !memory.Exists("/tree/bad_one_") {
+		t.Error("Expected approved folder to be renamed")
+	}
+	if !memory.Exists("/tree/bad<two>") {
+		t.Error("Expected unapproved folder to be left alone")
+	}
+}
+
+// waitForCompletion polls the progress endpoint until the apply run ends
+func waitForCompletion(t *testing.T, baseURL string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		response, err := http.Get(baseURL + "/api/progress")
+		if err != nil {
+			t.Fatalf("GET progress failed: %v", err)
+		}
+		var progress web.Progress
+		err = json.NewDecoder(response.Body).Decode(&progress)
+		response.Body.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode progress: %v", err)
+		}
+		if !progress.Running {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Timed out waiting for apply run to finish")
+}
+
+// TestServer_GuestLinks tests that guest links expose a scoped, read-only run report
+func TestServer_GuestLinks(t *testing.T) {
+	httpServer, _ := newTestServer(t)
+
+	var plan struct {
+		Items []web.PlanItem `json:"items"`
+	}
+	postJSON(t, httpServer.URL+"/api/plan", "", &plan)
+	postJSON(t, httpServer.URL+"/api/approve", `{"ids":[0,1],"approved":true}`, nil)
+
+	var started struct {
+		RunID string `json:"run_id"`
+	}
+	if status := postJSON(t, httpServer.URL+"/api/apply", "", &started); status != http.StatusOK {
+		t.Fatalf("Expected apply to start, got status %d", status)
+	}
+	waitForCompletion(t, httpServer.URL)
+
+	// Links to unknown runs are rejected
+	if status := postJSON(t, httpServer.URL+"/api/links", `{"run_id":"missing"}`, nil); status != http.StatusNotFound {
+		t.Errorf("Expected not found for unknown run, got status %d", status)
+	}
+
+	var link struct {
+		URL string `json:"url"`
+	}
+	body := `{"run_id":"` + started.RunID + `","scope":"bad<one>","ttl":"1h"}`
+	if status := postJSON(t, httpServer.URL+"/api/links", body, &link); status != http.StatusOK {
+		t.Fatalf("Expected link creation to succeed, got status %d", status)
+	}
+
+	page, status := getBody(t, httpServer.URL+link.URL)
+	if status != http.StatusOK {
+		t.Fatalf("Expected guest page to load, got status %d", status)
+	}
+	if !strings.Contains(page, "bad_one_") {
+		t.Error("Expected guest page to list the folder in scope")
+	}
+	if strings.Contains(page, "bad_two_") {
+		t.Error("Expected guest page to hide folders outside the scope")
+	}
+
+	// A tampered token must not grant access
+	if _, status := getBody(t, httpServer.URL+link.URL+"x"); status != http.StatusForbidden {
+		t.Errorf("Expected forbidden for tampered link, got status %d", status)
+	}
+}
+
+// getBody fetches url and returns the response body and status code
+func getBody(t *testing.T, url string) (string, int) {
+	t.Helper()
+
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response from %s: %v", url, err)
+	}
+	return string(data), response.StatusCode
+}
+
+// TestServer_Reload tests that a reload swaps the policy in, rebuilds the plan and reports failures
+func TestServer_Reload(t *testing.T) {
+	var current atomic.Pointer[interfaces.FolderSanitizer]
+	initial := sanitizer.NewWindowsSanitizer()
+	current.Store(&initial)
+
+	var failure error
+	reload := func() ([]string, error) {
+		if failure != nil {
+			return nil, failure
+		}
+		replacements := sanitizer.DefaultReplacements()
+		replacements.InvalidChar = "-"
+		reloaded := sanitizer.NewWindowsSanitizer(sanitizer.WithReplacements(replacements))
+		current.Store(&reloaded)
+		return []string{`--replacement: "_" -> "-"`}, nil
+	}
+
+	httpServer, _ := newTestServerWith(t, func() interfaces.FolderSanitizer { return *current.Load() }, web.WithReloader(reload))
+
+	var result struct {
+		Changes []string `json:"changes"`
+	}
+	if status := postJSON(t, httpServer.URL+"/api/reload", "", &result); status != http.StatusOK {
+		t.Fatalf("Expected reload to succeed, got status %d", status)
+	}
+	if len(result.Changes) != 1 {
+		t.Errorf("Expected one change, got %v", result.Changes)
+	}
+
+	var plan struct {
+		Items []web.PlanItem `json:"items"`
+	}
+	postJSON(t, httpServer.URL+"/api/plan", "", &plan)
+	for _, item := range plan.Items {
+		if !strings.HasSuffix(item.NewPath, "-") {
+			t.Errorf("Expected the reloaded policy in the plan, got %q", item.NewPath)
+		}
+	}
+
+	failure = errors.New("invalid replacement")
+	if status := postJSON(t, httpServer.URL+"/api/reload", "", nil); status != http.StatusUnprocessableEntity {
+		t.Errorf("Expected a rejected reload to fail, got status %d", status)
+	}
+
+	// Without a reloader the endpoint is not offered
+	plain, _ := newTestServer(t)
+	if status := postJSON(t, plain.URL+"/api/reload", "", nil); status != http.StatusNotFound {
+		t.Errorf("Expected not found without a reloader, got status %d", status)
+	}
+}
+
+// droppingFileSystem fails every stat while the session to the share is down
+type droppingFileSystem struct {
+	*filesystem.MemoryFileSystem
+	down atomic.Bool
+}
+
+// Stat fails with a network error while the session is down
+func (d *droppingFileSystem) Stat(path string) (fs.FileInfo, error) {
+	if d.down.Load() {
+		return nil, &fs.PathError{Op: "stat", Path: path, Err: errors.New("host is down")}
+	}
+	return d.MemoryFileSystem.Stat(path)
+}
+
+// planState is the part of the plan response that describes the session
+type planState struct {
+	Items       []web.PlanItem `json:"items"`
+	Session     string         `json:"session"`
+	Revalidated *time.Time     `json:"revalidated"`
+}
+
+// waitForPlan polls the plan until done accepts it, failing the test after a second
+func waitForPlan(t *testing.T, url string, done func(planState) bool) planState {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		var plan planState
+		response, err := http.Get(url + "/api/plan")
+		if err != nil {
+			t.Fatalf("GET /api/plan failed: %v", err)
+		}
+		err = json.NewDecoder(response.Body).Decode(&plan)
+		response.Body.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode plan: %v", err)
+		}
+		if done(plan) {
+			return plan
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Plan never reached the expected state, last %+v", plan)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestServer_Keepalive tests that a lost session blocks applying and re-validates the plan once it is back
+func TestServer_Keepalive(t *testing.T) {
+	memory := filesystem.NewMemoryFileSystem()
+	memory.MkdirAll("/tree/bad<one>")
+	memory.MkdirAll("/tree/bad<two>")
+	dropping := &droppingFileSystem{MemoryFileSystem: memory}
+
+	server := web.NewServer("/tree", web.ComponentFactory{
+		Sanitizer: func() interfaces.FolderSanitizer { return sanitizer.NewWindowsSanitizer() },
+		Walker: func() interfaces.DirectoryWalker {
+			return walker.NewFileSystemWalker(true, 0, walker.WithFileSystem(memory))
+		},
+		Processor: func() interfaces.FolderProcessor {
+			return processor.NewFileSystemProcessor(10, processor.WithFileSystem(memory))
+		},
+	}, web.WithKeepalive(dropping, time.Millisecond), web.WithSessionToken(testToken))
+	httpServer := httptest.NewServer(server.Handler())
+	t.Cleanup(httpServer.Close)
+	defer server.StartKeepalive()()
+
+	var plan planState
+	postJSON(t, httpServer.URL+"/api/plan", "", &plan)
+	postJSON(t, httpServer.URL+"/api/approve", `{"ids":[0],"approved":true}`, nil)
+	approvedPath := plan.Items[0].OldPath
+
+	dropping.down.Store(true)
+	waitForPlan(t, httpServer.URL, func(plan planState) bool { return plan.Session != "" })
+	if status := postJSON(t, httpServer.URL+"/api/apply", "", nil); status != http.StatusConflict {
+		t.Errorf("Expected applying to be refused while the session is lost, got status %d", status)
+	}
+
+	// The tree changed while the share was away
+	memory.MkdirAll("/tree/bad<three>")
+	dropping.down.Store(false)
+	plan = waitForPlan(t, httpServer.URL, func(plan planState) bool { return plan.Revalidated != nil })
+
+	if plan.Session != "" || len(plan.Items) != 3 {
+		t.Fatalf("Expected a re-validated plan of 3 items, got %+v", plan)
+	}
+	for _, item := range plan.Items {
+		if item.Approved != (item.OldPath == approvedPath) {
+			t.Errorf("Expected only %s to stay approved, got %+v", approvedPath, item)
+		}
+	}
+}
+
+// TestServer_RejectsCrossSiteRequests tests that state-changing requests need same-origin JSON with the session token
+func TestServer_RejectsCrossSiteRequests(t *testing.T) {
+	httpServer, memory := newTestServer(t)
+	postJSON(t, httpServer.URL+"/api/approve", `{"ids":[0,1],"approved":true}`, nil)
+
+	tests := []struct {
+		name   string
+		path   string
+		header map[string]string
+		host   string
+		want   int
+	}{
+		{"form post", "/api/apply", map[string]string{"Content-Type": "application/x-www-form-urlencoded", web.SessionHeader: testToken}, "", http.StatusUnsupportedMediaType},
+		{"text post", "/api/approve", map[string]string{"Content-Type": "text/plain", web.SessionHeader: testToken}, "", http.StatusUnsupportedMediaType},
+		{"no content type", "/api/reload", map[string]string{web.SessionHeader: testToken}, "", http.StatusUnsupportedMediaType},
+		{"foreign origin", "/api/apply", map[string]string{"Content-Type": "application/json", web.SessionHeader: testToken, "Origin": "https://evil.example"}, "", http.StatusForbidden},
+		{"null origin", "/api/links", map[string]string{"Content-Type": "application/json", web.SessionHeader: testToken, "Origin": "null"}, "", http.StatusForbidden},
+		{"foreign host", "/api/apply", map[string]string{"Content-Type": "application/json", web.SessionHeader: testToken}, "evil.example:8080", http.StatusForbidden},
+		{"foreign host reading", "/api/plan", nil, "evil.example", http.StatusForbidden},
+		{"missing token", "/api/apply", map[string]string{"Content-Type": "application/json"}, "", http.StatusUnauthorized},
+		{"wrong token", "/api/links", map[string]string{"Content-Type": "application/json", web.SessionHeader: "guess"}, "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := http.MethodPost
+			if tt.header == nil {
+				method = http.MethodGet
+			}
+			request, err := http.NewRequest(method, httpServer.URL+tt.path, strings.NewReader(`{"run_id":"x"}`))
+			if err != nil {
+				t.Fatalf("Failed to create request: %v", err)
+			}
+			for key, value := range tt.header {
+				request.Header.Set(key, value)
+			}
+			if tt.host != "" {
+				request.Host = tt.host
+			}
+
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			response.Body.Close()
+			if response.StatusCode != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, response.StatusCode)
+			}
+		})
+	}
+
+	if !memory.Exists("/tree/bad<one>") || !memory.Exists("/tree/bad<two>") {
+		t.Error("Expected rejected requests to rename nothing")
+	}
+
+	// Same-origin requests from the UI are accepted
+	request, _ := http.NewRequest(http.MethodPost, httpServer.URL+"/api/plan", strings.NewReader("{}"))
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	request.Header.Set("Origin", httpServer.URL)
+	request.Header.Set(web.SessionHeader, testToken)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Expected a same-origin request to succeed, got status %d", response.StatusCode)
+	}
+}
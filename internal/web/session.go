@@ -0,0 +1,107 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SessionHeader carries the session token on every API request that changes state
+const SessionHeader = "X-Sanitize-Token"
+
+// WithSessionToken sets the token state-changing API requests must carry
+// An empty token keeps the random token generated per process
+func WithSessionToken(token string) Option {
+	return func(s *Server) {
+		if token != "" {
+			s.token = token
+		}
+	}
+}
+
+// newSessionToken returns a random token for a server process
+func newSessionToken() string {
+	random := make([]byte, 16)
+	_, _ = rand.Read(random)
+	return hex.EncodeToString(random)
+}
+
+// SessionToken returns the token the UI has to send with state-changing requests
+// The startup message passes it to the browser in the URL fragment, which is never sent to the server.
+func (s *Server) SessionToken() string {
+	return s.token
+}
+
+// guard protects an API endpoint against cross-site requests and DNS rebinding
+// Every request must name an allowed host; requests other than GET and HEAD must also be same-origin JSON with the session token.
+func (s *Server) guard(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host) {
+			http.Error(w, "foreign host", http.StatusForbidden)
+			return
+		}
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next(w, r)
+			return
+		}
+
+		if origin := r.Header.Get("Origin"); origin != "" && !sameOrigin(origin, r.Host) {
+			http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+			return
+		}
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			http.Error(w, "requests must be sent as application/json", http.StatusUnsupportedMediaType)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(SessionHeader)), []byte(s.token)) != 1 {
+			http.Error(w, "missing or wrong session token", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// allowedHost reports whether a Host header names this server
+// Loopback names are always allowed; other names only once ListenAndServe bound them, or any name on a wildcard address.
+func (s *Server) allowedHost(hostport string) bool {
+	host := hostport
+	if split, _, err := net.SplitHostPort(hostport); err == nil {
+		host = split
+	}
+	host = strings.Trim(host, "[]")
+
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	return s.anyHost || (s.listenHost != "" && strings.EqualFold(host, s.listenHost))
+}
+
+// bindHost records the host ListenAndServe listens on for allowedHost
+func (s *Server) bindHost(addr string) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		s.anyHost = true
+		return
+	}
+	s.listenHost = host
+}
+
+// sameOrigin reports whether an Origin header names the host the request was sent to
+func sameOrigin(origin, host string) bool {
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" {
+		return false // Includes the "null" origin of sandboxed pages and files
+	}
+	return strings.EqualFold(parsed.Host, host)
+}
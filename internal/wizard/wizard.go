@@ -0,0 +1,244 @@
+// Package wizard provides an interactive questionnaire using Bubble Tea.
+// This implementation asks one question per screen and collects the answers by key.
+package wizard
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ErrCancelled is returned when the user quits the wizard before answering every question
+var ErrCancelled = errors.New("wizard cancelled")
+
+// Option is a single choice of a multiple-choice question
+type Option struct {
+	Label       string // Text shown in the list
+	Value       string // Answer recorded when the option is chosen
+	Description string // Additional explanation shown next to the label
+}
+
+// Question is a single step of the wizard
+// Questions without options accept free text
+type Question struct {
+	Key     string   // Key the answer is recorded under
+	Title   string   // Question shown as the heading of the step
+	Help    string   // Explanation shown below the heading
+	Options []Option // Choices; empty for free-text questions
+	Default string   // Preselected option value, or the answer used for empty text input
+}
+
+// Model implements tea.Model for the wizard
+// This struct walks through the questions and remembers every answer
+type Model struct {
+	title     string
+	questions []Question
+	step      int
+	cursor    int
+	input     []rune
+	answers   map[string]string
+	done      bool
+	cancelled bool
+}
+
+// New creates a wizard model for the given questions
+// This constructor preselects the default option of the first question
+func New(title string, questions []Question) *Model {
+	m := &Model{
+		title:     title,
+		questions: questions,
+		answers:   make(map[string]string, len(questions)),
+	}
+	m.enterStep(0)
+	return m
+}
+
+// Run shows the wizard in the terminal and returns the answers by question key
+func Run(title string, questions []Question) (map[string]string, error) {
+	model := New(title, questions)
+	if _, err := tea.NewProgram(model).Run(); err != nil {
+		return nil, fmt.Errorf("failed to run wizard: %w", err)
+	}
+	if model.Cancelled() {
+		return nil, ErrCancelled
+	}
+	return model.Answers(), nil
+}
+
+// Answers returns the answers given so far by question key
+func (m *Model) Answers() map[string]string {
+	return m.answers
+}
+
+// Done reports whether every question has been answered
+func (m *Model) Done() bool {
+	return m.done
+}
+
+// Cancelled reports whether the user quit the wizard early
+func (m *Model) Cancelled() bool {
+	return m.cancelled
+}
+
+// enterStep shows a question, restoring its previous answer or default
+func (m *Model) enterStep(step int) {
+	m.step = step
+	m.cursor = 0
+	m.input = nil
+	if step >= len(m.questions) {
+		return
+	}
+
+	question := m.questions[step]
+	current, answered := m.answers[question.Key]
+	if !answered {
+		current = question.Default
+	}
+
+	if len(question.Options) == 0 {
+		if answered {
+			m.input = []rune(current)
+		}
+		return
+	}
+	for i, option := range question.Options {
+		if option.Value == current {
+			m.cursor = i
+		}
+	}
+}
+
+// Init initializes the Bubble Tea model
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles key presses and moves through the questions
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.done {
+		return m, nil
+	}
+	question := m.questions[m.step]
+
+	switch keyMsg.Type {
+	case tea.KeyCtrlC:
+		m.cancelled = true
+		return m, tea.Quit
+	case tea.KeyEsc, tea.KeyShiftTab:
+		if m.step > 0 {
+			m.enterStep(m.step - 1)
+		}
+		return m, nil
+	case tea.KeyEnter:
+		m.answers[question.Key] = m.answer(question)
+		if m.step == len(m.questions)-1 {
+			m.done = true
+			return m, tea.Quit
+		}
+		m.enterStep(m.step + 1)
+		return m, nil
+	}
+
+	if len(question.Options) > 0 {
+		switch keyMsg.String() {
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.cursor < len(question.Options)-1 {
+				m.cursor++
+			}
+		}
+		return m, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyBackspace:
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.input = append(m.input, keyMsg.Runes...)
+	}
+	return m, nil
+}
+
+// answer returns the value of the selected option or the typed text
+func (m *Model) answer(question Question) string {
+	if len(question.Options) > 0 {
+		return question.Options[m.cursor].Value
+	}
+	if len(m.input) == 0 {
+		return question.Default
+	}
+	return string(m.input)
+}
+
+// View renders the current question
+func (m *Model) View() string {
+	if m.done || m.cancelled {
+		return ""
+	}
+
+	var b strings.Builder
+
+	// Styles
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("15")).
+		Background(lipgloss.Color("63")).
+		Padding(0, 1)
+
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39"))
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("40"))
+
+	infoStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("245"))
+
+	question := m.questions[m.step]
+
+	b.WriteString(titleStyle.Render(fmt.Sprintf("%s (%d/%d)", m.title, m.step+1, len(m.questions))))
+	b.WriteString("\n\n")
+	b.WriteString(headerStyle.Render(question.Title))
+	b.WriteString("\n")
+	if question.Help != "" {
+		b.WriteString(infoStyle.Render(question.Help))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	if len(question.Options) > 0 {
+		for i, option := range question.Options {
+			line := "  " + option.Label
+			if i == m.cursor {
+				line = selectedStyle.Render("> " + option.Label)
+			}
+			b.WriteString(line)
+			if option.Description != "" {
+				b.WriteString(infoStyle.Render("  " + option.Description))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(infoStyle.Render("Up/down to choose, Enter to confirm, Esc to go back, Ctrl+C to cancel"))
+		return b.String()
+	}
+
+	b.WriteString("> " + string(m.input) + "_\n")
+	if question.Default != "" {
+		b.WriteString(infoStyle.Render(fmt.Sprintf("Leave empty for %q", question.Default)))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(infoStyle.Render("Enter to confirm, Esc to go back, Ctrl+C to cancel"))
+	return b.String()
+}
@@ -0,0 +1,89 @@
+// Package wizard_test provides tests for the wizard package.
+// This test suite drives the wizard with key presses and checks the collected answers.
+package wizard_test
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/punkscience/sanitize/internal/wizard"
+)
+
+// questions returns a multiple-choice question followed by a free-text question
+func questions() []wizard.Question {
+	return []wizard.Question{
+		{
+			Key:   "profile",
+			Title: "Target platform",
+			Options: []wizard.Option{
+				{Label: "Windows", Value: "windows"},
+				{Label: "OneDrive", Value: "onedrive"},
+				{Label: "POSIX", Value: "posix"},
+			},
+			Default: "onedrive",
+		},
+		{
+			Key:     "path",
+			Title:   "Sample folder",
+			Default: ".",
+		},
+	}
+}
+
+// press sends key presses to the model
+func press(model *wizard.Model, keys ...tea.KeyMsg) {
+	for _, key := range keys {
+		model.Update(key)
+	}
+}
+
+// typed returns the key press for typing text
+func typed(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+}
+
+var (
+	enter = tea.KeyMsg{Type: tea.KeyEnter}
+	down  = tea.KeyMsg{Type: tea.KeyDown}
+	esc   = tea.KeyMsg{Type: tea.KeyEsc}
+)
+
+// TestModel_Defaults tests that confirming every step keeps the defaults
+func TestModel_Defaults(t *testing.T) {
+	model := wizard.New("Setup", questions())
+
+	press(model, enter, enter)
+
+	if !model.Done() {
+		t.Fatal("Expected the wizard to be done")
+	}
+	answers := model.Answers()
+	if answers["profile"] != "onedrive" || answers["path"] != "." {
+		t.Errorf("Expected default answers, got %v", answers)
+	}
+}
+
+// TestModel_ChooseAndType tests choosing an option, going back and typing text
+func TestModel_ChooseAndType(t *testing.T) {
+	model := wizard.New("Setup", questions())
+
+	// Choose POSIX, go back to check the choice is kept, then type a folder
+	press(model, down, enter, esc, enter, typed("/srv/"), typed("share"), enter)
+
+	answers := model.Answers()
+	if answers["profile"] != "posix" || answers["path"] != "/srv/share" {
+		t.Errorf("Expected posix and /srv/share, got %v", answers)
+	}
+}
+
+// TestModel_Cancel tests that Ctrl+C cancels the wizard
+func TestModel_Cancel(t *testing.T) {
+	model := wizard.New("Setup", questions())
+
+	press(model, tea.KeyMsg{Type: tea.KeyCtrlC})
+
+	if !model.Cancelled() || model.Done() {
+		t.Error("Expected the wizard to be cancelled")
+	}
+}
@@ -0,0 +1,75 @@
+package wordpack
+
+import (
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Sanitizer implements FolderSanitizer, NameExplainer, FolderExplainer and NameTracer
+// This struct reports reserved-word matches as rule violations and optionally replaces them
+// before the wrapped sanitizer applies its own rules
+type Sanitizer struct {
+	base    interfaces.FolderSanitizer
+	packs   []*Pack
+	replace bool
+}
+
+// NewSanitizer wraps base with reserved-word packs; matches are only replaced when replace is set
+// Without replacement, matching names keep their name but are still reported by check mode
+func NewSanitizer(base interfaces.FolderSanitizer, packs []*Pack, replace bool) *Sanitizer {
+	return &Sanitizer{
+		base:    base,
+		packs:   packs,
+		replace: replace,
+	}
+}
+
+// SanitizeName returns the sanitized version of a bare name
+// This method implements the FolderSanitizer interface
+func (s *Sanitizer) SanitizeName(name string) string {
+	sanitized, _ := s.ExplainFolder(interfaces.FolderInfo{Name: name})
+	return sanitized
+}
+
+// ExplainName returns the sanitized name and the rules that fired, including matching packs
+// This method implements the NameExplainer interface
+func (s *Sanitizer) ExplainName(name string) (string, []string) {
+	return s.ExplainFolder(interfaces.FolderInfo{Name: name})
+}
+
+// TraceName returns the sanitized name and what each matching pack and rule changed
+// This method implements the NameTracer interface
+func (s *Sanitizer) TraceName(name string) (string, []interfaces.RuleStep) {
+	var steps []interfaces.RuleStep
+	for _, pack := range s.packs {
+		if !pack.Matches(name) {
+			continue
+		}
+		replaced := name
+		if s.replace {
+			replaced = pack.Replace(name)
+		}
+		steps = append(steps, interfaces.RuleStep{Rule: pack.Rule(), Before: name, After: replaced})
+		name = replaced
+	}
+
+	sanitized, baseSteps := interfaces.Trace(s.base, name)
+	return sanitized, append(steps, baseSteps...)
+}
+
+// ExplainFolder applies the packs and then the wrapped sanitizer to the folder
+// This method implements the FolderExplainer interface
+func (s *Sanitizer) ExplainFolder(folder interfaces.FolderInfo) (string, []string) {
+	var rules []string
+	for _, pack := range s.packs {
+		if !pack.Matches(folder.Name) {
+			continue
+		}
+		rules = append(rules, pack.Rule())
+		if s.replace {
+			folder.Name = pack.Replace(folder.Name)
+		}
+	}
+
+	sanitized, baseRules := interfaces.Explain(s.base, folder)
+	return sanitized, append(rules, baseRules...)
+}
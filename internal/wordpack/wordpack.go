@@ -0,0 +1,165 @@
+// Package wordpack flags and replaces organization-specific reserved words in folder names.
+// This implementation follows the Decorator pattern by wrapping the configured FolderSanitizer.
+package wordpack
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// RulePrefix prefixes the rule identifier reported for a pack match, e.g. "reserved-word:trademarks"
+const RulePrefix = "reserved-word:"
+
+// regexPrefix marks a pattern line as a regular expression instead of a word
+const regexPrefix = "re:"
+
+// replacementSeparator separates a pattern from its optional replacement
+const replacementSeparator = "=>"
+
+// Pattern is a single reserved word or regular expression of a pack
+type Pattern struct {
+	Spec        string // Original pattern line, for messages
+	Replacement string // Text that replaces a match (empty = remove the match)
+	wholeWord   bool   // Only match where the pattern isn't part of a longer word
+	expression  *regexp.Regexp
+}
+
+// Pack is a named list of reserved-word patterns loaded from a file
+type Pack struct {
+	Name     string
+	Patterns []Pattern
+}
+
+// Load reads a pack from a file; the pack is named after the file without its extension
+func Load(path string) (*Pack, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reserved-word pack: %w", err)
+	}
+	defer file.Close()
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return Parse(name, file)
+}
+
+// Parse reads pack patterns, one per line; blank lines and lines starting with # are ignored
+// A line is a word (matched case-insensitively as a whole word, * matches any letters or digits)
+// or "re:<expression>" for a case-insensitive regular expression, optionally followed by "=> replacement"
+func Parse(name string, r io.Reader) (*Pack, error) {
+	pack := &Pack{Name: name}
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pattern, err := parsePattern(line)
+		if err != nil {
+			return nil, fmt.Errorf("reserved-word pack %s, line %d: %w", name, lineNumber, err)
+		}
+		pack.Patterns = append(pack.Patterns, pattern)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read reserved-word pack %s: %w", name, err)
+	}
+
+	return pack, nil
+}
+
+// parsePattern parses a single non-empty pattern line
+func parsePattern(line string) (Pattern, error) {
+	spec, replacement, _ := strings.Cut(line, replacementSeparator)
+	spec = strings.TrimSpace(spec)
+	pattern := Pattern{Spec: spec, Replacement: strings.TrimSpace(replacement)}
+
+	var source string
+	if expression, ok := strings.CutPrefix(spec, regexPrefix); ok {
+		source = expression
+	} else {
+		// Words are literal except for *, which stands for the rest of a word
+		pattern.wholeWord = true
+		source = strings.ReplaceAll(regexp.QuoteMeta(spec), `\*`, `[\pL\pN]*`)
+	}
+	if strings.TrimSpace(source) == "" {
+		return Pattern{}, fmt.Errorf("empty pattern %q", spec)
+	}
+
+	expression, err := regexp.Compile("(?i)" + source)
+	if err != nil {
+		return Pattern{}, fmt.Errorf("invalid pattern %q: %w", spec, err)
+	}
+	pattern.expression = expression
+
+	return pattern, nil
+}
+
+// Rule returns the identifier reported when a name matches the pack
+func (p *Pack) Rule() string {
+	return RulePrefix + p.Name
+}
+
+// Matches reports whether any pattern of the pack occurs in name
+func (p *Pack) Matches(name string) bool {
+	for _, pattern := range p.Patterns {
+		if len(pattern.matches(name)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Replace substitutes every match of every pattern with the pattern's replacement
+func (p *Pack) Replace(name string) string {
+	for _, pattern := range p.Patterns {
+		matches := pattern.matches(name)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var replaced strings.Builder
+		last := 0
+		for _, match := range matches {
+			replaced.WriteString(name[last:match[0]])
+			replaced.WriteString(pattern.Replacement)
+			last = match[1]
+		}
+		replaced.WriteString(name[last:])
+		name = replaced.String()
+	}
+	return name
+}
+
+// matches returns the byte ranges of the pattern in name, dropping word matches inside longer words
+func (p Pattern) matches(name string) [][]int {
+	matches := p.expression.FindAllStringIndex(name, -1)
+	if !p.wholeWord {
+		return matches
+	}
+
+	whole := matches[:0]
+	for _, match := range matches {
+		if match[0] == match[1] {
+			continue
+		}
+		before, _ := utf8.DecodeLastRuneInString(name[:match[0]])
+		after, _ := utf8.DecodeRuneInString(name[match[1]:])
+		if !isWordRune(before) && !isWordRune(after) {
+			whole = append(whole, match)
+		}
+	}
+	return whole
+}
+
+// isWordRune reports whether r continues a word; underscores, hyphens and spaces separate words
+func isWordRune(r rune) bool {
+	return r != utf8.RuneError && (unicode.IsLetter(r) || unicode.IsNumber(r))
+}
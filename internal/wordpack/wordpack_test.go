@@ -0,0 +1,146 @@
+// Package wordpack_test provides tests for reserved-word packs.
+// This test suite ensures patterns parse, match whole words and are reported or replaced.
+package wordpack_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+	"github.com/punkscience/sanitize/internal/wordpack"
+)
+
+// samplePack is a pack with words, wildcards, expressions and replacements
+const samplePack = `
+# Trademarks and legacy codes
+acme => ACME-Corp
+darn*
+re:^tmp[0-9]+$ => scratch
+secret
+`
+
+// parseSample parses samplePack or fails the test
+func parseSample(t *testing.T) *wordpack.Pack {
+	t.Helper()
+	pack, err := wordpack.Parse("policy", strings.NewReader(samplePack))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	return pack
+}
+
+// TestParse_Invalid tests that malformed patterns are rejected with their line number
+func TestParse_Invalid(t *testing.T) {
+	for _, content := range []string{"re:(unclosed", "re: => x", "ok\n=> replacement"} {
+		if _, err := wordpack.Parse("bad", strings.NewReader(content)); err == nil {
+			t.Errorf("Parse(%q) expected error", content)
+		}
+	}
+
+	_, err := wordpack.Parse("bad", strings.NewReader("ok\n\nre:[z-a]"))
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected error on line 3, got %v", err)
+	}
+}
+
+// TestPack_Matches tests whole-word, wildcard and expression matching
+func TestPack_Matches(t *testing.T) {
+	pack := parseSample(t)
+
+	testCases := map[string]bool{
+		"ACME Reports":   true,
+		"acme_archive":   true,
+		"acmeology":      false, // part of a longer word
+		"Darnedest-2020": true,
+		"tmp42":          true,
+		"my tmp42":       false, // anchored expression
+		"Top Secret":     true,
+		"Secretary":      false,
+		"Quarterly":      false,
+	}
+
+	for name, expected := range testCases {
+		if result := pack.Matches(name); result != expected {
+			t.Errorf("Matches(%q) = %v, expected %v", name, result, expected)
+		}
+	}
+}
+
+// TestPack_Replace tests replacements, including removal when no replacement is given
+func TestPack_Replace(t *testing.T) {
+	pack := parseSample(t)
+
+	testCases := map[string]string{
+		"acme reports":  "ACME-Corp reports",
+		"tmp7":          "scratch",
+		"Secret Plans":  " Plans",
+		"Darned darnit": " ",
+		"unchanged":     "unchanged",
+	}
+
+	for name, expected := range testCases {
+		if result := pack.Replace(name); result != expected {
+			t.Errorf("Replace(%q) = %q, expected %q", name, result, expected)
+		}
+	}
+}
+
+// TestSanitizer tests report-only and replacing modes on top of the Windows rules
+func TestSanitizer(t *testing.T) {
+	pack := parseSample(t)
+	base := sanitizer.NewWindowsSanitizer()
+
+	// Report-only keeps the name but names the pack as a violated rule
+	reportOnly := wordpack.NewSanitizer(base, []*wordpack.Pack{pack}, false)
+	name, rules := reportOnly.ExplainName("Top Secret")
+	if name != "Top Secret" || !reflect.DeepEqual(rules, []string{"reserved-word:policy"}) {
+		t.Errorf("ExplainName() = %q, %v; expected unchanged name with the pack rule", name, rules)
+	}
+
+	// Replacing hands the result to the Windows rules, which trim the leftover space
+	replacing := wordpack.NewSanitizer(base, []*wordpack.Pack{pack}, true)
+	name, rules = replacing.ExplainName("Top Secret")
+	if name != "Top" || !reflect.DeepEqual(rules, []string{"reserved-word:policy", sanitizer.RuleSurroundingSpaces}) {
+		t.Errorf("ExplainName() = %q, %v; expected %q with pack and space rules", name, rules, "Top")
+	}
+
+	// Names without matches are left to the wrapped sanitizer alone
+	if name := replacing.SanitizeName("bad<name>"); name != "bad_name_" {
+		t.Errorf("SanitizeName() = %q, expected %q", name, "bad_name_")
+	}
+}
+
+// TestSanitizer_TraceName tests that pack replacements are traced ahead of the wrapped sanitizer's steps
+func TestSanitizer_TraceName(t *testing.T) {
+	pack := parseSample(t)
+	replacing := wordpack.NewSanitizer(sanitizer.NewWindowsSanitizer(), []*wordpack.Pack{pack}, true)
+
+	name, steps := replacing.TraceName("Top Secret")
+	expected := []interfaces.RuleStep{
+		{Rule: "reserved-word:policy", Before: "Top Secret", After: "Top "},
+		{Rule: sanitizer.RuleSurroundingSpaces, Before: "Top ", After: "Top"},
+	}
+	if name != "Top" || !reflect.DeepEqual(steps, expected) {
+		t.Errorf("TraceName() = %q, %+v; expected %q, %+v", name, steps, "Top", expected)
+	}
+}
+
+// TestLoad tests that packs are named after their file
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trademarks.txt")
+	if err := os.WriteFile(path, []byte("acme\n"), 0644); err != nil {
+		t.Fatalf("Failed to write pack: %v", err)
+	}
+
+	pack, err := wordpack.Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	if pack.Name != "trademarks" || pack.Rule() != "reserved-word:trademarks" || len(pack.Patterns) != 1 {
+		t.Errorf("Unexpected pack %+v", pack)
+	}
+}
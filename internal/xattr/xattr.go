@@ -0,0 +1,68 @@
+// Package xattr keeps the original name of a renamed folder in an extended attribute of the folder itself.
+// This implementation follows the Decorator pattern by wrapping an existing FolderProcessor.
+package xattr
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// Attribute is the extended attribute that holds a folder's original name
+const Attribute = "user.sanitize.original"
+
+// ErrUnsupported is returned on platforms without extended attributes; it matches errors.ErrUnsupported like
+// the errors of file systems without them
+var ErrUnsupported = fmt.Errorf("extended attributes are not supported on this platform: %w", errors.ErrUnsupported)
+
+// Supported reports whether extended attributes are available on this platform
+// Individual file systems may still lack them, which Set reports as an error
+func Supported() bool {
+	return supported
+}
+
+// Store reads and removes original names; it implements the sidecar package's Attributes interface
+type Store struct{}
+
+// Original returns the original name stored on path, or "" if none is stored
+func (Store) Original(path string) (string, error) {
+	return Original(path)
+}
+
+// Remove deletes the original name stored on path; a missing attribute is not an error
+func (Store) Remove(path string) error {
+	return Remove(path)
+}
+
+// Processor wraps next so every completed rename of a real run stores the folder's original name
+// A folder that already carries an original name keeps it, so the name before the first rename survives.
+// Failures don't affect the rename; they are passed to onError.
+func Processor(next interfaces.FolderProcessor, onError func(path string, err error)) interfaces.FolderProcessor {
+	return &attributeProcessor{next: next, onError: onError}
+}
+
+// attributeProcessor stores original names on the folders it renames
+type attributeProcessor struct {
+	next    interfaces.FolderProcessor
+	onError func(path string, err error)
+}
+
+// ProcessRename renames through the wrapped processor and stores the original name on the renamed folder
+// This method implements the FolderProcessor interface
+func (p *attributeProcessor) ProcessRename(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+	result, err := p.next.ProcessRename(folder, newName, dryRun)
+	if err != nil || dryRun || result == nil || !result.WasRenamed || !result.Success || result.Merged {
+		return result, err
+	}
+
+	original, attrErr := Original(result.NewPath)
+	if attrErr == nil && original == "" {
+		attrErr = Set(result.NewPath, filepath.Base(result.OldPath))
+	}
+	if attrErr != nil {
+		p.onError(result.NewPath, attrErr)
+	}
+	return result, nil
+}
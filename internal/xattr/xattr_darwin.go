@@ -0,0 +1,6 @@
+package xattr
+
+import "golang.org/x/sys/unix"
+
+// errNoAttribute is returned by the kernel for a missing attribute
+const errNoAttribute = unix.ENOATTR
@@ -0,0 +1,21 @@
+//go:build !linux && !darwin
+
+package xattr
+
+// supported reports whether extended attributes are available on this platform
+const supported = false
+
+// Original returns ErrUnsupported on this platform
+func Original(path string) (string, error) {
+	return "", ErrUnsupported
+}
+
+// Set returns ErrUnsupported on this platform
+func Set(path, name string) error {
+	return ErrUnsupported
+}
+
+// Remove returns ErrUnsupported on this platform
+func Remove(path string) error {
+	return ErrUnsupported
+}
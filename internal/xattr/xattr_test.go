@@ -0,0 +1,72 @@
+package xattr_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/processor"
+	"github.com/punkscience/sanitize/internal/xattr"
+)
+
+// supportedDir returns a temporary directory whose file system stores extended attributes, or skips the test
+func supportedDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if !xattr.Supported() {
+		t.Skip("extended attributes are not supported on this platform")
+	}
+	if err := xattr.Set(dir, "probe"); errors.Is(err, errors.ErrUnsupported) {
+		t.Skipf("the temporary directory does not support extended attributes: %v", err)
+	} else if err != nil {
+		t.Fatalf("Set() returned error: %v", err)
+	}
+	return dir
+}
+
+// TestProcessor tests that renamed folders keep the name before their first rename
+func TestProcessor(t *testing.T) {
+	root := supportedDir(t)
+	os.Mkdir(filepath.Join(root, "Café"), 0755)
+
+	var failures []error
+	rename := func(name, newName string, dryRun bool) {
+		// A fresh processor for every rename, so planned dry-run renames don't count as collisions
+		folderProcessor := xattr.Processor(processor.NewFileSystemProcessor(10, processor.WithFileSystem(filesystem.NewOSFileSystem())), func(path string, err error) {
+			failures = append(failures, err)
+		})
+		path := filepath.Join(root, name)
+		folder := interfaces.FolderInfo{Path: path, Name: name, Parent: root}
+		if _, err := folderProcessor.ProcessRename(folder, newName, dryRun); err != nil {
+			t.Fatalf("ProcessRename(%s) returned error: %v", name, err)
+		}
+	}
+
+	rename("Café", "Cafe", true)
+	if original, err := xattr.Original(filepath.Join(root, "Café")); err != nil || original != "" {
+		t.Errorf("Expected a dry run to store nothing, got %q, %v", original, err)
+	}
+
+	rename("Café", "Cafe", false)
+	rename("Cafe", "Kafe", false)
+	original, err := xattr.Original(filepath.Join(root, "Kafe"))
+	if err != nil || original != "Café" {
+		t.Errorf("Expected the first original name %q, got %q, %v", "Café", original, err)
+	}
+	if len(failures) != 0 {
+		t.Errorf("Expected no failures, got %v", failures)
+	}
+
+	if err := xattr.Remove(filepath.Join(root, "Kafe")); err != nil {
+		t.Fatalf("Remove() returned error: %v", err)
+	}
+	if err := xattr.Remove(filepath.Join(root, "Kafe")); err != nil {
+		t.Errorf("Expected removing a missing attribute to succeed, got %v", err)
+	}
+	if original, err := xattr.Original(filepath.Join(root, "Kafe")); err != nil || original != "" {
+		t.Errorf("Expected no original name after Remove, got %q, %v", original, err)
+	}
+}
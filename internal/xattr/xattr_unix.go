@@ -0,0 +1,42 @@
+//go:build linux || darwin
+
+package xattr
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// supported reports whether extended attributes are available on this platform
+const supported = true
+
+// Original returns the original name stored on path, or "" if none is stored
+func Original(path string) (string, error) {
+	buffer := make([]byte, 1024) // Names are limited to 255 bytes on every common file system
+	size, err := unix.Getxattr(path, Attribute, buffer)
+	if errors.Is(err, errNoAttribute) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s of %s: %w", Attribute, path, err)
+	}
+	return string(buffer[:size]), nil
+}
+
+// Set stores name as the original name of path
+func Set(path, name string) error {
+	if err := unix.Setxattr(path, Attribute, []byte(name), 0); err != nil {
+		return fmt.Errorf("failed to write %s of %s: %w", Attribute, path, err)
+	}
+	return nil
+}
+
+// Remove deletes the original name stored on path; a missing attribute is not an error
+func Remove(path string) error {
+	if err := unix.Removexattr(path, Attribute); err != nil && !errors.Is(err, errNoAttribute) {
+		return fmt.Errorf("failed to remove %s of %s: %w", Attribute, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+// closableReporter is a reporter the user can close before the run is over, like the TUI
+type closableReporter interface {
+	// Closed returns a channel that is closed once the user closed the reporter
+	Closed() <-chan struct{}
+}
+
+// eitherClosed returns a channel that is closed as soon as a or b is closed
+func eitherClosed(a, b <-chan struct{}) <-chan struct{} {
+	closed := make(chan struct{})
+	go func() {
+		select {
+		case <-a:
+		case <-b:
+		}
+		close(closed)
+	}()
+	return closed
+}
+
+// notifyInterrupt returns a channel that is closed on the first Ctrl-C (SIGINT) and a function to stop listening
+// The first signal prints message; afterwards the default handling is restored, so a second Ctrl-C stops the process immediately
+func notifyInterrupt(message string) (<-chan struct{}, func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+
+	interrupt := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			// stderr keeps JSON progress on stdout parsable
+			fmt.Fprintln(os.Stderr, "\n"+message)
+			close(interrupt)
+		case <-done:
+		}
+	}()
+
+	return interrupt, func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
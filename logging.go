@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/logging"
+	"github.com/punkscience/sanitize/internal/reporter"
+)
+
+// Flags for the audit log
+var (
+	logFile   string // File the audit log is appended to (empty = no log)
+	logLevel  string // Lowest level written to the log
+	logFormat string // text or json
+)
+
+// logger records warnings, skipped directories and renames; it discards everything without --log-file
+var logger = logging.Discard()
+
+// prepareRun applies the policy, resolves the console output and then opens the audit log, so a policy can configure both
+func prepareRun(cmd *cobra.Command, args []string) error {
+	if err := loadPolicy(cmd, args); err != nil {
+		return err
+	}
+	if err := resolveOutput(); err != nil {
+		return err
+	}
+	return openLog(cmd)
+}
+
+// openLog opens the file named by --log-file and logs the start of the command
+// The file is left open until the process exits; entries are written unbuffered
+func openLog(cmd *cobra.Command) error {
+	if logFile == "" {
+		return nil
+	}
+
+	opened, _, err := logging.Open(logFile, logLevel, logFormat)
+	if err != nil {
+		return err
+	}
+	logger = opened.With("run_id", runID)
+	logger.Info("started", "command", cmd.CommandPath(), "path", rootPath)
+
+	return nil
+}
+
+// withLogReporter adds the audit log to a reporter when --log-file is set
+func withLogReporter(progressReporter interfaces.ProgressReporter, dryRun bool) interfaces.ProgressReporter {
+	if logFile == "" {
+		return progressReporter
+	}
+	// The log comes first, so its closing line is written before the TUI waits for the user to quit
+	return reporter.NewMultiReporter(reporter.NewLogReporter(logger, dryRun), progressReporter)
+}
+
+// init registers the logging flags on every command
+func init() {
+	rootCmd.PersistentPreRunE = prepareRun
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append an audit log of warnings, skipped directories and renames to this file, independent of console output")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Lowest level written to --log-file: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Format of --log-file: "+strings.Join(logging.Formats, " or "))
+}
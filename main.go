@@ -3,29 +3,118 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
-	"sanitize/internal/interfaces"
-	"sanitize/internal/processor"
-	"sanitize/internal/reporter"
-	"sanitize/internal/sanitizer"
-	"sanitize/internal/service"
-	"sanitize/internal/walker"
+	"github.com/punkscience/sanitize/internal/ancestors"
+	"github.com/punkscience/sanitize/internal/audit"
+	"github.com/punkscience/sanitize/internal/casing"
+	"github.com/punkscience/sanitize/internal/chaos"
+	"github.com/punkscience/sanitize/internal/checkpoint"
+	"github.com/punkscience/sanitize/internal/classify"
+	"github.com/punkscience/sanitize/internal/collation"
+	"github.com/punkscience/sanitize/internal/failures"
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/htmlreport"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/journal"
+	"github.com/punkscience/sanitize/internal/paths"
+	"github.com/punkscience/sanitize/internal/processor"
+	"github.com/punkscience/sanitize/internal/reporter"
+	"github.com/punkscience/sanitize/internal/ruleset"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+	"github.com/punkscience/sanitize/internal/schedule"
+	"github.com/punkscience/sanitize/internal/service"
+	"github.com/punkscience/sanitize/internal/state"
+	"github.com/punkscience/sanitize/internal/walker"
+	"github.com/punkscience/sanitize/internal/wordpack"
 )
 
 // CLI flags
 var (
-	rootPath string
-	dryRun   bool
-	verbose  bool
-	tui      bool
+	rootPath      string
+	dryRun        bool
+	verbose       int
+	quiet         bool
+	tui           bool
+	noTUI         bool
+	accessible    bool
+	asciiOutput   bool
+	noColor       bool
+	maxErrors     int
+	maxErrorRate  float64
+	maxRenames    int
+	maxRenamesPct float64
+	failedFile    string
+	reportFile    string
+	retryFile     string
+	pathsFrom     string
+	resume        bool
+	checkpointDir string
+	quietHours    []string
+	maxLatency    time.Duration
+	latencyPause  time.Duration
+	chaosSpec     string
+	chaosSandbox  string
+	stateDir      string
+	stateMode     string
+	stateGroup    string
+	relativePaths bool
+	collationName string
+	merge         bool
+	consolidate   bool
+	hashSuffixes  bool
+	renameOrder   string
+	caseCanonical string
+	progressJSON  bool
+	progressFD    int
+	replacements  = sanitizer.DefaultReplacements()
+	whitespace    sanitizer.Whitespace
+	slugNames     bool
+	pipeline      []string
+	profileName   string
+	maxNameLength int
+	truncation    string
+	srcEncodings  []string
+	emojiMode     string
+	translitLang  string
+	confusables   bool
+	budgetPrefix  string
+	rulesVersion  int
+	classifyRules []string
+	protectNames  []string
+	noProtection  bool
+	markerFile    string
+	markerSubtree bool
+	ownerName     string
+	ownerGroup    string
+	byOwner       bool
+	oneFileSystem bool
+	linkPolicy    string
+	withAncestors bool
+	netRetries    int
+	netRetryDelay time.Duration
+	renameRetries int
+	renameDelay   time.Duration
+	checkVanished bool
+	force         bool
+	assumeYes     bool
+	wordPackFiles []string
+	ruleFiles     []string
+	replaceWords  bool
 )
 
+// runID identifies this invocation in the summary, logs, journal and every other artifact
+var runID = state.NewRunID()
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "sanitize",
@@ -36,19 +125,87 @@ with Windows naming conventions.
 Features:
 - Removes invalid Windows characters: < > : " | ? * \ /
 - Removes control characters (ASCII 0-31)
-- Trims trailing spaces and periods
+- Removes invisible and formatting characters (zero-width spaces and joiners, bidi overrides) that make names look alike
+- Trims trailing spaces and periods, also from --path and the directories above it with --ancestors
 - Handles Windows reserved names (CON, PRN, AUX, NUL, COM1-COM9, LPT1-LPT9)
 - Converts Unicode/non-ASCII characters to closest ASCII equivalents
-- Enforces 255-character length limit
-- Handles name collisions by appending numbers
+- Language-aware transliteration, e.g. ü -> ue and ß -> ss for German (--translit-locale)
+- Normalizes look-alike characters such as Cyrillic or fullwidth letters to ASCII (--confusables)
+- Strips emoji or spells them out, e.g. "🎉" -> party-popper (--emoji)
+- Decodes names that aren't valid UTF-8, e.g. Latin-1 or Shift-JIS bytes from old NAS shares (--source-encoding)
+- Enforces 255-character length limit with cut, middle, word-boundary or hash-suffix truncation
+- Handles name collisions by appending numbers, stable hash suffixes (--hash-suffixes) or merging (--merge)
+- Renames deepest folders first, or parents first with --order top-down
+- Case-only renames (FOLDER -> Folder) pass through a temporary name, so the new case sticks on NTFS and APFS
 - Dry-run mode to preview changes
-- Verbose output for detailed progress`,
+- Exit codes that tell "nothing to do" (0) from "renamed folders" (1), "completed with errors" (2) and fatal errors (3)
+- Standalone HTML reports of planned renames for review by archive owners (--report)
+- Quiet mode for cron jobs and repeatable -v for more detail
+- Accessible mode for screen readers
+- ASCII-only output for log aggregators, automatic in Windows consoles with legacy code pages
+- Error budget to abort runs against misbehaving file systems
+- Rename limits that ask before a run renames more folders than expected
+- Confirmation prompt with the number of renames and collisions before a real run (skip with --yes)
+- Failed-items export and targeted re-runs
+- Processing an explicit list of directories from a file or stdin
+- Per-run state directory for artifacts with shared permissions and group ownership
+- Root-relative paths in reports and artifacts
+- Machine-parsable JSON progress for GUI wrappers
+- Centrally maintained policy files loaded from disk or a URL
+- Profiles for stricter targets such as OneDrive/SharePoint
+- Rules versions that can be pinned, so later releases never change already-sanitized names
+- Protection for tool-owned directories (.git, node_modules, ...) and opt-out marker files
+- Ownership-scoped runs for offboarding and per-team cleanups
+- Per-owner breakdown of renames and violations for shared storage
+- Summary breakdown of renames and failures by rule and by top-level directory
+- Organization-specific reserved-word packs, reported as violations or replaced
+- Custom rules files with find/replace, strip, reserved-name and length rules around the built-in rules
+- Golden corpus tests for naming policies (rules test)
+- SARIF and GitHub Actions annotation output for check in CI
+- Risk warnings in check reports for names that comply but may still cause trouble
+- Case audit of siblings that collide on case-insensitive targets, with optional consolidation
+- Optional whitespace normalization: collapse runs of spaces and replace spaces with _ or -
+- Slug mode for web-safe, kebab-case names (My Fancy Folder! -> my-fancy-folder)
+- Configurable sanitizer pipeline: reorder, drop or chain stages and whole profiles
+- Symbolic links, junctions and volume mount points are never followed out of the tree
+- UNC network roots with retries on slow shares and a clean stop when a share disconnects
+- Folders held open by other applications are retried at the end of the run and listed if still in use
+- Scan, plan, apply and undo subcommands with reviewable plans and rename journals
+- Plan comparison that lists only the renames changed since an earlier plan
+- Interactive first-run wizard that writes a naming policy
+- Locale-aware sorting of report output
+- Staying on one file system, skipping mount points like du -x
+- Ctrl-C stops a run cleanly after the current folder, with a saved journal and partial summary
+- Resuming interrupted or crashed runs from a checkpoint without re-walking the tree
+- Quiet hours and automatic pauses while the file system is under heavy load
+- Chaos mode that injects rename failures, timeouts and collisions to rehearse runbooks
+- Watch mode that sanitizes new directories as soon as they are created
+- Rename quotas in watch mode that hold bursts of bad names for approval
+- Explain subcommand with a character-level diff of what each rule changes
+- Colored highlighting of the changed characters in rename output
+- Progress bar with throughput and ETA in the default CLI output
+- Policy reload on SIGHUP or API call in watch and serve mode
+- Optional verification pass that flags renames undone by other processes
+- Scanning progress while huge trees are walked, in the CLI and the TUI
+- Profile-tree datasets (CSV or Parquet) of name lengths, scripts and violations
+- Structured audit log of warnings, skipped directories and renames with --log-file
+- Append-only compliance log of renames with user, time and directory listing checksums
+- A run ID (ULID) shared by the summary, logs, journal and every other artifact of a run
+- Sidecar name mapping files and a restore subcommand that brings original names back
+- Original names kept in extended attributes of the renamed folders with --xattr
+- Refuses to rename in system locations such as / or C:\Windows without --force`,
 	RunE: runSanitize,
 }
 
-// runSanitize executes the main sanitization logic
-// This function orchestrates all the components following the Dependency Injection pattern
+// runSanitize executes the main sanitization logic for the root command
 func runSanitize(cmd *cobra.Command, args []string) error {
+	return runTree(dryRun, "")
+}
+
+// runTree walks the tree and renames (or, in a dry run, plans) every non-compliant folder
+// A non-empty planPath saves the renames of a dry run as a plan for a later apply
+// This function orchestrates all the components following the Dependency Injection pattern
+func runTree(dryRun bool, planPath string) error {
 	// Convert to absolute path for consistency
 	absPath, err := filepath.Abs(rootPath)
 	if err != nil {
@@ -59,18 +216,241 @@ func runSanitize(cmd *cobra.Command, args []string) error {
 	if err := validatePath(absPath); err != nil {
 		return err
 	}
+	if err := walker.ValidateOrder(renameOrder); err != nil {
+		return fmt.Errorf("--order: %w", err)
+	}
+	if !dryRun {
+		if err := refuseSystemLocation(absPath); err != nil {
+			return err
+		}
+		// The TUI takes over the terminal, so there is nowhere to ask before renaming
+		if tui && !accessible && !assumeYes {
+			return fmt.Errorf("--tui can't ask for confirmation before renaming; pass --yes, or review the renames with --dry-run first")
+		}
+	}
 
-	// Create the dependency chain following SOLID principles
-	folderSanitizer := sanitizer.NewWindowsSanitizer()
-	directoryWalker := walker.NewFileSystemWalker(true, 0) // Skip inaccessible, no depth limit
-	folderProcessor := processor.NewFileSystemProcessor(1000)
+	// The earlier plan is read up front, so a wrong --compare fails before the walk
+	var earlierPlan *journal.File
+	if planPath != "" && planCompare != "" {
+		if earlierPlan, err = journal.Load(planCompare, journal.KindPlan, absPath); err != nil {
+			return err
+		}
+	}
 
-	// Create the appropriate reporter based on flags
-	var progressReporter interfaces.ProgressReporter
-	if tui {
-		progressReporter = reporter.NewTUIReporter(dryRun)
+	// Create the dependency chain following SOLID principles
+	folderSanitizer, err := newFolderSanitizer()
+	if err != nil {
+		return err
+	}
+	// Plans and journals record the rules version their names came from, and the order they were renamed in
+	namesVersion, _ := sanitizer.LookupRulesVersion(rulesVersion)
+	folderOrder := renameOrder
+	if countSet(retryFile, pathsFrom, planFile) > 1 {
+		return fmt.Errorf("only one of --retry-file, --paths-from and --plan can be used")
+	}
+	if resume && countSet(retryFile, pathsFrom, planFile) > 0 {
+		return fmt.Errorf("--resume can't be combined with --retry-file, --paths-from or --plan")
+	}
+	var directoryWalker interfaces.DirectoryWalker
+	if resume || retryFile != "" || pathsFrom != "" || planFile != "" {
+		// Process exactly the listed items instead of scanning the tree
+		var listedFolders []interfaces.FolderInfo
+		switch {
+		case resume:
+			listedFolders, err = resumeFolders(absPath)
+		case retryFile != "":
+			listedFolders, err = failures.Load(retryFile, absPath)
+		case pathsFrom != "":
+			listedFolders, err = readPathList(pathsFrom, absPath)
+		default:
+			// A reviewed plan is applied as planned, whatever the current rules would say
+			var plan *journal.File
+			if plan, err = journal.Load(planFile, journal.KindPlan, absPath); err == nil {
+				listedFolders = plan.Folders()
+				folderSanitizer = plan.Sanitizer()
+				namesVersion = plan.RulesVersion
+				// Paths below renamed parents depend on the order, so a plan is applied in its own; older plans are bottom-up
+				folderOrder = walker.OrderBottomUp
+				if plan.Order != "" {
+					folderOrder = plan.Order
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if len(classifyRules) > 0 && planFile == "" {
+			summarizeListedFolders(listedFolders)
+		}
+		directoryWalker = walker.NewListWalker(listedFolders)
 	} else {
-		progressReporter = reporter.NewCLIReporter(verbose, dryRun)
+		options, err := walkerOptions()
+		if err != nil {
+			return err
+		}
+		directoryWalker = walker.NewFileSystemWalker(true, 0, options...) // Skip inaccessible, no depth limit
+	}
+	// The root and the directories above it are checked for trailing spaces and periods too; plans already list them
+	if withAncestors && planFile == "" {
+		checker := ancestors.NewChecker()
+		directoryWalker = checker.Walker(directoryWalker)
+		folderSanitizer = checker.Sanitizer(folderSanitizer)
+	}
+	// Reordering wraps the walker itself, so checkpoints list the folders in the order they are processed
+	directoryWalker = walker.NewOrderedWalker(directoryWalker, folderOrder)
+	// Siblings that differ only in case are merged into one spelling; plans already record those merges
+	if consolidate {
+		if planFile != "" {
+			return fmt.Errorf("--consolidate-case can't be combined with --plan; plan with --consolidate-case instead")
+		}
+		auditor, err := casing.NewAuditor(caseCanonical)
+		if err != nil {
+			return fmt.Errorf("--case-canonical: %w", err)
+		}
+		directoryWalker = auditor.Walker(directoryWalker)
+		folderSanitizer = auditor.Sanitizer(folderSanitizer)
+	}
+	// Chaos mode makes some renames fail on purpose to rehearse failure handling
+	injector, err := newChaosInjector(absPath, dryRun)
+	if err != nil {
+		return err
+	}
+	var folderProcessor interfaces.FolderProcessor
+	switch {
+	case injector == nil:
+		folderProcessor = newFolderProcessor(newFileSystem())
+	case dryRun:
+		folderProcessor = injector.Processor(newFolderProcessor(newFileSystem()))
+	default:
+		folderProcessor = newFolderProcessor(injector.FileSystem(newFileSystem()))
+	}
+
+	// Only real runs touch the file system enough to need pausing
+	var pacer interfaces.Pacer
+	if !dryRun {
+		if pacer, err = newPacer(); err != nil {
+			return err
+		}
+	}
+
+	// Verification lists the affected parents again once the run is over, bypassing any chaos faults
+	var verifier interfaces.RenameVerifier
+	if verifyAfter && !dryRun {
+		verifier = journal.NewVerifier(newFileSystem())
+	}
+
+	progressReporter, closeReporter, err := newProgressReporter(dryRun)
+	if err != nil {
+		return err
+	}
+	defer closeReporter()
+	progressReporter = withLogReporter(progressReporter, dryRun)
+	hold, _ := progressReporter.(interfaces.Hold) // The TUI pauses the run with space, also behind a MultiReporter
+	closable, _ := progressReporter.(closableReporter)
+
+	// Centralize artifacts in a per-run state directory when requested
+	var runState *state.Dir
+	if stateDir != "" {
+		mode, err := state.ParseMode(stateMode)
+		if err != nil {
+			return err
+		}
+		if runState, err = state.New(stateDir, runID, mode, stateGroup); err != nil {
+			return err
+		}
+		if failedFile == "" {
+			failedFile = "failed.json" // Always keep failed items with the run's artifacts
+		}
+		failedFile = runState.ArtifactPath(failedFile)
+	}
+
+	// Compliance audits record every performed rename with checksums of the directory listing
+	var auditLog *audit.Log
+	if auditFile != "" && !dryRun {
+		if auditLog, err = audit.Open(auditFile, newFileSystem(), runID); err != nil {
+			return err
+		}
+		folderProcessor = auditLog.Processor(folderProcessor)
+	}
+
+	// Mapping files record original names next to the renamed folders for a later restore
+	sidecarRecorder, err := newSidecarRecorder(absPath, dryRun)
+	if err != nil {
+		return err
+	}
+	if sidecarRecorder != nil {
+		folderProcessor = sidecarRecorder.Processor(folderProcessor)
+	}
+
+	// Extended attributes keep the original name on the renamed folder itself
+	folderProcessor, xattrFailures, err := withXattr(folderProcessor, dryRun)
+	if err != nil {
+		return err
+	}
+
+	// Dry runs can save their renames as a plan, real runs as a journal for undo
+	recordFile, recordKind := journalFile, journal.KindJournal
+	if dryRun {
+		recordFile, recordKind = planPath, journal.KindPlan
+	}
+	if runState != nil {
+		if recordFile == "" && !dryRun {
+			recordFile = "journal.json" // Always keep the journal with the run's artifacts
+		}
+		if recordFile != "" {
+			recordFile = runState.ArtifactPath(recordFile)
+		}
+	}
+
+	var renameRecorder *journal.Recorder
+	if recordFile != "" {
+		renameRecorder = journal.NewRecorder(progressReporter)
+		progressReporter = renameRecorder
+	}
+
+	// Collect failed items for export when requested
+	var failureRecorder *failures.Recorder
+	if failedFile != "" {
+		failureRecorder = failures.NewRecorder(progressReporter)
+		progressReporter = failureRecorder
+	}
+
+	// An HTML report lets people who don't use the command line review the renames
+	var reportRecorder *htmlreport.Recorder
+	if reportFile != "" {
+		if runState != nil {
+			reportFile = runState.ArtifactPath(reportFile)
+		}
+		reportRecorder = htmlreport.NewRecorder(progressReporter)
+		progressReporter = reportRecorder
+	}
+
+	// Real runs keep a checkpoint so an interrupted or crashed run can continue with --resume
+	var checkpointRecorder *checkpoint.Recorder
+	if !dryRun && checkpointDir != "" {
+		var checkpointOptions []checkpoint.Option
+		if resume {
+			checkpointOptions = append(checkpointOptions, checkpoint.WithResumedRun())
+		}
+		checkpointRecorder = checkpoint.NewRecorder(progressReporter, checkpoint.Path(checkpointDir, absPath), checkpoint.Header{
+			RunID: runID,
+			Root:  absPath,
+		}, checkpointOptions...)
+		progressReporter = checkpointRecorder
+		directoryWalker = checkpointRecorder.Walker(directoryWalker)
+	}
+
+	// Ctrl-C stops the run between folders so the journal and failures below are still saved
+	interrupt, stopInterrupt := notifyInterrupt("Interrupted: finishing the current folder and saving the journal. Press Ctrl-C again to stop immediately.")
+	defer stopInterrupt()
+	if closable != nil && closable.Closed() != nil {
+		interrupt = eitherClosed(interrupt, closable.Closed()) // Quitting the TUI stops the run the same way
+	}
+
+	// Real runs show what they will do and wait for a yes first, unless --yes was given
+	var planner interfaces.FolderProcessor
+	if !dryRun && !assumeYes {
+		planner = newFolderProcessor(newFileSystem())
 	}
 
 	// Create the main service with all dependencies injected
@@ -79,10 +459,20 @@ func runSanitize(cmd *cobra.Command, args []string) error {
 		directoryWalker,
 		folderProcessor,
 		progressReporter,
+		service.WithMaxErrors(maxErrors),
+		service.WithMaxErrorRate(maxErrorRate),
+		service.WithMaxRenames(maxRenames, maxRenamesPct, confirmRenames),
+		service.WithConfirmation(planner, confirmPlan),
+		service.WithRelativePaths(relativePaths),
+		service.WithInterrupt(interrupt),
+		service.WithPacer(pacer),
+		service.WithHold(hold),
+		service.WithVerifier(verifier),
+		service.WithRunID(runID),
 	)
 
-	// Report the start of processing
-	if verbose {
+	// Report the start of processing (stdout is reserved for JSON records with --progress-json)
+	if verbosity >= reporter.VerbosityInfo && !progressJSON {
 		fmt.Printf("Starting sanitization of directory tree: %s\n", absPath)
 		if dryRun {
 			fmt.Println("DRY RUN MODE: No changes will be made")
@@ -91,17 +481,497 @@ func runSanitize(cmd *cobra.Command, args []string) error {
 
 	// Execute the sanitization process
 	err = sanitizeService.SanitizeDirectory(absPath, dryRun)
+	xattrFailures.report()
+	// Mapping files are written even if the run failed, so whatever was renamed can be restored
+	if sidecarRecorder != nil {
+		if saveErr := sidecarRecorder.Save(); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}
+	// A rename missing from the audit log fails the run, even if the rename itself succeeded
+	if auditLog != nil {
+		if closeErr := auditLog.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	// Save the renames even if the run failed, so whatever was renamed can be undone
+	if renameRecorder != nil {
+		if saveErr := renameRecorder.Save(recordFile, journal.Header{
+			Kind:          recordKind,
+			RunID:         runID,
+			Root:          absPath,
+			RelativePaths: relativePaths,
+			RulesVersion:  namesVersion,
+			Order:         folderOrder,
+		}); saveErr != nil {
+			return saveErr
+		}
+		if runState != nil {
+			if saveErr := runState.Finalize(recordFile); saveErr != nil {
+				return saveErr
+			}
+		}
+		// The saved plan is read back, so both plans are resolved against the root the same way
+		if earlierPlan != nil {
+			laterPlan, loadErr := journal.Load(recordFile, journal.KindPlan, absPath)
+			if loadErr != nil {
+				return loadErr
+			}
+			printPlanComparison(journal.Compare(earlierPlan.Entries, laterPlan.Entries), absPath)
+		}
+	}
+
+	// Export failures even if the run itself failed so they can be retried later
+	if failureRecorder != nil {
+		if saveErr := failureRecorder.Save(failedFile, failures.Header{
+			RunID:         runID,
+			Root:          absPath,
+			RelativePaths: relativePaths,
+		}); saveErr != nil {
+			return saveErr
+		}
+		if runState != nil {
+			if saveErr := runState.Finalize(failedFile); saveErr != nil {
+				return saveErr
+			}
+		}
+	}
+
+	// The report is written even if the run failed, so a partial run can be reviewed too
+	if reportRecorder != nil {
+		if saveErr := reportRecorder.Save(reportFile, htmlreport.Header{
+			RunID:         runID,
+			Root:          absPath,
+			DryRun:        dryRun,
+			RelativePaths: relativePaths,
+		}); saveErr != nil {
+			return saveErr
+		}
+		if runState != nil {
+			if saveErr := runState.Finalize(reportFile); saveErr != nil {
+				return saveErr
+			}
+		}
+	}
+
+	// The checkpoint is removed once every folder was processed, or when the run stopped before the first one
+	if checkpointRecorder != nil {
+		kept, saveErr := checkpointRecorder.Finish()
+		if saveErr != nil {
+			return saveErr
+		}
+		if kept && checkpointRecorder.Started() {
+			fmt.Fprintf(os.Stderr, "Progress saved. Continue this run with --resume --path %s\n", absPath)
+		}
+	}
+
+	return runOutcome(sanitizeService.Summary(), err)
+}
+
+// newPacer creates the pacer configured by the quiet-hours and latency flags, or nil when neither is set
+func newPacer() (interfaces.Pacer, error) {
+	if len(quietHours) == 0 && maxLatency <= 0 {
+		return nil, nil
+	}
+
+	windows := make([]schedule.Window, 0, len(quietHours))
+	for _, text := range quietHours {
+		window, err := schedule.ParseWindow(text)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+
+	return schedule.NewPacer(
+		schedule.WithQuietHours(windows...),
+		schedule.WithLatencyLimit(maxLatency, latencyPause),
+	), nil
+}
+
+// newChaosInjector creates the fault injector configured by --chaos, or nil when chaos mode is off
+// Real runs only inject faults below --chaos-sandbox, so a rehearsal can never damage production data
+func newChaosInjector(root string, dryRun bool) (*chaos.Injector, error) {
+	if chaosSpec == "" {
+		return nil, nil
+	}
+
+	config, err := chaos.ParseConfig(chaosSpec)
 	if err != nil {
-		return fmt.Errorf("error during sanitization: %w", err)
+		return nil, err
+	}
+	if !dryRun {
+		if chaosSandbox == "" {
+			return nil, fmt.Errorf("--chaos needs --dry-run or a --chaos-sandbox that contains --path")
+		}
+		sandbox, err := filepath.Abs(chaosSandbox)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving chaos sandbox: %w", err)
+		}
+		if !paths.Within(sandbox, root) {
+			return nil, fmt.Errorf("--chaos only renames inside the sandbox %s, not %s", sandbox, root)
+		}
 	}
 
-	return nil
+	faults := make([]string, len(config.Faults))
+	for i, fault := range config.Faults {
+		faults[i] = string(fault)
+	}
+	fmt.Fprintf(os.Stderr, "Chaos mode: %g%% of renames fail with %s (seed %d)\n",
+		config.Rate*100, strings.Join(faults, ", "), config.Seed)
+	return chaos.NewInjector(config), nil
+}
+
+// resumeFolders returns the folders the interrupted run on root did not finish, in their original order
+func resumeFolders(root string) ([]interfaces.FolderInfo, error) {
+	if checkpointDir == "" {
+		return nil, fmt.Errorf("--resume needs a --checkpoint-dir")
+	}
+
+	progress, err := checkpoint.Load(checkpoint.Path(checkpointDir, root))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no interrupted run to resume for %s", root)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if progress.Root != root {
+		return nil, fmt.Errorf("checkpoint belongs to %s, not %s", progress.Root, root)
+	}
+
+	remaining := progress.Remaining(newFileSystem())
+	if verbosity > reporter.VerbosityQuiet && !progressJSON {
+		fmt.Printf("Resuming run %s: %d of %d folders remain\n", progress.RunID, len(remaining), len(progress.Folders))
+	}
+	return remaining, nil
+}
+
+// defaultCheckpointDir returns the per-user directory for checkpoints of interrupted runs
+func defaultCheckpointDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "sanitize", "checkpoints")
+}
+
+// newProgressReporter creates the reporter selected by the output flags
+// The returned function closes the TUI and the progress descriptor, if they were opened
+func newProgressReporter(dryRun bool) (interfaces.ProgressReporter, func(), error) {
+	collator, err := collation.New(collationName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Machine-parsable progress for GUI wrappers: stdout replaces human output, a descriptor adds to it
+	if progressJSON && progressFD < 0 {
+		return reporter.NewJSONReporter(os.Stdout), func() {}, nil
+	}
+	var progressFile *os.File
+	if progressFD >= 0 {
+		if progressFile = os.NewFile(uintptr(progressFD), "progress"); progressFile == nil {
+			return nil, nil, fmt.Errorf("invalid progress descriptor %d", progressFD)
+		}
+	}
+
+	var progressReporter interfaces.ProgressReporter
+	closeReporter := func() {}
+	if accessible {
+		// Accessible mode takes precedence over the alt-screen TUI
+		progressReporter = reporter.NewAccessibleReporter(verbosity, dryRun, collator)
+	} else if tui {
+		tuiReporter := reporter.NewTUIReporter(dryRun, asciiOutput, collator)
+		progressReporter, closeReporter = tuiReporter, tuiReporter.Close
+	} else {
+		progressReporter = reporter.NewCLIReporter(verbosity, dryRun, collator, reporter.WithColor(colorOutput()), reporter.WithTerminal(stdoutIsTerminal()))
+	}
+
+	if progressFile != nil {
+		// JSON comes first, so its final record is written before the TUI waits for the user to quit
+		multi := reporter.NewMultiReporter(reporter.NewJSONReporter(progressFile), progressReporter)
+		return multi, func() { closeReporter(); progressFile.Close() }, nil
+	}
+
+	return progressReporter, closeReporter, nil
+}
+
+// countSet returns how many of the given flag values are non-empty
+func countSet(values ...string) int {
+	count := 0
+	for _, value := range values {
+		if value != "" {
+			count++
+		}
+	}
+	return count
+}
+
+// newFolderSanitizer creates the sanitizer configured by the profile, replacement, word pack and classification flags
+// Templates, packs and rules are validated up front so a typo fails the run before anything is renamed
+func newFolderSanitizer() (interfaces.FolderSanitizer, error) {
+	if err := replacements.Validate(); err != nil {
+		return nil, err
+	}
+	if err := whitespace.Validate(); err != nil {
+		return nil, err
+	}
+
+	packs := make([]*wordpack.Pack, 0, len(wordPackFiles))
+	for _, path := range wordPackFiles {
+		pack, err := wordpack.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, pack)
+	}
+
+	rulesets := make([]*ruleset.Ruleset, 0, len(ruleFiles))
+	for _, path := range ruleFiles {
+		rules, err := ruleset.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		rulesets = append(rulesets, rules)
+	}
+
+	folderSanitizer, err := newProfileSanitizer(profileName, packs, rulesets)
+	if err != nil {
+		return nil, err
+	}
+	if len(classifyRules) == 0 {
+		return folderSanitizer, nil
+	}
+
+	routes := make([]classify.Route, 0, len(classifyRules))
+	for _, spec := range classifyRules {
+		rule, err := classify.ParseRule(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		route := classify.Route{Rule: rule}
+		if rule.Action == classify.ActionProfile {
+			if route.Sanitizer, err = newProfileSanitizer(rule.Profile, packs, rulesets); err != nil {
+				return nil, fmt.Errorf("classification rule %q: %w", spec, err)
+			}
+		}
+		routes = append(routes, route)
+	}
+
+	return classify.NewSanitizer(folderSanitizer, routes), nil
+}
+
+// newProfileSanitizer creates a sanitizer for the named profile with the configured replacements
+// Reserved-word packs are checked before the profile's own rules, and custom rulesets wrap them
+func newProfileSanitizer(name string, packs []*wordpack.Pack, rulesets []*ruleset.Ruleset) (interfaces.FolderSanitizer, error) {
+	profile, err := sanitizer.LookupProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	if maxNameLength < 0 || (maxNameLength > 0 && maxNameLength < sanitizer.MinNameLength) {
+		return nil, fmt.Errorf("--max-name-length must be 0 (profile default) or at least %d", sanitizer.MinNameLength)
+	}
+	if maxNameLength > 0 {
+		profile.MaxNameLength = maxNameLength
+	}
+	if err := sanitizer.ValidateTruncation(truncation); err != nil {
+		return nil, fmt.Errorf("--truncate: %w", err)
+	}
+	if err := sanitizer.ValidateSourceEncodings(srcEncodings); err != nil {
+		return nil, fmt.Errorf("--source-encoding: %w", err)
+	}
+	if err := sanitizer.ValidateEmoji(emojiMode); err != nil {
+		return nil, fmt.Errorf("--emoji: %w", err)
+	}
+	if err := sanitizer.ValidateTransliterationLocale(translitLang); err != nil {
+		return nil, fmt.Errorf("--translit-locale: %w", err)
+	}
+	for _, rules := range rulesets {
+		profile = rules.ApplyProfile(profile)
+	}
+	if budgetPrefix != "" && sanitizer.PathBudget(profile.MaxPathLength, budgetPrefix) < 0 {
+		return nil, fmt.Errorf("--path-budget-prefix %s leaves no room below the path limit of profile %s", budgetPrefix, profile.Name)
+	}
+	version, err := sanitizer.LookupRulesVersion(rulesVersion)
+	if err != nil {
+		return nil, fmt.Errorf("--rules-version: %w", err)
+	}
+	options := []sanitizer.Option{
+		sanitizer.WithRulesVersion(version),
+		sanitizer.WithProfile(profile),
+		sanitizer.WithReplacements(replacements),
+		sanitizer.WithPathPrefix(budgetPrefix),
+		sanitizer.WithWhitespace(whitespace),
+		sanitizer.WithSlug(slugNames),
+		sanitizer.WithTruncation(truncation),
+		sanitizer.WithSourceEncodings(srcEncodings),
+		sanitizer.WithEmoji(emojiMode),
+		sanitizer.WithTransliterationLocale(translitLang),
+		sanitizer.WithConfusables(confusables),
+	}
+	var folderSanitizer interfaces.FolderSanitizer
+	if len(pipeline) > 0 {
+		if folderSanitizer, err = sanitizer.NewPipeline(pipeline, options...); err != nil {
+			return nil, fmt.Errorf("--pipeline: %w", err)
+		}
+	} else {
+		folderSanitizer = sanitizer.NewWindowsSanitizer(options...)
+	}
+	if len(rulesets) > 0 {
+		folderSanitizer = ruleset.NewSanitizer(folderSanitizer, rulesets)
+	}
+	if len(packs) == 0 {
+		return folderSanitizer, nil
+	}
+	return wordpack.NewSanitizer(folderSanitizer, packs, replaceWords), nil
+}
+
+// walkerOptions returns the walker options required by the configured flags
+// Classification rules need a summary of every folder's contents
+func walkerOptions() ([]walker.Option, error) {
+	ownerFilter, err := walker.NewOwnerFilter(ownerName, ownerGroup)
+	if err != nil {
+		return nil, err
+	}
+	if byOwner && !walker.OwnershipSupported() {
+		return nil, walker.ErrOwnershipUnsupported
+	}
+	if oneFileSystem && !walker.OneFileSystemSupported() {
+		return nil, walker.ErrOneFileSystemUnsupported
+	}
+	if err := walker.ValidateLinks(linkPolicy); err != nil {
+		return nil, err
+	}
+
+	return []walker.Option{
+		walker.WithFileSystem(newFileSystem()),
+		walker.WithContentSummary(len(classifyRules) > 0),
+		walker.WithProtection(newProtection()),
+		walker.WithOwnerFilter(ownerFilter),
+		walker.WithOwnerAttribution(byOwner),
+		walker.WithOneFileSystem(oneFileSystem),
+		walker.WithLinks(linkPolicy),
+		walker.WithLogger(logger),
+	}, nil
+}
+
+// newProtection returns the protected directories configured by the protection flags
+func newProtection() walker.Protection {
+	protection := walker.DefaultProtection()
+	if noProtection {
+		protection.Names = nil
+	}
+	protection.Names = append(protection.Names, protectNames...)
+	protection.MarkerFile = markerFile
+	protection.MarkerSubtree = markerSubtree
+	return protection
+}
+
+// readPathList reads the directories listed in a file, or on stdin for "-", resolving them against root
+func readPathList(source, root string) ([]interfaces.FolderInfo, error) {
+	if source == "-" {
+		return walker.ReadPathList(os.Stdin, root)
+	}
+
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, fmt.Errorf("error opening path list: %w", err)
+	}
+	defer file.Close()
+
+	return walker.ReadPathList(file, root)
+}
+
+// summarizeListedFolders attaches content summaries to listed folders so classification rules still apply
+// Folders that can't be read keep a nil summary; processing them reports the underlying error
+func summarizeListedFolders(folders []interfaces.FolderInfo) {
+	fileSystem := newFileSystem()
+	for i := range folders {
+		folders[i].Contents, _ = walker.SummarizeFolder(fileSystem, folders[i].Path)
+	}
+}
+
+// addRunFlags registers the flags shared by every command that renames (or plans renames in) a tree
+func addRunFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&maxErrors, "max-errors", 0, "Abort the run once more than N errors occurred (0 = unlimited)")
+	cmd.Flags().Float64Var(&maxErrorRate, "max-error-rate", 0, "Abort the run once the error percentage (0-100) exceeds this value (0 = unlimited)")
+	cmd.Flags().IntVar(&maxRenames, "max-renames", 0, "Ask for confirmation before renaming more than N folders, and abort without it (0 = unlimited)")
+	cmd.Flags().Float64Var(&maxRenamesPct, "max-renames-percent", 0, "Ask for confirmation before renaming more than this percentage (0-100) of the folders, and abort without it (0 = unlimited)")
+	cmd.Flags().StringVar(&failedFile, "failed-file", "", "Write folders that failed to process to this JSON file")
+	cmd.Flags().StringVar(&reportFile, "report", "", "Write a standalone HTML report of the renames, the rules behind them, resolved collisions and a summary to this file, e.g. for review before approving a run")
+	cmd.Flags().StringVar(&retryFile, "retry-file", "", "Process only the folders listed in a previous --failed-file instead of scanning the tree")
+	cmd.Flags().StringVar(&pathsFrom, "paths-from", "", "Process only the directories listed in this file, one per line (- = stdin); relative paths are resolved against --path")
+	cmd.Flags().BoolVar(&resume, "resume", false, "Continue the interrupted run on --path from its checkpoint instead of scanning the tree")
+	cmd.Flags().StringVar(&checkpointDir, "checkpoint-dir", defaultCheckpointDir(), "Where real runs keep the checkpoint used by --resume (empty = no checkpoints)")
+	cmd.Flags().StringArrayVar(&quietHours, "quiet-hours", nil, "Pause renaming during this daily local time window, e.g. 07:00-19:00 (repeatable)")
+	cmd.Flags().DurationVar(&maxLatency, "max-latency", 0, "Pause renaming once renames take longer than this on average, a sign of heavy user load (0 = never)")
+	cmd.Flags().DurationVar(&latencyPause, "latency-pause", 5*time.Minute, "How long to pause once --max-latency is exceeded")
+	cmd.Flags().StringVar(&chaosSpec, "chaos", "", "Rehearsal mode: fail renames on purpose, e.g. rate=0.01,seed=42,faults=failure+timeout+collision (needs --dry-run or --chaos-sandbox)")
+	cmd.Flags().StringVar(&chaosSandbox, "chaos-sandbox", "", "Directory that must contain --path before --chaos may rename anything")
+	cmd.Flags().BoolVar(&merge, "merge", false, "Merge a folder into an existing folder with the sanitized name instead of appending _1, _2, ...")
+	cmd.Flags().BoolVar(&hashSuffixes, "hash-suffixes", false, "Resolve name collisions with a suffix derived from a hash of the original name (_3f9a12) instead of _1, _2, ..., so every run produces the same names")
+	cmd.Flags().StringVar(&renameOrder, "order", walker.OrderBottomUp, "Order folders are renamed in: bottom-up (deepest first) or top-down (parents first, so shortened parents shorten the paths of their children)")
+	cmd.Flags().BoolVar(&consolidate, "consolidate-case", false, "Merge siblings whose names differ only in case into one folder (implies --merge)")
+	addCaseCanonicalFlag(cmd)
+}
+
+// addCaseCanonicalFlag registers the flag choosing which spelling of a case conflict is kept
+func addCaseCanonicalFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&caseCanonical, "case-canonical", casing.Largest, "Spelling kept for siblings that differ only in case: largest (most subfolders) or lower")
+}
+
+// confirmRenames lets the user approve a run over the --max-renames limits when there is a terminal to ask on
+// Unattended runs are never approved, so a wrong --path in a scheduled job renames nothing
+func confirmRenames(reason string) bool {
+	if !canPrompt() {
+		fmt.Fprintf(os.Stderr, "%s. Re-run with a higher --max-renames or --max-renames-percent to rename anyway.\n", reason)
+		return false
+	}
+	declined = !confirm(reason + ". Rename anyway?")
+	return !declined
+}
+
+// confirmPlan shows how many folders a real run will rename and asks whether to go ahead
+// Without a terminal to ask on, only --yes lets the run rename anything
+func confirmPlan(renames, collisions int) bool {
+	summary := fmt.Sprintf("%d folders will be renamed (%d collisions resolved)", renames, collisions)
+	if !canPrompt() {
+		fmt.Fprintf(os.Stderr, "%s. Pass --yes to rename without confirmation.\n", summary)
+		return false
+	}
+	declined = !confirm(summary + ". Continue?")
+	return !declined
+}
+
+// newFolderProcessor creates the processor configured by the collision and retry flags on fileSystem
+func newFolderProcessor(fileSystem interfaces.FileSystem) interfaces.FolderProcessor {
+	return processor.NewFileSystemProcessor(1000, // Safety limit for collision suffixes
+		processor.WithMergeOnCollision(merge || consolidate),
+		processor.WithFileSystem(fileSystem),
+		processor.WithRenameRetries(renameRetries, renameDelay),
+		processor.WithConfirmVanished(checkVanished),
+		processor.WithHashSuffixes(hashSuffixes),
+	)
+}
+
+// newFileSystem returns the file system backend used by every command
+// Network errors are retried as configured, so slow or briefly stale shares don't fail the run
+func newFileSystem() interfaces.FileSystem {
+	return filesystem.NewNetworkFileSystem(filesystem.NewOSFileSystem(), netRetries, netRetryDelay)
 }
 
 // validatePath ensures the provided path exists and is a directory
 // This function provides early validation to prevent unnecessary processing
 func validatePath(path string) error {
-	info, err := os.Stat(path)
+	if server, share, _, ok := paths.SplitUNC(path); ok && (server == "" || share == "") {
+		return fmt.Errorf("network path %s must name a server and a share, e.g. \\\\server\\share", path)
+	}
+
+	info, err := newFileSystem().Stat(path)
+	if errors.Is(err, interfaces.ErrFileSystemUnavailable) {
+		return fmt.Errorf("network path %s is unreachable: %w", path, err)
+	}
 	if err != nil {
 		return fmt.Errorf("error accessing path %s: %w", path, err)
 	}
@@ -113,20 +983,119 @@ func validatePath(path string) error {
 	return nil
 }
 
+// refuseSystemLocation stops commands that rename folders from running on /, C:\Windows, a home directory and the like
+// The path counts as it is given and as its symbolic links resolve; --force overrides the check
+func refuseSystemLocation(path string) error {
+	if force {
+		return nil
+	}
+
+	home, _ := os.UserHomeDir()
+	locations := paths.SystemLocations(home)
+	candidates := []string{path}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil && resolved != path {
+		candidates = append(candidates, resolved)
+	}
+	for _, candidate := range candidates {
+		if reason := paths.SystemLocation(candidate, locations); reason != "" {
+			return fmt.Errorf("refusing to rename folders in %s, which is %s; pass --force if you really mean it", candidate, reason)
+		}
+	}
+	return nil
+}
+
+// addYesFlag registers --yes on a command that asks before renaming
+func addYesFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Rename without asking for confirmation (needed when stdin is not a terminal)")
+}
+
+// addForceFlag registers --force on a command that renames folders
+func addForceFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&force, "force", false, "Rename folders even if --path is a volume root, a system directory or a home directory")
+}
+
 // init initializes the CLI flags and configuration
 // This function sets up the Cobra command structure
 func init() {
-	// Define command flags with appropriate defaults and help text
-	rootCmd.Flags().StringVarP(&rootPath, "path", "p", ".", "Root path to sanitize")
+	// Output and artifact flags are shared by every subcommand
+	rootCmd.PersistentFlags().StringVarP(&rootPath, "path", "p", ".", "Root path of the folder tree")
+	rootCmd.PersistentFlags().CountVarP(&verbose, "verbose", "v", "Show more output; repeat for more detail: -v renames, -vv every folder, -vvv every directory read")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Print only errors, and the summary only if something was renamed or failed")
+	rootCmd.PersistentFlags().BoolVarP(&tui, "tui", "t", false, "Use Terminal UI (Bubble Tea) for interactive progress (plain output when stdout is not a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&noTUI, "no-tui", false, "Never use the Terminal UI, even if --tui is set, e.g. by a policy file or an alias")
+	rootCmd.PersistentFlags().BoolVar(&accessible, "accessible", false, "Screen-reader friendly output: no TUI, emoji or color, one plain sentence per event")
+	rootCmd.PersistentFlags().BoolVar(&asciiOutput, "ascii-output", false, "Replace emoji and box-drawing decorations with plain ASCII (automatic in Windows consoles with a legacy code page)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Don't highlight changed characters in renames (color is also off when stdout is not a terminal or NO_COLOR is set)")
+	rootCmd.PersistentFlags().BoolVar(&relativePaths, "relative-paths", false, "Show and store paths relative to the root; retry files, plans and journals are resolved against --path")
+	rootCmd.PersistentFlags().StringVar(&collationName, "collation", collation.Binary, "Order names in reports by this locale's collation rules, e.g. und, de or sv (binary = byte order)")
+	rootCmd.PersistentFlags().StringVar(&stateDir, "state-dir", "", "Write run artifacts to a per-run subdirectory of this directory (file names include the run ID)")
+	rootCmd.PersistentFlags().StringVar(&stateMode, "state-mode", "0750", "Octal permissions for the per-run state directory; artifacts get the same bits without execute")
+	rootCmd.PersistentFlags().StringVar(&stateGroup, "state-group", "", "Group name or ID that owns the state directory and its artifacts")
+	rootCmd.PersistentFlags().BoolVar(&progressJSON, "progress-json", false, "Write JSON Lines progress records to stdout instead of human-readable output")
+	rootCmd.PersistentFlags().IntVar(&progressFD, "progress-fd", -1, "Also write JSON Lines progress records to this open file descriptor")
+
+	// Running the root command directly keeps the original flag-based interface working
 	rootCmd.Flags().BoolVarP(&dryRun, "dry-run", "d", false, "Show what would be renamed without making changes")
-	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
-	rootCmd.Flags().BoolVarP(&tui, "tui", "t", false, "Use Terminal UI (Bubble Tea) for interactive progress")
+	rootCmd.Flags().StringVar(&journalFile, "journal", "", "Write the renames of a real run to this JSON journal for undo")
+	rootCmd.Flags().BoolVar(&verifyAfter, "verify", false, "Re-check every applied rename after a real run and list discrepancies in the summary")
+	addForceFlag(rootCmd)
+	addYesFlag(rootCmd)
+	rootCmd.Flags().StringVar(&auditFile, "audit-log", "", "Append every rename of a real run with user, time and checksums of the directory listing to this file")
+	addRunFlags(rootCmd)
+
+	// Protected directories apply to every command that walks a tree
+	rootCmd.PersistentFlags().StringArrayVar(&protectNames, "protect", nil, "Additional directory name never renamed or descended into (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&noProtection, "no-default-protection", false, "Don't protect .git, .svn, .hg, node_modules, __pycache__, .venv and target")
+	rootCmd.PersistentFlags().StringVar(&markerFile, "marker-file", walker.DefaultProtection().MarkerFile, "Directories containing this file are not renamed; a file containing \"subtree\" exempts the whole subtree (empty = disabled)")
+	rootCmd.PersistentFlags().BoolVar(&markerSubtree, "marker-subtree", false, "Every marker file exempts its whole subtree, not just its directory")
+
+	// Ownership filters scope every command that walks a tree
+	rootCmd.PersistentFlags().StringVar(&ownerName, "owner", "", "Only process directories owned by this user name or ID")
+	rootCmd.PersistentFlags().StringVar(&ownerGroup, "group", "", "Only process directories owned by this group name or ID")
+	rootCmd.PersistentFlags().BoolVar(&byOwner, "by-owner", false, "Break renames and violations down by directory owner in the summary")
+	rootCmd.PersistentFlags().BoolVarP(&oneFileSystem, "one-file-system", "x", false, "Don't descend into directories on other file systems (mount points), like du -x")
+	rootCmd.PersistentFlags().StringVar(&linkPolicy, "links", walker.LinksSkip, "Links to directories (symlinks, junctions, volume mount points) are never descended into: skip or rename them")
+	rootCmd.PersistentFlags().BoolVar(&withAncestors, "ancestors", false, "Also check --path itself and every directory above it for trailing spaces and periods (sanitize and check)")
+
+	// Network shares can be slow or stale; reads are retried before the share is considered gone
+	rootCmd.PersistentFlags().IntVar(&netRetries, "network-retries", 3, "Retry reads that fail with network errors this many times before giving up")
+	rootCmd.PersistentFlags().DurationVar(&netRetryDelay, "network-retry-delay", time.Second, "Delay before the first network retry; doubled for every further attempt")
+
+	// Folders briefly held open by scanners or SMB clients can usually be renamed a moment later
+	rootCmd.PersistentFlags().IntVar(&renameRetries, "rename-retries", 3, "Retry renames that fail with transient errors (file in use, network hiccup) this many times")
+	rootCmd.PersistentFlags().DurationVar(&renameDelay, "rename-retry-delay", 200*time.Millisecond, "Delay before the first rename retry; doubled for every further attempt")
+	rootCmd.PersistentFlags().BoolVar(&checkVanished, "confirm-vanished", false, "List the parent of a folder that is gone at rename time before counting it as vanished instead of failed")
+
+	// The profile and replacement templates apply to every command that sanitizes names
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", sanitizer.DefaultProfile, "Naming rules to enforce: "+strings.Join(sanitizer.ProfileNames(), ", "))
+	rootCmd.PersistentFlags().IntVar(&rulesVersion, "rules-version", 0, fmt.Sprintf("Reproduce the names of this rules version exactly, so later releases never rename already-sanitized content differently (0 = the rules of this release, version %d)", sanitizer.CurrentRulesVersion))
+	rootCmd.PersistentFlags().IntVar(&maxNameLength, "max-name-length", 0, "Maximum length of a single name, at least 4, e.g. 14 for strict POSIX (0 = profile default)")
+	rootCmd.PersistentFlags().StringVar(&truncation, "truncate", "", "How names over the length limit are shortened: "+strings.Join(sanitizer.TruncationStrategies(), ", ")+` (default cut; rules version 1 appends "...")`)
+	rootCmd.PersistentFlags().StringSliceVar(&srcEncodings, "source-encoding", nil, "Encodings tried in order to decode names that aren't valid UTF-8, e.g. shift_jis,latin1 (single-byte encodings like latin1 always fit, so list them last)")
+	rootCmd.PersistentFlags().StringVar(&budgetPrefix, "path-budget-prefix", "", `Plan path lengths for the tree copied below this destination, e.g. \\server\share\archive (uses the 259-character Windows limit if the profile has none)`)
+	rootCmd.PersistentFlags().StringArrayVar(&classifyRules, "classify", nil, "Rule applied by folder contents, e.g. contains:.git=skip or mostly:.mp3,.flac=profile:onedrive (repeatable, first match wins)")
+	rootCmd.PersistentFlags().StringVar(&replacements.InvalidChar, "replacement", replacements.InvalidChar, "Replacement for each invalid or unmappable character (template)")
+	rootCmd.PersistentFlags().StringVar(&replacements.EmptyName, "empty-name", replacements.EmptyName, "Replacement for names that end up empty (template)")
+	rootCmd.PersistentFlags().StringVar(&replacements.ReservedSuffix, "reserved-suffix", replacements.ReservedSuffix, "Suffix appended to Windows reserved names (template)")
+	rootCmd.PersistentFlags().StringVar(&replacements.Invisible, "invisible-replacement", replacements.Invisible, "Replacement for each invisible or formatting character, e.g. zero-width spaces and bidi overrides (template, empty = remove)")
+	rootCmd.PersistentFlags().StringVar(&translitLang, "translit-locale", "", "Transliterate letters the way speakers of this language expect, e.g. de (ü -> ue, ß -> ss) or da (å -> aa): "+strings.Join(sanitizer.TransliterationLocales(), ", "))
+	rootCmd.PersistentFlags().BoolVar(&confusables, "confusables", false, `Replace characters that look like ASCII with it, e.g. Cyrillic "А" or fullwidth "Ａ" -> A, so look-alike duplicates collide`)
+	rootCmd.PersistentFlags().StringVar(&emojiMode, "emoji", sanitizer.EmojiReplace, "How emoji and pictographs are handled: "+strings.Join(sanitizer.EmojiModes(), ", ")+` (text turns "🎉" into party-popper)`)
+	rootCmd.PersistentFlags().BoolVar(&whitespace.Collapse, "collapse-spaces", false, "Collapse runs of whitespace inside names into a single space")
+	rootCmd.PersistentFlags().StringVar(&whitespace.Replacement, "space-replacement", "", "Replace each space inside names with this, e.g. _ or - (empty = keep spaces)")
+	rootCmd.PersistentFlags().BoolVar(&slugNames, "slug", false, `Convert names to lower-case, hyphen-separated slugs for web servers, e.g. "My Fancy Folder!" -> my-fancy-folder`)
+	rootCmd.PersistentFlags().StringSliceVar(&pipeline, "pipeline", nil, "Apply these stages in order instead of the default pipeline, e.g. transliterate,windows,slug,length; profile:NAME applies a whole profile (see sanitize rules stages)")
+	rootCmd.PersistentFlags().StringArrayVar(&ruleFiles, "rules-file", nil, "File of custom find/replace, strip, reserved-name and length rules applied around the built-in rules (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVar(&wordPackFiles, "reserved-words", nil, "File of additional forbidden words or re: patterns, reported as violations (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&replaceWords, "replace-reserved-words", false, "Replace reserved-word matches instead of only reporting them")
 }
 
 // main is the entry point of the application
-// This function follows Go best practices for CLI applications
+// The exit code tells scripts whether anything changed or failed (see exitcode.go)
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		log.Fatal(err)
+		log.Print(err)
+		os.Exit(exitCode(err))
 	}
+	os.Exit(exitStatus)
 }
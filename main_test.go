@@ -0,0 +1,42 @@
+// Tests for building the components of a run from the command-line flags.
+// This test suite ensures invalid naming options are rejected before a run starts.
+package main
+
+import (
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/sanitizer"
+)
+
+// TestNewProfileSanitizer_MaxNameLength tests that name length limits below the minimum are rejected instead of truncating names to nothing
+func TestNewProfileSanitizer_MaxNameLength(t *testing.T) {
+	tests := []struct {
+		limit   int
+		wantErr bool
+	}{
+		{-1, true},
+		{0, false},
+		{1, true},
+		{2, true},
+		{sanitizer.MinNameLength - 1, true},
+		{sanitizer.MinNameLength, false},
+		{14, false},
+	}
+
+	saved := maxNameLength
+	t.Cleanup(func() { maxNameLength = saved })
+
+	for _, tt := range tests {
+		maxNameLength = tt.limit
+		folderSanitizer, err := newProfileSanitizer("posix", nil, nil)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("--max-name-length %d: error = %v, want an error = %v", tt.limit, err, tt.wantErr)
+			continue
+		}
+		if err == nil && tt.limit > 0 {
+			if got := folderSanitizer.SanitizeName("Some very long name here"); len(got) > tt.limit || got == "" {
+				t.Errorf("--max-name-length %d: SanitizeName() = %q", tt.limit, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+)
+
+// Flags for the name subcommand
+var (
+	showRules     bool // List the rules that fired for each name
+	readStdin     bool // Read names from stdin instead of arguments
+	nullDelimited bool // Use NUL instead of newline as the stdin/stdout delimiter
+)
+
+// maxStdinNameLength bounds a single delimited name read from stdin
+const maxStdinNameLength = 1024 * 1024
+
+// nameCmd sanitizes names given on the command line without touching the file system
+var nameCmd = &cobra.Command{
+	Use:   "name [NAME...]",
+	Short: "Print the sanitized form of one or more names",
+	Long: `Print the Windows-compatible form of each NAME, one per line, without touching
+the file system. Useful in shell scripts that generate directory names.
+
+With --rules, the rules that changed each name are written to stderr so that
+stdout stays safe to capture.
+
+With --stdin, newline-delimited names (NUL-delimited with -0) are read from
+stdin and written to stdout with the same delimiter, so the command composes
+with find, ls and other tools.`,
+	Example: `  sanitize name "My:Bad<Name>"
+  dir=$(sanitize name "$title") && mkdir "$dir"
+  ls | sanitize name --stdin
+  find . -mindepth 1 -maxdepth 1 -printf '%f\0' | sanitize name --stdin -0`,
+	Args: validateNameArgs,
+	RunE: runName,
+}
+
+// validateNameArgs ensures names come either from arguments or from stdin
+func validateNameArgs(cmd *cobra.Command, args []string) error {
+	if readStdin && len(args) > 0 {
+		return errors.New("names cannot be given as arguments together with --stdin")
+	}
+	if !readStdin && len(args) == 0 {
+		return errors.New("requires at least one NAME argument or --stdin")
+	}
+	if nullDelimited && !readStdin {
+		return errors.New("-0 can only be used together with --stdin")
+	}
+	return nil
+}
+
+// runName prints the sanitized form of every argument or stdin entry
+func runName(cmd *cobra.Command, args []string) error {
+	folderSanitizer, err := newFolderSanitizer()
+	if err != nil {
+		return err
+	}
+
+	if readStdin {
+		return sanitizeStream(cmd, folderSanitizer, cmd.InOrStdin())
+	}
+
+	for _, name := range args {
+		printName(cmd, folderSanitizer, name, '\n')
+	}
+
+	return nil
+}
+
+// sanitizeStream sanitizes every delimited name read from input
+func sanitizeStream(cmd *cobra.Command, folderSanitizer interfaces.FolderSanitizer, input io.Reader) error {
+	delimiter := byte('\n')
+	if nullDelimited {
+		delimiter = 0
+	}
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStdinNameLength)
+	scanner.Split(splitOn(delimiter))
+
+	for scanner.Scan() {
+		printName(cmd, folderSanitizer, scanner.Text(), delimiter)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading names from stdin: %w", err)
+	}
+
+	return nil
+}
+
+// splitOn returns a bufio.SplitFunc that splits input on a single delimiter byte
+// A final entry without a trailing delimiter is still returned
+func splitOn(delimiter byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.IndexByte(data, delimiter); i >= 0 {
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// printName writes the sanitized name followed by the delimiter and optionally the rules that fired
+func printName(cmd *cobra.Command, folderSanitizer interfaces.FolderSanitizer, name string, delimiter byte) {
+	fmt.Fprintf(cmd.OutOrStdout(), "%s%c", folderSanitizer.SanitizeName(name), delimiter)
+
+	if showRules {
+		printRules(cmd, folderSanitizer, name)
+	}
+}
+
+// printRules writes the rules that fired for a name to stderr
+func printRules(cmd *cobra.Command, folderSanitizer interfaces.FolderSanitizer, name string) {
+	explainer, ok := folderSanitizer.(interfaces.NameExplainer)
+	if !ok {
+		return
+	}
+
+	_, rules := explainer.ExplainName(name)
+	if len(rules) == 0 {
+		fmt.Fprintf(cmd.ErrOrStderr(), "  %q: no rules applied\n", name)
+		return
+	}
+
+	for _, rule := range rules {
+		fmt.Fprintf(cmd.ErrOrStderr(), "  %q: %s (%s)\n", name, rule, sanitizer.RuleDescription(rule))
+	}
+}
+
+// init registers the name subcommand and its flags
+func init() {
+	nameCmd.Flags().BoolVarP(&showRules, "rules", "r", false, "Also list which rules fired (written to stderr)")
+	nameCmd.Flags().BoolVar(&readStdin, "stdin", false, "Read delimited names from stdin instead of arguments")
+	nameCmd.Flags().BoolVarP(&nullDelimited, "null", "0", false, "Use NUL instead of newline to delimit names on stdin and stdout")
+	rootCmd.AddCommand(nameCmd)
+}
@@ -0,0 +1,137 @@
+package sanitize
+
+import (
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// publicWalker exposes a walker of the pipeline as a public DirectoryWalker
+type publicWalker struct {
+	next interfaces.DirectoryWalker
+}
+
+// Walk returns the folders of the wrapped walker as public folders
+func (w publicWalker) Walk(rootPath string) ([]FolderInfo, error) {
+	folders, err := w.next.Walk(rootPath)
+	public := make([]FolderInfo, 0, len(folders))
+	for _, folder := range folders {
+		public = append(public, toFolderInfo(folder))
+	}
+	return public, err
+}
+
+// pipelineWalker hands the folders of a caller's DirectoryWalker to the pipeline
+type pipelineWalker struct {
+	next DirectoryWalker
+}
+
+// Walk returns the folders of the caller's walker as pipeline folders
+func (pw pipelineWalker) Walk(rootPath string) ([]interfaces.FolderInfo, error) {
+	folders, err := pw.next.Walk(rootPath)
+	converted := make([]interfaces.FolderInfo, 0, len(folders))
+	for _, folder := range folders {
+		converted = append(converted, fromFolderInfo(folder))
+	}
+	return converted, err
+}
+
+// toPipelineWalker returns the pipeline walker behind w, wrapping walkers the caller implemented
+// Walkers created by this package are unwrapped, so the pipeline keeps the details the public types leave out
+func toPipelineWalker(w DirectoryWalker) interfaces.DirectoryWalker {
+	if own, ok := w.(publicWalker); ok {
+		return own.next
+	}
+	return pipelineWalker{next: w}
+}
+
+// publicProcessor exposes a processor of the pipeline as a public FolderProcessor
+type publicProcessor struct {
+	next interfaces.FolderProcessor
+}
+
+// ProcessRename renames a public folder with the wrapped processor
+func (p publicProcessor) ProcessRename(folder FolderInfo, newName string, dryRun bool) (*RenameResult, error) {
+	result, err := p.next.ProcessRename(fromFolderInfo(folder), newName, dryRun)
+	if result == nil {
+		return nil, err
+	}
+	public := toRenameResult(*result)
+	return &public, err
+}
+
+// pipelineProcessor lets the pipeline rename folders with a caller's FolderProcessor
+type pipelineProcessor struct {
+	next FolderProcessor
+}
+
+// ProcessRename renames a pipeline folder with the caller's processor
+func (pp pipelineProcessor) ProcessRename(folder interfaces.FolderInfo, newName string, dryRun bool) (*interfaces.RenameResult, error) {
+	result, err := pp.next.ProcessRename(toFolderInfo(folder), newName, dryRun)
+	if result == nil {
+		return nil, err
+	}
+	converted := fromRenameResult(*result)
+	return &converted, err
+}
+
+// toPipelineProcessor returns the pipeline processor behind p, wrapping processors the caller implemented
+func toPipelineProcessor(p FolderProcessor) interfaces.FolderProcessor {
+	if own, ok := p.(publicProcessor); ok {
+		return own.next
+	}
+	return pipelineProcessor{next: p}
+}
+
+// pipelineReporter forwards the events of the pipeline to a caller's ProgressReporter as public types
+// It also keeps the last summary, so Directory can return it; without a caller's reporter, events are discarded
+type pipelineReporter struct {
+	next    ProgressReporter
+	summary ProcessingSummary
+}
+
+// newPipelineReporter wraps a caller's reporter; nil discards every event
+func newPipelineReporter(next ProgressReporter) *pipelineReporter {
+	return &pipelineReporter{next: next}
+}
+
+// ReportProgress forwards progress updates
+func (pr *pipelineReporter) ReportProgress(current, total int, message string) {
+	if pr.next != nil {
+		pr.next.ReportProgress(current, total, message)
+	}
+}
+
+// ReportError forwards errors
+func (pr *pipelineReporter) ReportError(err error) {
+	if pr.next != nil {
+		pr.next.ReportError(err)
+	}
+}
+
+// ReportComplete records and forwards the summary
+func (pr *pipelineReporter) ReportComplete(summary interfaces.ProcessingSummary) {
+	pr.summary = toProcessingSummary(summary)
+	if pr.next != nil {
+		pr.next.ReportComplete(pr.summary)
+	}
+}
+
+// ReportRename forwards renames when the caller's reporter supports them
+func (pr *pipelineReporter) ReportRename(result interfaces.RenameResult) {
+	if renameReporter, ok := pr.next.(RenameReporter); ok {
+		renameReporter.ReportRename(toRenameResult(result))
+	}
+}
+
+// ReportFolder forwards the current folder when the caller's reporter supports it
+func (pr *pipelineReporter) ReportFolder(current, total int, folder interfaces.FolderInfo) {
+	if folderReporter, ok := pr.next.(FolderReporter); ok {
+		folderReporter.ReportFolder(current, total, toFolderInfo(folder))
+	}
+}
+
+// ReportFailure forwards failures when the caller's reporter supports them
+func (pr *pipelineReporter) ReportFailure(folder interfaces.FolderInfo, err error) {
+	if failureReporter, ok := pr.next.(FailureReporter); ok {
+		failureReporter.ReportFailure(toFolderInfo(folder), err)
+	}
+}
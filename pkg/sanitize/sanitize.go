@@ -0,0 +1,210 @@
+// Package sanitize is the public library API for the sanitize application.
+// It exposes the sanitizer, walker, processor and service so other Go programs can sanitize
+// folder names or run the full pipeline without shelling out to the CLI.
+//
+// Sanitizing a single name:
+//
+//	clean := sanitize.Name("bad<chars>") // "bad_chars_"
+//
+// Running the full pipeline:
+//
+//	summary, err := sanitize.Directory("/path/to/tree", sanitize.Options{DryRun: true})
+package sanitize
+
+import (
+	"io/fs"
+
+	"github.com/punkscience/sanitize/internal/filesystem"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/processor"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+	"github.com/punkscience/sanitize/internal/service"
+	"github.com/punkscience/sanitize/internal/walker"
+)
+
+// MemoryFileSystem is an in-memory FileSystem for tests and simulations
+type MemoryFileSystem struct {
+	memory *filesystem.MemoryFileSystem
+}
+
+// Service orchestrates the sanitizer, walker, processor and reporter
+type Service struct {
+	service *service.SanitizeService
+}
+
+// ServiceOption configures optional Service behavior
+type ServiceOption func(*serviceConfig)
+
+// serviceConfig collects the pipeline options a Service is created with
+type serviceConfig struct {
+	options []service.Option
+}
+
+// ErrErrorBudgetExceeded is returned when a run is aborted by MaxErrors or MaxErrorRate
+var ErrErrorBudgetExceeded = service.ErrErrorBudgetExceeded
+
+// Options configures a full pipeline run started with Directory
+type Options struct {
+	// DryRun reports what would be renamed without touching the file system
+	DryRun bool
+	// MaxDepth limits how deep the walker traverses (0 = unlimited)
+	MaxDepth int
+	// MaxCollisionRetries limits numbered collision suffixes (0 = default of 1000)
+	MaxCollisionRetries int
+	// MaxErrors aborts the run once more than this many errors occurred (0 = unlimited)
+	MaxErrors int
+	// MaxErrorRate aborts the run once the error percentage exceeds this value (0 = unlimited)
+	MaxErrorRate float64
+	// Reporter receives progress events (nil = silent)
+	Reporter ProgressReporter
+	// FileSystem is the backend to operate on (nil = the real file system)
+	FileSystem FileSystem
+}
+
+// NewOSFileSystem creates a FileSystem backed by the real operating system
+func NewOSFileSystem() FileSystem {
+	return filesystem.NewOSFileSystem()
+}
+
+// NewMemoryFileSystem creates an empty in-memory FileSystem
+func NewMemoryFileSystem() *MemoryFileSystem {
+	return &MemoryFileSystem{memory: filesystem.NewMemoryFileSystem()}
+}
+
+// MkdirAll creates a directory and all missing parents
+func (m *MemoryFileSystem) MkdirAll(path string) {
+	m.memory.MkdirAll(path)
+}
+
+// WriteFile creates a file (and its parents) with the given content
+func (m *MemoryFileSystem) WriteFile(path string, content []byte) {
+	m.memory.WriteFile(path, content)
+}
+
+// Exists reports whether a path exists
+func (m *MemoryFileSystem) Exists(path string) bool {
+	return m.memory.Exists(path)
+}
+
+// Stat returns information about a path, following symbolic links
+func (m *MemoryFileSystem) Stat(path string) (fs.FileInfo, error) {
+	return m.memory.Stat(path)
+}
+
+// Lstat returns information about a path without following symbolic links
+func (m *MemoryFileSystem) Lstat(path string) (fs.FileInfo, error) {
+	return m.memory.Lstat(path)
+}
+
+// ReadDir returns the entries of a directory sorted by name
+func (m *MemoryFileSystem) ReadDir(path string) ([]fs.DirEntry, error) {
+	return m.memory.ReadDir(path)
+}
+
+// ReadFile returns the content of a file
+func (m *MemoryFileSystem) ReadFile(path string) ([]byte, error) {
+	return m.memory.ReadFile(path)
+}
+
+// Rename moves oldPath to newPath
+func (m *MemoryFileSystem) Rename(oldPath, newPath string) error {
+	return m.memory.Rename(oldPath, newPath)
+}
+
+// Remove deletes a file or an empty directory
+func (m *MemoryFileSystem) Remove(path string) error {
+	return m.memory.Remove(path)
+}
+
+// SameFile reports whether two FileInfos obtained from this file system describe the same file
+func (m *MemoryFileSystem) SameFile(a, b fs.FileInfo) bool {
+	return m.memory.SameFile(a, b)
+}
+
+// NewWindowsSanitizer creates the default Windows-compatible folder name sanitizer
+func NewWindowsSanitizer() FolderSanitizer {
+	return sanitizer.NewWindowsSanitizer()
+}
+
+// NewFileSystemWalker creates a walker that returns folders deepest first
+func NewFileSystemWalker(skipInaccessible bool, maxDepth int) DirectoryWalker {
+	return publicWalker{next: walker.NewFileSystemWalker(skipInaccessible, maxDepth)}
+}
+
+// NewFileSystemProcessor creates a processor that renames folders on disk
+func NewFileSystemProcessor(maxCollisionRetries int) FolderProcessor {
+	return publicProcessor{next: processor.NewFileSystemProcessor(maxCollisionRetries)}
+}
+
+// NewService creates a pipeline service from the provided components (a nil reporter is silent)
+func NewService(s FolderSanitizer, w DirectoryWalker, p FolderProcessor, r ProgressReporter, options ...ServiceOption) *Service {
+	config := &serviceConfig{}
+	for _, option := range options {
+		option(config)
+	}
+	return &Service{
+		service: service.NewSanitizeService(s, toPipelineWalker(w), toPipelineProcessor(p), newPipelineReporter(r), config.options...),
+	}
+}
+
+// SanitizeDirectory runs the pipeline on rootPath; a dry run reports what would be renamed without changing anything
+func (s *Service) SanitizeDirectory(rootPath string, dryRun bool) error {
+	return s.service.SanitizeDirectory(rootPath, dryRun)
+}
+
+// Summary returns the summary of the most recent SanitizeDirectory call, or nil if none got past the walk
+func (s *Service) Summary() *ProcessingSummary {
+	summary := s.service.Summary()
+	if summary == nil {
+		return nil
+	}
+	public := toProcessingSummary(*summary)
+	return &public
+}
+
+// WithMaxErrors aborts a Service run once more than maxErrors errors occurred (0 = unlimited)
+func WithMaxErrors(maxErrors int) ServiceOption {
+	return func(c *serviceConfig) {
+		c.options = append(c.options, service.WithMaxErrors(maxErrors))
+	}
+}
+
+// WithMaxErrorRate aborts a Service run once the error percentage exceeds maxErrorRate (0 = unlimited)
+func WithMaxErrorRate(maxErrorRate float64) ServiceOption {
+	return func(c *serviceConfig) {
+		c.options = append(c.options, service.WithMaxErrorRate(maxErrorRate))
+	}
+}
+
+// Name returns the Windows-compatible version of a single folder name
+func Name(name string) string {
+	return sanitizer.NewWindowsSanitizer().SanitizeName(name)
+}
+
+// Directory runs the full sanitization pipeline on rootPath using the default components
+// The returned summary is the same one handed to the reporter on completion
+func Directory(rootPath string, opts Options) (ProcessingSummary, error) {
+	recorder := newPipelineReporter(opts.Reporter)
+
+	var fileSystem interfaces.FileSystem
+	switch backend := opts.FileSystem.(type) {
+	case nil:
+		fileSystem = filesystem.NewOSFileSystem()
+	case *MemoryFileSystem:
+		fileSystem = backend.memory // Unwrapped, so the pipeline sees every capability of the backend
+	default:
+		fileSystem = backend
+	}
+
+	svc := service.NewSanitizeService(
+		sanitizer.NewWindowsSanitizer(),
+		walker.NewFileSystemWalker(true, opts.MaxDepth, walker.WithFileSystem(fileSystem)),
+		processor.NewFileSystemProcessor(opts.MaxCollisionRetries, processor.WithFileSystem(fileSystem)),
+		recorder,
+		service.WithMaxErrors(opts.MaxErrors),
+		service.WithMaxErrorRate(opts.MaxErrorRate),
+	)
+
+	err := svc.SanitizeDirectory(rootPath, opts.DryRun)
+	return recorder.summary, err
+}
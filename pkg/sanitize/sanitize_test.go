@@ -0,0 +1,146 @@
+// Package sanitize_test provides tests for the public library API.
+// This test suite ensures the exported entry points behave like the CLI pipeline.
+package sanitize_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/punkscience/sanitize/pkg/sanitize"
+)
+
+// TestName tests single-name sanitization through the public API
+func TestName(t *testing.T) {
+	testCases := map[string]string{
+		"ValidFolder": "ValidFolder",
+		"bad<chars>":  "bad_chars_",
+		"CON":         "CON_",
+		"café":        "cafe",
+	}
+
+	for input, expected := range testCases {
+		if result := sanitize.Name(input); result != expected {
+			t.Errorf("Name(%q) = %q, expected %q", input, result, expected)
+		}
+	}
+}
+
+// TestDirectory tests running the full pipeline through the public API
+func TestDirectory(t *testing.T) {
+	tempDir := t.TempDir()
+	badDir := filepath.Join(tempDir, "bad<chars>")
+	if err := os.Mkdir(badDir, 0755); err != nil {
+		t.Skipf("File system does not allow test folder name: %v", err)
+	}
+
+	// Dry run must report the rename without touching the file system
+	summary, err := sanitize.Directory(tempDir, sanitize.Options{DryRun: true})
+	if err != nil {
+		t.Fatalf("Directory() dry run returned error: %v", err)
+	}
+	if summary.RenamedCount != 1 {
+		t.Errorf("Expected 1 rename in dry run, got %d", summary.RenamedCount)
+	}
+	if _, err := os.Stat(badDir); err != nil {
+		t.Errorf("Dry run should not rename folder: %v", err)
+	}
+
+	// A real run must rename the folder
+	summary, err = sanitize.Directory(tempDir, sanitize.Options{})
+	if err != nil {
+		t.Fatalf("Directory() returned error: %v", err)
+	}
+	if summary.RenamedCount != 1 {
+		t.Errorf("Expected 1 rename, got %d", summary.RenamedCount)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "bad_chars_")); err != nil {
+		t.Errorf("Expected sanitized folder to exist: %v", err)
+	}
+}
+
+// TestDirectory_MemoryFileSystem tests running the pipeline against an in-memory backend
+func TestDirectory_MemoryFileSystem(t *testing.T) {
+	memory := sanitize.NewMemoryFileSystem()
+	memory.MkdirAll("/tree/bad<chars>/CON")
+	memory.MkdirAll("/tree/fine")
+
+	summary, err := sanitize.Directory("/tree", sanitize.Options{FileSystem: memory})
+	if err != nil {
+		t.Fatalf("Directory() returned error: %v", err)
+	}
+	if summary.RenamedCount != 2 {
+		t.Errorf("Expected 2 renames, got %d", summary.RenamedCount)
+	}
+
+	if !memory.Exists("/tree/bad_chars_/CON_") {
+		t.Error("Expected /tree/bad_chars_/CON_ to exist after sanitization")
+	}
+	if memory.Exists("/tree/bad<chars>") {
+		t.Error("Expected /tree/bad<chars> to be renamed")
+	}
+}
+
+// listWalker returns a fixed list of folders
+type listWalker []sanitize.FolderInfo
+
+func (lw listWalker) Walk(rootPath string) ([]sanitize.FolderInfo, error) {
+	return lw, nil
+}
+
+// recordingProcessor records the renames it was asked for and reports every changed name as renamed
+type recordingProcessor struct {
+	renames []string
+}
+
+func (rp *recordingProcessor) ProcessRename(folder sanitize.FolderInfo, newName string, dryRun bool) (*sanitize.RenameResult, error) {
+	rp.renames = append(rp.renames, folder.Name+" -> "+newName)
+	return &sanitize.RenameResult{
+		Success:    true,
+		OldPath:    folder.Path,
+		NewPath:    filepath.Join(folder.Parent, newName),
+		WasRenamed: newName != folder.Name,
+	}, nil
+}
+
+// renameRecorder collects every rename and the summary of a run
+type renameRecorder struct {
+	renames []sanitize.RenameResult
+	summary sanitize.ProcessingSummary
+}
+
+func (rr *renameRecorder) ReportProgress(current, total int, message string) {}
+func (rr *renameRecorder) ReportError(err error)                             {}
+func (rr *renameRecorder) ReportComplete(summary sanitize.ProcessingSummary) { rr.summary = summary }
+func (rr *renameRecorder) ReportRename(result sanitize.RenameResult) {
+	rr.renames = append(rr.renames, result)
+}
+
+// TestNewService_CustomComponents tests that components implemented against the public types drive the pipeline
+func TestNewService_CustomComponents(t *testing.T) {
+	walker := listWalker{
+		{Path: "/tree/bad<chars>", Name: "bad<chars>", Depth: 1, Parent: "/tree"},
+		{Path: "/tree/fine", Name: "fine", Depth: 1, Parent: "/tree"},
+	}
+	processor := &recordingProcessor{}
+	reporter := &renameRecorder{}
+
+	svc := sanitize.NewService(sanitize.NewWindowsSanitizer(), walker, processor, reporter, sanitize.WithMaxErrors(1))
+	if err := svc.SanitizeDirectory("/tree", true); err != nil {
+		t.Fatalf("SanitizeDirectory() returned error: %v", err)
+	}
+
+	if len(processor.renames) != 2 || processor.renames[0] != "bad<chars> -> bad_chars_" {
+		t.Errorf("Expected the processor to be asked for both folders, got %q", processor.renames)
+	}
+	if len(reporter.renames) != 1 || reporter.renames[0].NewPath != "/tree/bad_chars_" {
+		t.Errorf("Expected one reported rename to bad_chars_, got %+v", reporter.renames)
+	}
+	want := sanitize.PhaseCounts{Planned: 1, WouldRename: 1, Skipped: 1}
+	if reporter.summary.Phases != want || !reporter.summary.DryRun {
+		t.Errorf("Expected dry-run phases %+v, got %+v", want, reporter.summary)
+	}
+	if summary := svc.Summary(); summary == nil || summary.Phases != want {
+		t.Errorf("Expected Summary() to match the reported summary, got %+v", summary)
+	}
+}
@@ -0,0 +1,211 @@
+package sanitize
+
+import (
+	"io/fs"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+)
+
+// FolderSanitizer sanitizes a single folder name
+type FolderSanitizer interface {
+	// SanitizeName returns the compliant version of name
+	SanitizeName(name string) string
+}
+
+// DirectoryWalker collects the folders of a directory tree in the order they are processed
+type DirectoryWalker interface {
+	// Walk returns every folder below rootPath
+	Walk(rootPath string) ([]FolderInfo, error)
+}
+
+// FolderProcessor renames a single folder with collision detection
+type FolderProcessor interface {
+	// ProcessRename renames folder to newName, or only checks the rename in a dry run
+	ProcessRename(folder FolderInfo, newName string, dryRun bool) (*RenameResult, error)
+}
+
+// ProgressReporter receives progress, error and completion events
+type ProgressReporter interface {
+	// ReportProgress reports that current of total folders were handled
+	ReportProgress(current, total int, message string)
+	// ReportError reports an error that did not stop the run
+	ReportError(err error)
+	// ReportComplete reports the summary of a finished run
+	ReportComplete(summary ProcessingSummary)
+}
+
+// RenameReporter is optionally implemented by a ProgressReporter to receive each individual rename
+type RenameReporter interface {
+	// ReportRename reports a rename that was performed, or would be in a dry run
+	ReportRename(result RenameResult)
+}
+
+// FolderReporter is optionally implemented by a ProgressReporter to receive the folder currently being processed
+type FolderReporter interface {
+	// ReportFolder reports the folder processing starts with
+	ReportFolder(current, total int, folder FolderInfo)
+}
+
+// FailureReporter is optionally implemented by a ProgressReporter to receive each folder that failed to process
+type FailureReporter interface {
+	// ReportFailure reports a folder and why it failed
+	ReportFailure(folder FolderInfo, err error)
+}
+
+// FileSystem is the backend the walker and processor operate on
+type FileSystem interface {
+	// Stat returns information about a path, following symbolic links
+	Stat(path string) (fs.FileInfo, error)
+	// Lstat returns information about a path without following symbolic links
+	Lstat(path string) (fs.FileInfo, error)
+	// ReadDir returns the entries of a directory sorted by name
+	ReadDir(path string) ([]fs.DirEntry, error)
+	// ReadFile returns the content of a file
+	ReadFile(path string) ([]byte, error)
+	// Rename moves oldPath to newPath
+	Rename(oldPath, newPath string) error
+	// Remove deletes a file or an empty directory
+	Remove(path string) error
+	// SameFile reports whether two FileInfos obtained from this file system describe the same file
+	SameFile(a, b fs.FileInfo) bool
+}
+
+// FolderInfo describes a folder discovered by the walker
+type FolderInfo struct {
+	Path   string // Full path to the folder
+	Name   string // Current folder name
+	Depth  int    // Depth level from the root
+	Parent string // Parent directory path
+	Owner  string // Owner of the folder, when the walker attributes owners (empty otherwise)
+}
+
+// RenameResult describes the outcome of a rename operation
+type RenameResult struct {
+	Success    bool   // Whether the rename was successful
+	OldPath    string // Original path
+	NewPath    string // New path after the rename
+	WasRenamed bool   // Whether the folder actually needed renaming
+	Merged     bool   // Whether the folder was merged into an existing folder instead of renamed
+	Error      error  // Any error that occurred
+	Transient  bool   // Whether Error is transient, so a later retry may succeed
+	Attempts   int    // Number of rename attempts, including retries of transient errors
+	Vanished   bool   // Whether the folder was deleted or moved after the scan
+	Locked     bool   // Whether another application holds the folder or a file in it open
+
+	// Rules lists the identifiers of the rules that changed the name, when the sanitizer explains its changes
+	Rules []string
+	// SanitizedName is the name the rules produced; it differs from the new name when a collision was resolved with a suffix
+	SanitizedName string
+}
+
+// ProcessingSummary contains statistics about a whole run
+type ProcessingSummary struct {
+	RunID          string `json:"run_id,omitempty"`       // Identifier of the run
+	TotalFolders   int    `json:"total_folders"`          // Total number of folders found
+	ProcessedCount int    `json:"processed_count"`        // Number of folders processed
+	RenamedCount   int    `json:"renamed_count"`          // Number of folders renamed, or that would be renamed in a dry run
+	ErrorCount     int    `json:"error_count"`            // Number of errors encountered
+	WarningCount   int    `json:"warning_count"`          // Number of non-fatal problems, e.g. directories the walk skipped
+	SkippedCount   int    `json:"skipped_count"`          // Number of folders skipped
+	RemainingCount int    `json:"remaining_count"`        // Number of folders a stopped run didn't reach
+	ElapsedTime    string `json:"elapsed_time"`           // Time taken for the run
+	Aborted        bool   `json:"aborted,omitempty"`      // Whether the run stopped early (e.g. error budget exceeded)
+	AbortReason    string `json:"abort_reason,omitempty"` // Why the run stopped early
+
+	// DryRun and Phases separate what the run planned from what it applied
+	DryRun bool        `json:"dry_run"`
+	Phases PhaseCounts `json:"phases"`
+
+	// Locked lists the folders still held open by other applications at the end of the run
+	Locked []string `json:"locked,omitempty"`
+}
+
+// PhaseCounts splits the folders of a run by outcome
+// Planned always equals Applied + WouldRename + Deferred + Failed + Vanished
+type PhaseCounts struct {
+	Planned     int `json:"planned"`      // Folders whose name needs to change
+	Applied     int `json:"applied"`      // Planned renames that were performed; always 0 in a dry run
+	WouldRename int `json:"would_rename"` // Planned renames a dry run checked; always 0 in a real run
+	Deferred    int `json:"deferred"`     // Planned renames a stopped run didn't reach
+	Failed      int `json:"failed"`       // Planned renames that failed
+	Vanished    int `json:"vanished"`     // Planned renames whose folder was deleted or moved after the scan
+	Skipped     int `json:"skipped"`      // Folders whose name already complies
+}
+
+// toFolderInfo converts a folder of the pipeline to the public type
+func toFolderInfo(folder interfaces.FolderInfo) FolderInfo {
+	return FolderInfo{
+		Path:   folder.Path,
+		Name:   folder.Name,
+		Depth:  folder.Depth,
+		Parent: folder.Parent,
+		Owner:  folder.Owner,
+	}
+}
+
+// fromFolderInfo converts a public folder to the pipeline type
+func fromFolderInfo(folder FolderInfo) interfaces.FolderInfo {
+	return interfaces.FolderInfo{
+		Path:   folder.Path,
+		Name:   folder.Name,
+		Depth:  folder.Depth,
+		Parent: folder.Parent,
+		Owner:  folder.Owner,
+	}
+}
+
+// toRenameResult converts a rename outcome of the pipeline to the public type
+func toRenameResult(result interfaces.RenameResult) RenameResult {
+	return RenameResult{
+		Success:       result.Success,
+		OldPath:       result.OldPath,
+		NewPath:       result.NewPath,
+		WasRenamed:    result.WasRenamed,
+		Merged:        result.Merged,
+		Error:         result.Error,
+		Transient:     result.Transient,
+		Attempts:      result.Attempts,
+		Vanished:      result.Vanished,
+		Locked:        result.Locked,
+		Rules:         result.Rules,
+		SanitizedName: result.SanitizedName,
+	}
+}
+
+// fromRenameResult converts a public rename outcome to the pipeline type
+func fromRenameResult(result RenameResult) interfaces.RenameResult {
+	return interfaces.RenameResult{
+		Success:       result.Success,
+		OldPath:       result.OldPath,
+		NewPath:       result.NewPath,
+		WasRenamed:    result.WasRenamed,
+		Merged:        result.Merged,
+		Error:         result.Error,
+		Transient:     result.Transient,
+		Attempts:      result.Attempts,
+		Vanished:      result.Vanished,
+		Locked:        result.Locked,
+		Rules:         result.Rules,
+		SanitizedName: result.SanitizedName,
+	}
+}
+
+// toProcessingSummary converts the summary of a run to the public type
+func toProcessingSummary(summary interfaces.ProcessingSummary) ProcessingSummary {
+	return ProcessingSummary{
+		RunID:          summary.RunID,
+		TotalFolders:   summary.TotalFolders,
+		ProcessedCount: summary.ProcessedCount,
+		RenamedCount:   summary.RenamedCount,
+		ErrorCount:     summary.ErrorCount,
+		WarningCount:   summary.WarningCount,
+		SkippedCount:   summary.SkippedCount,
+		RemainingCount: summary.RemainingCount,
+		ElapsedTime:    summary.ElapsedTime,
+		Aborted:        summary.Aborted,
+		AbortReason:    summary.AbortReason,
+		DryRun:         summary.DryRun,
+		Phases:         PhaseCounts(summary.Phases),
+		Locked:         summary.Locked,
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/config"
+)
+
+// Flags for loading a naming policy
+var (
+	configLocation string // Local path or URL of the policy file
+	configSHA256   string // Pinned SHA-256 checksum of the policy
+	configCacheDir string // Where downloaded policies are cached
+)
+
+// policyOptions are the flags a policy may set: naming rules, what to leave alone and how results are reported
+// Everything else, such as --path, --yes, --force, output files or listen addresses, stays with whoever runs the command,
+// so a fetched policy can't widen what a run touches or where its results go
+var policyOptions = map[string]bool{
+	// Naming
+	"profile": true, "rules-version": true, "max-name-length": true, "truncate": true, "source-encoding": true, "emoji": true,
+	"translit-locale": true, "confusables": true, "path-budget-prefix": true, "classify": true, "replacement": true, "empty-name": true,
+	"reserved-suffix": true, "invisible-replacement": true, "collapse-spaces": true, "space-replacement": true, "slug": true,
+	"pipeline": true, "rules-file": true, "reserved-words": true, "replace-reserved-words": true, "merge": true, "hash-suffixes": true,
+	"case-canonical": true, "order": true, "risk-name-length": true, "risk-path-headroom": true,
+	// What to leave alone
+	"protect": true, "no-default-protection": true, "marker-file": true, "marker-subtree": true,
+	"owner": true, "group": true, "one-file-system": true, "links": true,
+	// Retries and error budget
+	"network-retries": true, "network-retry-delay": true, "rename-retries": true, "rename-retry-delay": true,
+	"confirm-vanished": true, "max-errors": true,
+	// Reporting
+	"relative-paths": true, "by-owner": true, "collation": true, "ascii-output": true, "accessible": true, "no-color": true,
+	"log-level": true, "log-format": true, "format": true,
+}
+
+// localPolicyOptions are the policy options only a policy file on this machine may set, not one fetched from a URL
+// Merges can't be undone and rules files are read from local paths, so whoever runs the command decides them
+var localPolicyOptions = map[string]bool{"merge": true, "rules-file": true}
+
+// loadPolicy applies the policy selected with --config to the flags of the running command
+// Flags given explicitly on the command line always win over the policy
+func loadPolicy(cmd *cobra.Command, args []string) error {
+	policyDefaults = policyFlagValues(cmd)
+	if configLocation == "" {
+		return nil
+	}
+
+	policy, err := policySource().Load()
+	if err != nil {
+		return err
+	}
+
+	return applyPolicy(cmd, policy, policySource().IsRemote())
+}
+
+// policySource returns the source selected by the policy flags; using a cached copy is reported on stderr
+func policySource() config.Source {
+	return config.Source{
+		Location: configLocation,
+		SHA256:   configSHA256,
+		CacheDir: configCacheDir,
+		Stale: func(err error) {
+			fmt.Fprintf(os.Stderr, "Warning: using the cached copy of policy %s, the server could not be reached: %v\n", configLocation, err)
+		},
+	}
+}
+
+// applyPolicy sets every flag named in the policy that the user did not set explicitly
+// Keys for flags of other subcommands are ignored so one policy can serve every command; keys outside policyOptions are rejected,
+// and so are localPolicyOptions when the policy is remote
+func applyPolicy(cmd *cobra.Command, policy config.Policy, remote bool) error {
+	for key, value := range policy {
+		if !isKnownFlag(cmd.Root(), key) {
+			return fmt.Errorf("policy sets unknown option %q", key)
+		}
+		if !policyOptions[key] {
+			return fmt.Errorf("policy cannot set --%s; only naming, protection and reporting options can come from a policy", key)
+		}
+		if remote && localPolicyOptions[key] {
+			return fmt.Errorf("remote policy cannot set --%s; pass it on the command line or in a local policy file", key)
+		}
+
+		flag := cmd.Flags().Lookup(key)
+		if flag == nil {
+			continue
+		}
+
+		if flag.Changed {
+			continue
+		}
+
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("policy value %q for %q is invalid: %w", value, key, err)
+		}
+	}
+
+	return nil
+}
+
+// isKnownFlag reports whether any command in the tree defines the flag
+func isKnownFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil {
+		return true
+	}
+
+	for _, child := range cmd.Commands() {
+		if isKnownFlag(child, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// defaultPolicyCacheDir returns the per-user cache directory for downloaded policies
+func defaultPolicyCacheDir() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "sanitize", "policies")
+}
+
+// init registers the policy flags on every command
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configLocation, "config", "", "Naming policy file or https URL providing defaults for naming and reporting flags")
+	rootCmd.PersistentFlags().StringVar(&configSHA256, "config-sha256", "", "Require the policy to match this SHA-256 checksum")
+	rootCmd.PersistentFlags().StringVar(&configCacheDir, "config-cache-dir", defaultPolicyCacheDir(), "Cache for downloaded policies, used when the URL is unreachable")
+}
@@ -0,0 +1,61 @@
+// Tests for applying naming policies to commands.
+// This test suite ensures a policy can only set naming, protection and reporting options.
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/punkscience/sanitize/internal/config"
+)
+
+// TestPolicyOptions_Known tests that every option a policy may set is a flag of some command
+func TestPolicyOptions_Known(t *testing.T) {
+	for name := range policyOptions {
+		if !isKnownFlag(rootCmd, name) {
+			t.Errorf("Policy option %q is not a flag", name)
+		}
+	}
+	for name := range localPolicyOptions {
+		if !policyOptions[name] {
+			t.Errorf("Local policy option %q can't be set by a policy", name)
+		}
+	}
+	for name := range reloadableFlags {
+		if !policyOptions[name] {
+			t.Errorf("Reloadable flag %q can't be set by a policy", name)
+		}
+	}
+}
+
+// TestApplyPolicy_Allowlist tests that policies can't set options that change what a run touches or where results go
+func TestApplyPolicy_Allowlist(t *testing.T) {
+	for _, key := range []string{"path", "yes", "force", "dry-run", "audit-log", "listen", "chaos-sandbox", "config", "config-sha256"} {
+		err := applyPolicy(rootCmd, config.Policy{key: "x"}, false)
+		if err == nil || !strings.Contains(err.Error(), "cannot set --"+key) {
+			t.Errorf("Expected policy setting %q to be rejected, got %v", key, err)
+		}
+	}
+
+	if err := applyPolicy(rootCmd, config.Policy{"no-such-option": "x"}, false); err == nil || !strings.Contains(err.Error(), "unknown option") {
+		t.Errorf("Expected unknown options to be rejected, got %v", err)
+	}
+}
+
+// TestApplyPolicy_Remote tests that only a local policy file may merge folders or load rules files
+func TestApplyPolicy_Remote(t *testing.T) {
+	for key, value := range map[string]string{"merge": "true", "rules-file": "/etc/house.rules"} {
+		err := applyPolicy(rootCmd, config.Policy{key: value}, true)
+		if err == nil || !strings.Contains(err.Error(), "remote policy cannot set --"+key) {
+			t.Errorf("Expected remote policy setting %q to be rejected, got %v", key, err)
+		}
+	}
+	if merge || len(ruleFiles) > 0 {
+		t.Errorf("Expected a rejected remote policy to change nothing, got merge=%v rules-file=%v", merge, ruleFiles)
+	}
+
+	defer func() { merge = false }()
+	if err := applyPolicy(rootCmd, config.Policy{"merge": "true"}, false); err != nil || !merge {
+		t.Errorf("Expected a local policy to set --merge, got %v (merge=%v)", err, merge)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/dataset"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/paths"
+	"github.com/punkscience/sanitize/internal/service"
+	"github.com/punkscience/sanitize/internal/walker"
+)
+
+// Flags for the profile-tree subcommand
+var datasetPath string // Dataset file to write; the extension selects CSV or Parquet
+
+// profileTreeCmd records every directory name of a tree as a dataset row without proposing renames
+var profileTreeCmd = &cobra.Command{
+	Use:   "profile-tree",
+	Short: "Write a dataset describing every directory name, for analysis before a migration",
+	Long: `Profile-tree walks the folder tree and writes one row per directory to a CSV or
+Parquet file: its path, name, depth, length in bytes and characters, the Unicode
+scripts its characters belong to, and the naming rules it violates.
+
+Nothing is renamed and no sanitized names are proposed, so the dataset shows what
+the tree looks like today. The extension of --out selects the format.`,
+	Example: `  sanitize profile-tree --path /mnt/share --out dataset.parquet
+  sanitize profile-tree --path ./photos --profile onedrive --out dataset.csv`,
+	Args: cobra.NoArgs,
+	RunE: runProfileTree,
+}
+
+// runProfileTree scans the tree and writes a dataset row for every directory
+func runProfileTree(cmd *cobra.Command, args []string) error {
+	absPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("error resolving path: %w", err)
+	}
+
+	if err := validatePath(absPath); err != nil {
+		return err
+	}
+
+	folderSanitizer, err := newFolderSanitizer()
+	if err != nil {
+		return err
+	}
+
+	options, err := walkerOptions()
+	if err != nil {
+		return err
+	}
+
+	// Profiling only needs the sanitizer for its rules and the walker; nothing is renamed or reported live
+	profileService := service.NewSanitizeService(
+		folderSanitizer,
+		walker.NewFileSystemWalker(true, 0, options...),
+		nil,
+		nil,
+	)
+
+	writer, err := dataset.Create(datasetPath)
+	if err != nil {
+		return err
+	}
+
+	rows := 0
+	warnings, err := profileService.ProfileDirectory(absPath, func(folder interfaces.FolderInfo, rules []string) error {
+		path := folder.Path
+		if relativePaths {
+			path = paths.Relative(absPath, path)
+		}
+		rows++
+		return writer.Write(dataset.NewRecord(folder, path, rules))
+	})
+	if err != nil {
+		writer.Close()
+		return fmt.Errorf("error during profiling: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	printCheckWarnings(cmd, warnings, false)
+	fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d directories to %s\n", rows, datasetPath)
+
+	return nil
+}
+
+func init() {
+	profileTreeCmd.Flags().StringVarP(&datasetPath, "out", "o", "", "Dataset file to write: .csv or .parquet")
+	_ = profileTreeCmd.MarkFlagRequired("out")
+	rootCmd.AddCommand(profileTreeCmd)
+}
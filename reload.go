@@ -0,0 +1,245 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/walker"
+)
+
+// runComponents are the parts of a run built from the naming, walking and renaming flags
+// Long-lived commands rebuild them when the policy is reloaded and swap them in between two runs
+type runComponents struct {
+	sanitizer     interfaces.FolderSanitizer
+	walkerOptions []walker.Option
+	protection    walker.Protection
+	processor     interfaces.FolderProcessor
+	relativePaths bool
+}
+
+// newRunComponents builds the components from the current flags, so invalid values are caught before they are used
+// reloadableFlags lists the flags read here
+func newRunComponents() (*runComponents, error) {
+	folderSanitizer, err := newFolderSanitizer()
+	if err != nil {
+		return nil, err
+	}
+	options, err := walkerOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	return &runComponents{
+		sanitizer:     folderSanitizer,
+		walkerOptions: options,
+		protection:    newProtection(),
+		processor:     newFolderProcessor(newFileSystem()),
+		relativePaths: relativePaths,
+	}, nil
+}
+
+// newWalker creates a walker with the component's options and extra ones
+func (c *runComponents) newWalker(extra ...walker.Option) interfaces.DirectoryWalker {
+	options := append(append([]walker.Option{}, c.walkerOptions...), extra...)
+	return walker.NewFileSystemWalker(true, 0, options...)
+}
+
+// reloadableFlags are the flags a policy reload applies to a running command, i.e. those newRunComponents reads
+// Changes to any other flag are reported but only take effect after a restart
+var reloadableFlags = map[string]bool{
+	"profile": true, "rules-version": true, "max-name-length": true, "truncate": true, "source-encoding": true, "emoji": true, "translit-locale": true, "confusables": true, "path-budget-prefix": true, "classify": true,
+	"replacement": true, "empty-name": true, "reserved-suffix": true, "invisible-replacement": true, "collapse-spaces": true, "space-replacement": true,
+	"slug": true, "pipeline": true, "rules-file": true, "reserved-words": true, "replace-reserved-words": true,
+	"protect": true, "no-default-protection": true, "marker-file": true, "marker-subtree": true,
+	"owner": true, "group": true, "by-owner": true, "one-file-system": true, "links": true,
+	"network-retries": true, "network-retry-delay": true,
+	"rename-retries": true, "rename-retry-delay": true, "confirm-vanished": true, "merge": true, "hash-suffixes": true,
+	"relative-paths": true,
+}
+
+// policyDefaults holds the values of the flags a policy may set, as they were before the policy was applied
+// A reload starts from these, so a key removed from the policy reverts to its default
+var policyDefaults map[string][]string
+
+// policyReloader re-applies the --config policy to a long-lived command and swaps in rebuilt components
+type policyReloader struct {
+	cmd     *cobra.Command
+	current atomic.Pointer[runComponents]
+	wrap    func(interfaces.FolderProcessor) interfaces.FolderProcessor // Applied to every rebuilt processor (nil = none)
+	mu      sync.Mutex                                                  // Serializes reloads
+}
+
+// newPolicyReloader builds the initial components of cmd; wrap, if given, decorates every processor
+func newPolicyReloader(cmd *cobra.Command, wrap func(interfaces.FolderProcessor) interfaces.FolderProcessor) (*policyReloader, error) {
+	r := &policyReloader{cmd: cmd, wrap: wrap}
+	components, err := r.build()
+	if err != nil {
+		return nil, err
+	}
+	r.current.Store(components)
+	return r, nil
+}
+
+// Components returns the components to use for the next run
+func (r *policyReloader) Components() *runComponents {
+	return r.current.Load()
+}
+
+// build creates components from the current flags
+func (r *policyReloader) build() (*runComponents, error) {
+	components, err := newRunComponents()
+	if err != nil {
+		return nil, err
+	}
+	if r.wrap != nil {
+		components.processor = r.wrap(components.processor)
+	}
+	return components, nil
+}
+
+// Reload reads the policy again, validates it by building new components and swaps them in
+// Runs in progress finish with the components they started with; on any error the running policy stays in force
+// It returns one line per changed flag
+func (r *policyReloader) Reload() ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if configLocation == "" {
+		return nil, errors.New("no policy to reload; start with --config")
+	}
+
+	policy, err := policySource().Load()
+	if err != nil {
+		return nil, err
+	}
+
+	previous := policyFlagValues(r.cmd)
+	setFlagValues(r.cmd, policyDefaults)
+	if err := applyPolicy(r.cmd, policy, policySource().IsRemote()); err != nil {
+		setFlagValues(r.cmd, previous)
+		return nil, err
+	}
+
+	components, err := r.build()
+	if err != nil {
+		setFlagValues(r.cmd, previous)
+		return nil, fmt.Errorf("policy rejected: %w", err)
+	}
+	r.current.Store(components)
+
+	return flagChanges(previous, policyFlagValues(r.cmd)), nil
+}
+
+// ReloadAndReport reloads the policy and writes the outcome to stderr, so the log of the command shows every reload
+func (r *policyReloader) ReloadAndReport() ([]string, error) {
+	changes, err := r.Reload()
+	reportReload(changes, err)
+	return changes, err
+}
+
+// policyFlagValues returns the values of the flags of cmd a policy may set, i.e. those not given on the command line
+func policyFlagValues(cmd *cobra.Command) map[string][]string {
+	values := make(map[string][]string)
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed || !policyOptions[flag.Name] {
+			return
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			values[flag.Name] = slice.GetSlice()
+		} else {
+			values[flag.Name] = []string{flag.Value.String()}
+		}
+	})
+	return values
+}
+
+// setFlagValues restores values captured by policyFlagValues
+func setFlagValues(cmd *cobra.Command, values map[string][]string) {
+	for name, value := range values {
+		flag := cmd.Flags().Lookup(name)
+		if flag == nil {
+			continue
+		}
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			slice.Replace(value)
+		} else if len(value) == 1 {
+			flag.Value.Set(value[0]) // The value came from the flag itself, so it parses
+		}
+	}
+}
+
+// flagChanges describes every flag whose value differs between before and after
+func flagChanges(before, after map[string][]string) []string {
+	var changes []string
+	for name, value := range after {
+		old := before[name]
+		if strings.Join(old, "\x00") == strings.Join(value, "\x00") {
+			continue
+		}
+		change := fmt.Sprintf("--%s: %s -> %s", name, formatFlagValue(old), formatFlagValue(value))
+		if !reloadableFlags[name] {
+			change += " (takes effect after a restart)"
+		}
+		changes = append(changes, change)
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+// formatFlagValue shows a flag value in a change report
+func formatFlagValue(value []string) string {
+	if len(value) == 1 {
+		return fmt.Sprintf("%q", value[0])
+	}
+	return fmt.Sprintf("%q", value)
+}
+
+// reportReload writes the outcome of a reload to stderr
+func reportReload(changes []string, err error) {
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Policy reload failed, keeping the current policy: %v\n", err)
+	case len(changes) == 0:
+		fmt.Fprintf(os.Stderr, "Policy reloaded from %s: nothing changed\n", configLocation)
+	default:
+		fmt.Fprintf(os.Stderr, "Policy reloaded from %s:\n", configLocation)
+		for _, change := range changes {
+			fmt.Fprintf(os.Stderr, "  %s\n", change)
+		}
+	}
+}
+
+// notifyReload reloads the policy on every SIGHUP until the returned function is called
+// Windows has no SIGHUP; use the API of the command there
+func notifyReload(reloader *policyReloader) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-signals:
+				reloader.ReloadAndReport()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/sidecar"
+	"github.com/punkscience/sanitize/internal/walker"
+	"github.com/punkscience/sanitize/internal/xattr"
+)
+
+// Flags for name mapping files and the restore subcommand
+var (
+	sidecarMode   string // Where renames record the original names: dir, root or empty for nowhere
+	restoreDryRun bool   // Show what restore would rename without renaming
+)
+
+// restoreCmd renames folders back to the original names recorded in mapping files
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Rename folders back to the original names recorded in " + sidecar.FileName + " files",
+	Long: `Restore finds the ` + sidecar.FileName + ` files written by runs with --sidecar-map
+below --path and renames every recorded folder back to its original name, deepest
+folder first.
+
+Restored entries are removed from the mapping files, and files without entries
+are deleted. Folders whose original name is in use again are reported as errors
+and stay in the mapping file.
+
+With --from-xattr the original names come from the ` + xattr.Attribute + ` extended
+attributes written by runs with --xattr instead, and restored folders lose the attribute.`,
+	Example: `  sanitize restore --path ./music --dry-run
+  sanitize restore --path ./music`,
+	Args: cobra.NoArgs,
+	RunE: runRestore,
+}
+
+// runRestore restores the recorded names and reports the outcome like a regular run
+func runRestore(cmd *cobra.Command, args []string) error {
+	absPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("error resolving path: %w", err)
+	}
+
+	if err := validatePath(absPath); err != nil {
+		return err
+	}
+	if !restoreDryRun {
+		if err := refuseSystemLocation(absPath); err != nil {
+			return err
+		}
+	}
+
+	// The root is included because it holds the manifest written with --sidecar-map root;
+	// attributes are only read below it, since the root itself is never renamed
+	var restoreOptions []sidecar.RestoreOption
+	if restoreFromXattr {
+		if !xattr.Supported() {
+			return fmt.Errorf("--from-xattr: %w", xattr.ErrUnsupported)
+		}
+		restoreOptions = append(restoreOptions, sidecar.FromAttributes(xattr.Store{}))
+	}
+	options, err := walkerOptions()
+	if err != nil {
+		return err
+	}
+	folders, err := walker.NewFileSystemWalker(true, 0, append(options, walker.WithRootIncluded(!restoreFromXattr))...).Walk(absPath)
+	if err != nil {
+		return fmt.Errorf("error scanning for name mappings: %w", err)
+	}
+
+	progressReporter, closeReporter, err := newProgressReporter(restoreDryRun)
+	if err != nil {
+		return err
+	}
+	defer closeReporter()
+
+	summary := sidecar.Restore(newFileSystem(), folders, restoreDryRun, withLogReporter(progressReporter, restoreDryRun), restoreOptions...)
+	if summary.ErrorCount > 0 {
+		return withExitCode(exitErrors, fmt.Errorf("restore completed with %d errors", summary.ErrorCount))
+	}
+	if summary.Phases.Applied > 0 {
+		exitStatus = exitChanged
+	}
+
+	return nil
+}
+
+// newSidecarRecorder creates the recorder selected by --sidecar-map, or nil without one or in a dry run
+func newSidecarRecorder(root string, dryRun bool) (*sidecar.Recorder, error) {
+	if sidecarMode == "" {
+		return nil, nil
+	}
+	recorder, err := sidecar.NewRecorder(root, sidecarMode, newFileSystem())
+	if err != nil || dryRun {
+		return nil, err
+	}
+	return recorder, nil
+}
+
+// addSidecarFlag registers --sidecar-map on a command that renames folders
+func addSidecarFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&sidecarMode, "sidecar-map", "", "Record original names in "+sidecar.FileName+" files for restore: "+strings.Join(sidecar.Modes, " (in each parent) or ")+" (one manifest)")
+}
+
+func init() {
+	addSidecarFlag(rootCmd)
+	addSidecarFlag(applyCmd)
+	addForceFlag(restoreCmd)
+	restoreCmd.Flags().BoolVarP(&restoreDryRun, "dry-run", "d", false, "Show what would be restored without renaming anything")
+	rootCmd.AddCommand(restoreCmd)
+}
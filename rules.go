@@ -0,0 +1,105 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/corpus"
+	"github.com/punkscience/sanitize/internal/sanitizer"
+)
+
+// Flags for the rules test subcommand
+var corpusDir string // Directory of corpus files to check the naming pipeline against
+
+// errCorpusMismatch is returned by rules test so the process exits with 1, like check with violations
+var errCorpusMismatch = errors.New("corpus cases failed")
+
+// rulesCmd groups the subcommands for authors of naming rules
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Tools for authors of naming policies and custom rules",
+	Args:  cobra.NoArgs,
+}
+
+// rulesStagesCmd lists the stages of the sanitizer pipeline
+var rulesStagesCmd = &cobra.Command{
+	Use:   "stages",
+	Short: "List the sanitizer pipeline stages usable with --pipeline, in default order",
+	Args:  cobra.NoArgs,
+	Run:   runRulesStages,
+}
+
+// rulesTestCmd checks the naming pipeline against a golden corpus
+var rulesTestCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Check the active naming rules against a corpus of names and their expected results",
+	Long: `Test sanitizes every name of a golden corpus with the active naming pipeline
+(--profile, --rules-file, --reserved-words, --config and the other naming flags)
+and reports every name whose result differs from the expected one.
+
+A corpus is a directory of ` + corpus.Extension + ` files, one case per line as INPUT<tab>EXPECTED.
+Blank lines and lines starting with # are ignored; either side can be written as a
+Go quoted string for names with surrounding spaces or control characters.
+
+The command exits non-zero if any case fails, so a naming policy can be tested
+in CI before it is rolled out.`,
+	Example: `  sanitize rules test --corpus ./naming-corpus --rules-file house.rules`,
+	Args:    cobra.NoArgs,
+	// Failing cases are an expected outcome, so don't print usage on failure
+	SilenceUsage: true,
+	RunE:         runRulesTest,
+}
+
+// runRulesTest runs the corpus and prints every mismatch
+func runRulesTest(cmd *cobra.Command, args []string) error {
+	if corpusDir == "" {
+		return errors.New("--corpus is required")
+	}
+
+	folderSanitizer, err := newFolderSanitizer()
+	if err != nil {
+		return err
+	}
+
+	cases, err := corpus.Load(corpusDir)
+	if err != nil {
+		return err
+	}
+
+	mismatches := corpus.Run(folderSanitizer, cases)
+	out := cmd.OutOrStdout()
+	for _, mismatch := range mismatches {
+		fmt.Fprintf(out, "%s:%d: %q\n", mismatch.File, mismatch.Line, mismatch.Input)
+		fmt.Fprintf(out, "  expected: %q\n", mismatch.Expected)
+		fmt.Fprintf(out, "  got:      %q\n", mismatch.Got)
+		if len(mismatch.Rules) > 0 {
+			fmt.Fprintf(out, "  rules:    %s\n", strings.Join(mismatch.Rules, ", "))
+		}
+	}
+
+	if len(mismatches) > 0 {
+		fmt.Fprintf(out, "\n%d of %d cases failed.\n", len(mismatches), len(cases))
+		return withExitCode(exitChanged, errCorpusMismatch)
+	}
+	fmt.Fprintf(out, "All %d cases passed.\n", len(cases))
+	return nil
+}
+
+// runRulesStages prints every stage with what it does
+func runRulesStages(cmd *cobra.Command, args []string) {
+	out := cmd.OutOrStdout()
+	for _, name := range sanitizer.Stages() {
+		fmt.Fprintf(out, "%-14s %s\n", name, sanitizer.StageDescription(name))
+	}
+	fmt.Fprintf(out, "%-14s %s\n", sanitizer.ProfilePrefix+"NAME", "apply every stage of a built-in profile: "+strings.Join(sanitizer.ProfileNames(), ", "))
+}
+
+// init registers the rules subcommands and their flags
+func init() {
+	rulesTestCmd.Flags().StringVar(&corpusDir, "corpus", "", "Directory of "+corpus.Extension+" corpus files (INPUT<tab>EXPECTED per line)")
+	rulesCmd.AddCommand(rulesStagesCmd, rulesTestCmd)
+	rootCmd.AddCommand(rulesCmd)
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/journal"
+	"github.com/punkscience/sanitize/internal/paths"
+)
+
+// Flags for the scan, plan, apply and undo subcommands
+var (
+	planOutput  string // Where plan writes the planned renames
+	planCompare string // Earlier plan that plan reports the differences to
+	planFile    string // Plan that apply performs instead of scanning the tree
+	journalFile string // Where apply records the renames it performed
+	verifyAfter bool   // Re-check the applied renames once the run is over
+	auditFile   string // Append-only compliance log of every performed rename
+	undoJournal string // Journal whose renames undo reverses
+	undoDryRun  bool   // Show what undo would restore without renaming
+)
+
+// scanCmd shows what would be renamed without changing anything
+var scanCmd = &cobra.Command{
+	Use:   "scan",
+	Short: "Show which folders would be renamed, without changing anything",
+	Long: `Scan walks the folder tree and reports every folder that would be renamed and
+its new name, exactly like a real run but without touching the file system.
+
+Unlike check, scan exits zero when non-compliant names are found.`,
+	Example: `  sanitize scan --path ./photos -v`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTree(true, "")
+	},
+}
+
+// planCmd saves the renames a run would perform for review and a later apply
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Save the renames a run would perform to a plan file",
+	Long: `Plan walks the folder tree like scan and writes every rename it would perform,
+including collision suffixes, to a JSON plan file.
+
+After review, "sanitize apply --plan FILE" performs exactly the planned renames,
+even if the naming rules have changed in the meantime.
+
+With --compare, the new plan is compared with an earlier one and every rename
+that was added, removed or now has a different target is listed, so a reviewer
+who approved the earlier plan only needs to review the differences.`,
+	Example: `  sanitize plan --path /srv/share --output plan.json
+  sanitize plan --path /srv/share --output plan.json --compare last-week.json
+  sanitize apply --path /srv/share --plan plan.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTree(true, planOutput)
+	},
+}
+
+// applyCmd renames folders, either by scanning the tree or from a reviewed plan
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Rename non-compliant folders",
+	Long: `Apply walks the folder tree and renames every non-compliant folder, or performs
+exactly the renames of a plan file created by "sanitize plan".
+
+Before the first rename, apply prints how many folders will be renamed and how
+many collisions were resolved, and asks for confirmation. --yes skips the
+question; without a terminal, nothing is renamed unless --yes is given.
+
+With --journal (or --state-dir) every performed rename is recorded, so the run
+can be reverted with "sanitize undo".
+
+With --verify, the parents of all renamed folders are listed once more after
+the run, and every rename whose new name is missing or whose old name exists
+again, e.g. because another process renamed things concurrently, is listed in
+the summary.`,
+	Example: `  sanitize apply --path ./photos --journal journal.json --verify
+  sanitize apply --path /srv/share --plan plan.json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTree(false, "")
+	},
+}
+
+// undoCmd reverts the renames recorded in a journal
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the renames recorded in a journal",
+	Long: `Undo renames every folder recorded in a journal written by "sanitize apply"
+back to its original name, most recent rename first.
+
+Folders that were merged into another folder cannot be separated again and are
+reported as errors, as are folders whose original path is in use again.
+Relative journals are resolved against --path, or the journal's own root if
+--path is not given.`,
+	Example: `  sanitize undo --journal journal.json --dry-run
+  sanitize undo --journal journal.json`,
+	Args: cobra.NoArgs,
+	RunE: runUndo,
+}
+
+// runUndo reverses a journal and reports the outcome like a regular run
+func runUndo(cmd *cobra.Command, args []string) error {
+	root := ""
+	if cmd.Flags().Changed("path") {
+		absPath, err := filepath.Abs(rootPath)
+		if err != nil {
+			return fmt.Errorf("error resolving path: %w", err)
+		}
+		root = absPath
+	}
+
+	file, err := journal.Load(undoJournal, journal.KindJournal, root)
+	if err != nil {
+		return err
+	}
+
+	progressReporter, closeReporter, err := newProgressReporter(undoDryRun)
+	if err != nil {
+		return err
+	}
+	defer closeReporter()
+
+	summary := journal.Undo(newFileSystem(), file, undoDryRun, progressReporter)
+	if summary.ErrorCount > 0 {
+		return withExitCode(exitErrors, fmt.Errorf("undo completed with %d errors", summary.ErrorCount))
+	}
+	if summary.Phases.Applied > 0 {
+		exitStatus = exitChanged
+	}
+
+	return nil
+}
+
+// printPlanComparison lists the renames added, removed and retargeted since an earlier plan
+// Paths are shown relative to root with --relative-paths, like in every other report
+func printPlanComparison(comparison journal.Comparison, root string) {
+	out := os.Stdout
+	if progressJSON {
+		out = os.Stderr // Stdout is reserved for JSON records
+	}
+	display := func(path string) string {
+		if relativePaths {
+			return paths.Relative(root, path)
+		}
+		return path
+	}
+	target := func(entry journal.Entry) string {
+		if entry.Merged {
+			return display(entry.NewPath) + " (merged)"
+		}
+		return display(entry.NewPath)
+	}
+
+	if comparison.Empty() {
+		fmt.Fprintf(out, "\nThe plan is unchanged since %s.\n", planCompare)
+		return
+	}
+	fmt.Fprintf(out, "\nChanges since %s: %d added, %d removed, %d changed\n",
+		planCompare, len(comparison.Added), len(comparison.Removed), len(comparison.Changed))
+	for _, entry := range comparison.Added {
+		fmt.Fprintf(out, "  + %s -> %s\n", display(entry.OldPath), target(entry))
+	}
+	for _, entry := range comparison.Removed {
+		fmt.Fprintf(out, "  - %s -> %s\n", display(entry.OldPath), target(entry))
+	}
+	for _, change := range comparison.Changed {
+		fmt.Fprintf(out, "  ~ %s -> %s (was %s)\n", display(change.OldPath), target(change.After), target(change.Before))
+	}
+}
+
+// init registers the run mode subcommands and their flags
+func init() {
+	addRunFlags(scanCmd)
+
+	addRunFlags(planCmd)
+	planCmd.Flags().StringVarP(&planOutput, "output", "o", "plan.json", "File to write the plan to")
+	planCmd.Flags().StringVar(&planCompare, "compare", "", "List how the new plan differs from this earlier plan file")
+
+	addRunFlags(applyCmd)
+	addForceFlag(applyCmd)
+	addYesFlag(applyCmd)
+	applyCmd.Flags().StringVar(&planFile, "plan", "", "Perform exactly the renames of this plan instead of scanning the tree")
+	applyCmd.Flags().StringVar(&journalFile, "journal", "", "Record every performed rename in this JSON journal for undo")
+	applyCmd.Flags().BoolVar(&verifyAfter, "verify", false, "Re-check every applied rename after the run and list discrepancies in the summary")
+	applyCmd.Flags().StringVar(&auditFile, "audit-log", "", "Append every performed rename with user, time and checksums of the directory listing to this file")
+
+	undoCmd.Flags().StringVar(&undoJournal, "journal", "", "Journal written by apply")
+	undoCmd.Flags().BoolVarP(&undoDryRun, "dry-run", "d", false, "Show what would be restored without renaming anything")
+	_ = undoCmd.MarkFlagRequired("journal")
+
+	rootCmd.AddCommand(scanCmd, planCmd, applyCmd, undoCmd)
+}
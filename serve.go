@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/web"
+)
+
+// Flags for the serve subcommand
+var (
+	serveWeb       bool          // Serve the embedded web UI
+	serveListen    string        // Address the server listens on
+	linkKey        string        // Key guest links are signed with; deprecated, since other users can read it in the process list
+	linkKeyFile    string        // File holding the key guest links are signed with
+	linkTTL        time.Duration // Default lifetime of guest links
+	serveKeepalive time.Duration // Interval of the root stat that keeps network sessions alive (0 = off)
+)
+
+// serveCmd runs a long-lived server for interacting with a tree
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a browser UI for reviewing and applying renames",
+	Long: `Serve starts a small HTTP server for the folder tree at --path.
+
+With --web, a single-page UI is served that shows the current plan (every
+folder that would be renamed), lets you approve individual items, applies
+the approved renames and shows progress while they run. This is convenient
+on NAS appliances where SSH and a terminal UI are awkward.
+
+Every apply run is recorded, and the UI can create expiring read-only guest
+links to a run's report, optionally limited to one folder, so data owners can
+review what was renamed in their area without an account on the server. Runs
+are kept in memory; set SANITIZE_LINK_KEY or pass --link-key-file to keep links
+verifiable across restarts.
+
+The --config policy is reloaded on SIGHUP or with a POST to /api/reload. The
+new policy is validated first and applies from the next plan or apply run on;
+if it is invalid, the running policy stays in force. Every changed setting is
+logged.
+
+Network mounts may drop idle sessions while a plan waits for approval. With
+--keepalive, the root is checked at that interval; if the share was lost in
+between, applying is refused until it is back, and the plan is then re-validated
+automatically, keeping the approvals of unchanged items.
+
+The server listens on 127.0.0.1 by default. Requests that change anything
+must carry the session token printed at startup, be sent as JSON and come
+from the UI's own origin, so other web pages can't approve or apply renames.
+Open the printed URL, which passes the token to the UI. Anyone who can reach
+the server can still read the plan; only bind it to other interfaces on
+trusted networks.`,
+	Example: `  sanitize serve --web --path /volume1/share
+  sanitize serve --web --path /volume1/share --listen 0.0.0.0:8080`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+// runServe starts the selected server mode
+func runServe(cmd *cobra.Command, args []string) error {
+	if !serveWeb {
+		return errors.New("no server mode selected; use --web")
+	}
+
+	absPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("error resolving path: %w", err)
+	}
+
+	if err := validatePath(absPath); err != nil {
+		return err
+	}
+	if err := refuseSystemLocation(absPath); err != nil {
+		return err
+	}
+
+	// The sanitizer is stateless, so one validated instance serves every plan and apply run until the policy is reloaded
+	reloader, err := newPolicyReloader(cmd, nil)
+	if err != nil {
+		return err
+	}
+	defer notifyReload(reloader)()
+
+	key, err := resolveLinkKey()
+	if err != nil {
+		return err
+	}
+
+	server := web.NewServer(absPath, web.ComponentFactory{
+		Sanitizer: func() interfaces.FolderSanitizer {
+			return reloader.Components().sanitizer
+		},
+		Walker: func() interfaces.DirectoryWalker {
+			return reloader.Components().newWalker()
+		},
+		Processor: func() interfaces.FolderProcessor {
+			return newFolderProcessor(newFileSystem())
+		},
+	}, web.WithLinkKey(key), web.WithLinkTTL(linkTTL), web.WithReloader(reloader.ReloadAndReport),
+		web.WithKeepalive(newFileSystem(), serveKeepalive))
+
+	fmt.Fprintf(cmd.OutOrStdout(), "Serving web UI for %s at http://%s/#token=%s\n", absPath, serveListen, server.SessionToken())
+
+	return server.ListenAndServe(serveListen)
+}
+
+// linkKeyEnv names the environment variable holding the key guest links are signed with
+const linkKeyEnv = "SANITIZE_LINK_KEY"
+
+// resolveLinkKey returns the key guest links are signed with: from --link-key-file, SANITIZE_LINK_KEY or the deprecated --link-key
+// An empty key makes the server sign links with a random key per process
+func resolveLinkKey() (string, error) {
+	if linkKeyFile != "" {
+		content, err := os.ReadFile(linkKeyFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read --link-key-file: %w", err)
+		}
+		key := strings.TrimSpace(string(content))
+		if key == "" {
+			return "", fmt.Errorf("--link-key-file %s is empty", linkKeyFile)
+		}
+		return key, nil
+	}
+	if key := os.Getenv(linkKeyEnv); key != "" {
+		return key, nil
+	}
+	return linkKey, nil
+}
+
+// init registers the serve subcommand and its flags
+func init() {
+	serveCmd.Flags().BoolVar(&serveWeb, "web", false, "Serve the embedded web UI")
+	addForceFlag(serveCmd)
+	serveCmd.Flags().StringVar(&serveListen, "listen", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().StringVar(&linkKeyFile, "link-key-file", "", "File holding the key for signing guest links (default: $SANITIZE_LINK_KEY, or random per process)")
+	serveCmd.Flags().StringVar(&linkKey, "link-key", "", "Key for signing guest links")
+	serveCmd.Flags().MarkDeprecated("link-key", "other users can read it in the process list; use --link-key-file or SANITIZE_LINK_KEY instead")
+	serveCmd.Flags().DurationVar(&linkTTL, "link-ttl", 72*time.Hour, "Default lifetime of guest links")
+	serveCmd.Flags().DurationVar(&serveKeepalive, "keepalive", 0, "Check the root at this interval so network mounts keep the session while a plan awaits approval (0 = off)")
+	serveCmd.Flags().BoolVar(&merge, "merge", false, "Merge colliding folders into the existing folder instead of renaming with a numeric suffix")
+	rootCmd.AddCommand(serveCmd)
+}
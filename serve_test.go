@@ -0,0 +1,56 @@
+// Tests for the serve subcommand.
+// This test suite ensures the guest link key can be passed without showing up in the process list.
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestResolveLinkKey tests that --link-key-file wins over SANITIZE_LINK_KEY, which wins over the deprecated --link-key
+func TestResolveLinkKey(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "link.key")
+	if err := os.WriteFile(keyFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty.key")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		env     string
+		flag    string
+		want    string
+		wantErr bool
+	}{
+		{name: "nothing set", want: ""},
+		{name: "deprecated flag", flag: "from-flag", want: "from-flag"},
+		{name: "environment", env: "from-env", flag: "from-flag", want: "from-env"},
+		{name: "file", file: keyFile, env: "from-env", flag: "from-flag", want: "from-file"},
+		{name: "empty file", file: emptyFile, wantErr: true},
+		{name: "missing file", file: filepath.Join(dir, "missing.key"), wantErr: true},
+	}
+
+	savedFile, savedKey := linkKeyFile, linkKey
+	t.Cleanup(func() { linkKeyFile, linkKey = savedFile, savedKey })
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(linkKeyEnv, tt.env)
+			linkKeyFile, linkKey = tt.file, tt.flag
+
+			got, err := resolveLinkKey()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveLinkKey() error = %v, want an error = %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveLinkKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/punkscience/sanitize/internal/reporter"
+)
+
+// verbosity is how much the console reporters show, resolved from --quiet and -v before every command
+var verbosity = reporter.VerbosityWarn
+
+// consoleSupport describes what the console stdout is attached to can display
+type consoleSupport struct {
+	escapes bool // ANSI escape sequences for colors and the TUI
+	unicode bool // Emoji and box-drawing characters
+}
+
+// console is what the console can display, detected before every command
+var console = consoleSupport{escapes: true, unicode: true}
+
+// stdoutIsTerminal reports whether stdout is an interactive terminal rather than a pipe or file
+// It is a variable so tests can resolve the output as if a terminal were attached
+var stdoutIsTerminal = func() bool {
+	fd := os.Stdout.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// colorOutput reports whether human-readable output may use ANSI colors
+// Color is off with --no-color or --accessible, when NO_COLOR is set (https://no-color.org), for dumb terminals and when stdout is not a terminal
+func colorOutput() bool {
+	if noColor || accessible || !console.escapes || os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return stdoutIsTerminal()
+}
+
+// resolveOutput adapts the output to the console, decides whether the TUI is used and sets verbosity from --quiet and -v
+// The TUI has no quiet mode, so --quiet is rejected together with a TUI that would actually be shown
+func resolveOutput() error {
+	console = prepareConsole()
+	if !console.unicode {
+		asciiOutput = true
+	}
+	resolveTUI()
+	if quiet && tui && !accessible {
+		return fmt.Errorf("--quiet can't be combined with --tui")
+	}
+	resolved, err := reporter.NewVerbosity(quiet, verbose)
+	if err != nil {
+		return err
+	}
+	verbosity = resolved
+	return nil
+}
+
+// resolveTUI turns --tui off with --no-tui, and when stdout is not a terminal that can show it
+// Under nohup, cron or CI the escape codes of the alt-screen TUI would end up in the log, so plain output is used instead
+func resolveTUI() {
+	switch {
+	case !tui:
+	case noTUI:
+		tui = false
+	case !stdoutIsTerminal() || !console.escapes || os.Getenv("TERM") == "dumb":
+		tui = false
+		if !quiet {
+			fmt.Fprintln(os.Stderr, "Not a terminal: using plain output instead of the TUI")
+		}
+	}
+}
+
+// stdinIsTerminal reports whether stdin is an interactive terminal that can answer questions
+func stdinIsTerminal() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// canPrompt reports whether a question can be asked: stdin is a terminal and neither the TUI nor JSON progress owns the screen
+func canPrompt() bool {
+	return stdinIsTerminal() && !tui && !progressJSON
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin; anything but y or yes declines
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "\n%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
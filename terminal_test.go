@@ -0,0 +1,83 @@
+// Tests for adapting the output to the console.
+// This test suite ensures the TUI is only used on a terminal that can show it and never with --no-tui.
+package main
+
+import (
+	"testing"
+)
+
+// TestResolveTUI tests when --tui is kept, and that --no-tui and plain consoles turn it off
+func TestResolveTUI(t *testing.T) {
+	tests := []struct {
+		name     string
+		tui      bool
+		noTUI    bool
+		terminal bool
+		term     string
+		escapes  bool
+		want     bool
+	}{
+		{name: "tui on a terminal", tui: true, terminal: true, term: "xterm-256color", escapes: true, want: true},
+		{name: "no tui requested", tui: false, terminal: true, term: "xterm-256color", escapes: true, want: false},
+		{name: "no-tui wins over tui", tui: true, noTUI: true, terminal: true, term: "xterm-256color", escapes: true, want: false},
+		{name: "stdout is not a terminal", tui: true, terminal: false, term: "xterm-256color", escapes: true, want: false},
+		{name: "dumb terminal", tui: true, terminal: true, term: "dumb", escapes: true, want: false},
+		{name: "console without escape sequences", tui: true, terminal: true, term: "xterm-256color", escapes: false, want: false},
+	}
+
+	savedTUI, savedNoTUI, savedQuiet, savedConsole, savedTerminal := tui, noTUI, quiet, console, stdoutIsTerminal
+	t.Cleanup(func() {
+		tui, noTUI, quiet, console, stdoutIsTerminal = savedTUI, savedNoTUI, savedQuiet, savedConsole, savedTerminal
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TERM", tt.term)
+			terminal := tt.terminal
+			stdoutIsTerminal = func() bool { return terminal }
+			tui, noTUI, quiet = tt.tui, tt.noTUI, true // quiet keeps the fallback notice out of the test output
+			console = consoleSupport{escapes: tt.escapes, unicode: true}
+
+			resolveTUI()
+			if tui != tt.want {
+				t.Errorf("resolveTUI() left tui = %v, want %v", tui, tt.want)
+			}
+		})
+	}
+}
+
+// TestResolveOutput_QuietTUI tests that --quiet only conflicts with a TUI that would actually be shown
+func TestResolveOutput_QuietTUI(t *testing.T) {
+	tests := []struct {
+		name     string
+		noTUI    bool
+		terminal bool
+		wantErr  bool
+	}{
+		{name: "tui on a terminal", terminal: true, wantErr: true},
+		{name: "tui turned off by no-tui", noTUI: true, terminal: true, wantErr: false},
+		{name: "tui falls back to plain output", terminal: false, wantErr: false},
+	}
+
+	savedTUI, savedNoTUI, savedQuiet, savedAccessible, savedConsole, savedTerminal, savedVerbosity := tui, noTUI, quiet, accessible, console, stdoutIsTerminal, verbosity
+	t.Cleanup(func() {
+		tui, noTUI, quiet, accessible, console, stdoutIsTerminal, verbosity = savedTUI, savedNoTUI, savedQuiet, savedAccessible, savedConsole, savedTerminal, savedVerbosity
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TERM", "xterm-256color")
+			terminal := tt.terminal
+			stdoutIsTerminal = func() bool { return terminal }
+			tui, noTUI, quiet, accessible = true, tt.noTUI, true, false
+
+			err := resolveOutput()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveOutput() error = %v, want an error = %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && tui {
+				t.Error("Expected the TUI to be turned off")
+			}
+		})
+	}
+}
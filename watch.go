@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/audit"
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/reporter"
+	"github.com/punkscience/sanitize/internal/service"
+	"github.com/punkscience/sanitize/internal/walker"
+	"github.com/punkscience/sanitize/internal/watch"
+)
+
+// Flags for the watch subcommand
+var (
+	watchDryRun  bool          // Log what would be renamed without renaming
+	watchEvents  string        // Event source: native or poll
+	pollInterval time.Duration // How often the polling event source lists the tree
+	settleTime   time.Duration // How long a new directory must be quiet before it is sanitized
+	checkOpen    bool          // Also wait while files in a new directory are open
+	renameQuota  int           // Renames allowed per quota window before approval is required (0 = unlimited)
+	quotaWindow  time.Duration // Length of the quota window
+	controlPath  string        // Unix domain socket for approving held directories and reloading the policy
+	watchAudit   string        // Append-only compliance log of every performed rename
+)
+
+// watchCmd sanitizes new directories as soon as they appear
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Sanitize new directories as soon as they are created",
+	Long: `Watch monitors the folder tree at --path and sanitizes every directory created
+below it as soon as it appears, together with any directories inside it. This
+closes the window a periodic run leaves for pipelines that keep dropping
+folders with incompatible names.
+
+Directories that already exist when watching starts are left alone; run
+"sanitize apply" once first to clean them up. Protected directories and
+marker files are honored like in a regular run. Every rename and error is
+logged with a timestamp. Press Ctrl-C to stop watching.
+
+By default the operating system reports new directories (inotify on Linux,
+ReadDirectoryChangesW on Windows, kqueue on BSD and macOS). File systems that
+don't deliver these notifications, such as many clustered and network file
+systems, can be watched with --events poll, which lists every directory of the
+tree each --poll-interval instead.
+
+A new directory is only sanitized once nothing inside it changed for --settle,
+so copies and downloads in progress are not renamed mid-transfer. Any new file,
+write or removal in the tree restarts the wait. On Linux the directory also
+waits while a process visible to you holds a file in it open; turn that off
+with --check-open-files=false. Directories still settling when watching stops
+are left for the next run.
+
+A mass influx of bad names usually means an upstream system misbehaves. With
+--rename-quota, at most that many folders are renamed per --quota-window;
+once a rename would exceed the quota, renaming halts and new directories are
+held until someone runs "sanitize watch approve" (rename the held directories
+and continue) or "sanitize watch discard" (leave them for a regular run and
+continue) against --control-socket. "sanitize watch status" shows the quota.
+
+The --config policy is reloaded on SIGHUP or with "sanitize watch reload". The
+new policy is validated first and only used from the next directory on; if it
+is invalid, the running policy stays in force. Every changed setting is logged.`,
+	Example: `  sanitize apply --path /srv/ingest
+  sanitize watch --path /srv/ingest
+  sanitize watch --path /mnt/cluster --events poll --poll-interval 30s
+  sanitize watch --path /srv/ingest --settle 1m
+  sanitize watch --path /srv/ingest --rename-quota 100 --control-socket /run/sanitize.sock
+  sanitize watch status --control-socket /run/sanitize.sock
+  sanitize watch reload --control-socket /run/sanitize.sock`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+// runWatch watches the tree and sanitizes new directories until interrupted
+func runWatch(cmd *cobra.Command, args []string) error {
+	absPath, err := filepath.Abs(rootPath)
+	if err != nil {
+		return fmt.Errorf("error resolving path: %w", err)
+	}
+
+	if err := validatePath(absPath); err != nil {
+		return err
+	}
+	if !watchDryRun {
+		if err := refuseSystemLocation(absPath); err != nil {
+			return err
+		}
+	}
+
+	quota, err := newQuota()
+	if err != nil {
+		return err
+	}
+	auditLog, err := openWatchAudit()
+	if err != nil {
+		return err
+	}
+	if auditLog != nil {
+		defer auditLog.Close()
+	}
+	// Renames held by the quota never reach the audit, which only records performed renames
+	wrap := func(processor interfaces.FolderProcessor) interfaces.FolderProcessor {
+		if auditLog != nil {
+			processor = auditLog.Processor(processor)
+		}
+		if quota != nil {
+			processor = quota.Processor(processor)
+		}
+		return processor
+	}
+
+	// The components are stateless, so one set serves every new directory until the policy is reloaded
+	reloader, err := newPolicyReloader(cmd, wrap)
+	if err != nil {
+		return err
+	}
+	progressReporter := withLogReporter(reporter.NewWatchReporter(verbosity, watchDryRun, colorOutput()), watchDryRun)
+
+	if controlPath != "" {
+		stopControl, err := serveControl(watch.Control{Quota: quota, Reload: reloader.ReloadAndReport})
+		if err != nil {
+			return err
+		}
+		defer stopControl()
+	}
+	defer notifyReload(reloader)()
+
+	// A new directory is processed like a tree of its own, with paths still reported against --path
+	sanitizeDir := func(dir string) error {
+		components := reloader.Components()
+		subtreeWalker := walker.NewSubtreeWalker(components.newWalker(walker.WithRootIncluded(true)), dir)
+		if dir == absPath {
+			subtreeWalker = components.newWalker() // Never rename the watched root itself
+		}
+		return service.NewSanitizeService(
+			components.sanitizer,
+			subtreeWalker,
+			components.processor,
+			progressReporter,
+			service.WithRelativePaths(components.relativePaths),
+			service.WithRunID(runID),
+		).SanitizeDirectory(absPath, watchDryRun)
+	}
+
+	if settleTime < 0 {
+		return fmt.Errorf("--settle must not be negative")
+	}
+
+	source, err := newEventSource()
+	if err != nil {
+		return err
+	}
+
+	watcher := watch.NewWatcher(absPath, sanitizeDir,
+		watch.WithEventSource(source),
+		watch.WithFileSystem(newFileSystem()),
+		watch.WithSkip(func(name string) bool {
+			return reloader.Components().protection.IsProtectedName(name)
+		}),
+		watch.WithErrorHandler(progressReporter.ReportError),
+		watch.WithSettle(settleTime),
+		watch.WithOpenFileCheck(checkOpen && watch.OpenFilesDetectable()),
+		watch.WithQuota(quota),
+		watch.WithReadyHandler(func(directories int) {
+			fmt.Fprintf(os.Stderr, "Watching %d directories below %s. Press Ctrl-C to stop.\n", directories, absPath)
+		}),
+	)
+
+	interrupt, stopInterrupt := notifyInterrupt("Interrupted: finishing the current directory and stopping. Press Ctrl-C again to stop immediately.")
+	defer stopInterrupt()
+
+	return watcher.Run(interrupt)
+}
+
+// openWatchAudit opens the compliance log selected by --audit-log, or returns nil without one or in a dry run
+func openWatchAudit() (*audit.Log, error) {
+	if watchAudit == "" || watchDryRun {
+		return nil, nil
+	}
+	return audit.Open(watchAudit, newFileSystem(), runID)
+}
+
+// newQuota creates the rename quota selected by --rename-quota, or nil without one
+func newQuota() (*watch.Quota, error) {
+	switch {
+	case renameQuota < 0:
+		return nil, fmt.Errorf("--rename-quota must not be negative")
+	case renameQuota == 0:
+		return nil, nil
+	case controlPath == "":
+		return nil, fmt.Errorf("--rename-quota needs --control-socket, so held directories can be approved")
+	case quotaWindow <= 0:
+		return nil, fmt.Errorf("--quota-window must be positive")
+	}
+	return watch.NewQuota(renameQuota, quotaWindow), nil
+}
+
+// serveControl answers control requests on --control-socket until the returned function is called
+func serveControl(control watch.Control) (func(), error) {
+	listener, err := watch.ListenControl(controlPath)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &http.Server{Handler: watch.NewControlHandler(control)}
+	go server.Serve(listener)
+	return func() {
+		server.Close()
+		os.Remove(controlPath)
+	}, nil
+}
+
+// newEventSource creates the event source selected by --events
+func newEventSource() (watch.EventSource, error) {
+	switch watchEvents {
+	case "native":
+		return watch.NewNativeSource()
+	case "poll":
+		if pollInterval <= 0 {
+			return nil, fmt.Errorf("--poll-interval must be positive")
+		}
+		return watch.NewPollingSource(newFileSystem(), pollInterval), nil
+	default:
+		return nil, fmt.Errorf("unknown event source %q (want native or poll)", watchEvents)
+	}
+}
+
+// init registers the watch subcommand and its flags
+func init() {
+	watchCmd.Flags().BoolVarP(&watchDryRun, "dry-run", "d", false, "Log what would be renamed without renaming anything")
+	addForceFlag(watchCmd)
+	watchCmd.Flags().StringVar(&watchEvents, "events", "native", "How new directories are noticed: native (operating system notifications) or poll (for file systems without them)")
+	watchCmd.Flags().DurationVar(&pollInterval, "poll-interval", 10*time.Second, "How often --events poll lists the tree")
+
+	watchCmd.Flags().DurationVar(&settleTime, "settle", 5*time.Second, "How long a new directory must go without changes before it is sanitized (0 = immediately)")
+	watchCmd.Flags().BoolVar(&checkOpen, "check-open-files", true, "Also wait while files in a new directory are open (Linux only)")
+	watchCmd.Flags().IntVar(&renameQuota, "rename-quota", 0, "Renames allowed per --quota-window; beyond that, new directories are held for approval (0 = unlimited)")
+	watchCmd.Flags().DurationVar(&quotaWindow, "quota-window", time.Hour, "Length of the window --rename-quota counts renames in")
+	watchCmd.Flags().StringVar(&watchAudit, "audit-log", "", "Append every rename with user, time and checksums of the directory listing to this file")
+	watchCmd.PersistentFlags().StringVar(&controlPath, "control-socket", "", "Unix domain socket for approving held directories and reloading the policy; required with --rename-quota")
+
+	rootCmd.AddCommand(watchCmd)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/watch"
+)
+
+// watchStatusCmd shows the rename quota of a running watcher
+var watchStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the rename quota and held directories of a running watcher",
+	Args:  cobra.NoArgs,
+	RunE:  runWatchStatus,
+}
+
+// watchApproveCmd releases the directories a watcher holds
+var watchApproveCmd = &cobra.Command{
+	Use:   "approve",
+	Short: "Rename the held directories of a running watcher and resume renaming",
+	Args:  cobra.NoArgs,
+	RunE:  runWatchApprove,
+}
+
+// watchDiscardCmd drops the directories a watcher holds
+var watchDiscardCmd = &cobra.Command{
+	Use:   "discard",
+	Short: "Leave the held directories of a running watcher for a regular run and resume renaming",
+	Args:  cobra.NoArgs,
+	RunE:  runWatchDiscard,
+}
+
+// watchReloadCmd makes a running watcher reload its policy
+var watchReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Reload the --config policy of a running watcher",
+	Args:  cobra.NoArgs,
+	RunE:  runWatchReload,
+}
+
+// newControlClient connects to the watcher on --control-socket
+func newControlClient() (*watch.ControlClient, error) {
+	if controlPath == "" {
+		return nil, errors.New("--control-socket is required")
+	}
+	return watch.NewControlClient(controlPath), nil
+}
+
+// runWatchStatus prints the quota of the watcher
+func runWatchStatus(cmd *cobra.Command, args []string) error {
+	client, err := newControlClient()
+	if err != nil {
+		return err
+	}
+	status, err := client.Status()
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Renames: %d of %d within %s\n", status.Used, status.Limit, status.Window)
+	if !status.Exceeded {
+		fmt.Fprintln(out, "Renaming is running.")
+		return nil
+	}
+
+	fmt.Fprintf(out, "Renaming is halted; %d directories are held for approval:\n", len(status.Held))
+	for _, dir := range status.Held {
+		fmt.Fprintf(out, "  %s\n", dir)
+	}
+	fmt.Fprintln(out, `Run "sanitize watch approve" to rename them or "sanitize watch discard" to leave them for a regular run.`)
+	return nil
+}
+
+// runWatchApprove releases the held directories
+func runWatchApprove(cmd *cobra.Command, args []string) error {
+	client, err := newControlClient()
+	if err != nil {
+		return err
+	}
+	released, err := client.Approve()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Approved %d held directories; renaming resumed.\n", released)
+	return nil
+}
+
+// runWatchDiscard drops the held directories
+func runWatchDiscard(cmd *cobra.Command, args []string) error {
+	client, err := newControlClient()
+	if err != nil {
+		return err
+	}
+	dropped, err := client.Discard()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Discarded %d held directories; renaming resumed.\n", dropped)
+	return nil
+}
+
+// runWatchReload reloads the policy and prints what changed
+func runWatchReload(cmd *cobra.Command, args []string) error {
+	client, err := newControlClient()
+	if err != nil {
+		return err
+	}
+	changes, err := client.Reload()
+	if err != nil {
+		return fmt.Errorf("policy reload failed, the watcher keeps its current policy: %w", err)
+	}
+
+	out := cmd.OutOrStdout()
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "Policy reloaded: nothing changed.")
+		return nil
+	}
+	fmt.Fprintln(out, "Policy reloaded:")
+	for _, change := range changes {
+		fmt.Fprintf(out, "  %s\n", change)
+	}
+	return nil
+}
+
+// init registers the watch control subcommands
+func init() {
+	watchCmd.AddCommand(watchStatusCmd, watchApproveCmd, watchDiscardCmd, watchReloadCmd)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/punkscience/sanitize/internal/interfaces"
+	"github.com/punkscience/sanitize/internal/xattr"
+)
+
+// Flags for original names in extended attributes
+var (
+	storeXattr       bool // Store the original name of every renamed folder in an extended attribute
+	restoreFromXattr bool // Restore reads the original names from extended attributes instead of mapping files
+)
+
+// xattrFailures counts the renamed folders whose original name could not be stored
+type xattrFailures struct {
+	count int
+	first error
+}
+
+// withXattr wraps processor to store original names when --xattr is set on a real run
+// The returned failures are reported after the run with report.
+func withXattr(processor interfaces.FolderProcessor, dryRun bool) (interfaces.FolderProcessor, *xattrFailures, error) {
+	if !storeXattr {
+		return processor, nil, nil
+	}
+	if !xattr.Supported() {
+		return nil, nil, fmt.Errorf("--xattr: %w", xattr.ErrUnsupported)
+	}
+	if dryRun {
+		return processor, nil, nil
+	}
+
+	failures := &xattrFailures{}
+	return xattr.Processor(processor, func(path string, err error) {
+		logger.Warn("cannot store original name", "path", path, "error", err)
+		failures.count++
+		if failures.first == nil {
+			failures.first = err
+		}
+	}), failures, nil
+}
+
+// report prints one warning for all folders whose original name was not stored; the renames themselves succeeded
+func (f *xattrFailures) report() {
+	if f == nil || f.count == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: could not store the original name of %d renamed folders in extended attributes: %v\n", f.count, f.first)
+}
+
+// addXattrFlag registers --xattr on a command that renames folders
+func addXattrFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&storeXattr, "xattr", false, "Store the original name of every renamed folder in its "+xattr.Attribute+" extended attribute")
+}
+
+func init() {
+	addXattrFlag(rootCmd)
+	addXattrFlag(applyCmd)
+	restoreCmd.Flags().BoolVar(&restoreFromXattr, "from-xattr", false, "Read the original names from "+xattr.Attribute+" extended attributes instead of mapping files")
+}